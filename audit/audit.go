@@ -0,0 +1,345 @@
+// Package audit writes the audit log of a run, passed via --audit-log-dir: an append-only record of who ran which
+// command with which config, and of every repo the run touched and commit it produced, for change-management auditors.
+// Each entry can be chained to the one before it by its hash, so that entries edited or removed afterwards are caught by
+// Verify. Only a chain anchored by the hash of its last entry, kept out of reach of whoever could rewrite the log, also
+// catches entries removed from its end.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// Type is the kind of action an entry records
+type Type string
+
+const (
+	// RunStarted is recorded once, before any repo is processed, with the command, config and token identity of the run
+	RunStarted Type = "run_started"
+	// BranchPushed is recorded for each branch pushed to a repo, with the commit it was pushed at
+	BranchPushed Type = "branch_pushed"
+	// PullRequestOpened is recorded for each pull request opened, with the branch and commit it was opened for
+	PullRequestOpened Type = "pull_request_opened"
+	// RepoSucceeded is recorded when a repo has been processed without error
+	RepoSucceeded Type = "repo_succeeded"
+	// RepoFailed is recorded when processing a repo returned an error
+	RepoFailed Type = "repo_failed"
+	// RunFinished is recorded once every repo of the run has been processed
+	RunFinished Type = "run_finished"
+)
+
+// Entry is a single line of the audit log
+type Entry struct {
+	Sequence        int              `json:"sequence"`
+	Time            time.Time        `json:"time"`
+	RunID           string           `json:"run_id"`
+	Type            Type             `json:"type"`
+	GitXargsVersion string           `json:"git_xargs_version,omitempty"`
+	Flags           []string         `json:"flags,omitempty"`
+	Command         []string         `json:"command,omitempty"`
+	Config          *manifest.Config `json:"config,omitempty"`
+	TokenLogin      string           `json:"token_login,omitempty"`
+	Repo            string           `json:"repo,omitempty"`
+	Branch          string           `json:"branch,omitempty"`
+	CommitSHA       string           `json:"commit_sha,omitempty"`
+	PullRequestURL  string           `json:"pull_request_url,omitempty"`
+	Error           string           `json:"error,omitempty"`
+	Repos           int              `json:"repos,omitempty"`
+	Failed          int              `json:"failed,omitempty"`
+	// PrevHash and Hash chain the entry to the one before it, if the log is hash-chained
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// RunInfo is what the run_started entry records about the run
+type RunInfo struct {
+	GitXargsVersion string
+	// Flags are the names of the flags git-xargs was invoked with, without their values, which may hold secrets
+	Flags      []string
+	Command    []string
+	Config     manifest.Config
+	TokenLogin string
+}
+
+// Log appends the entries of a run to its audit log. Runs without --audit-log-dir get a nil Log, on which appending is a
+// no-op
+type Log struct {
+	runID     string
+	hashChain bool
+	mutex     sync.Mutex
+	out       io.Writer
+	file      *os.File
+	sequence  int
+	lastHash  string
+	// err is the first error writing an entry, which is returned by Close
+	err error
+}
+
+// Path returns the path of the audit log of the run with the supplied ID in the supplied directory
+func Path(dir string, runID string) string {
+	return filepath.Join(dir, fmt.Sprintf("git-xargs-%s.audit.jsonl", runID))
+}
+
+// Open returns a Log for the run with the supplied ID that appends to its file in the supplied directory, creating the
+// directory and file if needed. If the file already exists, e.g. because the run is resumed, its entries are carried
+// on, and so is its hash chain if hashChain is set
+func Open(dir string, runID string, hashChain bool) (*Log, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	path := Path(dir, runID)
+	log := New(nil, runID, hashChain)
+	if existing, err := os.Open(path); err == nil {
+		last, err := lastEntry(existing)
+		existing.Close()
+		if err != nil {
+			return nil, errors.WithStackTrace(types.InvalidAuditLogErr{File: path, Err: err})
+		}
+		if last != nil {
+			log.sequence = last.Sequence
+			log.lastHash = last.Hash
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	log.out = file
+	log.file = file
+	return log, nil
+}
+
+// New returns a Log for the run with the supplied ID that writes to the supplied writer, chaining each entry to the
+// one before it by its hash if hashChain is set
+func New(out io.Writer, runID string, hashChain bool) *Log {
+	return &Log{out: out, runID: runID, hashChain: hashChain}
+}
+
+// Close closes the file the log appends to, if it opened one, and returns the first error writing an entry, if any.
+// It can be called more than once
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.file != nil {
+		if err := l.file.Close(); err != nil && l.err == nil {
+			l.err = errors.WithStackTrace(err)
+		}
+		l.file = nil
+	}
+	return l.err
+}
+
+// LastHash returns the hash of the last entry of the log, or an empty string if the log isn't hash-chained. Keeping it
+// where the people running git-xargs can't write anchors the hash chain, so that Verify also catches a log that was cut
+// short or replaced by a rewritten one
+func (l *Log) LastHash() string {
+	if l == nil {
+		return ""
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.lastHash
+}
+
+// RunStarted records the start of the run
+func (l *Log) RunStarted(info RunInfo) error {
+	return l.record(Entry{
+		Type:            RunStarted,
+		GitXargsVersion: info.GitXargsVersion,
+		Flags:           info.Flags,
+		Command:         info.Command,
+		Config:          &info.Config,
+		TokenLogin:      info.TokenLogin,
+	})
+}
+
+// Branch records the supplied branch pushed to the supplied repo, and the pull request opened for it, if any
+func (l *Log) Branch(repo *github.Repository, branch types.PushedBranch) error {
+	entryType := BranchPushed
+	if branch.PullRequestURL != "" {
+		entryType = PullRequestOpened
+	}
+	return l.record(Entry{
+		Type:           entryType,
+		Repo:           fullName(repo),
+		Branch:         branch.Name,
+		CommitSHA:      branch.CommitSHA,
+		PullRequestURL: branch.PullRequestURL,
+	})
+}
+
+// RepoFinished records the outcome of processing the supplied repo, failed if processErr is set
+func (l *Log) RepoFinished(repo *github.Repository, processErr error) error {
+	if processErr != nil {
+		return l.record(Entry{Type: RepoFailed, Repo: fullName(repo), Error: processErr.Error()})
+	}
+	return l.record(Entry{Type: RepoSucceeded, Repo: fullName(repo)})
+}
+
+// RunFinished records the end of the run, with the number of repos it processed and of those that failed
+func (l *Log) RunFinished(repos int, failed int) error {
+	return l.record(Entry{Type: RunFinished, Repos: repos, Failed: failed})
+}
+
+// record appends the supplied entry to the log as a single line, synced to disk before returning, so that the log
+// holds every action taken even if the run is killed
+func (l *Log) record(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry.Sequence = l.sequence + 1
+	entry.Time = time.Now().UTC()
+	entry.RunID = l.runID
+	if l.hashChain {
+		entry.PrevHash = l.lastHash
+		hash, err := entryHash(entry)
+		if err != nil {
+			return l.fail(err)
+		}
+		entry.Hash = hash
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return l.fail(err)
+	}
+	if _, err := l.out.Write(append(line, '\n')); err != nil {
+		return l.fail(err)
+	}
+	if l.file != nil {
+		if err := l.file.Sync(); err != nil {
+			return l.fail(err)
+		}
+	}
+
+	l.sequence = entry.Sequence
+	l.lastHash = entry.Hash
+	return nil
+}
+
+// fail remembers the first error writing an entry, so that Close returns it, and returns it wrapped
+func (l *Log) fail(err error) error {
+	wrapped := errors.WithStackTrace(err)
+	if l.err == nil {
+		l.err = wrapped
+	}
+	return wrapped
+}
+
+// entryHash returns the SHA-256 of the supplied entry, without its own hash, as hex. Since the entry holds the hash of
+// the one before it, changing or removing any entry changes the hashes of every entry after it
+func entryHash(entry Entry) (string, error) {
+	entry.Hash = ""
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify reads the audit log from the supplied reader, and checks that its entries are numbered in sequence and, for
+// the entries that are hash-chained, that each hash matches the entry and the entry before it. If lastHash is set, it
+// also checks that the log ends with the entry of that hash, which catches entries removed from its end and a log
+// rewritten as a whole. It returns the number of entries checked, or a types.AuditLogTamperedErr naming the first entry
+// that doesn't check out
+func Verify(r io.Reader, lastHash string) (int, error) {
+	scanner := newScanner(r)
+	var previous *Entry
+	count := 0
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		count++
+
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: fmt.Sprintf("it isn't valid JSON: %v", err)})
+		}
+		if entry.Sequence != count {
+			return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: fmt.Sprintf("its sequence number is %d", entry.Sequence)})
+		}
+
+		if entry.Hash != "" || entry.PrevHash != "" {
+			expected := ""
+			if previous != nil {
+				expected = previous.Hash
+			}
+			if entry.PrevHash != expected {
+				return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: "it doesn't chain to the entry before it"})
+			}
+			hash, err := entryHash(entry)
+			if err != nil {
+				return count, errors.WithStackTrace(err)
+			}
+			if hash != entry.Hash {
+				return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: "its hash doesn't match its contents"})
+			}
+		} else if previous != nil && previous.Hash != "" {
+			return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: "the hash chain stops at it"})
+		}
+		previous = &entry
+	}
+	if err := scanner.Err(); err != nil {
+		return count, errors.WithStackTrace(err)
+	}
+	if lastHash != "" && (previous == nil || previous.Hash != lastHash) {
+		return count, errors.WithStackTrace(types.AuditLogTamperedErr{Entry: count, Reason: "the log doesn't end with the entry of the anchored last hash"})
+	}
+	return count, nil
+}
+
+// lastEntry returns the last entry of the audit log read from the supplied reader, or nil if it has none
+func lastEntry(r io.Reader) (*Entry, error) {
+	scanner := newScanner(r)
+	var last *Entry
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		last = &entry
+	}
+	return last, scanner.Err()
+}
+
+// newScanner returns a scanner over the lines of the supplied reader, allowing for long lines, since run_started
+// entries hold the whole config of the run
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return scanner
+}
+
+func fullName(repo *github.Repository) string {
+	return repo.GetOwner().GetLogin() + "/" + repo.GetName()
+}
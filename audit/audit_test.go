@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepo() *github.Repository {
+	return &github.Repository{Name: github.String("terragrunt"), Owner: &github.User{Login: github.String("gruntwork-io")}}
+}
+
+// writeTestLog records a run touching a single repo to the audit log of the run in the supplied directory, and returns
+// the hash of the last entry
+func writeTestLog(t *testing.T, dir string, hashChain bool) string {
+	log, err := Open(dir, "run-1", hashChain)
+	require.NoError(t, err)
+
+	require.NoError(t, log.RunStarted(RunInfo{
+		Command:    []string{"touch", "file"},
+		Config:     manifest.Config{BranchName: "update-ci"},
+		TokenLogin: "octocat",
+	}))
+	require.NoError(t, log.Branch(newTestRepo(), types.PushedBranch{Name: "update-ci", CommitSHA: "5555555555555555555555555555555555555555"}))
+	require.NoError(t, log.Branch(newTestRepo(), types.PushedBranch{Name: "update-ci", CommitSHA: "5555555555555555555555555555555555555555", PullRequestURL: "https://github.com/gruntwork-io/terragrunt/pull/1"}))
+	require.NoError(t, log.RepoFinished(newTestRepo(), fmt.Errorf("exit status 1")))
+	require.NoError(t, log.RunFinished(1, 1))
+	require.NoError(t, log.Close())
+	require.NoError(t, log.Close())
+	return log.LastHash()
+}
+
+// Test that a hash-chained audit log verifies, is carried on when its run is resumed, and that editing, removing or
+// reordering its entries is caught
+func TestHashChainedLog(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestLog(t, dir, true)
+	lastHash := writeTestLog(t, dir, true)
+	assert.NotEmpty(t, lastHash)
+
+	contents, err := ioutil.ReadFile(Path(dir, "run-1"))
+	require.NoError(t, err)
+	count, err := Verify(bytes.NewReader(contents), lastHash)
+	require.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	lines := strings.SplitAfter(strings.TrimSuffix(string(contents), "\n"), "\n")
+	assert.Contains(t, lines[0], `"token_login":"octocat"`)
+	assert.Contains(t, lines[2], `"type":"pull_request_opened"`)
+
+	tampered := map[string]string{
+		"edited":    strings.Replace(string(contents), "exit status 1", "exit status 0", 1),
+		"removed":   strings.Join(append(append([]string{}, lines[:3]...), lines[4:]...), ""),
+		"reordered": lines[1] + lines[0] + strings.Join(lines[2:], ""),
+	}
+	for name, contents := range tampered {
+		_, err := Verify(strings.NewReader(contents), "")
+		require.Error(t, err, name)
+		assert.IsType(t, types.AuditLogTamperedErr{}, errors.Unwrap(err), name)
+	}
+
+	// Entries removed from the end of the log are only caught against the anchored last hash
+	truncated := strings.Join(lines[:9], "")
+	_, err = Verify(strings.NewReader(truncated), "")
+	assert.NoError(t, err)
+	_, err = Verify(strings.NewReader(truncated), lastHash)
+	assert.IsType(t, types.AuditLogTamperedErr{}, errors.Unwrap(err))
+}
+
+// Test that an audit log that isn't hash-chained only has the sequence of its entries checked
+func TestUnchainedLog(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Empty(t, writeTestLog(t, dir, false))
+
+	contents, err := ioutil.ReadFile(Path(dir, "run-1"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), `"hash"`)
+
+	count, err := Verify(bytes.NewReader(contents), "")
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+}
+
+// Test that a nil log records nothing
+func TestNilLog(t *testing.T) {
+	t.Parallel()
+
+	var log *Log
+	assert.NoError(t, log.RepoFinished(newTestRepo(), nil))
+	assert.NoError(t, log.Close())
+}
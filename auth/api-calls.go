@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// APICallCounter counts the requests a GithubClient sends to the GitHub API, by endpoint category, and how many of them
+// were retries, so that they can be reported in the run's metrics. A nil *APICallCounter, as found in the mocked clients
+// used in tests, counts nothing
+type APICallCounter struct {
+	count      uint64
+	retries    uint64
+	mutex      sync.Mutex
+	categories map[string]uint64
+}
+
+// Count returns the number of requests sent so far
+func (counter *APICallCounter) Count() uint64 {
+	if counter == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&counter.count)
+}
+
+// Retries returns the number of requests sent again so far, because they failed or were rate limited
+func (counter *APICallCounter) Retries() uint64 {
+	if counter == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&counter.retries)
+}
+
+// ByCategory returns the number of requests sent so far to each category of endpoints, e.g. pulls or search, as
+// returned by apiCallCategory
+func (counter *APICallCounter) ByCategory() map[string]uint64 {
+	categories := map[string]uint64{}
+	if counter == nil {
+		return categories
+	}
+
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	for category, count := range counter.categories {
+		categories[category] = count
+	}
+	return categories
+}
+
+// countCall counts a request about to be sent to the supplied URL path
+func (counter *APICallCounter) countCall(path string) {
+	atomic.AddUint64(&counter.count, 1)
+
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	if counter.categories == nil {
+		counter.categories = map[string]uint64{}
+	}
+	counter.categories[apiCallCategory(path)]++
+}
+
+// apiCallCategory returns the category of GitHub API endpoints the supplied URL path belongs to: the kind of resource
+// of a repo it acts on, such as pulls, issues or git, "repos" for the repo itself, or else the first segment of the
+// path, such as search, orgs or graphql. The /api/v3 prefix of GitHub Enterprise Server is left out
+func apiCallCategory(path string) string {
+	path = strings.TrimPrefix(path, "/api/v3")
+	path = strings.TrimPrefix(path, "/api")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case segments[0] == "":
+		return "root"
+	case segments[0] == "repos" && len(segments) > 3:
+		return segments[3]
+	case segments[0] == "user":
+		return "users"
+	default:
+		return segments[0]
+	}
+}
+
+// countRetry counts a request that is about to be sent again
+func (counter *APICallCounter) countRetry() {
+	if counter == nil {
+		return
+	}
+	atomic.AddUint64(&counter.retries, 1)
+}
+
+// countingTransport is an http.RoundTripper that counts every request it sends via the wrapped RoundTripper
+type countingTransport struct {
+	base    http.RoundTripper
+	counter *APICallCounter
+}
+
+func (transport *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport.counter.countCall(req.URL.Path)
+	return transport.base.RoundTrip(req)
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountingTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	counter := &APICallCounter{}
+	client := &http.Client{Transport: &countingTransport{base: http.DefaultTransport, counter: counter}}
+	for _, path := range []string{"/repos/gruntwork-io/terragrunt/pulls", "/repos/gruntwork-io/fetch/pulls", "/search/repositories"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, uint64(3), counter.Count())
+	assert.Equal(t, map[string]uint64{"pulls": 2, "search": 1}, counter.ByCategory())
+
+	var nilCounter *APICallCounter
+	assert.Equal(t, uint64(0), nilCounter.Count())
+	assert.Empty(t, nilCounter.ByCategory())
+}
+
+func TestAPICallCategory(t *testing.T) {
+	t.Parallel()
+
+	for path, expected := range map[string]string{
+		"/":                              "root",
+		"/repos/gruntwork-io/terragrunt": "repos",
+		"/repos/gruntwork-io/terragrunt/pulls/12":         "pulls",
+		"/repos/gruntwork-io/terragrunt/issues/12/labels": "issues",
+		"/api/v3/repos/gruntwork-io/terragrunt/git/refs":  "git",
+		"/orgs/gruntwork-io/repos":                        "orgs",
+		"/user":                                           "users",
+		"/users/octocat":                                  "users",
+		"/api/graphql":                                    "graphql",
+	} {
+		assert.Equal(t, expected, apiCallCategory(path), path)
+	}
+}
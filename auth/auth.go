@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"os"
 
 	"github.com/google/go-github/v32/github"
@@ -15,12 +17,79 @@ import (
 type githubPullRequestService interface {
 	Create(ctx context.Context, owner string, name string, pr *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
 	List(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, *github.Response, error)
+	ListReviewers(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) (*github.Reviewers, *github.Response, error)
+	RemoveReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.Response, error)
+	Get(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error)
+	ListReviews(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error)
+	CreateReview(ctx context.Context, owner, repo string, number int, review *github.PullRequestReviewRequest) (*github.PullRequestReview, *github.Response, error)
+	Merge(ctx context.Context, owner string, repo string, number int, commitMessage string, options *github.PullRequestOptions) (*github.PullRequestMergeResult, *github.Response, error)
 }
 
 // The go-github package satisfies this Repositories service's interface in production
 type githubRepositoriesService interface {
 	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
 	ListByOrg(ctx context.Context, org string, opts *github.RepositoryListByOrgOptions) ([]*github.Repository, *github.Response, error)
+	GetCombinedStatus(ctx context.Context, owner, repo, ref string, opts *github.ListOptions) (*github.CombinedStatus, *github.Response, error)
+	GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error)
+	ListBranches(ctx context.Context, owner string, repo string, opts *github.BranchListOptions) ([]*github.Branch, *github.Response, error)
+	CreateStatus(ctx context.Context, owner, repo, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error)
+	GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error)
+	CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	DeleteFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+	GetBranchProtection(ctx context.Context, owner, repo, branch string) (*github.Protection, *github.Response, error)
+	GetSignaturesProtectedBranch(ctx context.Context, owner, repo, branch string) (*github.SignaturesProtectedBranch, *github.Response, error)
+}
+
+// The go-github package satisfies this Issues service's interface in production
+type githubIssuesService interface {
+	AddAssignees(ctx context.Context, owner, repo string, number int, assignees []string) (*github.Issue, *github.Response, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error)
+	Create(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+}
+
+// The go-github package satisfies this Checks service's interface in production
+type githubChecksService interface {
+	ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opts *github.ListCheckRunsOptions) (*github.ListCheckRunsResults, *github.Response, error)
+}
+
+// The go-github package satisfies this Git service's interface in production
+type githubGitService interface {
+	DeleteRef(ctx context.Context, owner string, repo string, ref string) (*github.Response, error)
+	GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error)
+	CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error)
+	UpdateRef(ctx context.Context, owner string, repo string, ref *github.Reference, force bool) (*github.Reference, *github.Response, error)
+	CreateBlob(ctx context.Context, owner string, repo string, blob *github.Blob) (*github.Blob, *github.Response, error)
+	CreateTree(ctx context.Context, owner string, repo string, baseTree string, entries []*github.TreeEntry) (*github.Tree, *github.Response, error)
+	CreateCommit(ctx context.Context, owner string, repo string, commit *github.Commit) (*github.Commit, *github.Response, error)
+}
+
+// The go-github package satisfies this Gists service's interface in production
+type githubGistsService interface {
+	Create(ctx context.Context, gist *github.Gist) (*github.Gist, *github.Response, error)
+}
+
+// The go-github package satisfies this Teams service's interface in production
+type githubTeamsService interface {
+	ListTeamMembersBySlug(ctx context.Context, org, slug string, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error)
+}
+
+// The go-github package satisfies this Users service's interface in production
+type githubUsersService interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+}
+
+// The go-github package satisfies this Search service's interface in production
+type githubSearchService interface {
+	Issues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error)
+}
+
+// The go-github package satisfies this rate limit service's interface in production
+type githubRateLimitsService interface {
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
 }
 
 // GithubClient is the data structure that is common between production code and test code. In production code,
@@ -31,12 +100,38 @@ type githubRepositoriesService interface {
 type GithubClient struct {
 	PullRequests githubPullRequestService
 	Repositories githubRepositoriesService
+	Search       githubSearchService
+	Users        githubUsersService
+	Issues       githubIssuesService
+	Teams        githubTeamsService
+	Checks       githubChecksService
+	Git          githubGitService
+	Gists        githubGistsService
+	GraphQL      githubGraphQLService
+	RateLimits   githubRateLimitsService
+	Meta         githubMetaService
+	Rules        githubRulesService
+	APICalls     *APICallCounter
+	RateLimiter  *RateLimiter
+	APIHeaders   *APIHeaders
+	BaseURL      *url.URL
 }
 
 func NewClient(client *github.Client) GithubClient {
 	return GithubClient{
 		PullRequests: client.PullRequests,
 		Repositories: client.Repositories,
+		Search:       client.Search,
+		Users:        client.Users,
+		Issues:       client.Issues,
+		Teams:        client.Teams,
+		Checks:       client.Checks,
+		Git:          client.Git,
+		Gists:        client.Gists,
+		RateLimits:   client,
+		Meta:         metaService{client: client},
+		Rules:        rulesService{client: client},
+		BaseURL:      client.BaseURL,
 	}
 }
 
@@ -45,14 +140,91 @@ func ConfigureGithubClient() GithubClient {
 	// Ensure user provided a GITHUB_OAUTH_TOKEN
 	GithubOauthToken := os.Getenv("GITHUB_OAUTH_TOKEN")
 
+	return ConfigureGithubClientForToken(GithubOauthToken)
+}
+
+// ConfigureApproverGithubClient creates a GitHub API client using the user-supplied GITHUB_APPROVER_OAUTH_TOKEN, which
+// belongs to the second identity used by --approve-and-merge, and returns the configured GitHub client
+func ConfigureApproverGithubClient() GithubClient {
+	return ConfigureGithubClientForToken(os.Getenv("GITHUB_APPROVER_OAUTH_TOKEN"))
+}
+
+// ConfigureGithubClientForToken creates a GitHub API client that authenticates with the supplied token, for callers that
+// don't read it from the environment, such as programs embedding git-xargs
+func ConfigureGithubClientForToken(token string) GithubClient {
+	return ConfigureGithubClientForHost("", token)
+}
+
+// ConfigureGithubClientForHost creates a GitHub API client for the GitHub Enterprise Server at the supplied host, e.g.
+// github.example.com, that authenticates with the supplied token. An empty host denotes github.com
+func ConfigureGithubClientForHost(host string, token string) GithubClient {
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: GithubOauthToken},
+		&oauth2.Token{AccessToken: token},
 	)
 
-	tc := oauth2.NewClient(context.Background(), ts)
+	return configureGithubClient(oauth2.NewClient(context.Background(), ts).Transport, host)
+}
+
+// ConfigureRecordingGithubClient creates a GitHub API client like ConfigureGithubClientForToken, that also records each
+// request it sends, and the response it gets, in the supplied recording
+func ConfigureRecordingGithubClient(token string, recording *Recording) GithubClient {
+	return ConfigureRecordingGithubClientForHost("", token, recording)
+}
+
+// ConfigureRecordingGithubClientForHost creates a GitHub API client like ConfigureGithubClientForHost, that also records
+// each request it sends, and the response it gets, in the supplied recording
+func ConfigureRecordingGithubClientForHost(host string, token string, recording *Recording) GithubClient {
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+
+	transport := oauth2.NewClient(context.Background(), ts).Transport
+	return configureGithubClient(&recordingTransport{base: transport, recording: recording}, host)
+}
+
+// ConfigureReplayingGithubClient creates a GitHub API client that answers every request with the interactions of the
+// supplied recording, instead of calling the GitHub API, so that it needs no token
+func ConfigureReplayingGithubClient(recording *Recording) GithubClient {
+	return ConfigureReplayingGithubClientForHost("", recording)
+}
+
+// ConfigureReplayingGithubClientForHost creates a GitHub API client like ConfigureReplayingGithubClient, for the GitHub
+// Enterprise Server at the supplied host
+func ConfigureReplayingGithubClientForHost(host string, recording *Recording) GithubClient {
+	return configureGithubClient(&replayTransport{recording: recording}, host)
+}
+
+// configureGithubClient creates a GitHub API client that sends its requests via the supplied transport, to the API of
+// the GitHub Enterprise Server at the supplied host, or of github.com if the host is empty
+func configureGithubClient(transport http.RoundTripper, host string) GithubClient {
+	// Send the headers passed via --api-header with every request. The transport of the token, if any, sets the
+	// Authorization header after them
+	apiHeaders := &APIHeaders{}
+	tc := &http.Client{Transport: &headerTransport{base: transport, headers: apiHeaders}}
+
+	// Count every request sent with this token, including the GraphQL ones and the ones retried after being rate
+	// limited, for the run's metrics
+	apiCalls := &APICallCounter{}
+	tc.Transport = &countingTransport{base: tc.Transport, counter: apiCalls}
+
+	// Pace the requests sent with this token by every worker, and pause them when the rate limit is hit
+	rateLimiter := NewRateLimiter()
+	tc.Transport = &rateLimitTransport{base: tc.Transport, limiter: rateLimiter, counter: apiCalls}
+
+	// Send the requests that failed with a server error or timed out again, with exponential backoff
+	tc.Transport = &retryTransport{base: tc.Transport, counter: apiCalls, backoff: retryBackoff}
 
 	// Wrap the go-github client in a GithubClient struct, which is common between production and test code
-	client := NewClient(github.NewClient(tc))
+	githubClient := github.NewClient(tc)
+	if host != "" {
+		githubClient.BaseURL = &url.URL{Scheme: "https", Host: host, Path: "/api/v3/"}
+		githubClient.UploadURL = &url.URL{Scheme: "https", Host: host, Path: "/api/uploads/"}
+	}
+	client := NewClient(githubClient)
+	client.GraphQL = NewGraphQLClient(tc, githubClient.BaseURL)
+	client.APICalls = apiCalls
+	client.RateLimiter = rateLimiter
+	client.APIHeaders = apiHeaders
 
 	return client
 }
@@ -64,3 +236,26 @@ func EnsureGithubOauthTokenSet() error {
 	}
 	return nil
 }
+
+// UseGithubTokenFromEnv makes the GitHub token exported as the supplied environment variable the one git-xargs uses, in
+// place of GITHUB_OAUTH_TOKEN, so that profiles for different organizations can each read their own token
+func UseGithubTokenFromEnv(envVar string) error {
+	token := os.Getenv(envVar)
+	if token == "" {
+		return errors.WithStackTrace(types.GithubTokenEnvNotSetErr{EnvVar: envVar})
+	}
+	return errors.WithStackTrace(os.Setenv("GITHUB_OAUTH_TOKEN", token))
+}
+
+// EnsureGithubApproverOauthTokenSet is a sanity check that a value is exported for GITHUB_APPROVER_OAUTH_TOKEN, and that
+// it differs from GITHUB_OAUTH_TOKEN, since GitHub does not let the author of a pull request approve it
+func EnsureGithubApproverOauthTokenSet() error {
+	approverToken := os.Getenv("GITHUB_APPROVER_OAUTH_TOKEN")
+	if approverToken == "" {
+		return errors.WithStackTrace(types.NoGithubApproverOauthTokenProvidedErr{})
+	}
+	if approverToken == os.Getenv("GITHUB_OAUTH_TOKEN") {
+		return errors.WithStackTrace(types.SameGithubApproverOauthTokenErr{})
+	}
+	return nil
+}
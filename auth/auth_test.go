@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"net/url"
 	"os"
 	"testing"
 
@@ -16,8 +17,8 @@ func TestConfigureGithubClient(t *testing.T) {
 }
 
 // TestNoGithubOauthTokenPassed temporarily drops the existing GITHUB_OAUTH_TOKEN env var to ensure that the validation
-// code throws an error when it is missing. It then replaces it. This is therefore the one test that cannot be run in
-// parallel.
+// code throws an error when it is missing. It then replaces it. This is therefore one of the tests that cannot be run
+// in parallel.
 func TestNoGithubOAuthTokenPassed(t *testing.T) {
 	token := os.Getenv("GITHUB_OAUTH_TOKEN")
 	defer os.Setenv("GITHUB_OAUTH_TOKEN", token)
@@ -27,3 +28,28 @@ func TestNoGithubOAuthTokenPassed(t *testing.T) {
 	err := EnsureGithubOauthTokenSet()
 	assert.Error(t, err)
 }
+
+// TestGraphQLEndpointForBaseURL ensures the GraphQL endpoint is derived correctly for github.com and GitHub Enterprise
+func TestGraphQLEndpointForBaseURL(t *testing.T) {
+	t.Parallel()
+
+	githubDotCom, _ := url.Parse("https://api.github.com/")
+	assert.Equal(t, "https://api.github.com/graphql", graphQLEndpointForBaseURL(githubDotCom))
+
+	enterprise, _ := url.Parse("https://github.example.com/api/v3/")
+	assert.Equal(t, "https://github.example.com/api/graphql", graphQLEndpointForBaseURL(enterprise))
+}
+
+// TestUseGithubTokenFromEnv replaces GITHUB_OAUTH_TOKEN with the token of another environment variable, and then
+// restores it, so it cannot be run in parallel either
+func TestUseGithubTokenFromEnv(t *testing.T) {
+	token := os.Getenv("GITHUB_OAUTH_TOKEN")
+	defer os.Setenv("GITHUB_OAUTH_TOKEN", token)
+	defer os.Unsetenv("GIT_XARGS_TEST_PROFILE_TOKEN")
+
+	assert.Error(t, UseGithubTokenFromEnv("GIT_XARGS_TEST_PROFILE_TOKEN"))
+
+	os.Setenv("GIT_XARGS_TEST_PROFILE_TOKEN", "profile-token")
+	assert.NoError(t, UseGithubTokenFromEnv("GIT_XARGS_TEST_PROFILE_TOKEN"))
+	assert.Equal(t, "profile-token", os.Getenv("GITHUB_OAUTH_TOKEN"))
+}
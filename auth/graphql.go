@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// The GraphQL service is used for the operations that GitHub only exposes via its GraphQL API, such as marking a
+// draft pull request as ready for review
+type githubGraphQLService interface {
+	Do(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error
+}
+
+// graphQLClient is the production implementation of the GraphQL service. It shares the authenticated HTTP client that
+// go-github uses, so that the same GITHUB_OAUTH_TOKEN is sent along with every request
+type graphQLClient struct {
+	httpClient *http.Client
+	endpoint   string
+}
+
+// graphQLResponse is the envelope GitHub wraps around every GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// NewGraphQLClient returns a GraphQL service that sends requests to the GraphQL endpoint corresponding to the supplied
+// REST API base URL, e.g., https://api.github.com/ maps to https://api.github.com/graphql
+func NewGraphQLClient(httpClient *http.Client, baseURL *url.URL) githubGraphQLService {
+	return graphQLClient{
+		httpClient: httpClient,
+		endpoint:   graphQLEndpointForBaseURL(baseURL),
+	}
+}
+
+// graphQLEndpointForBaseURL converts a REST API base URL into its GraphQL counterpart. GitHub Enterprise serves the
+// REST API at /api/v3/ and GraphQL at /api/graphql, whereas github.com serves GraphQL at the root of api.github.com
+func graphQLEndpointForBaseURL(baseURL *url.URL) string {
+	base := strings.TrimSuffix(baseURL.String(), "/")
+	base = strings.TrimSuffix(base, "/v3")
+	return base + "/graphql"
+}
+
+// Do sends the supplied query and variables to the GraphQL API and unmarshals the data field of the response into result
+func (g graphQLClient) Do(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.WithStackTrace(types.GraphQLRequestFailedErr{StatusCode: resp.StatusCode})
+	}
+
+	var envelope graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	if len(envelope.Errors) > 0 {
+		messages := []string{}
+		for _, e := range envelope.Errors {
+			messages = append(messages, e.Message)
+		}
+		return errors.WithStackTrace(types.GraphQLResponseErr{Messages: messages})
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return errors.WithStackTrace(json.Unmarshal(envelope.Data, result))
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// ParseAPIHeaders reads the headers passed via --api-header, in the format of <name>: <value>
+func ParseAPIHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.WithStackTrace(types.InvalidAPIHeaderErr{Header: value})
+		}
+		name := strings.TrimSpace(parts[0])
+		if name == "" || strings.ContainsAny(name, " \t") {
+			return nil, errors.WithStackTrace(types.InvalidAPIHeaderErr{Header: value})
+		}
+		headers.Add(textproto.CanonicalMIMEHeaderKey(name), strings.TrimSpace(parts[1]))
+	}
+	return headers, nil
+}
+
+// APIHeaders holds the static headers a GitHub API client sends with each of its requests, e.g. the ones an
+// authenticating proxy in front of a GitHub Enterprise Server requires
+type APIHeaders struct {
+	mutex   sync.RWMutex
+	headers http.Header
+}
+
+func (apiHeaders *APIHeaders) get() http.Header {
+	apiHeaders.mutex.RLock()
+	defer apiHeaders.mutex.RUnlock()
+	return apiHeaders.headers
+}
+
+func (apiHeaders *APIHeaders) set(headers http.Header) {
+	apiHeaders.mutex.Lock()
+	defer apiHeaders.mutex.Unlock()
+	apiHeaders.headers = headers
+}
+
+// SetAPIHeaders sets the static headers the client sends with each of its requests from then on. They only apply to
+// this client, and to the copies of it. The token of the client always takes precedence over an Authorization header
+func (client GithubClient) SetAPIHeaders(headers http.Header) {
+	if client.APIHeaders == nil {
+		return
+	}
+	client.APIHeaders.set(headers)
+}
+
+// headerTransport is an http.RoundTripper that adds the headers of its client to each request before sending it via
+// the wrapped RoundTripper
+type headerTransport struct {
+	base    http.RoundTripper
+	headers *APIHeaders
+}
+
+func (transport *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := transport.headers.get()
+	if len(headers) == 0 {
+		return transport.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it was handed, so the headers are set on a copy of it
+	withHeaders := req.Clone(req.Context())
+	for name, values := range headers {
+		withHeaders.Header[name] = values
+	}
+	return transport.base.RoundTrip(withHeaders)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestParseAPIHeaders(t *testing.T) {
+	t.Parallel()
+
+	headers, err := ParseAPIHeaders([]string{"x-forwarded-auth: secret", "X-Team:platform", "X-Team: infra", "X-Empty:"})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", headers.Get("X-Forwarded-Auth"))
+	assert.Equal(t, []string{"platform", "infra"}, headers["X-Team"])
+	assert.Equal(t, []string{""}, headers["X-Empty"])
+
+	for _, invalid := range []string{"X-Forwarded-Auth", ": secret", "X Forwarded Auth: secret"} {
+		_, err := ParseAPIHeaders([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+// Test that the --api-header headers are sent with every request, without overriding the token of the client
+func TestHeaderTransportAddsAPIHeaders(t *testing.T) {
+	t.Parallel()
+
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+	}))
+	defer server.Close()
+
+	headers, err := ParseAPIHeaders([]string{"X-Forwarded-Auth: secret", "Authorization: Basic proxy"})
+	require.NoError(t, err)
+	apiHeaders := &APIHeaders{}
+	apiHeaders.set(headers)
+
+	tokenTransport := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})).Transport
+	client := &http.Client{Transport: &headerTransport{base: tokenTransport, headers: apiHeaders}}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "secret", received.Get("X-Forwarded-Auth"))
+	assert.Equal(t, "Bearer token", received.Get("Authorization"))
+	assert.Empty(t, req.Header.Get("X-Forwarded-Auth"))
+}
+
+// Test that the headers set on one client aren't sent by another one
+func TestSetAPIHeadersOnlyAppliesToTheClient(t *testing.T) {
+	t.Parallel()
+
+	headers, err := ParseAPIHeaders([]string{"X-Forwarded-Auth: secret"})
+	require.NoError(t, err)
+
+	client := ConfigureGithubClientForHost("github.example.com", "token")
+	other := ConfigureGithubClientForHost("github.example.com", "token")
+	client.SetAPIHeaders(headers)
+
+	assert.Equal(t, "secret", client.APIHeaders.get().Get("X-Forwarded-Auth"))
+	assert.Empty(t, other.APIHeaders.get())
+}
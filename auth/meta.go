@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// The meta service reads the version a GitHub Enterprise Server reports via its meta endpoint, which go-github doesn't
+// expose. github.com reports no version
+type githubMetaService interface {
+	InstalledVersion(ctx context.Context) (string, *github.Response, error)
+}
+
+// metaService calls the meta endpoint with the go-github client it wraps
+type metaService struct {
+	client *github.Client
+}
+
+func (service metaService) InstalledVersion(ctx context.Context) (string, *github.Response, error) {
+	req, err := service.client.NewRequest("GET", "meta", nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	resp, err := service.client.Do(ctx, req, &meta)
+	if err != nil {
+		return "", resp, err
+	}
+	return meta.InstalledVersion, resp, nil
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// rateLimitPaceFraction is the fraction of a rate limit window's requests left below which requests are paced, so
+	// that the ones left are spread evenly over the rest of the window instead of being used up at once
+	rateLimitPaceFraction = 10
+	// maxRateLimitRetries is the number of times a request that was rate limited is sent again before its response is
+	// returned as is
+	maxRateLimitRetries = 3
+	// defaultSecondaryRateLimitPause is how long API calls are paused after hitting a secondary rate limit that doesn't
+	// say when to retry, as recommended by GitHub
+	defaultSecondaryRateLimitPause = time.Minute
+)
+
+// rateLimitWindow is what the GitHub API last reported about one of its rate limits
+type rateLimitWindow struct {
+	limit       int
+	remaining   int
+	reset       time.Time
+	pausedUntil time.Time
+	nextRequest time.Time
+}
+
+// RateLimiter paces the requests a GithubClient sends, based on the X-RateLimit-* headers of the GitHub API's responses,
+// and pauses them after a request is rate limited, so that large runs don't exhaust the rate limit and fail with 403s.
+// The core, search and GraphQL APIs are limited separately. A RateLimiter is shared by every request sent with a token
+type RateLimiter struct {
+	mutex   sync.Mutex
+	windows map[string]*rateLimitWindow
+	now     func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter that knows nothing about the rate limits yet
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		windows: map[string]*rateLimitWindow{},
+		now:     time.Now,
+	}
+}
+
+// window returns the window of the supplied rate limit resource. Must be called with the mutex held
+func (limiter *RateLimiter) window(resource string) *rateLimitWindow {
+	window, ok := limiter.windows[resource]
+	if !ok {
+		window = &rateLimitWindow{}
+		limiter.windows[resource] = window
+	}
+	return window
+}
+
+// reserve returns how long to wait before sending a request against the supplied rate limit resource. Requests are
+// held back while the API is paused, or until the window resets once no requests are left, and are spaced evenly over
+// the rest of the window once fewer than a tenth of its requests are left
+func (limiter *RateLimiter) reserve(resource string) time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	window := limiter.window(resource)
+
+	if window.pausedUntil.After(now) {
+		return window.pausedUntil.Sub(now)
+	}
+	if window.limit == 0 || !window.reset.After(now) || window.remaining > window.limit/rateLimitPaceFraction {
+		return 0
+	}
+	if window.remaining <= 0 {
+		return window.reset.Sub(now)
+	}
+
+	start := window.nextRequest
+	if start.Before(now) {
+		start = now
+	}
+	window.nextRequest = start.Add(window.reset.Sub(now) / time.Duration(window.remaining+1))
+	window.remaining--
+	return start.Sub(now)
+}
+
+// update records the rate limit reported by the supplied response, and pauses requests against its resource until
+// the supplied time, if it isn't zero
+func (limiter *RateLimiter) update(resource string, resp *http.Response, pauseUntil time.Time) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	window := limiter.window(resource)
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		window.limit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		window.remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		window.reset = time.Unix(reset, 0)
+	}
+	if pauseUntil.After(window.pausedUntil) {
+		window.pausedUntil = pauseUntil
+	}
+}
+
+// Headroom returns the fraction of the core API's rate limit window that is left, from 0 to 1. It returns 0 while API
+// calls are paused after hitting a rate limit, and 1 if nothing is known about the rate limit yet or its window reset
+func (limiter *RateLimiter) Headroom() float64 {
+	if limiter == nil {
+		return 1
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	window := limiter.window("core")
+	if window.pausedUntil.After(now) {
+		return 0
+	}
+	if window.limit <= 0 || !window.reset.After(now) {
+		return 1
+	}
+	if window.remaining <= 0 {
+		return 0
+	}
+	return float64(window.remaining) / float64(window.limit)
+}
+
+// Status returns what the GitHub API last reported about each of its rate limits requests were sent against, keyed by
+// resource, e.g. core, search or graphql, so that operators can see how much of the rate limit a run left for the next
+func (limiter *RateLimiter) Status() map[string]types.RateLimitStatus {
+	status := map[string]types.RateLimitStatus{}
+	if limiter == nil {
+		return status
+	}
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	for resource, window := range limiter.windows {
+		if window.limit <= 0 {
+			continue
+		}
+		status[resource] = types.RateLimitStatus{Limit: window.limit, Remaining: window.remaining, Reset: window.reset}
+	}
+	return status
+}
+
+// rateLimitResource returns the rate limit the supplied request counts against
+func rateLimitResource(req *http.Request) string {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case strings.Contains(req.URL.Path, "/search/"):
+		return "search"
+	default:
+		return "core"
+	}
+}
+
+// rateLimitedUntil returns when the request that got the supplied response may be sent again, if the response says it
+// was rate limited. Primary rate limits are lifted when their window resets, while secondary rate limits either say
+// how long to wait via Retry-After or only say so in the response body. Older GitHub Enterprise Server versions call
+// secondary rate limits abuse detection
+func rateLimitedUntil(resp *http.Response, now time.Time) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	if retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return now.Add(time.Duration(retryAfter) * time.Second), true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(reset, 0), true
+		}
+	}
+
+	// Read the body to look for a secondary rate limit, and put it back, so the caller can still read it
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	lowerBody := strings.ToLower(string(body))
+	if err == nil && (strings.Contains(lowerBody, "secondary rate limit") || strings.Contains(lowerBody, "abuse detection")) {
+		return now.Add(defaultSecondaryRateLimitPause), true
+	}
+	return time.Time{}, false
+}
+
+// rateLimitTransport is an http.RoundTripper that paces the requests it sends via the wrapped RoundTripper with a
+// RateLimiter, and transparently sends rate limited requests again once the rate limit is lifted
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	limiter *RateLimiter
+	counter *APICallCounter
+}
+
+func (transport *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.GetLogger("git-xargs")
+	resource := rateLimitResource(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := waitFor(req.Context(), transport.limiter.reserve(resource)); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := transport.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		pauseUntil, rateLimited := rateLimitedUntil(resp, transport.limiter.now())
+		if !rateLimited {
+			transport.limiter.update(resource, resp, time.Time{})
+			return resp, nil
+		}
+		transport.limiter.update(resource, resp, pauseUntil)
+
+		// Requests whose body can't be read again can't be retried
+		if attempt >= maxRateLimitRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		resp.Body.Close()
+		transport.counter.countRetry()
+
+		logger.WithFields(logrus.Fields{
+			"Resource": resource,
+			"Until":    pauseUntil.Format(time.RFC3339),
+		}).Warn("Hit the GitHub API rate limit, pausing API calls until it is lifted")
+	}
+}
+
+// waitFor waits for the supplied duration, or until the supplied context is cancelled
+func waitFor(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResponse(statusCode int, headers map[string]string, body string) *http.Response {
+	resp := &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestRateLimiterPacesRequestsNearTheLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// Plenty of requests left, so nothing is held back
+	limiter.update("core", newTestResponse(http.StatusOK, map[string]string{
+		"X-RateLimit-Limit":     "5000",
+		"X-RateLimit-Remaining": "4000",
+		"X-RateLimit-Reset":     strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+	}, ""), time.Time{})
+	assert.Equal(t, time.Duration(0), limiter.reserve("core"))
+
+	// Fewer than a tenth of the requests left, so the 3 left are spread over the rest of the window
+	limiter.update("core", newTestResponse(http.StatusOK, map[string]string{
+		"X-RateLimit-Remaining": "3",
+		"X-RateLimit-Reset":     strconv.FormatInt(now.Add(4*time.Minute).Unix(), 10),
+	}, ""), time.Time{})
+	assert.Equal(t, time.Duration(0), limiter.reserve("core"))
+	assert.Equal(t, time.Minute, limiter.reserve("core"))
+
+	// Other resources are limited separately
+	assert.Equal(t, time.Duration(0), limiter.reserve("search"))
+
+	// No requests left, so requests wait for the window to reset
+	limiter.update("core", newTestResponse(http.StatusOK, map[string]string{
+		"X-RateLimit-Remaining": "0",
+	}, ""), time.Time{})
+	assert.Equal(t, 4*time.Minute, limiter.reserve("core"))
+}
+
+func TestRateLimiterHeadroom(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	limiter := NewRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// Nothing is known about the rate limit yet
+	assert.Equal(t, 1.0, limiter.Headroom())
+
+	limiter.update("core", newTestResponse(http.StatusOK, map[string]string{
+		"X-RateLimit-Limit":     "5000",
+		"X-RateLimit-Remaining": "1250",
+		"X-RateLimit-Reset":     strconv.FormatInt(now.Add(time.Hour).Unix(), 10),
+	}, ""), time.Time{})
+	assert.Equal(t, 0.25, limiter.Headroom())
+	assert.Equal(t, map[string]types.RateLimitStatus{
+		"core": {Limit: 5000, Remaining: 1250, Reset: time.Unix(now.Add(time.Hour).Unix(), 0)},
+	}, limiter.Status())
+
+	// API calls are paused after hitting a rate limit
+	limiter.update("core", newTestResponse(http.StatusForbidden, nil, ""), now.Add(time.Minute))
+	assert.Equal(t, 0.0, limiter.Headroom())
+
+	var noLimiter *RateLimiter
+	assert.Equal(t, 1.0, noLimiter.Headroom())
+	assert.Empty(t, noLimiter.Status())
+}
+
+func TestRateLimitedUntil(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	reset := now.Add(10 * time.Minute)
+
+	testCases := []struct {
+		name          string
+		resp          *http.Response
+		expectLimited bool
+		expectUntil   time.Time
+	}{
+		{"success", newTestResponse(http.StatusOK, map[string]string{"X-RateLimit-Remaining": "0"}, ""), false, time.Time{}},
+		{"forbidden", newTestResponse(http.StatusForbidden, nil, `{"message": "Resource not accessible by integration"}`), false, time.Time{}},
+		{"retry after", newTestResponse(http.StatusForbidden, map[string]string{"Retry-After": "30"}, ""), true, now.Add(30 * time.Second)},
+		{"too many requests", newTestResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}, ""), true, now.Add(5 * time.Second)},
+		{"primary rate limit", newTestResponse(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(reset.Unix(), 10)}, ""), true, reset},
+		{"secondary rate limit", newTestResponse(http.StatusForbidden, nil, `{"message": "You have exceeded a secondary rate limit."}`), true, now.Add(defaultSecondaryRateLimitPause)},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			until, limited := rateLimitedUntil(testCase.resp, now)
+			assert.Equal(t, testCase.expectLimited, limited)
+			assert.Equal(t, testCase.expectUntil, until)
+
+			// The body must still be readable by the caller
+			_, err := ioutil.ReadAll(testCase.resp.Body)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRateLimitTransportRetriesRateLimitedRequests(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	counter := &APICallCounter{}
+	client := &http.Client{Transport: &rateLimitTransport{base: http.DefaultTransport, limiter: NewRateLimiter(), counter: counter}}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, uint64(1), counter.Retries())
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// Interaction is a request sent to the GitHub API and the response it got, as kept in a recording
+type Interaction struct {
+	Method      string              `json:"method"`
+	URL         string              `json:"url"`
+	RequestBody string              `json:"request_body,omitempty"`
+	StatusCode  int                 `json:"status_code"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        string              `json:"body"`
+}
+
+// Recording holds the GitHub API interactions of a run, so that they can be saved with --record and served again
+// with --replay instead of calling the GitHub API. It is safe to use from concurrent goroutines
+type Recording struct {
+	mutex        sync.Mutex
+	Interactions []Interaction `json:"interactions"`
+	replayed     []bool
+}
+
+// NewRecording returns an empty recording, to record the interactions of a run in
+func NewRecording() *Recording {
+	return &Recording{Interactions: []Interaction{}}
+}
+
+// LoadRecording reads the recording saved at the supplied path, to replay its interactions
+func LoadRecording(path string) (*Recording, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	recording := &Recording{}
+	if err := json.Unmarshal(contents, recording); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidRecordingErr{File: path, Err: err})
+	}
+	recording.replayed = make([]bool, len(recording.Interactions))
+	return recording, nil
+}
+
+// Save writes the recorded interactions to the supplied path as JSON
+func (recording *Recording) Save(path string) error {
+	recording.mutex.Lock()
+	defer recording.mutex.Unlock()
+
+	contents, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(ioutil.WriteFile(path, contents, 0600))
+}
+
+// record adds the supplied interaction to the recording
+func (recording *Recording) record(interaction Interaction) {
+	recording.mutex.Lock()
+	defer recording.mutex.Unlock()
+	recording.Interactions = append(recording.Interactions, interaction)
+}
+
+// replay returns the recorded interaction to answer a request with the supplied method, URL and body with. Interactions
+// are replayed in the order they were recorded, preferring ones whose request body matches, since the bodies of some
+// requests, such as the ones opening pull requests, differ between runs. Once every matching interaction was replayed,
+// the last one is replayed again, so that polling doesn't run out of responses
+func (recording *Recording) replay(method string, url string, body string) (Interaction, bool) {
+	recording.mutex.Lock()
+	defer recording.mutex.Unlock()
+
+	sameRequest := func(interaction Interaction) bool {
+		return interaction.Method == method && interaction.URL == url
+	}
+
+	for _, matchBody := range []bool{true, false} {
+		for i, interaction := range recording.Interactions {
+			if !recording.replayed[i] && sameRequest(interaction) && (!matchBody || interaction.RequestBody == body) {
+				recording.replayed[i] = true
+				return interaction, true
+			}
+		}
+	}
+	for i := len(recording.Interactions) - 1; i >= 0; i-- {
+		if sameRequest(recording.Interactions[i]) {
+			return recording.Interactions[i], true
+		}
+	}
+	return Interaction{}, false
+}
+
+// readRequestBody returns the body of the supplied request, and puts it back, so that it can still be sent
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return string(body), nil
+}
+
+// recordingTransport is an http.RoundTripper that sends requests via the wrapped RoundTripper, and records each request
+// and its response. Only the response headers are recorded, so that the token sent with the requests isn't
+type recordingTransport struct {
+	base      http.RoundTripper
+	recording *Recording
+}
+
+func (transport *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := transport.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	transport.recording.record(Interaction{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		RequestBody: requestBody,
+		StatusCode:  resp.StatusCode,
+		Header:      resp.Header,
+		Body:        string(body),
+	})
+	return resp, nil
+}
+
+// replayTransport is an http.RoundTripper that answers requests with the interactions of a recording, without sending
+// them anywhere
+type replayTransport struct {
+	recording *Recording
+}
+
+func (transport *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction, ok := transport.recording.replay(req.Method, req.URL.String(), requestBody)
+	if !ok {
+		return nil, errors.WithStackTrace(types.NoRecordedResponseErr{Method: req.Method, URL: req.URL.String()})
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(interaction.StatusCode),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(interaction.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(interaction.Body))),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       req,
+	}, nil
+}
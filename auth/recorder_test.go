@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the interactions recorded with --record are answered the same way with --replay, without a server
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Request", fmt.Sprint(requests))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s %s #%d", r.Method, r.URL.Path, body, requests)
+	}))
+
+	dir, err := ioutil.TempDir("", "git-xargs-recording-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "recording.json")
+
+	send := func(client *http.Client, method string, url string, body string) (string, *http.Response) {
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		respBody, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(respBody), resp
+	}
+
+	recording := NewRecording()
+	recordingClient := &http.Client{Transport: &recordingTransport{base: http.DefaultTransport, recording: recording}}
+	send(recordingClient, http.MethodGet, server.URL+"/repos", "")
+	send(recordingClient, http.MethodGet, server.URL+"/repos", "")
+	send(recordingClient, http.MethodPost, server.URL+"/pulls", "first")
+	send(recordingClient, http.MethodPost, server.URL+"/pulls", "second")
+	server.Close()
+	require.NoError(t, recording.Save(path))
+
+	replayed, err := LoadRecording(path)
+	require.NoError(t, err)
+	replayingClient := &http.Client{Transport: &replayTransport{recording: replayed}}
+
+	body, resp := send(replayingClient, http.MethodGet, server.URL+"/repos", "")
+	assert.Equal(t, "GET /repos  #1", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("X-Request"))
+
+	// Requests whose body matches are preferred, and the others are replayed in order
+	body, _ = send(replayingClient, http.MethodPost, server.URL+"/pulls", "second")
+	assert.Equal(t, "POST /pulls second #4", body)
+	body, _ = send(replayingClient, http.MethodPost, server.URL+"/pulls", "third")
+	assert.Equal(t, "POST /pulls first #3", body)
+
+	// Once every matching interaction was replayed, the last one is replayed again
+	send(replayingClient, http.MethodGet, server.URL+"/repos", "")
+	body, _ = send(replayingClient, http.MethodGet, server.URL+"/repos", "")
+	assert.Equal(t, "GET /repos  #2", body)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/user", nil)
+	require.NoError(t, err)
+	_, err = replayingClient.Do(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), types.NoRecordedResponseErr{Method: http.MethodGet, URL: server.URL + "/user"}.Error())
+}
+
+func TestLoadRecordingRejectsInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "git-xargs-recording-*.json")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("not json")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = LoadRecording(file.Name())
+	assert.IsType(t, types.InvalidRecordingErr{}, errors.Unwrap(err))
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxRequestRetries is the number of times a request that failed with a server error or timed out is sent again
+	// before its response or error is returned as is
+	maxRequestRetries = 4
+	// initialRetryBackoff is how long to wait before sending a failed request again the first time. The wait doubles
+	// with each retry, up to maxRetryBackoff
+	initialRetryBackoff = time.Second
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// retryBackoff returns how long to wait before the supplied retry of a request, counting from 0. The wait grows
+// exponentially, and is jittered so that the workers whose requests failed at the same time don't retry in lockstep
+func retryBackoff(retry int) time.Duration {
+	backoff := maxRetryBackoff
+	if retry < 5 {
+		backoff = initialRetryBackoff << uint(retry)
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// idempotentMethods are the HTTP methods whose requests can be sent again after the GitHub API returned a server error
+// for them. The API may have applied a POST or PATCH before it failed, so sending one again could create a second pull
+// request, comment or review
+var idempotentMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// shouldRetry returns true if the supplied request, which got the supplied response or error, may succeed if it is sent
+// again: the request timed out before any response was received, or the GitHub API returned a server error for an
+// idempotent request
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		netErr, ok := err.(net.Error)
+		return ok && netErr.Timeout()
+	}
+	return resp.StatusCode >= http.StatusInternalServerError && idempotentMethods[req.Method]
+}
+
+// retryTransport is an http.RoundTripper that sends the idempotent requests that failed with a server error, and the
+// requests that timed out, via the wrapped RoundTripper again, with exponential backoff. Rate limited requests are retried by rateLimitTransport
+type retryTransport struct {
+	base    http.RoundTripper
+	counter *APICallCounter
+	backoff func(retry int) time.Duration
+}
+
+func (transport *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	for retry := 0; ; retry++ {
+		attemptReq := req
+		if retry > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := transport.base.RoundTrip(attemptReq)
+
+		// Requests whose body can't be read again can't be retried, and neither can those of a cancelled run
+		if retry >= maxRequestRetries || !shouldRetry(req, resp, err) || (req.Body != nil && req.GetBody == nil) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		fields := logrus.Fields{
+			"Method": req.Method,
+			"URL":    req.URL.String(),
+			"Retry":  retry + 1,
+		}
+		if err != nil {
+			fields["Error"] = err
+		} else {
+			fields["Status"] = resp.StatusCode
+			resp.Body.Close()
+		}
+		logger.WithFields(fields).Debug("GitHub API request failed, retrying it")
+
+		transport.counter.countRetry()
+		if err := waitFor(req.Context(), transport.backoff(retry)); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noBackoff(retry int) time.Duration {
+	return 0
+}
+
+func TestRetryTransportRetriesServerErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		method         string
+		statusCodes    []int
+		expectStatus   int
+		expectRequests int
+	}{
+		{"success", http.MethodPut, []int{http.StatusOK}, http.StatusOK, 1},
+		{"client errors aren't retried", http.MethodPut, []int{http.StatusNotFound}, http.StatusNotFound, 1},
+		{"server errors are retried", http.MethodPut, []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusCreated}, http.StatusCreated, 3},
+		{"gives up after the last retry", http.MethodPut, []int{500, 500, 500, 500, 500, 500}, http.StatusInternalServerError, maxRequestRetries + 1},
+		{"server errors of a post aren't retried", http.MethodPost, []int{http.StatusBadGateway, http.StatusCreated}, http.StatusBadGateway, 1},
+		{"server errors of a patch aren't retried", http.MethodPatch, []int{http.StatusGatewayTimeout, http.StatusOK}, http.StatusGatewayTimeout, 1},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				assert.Equal(t, testCase.method, r.Method)
+				assert.Equal(t, "payload", string(body))
+				w.WriteHeader(testCase.statusCodes[requests])
+				requests++
+			}))
+			defer server.Close()
+
+			counter := &APICallCounter{}
+			client := &http.Client{Transport: &retryTransport{base: http.DefaultTransport, counter: counter, backoff: noBackoff}}
+			req, err := http.NewRequest(testCase.method, server.URL, strings.NewReader("payload"))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, testCase.expectStatus, resp.StatusCode)
+			assert.Equal(t, testCase.expectRequests, requests)
+			assert.Equal(t, uint64(testCase.expectRequests-1), counter.Retries())
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	for retry, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, maxRetryBackoff, maxRetryBackoff} {
+		backoff := retryBackoff(retry)
+		assert.True(t, backoff >= expected/2 && backoff <= expected, "retry %d waited %s", retry, backoff)
+	}
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// The rules service reads the rules that the rulesets of a repo and its organization apply to a branch, which go-github
+// doesn't expose. Unlike branch protection, they can be read with read access to the repo
+type githubRulesService interface {
+	GetRulesForBranch(ctx context.Context, owner string, repo string, branch string) ([]*types.BranchRule, *github.Response, error)
+}
+
+// rulesService calls the branch rules endpoint with the go-github client it wraps
+type rulesService struct {
+	client *github.Client
+}
+
+func (service rulesService) GetRulesForBranch(ctx context.Context, owner string, repo string, branch string) ([]*types.BranchRule, *github.Response, error) {
+	req, err := service.client.NewRequest("GET", fmt.Sprintf("repos/%v/%v/rules/branches/%v", owner, repo, url.PathEscape(branch)), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rules []*types.BranchRule
+	resp, err := service.client.Do(ctx, req, &rules)
+	if err != nil {
+		return nil, resp, err
+	}
+	return rules, resp, nil
+}
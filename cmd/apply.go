@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunApply is the urfave cli Action for the apply subcommand. It executes the plan file written by the plan subcommand,
+// as a run with the run ID, branch and commit message recorded in the plan
+func RunApply(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	path := c.Args().First()
+	if path == "" {
+		return errors.WithStackTrace(types.NoPlanFileProvidedErr{})
+	}
+
+	logger.Info("git-xargs applying plan...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	p, err := plan.Read(path)
+	if err != nil {
+		return err
+	}
+
+	config.RunID = p.RunID
+	config.BranchName = p.BranchName
+	config.BaseBranchName = p.BaseBranchName
+	config.CommitMessage = p.CommitMessage
+	config.Draft = p.Draft
+	config.Stats.SetRunID(config.RunID)
+	config.Stats.SetCommand(p.Command)
+	config.Stats.SetDiffPreviewLines(config.ReportDiffLines)
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	// The plan subcommand recorded the run when it created the plan, so applying it keeps that record, like --resume does.
+	// It's only started here if the plan was created with --skip-state or against another state store
+	if _, err := config.State.GetRun(config.RunID); err != nil {
+		if _, isNotFound := errors.Unwrap(err).(types.RunNotFoundErr); !isNotFound {
+			return err
+		}
+		err = config.State.StartRun(state.Run{
+			ID:             config.RunID,
+			Command:        p.Command,
+			BranchName:     config.BranchName,
+			BaseBranchName: config.BaseBranchName,
+			StartedAt:      config.StartTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := openAuditLog(config, p.Command); err != nil {
+		return err
+	}
+	defer config.Audit.Close()
+
+	if err := repository.ApplyPlan(config, p); err != nil {
+		return err
+	}
+
+	if err := config.State.RecordEvents(config.RunID, config.Stats.GetRepos()); err != nil {
+		return err
+	}
+	if err := config.State.FinishRun(config.RunID, time.Now()); err != nil {
+		return err
+	}
+
+	reportErr := writeRunReport(config)
+	if err := finishAuditLog(config); err != nil {
+		return err
+	}
+	return reportErr
+}
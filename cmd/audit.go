@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/audit"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/version"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// openAuditLog opens the audit log of the run in --audit-log-dir, if it was passed, and records the start of the run in
+// it, with the supplied command. The caller is responsible for closing it, via finishAuditLog once the run is done
+func openAuditLog(config *config.GitXargsConfig, command []string) error {
+	if config.AuditLogDir == "" {
+		return nil
+	}
+	logger := logging.GetLogger("git-xargs")
+
+	log, err := audit.Open(config.AuditLogDir, config.RunID, config.AuditLogHashChain)
+	if err != nil {
+		return err
+	}
+	config.Audit = log
+
+	// The login the token belongs to says who made the changes, whoever ran git-xargs
+	user, _, err := config.GithubClient.Users.Get(config.Context, "")
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+		}).Warn("Can't look up the user GITHUB_OAUTH_TOKEN belongs to, so the audit log won't name it")
+	}
+
+	return config.Audit.RunStarted(audit.RunInfo{
+		GitXargsVersion: version.Version,
+		Flags:           invocationFlags(os.Args),
+		Command:         command,
+		Config:          manifestConfig(config),
+		TokenLogin:      user.GetLogin(),
+	})
+}
+
+// invocationFlags returns the names of the flags in the supplied command line, without their values, which may hold
+// secrets such as API headers or webhook URLs. The command run against each repo, after the flags, is left out, since it
+// is recorded on its own
+func invocationFlags(args []string) []string {
+	flags := []string{}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			flags = append(flags, strings.SplitN(arg, "=", 2)[0])
+		}
+	}
+	return flags
+}
+
+// finishAuditLog records the end of the run in its audit log, if --audit-log-dir was passed, and closes it. It returns
+// the first error writing an entry of the log, since a log with entries missing doesn't serve the auditors it is for
+func finishAuditLog(config *config.GitXargsConfig) error {
+	if config.Audit == nil {
+		return nil
+	}
+	config.Audit.RunFinished(config.Stats.CountRepos(), len(config.Stats.GetErrors()))
+	if err := config.Audit.Close(); err != nil {
+		return err
+	}
+
+	fields := logrus.Fields{"Path": audit.Path(config.AuditLogDir, config.RunID)}
+	if lastHash := config.Audit.LastHash(); lastHash != "" {
+		// The hash chain only catches a log cut short or rewritten as a whole if its last hash is kept elsewhere
+		fields["Last hash"] = lastHash
+	}
+	logging.GetLogger("git-xargs").WithFields(fields).Info("Wrote the audit log of the run")
+	return nil
+}
+
+// RunAuditVerify is the urfave cli Action for the audit verify subcommand. It checks the audit log passed as its
+// argument, and returns an error naming the first entry that was edited, removed or reordered. With --last-hash, it also
+// checks that the log ends with the entry of that hash
+func RunAuditVerify(c *cli.Context) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.WithStackTrace(types.NoAuditLogProvidedErr{})
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer file.Close()
+
+	count, err := audit.Verify(file, c.String(common.AuditLastHashFlagName))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.App.Writer, "Verified %d entries of %s\n", count, path)
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// cloneDirPrefix is the prefix of the temporary directories repos are cloned into, see cloneLocalRepository
+const cloneDirPrefix = "git-xargs-"
+
+// RunCleanLocal is the urfave cli Action for the clean local subcommand. It removes the temporary clones left behind by
+// runs that didn't get to clean up after themselves, and the repo names cached for completion. Only those last modified
+// more than --older-than days ago are removed, a day by default, so that the clones of runs that are still going are
+// kept, and with --dry-run, they are only printed
+func RunCleanLocal(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	olderThan := time.Duration(c.Uint(common.OlderThanFlagName)) * 24 * time.Hour
+	now := time.Now()
+
+	artifacts, err := findClonesToClean(os.TempDir(), olderThan, now)
+	if err != nil {
+		return err
+	}
+	cacheDir, err := repoCompletionCacheDir()
+	if err != nil {
+		return err
+	}
+	cacheEntries, err := findCacheEntriesToClean(cacheDir, olderThan, now)
+	if err != nil {
+		return err
+	}
+	artifacts = append(artifacts, cacheEntries...)
+
+	dryRun := c.Bool("dry-run")
+	for _, path := range artifacts {
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return errors.WithStackTrace(err)
+			}
+		}
+		if _, err := fmt.Fprintln(c.App.Writer, path); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+
+	message := "Removed local git-xargs artifacts"
+	if dryRun {
+		message = "Found local git-xargs artifacts to remove, but --dry-run is set"
+	}
+	logger.WithFields(logrus.Fields{
+		"Artifacts": len(artifacts),
+	}).Info(message)
+	return nil
+}
+
+// findClonesToClean returns the paths of the temporary clones in the supplied directory that were last modified more
+// than the supplied duration before now. Directories that merely share the prefix are left alone unless they are git
+// repos, or empty, as a failed clone leaves them
+func findClonesToClean(tempDir string, olderThan time.Duration, now time.Time) ([]string, error) {
+	entries, err := ioutil.ReadDir(tempDir)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), cloneDirPrefix) || now.Sub(entry.ModTime()) < olderThan {
+			continue
+		}
+		path := filepath.Join(tempDir, entry.Name())
+		if isCloneDir(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// isCloneDir returns whether the directory at the supplied path is a git repo, or empty
+func isCloneDir(path string) bool {
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		return true
+	}
+	entries, err := ioutil.ReadDir(path)
+	return err == nil && len(entries) == 0
+}
+
+// findCacheEntriesToClean returns the paths of the files in the supplied cache directory that were last modified more
+// than the supplied duration before now. A cache directory that doesn't exist has nothing to clean
+func findCacheEntriesToClean(cacheDir string, olderThan time.Duration, now time.Time) ([]string, error) {
+	entries, err := ioutil.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.IsDir() || now.Sub(entry.ModTime()) < olderThan {
+			continue
+		}
+		paths = append(paths, filepath.Join(cacheDir, entry.Name()))
+	}
+	return paths, nil
+}
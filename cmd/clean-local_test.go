@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindClonesToClean(t *testing.T) {
+	t.Parallel()
+
+	tempDir, err := ioutil.TempDir("", "clean-local-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	mkdir := func(path string, age time.Duration) {
+		require.NoError(t, os.MkdirAll(filepath.Join(tempDir, path), 0755))
+		modTime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(tempDir, path), modTime, modTime))
+	}
+	mkdir("git-xargs-terratest123/.git", 0)
+	mkdir("git-xargs-terratest123", 10*24*time.Hour)
+	mkdir("git-xargs-cloud-nuke456/.git", 0)
+	mkdir("git-xargs-cloud-nuke456", time.Hour)
+	mkdir("git-xargs-fetch789", 10*24*time.Hour)
+	mkdir("git-xargs-notes/docs", 0)
+	mkdir("git-xargs-notes", 10*24*time.Hour)
+	mkdir("someone-else", 10*24*time.Hour)
+
+	paths, err := findClonesToClean(tempDir, 0, time.Now())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(tempDir, "git-xargs-terratest123"),
+		filepath.Join(tempDir, "git-xargs-cloud-nuke456"),
+		filepath.Join(tempDir, "git-xargs-fetch789"),
+	}, paths)
+
+	paths, err = findClonesToClean(tempDir, 7*24*time.Hour, time.Now())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(tempDir, "git-xargs-terratest123"),
+		filepath.Join(tempDir, "git-xargs-fetch789"),
+	}, paths)
+}
+
+func TestFindCacheEntriesToClean(t *testing.T) {
+	t.Parallel()
+
+	cacheDir, err := ioutil.TempDir("", "clean-local-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	paths, err := findCacheEntriesToClean(filepath.Join(cacheDir, "missing"), 0, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	require.NoError(t, writeCachedRepoNames(filepath.Join(cacheDir, "acme-repos.txt"), []string{"acme/one"}))
+	require.NoError(t, writeCachedRepoNames(filepath.Join(cacheDir, "globex-repos.txt"), []string{"globex/one"}))
+	modTime := time.Now().Add(-30 * 24 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(cacheDir, "globex-repos.txt"), modTime, modTime))
+
+	paths, err = findCacheEntriesToClean(cacheDir, 7*24*time.Hour, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cacheDir, "globex-repos.txt")}, paths)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"regexp"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunCleanupBranches is the urfave cli Action for the cleanup-branches subcommand. It deletes the git-xargs branches in
+// every selected repo whose pull requests have all been merged or closed
+func RunCleanupBranches(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs cleaning up stale branches...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if err := gitxargs_io.EnsureRepoSelectionPassed(config); err != nil {
+		return err
+	}
+
+	if _, err := regexp.Compile(config.BranchPattern); err != nil {
+		return errors.WithStackTrace(types.InvalidBranchPatternErr{Pattern: config.BranchPattern})
+	}
+
+	repos, err := repository.SelectRepos(config)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.CleanupStaleBranches(config, repos); err != nil {
+		return err
+	}
+
+	return writeRunReport(config)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunClose is the urfave cli Action for the close subcommand. It closes every open pull request opened by the run passed
+// via --run-id with a comment, optionally deleting their branches, so that a campaign can be aborted cleanly
+func RunClose(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs closing the run's pull requests...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if !config.RunIDSupplied {
+		return errors.WithStackTrace(types.NoRunIDProvidedErr{Command: "close"})
+	}
+
+	if err := gitxargs_io.EnsureValidTemplate("close-comment", config.CloseComment); err != nil {
+		return err
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	if _, err := config.State.GetRun(config.RunID); err != nil {
+		return err
+	}
+
+	config.Stats.SetRunID(config.RunID)
+
+	if err := repository.CloseRunPullRequests(config); err != nil {
+		return err
+	}
+
+	return writeRunReport(config)
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+const (
+	// completionCacheTTL is how long the repo names of an organization are cached for completing --repo, so that
+	// completing doesn't page through the Github API on every keystroke
+	completionCacheTTL = time.Hour
+	// completionFetchTimeout is the longest completing --repo waits for the Github API when the cache is stale
+	completionFetchTimeout = 10 * time.Second
+)
+
+// completionScripts are the completion scripts printed by the completion subcommand, keyed by shell. Each of them runs
+// git-xargs with the words typed so far and --generate-bash-completion, which urfave cli answers with the subcommands,
+// flags or, via CompleteWithRepos, repos that can come next
+var completionScripts = map[string]string{
+	"bash": `_git_xargs_complete() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  if [[ "$cur" == "-"* ]]; then
+    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} ${cur} --generate-bash-completion )
+  else
+    opts=$( ${COMP_WORDS[@]:0:$COMP_CWORD} --generate-bash-completion )
+  fi
+  COMPREPLY=( $(compgen -W "${opts}" -- ${cur}) )
+  return 0
+}
+
+complete -o bashdefault -o default -o nospace -F _git_xargs_complete git-xargs
+`,
+	"zsh": `#compdef git-xargs
+
+_git_xargs_complete() {
+  local -a opts
+  local cur
+  cur=${words[-1]}
+  if [[ "$cur" == "-"* ]]; then
+    opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${words[@]:0:#words[@]-1} ${cur} --generate-bash-completion)}")
+  else
+    opts=("${(@f)$(_CLI_ZSH_AUTOCOMPLETE_HACK=1 ${words[@]:0:#words[@]-1} --generate-bash-completion)}")
+  fi
+
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+
+compdef _git_xargs_complete git-xargs
+`,
+	"fish": `function __git_xargs_complete
+    set -l words (commandline -opc)
+    set -l cur (commandline -ct)
+    if string match -q -- '-*' $cur
+        $words $cur --generate-bash-completion
+    else
+        $words --generate-bash-completion
+    end
+end
+
+complete -c git-xargs -f -a '(__git_xargs_complete)'
+`,
+}
+
+// RunCompletion is the urfave cli Action for the completion subcommand. It prints the completion script for the shell
+// passed as its argument, to be sourced from the shell's startup file
+func RunCompletion(c *cli.Context) error {
+	shell := c.Args().First()
+	script, ok := completionScripts[shell]
+	if !ok {
+		return errors.WithStackTrace(types.UnsupportedShellErr{Shell: shell})
+	}
+	_, err := fmt.Fprint(c.App.Writer, script)
+	return errors.WithStackTrace(err)
+}
+
+// CompleteWithRepos returns the urfave cli BashComplete func of the supplied subcommand, or of the app itself if it is
+// nil. It completes the value of --repo with the repos of the organization passed via --github-org, and everything else
+// the way urfave cli does by default
+func CompleteWithRepos(command *cli.Command) cli.BashCompleteFunc {
+	return func(c *cli.Context) {
+		if completingFlag(os.Args) == "--"+common.RepoFlagName {
+			printRepoCompletions(c.App.Writer, c.String(common.GithubOrgFlagName))
+			return
+		}
+		cli.DefaultCompleteWithFlags(command)(c)
+	}
+}
+
+// completingFlag returns the flag whose value is being completed, given the args git-xargs was run with by a completion
+// script, or an empty string if the value of no flag is being completed
+func completingFlag(args []string) string {
+	if len(args) < 2 || args[len(args)-1] != "--"+cli.BashCompletionFlag.GetName() {
+		return ""
+	}
+	previous := args[len(args)-2]
+	if !strings.HasPrefix(previous, "-") {
+		return ""
+	}
+	return previous
+}
+
+// printRepoCompletions prints the full names of the repos of the supplied organization, one per line, from the cache if
+// it is fresh, or else from the Github API. Completing prints nothing, rather than failing, if the repos can't be listed
+func printRepoCompletions(writer io.Writer, githubOrg string) {
+	if githubOrg == "" {
+		return
+	}
+
+	cachePath, err := repoCompletionCachePath(githubOrg)
+	if err != nil {
+		return
+	}
+
+	names, fresh := readCachedRepoNames(cachePath, time.Now())
+	if !fresh {
+		names, err = fetchRepoNames(githubOrg)
+		if err != nil {
+			return
+		}
+		_ = writeCachedRepoNames(cachePath, names)
+	}
+
+	for _, name := range names {
+		fmt.Fprintln(writer, name)
+	}
+}
+
+// fetchRepoNames lists the full names of the repos of the supplied organization via the Github API
+func fetchRepoNames(githubOrg string) ([]string, error) {
+	gitxargsConfig := config.NewGitXargsConfig()
+	gitxargsConfig.GithubOrg = githubOrg
+
+	ctx, cancel := context.WithTimeout(gitxargsConfig.Context, completionFetchTimeout)
+	defer cancel()
+	gitxargsConfig.Context = ctx
+
+	return repository.ListOrgRepoNames(gitxargsConfig)
+}
+
+// repoCompletionCachePath returns the path the repo names of the supplied organization are cached at:
+// ~/.git-xargs/completion/<org>-repos.txt
+func repoCompletionCachePath(githubOrg string) (string, error) {
+	cacheDir, err := repoCompletionCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, githubOrg+"-repos.txt"), nil
+}
+
+// repoCompletionCacheDir returns the directory the repo names of every organization are cached in
+func repoCompletionCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return filepath.Join(home, ".git-xargs", "completion"), nil
+}
+
+// readCachedRepoNames returns the repo names cached at the supplied path, and whether the cache was written within
+// completionCacheTTL of the supplied time
+func readCachedRepoNames(path string, now time.Time) ([]string, bool) {
+	info, err := os.Stat(path)
+	if err != nil || now.Sub(info.ModTime()) > completionCacheTTL {
+		return nil, false
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return strings.Fields(string(contents)), true
+}
+
+// writeCachedRepoNames caches the supplied repo names at the supplied path, one per line
+func writeCachedRepoNames(path string, names []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(ioutil.WriteFile(path, []byte(strings.Join(names, "\n")+"\n"), 0600))
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestRunCompletion(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		var stdout strings.Builder
+		app := cli.NewApp()
+		app.Writer = &stdout
+		app.Action = RunCompletion
+
+		require.NoError(t, app.Run([]string{"git-xargs", shell}))
+		assert.Contains(t, stdout.String(), "--generate-bash-completion", shell)
+	}
+
+	app := cli.NewApp()
+	app.Action = RunCompletion
+	err := app.Run([]string{"git-xargs", "powershell"})
+	assert.IsType(t, types.UnsupportedShellErr{}, errors.Unwrap(err))
+}
+
+func TestCompletingFlag(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "--repo", completingFlag([]string{"git-xargs", "--github-org", "acme", "--repo", "--generate-bash-completion"}))
+	assert.Equal(t, "", completingFlag([]string{"git-xargs", "--github-org", "acme", "--generate-bash-completion"}))
+	assert.Equal(t, "", completingFlag([]string{"git-xargs", "--repo"}))
+}
+
+func TestCachedRepoNames(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-completion")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "completion", "acme-repos.txt")
+
+	_, fresh := readCachedRepoNames(path, time.Now())
+	assert.False(t, fresh)
+
+	require.NoError(t, writeCachedRepoNames(path, []string{"acme/api", "acme/web"}))
+	names, fresh := readCachedRepoNames(path, time.Now())
+	assert.True(t, fresh)
+	assert.Equal(t, []string{"acme/api", "acme/web"}, names)
+
+	// The cache goes stale after completionCacheTTL
+	_, fresh = readCachedRepoNames(path, time.Now().Add(completionCacheTTL+time.Minute))
+	assert.False(t, fresh)
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// parseTOMLConfigFile returns the keys set at the top level of the supplied TOML config file, and the keys set in each
+// of its [profiles.<name>] tables, keyed by profile name. Config files only hold flags, so only the part of TOML that
+// flags need is supported: strings, integers, floats, booleans and arrays of those, and no tables other than profiles
+func parseTOMLConfigFile(path string, contents string) ([]configFileEntry, map[string][]configFileEntry, error) {
+	parser := &tomlParser{path: path, data: contents, line: 1}
+
+	var topLevel []configFileEntry
+	var profiles map[string][]configFileEntry
+	// The profile whose table the parser is in, or an empty string at the top level
+	profile := ""
+	seen := map[string]bool{}
+
+	for {
+		parser.skipBlankLines()
+		if parser.done() {
+			break
+		}
+
+		if parser.peek() == '[' {
+			line := parser.line
+			table, err := parser.parseTableHeader()
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(table) != 2 || table[0] != configFileProfilesKey {
+				return nil, nil, parser.errorOnLine(line, "only [%s.<name>] tables are supported, not [%s]", configFileProfilesKey, strings.Join(table, "."))
+			}
+			if profiles == nil {
+				profiles = map[string][]configFileEntry{}
+			}
+			if _, ok := profiles[table[1]]; ok {
+				return nil, nil, parser.errorOnLine(line, "the profile %s is defined more than once", table[1])
+			}
+			profile = table[1]
+			profiles[profile] = []configFileEntry{}
+			seen = map[string]bool{}
+			continue
+		}
+
+		entry, err := parser.parseKeyValue()
+		if err != nil {
+			return nil, nil, err
+		}
+		if seen[entry.key] {
+			return nil, nil, parser.errorOnLine(entry.line, "%s is set more than once", entry.key)
+		}
+		seen[entry.key] = true
+		if profile == "" {
+			topLevel = append(topLevel, entry)
+		} else {
+			profiles[profile] = append(profiles[profile], entry)
+		}
+	}
+
+	return topLevel, profiles, nil
+}
+
+// tomlParser reads a TOML document one character at a time, keeping track of the line it is on for error messages
+type tomlParser struct {
+	path string
+	data string
+	pos  int
+	line int
+}
+
+func (parser *tomlParser) done() bool {
+	return parser.pos >= len(parser.data)
+}
+
+func (parser *tomlParser) peek() byte {
+	if parser.done() {
+		return 0
+	}
+	return parser.data[parser.pos]
+}
+
+func (parser *tomlParser) next() byte {
+	char := parser.peek()
+	parser.pos++
+	if char == '\n' {
+		parser.line++
+	}
+	return char
+}
+
+func (parser *tomlParser) errorOnLine(line int, format string, args ...interface{}) error {
+	return errors.WithStackTrace(types.InvalidConfigFileErr{File: parser.path, Line: line, Err: fmt.Errorf(format, args...)})
+}
+
+// skipSpaces skips spaces and tabs, and a comment up to the end of the line
+func (parser *tomlParser) skipSpaces() {
+	for !parser.done() {
+		switch parser.peek() {
+		case ' ', '\t':
+			parser.next()
+		case '#':
+			for !parser.done() && parser.peek() != '\n' {
+				parser.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// skipBlankLines skips whitespace, comments and line breaks
+func (parser *tomlParser) skipBlankLines() {
+	for {
+		parser.skipSpaces()
+		if parser.peek() != '\n' && parser.peek() != '\r' {
+			return
+		}
+		parser.next()
+	}
+}
+
+// endLine expects nothing but whitespace and a comment up to the end of the current line
+func (parser *tomlParser) endLine() error {
+	parser.skipSpaces()
+	if parser.peek() == '\r' {
+		parser.next()
+	}
+	if parser.done() || parser.peek() == '\n' {
+		return nil
+	}
+	return parser.errorOnLine(parser.line, "unexpected %q", parser.peek())
+}
+
+// parseTableHeader parses a [table] header, and returns the parts of its name
+func (parser *tomlParser) parseTableHeader() ([]string, error) {
+	parser.next()
+	if parser.peek() == '[' {
+		return nil, parser.errorOnLine(parser.line, "arrays of tables are not supported")
+	}
+
+	parts := []string{}
+	for {
+		parser.skipSpaces()
+		part, err := parser.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		parser.skipSpaces()
+
+		switch parser.next() {
+		case '.':
+			continue
+		case ']':
+			return parts, parser.endLine()
+		default:
+			return nil, parser.errorOnLine(parser.line, "expected ] to close the table header")
+		}
+	}
+}
+
+// parseKeyValue parses a key = value line
+func (parser *tomlParser) parseKeyValue() (configFileEntry, error) {
+	line := parser.line
+	key, err := parser.parseKey()
+	if err != nil {
+		return configFileEntry{}, err
+	}
+	parser.skipSpaces()
+	if parser.peek() == '.' {
+		return configFileEntry{}, parser.errorOnLine(line, "dotted keys are not supported, set %s in a table instead", key)
+	}
+	if parser.next() != '=' {
+		return configFileEntry{}, parser.errorOnLine(line, "expected = after %s", key)
+	}
+	parser.skipSpaces()
+
+	value, err := parser.parseValue()
+	if err != nil {
+		return configFileEntry{}, err
+	}
+	return configFileEntry{key: key, value: value, line: line}, parser.endLine()
+}
+
+// parseKey parses a bare or quoted key
+func (parser *tomlParser) parseKey() (string, error) {
+	switch parser.peek() {
+	case '"':
+		return parser.parseBasicString()
+	case '\'':
+		return parser.parseLiteralString()
+	}
+
+	start := parser.pos
+	for !parser.done() && isBareKeyChar(parser.peek()) {
+		parser.next()
+	}
+	if parser.pos == start {
+		return "", parser.errorOnLine(parser.line, "expected a key")
+	}
+	return parser.data[start:parser.pos], nil
+}
+
+func isBareKeyChar(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9') || char == '-' || char == '_'
+}
+
+// parseValue parses a string, integer, float, boolean or array
+func (parser *tomlParser) parseValue() (interface{}, error) {
+	switch parser.peek() {
+	case '"':
+		return parser.parseBasicString()
+	case '\'':
+		return parser.parseLiteralString()
+	case '[':
+		return parser.parseArray()
+	case '{':
+		return nil, parser.errorOnLine(parser.line, "inline tables are not supported")
+	}
+
+	start := parser.pos
+	for !parser.done() && !strings.ContainsRune(" \t\r\n,]#", rune(parser.peek())) {
+		parser.next()
+	}
+	token := parser.data[start:parser.pos]
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "":
+		return nil, parser.errorOnLine(parser.line, "expected a value")
+	}
+
+	number := strings.Replace(token, "_", "", -1)
+	unsigned := strings.TrimLeft(number, "+-")
+	if len(unsigned) > 1 && unsigned[0] == '0' && unsigned[1] >= '0' && unsigned[1] <= '9' {
+		return nil, parser.errorOnLine(parser.line, "leading zeros are not allowed in %s", token)
+	}
+	if integer, err := strconv.ParseInt(number, 0, 64); err == nil {
+		return integer, nil
+	}
+	if float, err := strconv.ParseFloat(number, 64); err == nil {
+		return float, nil
+	}
+	return nil, parser.errorOnLine(parser.line, "%s is not a string, number, boolean or array. Quote strings", token)
+}
+
+// parseBasicString parses a "double-quoted" string, unescaping it
+func (parser *tomlParser) parseBasicString() (string, error) {
+	line := parser.line
+	if strings.HasPrefix(parser.data[parser.pos:], `"""`) {
+		return "", parser.errorOnLine(line, "multi-line strings are not supported")
+	}
+
+	start := parser.pos
+	parser.next()
+	for {
+		if parser.done() || parser.peek() == '\n' {
+			return "", parser.errorOnLine(line, "unterminated string")
+		}
+		char := parser.next()
+		if char == '\\' {
+			parser.next()
+			continue
+		}
+		if char == '"' {
+			break
+		}
+	}
+
+	value, err := strconv.Unquote(parser.data[start:parser.pos])
+	if err != nil {
+		return "", parser.errorOnLine(line, "invalid escape in %s", parser.data[start:parser.pos])
+	}
+	return value, nil
+}
+
+// parseLiteralString parses a 'single-quoted' string, which has no escapes
+func (parser *tomlParser) parseLiteralString() (string, error) {
+	line := parser.line
+	if strings.HasPrefix(parser.data[parser.pos:], `'''`) {
+		return "", parser.errorOnLine(line, "multi-line strings are not supported")
+	}
+
+	parser.next()
+	start := parser.pos
+	for parser.peek() != '\'' {
+		if parser.done() || parser.peek() == '\n' {
+			return "", parser.errorOnLine(line, "unterminated string")
+		}
+		parser.next()
+	}
+	value := parser.data[start:parser.pos]
+	parser.next()
+	return value, nil
+}
+
+// parseArray parses an array, which may span multiple lines and end with a trailing comma
+func (parser *tomlParser) parseArray() ([]interface{}, error) {
+	line := parser.line
+	parser.next()
+
+	values := []interface{}{}
+	for {
+		parser.skipBlankLines()
+		if parser.done() {
+			return nil, parser.errorOnLine(line, "unterminated array")
+		}
+		if parser.peek() == ']' {
+			parser.next()
+			return values, nil
+		}
+
+		value, err := parser.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		parser.skipBlankLines()
+		switch parser.peek() {
+		case ',':
+			parser.next()
+		case ']':
+		default:
+			return nil, parser.errorOnLine(parser.line, "expected , or ] in the array")
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileCommandKey is the key of a config file that holds the command to run against each repo, as a list of
+	// the command and its arguments, or as a single executable
+	configFileCommandKey = "command"
+	// configFileProfilesKey is the key of a config file that holds its profiles, keyed by name. Each profile holds flags,
+	// just like the top level of the config file
+	configFileProfilesKey = "profiles"
+)
+
+// defaultConfigFiles are the config files looked for in the working directory when --config isn't passed. They are
+// named apart from the .git-xargs.yml files committed to repos, which configure how changes land in those repos
+var defaultConfigFiles = []string{"git-xargs.config.yml", "git-xargs.config.yaml", "git-xargs.config.toml"}
+
+// configFileOnlyFlags are the flags that can't be set in a config file, because they are read before it, or say where
+// it is
+var configFileOnlyFlags = map[string]bool{
+	common.ConfigFileFlagName: true,
+	common.ProfileFlagName:    true,
+	"log-level":               true,
+	"loglevel":                true,
+	"quiet":                   true,
+	"log-file":                true,
+}
+
+// findConfigFile returns the path of the config file passed via --config, or else of the first of defaultConfigFiles
+// that exists in the working directory, or an empty string if there is neither. The returned bool is true if the path
+// was passed via --config
+func findConfigFile(c *cli.Context) (string, bool) {
+	if path := c.String(common.ConfigFileFlagName); path != "" {
+		return path, true
+	}
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path, false
+		}
+	}
+	return "", false
+}
+
+// hasCommand returns true if the command to run against each repo was passed on the command line, or may be set in the
+// config file passed via --config, or if files to change via --put-file, --delete-file or --move-file were passed in
+// its place
+func hasCommand(c *cli.Context) bool {
+	return c.Args().Present() || c.String(common.ConfigFileFlagName) != "" || len(c.StringSlice(common.PutFileFlagName)) > 0 || len(c.StringSlice(common.DeleteFileFlagName)) > 0 || len(c.StringSlice(common.MoveFileFlagName)) > 0
+}
+
+// applyConfigFile sets each flag in the config file of the run that wasn't passed on the command line, as if it was,
+// and returns the command the config file holds, if any. Flags the subcommand being run doesn't accept are left out, so
+// that the same config file can be shared between subcommands, but keys that are no git-xargs flag at all are an error,
+// to catch typos. Only a config file passed via --config may set the command, so that a config file that happens to be
+// in the directory git-xargs is run from can't make it run commands
+func applyConfigFile(c *cli.Context) ([]string, error) {
+	path, explicit := findConfigFile(c)
+	profile := c.String(common.ProfileFlagName)
+	if path == "" {
+		if profile != "" {
+			return nil, errors.WithStackTrace(types.UnknownProfileErr{Profile: profile})
+		}
+		return nil, nil
+	}
+
+	entries, err := readConfigFile(path, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Config file": path,
+		"Profile":     profile,
+	}).Debug("Reading flags from the config file")
+
+	knownFlags := appFlags(c.App)
+
+	var command []string
+	for _, entry := range entries {
+		if entry.key == configFileCommandKey {
+			if !explicit {
+				return nil, errors.WithStackTrace(types.DiscoveredConfigFileCommandErr{File: path, Line: entry.line})
+			}
+			command, err = configFileValues(path, entry, true)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		flag, ok := knownFlags[entry.key]
+		if !ok || configFileOnlyFlags[entry.key] {
+			return nil, errors.WithStackTrace(types.UnknownConfigFileKeyErr{
+				File:       path,
+				Line:       entry.line,
+				Key:        entry.key,
+				Suggestion: closestFlagName(entry.key, knownFlags),
+			})
+		}
+		flagValues, err := configFileValues(path, entry, isSliceFlag(flag))
+		if err != nil {
+			return nil, err
+		}
+
+		// Check the values against the type of the flag, so that a bad value is reported with its line, rather than
+		// mistaken for a flag the subcommand being run doesn't accept
+		for _, value := range flagValues {
+			if reason := checkFlagValue(flag, value); reason != "" {
+				return nil, errors.WithStackTrace(types.InvalidConfigFileValueErr{File: path, Line: entry.line, Key: entry.key, Reason: reason})
+			}
+		}
+
+		// Flags passed on the command line take precedence over the config file
+		if c.IsSet(entry.key) {
+			continue
+		}
+		for _, value := range flagValues {
+			if err := c.Set(entry.key, value); err != nil {
+				// The subcommand being run doesn't accept this flag
+				logger.WithFields(logrus.Fields{
+					"Config file": path,
+					"Flag":        entry.key,
+				}).Debug("Ignoring a flag of the config file that this subcommand doesn't accept")
+				break
+			}
+		}
+	}
+	return command, nil
+}
+
+// configFileEntry is a key set in a config file, along with its value and the line it is set on
+type configFileEntry struct {
+	key   string
+	value interface{}
+	line  int
+}
+
+// readConfigFile returns the keys set at the top level of the config file at the supplied path, sorted by name, so that
+// errors about them don't depend on the order of the file. If a profile was passed, its keys take precedence over the
+// ones at the top level. Files ending in .toml are read as TOML, and every other file as YAML
+func readConfigFile(path string, profile string) ([]configFileEntry, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var topLevel []configFileEntry
+	var profiles map[string][]configFileEntry
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		topLevel, profiles, err = parseTOMLConfigFile(path, string(contents))
+	} else {
+		topLevel, profiles, err = parseYAMLConfigFile(path, contents, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]configFileEntry{}
+	for _, entry := range topLevel {
+		entries[entry.key] = entry
+	}
+
+	if profile != "" {
+		profileEntries, ok := profiles[profile]
+		if !ok {
+			return nil, errors.WithStackTrace(types.UnknownProfileErr{File: path, Profile: profile})
+		}
+		for _, entry := range profileEntries {
+			entries[entry.key] = entry
+		}
+	}
+
+	keys := []string{}
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := []configFileEntry{}
+	for _, key := range keys {
+		sorted = append(sorted, entries[key])
+	}
+	return sorted, nil
+}
+
+// parseYAMLConfigFile returns the keys set at the top level of the supplied YAML config file, other than profiles, and
+// the keys set in the supplied profile, if the file defines it. Other profiles are left unread
+func parseYAMLConfigFile(path string, contents []byte, profile string) ([]configFileEntry, map[string][]configFileEntry, error) {
+	document := yaml.Node{}
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, nil, errors.WithStackTrace(types.InvalidConfigFileErr{File: path, Err: err})
+	}
+
+	// An empty config file sets nothing, but still has to define the profile, if one was passed
+	if len(document.Content) == 0 {
+		return nil, nil, nil
+	}
+	entries, err := configFileEntries(path, document.Content[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	topLevel := []configFileEntry{}
+	for _, entry := range entries {
+		if entry.key != configFileProfilesKey {
+			topLevel = append(topLevel, entry)
+		}
+	}
+
+	profiles := map[string][]configFileEntry{}
+	profilesNode := mappingValue(document.Content[0], configFileProfilesKey)
+	if profile == "" || profilesNode == nil {
+		return topLevel, profiles, nil
+	}
+	profileNode := mappingValue(profilesNode, profile)
+	if profileNode == nil {
+		return topLevel, profiles, nil
+	}
+
+	// A profile without any flags is empty, rather than an error
+	profiles[profile] = []configFileEntry{}
+	if profileNode.Tag != "!!null" {
+		profiles[profile], err = configFileEntries(path, profileNode)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return topLevel, profiles, nil
+}
+
+// configFileEntries returns the keys set in the supplied mapping node of the config file at the supplied path
+func configFileEntries(path string, node *yaml.Node) ([]configFileEntry, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, errors.WithStackTrace(types.InvalidConfigFileErr{File: path, Line: node.Line, Err: fmt.Errorf("expected flag names mapped to their values")})
+	}
+
+	entries := []configFileEntry{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		var value interface{}
+		if err := valueNode.Decode(&value); err != nil {
+			return nil, errors.WithStackTrace(types.InvalidConfigFileErr{File: path, Line: valueNode.Line, Err: err})
+		}
+		entries = append(entries, configFileEntry{key: keyNode.Value, value: value, line: keyNode.Line})
+	}
+	return entries, nil
+}
+
+// mappingValue returns the value of the supplied key in the supplied mapping node, or nil if the key isn't set
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// configFileValues returns the value of the supplied config file entry, as the values to set the flag of its key to.
+// Only flags that can be passed multiple times take a list
+func configFileValues(path string, entry configFileEntry, list bool) ([]string, error) {
+	items, isList := entry.value.([]interface{})
+	if !isList {
+		items = []interface{}{entry.value}
+	} else if !list {
+		return nil, errors.WithStackTrace(types.InvalidConfigFileValueErr{File: path, Line: entry.line, Key: entry.key, Reason: "takes a single value, not a list"})
+	}
+
+	values := []string{}
+	for _, item := range items {
+		switch item.(type) {
+		case string, bool, int, int64, uint64, float64:
+			values = append(values, fmt.Sprint(item))
+		default:
+			return nil, errors.WithStackTrace(types.InvalidConfigFileValueErr{File: path, Line: entry.line, Key: entry.key, Reason: "takes a string, number or boolean"})
+		}
+	}
+	return values, nil
+}
+
+// appFlags returns every flag of the supplied app and its subcommands, keyed by name
+func appFlags(app *cli.App) map[string]cli.Flag {
+	flags := map[string]cli.Flag{}
+	addFlags := func(appFlags []cli.Flag) {
+		for _, flag := range appFlags {
+			for _, name := range strings.Split(flag.GetName(), ",") {
+				flags[strings.TrimSpace(name)] = flag
+			}
+		}
+	}
+
+	var addCommands func(commands []cli.Command)
+	addCommands = func(commands []cli.Command) {
+		for _, command := range commands {
+			addFlags(command.Flags)
+			addCommands(command.Subcommands)
+		}
+	}
+
+	addFlags(app.Flags)
+	addCommands(app.Commands)
+	return flags
+}
+
+// isSliceFlag returns true if the supplied flag can be passed multiple times
+func isSliceFlag(flag cli.Flag) bool {
+	switch flag.(type) {
+	case cli.StringSliceFlag, *cli.StringSliceFlag, cli.IntSliceFlag, *cli.IntSliceFlag, cli.Int64SliceFlag, *cli.Int64SliceFlag:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkFlagValue returns why the supplied value can't be set on the supplied flag, or an empty string if it can
+func checkFlagValue(flag cli.Flag, value string) string {
+	var err error
+	var expected string
+	switch flag.(type) {
+	case cli.BoolFlag, *cli.BoolFlag, cli.BoolTFlag, *cli.BoolTFlag:
+		_, err = strconv.ParseBool(value)
+		expected = "true or false"
+	case cli.IntFlag, *cli.IntFlag, cli.Int64Flag, *cli.Int64Flag, cli.IntSliceFlag, *cli.IntSliceFlag, cli.Int64SliceFlag, *cli.Int64SliceFlag:
+		_, err = strconv.ParseInt(value, 0, 64)
+		expected = "an integer"
+	case cli.UintFlag, *cli.UintFlag, cli.Uint64Flag, *cli.Uint64Flag:
+		_, err = strconv.ParseUint(value, 0, 64)
+		expected = "a non-negative integer"
+	case cli.Float64Flag, *cli.Float64Flag:
+		_, err = strconv.ParseFloat(value, 64)
+		expected = "a number"
+	case cli.DurationFlag, *cli.DurationFlag:
+		_, err = time.ParseDuration(value)
+		expected = "a duration, such as 10m or 1h30m"
+	}
+	if err != nil {
+		return fmt.Sprintf("takes %s, but is set to %q", expected, value)
+	}
+	return ""
+}
+
+// closestFlagName returns the name of the known flag the supplied key is most likely a typo of, or an empty string if
+// none is close enough
+func closestFlagName(key string, knownFlags map[string]cli.Flag) string {
+	closest := ""
+	closestDistance := 3
+	for name := range knownFlags {
+		if configFileOnlyFlags[name] {
+			continue
+		}
+		distance := editDistance(key, name)
+		if distance < closestDistance || (distance == closestDistance && closest != "" && name < closest) {
+			closest, closestDistance = name, distance
+		}
+	}
+	if closestDistance >= 3 {
+		return ""
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between the supplied strings
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+// runWithConfigFile runs an app with a few of the git-xargs flags, and the supplied YAML config file and command line
+// args, and returns the context its action was called with, along with the command of the config file
+func runWithConfigFile(t *testing.T, contents string, args ...string) (*cli.Context, []string, error) {
+	path := filepath.Join(t.TempDir(), "git-xargs.config.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return runConfigFileApp(append([]string{"--config", path}, args...)...)
+}
+
+// runConfigFileApp runs an app with a few of the git-xargs flags and the supplied command line args, and returns the
+// context its action was called with, along with the command of the config file
+func runConfigFileApp(args ...string) (*cli.Context, []string, error) {
+	var actionContext *cli.Context
+	var command []string
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		common.GenericConfigFileFlag,
+		common.GenericProfileFlag,
+		common.GenericBranchFlag,
+		common.GenericRepoFlag,
+		common.GenericDryRunFlag,
+		common.GenericRepoTimeoutFlag,
+	}
+	app.Commands = []cli.Command{{Name: "watch", Flags: []cli.Flag{common.GenericScheduleFlag}}}
+	app.Action = func(c *cli.Context) error {
+		actionContext = c
+		fileCommand, applyErr := applyConfigFile(c)
+		command = fileCommand
+		return applyErr
+	}
+
+	runErr := app.Run(append([]string{"git-xargs"}, args...))
+	return actionContext, command, runErr
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	t.Parallel()
+
+	c, command, err := runWithConfigFile(t, `
+branch-name: from-file
+repo:
+  - gruntwork-io/git-xargs
+  - gruntwork-io/terratest
+dry-run: true
+repo-timeout: 10m
+schedule: "@daily"
+command: ["./scripts/upgrade.sh", "--version", 2]
+`, "--branch-name", "from-command-line")
+	require.NoError(t, err)
+
+	// Flags passed on the command line take precedence
+	assert.Equal(t, "from-command-line", c.String("branch-name"))
+	assert.Equal(t, []string{"gruntwork-io/git-xargs", "gruntwork-io/terratest"}, c.StringSlice("repo"))
+	assert.True(t, c.Bool("dry-run"))
+	assert.Equal(t, 10*time.Minute, c.Duration("repo-timeout"))
+	assert.Equal(t, []string{"./scripts/upgrade.sh", "--version", "2"}, command)
+}
+
+func TestApplyConfigFileProfile(t *testing.T) {
+	t.Parallel()
+
+	contents := `
+branch-name: from-top-level
+dry-run: true
+profiles:
+  sandbox:
+    branch-name: from-sandbox
+    repo: [sandbox-org/test-repo]
+  prod-org:
+`
+	c, _, err := runWithConfigFile(t, contents, "--profile", "sandbox")
+	require.NoError(t, err)
+
+	// The profile takes precedence over the top level of the file
+	assert.Equal(t, "from-sandbox", c.String("branch-name"))
+	assert.Equal(t, []string{"sandbox-org/test-repo"}, c.StringSlice("repo"))
+	assert.True(t, c.Bool("dry-run"))
+
+	c, _, err = runWithConfigFile(t, contents, "--profile", "prod-org")
+	require.NoError(t, err)
+	assert.Equal(t, "from-top-level", c.String("branch-name"))
+
+	_, _, err = runWithConfigFile(t, contents, "--profile", "staging")
+	assert.IsType(t, types.UnknownProfileErr{}, errors.Unwrap(err))
+}
+
+// Test that environment variables take precedence over the config file, and GITXARGS_* variables over GIT_XARGS_* ones.
+// Not parallel, since it sets environment variables the other tests would see
+func TestApplyConfigFileUnderEnvVars(t *testing.T) {
+	for name, value := range map[string]string{
+		"GITXARGS_REPO_TIMEOUT":  "5m",
+		"GIT_XARGS_REPO_TIMEOUT": "7m",
+		"GIT_XARGS_BRANCH_NAME":  "from-env",
+	} {
+		require.NoError(t, os.Setenv(name, value))
+		defer os.Unsetenv(name)
+	}
+
+	c, _, err := runWithConfigFile(t, "repo-timeout: 10m\nbranch-name: from-file\ndry-run: true\n")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Minute, c.Duration("repo-timeout"))
+	assert.Equal(t, "from-env", c.String("branch-name"))
+	assert.True(t, c.Bool("dry-run"))
+}
+
+func TestApplyConfigFileRejectsInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := runWithConfigFile(t, "brnach-name: typo\n")
+	unknownKeyErr, ok := errors.Unwrap(err).(types.UnknownConfigFileKeyErr)
+	require.True(t, ok)
+	assert.Equal(t, "brnach-name", unknownKeyErr.Key)
+
+	_, _, err = runWithConfigFile(t, "loglevel: debug\n")
+	assert.IsType(t, types.UnknownConfigFileKeyErr{}, errors.Unwrap(err))
+
+	_, _, err = runWithConfigFile(t, "branch-name: [one, two]\n")
+	assert.IsType(t, types.InvalidConfigFileValueErr{}, errors.Unwrap(err))
+}
+
+// Test that errors about the config file point at the line of the offending key, and suggest the flag a typo was meant
+// to be
+func TestApplyConfigFileReportsLocations(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := runWithConfigFile(t, "dry-run: true\nbrnach-name: typo\n")
+	unknownKeyErr, ok := errors.Unwrap(err).(types.UnknownConfigFileKeyErr)
+	require.True(t, ok)
+	assert.Equal(t, 2, unknownKeyErr.Line)
+	assert.Equal(t, "branch-name", unknownKeyErr.Suggestion)
+
+	_, _, err = runWithConfigFile(t, "completely-unrelated: true\n")
+	unknownKeyErr, ok = errors.Unwrap(err).(types.UnknownConfigFileKeyErr)
+	require.True(t, ok)
+	assert.Empty(t, unknownKeyErr.Suggestion)
+
+	_, _, err = runWithConfigFile(t, "branch-name: ok\nprofiles:\n  sandbox:\n    repo-timeout: ten minutes\n", "--profile", "sandbox")
+	invalidValueErr, ok := errors.Unwrap(err).(types.InvalidConfigFileValueErr)
+	require.True(t, ok)
+	assert.Equal(t, 4, invalidValueErr.Line)
+	assert.Equal(t, "repo-timeout", invalidValueErr.Key)
+
+	_, _, err = runWithConfigFile(t, "dry-run: maybe\n")
+	assert.IsType(t, types.InvalidConfigFileValueErr{}, errors.Unwrap(err))
+
+	_, _, err = runWithConfigFile(t, "branch-name: ok\n  repo: [indented\n")
+	assert.IsType(t, types.InvalidConfigFileErr{}, errors.Unwrap(err))
+}
+
+func TestApplyTOMLConfigFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "campaign.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`# The CI upgrade campaign
+branch-name = "from-top-level"
+repo = [
+  "gruntwork-io/git-xargs", # The tool itself
+  'gruntwork-io/terratest',
+]
+repo-timeout = "10m"
+command = ["./scripts/upgrade.sh", "--version", 2]
+
+[profiles.sandbox]
+branch-name = "from-sandbox"
+dry-run = true
+`), 0644))
+
+	c, command, err := runConfigFileApp("--config", path, "--profile", "sandbox")
+	require.NoError(t, err)
+	assert.Equal(t, "from-sandbox", c.String("branch-name"))
+	assert.Equal(t, []string{"gruntwork-io/git-xargs", "gruntwork-io/terratest"}, c.StringSlice("repo"))
+	assert.True(t, c.Bool("dry-run"))
+	assert.Equal(t, 10*time.Minute, c.Duration("repo-timeout"))
+	assert.Equal(t, []string{"./scripts/upgrade.sh", "--version", "2"}, command)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("dry-run = true\n[settings]\nbranch-name = \"nope\"\n"), 0644))
+	_, _, err = runConfigFileApp("--config", path)
+	invalidErr, ok := errors.Unwrap(err).(types.InvalidConfigFileErr)
+	require.True(t, ok)
+	assert.Equal(t, 2, invalidErr.Line)
+}
+
+// Test that a config file picked up from the working directory sets flags, but can't set the command. Not parallel,
+// since it changes the working directory
+func TestApplyDiscoveredConfigFile(t *testing.T) {
+	workingDir, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(workingDir)
+
+	require.NoError(t, ioutil.WriteFile("git-xargs.config.yml", []byte("branch-name: discovered\n"), 0644))
+	c, _, err := runConfigFileApp()
+	require.NoError(t, err)
+	assert.Equal(t, "discovered", c.String("branch-name"))
+
+	require.NoError(t, ioutil.WriteFile("git-xargs.config.yml", []byte("branch-name: discovered\ncommand: [\"./evil.sh\"]\n"), 0644))
+	_, _, err = runConfigFileApp("touch", "file.txt")
+	commandErr, ok := errors.Unwrap(err).(types.DiscoveredConfigFileCommandErr)
+	require.True(t, ok)
+	assert.Equal(t, 2, commandErr.Line)
+
+	_, command, err := runConfigFileApp("--config", "git-xargs.config.yml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"./evil.sh"}, command)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// RunContinue is the urfave cli Action for the continue subcommand. It processes the repos that the run passed via
+// --run-id queued because --max-open-prs pull requests were open, as a resumed run with the same run ID, branch and
+// base branch. The repos are taken from the state store instead of the repo selection flags, and the command, commit
+// message, pull request title and pull request description default to the ones the run recorded. Passing
+// --max-open-prs again queues the repos that still don't fit for the next continue
+func RunContinue(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if !config.RunIDSupplied {
+		return errors.WithStackTrace(types.NoRunIDProvidedErr{Command: "continue"})
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	run, err := config.State.GetRun(config.RunID)
+	if err != nil {
+		return err
+	}
+	queued, err := config.State.ListQueuedRepos(config.RunID)
+	if err != nil {
+		return err
+	}
+	if len(queued) == 0 {
+		logger.WithFields(logrus.Fields{
+			"Run ID": config.RunID,
+		}).Info("The run has no queued repos left to continue with")
+		return nil
+	}
+
+	config.Resume = true
+	config.RepoSlice = queued
+	config.RepoFromStdIn = []string{}
+	config.ReposFile = ""
+	config.GithubOrg = ""
+	if len(config.Args) == 0 {
+		config.Args = run.Command
+	}
+	restoreRunMessages(c, config, run)
+
+	if err := sanityCheckInputs(config); err != nil {
+		return err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Run ID":       config.RunID,
+		"Queued repos": len(queued),
+	}).Info("git-xargs continuing with the queued repos of the run...")
+
+	if err := gitxargs.OpenEventStream(config); err != nil {
+		return err
+	}
+	defer config.Events.Close()
+
+	return handleRepoProcessing(config)
+}
+
+// restoreRunMessages sets the commit message, pull request title and pull request description of the supplied config to
+// the ones the supplied run recorded, unless they were passed again, so that the pull requests opened by continue read
+// like the ones the run opened before
+func restoreRunMessages(c *cli.Context, config *config.GitXargsConfig, run *state.Run) {
+	if run.CommitMessage != "" && !c.IsSet(common.CommitMessageFlagName) {
+		config.CommitMessage = run.CommitMessage
+	}
+	if run.PullRequestTitle != "" && !c.IsSet(common.PullRequestTitleFlagName) {
+		config.PullRequestTitle = run.PullRequestTitle
+	}
+	if run.PullRequestDescription != "" && !c.IsSet(common.PullRequestDescriptionFlagName) {
+		config.PullRequestDescription = run.PullRequestDescription
+	}
+}
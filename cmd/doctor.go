@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/notify"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+const (
+	// Below either of these, the disk repos are cloned to is considered too full for a run
+	minDoctorDiskFreeBytes    = 1 << 30
+	minDoctorDiskFreeFraction = 0.05
+	// githubScopesHeader is the header GitHub lists the scopes of a classic token in. Fine-grained tokens have no scopes,
+	// so GitHub leaves it out
+	githubScopesHeader = "X-OAuth-Scopes"
+)
+
+// doctorStatus is the outcome of one of the checks of the doctor subcommand
+type doctorStatus string
+
+const (
+	doctorOK      doctorStatus = "ok"
+	doctorWarning doctorStatus = "warning"
+	doctorProblem doctorStatus = "problem"
+)
+
+// doctorCheck is the outcome of one of the checks of the doctor subcommand, along with what to do about it
+type doctorCheck struct {
+	Name    string
+	Status  doctorStatus
+	Details string
+}
+
+// RunDoctor is the urfave cli Action for the doctor subcommand. It checks everything a run with the same flags and
+// command needs before any repo is touched, and prints what to do about each problem it finds
+func RunDoctor(c *cli.Context) error {
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	checks := runDoctorChecks(config)
+	printDoctorChecks(c.App.Writer, checks)
+
+	problems := 0
+	for _, check := range checks {
+		if check.Status == doctorProblem {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return errors.WithStackTrace(types.DoctorFoundProblemsErr{Problems: problems})
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check of the doctor subcommand against the supplied config
+func runDoctorChecks(config *config.GitXargsConfig) []doctorCheck {
+	checks := checkGithubAPI(config)
+	if config.GithubOrg != "" {
+		checks = append(checks, checkGithubOrg(config))
+	}
+	checks = append(checks, checkGit(), checkDiskSpace(os.TempDir()), checkFlags(config))
+	return checks
+}
+
+// printDoctorChecks prints the outcome of each check, followed by the number of problems and warnings found
+func printDoctorChecks(w io.Writer, checks []doctorCheck) {
+	counts := map[doctorStatus]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+		fmt.Fprintf(w, "%-10s %-14s %s\n", "["+check.Status+"]", check.Name, check.Details)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%d problems, %d warnings\n", counts[doctorProblem], counts[doctorWarning])
+}
+
+// checkGithubAPI checks that the GitHub API can be reached, and that GITHUB_OAUTH_TOKEN is valid and has the scopes a run
+// needs
+func checkGithubAPI(config *config.GitXargsConfig) []doctorCheck {
+	host := "the GitHub API"
+	if config.GithubClient.BaseURL != nil {
+		host = config.GithubClient.BaseURL.String()
+	}
+
+	user, resp, err := config.GithubClient.Users.Get(config.Context, "")
+	if resp == nil || resp.Response == nil {
+		// The request never got an answer, so whether the token is valid is unknown
+		return []doctorCheck{{
+			Name:    "GitHub API",
+			Status:  doctorProblem,
+			Details: fmt.Sprintf("Can't reach %s: %v. Check your network connection and proxy settings, and that the host is right if you use GitHub Enterprise", host, err),
+		}}
+	}
+
+	checks := []doctorCheck{{Name: "GitHub API", Status: doctorOK, Details: fmt.Sprintf("Reachable at %s", host)}}
+	return append(checks, checkGithubToken(user, resp, err))
+}
+
+// checkGithubToken checks the answer of the GitHub API to a request for the user GITHUB_OAUTH_TOKEN belongs to
+func checkGithubToken(user *github.User, resp *github.Response, err error) doctorCheck {
+	check := doctorCheck{Name: "GitHub token"}
+
+	if tokenErr := auth.EnsureGithubOauthTokenSet(); tokenErr != nil {
+		check.Status = doctorProblem
+		check.Details = tokenErr.Error()
+		return check
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		check.Status = doctorProblem
+		check.Details = "GitHub rejected GITHUB_OAUTH_TOKEN. It may have expired or been revoked: create a new one and export it"
+		return check
+	}
+	if err != nil {
+		check.Status = doctorProblem
+		check.Details = fmt.Sprintf("Can't look up the user GITHUB_OAUTH_TOKEN belongs to: %v", err)
+		return check
+	}
+
+	login := user.GetLogin()
+	if _, ok := resp.Header[http.CanonicalHeaderKey(githubScopesHeader)]; !ok {
+		check.Status = doctorOK
+		check.Details = fmt.Sprintf("Authenticated as %s with a fine-grained token. Its permissions can't be checked up front: it needs read and write access to the contents and pull requests of every repo", login)
+		return check
+	}
+
+	scopes := map[string]bool{}
+	for _, scope := range strings.Split(resp.Header.Get(githubScopesHeader), ",") {
+		scopes[strings.TrimSpace(scope)] = true
+	}
+	switch {
+	case !scopes["repo"] && !scopes["public_repo"]:
+		check.Status = doctorProblem
+		check.Details = fmt.Sprintf("Authenticated as %s, but the token lacks the repo scope, so git-xargs can't push branches or open pull requests. Create a token with the repo scope", login)
+	case !scopes["repo"]:
+		check.Status = doctorWarning
+		check.Details = fmt.Sprintf("Authenticated as %s, but the token only has the public_repo scope, so private repos will fail. Add the repo scope to change them", login)
+	case !scopes["workflow"]:
+		check.Status = doctorWarning
+		check.Details = fmt.Sprintf("Authenticated as %s, but the token lacks the workflow scope, so GitHub rejects pushes that change files under .github/workflows. Add the workflow scope if the command changes them", login)
+	default:
+		check.Status = doctorOK
+		check.Details = fmt.Sprintf("Authenticated as %s, with the repo and workflow scopes", login)
+	}
+	return check
+}
+
+// checkGithubOrg checks that the repos of the organization passed via --github-org can be listed
+func checkGithubOrg(config *config.GitXargsConfig) doctorCheck {
+	check := doctorCheck{Name: "GitHub org"}
+
+	opt := &github.RepositoryListByOrgOptions{ListOptions: github.ListOptions{PerPage: 1}}
+	if _, _, err := config.GithubClient.Repositories.ListByOrg(config.Context, config.GithubOrg, opt); err != nil {
+		check.Status = doctorProblem
+		check.Details = fmt.Sprintf("Can't list the repos of %s: %v. Check the name of the organization, that the token can see it and, for organizations with SAML single sign-on, that the token is authorized for it", config.GithubOrg, err)
+		return check
+	}
+
+	check.Status = doctorOK
+	check.Details = fmt.Sprintf("The repos of %s can be listed", config.GithubOrg)
+	return check
+}
+
+// checkGit checks that git is installed. git-xargs clones, commits and pushes without it, but most commands that change
+// repos call it
+func checkGit() doctorCheck {
+	path, err := exec.LookPath("git")
+	if err != nil {
+		return doctorCheck{
+			Name:    "git",
+			Status:  doctorWarning,
+			Details: "git isn't installed or isn't on the PATH. git-xargs doesn't need it, but commands that call git will fail",
+		}
+	}
+	return doctorCheck{Name: "git", Status: doctorOK, Details: fmt.Sprintf("Installed at %s", path)}
+}
+
+// checkDiskSpace checks that the disk the supplied directory, which repos are cloned to, is on has room for the clones
+func checkDiskSpace(dir string) doctorCheck {
+	check := doctorCheck{Name: "Disk space"}
+
+	free, total, ok := util.DiskSpace(dir)
+	if !ok {
+		check.Status = doctorWarning
+		check.Details = fmt.Sprintf("Can't tell how much space is free on the disk of %s", dir)
+		return check
+	}
+
+	freeGiB := float64(free) / (1 << 30)
+	if free < minDoctorDiskFreeBytes || float64(free) < minDoctorDiskFreeFraction*float64(total) {
+		check.Status = doctorProblem
+		check.Details = fmt.Sprintf("Only %.1f GiB free on the disk of %s, which repos are cloned to. Free up space, or point TMPDIR at a bigger disk", freeGiB, dir)
+		return check
+	}
+
+	check.Status = doctorOK
+	check.Details = fmt.Sprintf("%.1f GiB free on the disk of %s", freeGiB, dir)
+	return check
+}
+
+// checkFlags checks the flags and command passed to doctor the way a run checks them, reporting every problem at once
+// rather than only the first
+func checkFlags(config *config.GitXargsConfig) doctorCheck {
+	problems := []string{}
+	addProblem := func(err error) {
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(config.Args) < 1 && len(config.FileChanges) == 0 && len(config.FileMoves) == 0 {
+		addProblem(types.NoArgumentsPassedErr{})
+	}
+	addProblem(gitxargs_io.EnsureRepoSelectionPassed(config))
+	addProblem(gitxargs_io.EnsureValidOptionsPassed(config))
+	if config.ApproveAndMerge {
+		addProblem(auth.EnsureGithubApproverOauthTokenSet())
+	}
+	if config.SlackChannel != "" {
+		addProblem(notify.EnsureSlackBotTokenSet())
+	}
+	if config.JiraIssue != "" {
+		addProblem(notify.EnsureJiraTokenSet())
+	}
+
+	if len(problems) > 0 {
+		return doctorCheck{Name: "Flags", Status: doctorProblem, Details: strings.Join(problems, ". ")}
+	}
+	return doctorCheck{Name: "Flags", Status: doctorOK, Details: "The flags and command are consistent"}
+}
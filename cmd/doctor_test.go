@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Not parallel, since it sets GITHUB_OAUTH_TOKEN, which the other tests would see
+func TestCheckGithubToken(t *testing.T) {
+	require.NoError(t, os.Setenv("GITHUB_OAUTH_TOKEN", "test-token"))
+	defer os.Unsetenv("GITHUB_OAUTH_TOKEN")
+
+	response := func(statusCode int, scopes ...string) *github.Response {
+		header := http.Header{}
+		for _, scope := range scopes {
+			header.Set(githubScopesHeader, scope)
+		}
+		return &github.Response{Response: &http.Response{StatusCode: statusCode, Header: header}}
+	}
+	user := &github.User{Login: github.String("git-xargs-bot")}
+
+	testCases := []struct {
+		name         string
+		resp         *github.Response
+		expectStatus doctorStatus
+	}{
+		{"repo and workflow scopes", response(http.StatusOK, "repo, workflow"), doctorOK},
+		{"fine-grained token", response(http.StatusOK), doctorOK},
+		{"no workflow scope", response(http.StatusOK, "repo, read:org"), doctorWarning},
+		{"only public repos", response(http.StatusOK, "public_repo, workflow"), doctorWarning},
+		{"no repo scope", response(http.StatusOK, "gist"), doctorProblem},
+		{"rejected token", response(http.StatusUnauthorized), doctorProblem},
+	}
+
+	for _, testCase := range testCases {
+		check := checkGithubToken(user, testCase.resp, nil)
+		assert.Equal(t, testCase.expectStatus, check.Status, testCase.name)
+	}
+
+	os.Unsetenv("GITHUB_OAUTH_TOKEN")
+	check := checkGithubToken(user, response(http.StatusUnauthorized), nil)
+	assert.Equal(t, doctorProblem, check.Status)
+	assert.Contains(t, check.Details, "GITHUB_OAUTH_TOKEN")
+}
+
+func TestCheckFlags(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	// Every problem is reported at once
+	check := checkFlags(testConfig)
+	assert.Equal(t, doctorProblem, check.Status)
+	assert.Contains(t, check.Details, "You must pass a valid command")
+	assert.Contains(t, check.Details, "You must target some repos")
+
+	testConfig.GithubOrg = "gruntwork-io"
+	testConfig.BranchName = "test-branch-name"
+	testConfig.Args = []string{"touch", "test.txt"}
+	check = checkFlags(testConfig)
+	assert.Equal(t, doctorOK, check.Status, check.Details)
+
+	assert.Equal(t, doctorOK, checkGithubOrg(testConfig).Status)
+}
@@ -2,38 +2,268 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/google/go-github/v32/github"
 	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
 	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/notify"
+	"github.com/gruntwork-io/git-xargs/plugins"
+	"github.com/gruntwork-io/git-xargs/progress"
 	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/tracing"
 	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/gruntwork-io/go-commons/errors"
-	"github.com/gruntwork-io/go-commons/logging"
+	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
 // parseGitXargsConfig accepts a urfave cli context and binds its values
 // to an internal representation of the data supplied by the user
 func parseGitXargsConfig(c *cli.Context) (*config.GitXargsConfig, error) {
+	// If --config was passed, or there is a config file in the working directory, take the flags that weren't passed
+	// on the command line from it
+	fileCommand, err := applyConfigFile(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// If --github-token-env was passed, e.g. by a profile, read the Github token from that environment variable
+	if tokenEnv := c.String(common.GithubTokenEnvFlagName); tokenEnv != "" {
+		if err := auth.UseGithubTokenFromEnv(tokenEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	// If --api-header was passed, send its headers with every GitHub API request
+	apiHeaders, err := auth.ParseAPIHeaders(c.StringSlice(common.APIHeaderFlagName))
+	if err != nil {
+		return nil, err
+	}
+
 	config := config.NewGitXargsConfig()
+	config.APIHeaders = apiHeaders
 	config.Draft = c.Bool("draft")
-	config.DryRun = c.Bool("dry-run")
+	config.DraftIfChecksPending = c.Bool("draft-if-checks-pending")
+	config.DraftIfDiffLinesOver = c.Int("draft-if-diff-lines-over")
+	config.DraftIfRepoMatches = c.StringSlice("draft-if-repo-matches")
+	// --dry-run is the commit-no-push level of --dry-run-level, which takes precedence over it
+	if c.Bool("dry-run") {
+		config.ApplyDryRunLevel(common.DryRunCommitNoPush)
+	}
+	config.ApplyDryRunLevel(c.String(common.DryRunLevelFlagName))
 	config.SkipPullRequests = c.Bool("skip-pull-requests")
 	config.SkipArchivedRepos = c.Bool("skip-archived-repos")
+	config.SkipReposWithOpenPRs = c.Bool("skip-repos-with-open-pull-requests")
 	config.BranchName = c.String("branch-name")
 	config.BaseBranchName = c.String("base-branch-name")
 	config.CommitMessage = c.String("commit-message")
 	config.PullRequestTitle = c.String("pull-request-title")
 	config.PullRequestDescription = c.String("pull-request-description")
+	config.PullRequestFooter = c.String("pull-request-footer")
+	config.CommitStatus = c.Bool("commit-status")
+	config.CommitStatusURL = c.String("commit-status-url")
+	config.StateFile = c.String("state-file")
+	config.SkipState = c.Bool("skip-state")
+	config.Resume = c.Bool("resume")
+	config.DeleteBranch = c.Bool("delete-branch")
+	config.CloseComment = c.String("close-comment")
+	config.BranchPattern = c.String("branch-pattern")
+	config.PlanFile = c.String("out")
+	config.Schedule = c.String("schedule")
+	config.OutputFormat = c.String("output")
+	config.OutputFile = c.String("output-file")
+	config.ReportCSV = c.String("report-csv")
+	config.ReportMarkdown = c.String("report-markdown")
+	config.ReportJUnit = c.String("report-junit")
+	config.ReportHTML = c.String("report-html")
+	config.ReportDiffLines = c.Int(common.ReportDiffLinesFlagName)
+	config.OutputManifest = c.String(common.OutputManifestFlagName)
+	config.WebhookURL = c.String("webhook-url")
+	config.WebhookIncludeEvents = c.Bool("webhook-include-events")
+	config.SlackWebhookURL = c.String("slack-webhook-url")
+	config.SlackChannel = c.String("slack-channel")
+	config.EmailTo = c.StringSlice(common.EmailToFlagName)
+	config.EmailFrom = c.String(common.EmailFromFlagName)
+	config.SMTPServer = c.String(common.SMTPServerFlagName)
+	config.PushgatewayURL = c.String("pushgateway-url")
+	config.OTLPEndpoint = c.String("otlp-endpoint")
+	config.TrackingIssueRepo = c.String("create-tracking-issue")
+	config.ReportGist = c.Bool("report-gist")
+	if reportUpload := c.String(common.ReportUploadFlagName); reportUpload != "" {
+		config.ReportUpload, err = gitxargs_io.ParseReportUploadURL(reportUpload)
+		if err != nil {
+			return nil, err
+		}
+	}
+	config.EventsFile = c.String("events-file")
+	config.AuditLogDir = c.String(common.AuditLogDirFlagName)
+	config.AuditLogHashChain = c.Bool(common.AuditLogHashChainFlagName)
+	if metricsJob := c.String("metrics-job"); metricsJob != "" {
+		config.MetricsJob = metricsJob
+	}
+	config.TelemetryEndpoint = c.String(common.TelemetryEndpointFlagName)
+	if config.TelemetryEndpoint != "" {
+		config.TelemetryCommand = telemetryCommand(c)
+		config.TelemetryFlags = flagsUsed(c)
+	}
+	if c.IsSet("allowed-failures") {
+		config.AllowedFailures = c.Int("allowed-failures")
+	}
+	if c.IsSet("allowed-failure-rate") {
+		config.AllowedFailureRate = c.Float64("allowed-failure-rate")
+	}
+	if c.IsSet(common.MinSuccessRateFlagName) {
+		config.MinSuccessRate, err = gitxargs_io.ParseSuccessRate(c.String(common.MinSuccessRateFlagName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	config.FailFast = c.Bool("fail-fast")
+	if c.IsSet("max-failures") {
+		config.MaxFailures = c.Int("max-failures")
+	}
+	if c.IsSet("max-failure-rate") {
+		config.MaxFailureRate = c.Float64("max-failure-rate")
+	}
 	config.ReposFile = c.String("repos")
 	config.GithubOrg = c.String("github-org")
+	config.Project = c.String("project")
+	config.ProjectFieldValues = c.StringSlice("project-field")
+	config.Reviewers = c.StringSlice("reviewers")
+	config.ReviewerStrategy = c.String("reviewer-strategy")
+	config.ReviewersPerPR = c.Int("reviewers-per-pull-request")
+	config.ExcludeReviewers = c.StringSlice(common.ExcludeReviewersFlagName)
+	config.ReviewerPool = reviewers.NewPool(reviewers.Exclude(config.Reviewers, config.ExcludeReviewers), config.ReviewerStrategy, config.ReviewersPerPR)
+	config.ReviewersFromBlame = c.Bool("reviewers-from-blame")
+	config.BlameReviewersCount = c.Int("blame-reviewers-count")
+	config.Assignees = c.StringSlice("assignees")
+	config.AssigneeStrategy = c.String("assignee-strategy")
+	config.AssigneesPerPR = c.Int("assignees-per-pull-request")
 	config.RepoSlice = c.StringSlice("repo")
 	config.MaxConcurrentRepos = c.Int("max-concurrent-repos")
+	config.AutoConcurrency = c.Bool("auto-concurrency")
+	config.Pick = c.Bool(common.PickFlagName)
+	config.Tags = c.StringSlice(common.TagFlagName)
+	config.IgnoreRepoConfig = c.Bool(common.IgnoreRepoConfigFlagName)
+	config.RepoTimeout = c.Duration("repo-timeout")
+	config.CloneConcurrency = c.Int("clone-concurrency")
+	config.CommandConcurrency = c.Int("command-concurrency")
+	config.PushConcurrency = c.Int("push-concurrency")
+	config.PullRequestConcurrency = c.Int("pull-request-concurrency")
 	config.Args = c.Args()
+	if len(config.Args) == 0 {
+		config.Args = fileCommand
+	}
+	config.SkipRunMarkers = c.Bool("skip-run-markers")
+	config.RecordFile = c.String(common.RecordFlagName)
+	config.ReplayFile = c.String(common.ReplayFlagName)
+	if err := useRecording(config); err != nil {
+		return nil, err
+	}
+	if err := useGithubHosts(config, c.StringSlice(common.GithubHostFlagName)); err != nil {
+		return nil, err
+	}
+	config.ApplyAPIHeaders()
+	config.JiraURL = c.String(common.JiraURLFlagName)
+	config.JiraIssue = c.String(common.JiraIssueFlagName)
+	config.JiraTransition = c.String(common.JiraTransitionFlagName)
+	config.CheckBranchProtection = c.Bool(common.CheckBranchProtectionFlagName)
+	config.MergeChecksTimeout = c.Duration(common.MergeChecksTimeoutFlagName)
+	config.DeployKeysDir = c.String(common.DeployKeysDirFlagName)
+	config.EnvFilesDir = c.String(common.EnvFilesDirFlagName)
+	config.RepoEnvFile = c.Bool(common.RepoEnvFileFlagName)
+	if pathLabels := c.String(common.PathLabelsFlagName); pathLabels != "" {
+		config.PathLabels, err = repository.LoadPathLabels(pathLabels)
+		if err != nil {
+			return nil, err
+		}
+	}
+	config.PullRequestSchedule = c.String(common.PRScheduleFlagName)
+	if config.PullRequestSchedule != "" {
+		config.PullRequestGate, err = gitxargs_io.ParsePullRequestSchedule(config.PullRequestSchedule, c.String(common.PRScheduleTimezoneFlagName), c.Int(common.PRScheduleMaxFlagName))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if signingKey := c.String(common.SigningKeyFlagName); signingKey != "" {
+		config.SigningKey, err = gitxargs_io.LoadSigningKey(signingKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	config.MaxOpenPRs = c.Int(common.MaxOpenPRsFlagName)
+	config.MaxOpenPRsScope = c.String(common.MaxOpenPRsScopeFlagName)
+	config.FileChanges, err = repository.ParseFileChanges(c.StringSlice(common.PutFileFlagName), c.StringSlice(common.DeleteFileFlagName))
+	if err != nil {
+		return nil, err
+	}
+	config.FileMoves, err = repository.ParseFileMoves(c.StringSlice(common.MoveFileFlagName))
+	if err != nil {
+		return nil, err
+	}
+	config.PushViaAPI = c.Bool(common.PushViaAPIFlagName)
+	config.MonorepoManifest = c.String(common.MonorepoManifestFlagName)
+	config.MonorepoPullRequests = c.String(common.MonorepoPullRequestsFlagName)
+	if config.MonorepoManifest != "" {
+		config.MonorepoTargets, err = repository.LoadMonorepoManifest(config.MonorepoManifest)
+		if err != nil {
+			return nil, err
+		}
+		// The monorepos are selected like repos passed via --repo, which can still be passed to run in whole repos
+		config.RepoSlice = append(config.RepoSlice, repository.MonorepoNames(config.MonorepoTargets)...)
+	}
+	config.SkipCITrailer = c.String(common.SkipCITrailerFlagName)
+	config.SkipCIIn = c.String(common.SkipCIInFlagName)
+	if c.Bool(common.NoSkipCIFlagName) {
+		config.SkipCITrailer = ""
+	}
+	config.ApproveAndMerge = c.Bool("approve-and-merge")
+	config.MergeMethod = c.String("merge-method")
+	config.MaxFilesPerPR = c.Int("max-files-per-pull-request")
+	config.SplitBy = c.String("split-by")
+
+	if runID := c.String("run-id"); runID != "" {
+		config.RunID = runID
+		config.RunIDSupplied = true
+	}
+	if err := useManifest(c, config); err != nil {
+		return nil, err
+	}
+
+	// The --progress dashboard replaces the info and debug logs, which would otherwise be interleaved with it, unless a
+	// --log-level was asked for explicitly
+	if c.Bool("progress") {
+		config.Progress = progress.New(config.RunID)
+		if !c.GlobalIsSet("log-level") && !c.IsSet("log-level") {
+			logging.SetGlobalLogLevel(logrus.ErrorLevel)
+		}
+	}
+
+	// The collector's headers and the service name are read from the standard OpenTelemetry environment variables
+	if config.OTLPEndpoint != "" {
+		serviceName := os.Getenv("OTEL_SERVICE_NAME")
+		if serviceName == "" {
+			serviceName = common.DefaultOTLPServiceName
+		}
+		config.Tracer = tracing.New(config.OTLPEndpoint, tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")), serviceName)
+	}
+
+	// Load the --plugin plugins up front, so that a plugin that can't be loaded fails the run before any repos are touched
+	config.Plugins, err = plugins.Load(c.StringSlice(common.PluginFlagName))
+	if err != nil {
+		return nil, err
+	}
 
 	shouldReadStdIn, err := dataBeingPipedToStdIn()
 	if err != nil {
@@ -88,34 +318,487 @@ func parseSliceFromReader(reader io.Reader) ([]string, error) {
 }
 
 // handleRepoProcessing encapsulates the main processing logic for the supplied repos and printing the run report that
-// is built up throughout the processing
+// is built up throughout the processing. Interrupting the run stops the repos being processed, but still prints the
+// report of the repos processed so far
 func handleRepoProcessing(config *config.GitXargsConfig) error {
-	// Track whether pull requests were skipped
-	config.Stats.SetSkipPullRequests(config.SkipPullRequests)
+	stopCancelOnInterrupt := cancelOnInterrupt(config)
+	defer stopCancelOnInterrupt()
+	defer saveRecording(config)
 
-	// Update raw command supplied
-	config.Stats.SetCommand(config.Args)
+	if err := openAuditLog(config, config.Args); err != nil {
+		return err
+	}
+	defer config.Audit.Close()
 
-	if err := repository.OperateOnRepos(config); err != nil {
+	if err := gitxargs.ProcessRun(config); err != nil {
 		return err
 	}
 
 	// Once all processing is complete, print out the summary of what was done
-	config.Stats.PrintReport()
+	reportErr := writeRunReport(config)
+	if err := finishAuditLog(config); err != nil {
+		return err
+	}
+	return reportErr
+}
+
+// writeRunReport writes the files of the run report, prints its summary, and publishes it to the places that were
+// passed. Finally, it returns an error if the run was interrupted, or if more repos failed than --allowed-failures,
+// --allowed-failure-rate and --min-success-rate allow, so that the process exits with a non-zero code
+func writeRunReport(config *config.GitXargsConfig) error {
+	config.Stats.SetAPIRetries(config.GithubClient.APICalls.Retries() + config.ApproverGithubClient.APICalls.Retries())
+	recordAPIUsage(config)
+
+	if err := writeReportFiles(config); err != nil {
+		return err
+	}
+	if err := printRunReport(config); err != nil {
+		return err
+	}
+	publishRunReport(config)
 
+	if err := ensureNotInterrupted(config); err != nil {
+		return err
+	}
+	return ensureFailuresAllowed(config)
+}
+
+// writeReportFiles exports the run report to --report-csv, --report-markdown, --report-junit, --report-html and
+// --output-manifest, for each of them that was passed
+func writeReportFiles(config *config.GitXargsConfig) error {
+	reportFiles := []struct {
+		path  string
+		write func(w io.Writer) error
+	}{
+		{config.ReportCSV, config.Stats.WriteCSVReport},
+		{config.ReportMarkdown, config.Stats.WriteMarkdownReport},
+		{config.ReportJUnit, config.Stats.WriteJUnitReport},
+		{config.ReportHTML, config.Stats.WriteHTMLReport},
+		{config.OutputManifest, func(w io.Writer) error {
+			return config.Stats.WriteRunManifest(w, manifestConfig(config))
+		}},
+	}
+	for _, reportFile := range reportFiles {
+		if reportFile.path == "" {
+			continue
+		}
+		if err := writeReportFile(reportFile.path, reportFile.write); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// printRunReport writes the summary of what was done in the --output format, to --output-file or stdout. With
+// --dry-run-level clone-and-run, the diffs of the changes that would have been made come before the summary. They go to
+// stderr when stdout carries machine-readable output
+func printRunReport(config *config.GitXargsConfig) error {
+	if config.DryRunLevel == common.DryRunCloneAndRun {
+		diffOutput := os.Stdout
+		if config.OutputFile == "" && config.OutputFormat == common.OutputFormatJSON {
+			diffOutput = os.Stderr
+		}
+		printDryRunDiffs(config, diffOutput)
+	}
+
+	if config.OutputFile == "" {
+		return config.Stats.WriteReport(config.OutputFormat, os.Stdout)
+	}
+	return writeReportFile(config.OutputFile, func(w io.Writer) error {
+		return config.Stats.WriteReport(config.OutputFormat, w)
+	})
+}
+
+// publishRunReport sends the run report to each of the places passed to publish it to: the GitHub Actions step
+// summary, a gist, object storage, a tracking issue, --webhook-url, Jira, Slack, email, --pushgateway-url,
+// --telemetry-endpoint and the OTLP endpoint of the traces
+func publishRunReport(config *config.GitXargsConfig) {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		writeGithubActionsResults(config)
+	}
+	// The URL of the full report, for a tracking issue too long to hold all of it
+	fullReportURL := ""
+	if config.ReportGist {
+		fullReportURL = uploadReportGist(config)
+	}
+	if config.ReportUpload != nil {
+		if objectURL := uploadReportToObjectStorage(config); objectURL != "" {
+			fullReportURL = objectURL
+		}
+	}
+	if config.TrackingIssueRepo != "" {
+		createTrackingIssue(config, fullReportURL)
+	}
+	if config.WebhookURL != "" {
+		sendRunWebhook(config)
+	}
+	if config.JiraIssue != "" {
+		commentOnJiraIssue(config)
+	}
+	if config.SlackWebhookURL != "" || config.SlackChannel != "" {
+		sendSlackNotification(config)
+	}
+	if len(config.EmailTo) > 0 {
+		sendEmailNotification(config)
+	}
+	if config.PushgatewayURL != "" {
+		pushRunMetrics(config)
+	}
+	if config.TelemetryEndpoint != "" {
+		sendTelemetry(config)
+	}
+	if config.Tracer != nil {
+		exportTraces(config)
+	}
+}
+
+// publishBestEffort runs one step of publishing the results of a run. The run has already finished by then, so a step
+// that fails is logged as an error, along with the supplied fields, rather than failing the run or skipping the steps
+// after it. It returns whether the step succeeded
+func publishBestEffort(failure string, fields logrus.Fields, step func() error) bool {
+	err := step()
+	if err == nil {
+		return true
+	}
+
+	errorFields := logrus.Fields{"Error": err}
+	for key, value := range fields {
+		errorFields[key] = value
+	}
+	logging.GetLogger("git-xargs").WithFields(errorFields).Error(failure)
+	return false
+}
+
+// recordAPIUsage records the GitHub API calls made during the run, by both the main and the approver token, and the rate
+// limits left for the main token, in the run report, so that operators can plan back-to-back campaigns
+func recordAPIUsage(config *config.GitXargsConfig) {
+	byCategory := config.GithubClient.APICalls.ByCategory()
+	for category, calls := range config.ApproverGithubClient.APICalls.ByCategory() {
+		byCategory[category] += calls
+	}
+	config.Stats.SetAPIUsage(
+		config.GithubClient.APICalls.Count()+config.ApproverGithubClient.APICalls.Count(),
+		byCategory,
+		config.GithubClient.RateLimiter.Status(),
+	)
+}
+
+// writeGithubActionsResults adds the Markdown run report to the summary of the GitHub Actions step git-xargs runs in, and
+// sets the outputs of the step, so that later steps of the workflow can use the results of the run
+func writeGithubActionsResults(config *config.GitXargsConfig) {
+	results := []struct {
+		path  string
+		write func(w io.Writer) error
+	}{
+		{os.Getenv("GITHUB_STEP_SUMMARY"), config.Stats.WriteGithubActionsSummary},
+		{os.Getenv("GITHUB_OUTPUT"), config.Stats.WriteGithubActionsOutputs},
+	}
+	for _, result := range results {
+		if result.path == "" {
+			continue
+		}
+		result := result
+		publishBestEffort("Error writing the run results for GitHub Actions", logrus.Fields{"Path": result.path}, func() error {
+			return appendToFile(result.path, result.write)
+		})
+	}
+}
+
+// uploadReportGist uploads the run report as Markdown and JSON to a secret gist, and returns the URL of the gist, or an
+// empty string if it couldn't be uploaded
+func uploadReportGist(config *config.GitXargsConfig) string {
+	var gist *github.Gist
+	uploaded := publishBestEffort("Error uploading the run report to a gist", logrus.Fields{}, func() error {
+		var markdownReport, jsonReport bytes.Buffer
+		if err := config.Stats.WriteMarkdownReport(&markdownReport); err != nil {
+			return err
+		}
+		if err := config.Stats.WriteReport(common.OutputFormatJSON, &jsonReport); err != nil {
+			return err
+		}
+
+		fileName := fmt.Sprintf("git-xargs-%s", config.RunID)
+		var err error
+		gist, err = repository.CreateReportGist(config, fmt.Sprintf("git-xargs run %s", config.RunID), map[string]string{
+			fileName + ".md":   markdownReport.String(),
+			fileName + ".json": jsonReport.String(),
+		})
+		return err
+	})
+	if !uploaded {
+		return ""
+	}
+
+	logging.GetLogger("git-xargs").WithFields(logrus.Fields{
+		"Gist URL": gist.GetHTMLURL(),
+	}).Info("Uploaded the run report to a gist")
+	return gist.GetHTMLURL()
+}
+
+// uploadReportToObjectStorage uploads the run report as JSON and HTML under --report-upload, and returns the URL of the
+// HTML report, or an empty string if it couldn't be uploaded
+func uploadReportToObjectStorage(config *config.GitXargsConfig) string {
+	logger := logging.GetLogger("git-xargs")
+
+	htmlURL := ""
+	reports := []struct {
+		extension   string
+		contentType string
+		write       func(w io.Writer) error
+	}{
+		{"json", "application/json", func(w io.Writer) error { return config.Stats.WriteReport(common.OutputFormatJSON, w) }},
+		{"html", "text/html; charset=utf-8", config.Stats.WriteHTMLReport},
+	}
+	for _, report := range reports {
+		name := fmt.Sprintf("git-xargs-%s.%s", config.RunID, report.extension)
+
+		objectURL := ""
+		report := report
+		uploaded := publishBestEffort("Error uploading the run report to object storage", logrus.Fields{"Location": config.ReportUpload.URL(name)}, func() error {
+			var buffer bytes.Buffer
+			if err := report.write(&buffer); err != nil {
+				return err
+			}
+			var err error
+			objectURL, err = notify.UploadObject(*config.ReportUpload, name, report.contentType, buffer.Bytes())
+			return err
+		})
+		if !uploaded {
+			continue
+		}
+
+		logger.WithFields(logrus.Fields{
+			"URL": objectURL,
+		}).Info("Uploaded the run report to object storage")
+		if report.extension == "html" {
+			htmlURL = objectURL
+		}
+	}
+	return htmlURL
+}
+
+// createTrackingIssue opens the tracking issue of the run in --create-tracking-issue. A report too long for an issue is
+// cut short, with a link to the supplied URL of the full report, or to the GitHub Actions run git-xargs runs in
+func createTrackingIssue(config *config.GitXargsConfig, fullReportURL string) {
+	if fullReportURL == "" && os.Getenv("GITHUB_ACTIONS") == "true" && os.Getenv("GITHUB_RUN_ID") != "" {
+		fullReportURL = fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	}
+
+	var issue *github.Issue
+	opened := publishBestEffort("Error opening the tracking issue of the run", logrus.Fields{"Repo": config.TrackingIssueRepo}, func() error {
+		body, err := config.Stats.RenderTrackingIssue(fullReportURL)
+		if err != nil {
+			return err
+		}
+		title := fmt.Sprintf("git-xargs run %s: %s", config.RunID, config.BranchName)
+		issue, err = repository.CreateTrackingIssue(config, title, body)
+		return err
+	})
+	if !opened {
+		return
+	}
+
+	logging.GetLogger("git-xargs").WithFields(logrus.Fields{
+		"Issue URL": issue.GetHTMLURL(),
+	}).Info("Opened the tracking issue of the run")
+}
+
+// exportTraces sends the spans of the run to --otlp-endpoint
+func exportTraces(config *config.GitXargsConfig) {
+	fields := logrus.Fields{"Endpoint": config.OTLPEndpoint}
+	if publishBestEffort("Error exporting the run traces to the OTLP collector", fields, config.Tracer.Export) {
+		logging.GetLogger("git-xargs").WithFields(fields).Debug("Exported the run traces to the OTLP collector")
+	}
+}
+
+// pushRunMetrics pushes the metrics of the run to --pushgateway-url
+func pushRunMetrics(config *config.GitXargsConfig) {
+	pushed := publishBestEffort("Error pushing the run metrics to the Pushgateway", logrus.Fields{"URL": config.PushgatewayURL}, func() error {
+		apiCalls := config.GithubClient.APICalls.Count() + config.ApproverGithubClient.APICalls.Count()
+
+		var metrics bytes.Buffer
+		if err := config.Stats.WritePrometheusMetrics(&metrics, apiCalls); err != nil {
+			return err
+		}
+		return notify.PushMetrics(config.PushgatewayURL, config.MetricsJob, metrics.Bytes())
+	})
+	if !pushed {
+		return
+	}
+
+	logging.GetLogger("git-xargs").WithFields(logrus.Fields{
+		"URL": config.PushgatewayURL,
+		"Job": config.MetricsJob,
+	}).Debug("Pushed the run metrics to the Pushgateway")
+}
+
+// ensureFailuresAllowed returns an error carrying common.FailedReposExitCode if the repos that failed exceed
+// --allowed-failures or --allowed-failure-rate, or leave fewer repos than --min-success-rate succeeding. Thresholds that
+// weren't passed are negative. If none was passed, any failed repo is too many
+func ensureFailuresAllowed(config *config.GitXargsConfig) error {
+	failed := len(config.Stats.GetErrors())
+	if failed == 0 {
+		return nil
+	}
+	total := config.Stats.CountRepos()
+
+	exceeded := config.AllowedFailures < 0 && config.AllowedFailureRate < 0 && config.MinSuccessRate < 0
+	if config.AllowedFailures >= 0 && failed > config.AllowedFailures {
+		exceeded = true
+	}
+	if config.AllowedFailureRate >= 0 && total > 0 && float64(failed)/float64(total) > config.AllowedFailureRate {
+		exceeded = true
+	}
+	// Like failures, the success rate is relative to every repo processed, so skipped repos count as successes
+	if config.MinSuccessRate >= 0 && total > 0 && float64(total-failed)/float64(total) < config.MinSuccessRate {
+		exceeded = true
+	}
+	if !exceeded {
+		return nil
+	}
+
+	return errors.WithStackTrace(errors.ErrorWithExitCode{
+		Err:      types.TooManyFailedReposErr{Failed: failed, Total: total},
+		ExitCode: common.FailedReposExitCode,
+	})
+}
+
+// sendSlackNotification posts a summary of the run to --slack-webhook-url and --slack-channel
+func sendSlackNotification(config *config.GitXargsConfig) {
+	message := config.Stats.RenderSlackMessage()
+
+	if config.SlackWebhookURL != "" {
+		publishBestEffort("Error posting the run summary to the Slack webhook", logrus.Fields{}, func() error {
+			return notify.PostSlackWebhook(config.SlackWebhookURL, message)
+		})
+	}
+
+	if config.SlackChannel != "" {
+		publishBestEffort("Error posting the run summary to the Slack channel", logrus.Fields{"Channel": config.SlackChannel}, func() error {
+			return notify.PostSlackMessage(config.SlackChannel, message)
+		})
+	}
+}
+
+// sendEmailNotification emails a summary of the run to --email-to
+func sendEmailNotification(config *config.GitXargsConfig) {
+	subject, body := config.Stats.RenderEmail()
+	publishBestEffort("Error emailing the run summary", logrus.Fields{"To": strings.Join(config.EmailTo, ", ")}, func() error {
+		return notify.SendEmail(config.SMTPServer, config.EmailFrom, config.EmailTo, subject, body)
+	})
+}
+
+// commentOnJiraIssue posts the run report to --jira-issue as a comment. Dry runs don't change anything, so they aren't
+// worth commenting about
+func commentOnJiraIssue(config *config.GitXargsConfig) {
+	logger := logging.GetLogger("git-xargs")
+	fields := logrus.Fields{"Issue": config.JiraIssue}
+
+	if config.DryRun {
+		logger.WithFields(fields).Info("Not commenting on the Jira issue, since this is a dry run")
+		return
+	}
+
+	posted := publishBestEffort("Error posting the run report to the Jira issue", fields, func() error {
+		return notify.PostJiraComment(config.JiraURL, config.JiraIssue, config.Stats.RenderJiraComment())
+	})
+	if posted {
+		logger.WithFields(fields).Info("Posted the run report to the Jira issue")
+	}
+}
+
+// sendRunWebhook POSTs the run report to --webhook-url
+func sendRunWebhook(config *config.GitXargsConfig) {
+	fields := logrus.Fields{"URL": config.WebhookURL}
+	sent := publishBestEffort("Error sending the run report to the webhook", fields, func() error {
+		var payload bytes.Buffer
+		if err := config.Stats.WriteWebhookPayload(&payload, config.WebhookIncludeEvents); err != nil {
+			return err
+		}
+		return notify.PostWebhook(config.WebhookURL, payload.Bytes())
+	})
+	if sent {
+		logging.GetLogger("git-xargs").WithFields(fields).Debug("Sent the run report to the webhook")
+	}
+}
+
+// printDryRunDiffs writes the diff of the changes the command made to each repo to the supplied writer, in the order of
+// the repo names
+func printDryRunDiffs(config *config.GitXargsConfig, w io.Writer) {
+	diffs := config.Stats.GetDiffs()
+	repoNames := make([]string, 0, len(diffs))
+	for repoName := range diffs {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	for _, repoName := range repoNames {
+		fmt.Fprintf(w, "=== %s ===\n%s\n", repoName, diffs[repoName])
+	}
+}
+
+// writeReportFile creates the file at the supplied path and writes a report to it with the supplied function
+func writeReportFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer file.Close()
+
+	return write(file)
+}
+
+// appendToFile appends to the file at the supplied path, creating it if needed, using the supplied write function.
+// GitHub Actions expects the files of the step summary and outputs to be appended to, rather than overwritten
+func appendToFile(path string, write func(w io.Writer) error) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer file.Close()
+
+	return write(file)
+}
+
 // sanityCheckInputs performs validation on the user-supplied inputs to ensure we have everything we need:
 // 1. An exported GITHUB_OAUTH_TOKEN
 // 2. Arguments passed to the binary itself which should be executed against the targeted repos
 // 3. At least one of the three valid methods for selecting repositories
 func sanityCheckInputs(config *config.GitXargsConfig) error {
-	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+	// Replaying a recording doesn't call the GitHub API, so it needs no tokens
+	if config.ReplayFile == "" {
+		if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+			return err
+		}
+	}
+
+	if config.ApproveAndMerge {
+		if config.ReplayFile == "" {
+			if err := auth.EnsureGithubApproverOauthTokenSet(); err != nil {
+				return err
+			}
+		}
+		config.ApproverGithubClient = approverGithubClient(config)
+		config.ApplyAPIHeaders()
+	}
+	if err := ensureGithubHostApprovers(config); err != nil {
 		return err
 	}
 
-	if len(config.Args) < 1 {
+	if config.SlackChannel != "" {
+		if err := notify.EnsureSlackBotTokenSet(); err != nil {
+			return err
+		}
+	}
+
+	if config.JiraIssue != "" {
+		if err := notify.EnsureJiraTokenSet(); err != nil {
+			return err
+		}
+	}
+
+	// Files passed via --put-file, --delete-file and --move-file take the place of the command
+	if len(config.Args) < 1 && len(config.FileChanges) == 0 && len(config.FileMoves) == 0 {
 		return errors.WithStackTrace(types.NoArgumentsPassedErr{})
 	}
 
@@ -126,10 +809,14 @@ func sanityCheckInputs(config *config.GitXargsConfig) error {
 	return nil
 }
 
-// RunGitXargs is the urfave cli app's Action that is called when the user executes the binary
+// RunGitXargs is the urfave cli app's Action that is called when the user executes the binary, and the Action of the
+// run subcommand
 func RunGitXargs(c *cli.Context) error {
-	// If someone calls us with no args at all, show the help text and exit
-	if !c.Args().Present() {
+	// If someone calls us with no args at all, and there is no config file to take them from, show the help text and exit
+	if !hasCommand(c) {
+		if c.Command.Name != "" {
+			return cli.ShowCommandHelp(c, c.Command.Name)
+		}
 		return cli.ShowAppHelp(c)
 	}
 
@@ -147,9 +834,44 @@ func RunGitXargs(c *cli.Context) error {
 	}
 
 	// If DryRun is enabled, notify user that no file changes will be made
-	if config.DryRun {
+	if config.DryRunLevel != "" {
+		logger.WithFields(logrus.Fields{
+			"Level": config.DryRunLevel,
+		}).Info("Dry run level set. The run will stop short of the steps past this level")
+	} else if config.DryRun {
 		logger.Info("Dry run setting enabled. No local branches will be pushed and no PRs will be opened in Github")
 	}
 
+	if err := openRunStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	if err := gitxargs.OpenEventStream(config); err != nil {
+		return err
+	}
+	defer config.Events.Close()
+
 	return handleRepoProcessing(config)
 }
+
+// openRunStateStore opens the state store of the run. If another git-xargs process holds it, the run goes ahead without
+// being recorded, as with --skip-state, unless it needs the store to resume an earlier run or to queue repos past
+// --max-open-prs
+func openRunStateStore(config *config.GitXargsConfig) error {
+	err := gitxargs.OpenStateStore(config)
+	if err == nil {
+		return nil
+	}
+
+	lockedErr, isLocked := errors.Unwrap(err).(types.StateStoreLockedErr)
+	if !isLocked || config.Resume || config.MaxOpenPRs > 0 {
+		return err
+	}
+
+	logging.GetLogger("git-xargs").WithFields(logrus.Fields{
+		"Path": lockedErr.Path,
+	}).Warn("The state store is in use by another git-xargs process. This run will not be recorded in it")
+	config.SkipState = true
+	return nil
+}
@@ -1,12 +1,25 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
 	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +35,8 @@ func TestHandleRepoProcessing(t *testing.T) {
 	testConfig.CommitMessage = "test-commit-name"
 	testConfig.Args = []string{"touch", "test.txt"}
 	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	// The mocked repos can't actually be cloned, which would otherwise fail the run
+	testConfig.AllowedFailureRate = 1
 	err := handleRepoProcessing(testConfig)
 
 	assert.NoError(t, err)
@@ -57,3 +72,202 @@ func TestParseSliceFromReader(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureFailuresAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		allowedFailures    int
+		allowedFailureRate float64
+		minSuccessRate     float64
+		failed             int
+		expectErr          bool
+	}{
+		{"no failures", -1, -1, -1, 0, false},
+		{"any failure fails by default", -1, -1, -1, 1, true},
+		{"within allowed failures", 2, -1, -1, 2, false},
+		{"over allowed failures", 2, -1, -1, 3, true},
+		{"within allowed failure rate", -1, 0.5, -1, 2, false},
+		{"over allowed failure rate", -1, 0.5, -1, 3, true},
+		{"over allowed failures but within rate", 1, 0.5, -1, 2, true},
+		{"at min success rate", -1, -1, 0.75, 1, false},
+		{"below min success rate", -1, -1, 0.75, 2, true},
+		{"within allowed failures but below min success rate", 2, -1, 0.75, 2, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testConfig := config.NewGitXargsTestConfig()
+			testConfig.AllowedFailures = testCase.allowedFailures
+			testConfig.AllowedFailureRate = testCase.allowedFailureRate
+			testConfig.MinSuccessRate = testCase.minSuccessRate
+			for i := 0; i < 4; i++ {
+				repo := &github.Repository{Name: github.String(fmt.Sprintf("repo-%d", i))}
+				testConfig.Stats.TrackSingle(stats.RepoSuccessfullyCloned, repo)
+				if i < testCase.failed {
+					testConfig.Stats.TrackError(repo, fmt.Errorf("failed"))
+				}
+			}
+
+			err := ensureFailuresAllowed(testConfig)
+			if !testCase.expectErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			exitErr, ok := errors.Unwrap(err).(errors.ErrorWithExitCode)
+			require.True(t, ok)
+			assert.Equal(t, common.FailedReposExitCode, exitErr.ExitCode)
+			assert.Equal(t, types.TooManyFailedReposErr{Failed: testCase.failed, Total: 4}, exitErr.Err)
+		})
+	}
+}
+
+// Test that an interrupted run exits with common.InterruptedExitCode once its report is written
+func TestEnsureNotInterrupted(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	assert.NoError(t, ensureNotInterrupted(testConfig))
+
+	ctx, cancel := context.WithCancel(testConfig.Context)
+	cancel()
+	testConfig.Context = ctx
+
+	err := ensureNotInterrupted(testConfig)
+	require.Error(t, err)
+	exitErr, ok := errors.Unwrap(err).(errors.ErrorWithExitCode)
+	require.True(t, ok)
+	assert.Equal(t, common.InterruptedExitCode, exitErr.ExitCode)
+	assert.Equal(t, types.RunInterruptedErr{}, exitErr.Err)
+}
+
+// Test that the GitHub Actions results are appended to the files of the step, after what earlier steps wrote to them
+func TestAppendToFile(t *testing.T) {
+	t.Parallel()
+
+	outputFile, err := ioutil.TempFile("", "git-xargs-github-output")
+	require.NoError(t, err)
+	defer os.Remove(outputFile.Name())
+	_, err = outputFile.WriteString("earlier_step=done\n")
+	require.NoError(t, err)
+	require.NoError(t, outputFile.Close())
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Stats.TrackError(mocks.GetMockGithubRepo(), fmt.Errorf("exit status 1"))
+	testConfig.Stats.TrackSingle(stats.CommandErrorOccurredDuringExecution, mocks.GetMockGithubRepo())
+	require.NoError(t, appendToFile(outputFile.Name(), testConfig.Stats.WriteGithubActionsOutputs))
+
+	content, err := ioutil.ReadFile(outputFile.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "earlier_step=done\npr_urls=[]\nfailed_repos=[\"gruntwork-io/terragrunt\"]\n", string(content))
+}
+
+// Test that --github-host configures a client and token per GitHub Enterprise Server, leaving github.com to the ones
+// of the run, and that malformed values and missing tokens are rejected
+func TestUseGithubHosts(t *testing.T) {
+	os.Setenv("GIT_XARGS_TEST_GHE_TOKEN", "enterprise-token")
+	defer os.Unsetenv("GIT_XARGS_TEST_GHE_TOKEN")
+
+	testConfig := config.NewGitXargsTestConfig()
+	require.NoError(t, useGithubHosts(testConfig, []string{"GitHub.Example.com=GIT_XARGS_TEST_GHE_TOKEN", "github.com=GITHUB_OAUTH_TOKEN"}))
+	assert.Len(t, testConfig.GithubHostClients, 1)
+	assert.Equal(t, "https://github.example.com/api/v3/", testConfig.GithubHostClients["github.example.com"].BaseURL.String())
+	assert.Equal(t, map[string]string{"github.example.com": "enterprise-token"}, testConfig.GithubHostTokens)
+
+	for _, invalid := range []string{"github.example.com", "=GHE_TOKEN", "github.example.com/api=GIT_XARGS_TEST_GHE_TOKEN"} {
+		err := useGithubHosts(config.NewGitXargsTestConfig(), []string{invalid})
+		assert.IsType(t, types.InvalidGithubHostFlagErr{}, errors.Unwrap(err), invalid)
+	}
+
+	err := useGithubHosts(config.NewGitXargsTestConfig(), []string{"github.example.com=GIT_XARGS_TEST_MISSING_TOKEN"})
+	assert.IsType(t, types.GithubHostTokenNotSetErr{}, errors.Unwrap(err))
+
+	err = useGithubHosts(config.NewGitXargsTestConfig(), []string{"github.example.com=GIT_XARGS_TEST_GHE_TOKEN:"})
+	assert.IsType(t, types.InvalidGithubHostFlagErr{}, errors.Unwrap(err))
+}
+
+func TestUseGithubHostsWithApprovers(t *testing.T) {
+	os.Setenv("GIT_XARGS_TEST_GHE_TOKEN", "enterprise-token")
+	defer os.Unsetenv("GIT_XARGS_TEST_GHE_TOKEN")
+	os.Setenv("GIT_XARGS_TEST_GHE_APPROVER_TOKEN", "enterprise-approver-token")
+	defer os.Unsetenv("GIT_XARGS_TEST_GHE_APPROVER_TOKEN")
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.ApproveAndMerge = true
+	require.NoError(t, useGithubHosts(testConfig, []string{"github.example.com=GIT_XARGS_TEST_GHE_TOKEN:GIT_XARGS_TEST_GHE_APPROVER_TOKEN"}))
+	assert.Equal(t, "https://github.example.com/api/v3/", testConfig.GithubHostApproverClients["github.example.com"].BaseURL.String())
+	assert.Equal(t, map[string]string{"github.example.com": "enterprise-token"}, testConfig.GithubHostTokens)
+	assert.NoError(t, ensureGithubHostApprovers(testConfig))
+
+	testConfig = config.NewGitXargsTestConfig()
+	testConfig.ApproveAndMerge = true
+	require.NoError(t, useGithubHosts(testConfig, []string{"github.example.com=GIT_XARGS_TEST_GHE_TOKEN"}))
+	assert.IsType(t, types.GithubHostApproverNotSetErr{}, errors.Unwrap(ensureGithubHostApprovers(testConfig)))
+
+	testConfig.ApproveAndMerge = false
+	assert.NoError(t, ensureGithubHostApprovers(testConfig))
+}
+
+// Test that continue opens its pull requests with the commit message, title and description the run recorded, unless
+// they were passed again
+func TestRestoreRunMessages(t *testing.T) {
+	t.Parallel()
+
+	run := &state.Run{CommitMessage: "Upgrade CI", PullRequestTitle: "Upgrade CI to v2", PullRequestDescription: "See the migration guide"}
+
+	var restored *config.GitXargsConfig
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{common.GenericCommitMessageFlag, common.GenericPullRequestTitleFlag, common.GenericPullRequestDescriptionFlag}
+	app.Action = func(c *cli.Context) error {
+		restored = config.NewGitXargsTestConfig()
+		restored.PullRequestTitle = c.String(common.PullRequestTitleFlagName)
+		restoreRunMessages(c, restored, run)
+		return nil
+	}
+	require.NoError(t, app.Run([]string{"git-xargs", "--pull-request-title", "Upgrade CI to v3"}))
+
+	assert.Equal(t, "Upgrade CI", restored.CommitMessage)
+	assert.Equal(t, "Upgrade CI to v3", restored.PullRequestTitle)
+	assert.Equal(t, "See the migration guide", restored.PullRequestDescription)
+}
+
+// Test that the audit log records the flags git-xargs was invoked with by name only, leaving out their values
+func TestInvocationFlags(t *testing.T) {
+	t.Parallel()
+
+	args := []string{"git-xargs", "--api-header", "X-Auth: secret", "--slack-webhook-url=https://hooks.slack.com/secret", "--dry-run", "./upgrade.sh", "--", "-v"}
+	assert.Equal(t, []string{"--api-header", "--slack-webhook-url", "--dry-run"}, invocationFlags(args))
+}
+
+// Test that --output-file is read into the config, and that the run report is written to it in the --output format
+func TestPrintRunReportToOutputFile(t *testing.T) {
+	t.Parallel()
+
+	outputFile := filepath.Join(t.TempDir(), "report.json")
+
+	var testConfig *config.GitXargsConfig
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{common.GenericOutputFlag, common.GenericOutputFileFlag}
+	app.Action = func(c *cli.Context) error {
+		testConfig = config.NewGitXargsTestConfig()
+		testConfig.OutputFormat = c.String("output")
+		testConfig.OutputFile = c.String("output-file")
+		return nil
+	}
+	require.NoError(t, app.Run([]string{"git-xargs", "--output", common.OutputFormatJSON, "--output-file", outputFile}))
+	assert.Equal(t, outputFile, testConfig.OutputFile)
+
+	testConfig.Stats.TrackSingle(stats.ReposArchivedSkipped, mocks.GetMockGithubRepo())
+	require.NoError(t, printRunReport(testConfig))
+
+	contents, err := ioutil.ReadFile(outputFile)
+	require.NoError(t, err)
+	report := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(contents, &report))
+	assert.Contains(t, report, "repos")
+}
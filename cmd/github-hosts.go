@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// useGithubHosts configures a GitHub API client, and the token to clone and push with, for each GitHub Enterprise
+// Server passed via --github-host as <host>=<token-env-var>, so that the run can include repos on those hosts along
+// with the ones on github.com. A value of <host>=<token-env-var>:<approver-token-env-var> also configures the client
+// of the second identity --approve-and-merge approves pull requests on the host with. Like the client of the run, the
+// clients record or replay their interactions if --record or --replay was passed
+func useGithubHosts(config *config.GitXargsConfig, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	config.GithubHostClients = map[string]auth.GithubClient{}
+	config.GithubHostApproverClients = map[string]auth.GithubClient{}
+	config.GithubHostTokens = map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return errors.WithStackTrace(types.InvalidGithubHostFlagErr{Value: value})
+		}
+
+		host, envVars := util.NormalizeGithubHost(parts[0]), strings.SplitN(parts[1], ":", 2)
+		// github.com uses the clients and token of the run
+		if host == "" {
+			continue
+		}
+		if strings.Contains(host, "/") || strings.TrimSpace(envVars[0]) == "" || (len(envVars) == 2 && strings.TrimSpace(envVars[1]) == "") {
+			return errors.WithStackTrace(types.InvalidGithubHostFlagErr{Value: value})
+		}
+
+		client, token, err := githubHostClient(config, host, strings.TrimSpace(envVars[0]))
+		if err != nil {
+			return err
+		}
+		config.GithubHostClients[host] = client
+		config.GithubHostTokens[host] = token
+
+		if len(envVars) == 2 {
+			approverClient, _, err := githubHostClient(config, host, strings.TrimSpace(envVars[1]))
+			if err != nil {
+				return err
+			}
+			config.GithubHostApproverClients[host] = approverClient
+		}
+	}
+	return nil
+}
+
+// githubHostClient returns a GitHub API client for the supplied GitHub Enterprise Server, authenticating with the token
+// exported as the supplied environment variable, along with the token
+func githubHostClient(config *config.GitXargsConfig, host string, envVar string) (auth.GithubClient, string, error) {
+	token := os.Getenv(envVar)
+	if token == "" && config.ReplayFile == "" {
+		return auth.GithubClient{}, "", errors.WithStackTrace(types.GithubHostTokenNotSetErr{Host: host, EnvVar: envVar})
+	}
+
+	switch {
+	case config.ReplayFile != "":
+		return auth.ConfigureReplayingGithubClientForHost(host, config.Recording), token, nil
+	case config.RecordFile != "":
+		return auth.ConfigureRecordingGithubClientForHost(host, token, config.Recording), token, nil
+	default:
+		return auth.ConfigureGithubClientForHost(host, token), token, nil
+	}
+}
+
+// ensureGithubHostApprovers returns an error if --approve-and-merge was passed, but a --github-host doesn't name the
+// token of the second identity to approve the pull requests on it with
+func ensureGithubHostApprovers(config *config.GitXargsConfig) error {
+	if !config.ApproveAndMerge {
+		return nil
+	}
+	for host := range config.GithubHostClients {
+		if _, ok := config.GithubHostApproverClients[host]; !ok {
+			return errors.WithStackTrace(types.GithubHostApproverNotSetErr{Host: host})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// cancelOnInterrupt cancels the context of the run on SIGINT or SIGTERM, which stops the clones, commands, pushes and
+// API calls in flight and skips the repos that weren't started yet, so that the report of the repos processed so far can
+// still be written. A second signal exits immediately. The returned function stops listening for signals
+func cancelOnInterrupt(config *config.GitXargsConfig) func() {
+	logger := logging.GetLogger("git-xargs")
+
+	ctx, cancel := context.WithCancel(config.Context)
+	config.Context = ctx
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			logger.WithFields(logrus.Fields{
+				"Signal": sig,
+			}).Warn("Interrupted, stopping the repos being processed. Interrupt again to exit immediately")
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-signals:
+			os.Exit(common.InterruptedExitCode)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// ensureNotInterrupted returns an error carrying common.InterruptedExitCode if the run was interrupted, since its report
+// only covers the repos processed before the interrupt
+func ensureNotInterrupted(config *config.GitXargsConfig) error {
+	if config.Context.Err() == nil {
+		return nil
+	}
+
+	return errors.WithStackTrace(errors.ErrorWithExitCode{
+		Err:      types.RunInterruptedErr{},
+		ExitCode: common.InterruptedExitCode,
+	})
+}
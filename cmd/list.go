@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunList is the urfave cli Action for the list subcommand. It prints the full name of every selected repo, one per
+// line, without touching any of them, to check a repo selection before running a command against it, or to feed a
+// filtered selection back into git-xargs via stdin
+func RunList(c *cli.Context) error {
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if err := gitxargs_io.EnsureRepoSelectionPassed(config); err != nil {
+		return err
+	}
+
+	repos, err := repository.SelectRepos(config)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if _, err := fmt.Fprintln(c.App.Writer, repo.GetFullName()); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	return nil
+}
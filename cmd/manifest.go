@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// useManifest reads the manifest passed via --manifest, if any, and acts on its run as if its ID was passed via
+// --run-id. The run is imported into the state store once the store is opened
+func useManifest(c *cli.Context, config *config.GitXargsConfig) error {
+	manifestPath := c.String(common.ManifestFlagName)
+	if manifestPath == "" {
+		return nil
+	}
+	if config.RunIDSupplied {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "manifest", Second: "run-id", Reason: "the manifest names the run"})
+	}
+
+	runManifest, err := manifest.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+	config.Manifest = runManifest
+	config.RunID = runManifest.RunID
+	config.RunIDSupplied = true
+	return nil
+}
+
+// manifestConfig returns the snapshot of the supplied config recorded in the --output-manifest of the run
+func manifestConfig(config *config.GitXargsConfig) manifest.Config {
+	return manifest.Config{
+		BranchName:             config.BranchName,
+		BaseBranchName:         config.BaseBranchName,
+		CommitMessage:          config.CommitMessage,
+		PullRequestTitle:       config.PullRequestTitle,
+		PullRequestDescription: config.PullRequestDescription,
+		GithubOrg:              config.GithubOrg,
+		ReposFile:              config.ReposFile,
+		Repos:                  config.RepoSlice,
+		Draft:                  config.Draft,
+		DryRun:                 config.DryRun,
+		DryRunLevel:            config.DryRunLevel,
+		SkipPullRequests:       config.SkipPullRequests,
+		SkipArchivedRepos:      config.SkipArchivedRepos,
+		SkipReposWithOpenPRs:   config.SkipReposWithOpenPRs,
+		PushViaAPI:             config.PushViaAPI,
+		ApproveAndMerge:        config.ApproveAndMerge,
+		MergeMethod:            config.MergeMethod,
+		Reviewers:              config.Reviewers,
+		Assignees:              config.Assignees,
+		Tags:                   config.Tags,
+		MaxConcurrentRepos:     config.MaxConcurrentRepos,
+	}
+}
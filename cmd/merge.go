@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunMerge is the urfave cli Action for the merge subcommand. It merges every pull request opened by the run passed via
+// --run-id that is ready to merge, optionally deleting their branches afterwards
+func RunMerge(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs merging the run's pull requests...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if !config.RunIDSupplied {
+		return errors.WithStackTrace(types.NoRunIDProvidedErr{Command: "merge"})
+	}
+
+	if !gitxargs_io.IsValidMergeMethod(config.MergeMethod) {
+		return errors.WithStackTrace(types.InvalidMergeMethodErr{MergeMethod: config.MergeMethod})
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	if _, err := config.State.GetRun(config.RunID); err != nil {
+		return err
+	}
+
+	config.Stats.SetRunID(config.RunID)
+
+	if err := repository.MergeRunPullRequests(config); err != nil {
+		return err
+	}
+
+	return writeRunReport(config)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/printer"
+	"github.com/urfave/cli"
+)
+
+// RunPlan is the urfave cli Action for the plan subcommand. It selects repos and runs the command against them like a
+// regular run, but instead of pushing the resulting changes and opening pull requests, it records them in the plan file
+// passed via --out, so that they can be reviewed and then executed with the apply subcommand
+func RunPlan(c *cli.Context) error {
+	if !hasCommand(c) {
+		return cli.ShowCommandHelp(c, "plan")
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs planning...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := sanityCheckInputs(config); err != nil {
+		return err
+	}
+
+	config.Plan = plan.New()
+
+	if err := handleRepoProcessing(config); err != nil {
+		return err
+	}
+
+	config.Plan.RunID = config.RunID
+	config.Plan.CreatedAt = config.StartTime
+	config.Plan.Command = config.Args
+	config.Plan.BranchName = config.BranchName
+	config.Plan.BaseBranchName = config.BaseBranchName
+	config.Plan.CommitMessage = config.CommitMessage
+	config.Plan.Draft = config.Draft
+
+	if err := plan.Write(config.Plan, config.PlanFile); err != nil {
+		return err
+	}
+
+	printer.PrintPlan(config.Plan, config.PlanFile)
+
+	return nil
+}
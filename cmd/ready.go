@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/urfave/cli"
+)
+
+// RunReady is the urfave cli Action for the ready subcommand. It looks up the open draft pull requests that were opened
+// from the supplied --branch-name in every selected repo and marks them as ready for review in bulk
+func RunReady(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs marking draft pull requests as ready for review...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if err := gitxargs_io.EnsureValidOptionsPassed(config); err != nil {
+		return err
+	}
+
+	if err := gitxargs.RenderBranchName(config); err != nil {
+		return err
+	}
+
+	repos, err := repository.SelectRepos(config)
+	if err != nil {
+		return err
+	}
+
+	if err := repository.MarkDraftPullRequestsReady(config, repos); err != nil {
+		return err
+	}
+
+	return writeRunReport(config)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// useRecording swaps the GitHub API client of the run for one that records its interactions, if --record was passed,
+// or for one that replays a recording of them, if --replay was passed
+func useRecording(config *config.GitXargsConfig) error {
+	if config.RecordFile != "" && config.ReplayFile != "" {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "record", Second: "replay", Reason: "a run either records the GitHub API or replays a recording of it"})
+	}
+
+	if config.ReplayFile != "" {
+		recording, err := auth.LoadRecording(config.ReplayFile)
+		if err != nil {
+			return err
+		}
+		config.Recording = recording
+		config.GithubClient = auth.ConfigureReplayingGithubClient(recording)
+	}
+	if config.RecordFile != "" {
+		config.Recording = auth.NewRecording()
+		config.GithubClient = auth.ConfigureRecordingGithubClient(os.Getenv("GITHUB_OAUTH_TOKEN"), config.Recording)
+	}
+	return nil
+}
+
+// approverGithubClient returns the GitHub API client of the second identity used by --approve-and-merge, which records
+// its interactions along with the ones of the run, or replays them, like the client of the run
+func approverGithubClient(config *config.GitXargsConfig) auth.GithubClient {
+	switch {
+	case config.ReplayFile != "":
+		return auth.ConfigureReplayingGithubClient(config.Recording)
+	case config.RecordFile != "":
+		return auth.ConfigureRecordingGithubClient(os.Getenv("GITHUB_APPROVER_OAUTH_TOKEN"), config.Recording)
+	default:
+		return auth.ConfigureApproverGithubClient()
+	}
+}
+
+// saveRecording saves the interactions recorded during the run to the file passed via --record, if it was passed
+func saveRecording(config *config.GitXargsConfig) {
+	if config.RecordFile == "" {
+		return
+	}
+
+	fields := logrus.Fields{"File": config.RecordFile}
+	saved := publishBestEffort("Error saving the recording of the GitHub API interactions", fields, func() error {
+		return config.Recording.Save(config.RecordFile)
+	})
+	if saved {
+		logging.GetLogger("git-xargs").WithFields(fields).Info("Saved the recording of the GitHub API interactions")
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/printer"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunReportDiff is the urfave cli Action for the report diff subcommand. It looks up the repos of the two runs passed
+// as arguments in the state store, and prints the repos whose outcome changed between them, e.g. to check that a
+// retry fixed the repos that failed without breaking any others
+func RunReportDiff(c *cli.Context) error {
+	if len(c.Args()) != 2 {
+		return errors.WithStackTrace(types.InvalidReportDiffArgsErr{})
+	}
+	beforeRunID, afterRunID := c.Args().Get(0), c.Args().Get(1)
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	runs := []*state.Run{}
+	repos := [][]*state.Repo{}
+	for _, runID := range []string{beforeRunID, afterRunID} {
+		run, err := config.State.GetRun(runID)
+		if err != nil {
+			return err
+		}
+		runRepos, err := config.State.ListRepos(runID)
+		if err != nil {
+			return err
+		}
+		runs = append(runs, run)
+		repos = append(repos, runRepos)
+	}
+
+	printer.PrintRunComparison(os.Stdout, runs[0], runs[1], state.CompareRepos(repos[0], repos[1]))
+	return nil
+}
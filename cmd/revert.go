@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/urfave/cli"
+)
+
+// RunRevert is the urfave cli Action for the revert subcommand. For every merged pull request opened by the run passed
+// via --run-id, it opens a pull request reverting its changes. The revert is a run of its own, with a new run ID, so its
+// pull requests can in turn be tracked, merged or closed
+func RunRevert(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs reverting the run's merged pull requests...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if !config.RunIDSupplied {
+		return errors.WithStackTrace(types.NoRunIDProvidedErr{Command: "revert"})
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	revertedRunID := config.RunID
+	if _, err := config.State.GetRun(revertedRunID); err != nil {
+		return err
+	}
+
+	repository.SetRevertDefaults(config, revertedRunID)
+
+	// Give the revert a run ID of its own, so the run markers on its commits and pull requests point at the revert
+	config.RunID = util.NewRunID(config.StartTime)
+	config.RunIDSupplied = false
+	config.Stats.SetRunID(config.RunID)
+	config.Stats.SetCommand([]string{"revert", revertedRunID})
+
+	err = config.State.StartRun(state.Run{
+		ID:             config.RunID,
+		Command:        []string{"revert", revertedRunID},
+		BranchName:     config.BranchName,
+		BaseBranchName: config.BaseBranchName,
+		StartedAt:      config.StartTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := repository.RevertRun(config, revertedRunID); err != nil {
+		return err
+	}
+
+	if err := config.State.RecordEvents(config.RunID, config.Stats.GetRepos()); err != nil {
+		return err
+	}
+	if err := config.State.FinishRun(config.RunID, time.Now()); err != nil {
+		return err
+	}
+
+	return writeRunReport(config)
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/update"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"golang.org/x/oauth2"
+)
+
+// RunSelfUpdate is the urfave cli Action for the self-update subcommand. It looks up the latest GitHub release of
+// git-xargs and, if it is newer than the running version, replaces the running binary with the one from the release,
+// once it has been verified against the checksums published with the release
+func RunSelfUpdate(c *cli.Context) error {
+	ctx := context.Background()
+	current := c.App.Version
+
+	release, err := update.LatestRelease(ctx, releasesClient().Repositories)
+	if err != nil {
+		return err
+	}
+	latest := release.GetTagName()
+
+	if current == "" && !c.Bool(common.ForceFlagName) {
+		fmt.Fprintf(c.App.Writer, "This git-xargs was built from source, so it has no version to compare with %s, the latest release. Pass --force to replace it with the release anyway\n", latest)
+		return nil
+	}
+	if !update.IsNewer(latest, current) && !c.Bool(common.ForceFlagName) {
+		fmt.Fprintf(c.App.Writer, "git-xargs %s is the latest release\n", current)
+		return nil
+	}
+	if c.Bool(common.CheckOnlyFlagName) {
+		fmt.Fprintf(c.App.Writer, "git-xargs %s is available, and this is %s. Run git-xargs self-update to install it\n", latest, describeVersion(current))
+		return nil
+	}
+
+	path, err := os.Executable()
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if strings.Contains(path, "/Cellar/") {
+		return errors.WithStackTrace(types.HomebrewInstallErr{Path: path})
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Release": latest,
+		"Path":    path,
+	}).Info("Downloading the latest release of git-xargs")
+
+	binary, err := update.Download(ctx, http.DefaultClient, release)
+	if err != nil {
+		return err
+	}
+	if err := update.ReplaceExecutable(path, binary); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "Updated git-xargs at %s from %s to %s\n", path, describeVersion(current), latest)
+	return nil
+}
+
+// releasesClient returns a GitHub API client for looking up releases. Releases are public, so GITHUB_OAUTH_TOKEN isn't
+// required, but it is used if it is set, for its higher rate limit
+func releasesClient() *github.Client {
+	token := os.Getenv("GITHUB_OAUTH_TOKEN")
+	if token == "" {
+		return github.NewClient(nil)
+	}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	return github.NewClient(oauth2.NewClient(context.Background(), ts))
+}
+
+// describeVersion returns the supplied version of git-xargs, or a description of it for binaries built from source,
+// which have no version set
+func describeVersion(version string) string {
+	if version == "" {
+		return "a build without a version"
+	}
+	return version
+}
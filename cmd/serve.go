@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/server"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// RunServe is the urfave cli Action for the serve subcommand. It serves the git-xargs REST API on --listen until it is
+// interrupted, so that other systems can submit runs, query their status and cancel them over HTTP. Every request but
+// the health check must carry the token exported as GIT_XARGS_SERVE_TOKEN as a bearer token, since a run executes
+// arbitrary commands
+func RunServe(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	token := os.Getenv("GIT_XARGS_SERVE_TOKEN")
+	if token == "" {
+		return errors.WithStackTrace(types.NoServeTokenProvidedErr{})
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	gitxargsConfig, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+	if err := gitxargs.OpenStateStore(gitxargsConfig); err != nil {
+		return err
+	}
+	defer gitxargsConfig.State.Close()
+
+	httpServer := &http.Server{
+		Addr:    c.String("listen"),
+		Handler: server.New(token, gitxargsConfig.State, config.NewGitXargsConfig, sanityCheckInputs, gitxargs.ProcessRun),
+	}
+
+	// Stop accepting requests on SIGINT or SIGTERM
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		sig := <-signals
+		logger.WithFields(logrus.Fields{
+			"Signal": sig,
+		}).Info("Stopping server")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(ctx)
+	}()
+
+	logger.WithFields(logrus.Fields{
+		"Address": httpServer.Addr,
+	}).Info("git-xargs serving the API...")
+
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/notify"
+	"github.com/gruntwork-io/git-xargs/printer"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// RunStatus is the urfave cli Action for the status subcommand. It looks up every pull request opened by the run passed
+// via --run-id in the state store, and prints the current state, checks and review state of each
+func RunStatus(c *cli.Context) error {
+	logger := logging.GetLogger("git-xargs")
+
+	logger.Info("git-xargs looking up the status of the run's pull requests...")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if err := auth.EnsureGithubOauthTokenSet(); err != nil {
+		return err
+	}
+
+	if !config.RunIDSupplied {
+		return errors.WithStackTrace(types.NoRunIDProvidedErr{Command: "status"})
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	run, err := config.State.GetRun(config.RunID)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := repository.GetRunStatus(config)
+	if err != nil {
+		return err
+	}
+
+	printer.PrintRunStatus(c.App.Writer, run, statuses)
+
+	if config.JiraIssue != "" && config.JiraTransition != "" && repository.AllPullRequestsMerged(statuses) {
+		return transitionJiraIssue(config)
+	}
+	return nil
+}
+
+// transitionJiraIssue moves --jira-issue through --jira-transition, once every pull request of the run is merged
+func transitionJiraIssue(config *config.GitXargsConfig) error {
+	logger := logging.GetLogger("git-xargs")
+
+	if config.JiraURL == "" {
+		return errors.WithStackTrace(types.JiraIssueWithoutURLErr{})
+	}
+	transitioned, err := notify.TransitionJiraIssue(config.JiraURL, config.JiraIssue, config.JiraTransition)
+	if err != nil {
+		return err
+	}
+	if !transitioned {
+		logger.WithFields(logrus.Fields{
+			"Issue":      config.JiraIssue,
+			"Transition": config.JiraTransition,
+		}).Info("Every pull request of the run is merged, and the Jira issue is already in that status")
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Issue":      config.JiraIssue,
+		"Transition": config.JiraTransition,
+	}).Info("Every pull request of the run is merged, so the Jira issue was transitioned")
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/notify"
+	"github.com/gruntwork-io/git-xargs/printer"
+	"github.com/gruntwork-io/git-xargs/version"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// telemetryCommand returns the name of the subcommand being run, for the --telemetry-endpoint payload, or "run" for a
+// regular run
+func telemetryCommand(c *cli.Context) string {
+	if c.Command.Name == "" {
+		return "run"
+	}
+	return c.Command.FullName()
+}
+
+// flagsUsed returns the names of the flags of the command being run that were set, whether on the command line, via
+// their environment variables or in the config file, sorted by name. Only their names are returned, since their values
+// may name repos, orgs or people
+func flagsUsed(c *cli.Context) []string {
+	flags := c.Command.Flags
+	if c.Command.Name == "" {
+		flags = c.App.Flags
+	}
+
+	names := []string{}
+	for _, flag := range flags {
+		name := strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+		if c.IsSet(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sendTelemetry sends the anonymized metrics of the run to --telemetry-endpoint
+func sendTelemetry(config *config.GitXargsConfig) {
+	fields := logrus.Fields{"Endpoint": config.TelemetryEndpoint}
+	sent := publishBestEffort("Error sending the run telemetry", fields, func() error {
+		var payload bytes.Buffer
+		err := config.Stats.WriteTelemetryPayload(&payload, printer.TelemetryUsage{
+			Version:  version.Version,
+			Command:  config.TelemetryCommand,
+			Flags:    config.TelemetryFlags,
+			APICalls: config.GithubClient.APICalls.Count() + config.ApproverGithubClient.APICalls.Count(),
+		})
+		if err != nil {
+			return err
+		}
+		return notify.PostWebhook(config.TelemetryEndpoint, payload.Bytes())
+	})
+	if sent {
+		logging.GetLogger("git-xargs").WithFields(fields).Debug("Sent the run telemetry")
+	}
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the flags set on the command line and in the config file are reported by name, and the others left out
+func TestFlagsUsed(t *testing.T) {
+	t.Parallel()
+
+	c, _, err := runWithConfigFile(t, "dry-run: true\n", "--branch-name", "upgrade-ci")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"branch-name", "config", "dry-run"}, flagsUsed(c))
+	assert.Equal(t, "run", telemetryCommand(c))
+}
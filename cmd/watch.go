@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/gitxargs"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// RunWatch is the urfave cli Action for the watch subcommand. It runs git-xargs on the --schedule cron schedule until
+// it is interrupted. Every scheduled run re-selects the repos and runs the command against them, with a run ID of its
+// own, skipping the repos that still have a pull request open from --branch-name. Pull requests are therefore only
+// opened for repos that have drifted since their last pull request was merged or closed
+func RunWatch(c *cli.Context) error {
+	if !hasCommand(c) {
+		return cli.ShowCommandHelp(c, "watch")
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+
+	if config.RunIDSupplied || config.Resume {
+		return errors.WithStackTrace(types.WatchWithRunIDErr{})
+	}
+
+	schedule, err := gitxargs_io.ParseSchedule(config.Schedule)
+	if err != nil {
+		return err
+	}
+
+	if err := sanityCheckInputs(config); err != nil {
+		return err
+	}
+
+	// Repos piped to stdin can only be read once, so hold on to them for every scheduled run
+	reposFromStdIn := config.RepoFromStdIn
+
+	// Stop between runs on SIGINT or SIGTERM. A signal received in the middle of a run interrupts that run, which still
+	// prints its report, and then stops the watch
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for {
+		next := schedule.Next(time.Now())
+		logger.WithFields(logrus.Fields{
+			"Schedule": config.Schedule,
+			"Next run": next,
+		}).Info("git-xargs watching, waiting for the next scheduled run...")
+
+		select {
+		case <-time.After(time.Until(next)):
+		case sig := <-signals:
+			logger.WithFields(logrus.Fields{
+				"Signal": sig,
+			}).Info("Stopping watch")
+			return nil
+		}
+
+		if err := runScheduledRun(c, reposFromStdIn); err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+			}).Error("Scheduled run failed")
+		}
+	}
+}
+
+// runScheduledRun performs a single scheduled run of the watch subcommand. The config is parsed afresh for every run,
+// so that each gets its own run ID, start time and report. The state store is only held open for the duration of the
+// run, so that other subcommands can use it in between
+func runScheduledRun(c *cli.Context, reposFromStdIn []string) error {
+	config, err := parseGitXargsConfig(c)
+	if err != nil {
+		return err
+	}
+	config.RepoFromStdIn = reposFromStdIn
+	config.SkipReposWithOpenPRs = true
+
+	if err := sanityCheckInputs(config); err != nil {
+		return err
+	}
+
+	if err := gitxargs.OpenStateStore(config); err != nil {
+		return err
+	}
+	defer config.State.Close()
+
+	if err := gitxargs.OpenEventStream(config); err != nil {
+		return err
+	}
+	defer config.Events.Close()
+
+	return handleRepoProcessing(config)
+}
@@ -1,8 +1,16 @@
 package common
 
-import "github.com/urfave/cli"
+import (
+	"time"
+
+	"github.com/urfave/cli"
+)
 
 const (
+	ConfigFileFlagName             = "config"
+	ProfileFlagName                = "profile"
+	GithubTokenEnvFlagName         = "github-token-env"
+	APIHeaderFlagName              = "api-header"
 	GithubOrgFlagName              = "github-org"
 	DraftPullRequestFlagName       = "draft"
 	DryRunFlagName                 = "dry-run"
@@ -15,68 +23,803 @@ const (
 	BaseBranchFlagName             = "base-branch-name"
 	PullRequestTitleFlagName       = "pull-request-title"
 	PullRequestDescriptionFlagName = "pull-request-description"
+	PullRequestFooterFlagName      = "pull-request-footer"
+	CommitStatusFlagName           = "commit-status"
+	StateFileFlagName              = "state-file"
+	DeleteBranchFlagName           = "delete-branch"
+	CloseCommentFlagName           = "close-comment"
+	BranchPatternFlagName          = "branch-pattern"
+	SkipStateFlagName              = "skip-state"
+	ResumeFlagName                 = "resume"
+	PlanOutFlagName                = "out"
+	ScheduleFlagName               = "schedule"
+	ListenFlagName                 = "listen"
+	OutputFlagName                 = "output"
+	OutputFileFlagName             = "output-file"
+	ReportCSVFlagName              = "report-csv"
+	ReportMarkdownFlagName         = "report-markdown"
+	ReportJUnitFlagName            = "report-junit"
+	ReportHTMLFlagName             = "report-html"
+	WebhookURLFlagName             = "webhook-url"
+	WebhookIncludeEventsFlagName   = "webhook-include-events"
+	SlackWebhookURLFlagName        = "slack-webhook-url"
+	SlackChannelFlagName           = "slack-channel"
+	EmailToFlagName                = "email-to"
+	EmailFromFlagName              = "email-from"
+	SMTPServerFlagName             = "smtp-server"
+	AllowedFailuresFlagName        = "allowed-failures"
+	AllowedFailureRateFlagName     = "allowed-failure-rate"
+	MinSuccessRateFlagName         = "min-success-rate"
+	FailFastFlagName               = "fail-fast"
+	MaxFailuresFlagName            = "max-failures"
+	MaxFailureRateFlagName         = "max-failure-rate"
+	RepoTimeoutFlagName            = "repo-timeout"
+	ProgressFlagName               = "progress"
+	PushgatewayURLFlagName         = "pushgateway-url"
+	MetricsJobFlagName             = "metrics-job"
+	TelemetryEndpointFlagName      = "telemetry-endpoint"
+	OTLPEndpointFlagName           = "otlp-endpoint"
+	CreateTrackingIssueFlagName    = "create-tracking-issue"
+	ReportGistFlagName             = "report-gist"
+	ReportUploadFlagName           = "report-upload"
+	EventsFileFlagName             = "events-file"
+	AuditLogDirFlagName            = "audit-log-dir"
+	AuditLogHashChainFlagName      = "audit-log-hash-chain"
+	AuditLastHashFlagName          = "last-hash"
+	CommitStatusURLFlagName        = "commit-status-url"
 	MaxConcurrentReposFlagName     = "max-concurrent-repos"
+	AutoConcurrencyFlagName        = "auto-concurrency"
+	PluginFlagName                 = "plugin"
+	PickFlagName                   = "pick"
+	CheckOnlyFlagName              = "check"
+	ForceFlagName                  = "force"
+	TagFlagName                    = "tag"
+	IgnoreRepoConfigFlagName       = "ignore-repo-config"
+	DryRunLevelFlagName            = "dry-run-level"
+	OlderThanFlagName              = "older-than"
+	SkipCITrailerFlagName          = "skip-ci-trailer"
+	SkipCIInFlagName               = "skip-ci-in"
+	NoSkipCIFlagName               = "no-skip-ci"
+	RecordFlagName                 = "record"
+	ReplayFlagName                 = "replay"
+	PutFileFlagName                = "put-file"
+	DeleteFileFlagName             = "delete-file"
+	MoveFileFlagName               = "move-file"
+	PushViaAPIFlagName             = "push-via-api"
+	MonorepoManifestFlagName       = "monorepo-manifest"
+	MonorepoPullRequestsFlagName   = "monorepo-pull-requests"
+	GithubHostFlagName             = "github-host"
+	JiraURLFlagName                = "jira-url"
+	JiraIssueFlagName              = "jira-issue"
+	JiraTransitionFlagName         = "jira-transition"
+	CheckBranchProtectionFlagName  = "check-branch-protection"
+	SigningKeyFlagName             = "signing-key"
+	MergeChecksTimeoutFlagName     = "merge-checks-timeout"
+	DefaultMergeChecksTimeout      = time.Duration(0)
+	DeployKeysDirFlagName          = "deploy-keys-dir"
+	EnvFilesDirFlagName            = "env-files-dir"
+	RepoEnvFileFlagName            = "repo-env-file"
+	PathLabelsFlagName             = "path-labels"
+	PRScheduleFlagName             = "pr-schedule"
+	PRScheduleTimezoneFlagName     = "pr-schedule-timezone"
+	PRScheduleMaxFlagName          = "pr-schedule-max-per-window"
+	MaxOpenPRsFlagName             = "max-open-prs"
+	MaxOpenPRsScopeFlagName        = "max-open-prs-scope"
+	ReportDiffLinesFlagName        = "report-diff-lines"
+	OutputManifestFlagName         = "output-manifest"
+	ManifestFlagName               = "manifest"
+	CloneConcurrencyFlagName       = "clone-concurrency"
+	CommandConcurrencyFlagName     = "command-concurrency"
+	PushConcurrencyFlagName        = "push-concurrency"
+	PullRequestConcurrencyFlagName = "pull-request-concurrency"
+	DraftIfDiffLinesOverFlagName   = "draft-if-diff-lines-over"
+	DraftIfChecksPendingFlagName   = "draft-if-checks-pending"
+	DraftIfRepoMatchesFlagName     = "draft-if-repo-matches"
+	ProjectFlagName                = "project"
+	ProjectFieldFlagName           = "project-field"
+	SkipReposWithOpenPRsFlagName   = "skip-repos-with-open-pull-requests"
+	ReviewersFlagName              = "reviewers"
+	ReviewerStrategyFlagName       = "reviewer-strategy"
+	ReviewersPerPRFlagName         = "reviewers-per-pull-request"
+	ReviewersFromBlameFlagName     = "reviewers-from-blame"
+	BlameReviewersCountFlagName    = "blame-reviewers-count"
+	ExcludeReviewersFlagName       = "exclude-reviewers"
+	RunIDFlagName                  = "run-id"
+	ApproveAndMergeFlagName        = "approve-and-merge"
+	MaxFilesPerPRFlagName          = "max-files-per-pull-request"
+	SplitByFlagName                = "split-by"
+	MergeMethodFlagName            = "merge-method"
+	SkipRunMarkersFlagName         = "skip-run-markers"
+	AssigneesFlagName              = "assignees"
+	AssigneeStrategyFlagName       = "assignee-strategy"
+	AssigneesPerPRFlagName         = "assignees-per-pull-request"
 	DefaultCommitMessage           = "git-xargs programmatic commit"
 	DefaultPullRequestTitle        = "git-xargs programmatic pull request"
 	DefaultPullRequestDescription  = "git-xargs programmatic pull request"
 	DefaultMaxConcurrentRepos      = 0
+	DefaultReviewerStrategy        = "all"
+	DefaultReviewersPerPR          = 1
+	DefaultBlameReviewersCount     = 2
+	RunIDTrailerKey                = "Git-Xargs-Run-Id"
+	DefaultMergeMethod             = "merge"
+	CommitStatusContext            = "git-xargs"
+	DefaultCloseComment            = "Closed by git-xargs, because the campaign that opened this pull request (run {{.RunID}}) was aborted."
+	SplitByDirectory               = "directory"
+	SplitByFile                    = "file"
+	MaxOpenPRsScopeRun             = "run"
+	MaxOpenPRsScopeOrg             = "org"
+	RunIDMarkerLabelPrefix         = "git-xargs:"
+	DefaultPlanFile                = "git-xargs-plan.json"
+	DefaultListenAddress           = "127.0.0.1:8080"
+	OutputFormatTable              = "table"
+	OutputFormatJSON               = "json"
+	FailedReposExitCode            = 2
+	InterruptedExitCode            = 130
+	DefaultMetricsJob              = "git-xargs"
+	DefaultOTLPServiceName         = "git-xargs"
+	RepoConfigFileName             = ".git-xargs.yml"
+	DefaultOlderThanDays           = 1
+	RepoEnvFileName                = ".git-xargs.env"
+	OptOutMarkerFileName           = ".git-xargs-ignore"
+	OptOutTopic                    = "gitxargs-ignore"
+	DryRunListOnly                 = "list-only"
+	DryRunCloneAndRun              = "clone-and-run"
+	DryRunCommitNoPush             = "commit-no-push"
+	DryRunPushNoPR                 = "push-no-pr"
+	SkipCIInCommit                 = "commit"
+	SkipCIInTitle                  = "title"
+	SkipCIInBoth                   = "both"
+	MonorepoCombined               = "combined"
+	MonorepoPerDirectory           = "per-directory"
+	ObjectStorageS3                = "s3"
+	ObjectStorageGCS               = "gs"
 )
 
 var (
+	GenericConfigFileFlag = cli.StringFlag{
+		Name:   ConfigFileFlagName,
+		EnvVar: "GITXARGS_CONFIG, GIT_XARGS_CONFIG",
+		Usage:  "Read the flags and the command of the run from this YAML or TOML file, keyed by flag name, with the flags passed on the command line taking precedence. Defaults to git-xargs.config.yml, git-xargs.config.yaml or git-xargs.config.toml in the working directory, if one exists, which can't set the command",
+	}
+	GenericProfileFlag = cli.StringFlag{
+		Name:   ProfileFlagName,
+		EnvVar: "GITXARGS_PROFILE, GIT_XARGS_PROFILE",
+		Usage:  "Use the flags of this profile, from the profiles section of the config file, on top of the flags set at the top level of the config file",
+	}
+	GenericGithubTokenEnvFlag = cli.StringFlag{
+		Name:   GithubTokenEnvFlagName,
+		EnvVar: "GITXARGS_GITHUB_TOKEN_ENV, GIT_XARGS_GITHUB_TOKEN_ENV",
+		Usage:  "Read the Github personal access token from this environment variable, instead of GITHUB_OAUTH_TOKEN. Useful for giving each profile a token of its own",
+	}
+	GenericAPIHeaderFlag = cli.StringSliceFlag{
+		Name:   APIHeaderFlagName,
+		EnvVar: "GITXARGS_API_HEADER, GIT_XARGS_API_HEADER",
+		Usage:  "A static header to send with every GitHub API request, as <name>: <value>, e.g. for an authenticating proxy in front of a GitHub Enterprise Server. Can be invoked multiple times",
+	}
 	GenericGithubOrgFlag = cli.StringFlag{
-		Name:  GithubOrgFlagName,
-		Usage: "The Github organization to fetch all repositories from.",
+		Name:   GithubOrgFlagName,
+		EnvVar: "GITXARGS_GITHUB_ORG, GIT_XARGS_GITHUB_ORG",
+		Usage:  "The Github organization to fetch all repositories from.",
 	}
 	GenericDraftPullRequestFlag = cli.BoolFlag{
-		Name:  DraftPullRequestFlagName,
-		Usage: "Whether to open pull requests in draft mode",
+		Name:   DraftPullRequestFlagName,
+		EnvVar: "GITXARGS_DRAFT, GIT_XARGS_DRAFT",
+		Usage:  "Whether to open pull requests in draft mode",
 	}
 	GenericDryRunFlag = cli.BoolFlag{
-		Name:  DryRunFlagName,
-		Usage: "When dry-run is set to true, no local branch changes will pushed and no pull requests will be opened.",
+		Name:   DryRunFlagName,
+		EnvVar: "GITXARGS_DRY_RUN, GIT_XARGS_DRY_RUN",
+		Usage:  "When dry-run is set to true, no local branch changes will pushed and no pull requests will be opened.",
+	}
+	GenericDryRunLevelFlag = cli.StringFlag{
+		Name:   DryRunLevelFlagName,
+		EnvVar: "GITXARGS_DRY_RUN_LEVEL, GIT_XARGS_DRY_RUN_LEVEL",
+		Usage:  "How much of a run to go through without irreversible effects: list-only lists the selected repos, clone-and-run also clones them, runs the command and prints the diffs, commit-no-push also commits the changes locally, which is what --dry-run does, and push-no-pr also pushes the branches, without opening pull requests",
 	}
 	GenericSkipPullRequestFlag = cli.BoolFlag{
-		Name:  SkipPullRequestsFlagName,
-		Usage: "When skip-pull-requests is set to true, no pull requests will be opened. All changes will be committed and pushed to the specified branch directly.",
+		Name:   SkipPullRequestsFlagName,
+		EnvVar: "GITXARGS_SKIP_PULL_REQUESTS, GIT_XARGS_SKIP_PULL_REQUESTS",
+		Usage:  "When skip-pull-requests is set to true, no pull requests will be opened. All changes will be committed and pushed to the specified branch directly.",
 	}
 	GenericSkipArchivedReposFlag = cli.BoolFlag{
-		Name:  SkipArchivedReposFlagName,
-		Usage: "Used in conjunction with github-org, will exclude archived repositories.",
+		Name:   SkipArchivedReposFlagName,
+		EnvVar: "GITXARGS_SKIP_ARCHIVED_REPOS, GIT_XARGS_SKIP_ARCHIVED_REPOS",
+		Usage:  "Used in conjunction with github-org, will exclude archived repositories.",
+	}
+	GenericSkipReposWithOpenPRsFlag = cli.BoolFlag{
+		Name:   SkipReposWithOpenPRsFlagName,
+		EnvVar: "GITXARGS_SKIP_REPOS_WITH_OPEN_PULL_REQUESTS, GIT_XARGS_SKIP_REPOS_WITH_OPEN_PULL_REQUESTS",
+		Usage:  "Skip repos that already have an open pull request from the branch specified by --branch-name, before cloning them. Makes re-running a campaign idempotent.",
 	}
 	GenericRepoFlag = cli.StringSliceFlag{
-		Name:  RepoFlagName,
-		Usage: "A single repo name to run the command on in the format of <github-organization/repo-name>. Can be invoked multiple times with different repo names",
+		Name:   RepoFlagName,
+		EnvVar: "GITXARGS_REPO, GIT_XARGS_REPO",
+		Usage:  "A single repo name to run the command on in the format of <github-organization/repo-name>. Can be invoked multiple times with different repo names",
 	}
 	GenericRepoFileFlag = cli.StringFlag{
-		Name:  ReposFileFlagName,
-		Usage: "The path to a file containing repos, one per line in the format of <github-organization/repo-name>",
+		Name:   ReposFileFlagName,
+		EnvVar: "GITXARGS_REPOS, GIT_XARGS_REPOS",
+		Usage:  "The path to a file containing repos, one per line in the format of <github-organization/repo-name>",
 	}
 	GenericBranchFlag = cli.StringFlag{
-		Name:  BranchFlagName,
-		Usage: "The name of the branch on which changes will be made",
+		Name:   BranchFlagName,
+		EnvVar: "GITXARGS_BRANCH_NAME, GIT_XARGS_BRANCH_NAME",
+		Usage:  "The name of the branch on which changes will be made. Supports the placeholders {{.RunID}} and {{.Date}}",
+	}
+	GenericRunIDFlag = cli.StringFlag{
+		Name:   RunIDFlagName + ", run",
+		EnvVar: "GITXARGS_RUN_ID, GIT_XARGS_RUN_ID",
+		Usage:  "The ID of this run, used in the run markers git-xargs leaves on commits and pull requests. Defaults to a newly generated ID. Pass the ID of an earlier run to continue that campaign",
+	}
+	GenericApproveAndMergeFlag = cli.BoolFlag{
+		Name:   ApproveAndMergeFlagName,
+		EnvVar: "GITXARGS_APPROVE_AND_MERGE, GIT_XARGS_APPROVE_AND_MERGE",
+		Usage:  "Approve each opened pull request as the identity whose token is exported as GITHUB_APPROVER_OAUTH_TOKEN, then merge it. Only use this where your organization sanctions this workflow",
+	}
+	GenericMergeMethodFlag = cli.StringFlag{
+		Name:   MergeMethodFlagName,
+		EnvVar: "GITXARGS_MERGE_METHOD, GIT_XARGS_MERGE_METHOD",
+		Usage:  "The method used to merge pull requests when --approve-and-merge is passed. One of merge, squash or rebase",
+		Value:  DefaultMergeMethod,
+	}
+	GenericMaxFilesPerPRFlag = cli.IntFlag{
+		Name:   MaxFilesPerPRFlagName,
+		EnvVar: "GITXARGS_MAX_FILES_PER_PULL_REQUEST, GIT_XARGS_MAX_FILES_PER_PULL_REQUEST",
+		Usage:  "Split changes that touch more than this many files across several branches and pull requests, each touching at most this many files. Defaults to 0, which never splits changes",
+	}
+	GenericSplitByFlag = cli.StringFlag{
+		Name:   SplitByFlagName,
+		EnvVar: "GITXARGS_SPLIT_BY, GIT_XARGS_SPLIT_BY",
+		Usage:  "How changes are split when --max-files-per-pull-request is exceeded. One of directory, which keeps files in the same directory together, or file",
+		Value:  SplitByDirectory,
+	}
+	GenericSkipRunMarkersFlag = cli.BoolFlag{
+		Name:   SkipRunMarkersFlagName,
+		EnvVar: "GITXARGS_SKIP_RUN_MARKERS, GIT_XARGS_SKIP_RUN_MARKERS",
+		Usage:  "Do not add the run ID commit trailer, pull request body marker and marker label to the commits and pull requests git-xargs creates",
 	}
 	GenericBaseBranchFlag = cli.StringFlag{
-		Name:  BaseBranchFlagName,
-		Usage: "The base branch that changes should be merged into",
+		Name:   BaseBranchFlagName,
+		EnvVar: "GITXARGS_BASE_BRANCH_NAME, GIT_XARGS_BASE_BRANCH_NAME",
+		Usage:  "The base branch that changes should be merged into",
 	}
 	GenericCommitMessageFlag = cli.StringFlag{
-		Name:  CommitMessageFlagName,
-		Usage: "The commit message to use when creating commits from changes introduced by your command or script",
-		Value: DefaultCommitMessage,
+		Name:   CommitMessageFlagName,
+		EnvVar: "GITXARGS_COMMIT_MESSAGE, GIT_XARGS_COMMIT_MESSAGE",
+		Usage:  "The commit message to use when creating commits from changes introduced by your command or script",
+		Value:  DefaultCommitMessage,
 	}
 	GenericPullRequestTitleFlag = cli.StringFlag{
-		Name:  PullRequestTitleFlagName,
-		Usage: "The title to add to pull requests opened by git-xargs",
-		Value: DefaultPullRequestTitle,
+		Name:   PullRequestTitleFlagName,
+		EnvVar: "GITXARGS_PULL_REQUEST_TITLE, GIT_XARGS_PULL_REQUEST_TITLE",
+		Usage:  "The title to add to pull requests opened by git-xargs. Supports the placeholders {{.RepoOwner}}, {{.RepoName}}, {{.FullName}}, {{.Date}} and {{.RunID}}",
+		Value:  DefaultPullRequestTitle,
 	}
 	GenericPullRequestDescriptionFlag = cli.StringFlag{
-		Name:  PullRequestDescriptionFlagName,
-		Usage: "The description to add to pull requests opened by git-xargs",
-		Value: DefaultPullRequestDescription,
+		Name:   PullRequestDescriptionFlagName,
+		EnvVar: "GITXARGS_PULL_REQUEST_DESCRIPTION, GIT_XARGS_PULL_REQUEST_DESCRIPTION",
+		Usage:  "The description to add to pull requests opened by git-xargs",
+		Value:  DefaultPullRequestDescription,
+	}
+	GenericPullRequestFooterFlag = cli.StringFlag{
+		Name:   PullRequestFooterFlagName,
+		Usage:  "A footer appended to the body of every pull request opened by git-xargs, below the description. Supports the same placeholders as --pull-request-title",
+		EnvVar: "GITXARGS_PULL_REQUEST_FOOTER, GIT_XARGS_PULL_REQUEST_FOOTER",
+	}
+	GenericCommitStatusFlag = cli.BoolFlag{
+		Name:   CommitStatusFlagName,
+		EnvVar: "GITXARGS_COMMIT_STATUS, GIT_XARGS_COMMIT_STATUS",
+		Usage:  "Set a commit status with the context \"git-xargs\" on the head of every pushed branch, so the change can be traced back to the run that made it",
+	}
+	GenericCommitStatusURLFlag = cli.StringFlag{
+		Name:   CommitStatusURLFlagName,
+		EnvVar: "GITXARGS_COMMIT_STATUS_URL, GIT_XARGS_COMMIT_STATUS_URL",
+		Usage:  "The URL the commit status set by --commit-status links to, such as where the run report is published. Supports the same placeholders as --pull-request-title",
+	}
+	GenericDeleteBranchFlag = cli.BoolFlag{
+		Name:   DeleteBranchFlagName,
+		EnvVar: "GITXARGS_DELETE_BRANCH, GIT_XARGS_DELETE_BRANCH",
+		Usage:  "Delete the branch of every pull request once it has been merged or closed",
+	}
+	GenericBranchPatternFlag = cli.StringFlag{
+		Name:   BranchPatternFlagName,
+		EnvVar: "GITXARGS_BRANCH_PATTERN, GIT_XARGS_BRANCH_PATTERN",
+		Usage:  "A regular expression matching the names of the branches the cleanup-branches subcommand considers. Defaults to considering every branch whose head commit carries the git-xargs run ID trailer",
+	}
+	GenericCloseCommentFlag = cli.StringFlag{
+		Name:   CloseCommentFlagName,
+		EnvVar: "GITXARGS_CLOSE_COMMENT, GIT_XARGS_CLOSE_COMMENT",
+		Usage:  "The comment added to every pull request closed by the close subcommand. Supports the same placeholders as --pull-request-title",
+		Value:  DefaultCloseComment,
+	}
+	GenericStateFileFlag = cli.StringFlag{
+		Name:   StateFileFlagName,
+		EnvVar: "GITXARGS_STATE_FILE, GIT_XARGS_STATE_FILE",
+		Usage:  "The path of the local state store that records every run, its repos and the pull requests it opened. Defaults to ~/.git-xargs/state.db",
+	}
+	GenericSkipStateFlag = cli.BoolFlag{
+		Name:   SkipStateFlagName,
+		EnvVar: "GITXARGS_SKIP_STATE, GIT_XARGS_SKIP_STATE",
+		Usage:  "Do not record this run in the local state store",
+	}
+	GenericPlanOutFlag = cli.StringFlag{
+		Name:   PlanOutFlagName,
+		EnvVar: "GITXARGS_OUT, GIT_XARGS_OUT",
+		Usage:  "Used with the plan subcommand. The path of the plan file to write",
+		Value:  DefaultPlanFile,
+	}
+	GenericScheduleFlag = cli.StringFlag{
+		Name:   ScheduleFlagName,
+		EnvVar: "GITXARGS_SCHEDULE, GIT_XARGS_SCHEDULE",
+		Usage:  "Used with the watch subcommand. The cron schedule to run on, for example \"0 * * * *\" to run every hour",
+	}
+	GenericListenFlag = cli.StringFlag{
+		Name:   ListenFlagName,
+		EnvVar: "GITXARGS_LISTEN, GIT_XARGS_LISTEN",
+		Usage:  "Used with the serve subcommand. The address to serve the API on",
+		Value:  DefaultListenAddress,
+	}
+	GenericOutputFlag = cli.StringFlag{
+		Name:   OutputFlagName,
+		EnvVar: "GITXARGS_OUTPUT, GIT_XARGS_OUTPUT",
+		Usage:  "The format of the run report: table or json",
+		Value:  OutputFormatTable,
+	}
+	GenericOutputFileFlag = cli.StringFlag{
+		Name:   OutputFileFlagName,
+		EnvVar: "GITXARGS_OUTPUT_FILE, GIT_XARGS_OUTPUT_FILE",
+		Usage:  "Write the run report to this file instead of stdout",
+	}
+	GenericReportCSVFlag = cli.StringFlag{
+		Name:   ReportCSVFlagName,
+		EnvVar: "GITXARGS_REPORT_CSV, GIT_XARGS_REPORT_CSV",
+		Usage:  "Also export the outcome of each repo to a CSV file at this path",
+	}
+	GenericReportMarkdownFlag = cli.StringFlag{
+		Name:   ReportMarkdownFlagName,
+		EnvVar: "GITXARGS_REPORT_MARKDOWN, GIT_XARGS_REPORT_MARKDOWN",
+		Usage:  "Also write the run report as Markdown, with links to the pull requests opened, to a file at this path",
+	}
+	GenericReportJUnitFlag = cli.StringFlag{
+		Name:   ReportJUnitFlagName,
+		EnvVar: "GITXARGS_REPORT_JUNIT, GIT_XARGS_REPORT_JUNIT",
+		Usage:  "Also write the outcome of each repo as JUnit XML, with a test case per repo, to a file at this path",
+	}
+	GenericReportHTMLFlag = cli.StringFlag{
+		Name:   ReportHTMLFlagName,
+		EnvVar: "GITXARGS_REPORT_HTML, GIT_XARGS_REPORT_HTML",
+		Usage:  "Also write the run report as a standalone HTML page, with the diff of each repo and links to its pull requests, to a file at this path",
+	}
+	GenericWebhookURLFlag = cli.StringFlag{
+		Name:   WebhookURLFlagName,
+		EnvVar: "GITXARGS_WEBHOOK_URL, GIT_XARGS_WEBHOOK_URL",
+		Usage:  "POST the JSON run report to this URL when the run finishes",
+	}
+	GenericWebhookIncludeEventsFlag = cli.BoolFlag{
+		Name:   WebhookIncludeEventsFlagName,
+		EnvVar: "GITXARGS_WEBHOOK_INCLUDE_EVENTS, GIT_XARGS_WEBHOOK_INCLUDE_EVENTS",
+		Usage:  "Include the events tracked for each repo in the run report POSTed to --webhook-url",
+	}
+	GenericSlackWebhookURLFlag = cli.StringFlag{
+		Name:   SlackWebhookURLFlagName,
+		EnvVar: "GITXARGS_SLACK_WEBHOOK_URL, GIT_XARGS_SLACK_WEBHOOK_URL",
+		Usage:  "Post a summary of the run to this Slack incoming webhook when the run finishes",
+	}
+	GenericSlackChannelFlag = cli.StringFlag{
+		Name:   SlackChannelFlagName,
+		EnvVar: "GITXARGS_SLACK_CHANNEL, GIT_XARGS_SLACK_CHANNEL",
+		Usage:  "Post a summary of the run to this Slack channel when the run finishes, as the bot whose token is exported as SLACK_BOT_TOKEN",
+	}
+	GenericEmailToFlag = cli.StringSliceFlag{
+		Name:   EmailToFlagName,
+		EnvVar: "GITXARGS_EMAIL_TO, GIT_XARGS_EMAIL_TO",
+		Usage:  "Email a summary of the run, with links to the pull requests opened, to this address when the run finishes. Can be invoked multiple times with different addresses. Requires --email-from and --smtp-server",
+	}
+	GenericEmailFromFlag = cli.StringFlag{
+		Name:   EmailFromFlagName,
+		EnvVar: "GITXARGS_EMAIL_FROM, GIT_XARGS_EMAIL_FROM",
+		Usage:  "The address the --email-to summary is sent from",
+	}
+	GenericSMTPServerFlag = cli.StringFlag{
+		Name:   SMTPServerFlagName,
+		EnvVar: "GITXARGS_SMTP_SERVER, GIT_XARGS_SMTP_SERVER",
+		Usage:  "The <host>:<port> of the SMTP server the --email-to summary is sent through. Authenticates as SMTP_USERNAME with SMTP_PASSWORD, if they are exported",
+	}
+	GenericAllowedFailuresFlag = cli.IntFlag{
+		Name:   AllowedFailuresFlagName,
+		EnvVar: "GITXARGS_ALLOWED_FAILURES, GIT_XARGS_ALLOWED_FAILURES",
+		Usage:  "The number of repos that can fail before git-xargs exits with code 2. By default, git-xargs exits with code 2 if any repo fails",
+	}
+	GenericAllowedFailureRateFlag = cli.Float64Flag{
+		Name:   AllowedFailureRateFlagName,
+		EnvVar: "GITXARGS_ALLOWED_FAILURE_RATE, GIT_XARGS_ALLOWED_FAILURE_RATE",
+		Usage:  "The fraction of repos, between 0 and 1, that can fail before git-xargs exits with code 2",
+	}
+	GenericMinSuccessRateFlag = cli.StringFlag{
+		Name:   MinSuccessRateFlagName,
+		EnvVar: "GITXARGS_MIN_SUCCESS_RATE, GIT_XARGS_MIN_SUCCESS_RATE",
+		Usage:  "The percentage of repos, e.g. 95%, or fraction of repos, e.g. 0.95, that must not fail for git-xargs to exit with code 0. Below it, git-xargs exits with code 2",
+	}
+	GenericFailFastFlag = cli.BoolFlag{
+		Name:   FailFastFlagName,
+		EnvVar: "GITXARGS_FAIL_FAST, GIT_XARGS_FAIL_FAST",
+		Usage:  "Abort the run as soon as a repo fails: stop the repos being processed and don't start any more",
+	}
+	GenericMaxFailuresFlag = cli.IntFlag{
+		Name:   MaxFailuresFlagName,
+		EnvVar: "GITXARGS_MAX_FAILURES, GIT_XARGS_MAX_FAILURES",
+		Usage:  "Abort the run once more than this number of repos failed: stop the repos being processed and don't start any more",
+	}
+	GenericMaxFailureRateFlag = cli.Float64Flag{
+		Name:   MaxFailureRateFlagName,
+		EnvVar: "GITXARGS_MAX_FAILURE_RATE, GIT_XARGS_MAX_FAILURE_RATE",
+		Usage:  "Abort the run once more than this fraction of the selected repos, between 0 and 1, failed",
+	}
+	GenericRepoTimeoutFlag = cli.DurationFlag{
+		Name:   RepoTimeoutFlagName,
+		EnvVar: "GITXARGS_REPO_TIMEOUT, GIT_XARGS_REPO_TIMEOUT",
+		Usage:  "Cancel the repos that take longer than this to clone, run the command against, push and open a pull request for, e.g. 10m, and report them as timed out",
+	}
+	GenericProgressFlag = cli.BoolFlag{
+		Name:   ProgressFlagName,
+		EnvVar: "GITXARGS_PROGRESS, GIT_XARGS_PROGRESS",
+		Usage:  "Show the live progress of the run on stderr, including the phase each repo is in and the most recent errors, instead of the info and debug logs",
+	}
+	GenericPushgatewayURLFlag = cli.StringFlag{
+		Name:   PushgatewayURLFlagName,
+		EnvVar: "GITXARGS_PUSHGATEWAY_URL, GIT_XARGS_PUSHGATEWAY_URL",
+		Usage:  "Push the metrics of the run to the Prometheus Pushgateway at this URL when the run finishes",
+	}
+	GenericTelemetryEndpointFlag = cli.StringFlag{
+		Name:   TelemetryEndpointFlagName,
+		EnvVar: "GITXARGS_TELEMETRY_ENDPOINT, GIT_XARGS_TELEMETRY_ENDPOINT",
+		Usage:  "Opt in to sending anonymized usage metrics of the run, such as repo counts, durations, failure categories and the names of the flags used, to this URL when the run finishes. Nothing is sent unless it is passed",
+	}
+	GenericMetricsJobFlag = cli.StringFlag{
+		Name:   MetricsJobFlagName,
+		EnvVar: "GITXARGS_METRICS_JOB, GIT_XARGS_METRICS_JOB",
+		Value:  DefaultMetricsJob,
+		Usage:  "The job to push the metrics of the run under to --pushgateway-url. Use a different job for each recurring campaign",
+	}
+	GenericAuditLogDirFlag = cli.StringFlag{
+		Name:   AuditLogDirFlagName,
+		EnvVar: "GITXARGS_AUDIT_LOG_DIR, GIT_XARGS_AUDIT_LOG_DIR",
+		Usage:  "Append an audit log of the run, recording its command, config, the login of the GitHub token, the repos it touched and the commits it pushed, to a file named after the run ID in this directory",
+	}
+	GenericAuditLogHashChainFlag = cli.BoolFlag{
+		Name:   AuditLogHashChainFlagName,
+		EnvVar: "GITXARGS_AUDIT_LOG_HASH_CHAIN, GIT_XARGS_AUDIT_LOG_HASH_CHAIN",
+		Usage:  "Chain each entry of the --audit-log-dir audit log to the one before it by its SHA-256 hash, so that entries edited or removed afterwards are caught by git-xargs audit verify",
+	}
+	GenericAuditLastHashFlag = cli.StringFlag{
+		Name:   AuditLastHashFlagName,
+		EnvVar: "GITXARGS_LAST_HASH, GIT_XARGS_LAST_HASH",
+		Usage:  "The hash of the last entry of the audit log, as logged at the end of the run, to also catch entries removed from the end of the log and a log rewritten as a whole",
+	}
+	GenericEventsFileFlag = cli.StringFlag{
+		Name:   EventsFileFlagName,
+		EnvVar: "GITXARGS_EVENTS_FILE, GIT_XARGS_EVENTS_FILE",
+		Usage:  "Append an event for each lifecycle transition of the run, such as repo_cloned or pr_opened, as a line of JSON to a file at this path as it happens. Pass - to stream the events to stdout",
+	}
+	GenericReportGistFlag = cli.BoolFlag{
+		Name:   ReportGistFlagName,
+		EnvVar: "GITXARGS_REPORT_GIST, GIT_XARGS_REPORT_GIST",
+		Usage:  "Upload the run report, as Markdown and JSON, to a secret gist once the run finishes and log its URL, to share the report of runs on ephemeral CI runners",
+	}
+	GenericReportUploadFlag = cli.StringFlag{
+		Name:   ReportUploadFlagName,
+		EnvVar: "GITXARGS_REPORT_UPLOAD, GIT_XARGS_REPORT_UPLOAD",
+		Usage:  "Upload the run report, as JSON and HTML, under this location in object storage once the run finishes, in the format of s3://<bucket>/<prefix> or gs://<bucket>/<prefix>, so that the report of runs on ephemeral CI runners outlives them",
+	}
+	GenericCreateTrackingIssueFlag = cli.StringFlag{
+		Name:   CreateTrackingIssueFlagName,
+		EnvVar: "GITXARGS_CREATE_TRACKING_ISSUE, GIT_XARGS_CREATE_TRACKING_ISSUE",
+		Usage:  "Open an issue with the run report and checklists of the pull requests opened and the repos that failed in this repo, in the format of <github-organization>/<repo-name>, once the run finishes",
+	}
+	GenericOTLPEndpointFlag = cli.StringFlag{
+		Name:   OTLPEndpointFlagName,
+		EnvVar: "GITXARGS_OTLP_ENDPOINT, GIT_XARGS_OTLP_ENDPOINT",
+		Usage:  "Export traces of the run, with a span for each repo and for its clone, command, push and pull request, to the OpenTelemetry collector at this OTLP/HTTP URL, e.g. http://localhost:4318",
+	}
+	GenericResumeFlag = cli.BoolFlag{
+		Name:   ResumeFlagName,
+		EnvVar: "GITXARGS_RESUME, GIT_XARGS_RESUME",
+		Usage:  "Resume the interrupted run passed via --run-id, skipping the repos it already opened pull requests for",
 	}
 	GenericMaxConcurrentReposFlag = cli.IntFlag{
-		Name:  MaxConcurrentReposFlagName,
-		Usage: "Limits the number of concurrent processed repositories. This is only useful if you encounter issues and need throttling when running on a very large number of repos.  Default is 0 (Unlimited)",
-		Value: DefaultMaxConcurrentRepos,
+		Name:   MaxConcurrentReposFlagName,
+		EnvVar: "GITXARGS_MAX_CONCURRENT_REPOS, GIT_XARGS_MAX_CONCURRENT_REPOS",
+		Usage:  "Limits the number of concurrent processed repositories. This is only useful if you encounter issues and need throttling when running on a very large number of repos.  Default is 0 (Unlimited)",
+		Value:  DefaultMaxConcurrentRepos,
+	}
+	GenericAutoConcurrencyFlag = cli.BoolFlag{
+		Name:   AutoConcurrencyFlagName,
+		EnvVar: "GITXARGS_AUTO_CONCURRENCY, GIT_XARGS_AUTO_CONCURRENCY",
+		Usage:  "Adjust the number of repos processed at once as the run goes, based on the GitHub API rate limit left, the rate of failed repos and the load and free disk space of this machine. --max-concurrent-repos, if passed, is the most repos processed at once",
+	}
+	GenericCloneConcurrencyFlag = cli.IntFlag{
+		Name:   CloneConcurrencyFlagName,
+		EnvVar: "GITXARGS_CLONE_CONCURRENCY, GIT_XARGS_CLONE_CONCURRENCY",
+		Usage:  "Limits the number of repos cloned at once. Default is 0 (Unlimited, within --max-concurrent-repos)",
+	}
+	GenericCommandConcurrencyFlag = cli.IntFlag{
+		Name:   CommandConcurrencyFlagName,
+		EnvVar: "GITXARGS_COMMAND_CONCURRENCY, GIT_XARGS_COMMAND_CONCURRENCY",
+		Usage:  "Limits the number of repos the command runs against at once. Default is 0 (Unlimited, within --max-concurrent-repos)",
+	}
+	GenericPushConcurrencyFlag = cli.IntFlag{
+		Name:   PushConcurrencyFlagName,
+		EnvVar: "GITXARGS_PUSH_CONCURRENCY, GIT_XARGS_PUSH_CONCURRENCY",
+		Usage:  "Limits the number of repos whose changes are committed and pushed at once. Default is 0 (Unlimited, within --max-concurrent-repos)",
+	}
+	GenericPullRequestConcurrencyFlag = cli.IntFlag{
+		Name:   PullRequestConcurrencyFlagName,
+		EnvVar: "GITXARGS_PULL_REQUEST_CONCURRENCY, GIT_XARGS_PULL_REQUEST_CONCURRENCY",
+		Usage:  "Limits the number of repos whose pull requests are opened at once. Default is 0 (Unlimited, within --max-concurrent-repos)",
+	}
+	GenericDraftIfDiffLinesOverFlag = cli.IntFlag{
+		Name:   DraftIfDiffLinesOverFlagName,
+		EnvVar: "GITXARGS_DRAFT_IF_DIFF_LINES_OVER, GIT_XARGS_DRAFT_IF_DIFF_LINES_OVER",
+		Usage:  "Open pull requests in draft mode when the changes made by your command or script add or delete more than this many lines. Default is 0 (disabled)",
+	}
+	GenericDraftIfChecksPendingFlag = cli.BoolFlag{
+		Name:   DraftIfChecksPendingFlagName,
+		EnvVar: "GITXARGS_DRAFT_IF_CHECKS_PENDING, GIT_XARGS_DRAFT_IF_CHECKS_PENDING",
+		Usage:  "Open pull requests in draft mode when a status or check run reported on the pushed branch has not yet completed",
+	}
+	GenericDraftIfRepoMatchesFlag = cli.StringSliceFlag{
+		Name:   DraftIfRepoMatchesFlagName,
+		EnvVar: "GITXARGS_DRAFT_IF_REPO_MATCHES, GIT_XARGS_DRAFT_IF_REPO_MATCHES",
+		Usage:  "Open pull requests in draft mode for repos whose <github-organization/repo-name> matches this regular expression. Can be invoked multiple times with different patterns",
+	}
+	GenericReviewersFlag = cli.StringSliceFlag{
+		Name:   ReviewersFlagName,
+		EnvVar: "GITXARGS_REVIEWERS, GIT_XARGS_REVIEWERS",
+		Usage:  "A user login, or team in the format of <github-organization/team-slug>, to request a review from on each opened pull request. Can be invoked multiple times to build a pool of reviewers",
+	}
+	GenericReviewerStrategyFlag = cli.StringFlag{
+		Name:   ReviewerStrategyFlagName,
+		EnvVar: "GITXARGS_REVIEWER_STRATEGY, GIT_XARGS_REVIEWER_STRATEGY",
+		Usage:  "How to distribute pull requests across the --reviewers pool: all (request every reviewer on every pull request), round-robin or least-loaded (fewest open review requests first)",
+		Value:  DefaultReviewerStrategy,
+	}
+	GenericReviewersPerPRFlag = cli.IntFlag{
+		Name:   ReviewersPerPRFlagName,
+		EnvVar: "GITXARGS_REVIEWERS_PER_PULL_REQUEST, GIT_XARGS_REVIEWERS_PER_PULL_REQUEST",
+		Usage:  "The number of reviewers from the --reviewers pool to request on each pull request when using the round-robin or least-loaded strategies",
+		Value:  DefaultReviewersPerPR,
+	}
+	GenericReviewersFromBlameFlag = cli.BoolFlag{
+		Name:   ReviewersFromBlameFlagName,
+		EnvVar: "GITXARGS_REVIEWERS_FROM_BLAME, GIT_XARGS_REVIEWERS_FROM_BLAME",
+		Usage:  "Request reviews from the most recent human committers of the lines changed in each repo, as determined by git blame",
+	}
+	GenericBlameReviewersCountFlag = cli.IntFlag{
+		Name:   BlameReviewersCountFlagName,
+		EnvVar: "GITXARGS_BLAME_REVIEWERS_COUNT, GIT_XARGS_BLAME_REVIEWERS_COUNT",
+		Usage:  "The number of reviewers to request per pull request when --reviewers-from-blame is set",
+		Value:  DefaultBlameReviewersCount,
+	}
+	GenericExcludeReviewersFlag = cli.StringSliceFlag{
+		Name:   ExcludeReviewersFlagName,
+		EnvVar: "GITXARGS_EXCLUDE_REVIEWERS, GIT_XARGS_EXCLUDE_REVIEWERS",
+		Usage:  "A user login, or team in the format of <github-organization/team-slug>, to never request a review from, even if --reviewers, --reviewers-from-blame or the CODEOWNERS of the repo would. Can be invoked multiple times",
+	}
+	GenericAssigneesFlag = cli.StringSliceFlag{
+		Name:   AssigneesFlagName,
+		EnvVar: "GITXARGS_ASSIGNEES, GIT_XARGS_ASSIGNEES",
+		Usage:  "A user login, or team in the format of <github-organization/team-slug> that is expanded to its members, to assign each opened pull request to. Can be invoked multiple times to build a pool of assignees",
+	}
+	GenericAssigneeStrategyFlag = cli.StringFlag{
+		Name:   AssigneeStrategyFlagName,
+		EnvVar: "GITXARGS_ASSIGNEE_STRATEGY, GIT_XARGS_ASSIGNEE_STRATEGY",
+		Usage:  "How to distribute pull requests across the --assignees pool: all (assign every member to every pull request), round-robin or least-loaded (fewest open assigned pull requests first)",
+		Value:  DefaultReviewerStrategy,
+	}
+	GenericAssigneesPerPRFlag = cli.IntFlag{
+		Name:   AssigneesPerPRFlagName,
+		EnvVar: "GITXARGS_ASSIGNEES_PER_PULL_REQUEST, GIT_XARGS_ASSIGNEES_PER_PULL_REQUEST",
+		Usage:  "The number of assignees from the --assignees pool to assign to each pull request when using the round-robin or least-loaded strategies",
+		Value:  DefaultReviewersPerPR,
+	}
+	GenericProjectFlag = cli.StringFlag{
+		Name:   ProjectFlagName,
+		EnvVar: "GITXARGS_PROJECT, GIT_XARGS_PROJECT",
+		Usage:  "The organization-level Projects (v2) board to add every opened pull request to, in the format of <github-organization>/<project-number>",
+	}
+	GenericProjectFieldFlag = cli.StringSliceFlag{
+		Name:   ProjectFieldFlagName,
+		EnvVar: "GITXARGS_PROJECT_FIELD, GIT_XARGS_PROJECT_FIELD",
+		Usage:  "A field value to set on each pull request added to the --project board, in the format of <field-name>=<value>. Can be invoked multiple times with different fields",
+	}
+	GenericTagFlag = cli.StringSliceFlag{
+		Name:   TagFlagName,
+		EnvVar: "GITXARGS_TAG, GIT_XARGS_TAG",
+		Usage:  "Tag the run as part of a kind of campaign, e.g. security or ci, so that repos can opt out of it via opt-out-tags in their " + RepoConfigFileName + ". Can be passed multiple times",
+	}
+	GenericIgnoreRepoConfigFlag = cli.BoolFlag{
+		Name:   IgnoreRepoConfigFlagName,
+		EnvVar: "GITXARGS_IGNORE_REPO_CONFIG, GIT_XARGS_IGNORE_REPO_CONFIG",
+		Usage:  "Ignore the " + RepoConfigFileName + " files committed to the repos, which otherwise override the branch name, base branch and reviewers for their repo, or opt it out of the run",
+	}
+	GenericCheckOnlyFlag = cli.BoolFlag{
+		Name:   CheckOnlyFlagName,
+		EnvVar: "GITXARGS_CHECK, GIT_XARGS_CHECK",
+		Usage:  "Only report whether a newer release of git-xargs is available, without installing it",
+	}
+	GenericForceFlag = cli.BoolFlag{
+		Name:   ForceFlagName,
+		EnvVar: "GITXARGS_FORCE, GIT_XARGS_FORCE",
+		Usage:  "Install the latest release of git-xargs even if it isn't newer than the running version",
+	}
+	GenericOlderThanFlag = cli.UintFlag{
+		Name:   OlderThanFlagName,
+		EnvVar: "GITXARGS_OLDER_THAN, GIT_XARGS_OLDER_THAN",
+		Usage:  "Only remove the local artifacts last modified more than this many days ago, so that the clones of runs that are still going are kept. Pass 0 to remove all of them",
+		Value:  DefaultOlderThanDays,
+	}
+	GenericSkipCITrailerFlag = cli.StringFlag{
+		Name:   SkipCITrailerFlagName,
+		EnvVar: "GITXARGS_SKIP_CI_TRAILER, GIT_XARGS_SKIP_CI_TRAILER",
+		Usage:  "A marker such as \"[skip ci]\", or a trailer such as \"skip-checks: true\", to add to commit messages or pull request titles, as --skip-ci-in decides, so that CI doesn't run for them",
+	}
+	GenericSkipCIInFlag = cli.StringFlag{
+		Name:   SkipCIInFlagName,
+		EnvVar: "GITXARGS_SKIP_CI_IN, GIT_XARGS_SKIP_CI_IN",
+		Usage:  "Where to add the --skip-ci-trailer: commit, title or both",
+		Value:  SkipCIInCommit,
+	}
+	GenericNoSkipCIFlag = cli.BoolFlag{
+		Name:   NoSkipCIFlagName,
+		EnvVar: "GITXARGS_NO_SKIP_CI, GIT_XARGS_NO_SKIP_CI",
+		Usage:  "Don't add the --skip-ci-trailer, even if a config file, profile or environment variable sets one, for campaigns whose CI must run",
+	}
+	GenericRecordFlag = cli.StringFlag{
+		Name:   RecordFlagName,
+		EnvVar: "GITXARGS_RECORD, GIT_XARGS_RECORD",
+		Usage:  "Record every GitHub API request of the run, and the response it got, to this JSON file, to replay them later with --replay",
+	}
+	GenericReplayFlag = cli.StringFlag{
+		Name:   ReplayFlagName,
+		EnvVar: "GITXARGS_REPLAY, GIT_XARGS_REPLAY",
+		Usage:  "Answer every GitHub API request of the run with the responses recorded in this JSON file by --record, instead of calling the GitHub API. Cloning and pushing still go to GitHub",
+	}
+	GenericPutFileFlag = cli.StringSliceFlag{
+		Name:   PutFileFlagName,
+		EnvVar: "GITXARGS_PUT_FILE, GIT_XARGS_PUT_FILE",
+		Usage:  "Create or overwrite a file in each repo via the GitHub Contents API, without cloning it, in the format of <path-in-repo>=<local-path>. Can be invoked multiple times, and replaces the command",
+	}
+	GenericDeleteFileFlag = cli.StringSliceFlag{
+		Name:   DeleteFileFlagName,
+		EnvVar: "GITXARGS_DELETE_FILE, GIT_XARGS_DELETE_FILE",
+		Usage:  "Delete the file at this path in each repo via the GitHub Contents API, without cloning it. Can be invoked multiple times, and replaces the command",
+	}
+	GenericMoveFileFlag = cli.StringSliceFlag{
+		Name:   MoveFileFlagName,
+		EnvVar: "GITXARGS_MOVE_FILE, GIT_XARGS_MOVE_FILE",
+		Usage:  "Move or rename the tracked files in each repo that match a glob, as git mv would, in the format of <glob>=<destination>. A destination ending in / is a directory to move the matches into. Can be invoked multiple times, and replaces the command",
+	}
+	GenericPushViaAPIFlag = cli.BoolFlag{
+		Name:   PushViaAPIFlagName,
+		EnvVar: "GITXARGS_PUSH_VIA_API, GIT_XARGS_PUSH_VIA_API",
+		Usage:  "Create the commits on GitHub via the Git Data API instead of pushing them through git, and clone the repos without their history. Useful for repos too large to clone and push practically",
+	}
+	GenericMonorepoManifestFlag = cli.StringFlag{
+		Name:   MonorepoManifestFlagName,
+		EnvVar: "GITXARGS_MONOREPO_MANIFEST, GIT_XARGS_MONOREPO_MANIFEST",
+		Usage:  "Path to a YAML manifest listing directories of one or more monorepos. Each monorepo is cloned once, and the command is run in each of its listed directories, whose changes are committed separately",
+	}
+	GenericMonorepoPullRequestsFlag = cli.StringFlag{
+		Name:   MonorepoPullRequestsFlagName,
+		EnvVar: "GITXARGS_MONOREPO_PULL_REQUESTS, GIT_XARGS_MONOREPO_PULL_REQUESTS",
+		Usage:  "How to open pull requests for the directories of --monorepo-manifest: combined, for one pull request per monorepo with a commit per directory, or per-directory, for one pull request per directory on stacked branches",
+		Value:  MonorepoCombined,
+	}
+	GenericGithubHostFlag = cli.StringSliceFlag{
+		Name:   GithubHostFlagName,
+		EnvVar: "GITXARGS_GITHUB_HOST, GIT_XARGS_GITHUB_HOST",
+		Usage:  "A GitHub Enterprise Server that repos of the run are on, as <host>=<token-env-var>, e.g. github.example.com=GHE_TOKEN, to reach it with the token exported as that environment variable. Repos are then passed as <host>/<org>/<repo>. Can be invoked multiple times",
+	}
+	GenericJiraURLFlag = cli.StringFlag{
+		Name:   JiraURLFlagName,
+		EnvVar: "GITXARGS_JIRA_URL, GIT_XARGS_JIRA_URL",
+		Usage:  "The URL of the Jira instance the issue passed via --jira-issue is in, e.g. https://my-org.atlassian.net",
+	}
+	GenericJiraIssueFlag = cli.StringFlag{
+		Name:   JiraIssueFlagName,
+		EnvVar: "GITXARGS_JIRA_ISSUE, GIT_XARGS_JIRA_ISSUE",
+		Usage:  "The key of the Jira issue the run is for, e.g. PLAT-123. It is added to the branch name, commit messages and pull request titles, and the run report is posted to the issue as a comment, with the token exported as JIRA_API_TOKEN",
+	}
+	GenericJiraTransitionFlag = cli.StringFlag{
+		Name:   JiraTransitionFlagName,
+		EnvVar: "GITXARGS_JIRA_TRANSITION, GIT_XARGS_JIRA_TRANSITION",
+		Usage:  "The transition, or the status it leads to, e.g. Done, to move the issue passed via --jira-issue through once the status subcommand finds every pull request of the run merged",
+	}
+	GenericCheckBranchProtectionFlag = cli.BoolFlag{
+		Name:   CheckBranchProtectionFlagName,
+		EnvVar: "GITXARGS_CHECK_BRANCH_PROTECTION, GIT_XARGS_CHECK_BRANCH_PROTECTION",
+		Usage:  "Look up the protection rules of the base branch of each repo before working on it, to report the rules that would block its changes, and adapt to them where possible. Requires admin rights on the repos",
+	}
+	GenericSigningKeyFlag = cli.StringFlag{
+		Name:   SigningKeyFlagName,
+		EnvVar: "GITXARGS_SIGNING_KEY, GIT_XARGS_SIGNING_KEY",
+		Usage:  "The path to an ASCII-armored OpenPGP private key to sign the commits pushed through git with, so that repos whose base branch requires signed commits aren't skipped. If the key is encrypted, export its passphrase as GIT_XARGS_SIGNING_KEY_PASSPHRASE",
+	}
+	GenericMergeChecksTimeoutFlag = cli.DurationFlag{
+		Name:   MergeChecksTimeoutFlagName,
+		EnvVar: "GITXARGS_MERGE_CHECKS_TIMEOUT, GIT_XARGS_MERGE_CHECKS_TIMEOUT",
+		Usage:  "How long --approve-and-merge waits for the status checks the base branch of a repo requires to pass before merging its pull request, e.g. 10m. By default, it doesn't wait. Pull requests whose required checks haven't passed by then are left open for the merge subcommand. Each wait holds one of the --pull-request-concurrency slots",
+		Value:  DefaultMergeChecksTimeout,
+	}
+	GenericDeployKeysDirFlag = cli.StringFlag{
+		Name:   DeployKeysDirFlagName,
+		EnvVar: "GITXARGS_DEPLOY_KEYS_DIR, GIT_XARGS_DEPLOY_KEYS_DIR",
+		Usage:  "A directory of SSH deploy keys, each at <org>/<repo>, to clone and push the repos that have one over SSH with their key, rather than over HTTPS with the GitHub token, e.g. for repos the token can't write to",
+	}
+	GenericEnvFilesDirFlag = cli.StringFlag{
+		Name:   EnvFilesDirFlagName,
+		EnvVar: "GITXARGS_ENV_FILES_DIR, GIT_XARGS_ENV_FILES_DIR",
+		Usage:  "A directory of env files, each at <org>/<repo>.env, whose variables the command gets for the repos that have one, e.g. repo-specific secrets or parameters",
+	}
+	GenericRepoEnvFileFlag = cli.BoolFlag{
+		Name:   RepoEnvFileFlagName,
+		EnvVar: "GITXARGS_REPO_ENV_FILE, GIT_XARGS_REPO_ENV_FILE",
+		Usage:  "Give the command the variables of the " + RepoEnvFileName + " file committed to each repo, if it has one. The env files in --env-files-dir take precedence over it",
+	}
+	GenericPathLabelsFlag = cli.StringFlag{
+		Name:   PathLabelsFlagName,
+		EnvVar: "GITXARGS_PATH_LABELS, GIT_XARGS_PATH_LABELS",
+		Usage:  "A YAML file mapping labels to the globs of the paths that get a pull request each label, like the labeler GitHub Action, to label each pull request after the files it changes",
+	}
+	GenericPRScheduleFlag = cli.StringFlag{
+		Name:   PRScheduleFlagName,
+		EnvVar: "GITXARGS_PR_SCHEDULE, GIT_XARGS_PR_SCHEDULE",
+		Usage:  "Hold pushed branches and only open their pull requests within this window, in the format \"<days> <start>-<end>\", e.g. \"Mon-Fri 09:00-11:00\"",
+	}
+	GenericPRScheduleTimezoneFlag = cli.StringFlag{
+		Name:   PRScheduleTimezoneFlagName,
+		EnvVar: "GITXARGS_PR_SCHEDULE_TIMEZONE, GIT_XARGS_PR_SCHEDULE_TIMEZONE",
+		Usage:  "The timezone of the --pr-schedule window, e.g. America/New_York. Defaults to the local timezone",
+	}
+	GenericPRScheduleMaxFlag = cli.IntFlag{
+		Name:   PRScheduleMaxFlagName,
+		EnvVar: "GITXARGS_PR_SCHEDULE_MAX_PER_WINDOW, GIT_XARGS_PR_SCHEDULE_MAX_PER_WINDOW",
+		Usage:  "The maximum number of pull requests to open each time the --pr-schedule window opens, spreading the rest over the following windows. 0 means no limit",
+	}
+	GenericMaxOpenPRsFlag = cli.IntFlag{
+		Name:   MaxOpenPRsFlagName,
+		EnvVar: "GITXARGS_MAX_OPEN_PRS, GIT_XARGS_MAX_OPEN_PRS",
+		Usage:  "Stop opening pull requests once this many git-xargs pull requests are open, and queue the rest of the repos in the state store for the continue subcommand. 0 means no limit",
+	}
+	GenericMaxOpenPRsScopeFlag = cli.StringFlag{
+		Name:   MaxOpenPRsScopeFlagName,
+		EnvVar: "GITXARGS_MAX_OPEN_PRS_SCOPE, GIT_XARGS_MAX_OPEN_PRS_SCOPE",
+		Usage:  "Which open pull requests count towards --max-open-prs: run (those opened by this run) or org (those opened by any git-xargs run in the organization of each repo)",
+		Value:  MaxOpenPRsScopeRun,
+	}
+	GenericReportDiffLinesFlag = cli.IntFlag{
+		Name:   ReportDiffLinesFlagName,
+		EnvVar: "GITXARGS_REPORT_DIFF_LINES, GIT_XARGS_REPORT_DIFF_LINES",
+		Usage:  "Include a preview of the diff of each changed repo, cut off after this many lines, in the Markdown, HTML and JSON run reports, but not in tracking issues, step summaries or webhooks. 0 leaves the previews out",
+	}
+	GenericOutputManifestFlag = cli.StringFlag{
+		Name:   OutputManifestFlagName,
+		EnvVar: "GITXARGS_OUTPUT_MANIFEST, GIT_XARGS_OUTPUT_MANIFEST",
+		Usage:  "Write a machine-readable manifest of the run, with a snapshot of its config and the branch, commit SHA, pull request and outcome of each repo, to a file at this path",
+	}
+	GenericManifestFlag = cli.StringFlag{
+		Name:   ManifestFlagName,
+		EnvVar: "GITXARGS_MANIFEST, GIT_XARGS_MANIFEST",
+		Usage:  "Act on the run recorded in the manifest written via --output-manifest at this path, in place of --run-id. The run is imported into the state store if it isn't there yet",
+	}
+	GenericPickFlag = cli.BoolFlag{
+		Name:   PickFlagName,
+		EnvVar: "GITXARGS_PICK, GIT_XARGS_PICK",
+		Usage:  "Show the selected repos in an interactive multi-select on the terminal, where they can be filtered and deselected, before any of them is processed",
+	}
+	GenericPluginFlag = cli.StringSliceFlag{
+		Name:   PluginFlagName,
+		EnvVar: "GITXARGS_PLUGIN, GIT_XARGS_PLUGIN",
+		Usage:  "The path to a plugin that selects repos, changes them after the command or runs once each repo is processed: a Go plugin ending in .so, or an executable speaking the JSON plugin protocol. Can be invoked multiple times, and the plugins run in order",
 	}
 )
@@ -1,58 +1,272 @@
 package config
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"time"
 
+	"github.com/gruntwork-io/git-xargs/audit"
 	"github.com/gruntwork-io/git-xargs/auth"
 	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/events"
 	"github.com/gruntwork-io/git-xargs/local"
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/plugins"
+	"github.com/gruntwork-io/git-xargs/progress"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/schedule"
+	"github.com/gruntwork-io/git-xargs/state"
 	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/tracing"
+	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/gruntwork-io/git-xargs/util"
+	"golang.org/x/crypto/openpgp"
 )
 
 // GitXargsConfig is the internal representation of a given git-xargs run as specified by the user
 type GitXargsConfig struct {
-	Draft                  bool
-	DryRun                 bool
-	SkipPullRequests       bool
-	SkipArchivedRepos      bool
-	MaxConcurrentRepos     int
-	BranchName             string
-	BaseBranchName         string
-	CommitMessage          string
-	PullRequestTitle       string
-	PullRequestDescription string
-	ReposFile              string
-	GithubOrg              string
-	RepoSlice              []string
-	RepoFromStdIn          []string
-	Args                   []string
-	GithubClient           auth.GithubClient
-	GitClient              local.GitClient
-	Stats                  *stats.RunStats
+	Draft                     bool
+	DraftIfChecksPending      bool
+	ReviewersFromBlame        bool
+	DryRun                    bool
+	SkipPullRequests          bool
+	SkipArchivedRepos         bool
+	SkipReposWithOpenPRs      bool
+	SkipRunMarkers            bool
+	ApproveAndMerge           bool
+	CommitStatus              bool
+	SkipState                 bool
+	Resume                    bool
+	DeleteBranch              bool
+	RunIDSupplied             bool
+	AutoConcurrency           bool
+	Pick                      bool
+	IgnoreRepoConfig          bool
+	MaxConcurrentRepos        int
+	CloneConcurrency          int
+	CommandConcurrency        int
+	PushConcurrency           int
+	PullRequestConcurrency    int
+	DraftIfDiffLinesOver      int
+	ReviewersPerPR            int
+	BlameReviewersCount       int
+	AssigneesPerPR            int
+	MaxFilesPerPR             int
+	BranchName                string
+	BaseBranchName            string
+	CommitMessage             string
+	PullRequestTitle          string
+	PullRequestDescription    string
+	PullRequestFooter         string
+	CommitStatusURL           string
+	StateFile                 string
+	CloseComment              string
+	BranchPattern             string
+	PlanFile                  string
+	Schedule                  string
+	OutputFormat              string
+	DryRunLevel               string
+	SkipCITrailer             string
+	SkipCIIn                  string
+	RecordFile                string
+	ReplayFile                string
+	Recording                 *auth.Recording
+	FileChanges               []types.FileChange
+	FileMoves                 []types.FileMove
+	PushViaAPI                bool
+	MonorepoManifest          string
+	MonorepoTargets           map[string][]string
+	MonorepoPullRequests      string
+	GithubHostClients         map[string]auth.GithubClient
+	GithubHostApproverClients map[string]auth.GithubClient
+	APIHeaders                http.Header
+	GithubHostTokens          map[string]string
+	GithubHostVersions        map[string]string
+	RepoBaseBranches          map[string]string
+	JiraURL                   string
+	JiraIssue                 string
+	JiraTransition            string
+	CheckBranchProtection     bool
+	SigningKey                *openpgp.Entity
+	MergeChecksTimeout        time.Duration
+	DeployKeysDir             string
+	EnvFilesDir               string
+	RepoEnvFile               bool
+	CommandEnv                []string
+	PathLabels                map[string][]string
+	PullRequestSchedule       string
+	PullRequestGate           *schedule.Gate
+	MaxOpenPRs                int
+	MaxOpenPRsScope           string
+	OutputFile                string
+	ReportCSV                 string
+	ReportMarkdown            string
+	ReportJUnit               string
+	ReportHTML                string
+	ReportDiffLines           int
+	OutputManifest            string
+	Manifest                  *manifest.Manifest
+	WebhookURL                string
+	WebhookIncludeEvents      bool
+	SlackWebhookURL           string
+	SlackChannel              string
+	EmailTo                   []string
+	EmailFrom                 string
+	SMTPServer                string
+	AllowedFailures           int
+	AllowedFailureRate        float64
+	MinSuccessRate            float64
+	FailFast                  bool
+	MaxFailures               int
+	MaxFailureRate            float64
+	RepoTimeout               time.Duration
+	Progress                  *progress.Tracker
+	PushgatewayURL            string
+	MetricsJob                string
+	TelemetryEndpoint         string
+	TelemetryCommand          string
+	TelemetryFlags            []string
+	OTLPEndpoint              string
+	TrackingIssueRepo         string
+	ReportGist                bool
+	ReportUpload              *types.ObjectStorageLocation
+	EventsFile                string
+	Events                    *events.Stream
+	AuditLogDir               string
+	AuditLogHashChain         bool
+	Audit                     *audit.Log
+	Tracer                    *tracing.Tracer
+	ReposFile                 string
+	GithubOrg                 string
+	Project                   string
+	ReviewerStrategy          string
+	AssigneeStrategy          string
+	MergeMethod               string
+	SplitBy                   string
+	RepoSlice                 []string
+	RepoFromStdIn             []string
+	DraftIfRepoMatches        []string
+	ProjectFieldValues        []string
+	Reviewers                 []string
+	ExcludeReviewers          []string
+	Assignees                 []string
+	Tags                      []string
+	Args                      []string
+	RunID                     string
+	StartTime                 time.Time
+	GithubToken               string
+	GithubClient              auth.GithubClient
+	ApproverGithubClient      auth.GithubClient
+	GitClient                 local.GitClient
+	Stats                     *stats.RunStats
+	ResolvedProject           *types.ProjectV2
+	ReviewerPool              *reviewers.Pool
+	AssigneePool              *reviewers.Pool
+	State                     *state.Store
+	Plan                      *plan.Plan
+	Plugins                   *plugins.Set
+	Context                   context.Context
 }
 
-// NewGitXargsConfig sets reasonable defaults for a GitXargsConfig and returns a pointer to the config
+// NewGitXargsConfig sets reasonable defaults for a GitXargsConfig, authenticating with the GitHub token exported as
+// GITHUB_OAUTH_TOKEN, and returns a pointer to the config
 func NewGitXargsConfig() *GitXargsConfig {
+	return NewGitXargsConfigForToken(os.Getenv("GITHUB_OAUTH_TOKEN"))
+}
+
+// NewGitXargsConfigForToken sets reasonable defaults for a GitXargsConfig, authenticating with the supplied GitHub token,
+// and returns a pointer to the config
+func NewGitXargsConfigForToken(githubToken string) *GitXargsConfig {
+	startTime := time.Now()
+
 	return &GitXargsConfig{
 		Draft:                  false,
+		DraftIfChecksPending:   false,
+		ReviewersFromBlame:     false,
 		DryRun:                 false,
 		SkipPullRequests:       false,
 		SkipArchivedRepos:      false,
+		SkipReposWithOpenPRs:   false,
+		SkipRunMarkers:         false,
+		ApproveAndMerge:        false,
+		CommitStatus:           false,
+		SkipState:              false,
+		DeleteBranch:           false,
+		RunIDSupplied:          false,
 		MaxConcurrentRepos:     0,
+		DraftIfDiffLinesOver:   0,
+		ReviewersPerPR:         common.DefaultReviewersPerPR,
+		BlameReviewersCount:    common.DefaultBlameReviewersCount,
+		AssigneesPerPR:         common.DefaultReviewersPerPR,
+		MaxFilesPerPR:          0,
+		AllowedFailures:        -1,
+		AllowedFailureRate:     -1,
+		MinSuccessRate:         -1,
+		MaxFailures:            -1,
+		MaxFailureRate:         -1,
+		MetricsJob:             common.DefaultMetricsJob,
 		BranchName:             "",
 		BaseBranchName:         "",
 		CommitMessage:          common.DefaultCommitMessage,
 		PullRequestTitle:       common.DefaultPullRequestTitle,
 		PullRequestDescription: common.DefaultPullRequestDescription,
+		PullRequestFooter:      "",
+		CommitStatusURL:        "",
+		StateFile:              "",
+		CloseComment:           common.DefaultCloseComment,
+		BranchPattern:          "",
 		ReposFile:              "",
 		GithubOrg:              "",
+		Project:                "",
+		ReviewerStrategy:       common.DefaultReviewerStrategy,
+		AssigneeStrategy:       common.DefaultReviewerStrategy,
+		MergeMethod:            common.DefaultMergeMethod,
+		MergeChecksTimeout:     common.DefaultMergeChecksTimeout,
+		SplitBy:                common.SplitByDirectory,
+		MaxOpenPRsScope:        common.MaxOpenPRsScopeRun,
+		SkipCIIn:               common.SkipCIInCommit,
+		MonorepoPullRequests:   common.MonorepoCombined,
 		RepoSlice:              []string{},
 		RepoFromStdIn:          []string{},
+		DraftIfRepoMatches:     []string{},
+		ProjectFieldValues:     []string{},
+		Reviewers:              []string{},
+		ExcludeReviewers:       []string{},
+		Assignees:              []string{},
 		Args:                   []string{},
-		GithubClient:           auth.ConfigureGithubClient(),
+		RunID:                  util.NewRunID(startTime),
+		StartTime:              startTime,
+		GithubToken:            githubToken,
+		GithubClient:           auth.ConfigureGithubClientForToken(githubToken),
 		GitClient:              local.NewGitClient(local.GitProductionProvider{}),
 		Stats:                  stats.NewStatsTracker(),
+		Context:                context.Background(),
+	}
+}
+
+// ApplyDryRunLevel sets the supplied --dry-run-level on the config, along with DryRun, which stops branches from
+// being pushed, for every level short of push-no-pr. An empty level leaves the config alone
+func (gitxargsConfig *GitXargsConfig) ApplyDryRunLevel(level string) {
+	if level == "" {
+		return
+	}
+	gitxargsConfig.DryRunLevel = level
+	gitxargsConfig.DryRun = level != common.DryRunPushNoPR
+}
+
+// ApplyAPIHeaders sets the APIHeaders of the config on each of its GitHub API clients: the one of the run, the one of
+// the approver and the ones of each GitHub Enterprise Server. Clients configured later need it to be applied again
+func (gitxargsConfig *GitXargsConfig) ApplyAPIHeaders() {
+	gitxargsConfig.GithubClient.SetAPIHeaders(gitxargsConfig.APIHeaders)
+	gitxargsConfig.ApproverGithubClient.SetAPIHeaders(gitxargsConfig.APIHeaders)
+	for _, client := range gitxargsConfig.GithubHostClients {
+		client.SetAPIHeaders(gitxargsConfig.APIHeaders)
+	}
+	for _, client := range gitxargsConfig.GithubHostApproverClients {
+		client.SetAPIHeaders(gitxargsConfig.APIHeaders)
 	}
 }
 
@@ -0,0 +1,141 @@
+// Package events streams the lifecycle of a run as newline-delimited JSON, one event per line as it happens, so that
+// external tooling can follow the run in real time.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// Type is the kind of lifecycle transition an event records
+type Type string
+
+const (
+	// RunStarted is emitted once the repos of the run have been selected, before any of them is processed
+	RunStarted Type = "run_started"
+	// RunFinished is emitted once every repo of the run has been processed
+	RunFinished Type = "run_finished"
+	// RepoStarted is emitted when a repo starts being processed
+	RepoStarted Type = "repo_started"
+	// RepoCloned is emitted when a repo has been cloned
+	RepoCloned Type = "repo_cloned"
+	// CommandSucceeded is emitted when the command exited successfully in a repo
+	CommandSucceeded Type = "command_succeeded"
+	// CommandFailed is emitted when the command exited with an error in a repo
+	CommandFailed Type = "command_failed"
+	// BranchPushed is emitted when the branch with the changes made to a repo has been pushed
+	BranchPushed Type = "branch_pushed"
+	// PullRequestOpened is emitted for each pull request opened
+	PullRequestOpened Type = "pr_opened"
+	// RepoSucceeded is emitted when a repo has been processed without error
+	RepoSucceeded Type = "repo_succeeded"
+	// RepoFailed is emitted when processing a repo returned an error
+	RepoFailed Type = "repo_failed"
+)
+
+// StdoutPath is the --events-file path that streams the events to stdout
+const StdoutPath = "-"
+
+// Event is a single line of the stream
+type Event struct {
+	Time           time.Time `json:"time"`
+	RunID          string    `json:"run_id"`
+	Type           Type      `json:"type"`
+	Repo           string    `json:"repo,omitempty"`
+	Repos          int       `json:"repos,omitempty"`
+	PullRequestURL string    `json:"pull_request_url,omitempty"`
+	Draft          bool      `json:"draft,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Stream writes the events of a run to --events-file, if it was passed, and is nil otherwise, in which case its
+// methods write nothing
+type Stream struct {
+	runID  string
+	mutex  sync.Mutex
+	out    io.Writer
+	closer io.Closer
+}
+
+// Open returns a Stream for the run with the supplied ID that appends to the file at the supplied path, creating it if
+// needed, or that writes to stdout if the path is StdoutPath
+func Open(path string, runID string) (*Stream, error) {
+	if path == StdoutPath {
+		return New(os.Stdout, runID), nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	stream := New(file, runID)
+	stream.closer = file
+	return stream, nil
+}
+
+// New returns a Stream for the run with the supplied ID that writes to the supplied writer
+func New(out io.Writer, runID string) *Stream {
+	return &Stream{out: out, runID: runID}
+}
+
+// Close closes the file the stream writes to, if it opened one
+func (s *Stream) Close() error {
+	if s == nil || s.closer == nil {
+		return nil
+	}
+	return errors.WithStackTrace(s.closer.Close())
+}
+
+// RunStarted emits the start of the run, with the number of repos it will process
+func (s *Stream) RunStarted(repos int) {
+	s.emit(Event{Type: RunStarted, Repos: repos})
+}
+
+// RunFinished emits the end of the run
+func (s *Stream) RunFinished() {
+	s.emit(Event{Type: RunFinished})
+}
+
+// Repo emits the supplied lifecycle transition of the supplied repo
+func (s *Stream) Repo(eventType Type, repo *github.Repository) {
+	s.emit(Event{Type: eventType, Repo: fullName(repo)})
+}
+
+// RepoError emits the supplied lifecycle transition of the supplied repo, along with the error that caused it
+func (s *Stream) RepoError(eventType Type, repo *github.Repository, err error) {
+	s.emit(Event{Type: eventType, Repo: fullName(repo), Error: err.Error()})
+}
+
+// PullRequestOpened emits the opening of a pull request for the supplied repo
+func (s *Stream) PullRequestOpened(repo *github.Repository, url string, draft bool) {
+	s.emit(Event{Type: PullRequestOpened, Repo: fullName(repo), PullRequestURL: url, Draft: draft})
+}
+
+// emit writes the supplied event as a single line. Events that can't be written are dropped rather than failing the
+// run, since the stream is only there to follow the run
+func (s *Stream) emit(event Event) {
+	if s == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.RunID = s.runID
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.out.Write(append(line, '\n'))
+}
+
+func fullName(repo *github.Repository) string {
+	return repo.GetOwner().GetLogin() + "/" + repo.GetName()
+}
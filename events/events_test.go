@@ -0,0 +1,79 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamWritesOneEventPerLine(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	stream := New(&buffer, "run-1")
+	repo := &github.Repository{Name: github.String("fetch"), Owner: &github.User{Login: github.String("gruntwork-io")}}
+
+	stream.RunStarted(1)
+	stream.Repo(RepoCloned, repo)
+	stream.PullRequestOpened(repo, "https://github.com/gruntwork-io/fetch/pull/1", true)
+	stream.RepoError(RepoFailed, repo, fmt.Errorf("exit status 1"))
+
+	events := []Event{}
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		event := Event{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 4)
+
+	assert.Equal(t, RunStarted, events[0].Type)
+	assert.Equal(t, 1, events[0].Repos)
+	assert.Equal(t, "run-1", events[0].RunID)
+	assert.False(t, events[0].Time.IsZero())
+
+	assert.Equal(t, Event{Time: events[1].Time, RunID: "run-1", Type: RepoCloned, Repo: "gruntwork-io/fetch"}, events[1])
+	assert.Equal(t, "https://github.com/gruntwork-io/fetch/pull/1", events[2].PullRequestURL)
+	assert.True(t, events[2].Draft)
+	assert.Equal(t, "exit status 1", events[3].Error)
+}
+
+// Test that each run appends to the events file, so that the runs of watch end up in a single stream
+func TestOpenAppendsToFile(t *testing.T) {
+	t.Parallel()
+
+	file, err := ioutil.TempFile("", "git-xargs-events")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	defer os.Remove(file.Name())
+
+	for _, runID := range []string{"run-1", "run-2"} {
+		stream, err := Open(file.Name(), runID)
+		require.NoError(t, err)
+		stream.RunFinished()
+		require.NoError(t, stream.Close())
+	}
+
+	content, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(content), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[1]), `"run_id":"run-2"`)
+}
+
+func TestNilStreamIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	var stream *Stream
+	stream.RunStarted(1)
+	stream.Repo(RepoCloned, &github.Repository{})
+	assert.NoError(t, stream.Close())
+}
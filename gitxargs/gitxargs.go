@@ -0,0 +1,219 @@
+// Package gitxargs runs git-xargs from Go code, for programs that embed it rather than shelling out to the git-xargs
+// binary. Unlike the CLI, it reads neither flags nor environment variables: everything a run needs, including the
+// GitHub token, is set on the config passed to Run.
+//
+//	config := gitxargs.NewConfig(token)
+//	config.GithubOrg = "my-org"
+//	config.BranchName = "upgrade-ci"
+//	config.Args = []string{"/path/to/upgrade-ci.sh"}
+//	result, err := gitxargs.Run(ctx, config)
+package gitxargs
+
+import (
+	"context"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/events"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/repository"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// RunResult is the outcome of a run
+type RunResult struct {
+	// RunID identifies the run in the state store, and in the markers left on everything the run created
+	RunID string
+	// Report holds the repos in each event category, the pull requests opened and the error of each repo that failed
+	Report *types.RunReport
+	// Failed is the number of repos that failed. Repos failing doesn't make Run return an error
+	Failed int
+}
+
+// NewConfig returns a config with the same defaults as the CLI, authenticating with the supplied GitHub token. Set the
+// repos to select, the branch and the command on it before passing it to Run
+func NewConfig(githubToken string) *config.GitXargsConfig {
+	return config.NewGitXargsConfigForToken(githubToken)
+}
+
+// Run runs the command of the supplied config against the repos it selects and opens pull requests with the changes,
+// like the CLI does, but returns the run report instead of printing it. Cancelling the supplied context stops the repos
+// being processed. An error is only returned if the run couldn't start or be recorded, not if repos failed.
+//
+// Unlike the CLI, Run only records the run in a state store if StateFile is set, and approves pull requests with
+// GithubClient unless ApproverGithubClient is set. The APIHeaders of the config are sent with the requests of each of
+// its GitHub API clients
+func Run(ctx context.Context, config *config.GitXargsConfig) (*RunResult, error) {
+	if config.GithubToken == "" {
+		return nil, errors.WithStackTrace(types.NoGithubOauthTokenProvidedErr{})
+	}
+	if config.StateFile == "" {
+		config.SkipState = true
+	}
+	if config.ApproverGithubClient.PullRequests == nil {
+		config.ApproverGithubClient = config.GithubClient
+	}
+	config.ApplyAPIHeaders()
+	if len(config.Args) < 1 && len(config.FileChanges) == 0 && len(config.FileMoves) == 0 {
+		return nil, errors.WithStackTrace(types.NoArgumentsPassedErr{})
+	}
+	if err := gitxargs_io.EnsureValidOptionsPassed(config); err != nil {
+		return nil, err
+	}
+
+	config.Context = ctx
+
+	if err := OpenStateStore(config); err != nil {
+		return nil, err
+	}
+	defer config.State.Close()
+
+	if err := OpenEventStream(config); err != nil {
+		return nil, err
+	}
+	defer config.Events.Close()
+
+	if err := ProcessRun(config); err != nil {
+		return nil, err
+	}
+
+	report := config.Stats.GenerateRunReport()
+	return &RunResult{
+		RunID:  config.RunID,
+		Report: report,
+		Failed: len(report.Errors),
+	}, nil
+}
+
+// OpenStateStore opens the state store at StateFile (--state-file), or the default path, unless SkipState
+// (--skip-state) is set. The caller is responsible for closing it
+func OpenStateStore(config *config.GitXargsConfig) error {
+	if config.SkipState {
+		return nil
+	}
+
+	path := config.StateFile
+	if path == "" {
+		defaultPath, err := state.DefaultPath()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+
+	store, err := state.Open(path)
+	if err != nil {
+		return err
+	}
+	config.State = store
+
+	// If --manifest was passed, make its run known to the store, so that it can be looked up by ID
+	if config.Manifest != nil {
+		return manifest.Import(store, config.Manifest)
+	}
+	return nil
+}
+
+// OpenEventStream opens the stream at EventsFile (--events-file), if it is set. The caller is responsible for closing it
+func OpenEventStream(config *config.GitXargsConfig) error {
+	if config.EventsFile == "" {
+		return nil
+	}
+
+	stream, err := events.Open(config.EventsFile, config.RunID)
+	if err != nil {
+		return err
+	}
+	config.Events = stream
+	return nil
+}
+
+// RenderBranchName fills in the run-wide placeholders, such as {{.RunID}}, in the branch name of the supplied config, and
+// puts the key of --jira-issue in front of it
+func RenderBranchName(config *config.GitXargsConfig) error {
+	branchName, err := util.RenderTemplate(config.BranchName, types.TemplateData{
+		Date:  config.StartTime.UTC().Format("2006-01-02"),
+		RunID: config.RunID,
+	})
+	if err != nil {
+		return errors.WithStackTrace(types.InvalidTemplateErr{Flag: "branch-name", Err: err})
+	}
+	config.BranchName = branchName
+	config.BranchName = repository.BranchNameWithJiraIssue(config)
+	return nil
+}
+
+// ProcessRun selects the repos, processes them and records the run in the state store, without printing the run report
+func ProcessRun(config *config.GitXargsConfig) error {
+	// Track whether pull requests were skipped
+	config.Stats.SetSkipPullRequests(config.SkipPullRequests)
+
+	// Update raw command supplied
+	config.Stats.SetCommand(config.Args)
+
+	// Track the ID of this run, so it is printed in the final report
+	config.Stats.SetRunID(config.RunID)
+
+	// If --report-diff-lines was passed, preview the diff of each changed repo in the run reports
+	config.Stats.SetDiffPreviewLines(config.ReportDiffLines)
+
+	if err := RenderBranchName(config); err != nil {
+		return err
+	}
+
+	// Look up the --project board up front, so that a bad project or field fails the run before any repos are touched
+	if err := repository.ResolveProject(config); err != nil {
+		return err
+	}
+
+	// Detect the version of each --github-host, so that the features it doesn't support are disabled for its repos
+	// rather than failing them midway
+	repository.DetectGithubHostVersions(config)
+
+	// Expand any teams passed via --assignees into their members before building the assignee pool
+	if err := repository.ResolveAssignees(config); err != nil {
+		return err
+	}
+
+	// Record the start of this run in the state store, so its repos and pull requests can be found by run ID later. A
+	// resumed run keeps its original record, and its branch, so that the repos it already handled are recognized
+	if config.Resume {
+		run, err := config.State.GetRun(config.RunID)
+		if err != nil {
+			return err
+		}
+		config.BranchName = run.BranchName
+		config.BaseBranchName = run.BaseBranchName
+	} else {
+		err := config.State.StartRun(state.Run{
+			ID:                     config.RunID,
+			Command:                config.Args,
+			BranchName:             config.BranchName,
+			BaseBranchName:         config.BaseBranchName,
+			CommitMessage:          config.CommitMessage,
+			PullRequestTitle:       config.PullRequestTitle,
+			PullRequestDescription: config.PullRequestDescription,
+			StartedAt:              config.StartTime,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Repos that failed don't stop the run from being recorded and reported: they are listed in the run report, and
+	// whether they make git-xargs exit with an error is up to --allowed-failures and --allowed-failure-rate
+	if err := repository.OperateOnRepos(config); err != nil {
+		if _, isProcessReposErr := errors.Unwrap(err).(types.ProcessReposErr); !isProcessReposErr {
+			return err
+		}
+	}
+
+	if err := config.State.RecordEvents(config.RunID, config.Stats.GetRepos()); err != nil {
+		return err
+	}
+	return config.State.FinishRun(config.RunID, time.Now())
+}
@@ -0,0 +1,75 @@
+package gitxargs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/local"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A smoke test that a run can be embedded without any flags or environment variables
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	config := NewConfig("test-token")
+	config.GithubClient = mocks.ConfigureMockGithubClient()
+	config.GitClient = local.NewGitClient(local.MockGitProvider{})
+	config.GithubOrg = "gruntwork-io"
+	config.BranchName = "test-branch-name"
+	config.Args = []string{"touch", "test.txt"}
+	config.SkipState = true
+
+	result, err := Run(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, config.RunID, result.RunID)
+	assert.Equal(t, len(result.Report.Errors), result.Failed)
+	assert.NotEmpty(t, result.Report.Repos)
+}
+
+func TestRunRequiresAToken(t *testing.T) {
+	t.Parallel()
+
+	config := NewConfig("")
+	config.BranchName = "test-branch-name"
+	config.Args = []string{"touch", "test.txt"}
+
+	_, err := Run(context.Background(), config)
+	assert.IsType(t, types.NoGithubOauthTokenProvidedErr{}, errors.Unwrap(err))
+}
+
+func TestRunRecordsStateOnlyIfAStateFileIsSet(t *testing.T) {
+	t.Parallel()
+
+	config := NewConfig("test-token")
+	config.GithubClient = mocks.ConfigureMockGithubClient()
+	config.GitClient = local.NewGitClient(local.MockGitProvider{})
+	config.GithubOrg = "gruntwork-io"
+	config.BranchName = "test-branch-name"
+	config.Args = []string{"touch", "test.txt"}
+
+	_, err := Run(context.Background(), config)
+	require.NoError(t, err)
+	assert.True(t, config.SkipState)
+	assert.Nil(t, config.State)
+}
+
+func TestRunApprovesWithTheRunClientByDefault(t *testing.T) {
+	t.Parallel()
+
+	config := NewConfig("test-token")
+	config.GithubClient = mocks.ConfigureMockGithubClient()
+	config.GitClient = local.NewGitClient(local.MockGitProvider{})
+	config.GithubOrg = "gruntwork-io"
+	config.BranchName = "test-branch-name"
+	config.Args = []string{"touch", "test.txt"}
+	config.ApproveAndMerge = true
+
+	_, err := Run(context.Background(), config)
+	require.NoError(t, err)
+	assert.NotNil(t, config.ApproverGithubClient.PullRequests)
+}
@@ -5,9 +5,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/gruntwork-io/git-xargs/logging"
 	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/gruntwork-io/git-xargs/util"
-	"github.com/gruntwork-io/go-commons/logging"
 	"github.com/sirupsen/logrus"
 )
 
@@ -1,18 +1,360 @@
 package io
 
 import (
+	"fmt"
+	"net/mail"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/notify"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/schedule"
 	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
 	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/robfig/cron/v3"
+	"golang.org/x/crypto/openpgp"
 )
 
 // EnsureValidOptionsPassed checks that user has provided one valid method for selecting repos to operate on
 func EnsureValidOptionsPassed(config *config.GitXargsConfig) error {
-	if len(config.RepoSlice) < 1 && config.ReposFile == "" && config.GithubOrg == "" && len(config.RepoFromStdIn) == 0 {
-		return errors.WithStackTrace(types.NoRepoSelectionsMadeErr{})
+	if err := EnsureRepoSelectionPassed(config); err != nil {
+		return err
 	}
 	if config.BranchName == "" {
 		return errors.WithStackTrace(types.NoBranchNameErr{})
 	}
+	if len(config.FileChanges) > 0 && len(config.Args) > 0 {
+		return errors.WithStackTrace(types.FileChangesWithCommandErr{})
+	}
+	if len(config.FileMoves) > 0 && (len(config.Args) > 0 || len(config.FileChanges) > 0) {
+		return errors.WithStackTrace(types.MoveFilesWithCommandErr{})
+	}
+	if len(config.ProjectFieldValues) > 0 && config.Project == "" {
+		return errors.WithStackTrace(types.ProjectFieldWithoutProjectErr{})
+	}
+	if err := EnsureValidTemplate("branch-name", config.BranchName); err != nil {
+		return err
+	}
+	if err := EnsureValidTemplate("pull-request-title", config.PullRequestTitle); err != nil {
+		return err
+	}
+	if err := EnsureValidTemplate("pull-request-footer", config.PullRequestFooter); err != nil {
+		return err
+	}
+	if err := EnsureValidTemplate("commit-status-url", config.CommitStatusURL); err != nil {
+		return err
+	}
+	if config.ReviewerStrategy != "" && !reviewers.IsValidStrategy(config.ReviewerStrategy) {
+		return errors.WithStackTrace(types.InvalidReviewerStrategyErr{Strategy: config.ReviewerStrategy})
+	}
+	if config.AssigneeStrategy != "" && !reviewers.IsValidStrategy(config.AssigneeStrategy) {
+		return errors.WithStackTrace(types.InvalidAssigneeStrategyErr{Strategy: config.AssigneeStrategy})
+	}
+	if !IsValidOutputFormat(config.OutputFormat) {
+		return errors.WithStackTrace(types.InvalidOutputFormatErr{Format: config.OutputFormat})
+	}
+	if config.AllowedFailureRate > 1 {
+		return errors.WithStackTrace(types.InvalidAllowedFailureRateErr{Rate: config.AllowedFailureRate})
+	}
+	if config.MaxFailureRate > 1 {
+		return errors.WithStackTrace(types.InvalidMaxFailureRateErr{Rate: config.MaxFailureRate})
+	}
+	if config.Resume && !config.RunIDSupplied {
+		return errors.WithStackTrace(types.ResumeWithoutRunIDErr{})
+	}
+	if config.Resume && config.SkipState {
+		return errors.WithStackTrace(types.ResumeWithSkipStateErr{})
+	}
+	if config.DryRunLevel != "" && !IsValidDryRunLevel(config.DryRunLevel) {
+		return errors.WithStackTrace(types.InvalidDryRunLevelErr{Level: config.DryRunLevel})
+	}
+	if config.SkipCITrailer != "" && !IsValidSkipCIIn(config.SkipCIIn) {
+		return errors.WithStackTrace(types.InvalidSkipCIInErr{SkipCIIn: config.SkipCIIn})
+	}
+	if util.IsGitTrailer(config.SkipCITrailer) && config.SkipCIIn != common.SkipCIInCommit {
+		return errors.WithStackTrace(types.SkipCITrailerInTitleErr{Trailer: config.SkipCITrailer, SkipCIIn: config.SkipCIIn})
+	}
+	if config.MonorepoManifest != "" && !IsValidMonorepoPullRequests(config.MonorepoPullRequests) {
+		return errors.WithStackTrace(types.InvalidMonorepoPullRequestsErr{MonorepoPullRequests: config.MonorepoPullRequests})
+	}
+	if config.MonorepoManifest != "" && config.GithubOrg != "" {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "monorepo-manifest", Second: "github-org", Reason: "the manifest selects the repos"})
+	}
+	if config.MonorepoManifest != "" && config.ReposFile != "" {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "monorepo-manifest", Second: "repos", Reason: "the manifest selects the repos"})
+	}
+	if config.MonorepoManifest != "" && config.MaxFilesPerPR > 0 {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "monorepo-manifest", Second: "max-files-per-pull-request", Reason: "the changes are already split by directory"})
+	}
+	if config.MonorepoManifest != "" && config.MonorepoPullRequests == common.MonorepoCombined && config.PushViaAPI {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "monorepo-manifest", Second: "push-via-api", Reason: "a combined pull request's branch holds a commit per directory. Pass --monorepo-pull-requests per-directory to push via the API"})
+	}
+	if config.MonorepoManifest != "" && len(config.FileChanges) > 0 {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "monorepo-manifest", Second: "put-file", Reason: "files changed via the Contents API aren't changed per directory"})
+	}
+	if config.PullRequestSchedule != "" && config.RepoTimeout > 0 {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "pr-schedule", Second: "repo-timeout", Reason: "pull requests may be held for longer than the timeout"})
+	}
+	if config.PullRequestSchedule != "" && config.SkipPullRequests {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "pr-schedule", Second: "skip-pull-requests", Reason: "no pull requests are opened"})
+	}
+	if config.Pick && config.Schedule != "" {
+		return errors.WithStackTrace(types.PickWithScheduleErr{})
+	}
+	if config.SkipPullRequests && config.ApproveAndMerge {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "skip-pull-requests", Second: "approve-and-merge", Reason: "there are no pull requests to merge"})
+	}
+	if config.SkipPullRequests && config.Draft {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "skip-pull-requests", Second: "draft", Reason: "no pull requests are opened"})
+	}
+	if config.ApproveAndMerge && config.Draft {
+		return errors.WithStackTrace(types.ApproveAndMergeWithDraftErr{})
+	}
+	if config.ApproveAndMerge && !IsValidMergeMethod(config.MergeMethod) {
+		return errors.WithStackTrace(types.InvalidMergeMethodErr{MergeMethod: config.MergeMethod})
+	}
+	if config.MaxOpenPRs < 0 {
+		return errors.WithStackTrace(types.InvalidMaxOpenPRsErr{MaxOpenPRs: config.MaxOpenPRs})
+	}
+	if config.SigningKey != nil && config.PushViaAPI {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "signing-key", Second: "push-via-api", Reason: "the commits are created by the Git Data API, which doesn't take the signatures of the local commits"})
+	}
+	if config.MaxOpenPRs > 0 && config.SkipState {
+		return errors.WithStackTrace(types.MutuallyExclusiveFlagsErr{First: "max-open-prs", Second: "skip-state", Reason: "the repos that don't fit are queued in the state store for git-xargs continue"})
+	}
+	if config.MaxOpenPRs > 0 && config.MaxOpenPRsScope != common.MaxOpenPRsScopeRun && config.MaxOpenPRsScope != common.MaxOpenPRsScopeOrg {
+		return errors.WithStackTrace(types.InvalidMaxOpenPRsScopeErr{Scope: config.MaxOpenPRsScope})
+	}
+	if config.SplitBy != "" && config.SplitBy != common.SplitByDirectory && config.SplitBy != common.SplitByFile {
+		return errors.WithStackTrace(types.InvalidSplitByErr{SplitBy: config.SplitBy})
+	}
+	if config.TrackingIssueRepo != "" && util.ConvertStringToAllowedRepo(config.TrackingIssueRepo) == nil {
+		return errors.WithStackTrace(types.InvalidTrackingIssueRepoErr{Repo: config.TrackingIssueRepo})
+	}
+	if config.JiraIssue != "" && config.JiraURL == "" {
+		return errors.WithStackTrace(types.JiraIssueWithoutURLErr{})
+	}
+	if config.JiraIssue != "" && !IsValidJiraIssueKey(config.JiraIssue) {
+		return errors.WithStackTrace(types.InvalidJiraIssueKeyErr{Key: config.JiraIssue})
+	}
+	if len(config.EmailTo) > 0 && config.EmailFrom == "" {
+		return errors.WithStackTrace(types.EmailToWithoutFromErr{})
+	}
+	if len(config.EmailTo) > 0 && config.SMTPServer == "" {
+		return errors.WithStackTrace(types.EmailToWithoutSMTPServerErr{})
+	}
+	for _, address := range append([]string{config.EmailFrom}, config.EmailTo...) {
+		if _, err := mail.ParseAddress(address); address != "" && err != nil {
+			return errors.WithStackTrace(types.InvalidEmailAddressErr{Address: address})
+		}
+	}
+	if config.DeployKeysDir != "" {
+		if info, err := os.Stat(config.DeployKeysDir); err != nil || !info.IsDir() {
+			return errors.WithStackTrace(types.DeployKeysDirNotFoundErr{Dir: config.DeployKeysDir})
+		}
+	}
+	if config.EnvFilesDir != "" {
+		if info, err := os.Stat(config.EnvFilesDir); err != nil || !info.IsDir() {
+			return errors.WithStackTrace(types.EnvFilesDirNotFoundErr{Dir: config.EnvFilesDir})
+		}
+	}
+	for _, pattern := range config.DraftIfRepoMatches {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.WithStackTrace(types.InvalidDraftRepoPatternErr{Pattern: pattern})
+		}
+	}
+	if config.ReportUpload != nil {
+		if err := notify.EnsureObjectStorageCredentials(*config.ReportUpload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureRepoSelectionPassed checks that user has provided one valid method for selecting repos, which is all that
+// subcommands that don't make changes, such as cleanup-branches, require
+func EnsureRepoSelectionPassed(config *config.GitXargsConfig) error {
+	if len(config.RepoSlice) < 1 && config.ReposFile == "" && config.GithubOrg == "" && len(config.RepoFromStdIn) == 0 {
+		return errors.WithStackTrace(types.NoRepoSelectionsMadeErr{})
+	}
 	return nil
 }
+
+// EnsureValidTemplate renders the supplied templated flag value against sample data, so that syntax errors and unknown
+// placeholders are reported before any repos are processed
+func EnsureValidTemplate(flagName string, text string) error {
+	sampleData := types.TemplateData{
+		RepoOwner: "gruntwork-io",
+		RepoName:  "git-xargs",
+		FullName:  "gruntwork-io/git-xargs",
+		Date:      time.Now().UTC().Format("2006-01-02"),
+		RunID:     "sample-run-id",
+	}
+	if _, err := util.RenderTemplate(text, sampleData); err != nil {
+		return errors.WithStackTrace(types.InvalidTemplateErr{Flag: flagName, Err: err})
+	}
+	return nil
+}
+
+// IsValidOutputFormat returns true if the supplied --output is a run report format git-xargs can write. An empty format
+// means the default table
+func IsValidOutputFormat(format string) bool {
+	switch format {
+	case "", common.OutputFormatTable, common.OutputFormatJSON:
+		return true
+	}
+	return false
+}
+
+// IsValidMergeMethod returns true if the supplied --merge-method is one the GitHub API accepts
+func IsValidMergeMethod(mergeMethod string) bool {
+	switch mergeMethod {
+	case "merge", "squash", "rebase":
+		return true
+	}
+	return false
+}
+
+// ParseSchedule parses the supplied --schedule, a standard five field cron expression such as "0 * * * *"
+func ParseSchedule(schedule string) (cron.Schedule, error) {
+	if schedule == "" {
+		return nil, errors.WithStackTrace(types.NoScheduleProvidedErr{})
+	}
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidScheduleErr{Schedule: schedule, Err: err})
+	}
+	return parsed, nil
+}
+
+// LoadSigningKey loads the ASCII-armored OpenPGP private key at the supplied --signing-key path to sign commits with,
+// decrypting it with the passphrase exported as GIT_XARGS_SIGNING_KEY_PASSPHRASE if it is encrypted
+func LoadSigningKey(path string) (*openpgp.Entity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidSigningKeyErr{Path: path, Err: err})
+	}
+	defer file.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidSigningKeyErr{Path: path, Err: err})
+	}
+	key := entities[0]
+	if key.PrivateKey == nil {
+		return nil, errors.WithStackTrace(types.InvalidSigningKeyErr{Path: path, Err: fmt.Errorf("it is not a private key")})
+	}
+	if key.PrivateKey.Encrypted {
+		if err := key.PrivateKey.Decrypt([]byte(os.Getenv("GIT_XARGS_SIGNING_KEY_PASSPHRASE"))); err != nil {
+			return nil, errors.WithStackTrace(types.InvalidSigningKeyErr{Path: path, Err: err})
+		}
+	}
+	return key, nil
+}
+
+// ParsePullRequestSchedule parses the supplied --pr-schedule window in the supplied --pr-schedule-timezone, or in the
+// local timezone if none was supplied, and returns the gate that holds pull requests until the window opens, letting
+// at most maxPerWindow through each time it does
+func ParsePullRequestSchedule(window string, timezone string, maxPerWindow int) (*schedule.Gate, error) {
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, errors.WithStackTrace(types.InvalidPullRequestScheduleErr{Schedule: window, Err: err})
+		}
+	}
+	if maxPerWindow < 0 {
+		return nil, errors.WithStackTrace(types.InvalidPullRequestScheduleErr{Schedule: window, Err: fmt.Errorf("--pr-schedule-max-per-window can't be negative")})
+	}
+
+	parsed, err := schedule.ParseWindow(window, location)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPullRequestScheduleErr{Schedule: window, Err: err})
+	}
+	return schedule.NewGate(parsed, maxPerWindow), nil
+}
+
+// ParseSuccessRate parses the supplied --min-success-rate, either a percentage such as "95%" or a fraction such as 0.95,
+// into a fraction between 0 and 1
+func ParseSuccessRate(rate string) (float64, error) {
+	trimmed := strings.TrimSpace(rate)
+	divisor := 1.0
+	if strings.HasSuffix(trimmed, "%") {
+		trimmed = strings.TrimSpace(strings.TrimSuffix(trimmed, "%"))
+		divisor = 100
+	}
+
+	parsed, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil || parsed < 0 || parsed/divisor > 1 {
+		return 0, errors.WithStackTrace(types.InvalidMinSuccessRateErr{Rate: rate})
+	}
+	return parsed / divisor, nil
+}
+
+// ParseReportUploadURL parses the location passed to --report-upload, s3://<bucket>/<prefix> or gs://<bucket>/<prefix>.
+// The prefix is optional
+func ParseReportUploadURL(uploadURL string) (*types.ObjectStorageLocation, error) {
+	scheme := ""
+	for _, supported := range []string{common.ObjectStorageS3, common.ObjectStorageGCS} {
+		if strings.HasPrefix(uploadURL, supported+"://") {
+			scheme = supported
+		}
+	}
+	if scheme == "" {
+		return nil, errors.WithStackTrace(types.InvalidReportUploadURLErr{URL: uploadURL})
+	}
+
+	path := strings.TrimPrefix(uploadURL, scheme+"://")
+	parts := strings.SplitN(path, "/", 2)
+	bucket := parts[0]
+	prefix := ""
+	if len(parts) == 2 {
+		prefix = strings.Trim(parts[1], "/")
+	}
+	if bucket == "" || strings.ContainsAny(bucket, " ?#") {
+		return nil, errors.WithStackTrace(types.InvalidReportUploadURLErr{URL: uploadURL})
+	}
+	return &types.ObjectStorageLocation{Scheme: scheme, Bucket: bucket, Prefix: prefix}, nil
+}
+
+// IsValidDryRunLevel returns true if the supplied level is one of the levels --dry-run-level accepts
+func IsValidDryRunLevel(level string) bool {
+	switch level {
+	case common.DryRunListOnly, common.DryRunCloneAndRun, common.DryRunCommitNoPush, common.DryRunPushNoPR:
+		return true
+	}
+	return false
+}
+
+// IsValidSkipCIIn returns true if the supplied --skip-ci-in is one of the places the --skip-ci-trailer can be added to
+func IsValidSkipCIIn(skipCIIn string) bool {
+	switch skipCIIn {
+	case common.SkipCIInCommit, common.SkipCIInTitle, common.SkipCIInBoth:
+		return true
+	}
+	return false
+}
+
+// IsValidMonorepoPullRequests returns true if the supplied --monorepo-pull-requests is one of the ways pull requests can
+// be opened for the directories of a monorepo
+func IsValidMonorepoPullRequests(monorepoPullRequests string) bool {
+	switch monorepoPullRequests {
+	case common.MonorepoCombined, common.MonorepoPerDirectory:
+		return true
+	}
+	return false
+}
+
+// jiraIssueKeyRegex matches the keys of Jira issues, which are the key of their project, followed by a number
+var jiraIssueKeyRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[0-9]+$`)
+
+// IsValidJiraIssueKey returns true if the supplied --jira-issue looks like the key of a Jira issue, e.g. PLAT-123
+func IsValidJiraIssueKey(key string) bool {
+	return jiraIssueKeyRegex.MatchString(key)
+}
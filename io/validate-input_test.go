@@ -1,10 +1,19 @@
 package io
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
 )
 
 func TestEnsureValidOptionsPassedRejectsEmptySelectors(t *testing.T) {
@@ -61,3 +70,270 @@ func TestEnsureValidOptionsPassedAcceptsAllFlagsSimultaneously(t *testing.T) {
 	err := EnsureValidOptionsPassed(testConfigWithAllSelectionCriteria)
 	assert.NoError(t, err)
 }
+
+func TestEnsureValidOptionsPassedRejectsInvalidDraftRepoPattern(t *testing.T) {
+	t.Parallel()
+	testConfigWithBadPattern := &config.GitXargsConfig{
+		BranchName:         "test-branch",
+		GithubOrg:          "gruntwork-io",
+		DraftIfRepoMatches: []string{"terraform-(aws"},
+	}
+
+	err := EnsureValidOptionsPassed(testConfigWithBadPattern)
+	assert.Error(t, err)
+}
+
+func TestEnsureValidOptionsPassedRejectsInvalidTitleTemplate(t *testing.T) {
+	t.Parallel()
+	testConfigWithBadTemplate := &config.GitXargsConfig{
+		BranchName:       "test-branch",
+		GithubOrg:        "gruntwork-io",
+		PullRequestTitle: "Update CI config ({{.RepoNam}})",
+	}
+
+	err := EnsureValidOptionsPassed(testConfigWithBadTemplate)
+	assert.Error(t, err)
+}
+
+func TestEnsureValidOptionsPassedRejectsBadMergeMethod(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.ApproveAndMerge = true
+	testConfig.MergeMethod = "fast-forward"
+
+	err := EnsureValidOptionsPassed(testConfig)
+	assert.Error(t, err)
+}
+
+func TestEnsureValidOptionsPassedRejectsPickWithSchedule(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.Pick = true
+	testConfig.Schedule = "0 * * * *"
+
+	err := EnsureValidOptionsPassed(testConfig)
+	assert.Error(t, err)
+}
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := ParseSchedule("0 * * * *")
+	require.NoError(t, err)
+	next := schedule.Next(time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC), next)
+
+	_, err = ParseSchedule("")
+	assert.Error(t, err)
+
+	_, err = ParseSchedule("every hour")
+	assert.Error(t, err)
+}
+
+func TestParsePullRequestSchedule(t *testing.T) {
+	t.Parallel()
+
+	gate, err := ParsePullRequestSchedule("Mon-Fri 09:00-11:00", "UTC", 10)
+	require.NoError(t, err)
+	assert.NotNil(t, gate)
+
+	_, err = ParsePullRequestSchedule("Mon-Fri 09:00-11:00", "Mars/Olympus_Mons", 0)
+	assert.Error(t, err)
+
+	_, err = ParsePullRequestSchedule("Mon-Fri 09:00-11:00", "", -1)
+	assert.Error(t, err)
+
+	_, err = ParsePullRequestSchedule("weekdays", "", 0)
+	assert.Error(t, err)
+}
+
+func TestParseSuccessRate(t *testing.T) {
+	t.Parallel()
+
+	for rate, expected := range map[string]float64{"95%": 0.95, " 100 % ": 1, "0.9": 0.9, "0": 0} {
+		parsed, err := ParseSuccessRate(rate)
+		require.NoError(t, err, rate)
+		assert.InDelta(t, expected, parsed, 1e-9, rate)
+	}
+
+	for _, rate := range []string{"95", "101%", "-1%", "most", ""} {
+		_, err := ParseSuccessRate(rate)
+		assert.Error(t, err, rate)
+	}
+}
+
+func TestParseReportUploadURL(t *testing.T) {
+	t.Parallel()
+
+	for uploadURL, expected := range map[string]types.ObjectStorageLocation{
+		"s3://reports/git-xargs/": {Scheme: "s3", Bucket: "reports", Prefix: "git-xargs"},
+		"gs://reports":            {Scheme: "gs", Bucket: "reports"},
+		"gs://reports/ci/runs":    {Scheme: "gs", Bucket: "reports", Prefix: "ci/runs"},
+	} {
+		location, err := ParseReportUploadURL(uploadURL)
+		require.NoError(t, err, uploadURL)
+		assert.Equal(t, expected, *location, uploadURL)
+	}
+
+	for _, uploadURL := range []string{"reports/git-xargs", "https://reports.s3.amazonaws.com", "s3://", "s3:///prefix"} {
+		_, err := ParseReportUploadURL(uploadURL)
+		assert.Error(t, err, uploadURL)
+	}
+}
+
+// Test that a run whose report can't be uploaded fails before it starts
+func TestEnsureValidOptionsPassedRequiresReportUploadCredentials(t *testing.T) {
+	for _, name := range []string{"CLOUDSDK_AUTH_ACCESS_TOKEN", "GOOGLE_APPLICATION_CREDENTIALS", "CLOUDSDK_CONFIG", "GCE_METADATA_HOST"} {
+		defer os.Setenv(name, os.Getenv(name))
+	}
+	os.Unsetenv("CLOUDSDK_AUTH_ACCESS_TOKEN")
+	os.Unsetenv("GOOGLE_APPLICATION_CREDENTIALS")
+	os.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+	// Nothing listens on port 1, so the metadata server is found missing right away
+	os.Setenv("GCE_METADATA_HOST", "127.0.0.1:1")
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.ReportUpload = &types.ObjectStorageLocation{Scheme: common.ObjectStorageGCS, Bucket: "reports"}
+
+	err := EnsureValidOptionsPassed(testConfig)
+	require.Error(t, err)
+	_, ok := errors.Unwrap(err).(types.MissingObjectStorageCredentialsErr)
+	assert.True(t, ok)
+
+	os.Setenv("CLOUDSDK_AUTH_ACCESS_TOKEN", "token")
+	assert.NoError(t, EnsureValidOptionsPassed(testConfig))
+}
+
+func TestEnsureValidOptionsPassedRejectsBadDryRunLevel(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.DryRunLevel = "push-everything"
+
+	err := EnsureValidOptionsPassed(testConfig)
+	assert.Error(t, err)
+}
+
+// Test that a --skip-ci-trailer in the key: value form can only be added to commit messages
+func TestEnsureValidOptionsPassedRejectsSkipCITrailerInTitles(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.SkipCITrailer = "skip-checks: true"
+	require.NoError(t, EnsureValidOptionsPassed(testConfig))
+
+	testConfig.SkipCIIn = common.SkipCIInBoth
+	err := EnsureValidOptionsPassed(testConfig)
+	assert.IsType(t, types.SkipCITrailerInTitleErr{}, errors.Unwrap(err))
+
+	testConfig.SkipCITrailer = "[skip ci]"
+	assert.NoError(t, EnsureValidOptionsPassed(testConfig))
+}
+
+func TestEnsureValidOptionsPassedRejectsMutuallyExclusiveFlags(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		first     string
+		second    string
+		configure func(testConfig *config.GitXargsConfig)
+	}{
+		{"monorepo-manifest", "github-org", func(c *config.GitXargsConfig) { c.MonorepoManifest = "monorepos.yml"; c.GithubOrg = "gruntwork-io" }},
+		{"monorepo-manifest", "repos", func(c *config.GitXargsConfig) { c.MonorepoManifest = "monorepos.yml"; c.ReposFile = "repos.txt" }},
+		{"monorepo-manifest", "max-files-per-pull-request", func(c *config.GitXargsConfig) { c.MonorepoManifest = "monorepos.yml"; c.MaxFilesPerPR = 10 }},
+		{"monorepo-manifest", "push-via-api", func(c *config.GitXargsConfig) { c.MonorepoManifest = "monorepos.yml"; c.PushViaAPI = true }},
+		{"monorepo-manifest", "put-file", func(c *config.GitXargsConfig) {
+			c.MonorepoManifest = "monorepos.yml"
+			c.FileChanges = []types.FileChange{{Path: "README.md"}}
+		}},
+		{"pr-schedule", "repo-timeout", func(c *config.GitXargsConfig) {
+			c.PullRequestSchedule = "Mon-Fri 09:00-17:00"
+			c.RepoTimeout = time.Minute
+		}},
+		{"pr-schedule", "skip-pull-requests", func(c *config.GitXargsConfig) {
+			c.PullRequestSchedule = "Mon-Fri 09:00-17:00"
+			c.SkipPullRequests = true
+		}},
+		{"skip-pull-requests", "approve-and-merge", func(c *config.GitXargsConfig) { c.SkipPullRequests = true; c.ApproveAndMerge = true }},
+		{"skip-pull-requests", "draft", func(c *config.GitXargsConfig) { c.SkipPullRequests = true; c.Draft = true }},
+		{"max-open-prs", "skip-state", func(c *config.GitXargsConfig) { c.MaxOpenPRs = 10; c.SkipState = true }},
+		{"signing-key", "push-via-api", func(c *config.GitXargsConfig) { c.SigningKey = &openpgp.Entity{}; c.PushViaAPI = true }},
+	} {
+		testConfig := config.NewGitXargsTestConfig()
+		testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+		testCase.configure(testConfig)
+
+		err := EnsureValidOptionsPassed(testConfig)
+		if assert.IsType(t, types.MutuallyExclusiveFlagsErr{}, errors.Unwrap(err), testCase.second) {
+			assert.Equal(t, testCase.first, errors.Unwrap(err).(types.MutuallyExclusiveFlagsErr).First)
+			assert.Equal(t, testCase.second, errors.Unwrap(err).(types.MutuallyExclusiveFlagsErr).Second)
+		}
+	}
+
+	// Per-directory pull requests of a monorepo can be pushed via the API
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+	testConfig.MonorepoManifest = "monorepos.yml"
+	testConfig.MonorepoPullRequests = common.MonorepoPerDirectory
+	testConfig.PushViaAPI = true
+	assert.NoError(t, EnsureValidOptionsPassed(testConfig))
+}
+
+func TestEnsureValidOptionsPassedRejectsIncompleteEmailFlags(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		from     string
+		server   string
+		to       string
+		expected error
+	}{
+		{"", "smtp.example.com:587", "owner@example.com", types.EmailToWithoutFromErr{}},
+		{"platform@example.com", "", "owner@example.com", types.EmailToWithoutSMTPServerErr{}},
+		{"platform@example.com", "smtp.example.com:587", "owner", types.InvalidEmailAddressErr{Address: "owner"}},
+		{"Platform Team <platform@example.com>", "smtp.example.com:587", "owner@example.com", nil},
+	} {
+		testConfig := config.NewGitXargsTestConfig()
+		testConfig.RepoSlice = []string{"gruntwork-io/cloud-nuke"}
+		testConfig.EmailTo = []string{testCase.to}
+		testConfig.EmailFrom = testCase.from
+		testConfig.SMTPServer = testCase.server
+
+		err := EnsureValidOptionsPassed(testConfig)
+		if testCase.expected == nil {
+			assert.NoError(t, err)
+		} else {
+			assert.Equal(t, testCase.expected, errors.Unwrap(err))
+		}
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	t.Parallel()
+
+	entity, err := openpgp.NewEntity("git-xargs", "", "git-xargs@example.com", nil)
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "git-xargs-signing-key-*.asc")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	writer, err := armor.Encode(file, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(writer, nil))
+	require.NoError(t, writer.Close())
+	require.NoError(t, file.Close())
+
+	key, err := LoadSigningKey(file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, entity.PrimaryKey.Fingerprint, key.PrimaryKey.Fingerprint)
+
+	_, err = LoadSigningKey("../data/test/good-test-repos.txt")
+	assert.IsType(t, types.InvalidSigningKeyErr{}, errors.Unwrap(err))
+}
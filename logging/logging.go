@@ -0,0 +1,118 @@
+// Package logging creates the loggers used throughout git-xargs. It wraps the go-commons logging package, so that the
+// log of a run can also be written in full to the file passed via --log-file, whatever the console log level is.
+package logging
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/gruntwork-io/go-commons/logging"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// logFile is the file passed via --log-file, or nil if there is none
+	logFile      io.Writer
+	logFileMutex sync.Mutex
+)
+
+// GetLogger returns a logger with the supplied name, which logs to stderr at the global log level. If a log file was
+// opened via OpenLogFile, the logger also writes every message to it, including debug messages
+func GetLogger(name string) *logrus.Logger {
+	logger := logging.GetLogger(name)
+
+	logFileMutex.Lock()
+	file := logFile
+	logFileMutex.Unlock()
+	if file == nil {
+		return logger
+	}
+
+	// The logger has to let every message through for the file to get them all, so the console output moves to a hook
+	// that only writes the messages at or above the global log level
+	consoleLevel := logger.Level
+	logger.AddHook(&writerHook{
+		writer:    logger.Out,
+		formatter: logger.Formatter,
+		levels:    levelsUpTo(consoleLevel),
+	})
+	logger.AddHook(&writerHook{
+		writer: file,
+		formatter: &logging.TextFormatterWithBinName{
+			Name: name,
+			TextFormatter: logrus.TextFormatter{
+				FullTimestamp: true,
+				DisableColors: true,
+			},
+		},
+		levels: logrus.AllLevels,
+	})
+	logger.Out = ioutil.Discard
+	logger.Level = logrus.TraceLevel
+	return logger
+}
+
+// SetGlobalLogLevel sets the level at which the loggers returned by GetLogger afterwards log to the console
+func SetGlobalLogLevel(level logrus.Level) {
+	logging.SetGlobalLogLevel(level)
+}
+
+// OpenLogFile makes the loggers returned by GetLogger afterwards also write every message to the file at the supplied
+// path, which is appended to if it already exists. The returned file must be closed once the run is done
+func OpenLogFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	logFileMutex.Lock()
+	defer logFileMutex.Unlock()
+	logFile = &syncWriter{writer: file}
+	return file, nil
+}
+
+// levelsUpTo returns the log levels at least as severe as the supplied one
+func levelsUpTo(level logrus.Level) []logrus.Level {
+	levels := []logrus.Level{}
+	for _, l := range logrus.AllLevels {
+		if l <= level {
+			levels = append(levels, l)
+		}
+	}
+	return levels
+}
+
+// writerHook is a logrus hook that writes the entries at the supplied levels to a writer, in the supplied format
+type writerHook struct {
+	writer    io.Writer
+	formatter logrus.Formatter
+	levels    []logrus.Level
+}
+
+func (hook *writerHook) Levels() []logrus.Level {
+	return hook.levels
+}
+
+func (hook *writerHook) Fire(entry *logrus.Entry) error {
+	line, err := hook.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = hook.writer.Write(line)
+	return err
+}
+
+// syncWriter serializes the writes of the many loggers sharing the log file, so that their lines don't interleave
+type syncWriter struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.writer.Write(p)
+}
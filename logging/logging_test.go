@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This test isn't run in parallel, because it opens the log file shared by every logger
+func TestLogFileGetsEveryLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-xargs-logging-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "git-xargs.log")
+	file, err := OpenLogFile(path)
+	require.NoError(t, err)
+	defer func() {
+		file.Close()
+		logFileMutex.Lock()
+		logFile = nil
+		logFileMutex.Unlock()
+	}()
+
+	SetGlobalLogLevel(logrus.InfoLevel)
+	logger := GetLogger("git-xargs")
+	logger.Debug("cloning repo")
+	logger.Info("repo processed")
+
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "level=debug msg=\"cloning repo\"")
+	assert.Contains(t, string(contents), "level=info msg=\"repo processed\"")
+	assert.Contains(t, string(contents), "time=")
+}
+
+func TestLevelsUpTo(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel}, levelsUpTo(logrus.WarnLevel))
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/gruntwork-io/git-xargs/cmd"
 	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/version"
 	"github.com/gruntwork-io/go-commons/entrypoint"
 	"github.com/gruntwork-io/go-commons/errors"
-	"github.com/gruntwork-io/go-commons/logging"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -15,29 +20,75 @@ import (
 //
 // build-go-binaries --app-name my-app --dest-path bin --ld-flags "-X main.VERSION=$CIRCLE_TAG"
 //
-// For more info, see: http://stackoverflow.com/a/11355611/483528
+// For more info, see: http://stackoverflow.com/a/11355611/483528. The rest of the build metadata, such as the commit, is
+// set in the version package, which VERSION is copied to so that the rest of git-xargs can read it
 var VERSION string
 
 var (
 	LogLevelFlag = cli.StringFlag{
-		Name:  "loglevel",
-		Value: logrus.InfoLevel.String(),
+		Name:   "log-level, loglevel",
+		EnvVar: "GITXARGS_LOG_LEVEL, GITXARGS_LOGLEVEL, GIT_XARGS_LOG_LEVEL, GIT_XARGS_LOGLEVEL",
+		Usage:  "The level of the messages logged to stderr: trace or debug for everything each repo goes through, info for the progress of the run, or warning or error for problems only",
+		Value:  logrus.InfoLevel.String(),
+	}
+	QuietFlag = cli.BoolFlag{
+		Name:   "quiet",
+		EnvVar: "GITXARGS_QUIET, GIT_XARGS_QUIET",
+		Usage:  "Only log errors, so that the output of a run is its final report. Can't be combined with --log-level",
 	}
+	LogFileFlag = cli.StringFlag{
+		Name:   "log-file",
+		EnvVar: "GITXARGS_LOG_FILE, GIT_XARGS_LOG_FILE",
+		Usage:  "Also write the complete, timestamped log of the run to this file, including debug messages, whatever the --log-level",
+	}
+
+	// logFile is the file opened for --log-file, closed once the command has run
+	logFile *os.File
 )
 
 // initCli initializes the CLI app before any command is actually executed. This function will handle all the setup
 // code, such as setting up the logger with the appropriate log level.
 func initCli(cliContext *cli.Context) error {
 	// Set logging level
-	logLevel := cliContext.String(LogLevelFlag.Name)
-	level, err := logrus.ParseLevel(logLevel)
+	level, err := consoleLogLevel(cliContext)
 	if err != nil {
-		return errors.WithStackTrace(err)
+		return err
 	}
 	logging.SetGlobalLogLevel(level)
+
+	if path := cliContext.String(LogFileFlag.Name); path != "" {
+		logFile, err = logging.OpenLogFile(path)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// closeLogFile closes the file opened for --log-file, if any, once the command has run
+// consoleLogLevel returns the level of the messages logged to the console, as set by --log-level or --quiet
+func consoleLogLevel(cliContext *cli.Context) (logrus.Level, error) {
+	if cliContext.Bool(QuietFlag.Name) {
+		if cliContext.IsSet("log-level") {
+			return 0, errors.WithStackTrace(types.QuietWithLogLevelErr{})
+		}
+		return logrus.ErrorLevel, nil
+	}
+
+	level, err := logrus.ParseLevel(cliContext.String("log-level"))
+	if err != nil {
+		return 0, errors.WithStackTrace(types.InvalidLogLevelErr{Level: cliContext.String("log-level")})
+	}
+	return level, nil
+}
+
+func closeLogFile(cliContext *cli.Context) error {
+	if logFile == nil {
+		return nil
+	}
+	return errors.WithStackTrace(logFile.Close())
+}
+
 func setupApp() *cli.App {
 	app := entrypoint.NewApp()
 	entrypoint.HelpTextLineWidth = 120
@@ -53,31 +104,569 @@ func setupApp() *cli.App {
 	app.Description = "git-xargs is a command-line tool (CLI) for making updates across multiple Github repositories with a single command."
 
 	// Set the version number from your app from the VERSION variable that is passed in at build time
-	app.Version = VERSION
+	if VERSION != "" {
+		version.Version = VERSION
+	}
+	app.Version = version.Version
+
+	// Print the commit, build date and Go version along with the version number
+	cli.VersionPrinter = func(c *cli.Context) {
+		fmt.Fprintln(c.App.Writer, version.Details())
+	}
 
 	app.EnableBashCompletion = true
 
 	app.Before = initCli
+	app.After = closeLogFile
 
-	app.Flags = []cli.Flag{
-		LogLevelFlag,
+	// The flags of a regular run, which the watch subcommand accepts as well
+	runFlags := []cli.Flag{
+		common.GenericConfigFileFlag,
+		common.GenericProfileFlag,
+		common.GenericGithubTokenEnvFlag,
+		common.GenericAPIHeaderFlag,
 		common.GenericGithubOrgFlag,
 		common.GenericDraftPullRequestFlag,
 		common.GenericDryRunFlag,
+		common.GenericDryRunLevelFlag,
 		common.GenericSkipPullRequestFlag,
 		common.GenericSkipArchivedReposFlag,
+		common.GenericSkipReposWithOpenPRsFlag,
 		common.GenericRepoFlag,
 		common.GenericRepoFileFlag,
 		common.GenericBranchFlag,
+		common.GenericRunIDFlag,
+		common.GenericSkipRunMarkersFlag,
+		common.GenericSkipCITrailerFlag,
+		common.GenericSkipCIInFlag,
+		common.GenericNoSkipCIFlag,
+		common.GenericRecordFlag,
+		common.GenericReplayFlag,
+		common.GenericPutFileFlag,
+		common.GenericDeleteFileFlag,
+		common.GenericMoveFileFlag,
+		common.GenericPushViaAPIFlag,
+		common.GenericMonorepoManifestFlag,
+		common.GenericMonorepoPullRequestsFlag,
+		common.GenericGithubHostFlag,
+		common.GenericJiraURLFlag,
+		common.GenericJiraIssueFlag,
+		common.GenericCheckBranchProtectionFlag,
+		common.GenericSigningKeyFlag,
+		common.GenericMergeChecksTimeoutFlag,
+		common.GenericDeployKeysDirFlag,
+		common.GenericEnvFilesDirFlag,
+		common.GenericRepoEnvFileFlag,
+		common.GenericPathLabelsFlag,
+		common.GenericPRScheduleFlag,
+		common.GenericPRScheduleTimezoneFlag,
+		common.GenericPRScheduleMaxFlag,
+		common.GenericMaxOpenPRsFlag,
+		common.GenericMaxOpenPRsScopeFlag,
+		common.GenericApproveAndMergeFlag,
+		common.GenericMergeMethodFlag,
+		common.GenericMaxFilesPerPRFlag,
+		common.GenericSplitByFlag,
 		common.GenericBaseBranchFlag,
 		common.GenericCommitMessageFlag,
 		common.GenericPullRequestTitleFlag,
 		common.GenericPullRequestDescriptionFlag,
+		common.GenericPullRequestFooterFlag,
+		common.GenericCommitStatusFlag,
+		common.GenericCommitStatusURLFlag,
+		common.GenericStateFileFlag,
+		common.GenericSkipStateFlag,
+		common.GenericResumeFlag,
 		common.GenericMaxConcurrentReposFlag,
+		common.GenericAutoConcurrencyFlag,
+		common.GenericRepoTimeoutFlag,
+		common.GenericCloneConcurrencyFlag,
+		common.GenericCommandConcurrencyFlag,
+		common.GenericPushConcurrencyFlag,
+		common.GenericPullRequestConcurrencyFlag,
+		common.GenericDraftIfDiffLinesOverFlag,
+		common.GenericDraftIfChecksPendingFlag,
+		common.GenericDraftIfRepoMatchesFlag,
+		common.GenericProjectFlag,
+		common.GenericProjectFieldFlag,
+		common.GenericReviewersFlag,
+		common.GenericReviewerStrategyFlag,
+		common.GenericReviewersPerPRFlag,
+		common.GenericReviewersFromBlameFlag,
+		common.GenericBlameReviewersCountFlag,
+		common.GenericExcludeReviewersFlag,
+		common.GenericAssigneesFlag,
+		common.GenericAssigneeStrategyFlag,
+		common.GenericAssigneesPerPRFlag,
+		common.GenericOutputFlag,
+		common.GenericOutputFileFlag,
+		common.GenericReportCSVFlag,
+		common.GenericReportMarkdownFlag,
+		common.GenericReportJUnitFlag,
+		common.GenericReportHTMLFlag,
+		common.GenericReportDiffLinesFlag,
+		common.GenericOutputManifestFlag,
+		common.GenericWebhookURLFlag,
+		common.GenericWebhookIncludeEventsFlag,
+		common.GenericSlackWebhookURLFlag,
+		common.GenericSlackChannelFlag,
+		common.GenericEmailToFlag,
+		common.GenericEmailFromFlag,
+		common.GenericSMTPServerFlag,
+		common.GenericPushgatewayURLFlag,
+		common.GenericMetricsJobFlag,
+		common.GenericTelemetryEndpointFlag,
+		common.GenericOTLPEndpointFlag,
+		common.GenericCreateTrackingIssueFlag,
+		common.GenericReportGistFlag,
+		common.GenericReportUploadFlag,
+		common.GenericAllowedFailuresFlag,
+		common.GenericAllowedFailureRateFlag,
+		common.GenericMinSuccessRateFlag,
+		common.GenericFailFastFlag,
+		common.GenericMaxFailuresFlag,
+		common.GenericMaxFailureRateFlag,
+		common.GenericProgressFlag,
+		common.GenericEventsFileFlag,
+		common.GenericAuditLogDirFlag,
+		common.GenericAuditLogHashChainFlag,
+		common.GenericPluginFlag,
+		common.GenericPickFlag,
+		common.GenericTagFlag,
+		common.GenericIgnoreRepoConfigFlag,
+	}
+
+	app.Flags = append([]cli.Flag{LogLevelFlag, QuietFlag, LogFileFlag}, runFlags...)
+
+	// The flags of the clean branches subcommand, which the cleanup-branches alias accepts as well
+	cleanupBranchesFlags := []cli.Flag{
+		common.GenericConfigFileFlag,
+		common.GenericProfileFlag,
+		common.GenericGithubTokenEnvFlag,
+		common.GenericAPIHeaderFlag,
+		common.GenericGithubOrgFlag,
+		common.GenericSkipArchivedReposFlag,
+		common.GenericRepoFlag,
+		common.GenericRepoFileFlag,
+		common.GenericBranchPatternFlag,
+		common.GenericRunIDFlag,
+		common.GenericDryRunFlag,
+		common.GenericOutputFlag,
+		common.GenericOutputFileFlag,
+		common.GenericReportCSVFlag,
+		common.GenericReportMarkdownFlag,
+		common.GenericReportJUnitFlag,
+		common.GenericReportHTMLFlag,
+		common.GenericWebhookURLFlag,
+		common.GenericWebhookIncludeEventsFlag,
+		common.GenericSlackWebhookURLFlag,
+		common.GenericSlackChannelFlag,
+		common.GenericEmailToFlag,
+		common.GenericEmailFromFlag,
+		common.GenericSMTPServerFlag,
+		common.GenericPushgatewayURLFlag,
+		common.GenericMetricsJobFlag,
+		common.GenericTelemetryEndpointFlag,
+		common.GenericOTLPEndpointFlag,
+		common.GenericCreateTrackingIssueFlag,
+		common.GenericReportGistFlag,
+		common.GenericReportUploadFlag,
+		common.GenericAllowedFailuresFlag,
+		common.GenericAllowedFailureRateFlag,
+		common.GenericMinSuccessRateFlag,
 	}
 
 	app.Action = cmd.RunGitXargs
 
+	app.Commands = []cli.Command{
+		{
+			Name:      "run",
+			Usage:     "Run the command against all selected repos and open pull requests with the resulting changes. Running git-xargs without a subcommand does the same",
+			ArgsUsage: "<command>",
+			Flags:     runFlags,
+			Action:    cmd.RunGitXargs,
+		},
+		{
+			Name:  "list",
+			Usage: "Print the full name of every selected repo, one per line, without touching any of them",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericGithubOrgFlag,
+				common.GenericSkipArchivedReposFlag,
+				common.GenericRepoFlag,
+				common.GenericRepoFileFlag,
+			},
+			Action: cmd.RunList,
+		},
+		{
+			Name:  "ready",
+			Usage: "Mark the draft pull requests opened from --branch-name as ready for review across all selected repos",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericGithubOrgFlag,
+				common.GenericSkipArchivedReposFlag,
+				common.GenericRepoFlag,
+				common.GenericRepoFileFlag,
+				common.GenericBranchFlag,
+				common.GenericRunIDFlag,
+				common.GenericOutputFlag,
+				common.GenericOutputFileFlag,
+				common.GenericReportCSVFlag,
+				common.GenericReportMarkdownFlag,
+				common.GenericReportJUnitFlag,
+				common.GenericReportHTMLFlag,
+				common.GenericWebhookURLFlag,
+				common.GenericWebhookIncludeEventsFlag,
+				common.GenericSlackWebhookURLFlag,
+				common.GenericSlackChannelFlag,
+				common.GenericEmailToFlag,
+				common.GenericEmailFromFlag,
+				common.GenericSMTPServerFlag,
+				common.GenericPushgatewayURLFlag,
+				common.GenericMetricsJobFlag,
+				common.GenericTelemetryEndpointFlag,
+				common.GenericOTLPEndpointFlag,
+				common.GenericCreateTrackingIssueFlag,
+				common.GenericReportGistFlag,
+				common.GenericReportUploadFlag,
+				common.GenericAllowedFailuresFlag,
+				common.GenericAllowedFailureRateFlag,
+				common.GenericMinSuccessRateFlag,
+			},
+			Action: cmd.RunReady,
+		},
+		{
+			Name:      "plan",
+			Usage:     "Run the command against all selected repos and record the resulting changes and pull requests in a plan file, without pushing anything",
+			ArgsUsage: "<command>",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericGithubOrgFlag,
+				common.GenericDraftPullRequestFlag,
+				common.GenericSkipArchivedReposFlag,
+				common.GenericRepoFlag,
+				common.GenericRepoFileFlag,
+				common.GenericBranchFlag,
+				common.GenericRunIDFlag,
+				common.GenericSkipRunMarkersFlag,
+				common.GenericBaseBranchFlag,
+				common.GenericCommitMessageFlag,
+				common.GenericPullRequestTitleFlag,
+				common.GenericPullRequestDescriptionFlag,
+				common.GenericPullRequestFooterFlag,
+				common.GenericMaxConcurrentReposFlag,
+				common.GenericPlanOutFlag,
+			},
+			Action: cmd.RunPlan,
+		},
+		{
+			Name:      "watch",
+			Usage:     "Run the command against all selected repos on a cron schedule, opening pull requests for the repos that have drifted",
+			ArgsUsage: "<command>",
+			Flags:     append([]cli.Flag{common.GenericScheduleFlag}, runFlags...),
+			Action:    cmd.RunWatch,
+		},
+		{
+			Name:      "continue",
+			Usage:     "Process the repos the run passed via --run-id queued because --max-open-prs pull requests were open",
+			ArgsUsage: "[<command>]",
+			Flags:     runFlags,
+			Action:    cmd.RunContinue,
+		},
+		{
+			Name:      "apply",
+			Usage:     "Push the changes and open the pull requests recorded in a plan file written by the plan subcommand",
+			ArgsUsage: "<plan-file>",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericStateFileFlag,
+				common.GenericSkipStateFlag,
+				common.GenericMaxConcurrentReposFlag,
+				common.GenericCommitStatusFlag,
+				common.GenericCommitStatusURLFlag,
+				common.GenericOutputFlag,
+				common.GenericOutputFileFlag,
+				common.GenericReportCSVFlag,
+				common.GenericReportMarkdownFlag,
+				common.GenericReportJUnitFlag,
+				common.GenericReportHTMLFlag,
+				common.GenericReportDiffLinesFlag,
+				common.GenericWebhookURLFlag,
+				common.GenericWebhookIncludeEventsFlag,
+				common.GenericSlackWebhookURLFlag,
+				common.GenericSlackChannelFlag,
+				common.GenericEmailToFlag,
+				common.GenericEmailFromFlag,
+				common.GenericSMTPServerFlag,
+				common.GenericPushgatewayURLFlag,
+				common.GenericMetricsJobFlag,
+				common.GenericTelemetryEndpointFlag,
+				common.GenericOTLPEndpointFlag,
+				common.GenericCreateTrackingIssueFlag,
+				common.GenericReportGistFlag,
+				common.GenericReportUploadFlag,
+				common.GenericAllowedFailuresFlag,
+				common.GenericAllowedFailureRateFlag,
+				common.GenericMinSuccessRateFlag,
+				common.GenericAuditLogDirFlag,
+				common.GenericAuditLogHashChainFlag,
+			},
+			Action: cmd.RunApply,
+		},
+		{
+			Name:  "serve",
+			Usage: "Serve a REST API for submitting runs, querying their status and cancelling them. Requires GIT_XARGS_SERVE_TOKEN",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericListenFlag,
+				common.GenericStateFileFlag,
+			},
+			Action: cmd.RunServe,
+		},
+		{
+			Name:  "report",
+			Usage: "Inspect the runs recorded in the state store",
+			Subcommands: []cli.Command{
+				{
+					Name:      "diff",
+					Usage:     "Compare the outcome of each repo in two runs, listing the repos that were fixed, regressed, or otherwise changed",
+					ArgsUsage: "<run-a> <run-b>",
+					Flags: []cli.Flag{
+						common.GenericConfigFileFlag,
+						common.GenericProfileFlag,
+						common.GenericGithubTokenEnvFlag,
+						common.GenericAPIHeaderFlag,
+						common.GenericStateFileFlag,
+					},
+					Action: cmd.RunReportDiff,
+				},
+			},
+		},
+		{
+			Name:  "audit",
+			Usage: "Inspect the audit logs written via --audit-log-dir",
+			Subcommands: []cli.Command{
+				{
+					Name:      "verify",
+					Usage:     "Check that the entries of an audit log are in sequence and, if it is hash-chained, that none of them was edited or removed. Pass --last-hash to also check how the log ends",
+					ArgsUsage: "<audit-log>",
+					Flags: []cli.Flag{
+						common.GenericAuditLastHashFlag,
+					},
+					Action: cmd.RunAuditVerify,
+				},
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "Print the current state, checks and review state of every pull request opened by the run passed via --run-id",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericRunIDFlag,
+				common.GenericManifestFlag,
+				common.GenericStateFileFlag,
+				common.GenericJiraURLFlag,
+				common.GenericJiraIssueFlag,
+				common.GenericJiraTransitionFlag,
+			},
+			Action: cmd.RunStatus,
+		},
+		{
+			Name:  "merge",
+			Usage: "Merge every pull request opened by the run passed via --run-id that is ready to merge",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericRunIDFlag,
+				common.GenericManifestFlag,
+				common.GenericStateFileFlag,
+				common.GenericMergeMethodFlag,
+				common.GenericDeleteBranchFlag,
+				common.GenericOutputFlag,
+				common.GenericOutputFileFlag,
+				common.GenericReportCSVFlag,
+				common.GenericReportMarkdownFlag,
+				common.GenericReportJUnitFlag,
+				common.GenericReportHTMLFlag,
+				common.GenericWebhookURLFlag,
+				common.GenericWebhookIncludeEventsFlag,
+				common.GenericSlackWebhookURLFlag,
+				common.GenericSlackChannelFlag,
+				common.GenericEmailToFlag,
+				common.GenericEmailFromFlag,
+				common.GenericSMTPServerFlag,
+				common.GenericPushgatewayURLFlag,
+				common.GenericMetricsJobFlag,
+				common.GenericTelemetryEndpointFlag,
+				common.GenericOTLPEndpointFlag,
+				common.GenericCreateTrackingIssueFlag,
+				common.GenericReportGistFlag,
+				common.GenericReportUploadFlag,
+				common.GenericAllowedFailuresFlag,
+				common.GenericAllowedFailureRateFlag,
+				common.GenericMinSuccessRateFlag,
+			},
+			Action: cmd.RunMerge,
+		},
+		{
+			Name:  "close",
+			Usage: "Close every open pull request opened by the run passed via --run-id, with a comment explaining why",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericRunIDFlag,
+				common.GenericManifestFlag,
+				common.GenericStateFileFlag,
+				common.GenericCloseCommentFlag,
+				common.GenericDeleteBranchFlag,
+				common.GenericOutputFlag,
+				common.GenericOutputFileFlag,
+				common.GenericReportCSVFlag,
+				common.GenericReportMarkdownFlag,
+				common.GenericReportJUnitFlag,
+				common.GenericReportHTMLFlag,
+				common.GenericWebhookURLFlag,
+				common.GenericWebhookIncludeEventsFlag,
+				common.GenericSlackWebhookURLFlag,
+				common.GenericSlackChannelFlag,
+				common.GenericEmailToFlag,
+				common.GenericEmailFromFlag,
+				common.GenericSMTPServerFlag,
+				common.GenericPushgatewayURLFlag,
+				common.GenericMetricsJobFlag,
+				common.GenericTelemetryEndpointFlag,
+				common.GenericOTLPEndpointFlag,
+				common.GenericCreateTrackingIssueFlag,
+				common.GenericReportGistFlag,
+				common.GenericReportUploadFlag,
+				common.GenericAllowedFailuresFlag,
+				common.GenericAllowedFailureRateFlag,
+				common.GenericMinSuccessRateFlag,
+			},
+			Action: cmd.RunClose,
+		},
+		{
+			Name:  "revert",
+			Usage: "Open a pull request reverting every merged pull request opened by the run passed via --run-id",
+			Flags: []cli.Flag{
+				common.GenericConfigFileFlag,
+				common.GenericProfileFlag,
+				common.GenericGithubTokenEnvFlag,
+				common.GenericAPIHeaderFlag,
+				common.GenericRunIDFlag,
+				common.GenericManifestFlag,
+				common.GenericStateFileFlag,
+				common.GenericBranchFlag,
+				common.GenericCommitMessageFlag,
+				common.GenericPullRequestTitleFlag,
+				common.GenericPullRequestDescriptionFlag,
+				common.GenericDryRunFlag,
+				common.GenericOutputFlag,
+				common.GenericOutputFileFlag,
+				common.GenericReportCSVFlag,
+				common.GenericReportMarkdownFlag,
+				common.GenericReportJUnitFlag,
+				common.GenericReportHTMLFlag,
+				common.GenericWebhookURLFlag,
+				common.GenericWebhookIncludeEventsFlag,
+				common.GenericSlackWebhookURLFlag,
+				common.GenericSlackChannelFlag,
+				common.GenericEmailToFlag,
+				common.GenericEmailFromFlag,
+				common.GenericSMTPServerFlag,
+				common.GenericPushgatewayURLFlag,
+				common.GenericMetricsJobFlag,
+				common.GenericTelemetryEndpointFlag,
+				common.GenericOTLPEndpointFlag,
+				common.GenericCreateTrackingIssueFlag,
+				common.GenericReportGistFlag,
+				common.GenericReportUploadFlag,
+				common.GenericAllowedFailuresFlag,
+				common.GenericAllowedFailureRateFlag,
+				common.GenericMinSuccessRateFlag,
+			},
+			Action: cmd.RunRevert,
+		},
+		{
+			Name:   "cleanup-branches",
+			Usage:  "Alias of clean branches",
+			Hidden: true,
+			Flags:  cleanupBranchesFlags,
+			Action: cmd.RunCleanupBranches,
+		},
+		{
+			Name:  "clean",
+			Usage: "Clean up after earlier runs",
+			Subcommands: []cli.Command{
+				{
+					Name:   "branches",
+					Usage:  "Delete the git-xargs branches in every selected repo whose pull requests have all been merged or closed",
+					Flags:  cleanupBranchesFlags,
+					Action: cmd.RunCleanupBranches,
+				},
+				{
+					Name:  "local",
+					Usage: "Remove the temporary clones left behind by interrupted runs, and the repo names cached for completion",
+					Flags: []cli.Flag{
+						common.GenericOlderThanFlag,
+						common.GenericDryRunFlag,
+					},
+					Action: cmd.RunCleanLocal,
+				},
+			},
+		},
+		{
+			Name:      "completion",
+			Usage:     "Print the completion script for bash, zsh or fish, which completes subcommands, flags and, for --repo, the repos of --github-org. E.g. source <(git-xargs completion bash)",
+			ArgsUsage: "<bash|zsh|fish>",
+			Action:    cmd.RunCompletion,
+		},
+		{
+			Name:      "doctor",
+			Usage:     "Check the GitHub token and its scopes, that the GitHub API is reachable, that git is installed, that there is disk space for the clones and that the flags are consistent, without touching any repo. Pass the flags and command of the run to check",
+			ArgsUsage: "<command>",
+			Flags:     runFlags,
+			Action:    cmd.RunDoctor,
+		},
+		{
+			Name:  "self-update",
+			Usage: "Replace this git-xargs binary with the one from the latest GitHub release, after verifying it against the checksums published with the release",
+			Flags: []cli.Flag{
+				common.GenericCheckOnlyFlag,
+				common.GenericForceFlag,
+			},
+			Action: cmd.RunSelfUpdate,
+		},
+	}
+
+	// Complete the value of --repo with the repos of --github-org, and everything else the way urfave cli does by default
+	app.BashComplete = cmd.CompleteWithRepos(nil)
+	for i := range app.Commands {
+		app.Commands[i].BashComplete = cmd.CompleteWithRepos(&app.Commands[i])
+	}
+
 	return app
 }
 
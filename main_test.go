@@ -2,11 +2,16 @@ package main
 
 import (
 	"flag"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/gruntwork-io/git-xargs/cmd"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli"
 )
 
@@ -15,6 +20,73 @@ func TestSetupApp(t *testing.T) {
 	assert.NotNil(t, app)
 }
 
+// Test that every flag can also be set via a GITXARGS_* environment variable named after it, so that CI systems can
+// configure runs via the environment, and that it takes precedence over the GIT_XARGS_* variable still read for it
+func TestEveryFlagHasAnEnvVar(t *testing.T) {
+	app := setupApp()
+
+	var checkFlags func(flags []cli.Flag)
+	checkFlags = func(flags []cli.Flag) {
+		for _, flag := range flags {
+			name := strings.Split(flag.GetName(), ",")[0]
+			envVar := reflect.ValueOf(flag).FieldByName("EnvVar").String()
+			suffix := strings.ToUpper(strings.Replace(name, "-", "_", -1))
+			envVars := strings.Split(envVar, ", ")
+			if assert.NotEmpty(t, envVars, "Flag --%s", name) {
+				assert.Equal(t, "GITXARGS_"+suffix, envVars[0], "Flag --%s", name)
+			}
+			assert.Contains(t, envVars, "GIT_XARGS_"+suffix, "Flag --%s", name)
+		}
+	}
+	var checkCommands func(commands []cli.Command)
+	checkCommands = func(commands []cli.Command) {
+		for _, command := range commands {
+			checkFlags(command.Flags)
+			checkCommands(command.Subcommands)
+		}
+	}
+
+	checkFlags(app.Flags)
+	checkCommands(app.Commands)
+}
+
+func TestConsoleLogLevel(t *testing.T) {
+	// Run an app, rather than parse the flags directly, so that urfave cli copies the value of --loglevel to --log-level
+	consoleLogLevelFor := func(args ...string) (level logrus.Level, err error) {
+		app := cli.NewApp()
+		app.Flags = []cli.Flag{LogLevelFlag, QuietFlag}
+		app.Action = func(c *cli.Context) error {
+			level, err = consoleLogLevel(c)
+			return nil
+		}
+		require.NoError(t, app.Run(append([]string{"git-xargs"}, args...)))
+		return level, err
+	}
+
+	level, err := consoleLogLevelFor()
+	require.NoError(t, err)
+	assert.Equal(t, logrus.InfoLevel, level)
+
+	level, err = consoleLogLevelFor("--log-level", "debug")
+	require.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, level)
+
+	// The old spelling of the flag still works
+	level, err = consoleLogLevelFor("--loglevel", "warning")
+	require.NoError(t, err)
+	assert.Equal(t, logrus.WarnLevel, level)
+
+	level, err = consoleLogLevelFor("--quiet")
+	require.NoError(t, err)
+	assert.Equal(t, logrus.ErrorLevel, level)
+
+	_, err = consoleLogLevelFor("--quiet", "--log-level", "debug")
+	assert.IsType(t, types.QuietWithLogLevelErr{}, errors.Unwrap(err))
+
+	_, err = consoleLogLevelFor("--log-level", "chatty")
+	assert.IsType(t, types.InvalidLogLevelErr{}, errors.Unwrap(err))
+}
+
 func TestGitXargsShowsHelpTextForEmptyArgs(t *testing.T) {
 	app := setupApp()
 
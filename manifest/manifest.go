@@ -0,0 +1,170 @@
+// Package manifest reads and writes the run manifest written via --output-manifest: a canonical, machine-readable
+// record of a run, with a snapshot of its config and the branch, commit, pull request and outcome of every repo, for
+// downstream automation to consume. The subcommands that act on an earlier run accept it via --manifest in place of
+// --run-id, e.g. to merge the pull requests of a run made on a CI runner whose state store is gone.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// FormatVersion is the version of the manifest format. It is bumped whenever a change to the format would break the
+// consumers of earlier manifests, which should check it
+const FormatVersion = 1
+
+// Manifest is the record of a single run
+type Manifest struct {
+	FormatVersion   int       `json:"format_version"`
+	RunID           string    `json:"run_id"`
+	GitXargsVersion string    `json:"git_xargs_version"`
+	Command         []string  `json:"command"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at"`
+	Config          Config    `json:"config"`
+	Repos           []Repo    `json:"repos"`
+}
+
+// Config is the snapshot of the options of the run that shape what it changed. Secrets, such as tokens, are left out
+type Config struct {
+	BranchName             string   `json:"branch_name"`
+	BaseBranchName         string   `json:"base_branch_name,omitempty"`
+	CommitMessage          string   `json:"commit_message"`
+	PullRequestTitle       string   `json:"pull_request_title"`
+	PullRequestDescription string   `json:"pull_request_description"`
+	GithubOrg              string   `json:"github_org,omitempty"`
+	ReposFile              string   `json:"repos_file,omitempty"`
+	Repos                  []string `json:"repos,omitempty"`
+	Draft                  bool     `json:"draft"`
+	DryRun                 bool     `json:"dry_run"`
+	DryRunLevel            string   `json:"dry_run_level,omitempty"`
+	SkipPullRequests       bool     `json:"skip_pull_requests"`
+	SkipArchivedRepos      bool     `json:"skip_archived_repos"`
+	SkipReposWithOpenPRs   bool     `json:"skip_repos_with_open_pull_requests"`
+	PushViaAPI             bool     `json:"push_via_api"`
+	ApproveAndMerge        bool     `json:"approve_and_merge"`
+	MergeMethod            string   `json:"merge_method,omitempty"`
+	Reviewers              []string `json:"reviewers,omitempty"`
+	Assignees              []string `json:"assignees,omitempty"`
+	Tags                   []string `json:"tags,omitempty"`
+	MaxConcurrentRepos     int      `json:"max_concurrent_repos"`
+}
+
+// Repo is what happened to a single repo during the run
+type Repo struct {
+	// Name is the full name of the repo, e.g. gruntwork-io/git-xargs
+	Name       string               `json:"name"`
+	URL        string               `json:"url,omitempty"`
+	Outcome    string               `json:"outcome"`
+	Error      string               `json:"error,omitempty"`
+	SkipReason string               `json:"skip_reason,omitempty"`
+	Events     []types.Event        `json:"events"`
+	Branches   []types.PushedBranch `json:"branches"`
+}
+
+// Write writes the supplied manifest to the supplied writer as indented JSON
+func Write(w io.Writer, manifest *Manifest) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.WithStackTrace(encoder.Encode(manifest))
+}
+
+// Load reads the manifest at the supplied path, rejecting manifests of a format version this binary doesn't know
+func Load(path string) (*Manifest, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(contents, manifest); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidManifestErr{File: path, Err: err})
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, errors.WithStackTrace(types.InvalidManifestErr{File: path, Err: fmt.Errorf("its format version %d is not %d", manifest.FormatVersion, FormatVersion)})
+	}
+	if manifest.RunID == "" {
+		return nil, errors.WithStackTrace(types.InvalidManifestErr{File: path, Err: fmt.Errorf("it has no run ID")})
+	}
+	for _, repo := range manifest.Repos {
+		if len(strings.Split(repo.Name, "/")) != 2 {
+			return nil, errors.WithStackTrace(types.InvalidManifestErr{File: path, Err: fmt.Errorf("%q is not in the format of <org>/<repo>", repo.Name)})
+		}
+	}
+	return manifest, nil
+}
+
+// Import records the run of the supplied manifest in the supplied state store, so that the subcommands that look runs
+// up by ID can act on it. Runs the store already knows are left alone, since the store's own record of them is at least
+// as complete as the manifest
+func Import(store *state.Store, manifest *Manifest) error {
+	if store == nil {
+		return nil
+	}
+	if _, err := store.GetRun(manifest.RunID); err == nil {
+		return nil
+	}
+
+	err := store.StartRun(state.Run{
+		ID:             manifest.RunID,
+		Command:        manifest.Command,
+		BranchName:     manifest.Config.BranchName,
+		BaseBranchName: manifest.Config.BaseBranchName,
+		StartedAt:      manifest.StartedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	events := map[types.Event][]*github.Repository{}
+	for _, repo := range manifest.Repos {
+		parts := strings.Split(repo.Name, "/")
+		githubRepo := &github.Repository{Owner: &github.User{Login: github.String(parts[0])}, Name: github.String(parts[1])}
+
+		var processErr error
+		if repo.Error != "" {
+			processErr = fmt.Errorf("%s", repo.Error)
+		}
+		if err := store.RecordOutcome(manifest.RunID, githubRepo, processErr); err != nil {
+			return err
+		}
+
+		for _, branch := range repo.Branches {
+			checkpoint := state.CheckpointPushed
+			if branch.PullRequestURL != "" {
+				checkpoint = state.CheckpointPullRequestOpened
+				err := store.RecordPullRequest(manifest.RunID, githubRepo, state.PullRequest{
+					Number:   branch.PullRequestNumber,
+					URL:      branch.PullRequestURL,
+					Branch:   branch.Name,
+					Draft:    branch.Draft,
+					OpenedAt: manifest.FinishedAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			if err := store.RecordCheckpoint(manifest.RunID, githubRepo, checkpoint); err != nil {
+				return err
+			}
+		}
+
+		for _, event := range repo.Events {
+			events[event] = append(events[event], githubRepo)
+		}
+	}
+	if err := store.RecordEvents(manifest.RunID, events); err != nil {
+		return err
+	}
+
+	return store.FinishRun(manifest.RunID, manifest.FinishedAt)
+}
@@ -0,0 +1,110 @@
+package manifest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManifest() *Manifest {
+	return &Manifest{
+		FormatVersion: FormatVersion,
+		RunID:         "run-1",
+		Command:       []string{"touch", "file"},
+		StartedAt:     time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		FinishedAt:    time.Date(2021, 6, 1, 10, 5, 0, 0, time.UTC),
+		Config:        Config{BranchName: "update-ci", CommitMessage: "Update CI"},
+		Repos: []Repo{
+			{
+				Name:    "gruntwork-io/terragrunt",
+				Outcome: "succeeded",
+				Events:  []types.Event{"pull-request-opened"},
+				Branches: []types.PushedBranch{{
+					Name:              "update-ci",
+					CommitSHA:         "5555555555555555555555555555555555555555",
+					PullRequestURL:    "https://github.com/gruntwork-io/terragrunt/pull/1",
+					PullRequestNumber: 1,
+				}},
+			},
+			{Name: "gruntwork-io/fetch", Outcome: "failed", Error: "exit status 1", Branches: []types.PushedBranch{}},
+		},
+	}
+}
+
+// writeTestManifest writes the supplied manifest to a temporary directory, returning its path
+func writeTestManifest(t *testing.T, dir string, runManifest *Manifest) string {
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, runManifest))
+	path := filepath.Join(dir, "manifest.json")
+	require.NoError(t, ioutil.WriteFile(path, buffer.Bytes(), 0644))
+	return path
+}
+
+// Test that a written manifest loads back as is, and that manifests of another format version are rejected
+func TestWriteAndLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	runManifest := newTestManifest()
+	loaded, err := Load(writeTestManifest(t, dir, runManifest))
+	require.NoError(t, err)
+	assert.Equal(t, runManifest, loaded)
+
+	runManifest.FormatVersion = FormatVersion + 1
+	_, err = Load(writeTestManifest(t, dir, runManifest))
+	assert.Error(t, err)
+
+	runManifest = newTestManifest()
+	runManifest.Repos[0].Name = "terragrunt"
+	_, err = Load(writeTestManifest(t, dir, runManifest))
+	assert.Error(t, err)
+}
+
+// Test that importing a manifest records its run, repos and pull requests in the state store, and leaves runs the store
+// already knows alone
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := state.Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, Import(store, newTestManifest()))
+
+	run, err := store.GetRun("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "update-ci", run.BranchName)
+
+	repos, err := store.ListRepos("run-1")
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "gruntwork-io/fetch", repos[0].FullName())
+	assert.Equal(t, state.OutcomeFailed, repos[0].Outcome)
+	assert.Equal(t, state.OutcomeSucceeded, repos[1].Outcome)
+	assert.Equal(t, state.CheckpointPullRequestOpened, repos[1].Checkpoint)
+	require.Len(t, repos[1].PullRequests, 1)
+	assert.Equal(t, 1, repos[1].PullRequests[0].Number)
+
+	// A second import of a different manifest for the same run changes nothing
+	changed := newTestManifest()
+	changed.Config.BranchName = "other"
+	require.NoError(t, Import(store, changed))
+	run, err = store.GetRun("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, "update-ci", run.BranchName)
+}
@@ -2,10 +2,15 @@ package mocks
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v32/github"
 	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/types"
 )
 
 // Mock *github.Repository slice that is returned from the mock Repositories service in test
@@ -57,8 +62,10 @@ var MockGithubRepositories = []*github.Repository{
 
 // This mocks the PullRequest service in go-github that is used in production to call the associated GitHub endpoint
 type mockGithubPullRequestService struct {
-	PullRequest *github.PullRequest
-	Response    *github.Response
+	PullRequest        *github.PullRequest
+	RequestedReviewers *github.Reviewers
+	Reviews            []*github.PullRequestReview
+	Response           *github.Response
 }
 
 func (m mockGithubPullRequestService) Create(ctx context.Context, owner, name string, pr *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
@@ -69,11 +76,217 @@ func (m mockGithubPullRequestService) List(ctx context.Context, owner string, re
 	return []*github.PullRequest{m.PullRequest}, m.Response, nil
 }
 
+func (m mockGithubPullRequestService) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.PullRequest, *github.Response, error) {
+	return m.PullRequest, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) ListReviewers(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) (*github.Reviewers, *github.Response, error) {
+	if m.RequestedReviewers == nil {
+		return &github.Reviewers{}, m.Response, nil
+	}
+	return m.RequestedReviewers, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) RemoveReviewers(ctx context.Context, owner, repo string, number int, reviewers github.ReviewersRequest) (*github.Response, error) {
+	return m.Response, nil
+}
+
+func (m mockGithubPullRequestService) Get(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return m.PullRequest, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) Edit(ctx context.Context, owner string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error) {
+	return pull, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) ListReviews(ctx context.Context, owner, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return m.Reviews, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) CreateReview(ctx context.Context, owner, repo string, number int, review *github.PullRequestReviewRequest) (*github.PullRequestReview, *github.Response, error) {
+	return &github.PullRequestReview{State: github.String("APPROVED")}, m.Response, nil
+}
+
+func (m mockGithubPullRequestService) Merge(ctx context.Context, owner string, repo string, number int, commitMessage string, options *github.PullRequestOptions) (*github.PullRequestMergeResult, *github.Response, error) {
+	return &github.PullRequestMergeResult{Merged: github.Bool(true)}, m.Response, nil
+}
+
+// This mocks the Checks service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubChecksService struct {
+	CheckRuns []*github.CheckRun
+	Response  *github.Response
+}
+
+func (m mockGithubChecksService) ListCheckRunsForRef(ctx context.Context, owner, repo, ref string, opts *github.ListCheckRunsOptions) (*github.ListCheckRunsResults, *github.Response, error) {
+	return &github.ListCheckRunsResults{Total: github.Int(len(m.CheckRuns)), CheckRuns: m.CheckRuns}, m.Response, nil
+}
+
+// This mocks the Git service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubGitService struct {
+	Refs     map[string]string
+	Commits  *[]*github.Commit
+	Response *github.Response
+}
+
+func (m mockGithubGitService) DeleteRef(ctx context.Context, owner string, repo string, ref string) (*github.Response, error) {
+	delete(m.Refs, ref)
+	return m.Response, nil
+}
+
+func (m mockGithubGitService) GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error) {
+	sha, ok := m.Refs[ref]
+	if !ok {
+		return nil, notFoundResponse(), &github.ErrorResponse{Response: notFoundResponse().Response, Message: "Not Found"}
+	}
+	return &github.Reference{Ref: github.String("refs/" + ref), Object: &github.GitObject{SHA: github.String(sha)}}, m.Response, nil
+}
+
+func (m mockGithubGitService) CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error) {
+	m.Refs[strings.TrimPrefix(ref.GetRef(), "refs/")] = ref.GetObject().GetSHA()
+	return ref, m.Response, nil
+}
+
+func (m mockGithubGitService) UpdateRef(ctx context.Context, owner string, repo string, ref *github.Reference, force bool) (*github.Reference, *github.Response, error) {
+	return m.CreateRef(ctx, owner, repo, ref)
+}
+
+func (m mockGithubGitService) CreateBlob(ctx context.Context, owner string, repo string, blob *github.Blob) (*github.Blob, *github.Response, error) {
+	return &github.Blob{SHA: github.String(fmt.Sprintf("%040x", len(blob.GetContent())))}, m.Response, nil
+}
+
+func (m mockGithubGitService) CreateTree(ctx context.Context, owner string, repo string, baseTree string, entries []*github.TreeEntry) (*github.Tree, *github.Response, error) {
+	return &github.Tree{SHA: github.String("6666666666666666666666666666666666666666"), Entries: entries}, m.Response, nil
+}
+
+func (m mockGithubGitService) CreateCommit(ctx context.Context, owner string, repo string, commit *github.Commit) (*github.Commit, *github.Response, error) {
+	created := &github.Commit{SHA: github.String(fmt.Sprintf("5%039x", len(*m.Commits)+1)), Message: commit.Message, Tree: commit.Tree, Parents: commit.Parents}
+	*m.Commits = append(*m.Commits, created)
+	return created, m.Response, nil
+}
+
+// notFoundResponse returns the response of the GitHub API to a request for something that doesn't exist
+func notFoundResponse() *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+}
+
+// This mocks the Issues service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubIssuesService struct {
+	Issue    *github.Issue
+	Labels   *[]string
+	Response *github.Response
+}
+
+func (m mockGithubIssuesService) AddAssignees(ctx context.Context, owner, repo string, number int, assignees []string) (*github.Issue, *github.Response, error) {
+	return m.Issue, m.Response, nil
+}
+
+func (m mockGithubIssuesService) CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	return comment, m.Response, nil
+}
+
+func (m mockGithubIssuesService) AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
+	if m.Labels != nil {
+		*m.Labels = append(*m.Labels, labels...)
+	}
+	return []*github.Label{}, m.Response, nil
+}
+
+func (m mockGithubIssuesService) Create(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return &github.Issue{
+		Number:  github.Int(1),
+		Title:   issue.Title,
+		Body:    issue.Body,
+		HTMLURL: github.String(fmt.Sprintf("https://github.com/%s/%s/issues/1", owner, repo)),
+	}, m.Response, nil
+}
+
+// This mocks the Gists service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubGistsService struct {
+	Response *github.Response
+}
+
+func (m mockGithubGistsService) Create(ctx context.Context, gist *github.Gist) (*github.Gist, *github.Response, error) {
+	created := *gist
+	created.HTMLURL = github.String("https://gist.github.com/gruntwork-io/0123456789abcdef")
+	return &created, m.Response, nil
+}
+
+// This mocks the rate limit endpoint in go-github that is used in production to call the associated GitHub endpoint
+type MockGithubRateLimitsService struct {
+	Limits *github.RateLimits
+	Err    error
+}
+
+func (m MockGithubRateLimitsService) RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error) {
+	return m.Limits, &github.Response{}, m.Err
+}
+
+// This mocks the meta endpoint that is used in production to read the version of a GitHub Enterprise Server
+type MockGithubMetaService struct {
+	Version string
+	Err     error
+}
+
+func (m MockGithubMetaService) InstalledVersion(ctx context.Context) (string, *github.Response, error) {
+	return m.Version, &github.Response{}, m.Err
+}
+
+// This mocks the branch rules endpoint that is used in production to read the rulesets that apply to a branch
+type MockGithubRulesService struct {
+	Rules []*types.BranchRule
+	Err   error
+}
+
+func (m MockGithubRulesService) GetRulesForBranch(ctx context.Context, owner string, repo string, branch string) ([]*types.BranchRule, *github.Response, error) {
+	return m.Rules, &github.Response{}, m.Err
+}
+
+// This mocks the Users service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubUsersService struct {
+	User     *github.User
+	Response *github.Response
+}
+
+func (m mockGithubUsersService) Get(ctx context.Context, user string) (*github.User, *github.Response, error) {
+	return m.User, m.Response, nil
+}
+
+// This mocks the Teams service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubTeamsService struct {
+	Members  []*github.User
+	Response *github.Response
+}
+
+func (m mockGithubTeamsService) ListTeamMembersBySlug(ctx context.Context, org, slug string, opts *github.TeamListTeamMembersOptions) ([]*github.User, *github.Response, error) {
+	return m.Members, m.Response, nil
+}
+
+// This mocks the Search service in go-github that is used in production to call the associated GitHub endpoint
+type mockGithubSearchService struct {
+	IssuesSearchResult *github.IssuesSearchResult
+	Response           *github.Response
+}
+
+func (m mockGithubSearchService) Issues(ctx context.Context, query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	return m.IssuesSearchResult, m.Response, nil
+}
+
 // This mocks the Repositories service in go-github that is used in production to call the associated GitHub endpoint
 type mockGithubRepositoriesService struct {
-	Repository   *github.Repository
-	Repositories []*github.Repository
-	Response     *github.Response
+	Repository     *github.Repository
+	Repositories   []*github.Repository
+	CombinedStatus *github.CombinedStatus
+	Commit         *github.RepositoryCommit
+	CommitsBySHA   map[string]*github.RepositoryCommit
+	Branches       []*github.Branch
+	Contents       map[string]string
+	Protection     *github.Protection
+	SignedCommits  bool
+	Response       *github.Response
+}
+
+func (m mockGithubRepositoriesService) ListBranches(ctx context.Context, owner string, repo string, opts *github.BranchListOptions) ([]*github.Branch, *github.Response, error) {
+	return m.Branches, m.Response, nil
 }
 
 func (m mockGithubRepositoriesService) Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error) {
@@ -84,8 +297,138 @@ func (m mockGithubRepositoriesService) ListByOrg(ctx context.Context, org string
 	return m.Repositories, m.Response, nil
 }
 
+func (m mockGithubRepositoriesService) CreateStatus(ctx context.Context, owner, repo, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return status, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) GetCommit(ctx context.Context, owner, repo, sha string) (*github.RepositoryCommit, *github.Response, error) {
+	if m.CommitsBySHA == nil {
+		return m.Commit, m.Response, nil
+	}
+	commit, ok := m.CommitsBySHA[sha]
+	if !ok {
+		return nil, notFoundResponse(), &github.ErrorResponse{Response: notFoundResponse().Response, Message: "No commit found for SHA: " + sha}
+	}
+	return commit, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) GetContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+	content, ok := m.Contents[path]
+	if !ok {
+		return nil, nil, notFoundResponse(), &github.ErrorResponse{Response: notFoundResponse().Response, Message: "Not Found"}
+	}
+	return &github.RepositoryContent{Path: github.String(path), Content: github.String(content), SHA: github.String(fmt.Sprintf("%x", len(content)))}, nil, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	m.Contents[path] = string(opts.Content)
+	return &github.RepositoryContentResponse{Commit: github.Commit{SHA: github.String("3333333333333333333333333333333333333333")}}, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) UpdateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	return m.CreateFile(ctx, owner, repo, path, opts)
+}
+
+func (m mockGithubRepositoriesService) DeleteFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	delete(m.Contents, path)
+	return &github.RepositoryContentResponse{Commit: github.Commit{SHA: github.String("4444444444444444444444444444444444444444")}}, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) GetBranchProtection(ctx context.Context, owner, repo, branch string) (*github.Protection, *github.Response, error) {
+	if m.Protection == nil {
+		return nil, notFoundResponse(), &github.ErrorResponse{Response: notFoundResponse().Response, Message: "Branch not protected"}
+	}
+	return m.Protection, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) GetSignaturesProtectedBranch(ctx context.Context, owner, repo, branch string) (*github.SignaturesProtectedBranch, *github.Response, error) {
+	if m.Protection == nil {
+		return nil, notFoundResponse(), &github.ErrorResponse{Response: notFoundResponse().Response, Message: "Branch not protected"}
+	}
+	return &github.SignaturesProtectedBranch{Enabled: github.Bool(m.SignedCommits)}, m.Response, nil
+}
+
+func (m mockGithubRepositoriesService) GetCombinedStatus(ctx context.Context, owner, repo, ref string, opts *github.ListOptions) (*github.CombinedStatus, *github.Response, error) {
+	return m.CombinedStatus, m.Response, nil
+}
+
+// This mocks the GraphQL service that is used in production to call the GitHub GraphQL API. It records the variables
+// of every request it receives so that tests can assert against them, and unmarshals the static Response JSON, if any,
+// into the result of every request
+type MockGithubGraphQLService struct {
+	Requests *[]map[string]interface{}
+	Response string
+}
+
+func (m MockGithubGraphQLService) Do(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	if m.Requests != nil {
+		*m.Requests = append(*m.Requests, variables)
+	}
+	if m.Response == "" || result == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(m.Response), result)
+}
+
 // ConfigureMockGithubClient returns a valid GithubClient configured for testing purposes, complete with the mocked services
 func ConfigureMockGithubClient() auth.GithubClient {
+	return ConfigureMockGithubClientWithContents(map[string]string{})
+}
+
+// ConfigureMockGithubClientWithContents returns a mock GithubClient whose repositories hold the given files, keyed by
+// path. Files written or deleted through the Contents API are reflected in the map, so tests can inspect it afterwards
+func ConfigureMockGithubClientWithContents(contents map[string]string) auth.GithubClient {
+	return configureMockGithubClient(contents, nil, false)
+}
+
+// ConfigureMockGithubClientWithBranchProtection returns a mock GitHub client whose repos protect their branches with the
+// supplied protection rules, and require signed commits if requireSignedCommits is set
+func ConfigureMockGithubClientWithBranchProtection(protection *github.Protection, requireSignedCommits bool) auth.GithubClient {
+	return configureMockGithubClient(map[string]string{}, protection, requireSignedCommits)
+}
+
+// ConfigureMockGithubClientWithCommits returns a mock GitHub client that appends every commit created via the Git Data
+// API to the supplied slice, so tests can inspect them afterwards
+func ConfigureMockGithubClientWithCommits(commits *[]*github.Commit) auth.GithubClient {
+	client := ConfigureMockGithubClient()
+	git := client.Git.(mockGithubGitService)
+	git.Commits = commits
+	client.Git = git
+	return client
+}
+
+// ConfigureMockGithubClientWithLabels returns a mock GitHub client that appends every label added to an issue or pull
+// request to the supplied slice, so tests can inspect them afterwards
+func ConfigureMockGithubClientWithLabels(labels *[]string) auth.GithubClient {
+	client := ConfigureMockGithubClient()
+	issues := client.Issues.(mockGithubIssuesService)
+	issues.Labels = labels
+	client.Issues = issues
+	return client
+}
+
+// ConfigureMockGithubClientWithRepositoryCommits returns a mock GitHub client that only knows of the supplied commits,
+// keyed by SHA. Looking up any other commit fails with a 404
+func ConfigureMockGithubClientWithRepositoryCommits(commits map[string]*github.RepositoryCommit) auth.GithubClient {
+	client := ConfigureMockGithubClient()
+	repositories := client.Repositories.(mockGithubRepositoriesService)
+	repositories.CommitsBySHA = commits
+	client.Repositories = repositories
+	return client
+}
+
+// ConfigureMockGithubClientWithChecks returns a mock GitHub client whose commits have the supplied combined status and
+// check runs
+func ConfigureMockGithubClientWithChecks(combinedStatus *github.CombinedStatus, checkRuns []*github.CheckRun) auth.GithubClient {
+	client := ConfigureMockGithubClient()
+	repositories := client.Repositories.(mockGithubRepositoriesService)
+	repositories.CombinedStatus = combinedStatus
+	client.Repositories = repositories
+	client.Checks = mockGithubChecksService{CheckRuns: checkRuns, Response: &github.Response{}}
+	return client
+}
+
+func configureMockGithubClient(contents map[string]string, protection *github.Protection, requireSignedCommits bool) auth.GithubClient {
 	// Call the same NewClient method that is used by the actual CLI to obtain a GitHub client that calls the
 	// GitHub API. In testing, however, we just implement the mock services above to satisfy the interfaces required
 	// by the GithubClient. GithubClient is used uniformly between production and test code, with the only difference
@@ -97,6 +440,24 @@ func ConfigureMockGithubClient() auth.GithubClient {
 	client.Repositories = mockGithubRepositoriesService{
 		Repository:   MockGithubRepositories[0],
 		Repositories: MockGithubRepositories,
+		CombinedStatus: &github.CombinedStatus{
+			State: github.String("success"),
+		},
+		Commit: &github.RepositoryCommit{
+			Author: &github.User{
+				Login: github.String("carol"),
+			},
+			Commit: &github.Commit{
+				Message: github.String("Update CI\n\nGit-Xargs-Run-Id: run-1"),
+			},
+		},
+		Branches: []*github.Branch{
+			{Name: github.String("master"), Commit: &github.RepositoryCommit{SHA: github.String("1111111111111111111111111111111111111111")}},
+			{Name: github.String("update-ci"), Commit: &github.RepositoryCommit{SHA: github.String("2222222222222222222222222222222222222222")}},
+		},
+		Contents:      contents,
+		Protection:    protection,
+		SignedCommits: requireSignedCommits,
 		Response: &github.Response{
 
 			Response: &http.Response{
@@ -116,9 +477,64 @@ func ConfigureMockGithubClient() auth.GithubClient {
 	client.PullRequests = mockGithubPullRequestService{
 		PullRequest: &github.PullRequest{
 			HTMLURL: &testHTMLUrl,
+			State:   github.String("open"),
+			Head: &github.PullRequestBranch{
+				SHA: github.String("0123456789abcdef0123456789abcdef01234567"),
+			},
+		},
+		Reviews: []*github.PullRequestReview{
+			{User: &github.User{Login: github.String("alice")}, State: github.String("APPROVED")},
+		},
+		Response: &github.Response{},
+	}
+	client.Checks = mockGithubChecksService{
+		CheckRuns: []*github.CheckRun{
+			{Name: github.String("build"), Status: github.String("completed"), Conclusion: github.String("success")},
 		},
 		Response: &github.Response{},
 	}
+	client.Search = mockGithubSearchService{
+		IssuesSearchResult: &github.IssuesSearchResult{
+			Total: github.Int(0),
+		},
+		Response: &github.Response{},
+	}
+	client.Issues = mockGithubIssuesService{
+		Issue:    &github.Issue{},
+		Response: &github.Response{},
+	}
+	client.Teams = mockGithubTeamsService{
+		Members: []*github.User{
+			{Login: github.String("alice")},
+			{Login: github.String("bob")},
+		},
+		Response: &github.Response{},
+	}
+	client.Git = mockGithubGitService{
+		Refs:     map[string]string{"heads/master": "1111111111111111111111111111111111111111"},
+		Commits:  &[]*github.Commit{},
+		Response: &github.Response{},
+	}
+	client.Gists = mockGithubGistsService{
+		Response: &github.Response{},
+	}
+	client.Users = mockGithubUsersService{
+		User: &github.User{Login: github.String("git-xargs-bot")},
+		Response: &github.Response{
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"X-Oauth-Scopes": []string{"repo, workflow"}},
+			},
+		},
+	}
+	client.GraphQL = MockGithubGraphQLService{}
+	client.RateLimits = MockGithubRateLimitsService{
+		Limits: &github.RateLimits{
+			Core: &github.Rate{Limit: 5000, Remaining: 5000, Reset: github.Timestamp{Time: time.Now().Add(time.Hour)}},
+		},
+	}
+	client.Meta = MockGithubMetaService{}
+	client.Rules = MockGithubRulesService{}
 
 	return client
 }
@@ -140,3 +556,15 @@ func GetMockGithubRepo() *github.Repository {
 
 	return repo
 }
+
+func GetMockPullRequest() *github.PullRequest {
+	number := 1
+	htmlURL := "https://github.com/gruntwork-io/terragrunt/pull/1"
+	nodeID := "PR_1"
+
+	return &github.PullRequest{
+		Number:  &number,
+		HTMLURL: &htmlURL,
+		NodeID:  &nodeID,
+	}
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// smtpTimeout bounds how long sending an email through the SMTP server may take
+const smtpTimeout = 30 * time.Second
+
+// SendEmail sends an email with the supplied subject and plain text body from the supplied address to the supplied
+// addresses, through the SMTP server at the supplied <host>:<port>. The connection is upgraded with STARTTLS if the
+// server supports it, or uses TLS from the start on port 465. If SMTP_USERNAME is exported, it authenticates with it
+// and SMTP_PASSWORD
+func SendEmail(server string, from string, to []string, subject string, body string) error {
+	message, err := buildEmailMessage(from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	return sendEmail(server, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from, to, message)
+}
+
+func sendEmail(server string, username string, password string, from string, to []string, message []byte) error {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	var conn net.Conn
+	if port == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", server, &tls.Config{ServerName: host})
+	} else {
+		conn, err = dialer.Dial("tcp", server)
+	}
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return errors.WithStackTrace(err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	if username != "" {
+		if err := client.Auth(smtp.PlainAuth("", username, password, host)); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+
+	envelopeFrom, err := envelopeAddress(from)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(envelopeFrom); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	for _, recipient := range to {
+		envelopeTo, err := envelopeAddress(recipient)
+		if err != nil {
+			return err
+		}
+		if err := client.Rcpt(envelopeTo); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if err := writer.Close(); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(client.Quit())
+}
+
+// buildEmailMessage returns the supplied email as a MIME message, with its body encoded as quoted-printable, so that
+// long lines and non-ASCII characters get through every mail server
+func buildEmailMessage(from string, to []string, subject string, body string, date time.Time) ([]byte, error) {
+	var encodedBody bytes.Buffer
+	writer := quotedprintable.NewWriter(&encodedBody)
+	if _, err := writer.Write([]byte(strings.Replace(body, "\n", "\r\n", -1))); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var message bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+	message.WriteString("\r\n")
+	message.Write(encodedBody.Bytes())
+	return message.Bytes(), nil
+}
+
+// envelopeAddress returns the bare address of the supplied address, which may include a display name, e.g.
+// "Platform Team <platform@example.com>", as the SMTP envelope expects
+func envelopeAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return parsed.Address, nil
+}
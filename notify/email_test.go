@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveFakeSMTP accepts a single SMTP session on the supplied listener, without STARTTLS or authentication, and sends
+// the envelope recipients and the message it received to the returned channel
+func serveFakeSMTP(listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		lines := []string{}
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				text.PrintfLine("250 localhost")
+			case strings.HasPrefix(line, "RCPT TO:"):
+				lines = append(lines, line)
+				text.PrintfLine("250 OK")
+			case line == "DATA":
+				text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotLines()
+				if err != nil {
+					return
+				}
+				lines = append(lines, data...)
+				text.PrintfLine("250 OK")
+			case line == "QUIT":
+				text.PrintfLine("221 Bye")
+				received <- lines
+				return
+			default:
+				text.PrintfLine("250 OK")
+			}
+		}
+	}()
+	return received
+}
+
+func TestSendEmail(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := serveFakeSMTP(listener)
+
+	message, err := buildEmailMessage("Platform Team <platform@example.com>", []string{"owner@example.com", "lead@example.com"}, "git-xargs run run-1: 1 succeeded", "1 repos processed.\n", time.Now())
+	require.NoError(t, err)
+	require.NoError(t, sendEmail(listener.Addr().String(), "", "", "Platform Team <platform@example.com>", []string{"owner@example.com", "lead@example.com"}, message))
+
+	lines := <-received
+	assert.Equal(t, "RCPT TO:<owner@example.com>", lines[0])
+	assert.Equal(t, "RCPT TO:<lead@example.com>", lines[1])
+	assert.Contains(t, lines, "From: Platform Team <platform@example.com>")
+	assert.Contains(t, lines, "Subject: git-xargs run run-1: 1 succeeded")
+	assert.Contains(t, lines, "1 repos processed.")
+}
+
+// Test that non-ASCII subjects are encoded, and that long body lines are wrapped as quoted-printable
+func TestBuildEmailMessage(t *testing.T) {
+	t.Parallel()
+
+	message, err := buildEmailMessage("platform@example.com", []string{"owner@example.com"}, "Mise à jour", strings.Repeat("a", 100)+"\n", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	reader := textproto.NewReader(bufio.NewReader(strings.NewReader(string(message))))
+	header, err := reader.ReadMIMEHeader()
+	require.NoError(t, err)
+	assert.Equal(t, "=?utf-8?q?Mise_=C3=A0_jour?=", header.Get("Subject"))
+	assert.Equal(t, "Fri, 16 Oct 2026 00:00:00 +0000", header.Get("Date"))
+	assert.Equal(t, "quoted-printable", header.Get("Content-Transfer-Encoding"))
+	assert.NotContains(t, string(message), strings.Repeat("a", 100))
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// jiraTransition is a transition of a Jira issue, as returned by the transitions endpoint of the Jira REST API
+type jiraTransition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
+}
+
+// EnsureJiraTokenSet is a sanity check that a value is exported for JIRA_API_TOKEN, which --jira-issue requires
+func EnsureJiraTokenSet() error {
+	if os.Getenv("JIRA_API_TOKEN") == "" {
+		return errors.WithStackTrace(types.NoJiraTokenProvidedErr{})
+	}
+	return nil
+}
+
+// PostJiraComment adds the supplied text as a comment to the supplied issue of the Jira instance at the supplied URL
+func PostJiraComment(jiraURL string, issueKey string, text string) error {
+	payload, err := json.Marshal(map[string]string{"body": text})
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return sendJiraRequest(http.MethodPost, jiraIssueURL(jiraURL, issueKey, "comment"), payload, nil)
+}
+
+// TransitionJiraIssue moves the supplied issue of the Jira instance at the supplied URL through the transition with the
+// supplied name, or the one leading to the status with that name, e.g. Done, and returns true if it did. An issue that
+// is already in that status, e.g. because status was run again after every pull request was merged, is left as is
+func TransitionJiraIssue(jiraURL string, issueKey string, transition string) (bool, error) {
+	issue := struct {
+		Fields struct {
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}{}
+	if err := sendJiraRequest(http.MethodGet, jiraIssueURL(jiraURL, issueKey, "")+"?fields=status", nil, &issue); err != nil {
+		return false, err
+	}
+	currentStatus := issue.Fields.Status.Name
+	if strings.EqualFold(currentStatus, transition) {
+		return false, nil
+	}
+
+	transitionsURL := jiraIssueURL(jiraURL, issueKey, "transitions")
+	response := struct {
+		Transitions []jiraTransition `json:"transitions"`
+	}{}
+	if err := sendJiraRequest(http.MethodGet, transitionsURL, nil, &response); err != nil {
+		return false, err
+	}
+
+	available := []string{}
+	for _, candidate := range response.Transitions {
+		if strings.EqualFold(candidate.Name, transition) || strings.EqualFold(candidate.To.Name, transition) {
+			// Workflows often offer a transition into the status the issue is already in, which would only add to its
+			// history
+			if strings.EqualFold(candidate.To.Name, currentStatus) {
+				return false, nil
+			}
+			payload, err := json.Marshal(map[string]interface{}{"transition": map[string]string{"id": candidate.ID}})
+			if err != nil {
+				return false, errors.WithStackTrace(err)
+			}
+			return true, sendJiraRequest(http.MethodPost, transitionsURL, payload, nil)
+		}
+		available = append(available, candidate.Name)
+	}
+	return false, errors.WithStackTrace(types.JiraTransitionNotFoundErr{Issue: issueKey, Transition: transition, Available: available})
+}
+
+// jiraIssueURL returns the URL of the supplied resource of the supplied issue in the Jira REST API, or of the issue
+// itself if the resource is empty
+func jiraIssueURL(jiraURL string, issueKey string, resource string) string {
+	issueURL := fmt.Sprintf("%s/rest/api/2/issue/%s", strings.TrimRight(jiraURL, "/"), issueKey)
+	if resource == "" {
+		return issueURL
+	}
+	return issueURL + "/" + resource
+}
+
+// sendJiraRequest sends a request to the Jira REST API, authenticated with the token exported as JIRA_API_TOKEN, and
+// decodes the JSON response into out, unless it is nil. Jira Cloud takes the token along with the email address of its
+// owner, exported as JIRA_USER_EMAIL, while Jira Data Center takes a personal access token on its own
+func sendJiraRequest(method string, url string, payload []byte, out interface{}) error {
+	if err := EnsureJiraTokenSet(); err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "git-xargs")
+	if email := os.Getenv("JIRA_USER_EMAIL"); email != "" {
+		req.SetBasicAuth(email, os.Getenv("JIRA_API_TOKEN"))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+os.Getenv("JIRA_API_TOKEN"))
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		ioutil.ReadAll(resp.Body)
+		return errors.WithStackTrace(types.WebhookRequestFailedErr{URL: url, StatusCode: resp.StatusCode})
+	}
+	if out == nil {
+		ioutil.ReadAll(resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests are not run in parallel, because they set JIRA_API_TOKEN and JIRA_USER_EMAIL
+
+func TestPostJiraComment(t *testing.T) {
+	var user, password string
+	body := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/issue/PLAT-123/comment", r.URL.Path)
+		user, password, _ = r.BasicAuth()
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	os.Setenv("JIRA_API_TOKEN", "jira-token")
+	os.Setenv("JIRA_USER_EMAIL", "bot@example.com")
+	defer os.Unsetenv("JIRA_API_TOKEN")
+	defer os.Unsetenv("JIRA_USER_EMAIL")
+
+	require.NoError(t, PostJiraComment(server.URL+"/", "PLAT-123", "# git-xargs run report"))
+	assert.Equal(t, "bot@example.com", user)
+	assert.Equal(t, "jira-token", password)
+	assert.Equal(t, "# git-xargs run report", body["body"])
+}
+
+func TestTransitionJiraIssue(t *testing.T) {
+	var authorization string
+	status := "In Progress"
+	transitioned := map[string]map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path == "/rest/api/2/issue/PLAT-123" {
+			assert.Equal(t, "status", r.URL.Query().Get("fields"))
+			w.Write([]byte(`{"fields":{"status":{"name":"` + status + `"}}}`))
+			return
+		}
+		assert.Equal(t, "/rest/api/2/issue/PLAT-123/transitions", r.URL.Path)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"transitions":[{"id":"21","name":"Start","to":{"name":"In Progress"}},{"id":"31","name":"Resolve","to":{"name":"Done"}}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&transitioned)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	os.Setenv("JIRA_API_TOKEN", "jira-pat")
+	defer os.Unsetenv("JIRA_API_TOKEN")
+
+	done, err := TransitionJiraIssue(server.URL, "PLAT-123", "done")
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, "Bearer jira-pat", authorization)
+	assert.Equal(t, "31", transitioned["transition"]["id"])
+
+	// An issue that is already in the status is left alone, whether the status or the transition leading to it is passed
+	status = "Done"
+	transitioned = map[string]map[string]string{}
+	for _, transition := range []string{"Done", "Resolve"} {
+		done, err = TransitionJiraIssue(server.URL, "PLAT-123", transition)
+		require.NoError(t, err)
+		assert.False(t, done)
+	}
+	assert.Empty(t, transitioned)
+
+	status = "In Progress"
+	_, err = TransitionJiraIssue(server.URL, "PLAT-123", "Closed")
+	require.Error(t, err)
+	notFoundErr, ok := errors.Unwrap(err).(types.JiraTransitionNotFoundErr)
+	require.True(t, ok)
+	assert.Equal(t, []string{"Start", "Resolve"}, notFoundErr.Available)
+}
@@ -0,0 +1,446 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	// credentialsTimeout bounds each request for credentials to a token endpoint
+	credentialsTimeout = 10 * time.Second
+	// metadataTimeout bounds each request to the metadata service of a cloud instance, which doesn't answer at all
+	// outside of one
+	metadataTimeout = 2 * time.Second
+	// gcsScope is the OAuth scope of the access tokens requested to upload to Google Cloud Storage
+	gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+	// googleTokenURL is where Google OAuth access tokens are requested, unless a credentials file names another
+	googleTokenURL = "https://oauth2.googleapis.com/token"
+)
+
+// The endpoints credentials are requested from, which tests point at a local server
+var (
+	awsSTSURL                   = "https://sts.amazonaws.com/"
+	awsContainerCredentialsHost = "http://169.254.170.2"
+	awsInstanceMetadataURL      = "http://169.254.169.254"
+	gceMetadataURL              = "http://metadata.google.internal"
+)
+
+// awsCredentials are the credentials S3 requests are signed with
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// awsCredentialSources are the sources of credentials for S3, in the order the AWS CLI and SDKs look them up in. Each
+// returns nil credentials if it isn't configured, and an error if it is, but doesn't work
+var awsCredentialSources = []struct {
+	name   string
+	lookup func() (*awsCredentials, error)
+}{
+	{"AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", awsCredentialsFromEnv},
+	{"the shared credentials file", awsCredentialsFromSharedFile},
+	{"AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN", awsCredentialsFromWebIdentity},
+	{"the container credentials endpoint", awsCredentialsFromContainer},
+	{"the EC2 instance metadata service", awsCredentialsFromInstanceMetadata},
+}
+
+// resolveAWSCredentials returns the credentials of the first source in awsCredentialSources that has some
+func resolveAWSCredentials() (*awsCredentials, error) {
+	looked := []string{}
+	for _, source := range awsCredentialSources {
+		credentials, err := source.lookup()
+		if err != nil {
+			return nil, err
+		}
+		if credentials != nil {
+			return credentials, nil
+		}
+		looked = append(looked, source.name)
+	}
+	return nil, errors.WithStackTrace(types.MissingObjectStorageCredentialsErr{Scheme: common.ObjectStorageS3, Sources: looked})
+}
+
+func awsCredentialsFromEnv() (*awsCredentials, error) {
+	accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, nil
+	}
+	return &awsCredentials{accessKeyID: accessKeyID, secretAccessKey: secretAccessKey, sessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+}
+
+// awsCredentialsFromSharedFile reads the credentials of the AWS_PROFILE profile, or of the default one, from
+// AWS_SHARED_CREDENTIALS_FILE, or from ~/.aws/credentials
+func awsCredentialsFromSharedFile() (*awsCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	values := iniSection(string(contents), profile)
+	if values["aws_access_key_id"] == "" || values["aws_secret_access_key"] == "" {
+		return nil, nil
+	}
+	return &awsCredentials{
+		accessKeyID:     values["aws_access_key_id"],
+		secretAccessKey: values["aws_secret_access_key"],
+		sessionToken:    values["aws_session_token"],
+	}, nil
+}
+
+// iniSection returns the keys and values of the supplied section of an INI file, such as the shared credentials file
+// of AWS
+func iniSection(contents string, section string) map[string]string {
+	values := map[string]string{}
+	inSection := false
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+		case inSection:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) == 2 {
+				values[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	return values
+}
+
+// awsCredentialsFromWebIdentity exchanges the OIDC token in AWS_WEB_IDENTITY_TOKEN_FILE for temporary credentials of
+// the AWS_ROLE_ARN role, as on EKS with IAM roles for service accounts
+func awsCredentialsFromWebIdentity() (*awsCredentials, error) {
+	tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || roleARN == "" {
+		return nil, nil
+	}
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "git-xargs"
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequest(http.MethodPost, awsSTSURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := requestCredentials(req, credentialsTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return &awsCredentials{
+		accessKeyID:     response.Credentials.AccessKeyID,
+		secretAccessKey: response.Credentials.SecretAccessKey,
+		sessionToken:    response.Credentials.SessionToken,
+	}, nil
+}
+
+// awsCredentialsFromContainer requests the credentials of the task or pod from the container credentials endpoint, as
+// on ECS, or on EKS with Pod Identity
+func awsCredentialsFromContainer() (*awsCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relativeURI != "" {
+		endpoint = awsContainerCredentialsHost + relativeURI
+	}
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	authorization := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		authorization = strings.TrimSpace(string(token))
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	body, err := requestCredentials(req, credentialsTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return parseAWSCredentials(body)
+}
+
+// awsCredentialsFromInstanceMetadata requests the credentials of the role of the EC2 instance from its metadata
+// service, with IMDSv2. Outside of an EC2 instance, or one without a role, there are none
+func awsCredentialsFromInstanceMetadata() (*awsCredentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return nil, nil
+	}
+
+	tokenReq, err := http.NewRequest(http.MethodPut, awsInstanceMetadataURL+"/latest/api/token", nil)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	tokenReq.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+	token, err := requestCredentials(tokenReq, metadataTimeout)
+	if err != nil {
+		return nil, nil
+	}
+
+	credentialsURL := awsInstanceMetadataURL + "/latest/meta-data/iam/security-credentials/"
+	roleReq, err := http.NewRequest(http.MethodGet, credentialsURL, nil)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	roleReq.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+	role, err := requestCredentials(roleReq, metadataTimeout)
+	if err != nil || strings.TrimSpace(string(role)) == "" {
+		return nil, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, credentialsURL+strings.TrimSpace(string(role)), nil)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+	body, err := requestCredentials(req, metadataTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return parseAWSCredentials(body)
+}
+
+// parseAWSCredentials parses the JSON credentials returned by the container credentials endpoint and the EC2 instance
+// metadata service
+func parseAWSCredentials(body []byte) (*awsCredentials, error) {
+	var response struct {
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string `json:"SecretAccessKey"`
+		Token           string `json:"Token"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return &awsCredentials{accessKeyID: response.AccessKeyID, secretAccessKey: response.SecretAccessKey, sessionToken: response.Token}, nil
+}
+
+// gcsCredentialSources are the sources of access tokens for Google Cloud Storage, in the order gcloud and the Google
+// Cloud client libraries look them up in. Each returns an empty token if it isn't configured, and an error if it is,
+// but doesn't work
+var gcsCredentialSources = []struct {
+	name   string
+	lookup func() (string, error)
+}{
+	{"CLOUDSDK_AUTH_ACCESS_TOKEN", gcsTokenFromEnv},
+	{"GOOGLE_APPLICATION_CREDENTIALS or the application default credentials of gcloud", gcsTokenFromCredentialsFile},
+	{"the GCE metadata server", gcsTokenFromMetadata},
+}
+
+// resolveGCSAccessToken returns an access token from the first source in gcsCredentialSources that has one
+func resolveGCSAccessToken() (string, error) {
+	looked := []string{}
+	for _, source := range gcsCredentialSources {
+		token, err := source.lookup()
+		if err != nil {
+			return "", err
+		}
+		if token != "" {
+			return token, nil
+		}
+		looked = append(looked, source.name)
+	}
+	return "", errors.WithStackTrace(types.MissingObjectStorageCredentialsErr{Scheme: common.ObjectStorageGCS, Sources: looked})
+}
+
+// gcsTokenFromEnv reads the access token gcloud itself reads, e.g. as output by the google-github-actions/auth GitHub
+// Action with token_format: access_token
+func gcsTokenFromEnv() (string, error) {
+	return os.Getenv("CLOUDSDK_AUTH_ACCESS_TOKEN"), nil
+}
+
+// gcsTokenFromCredentialsFile requests an access token with the service account key or user credentials in the file
+// at GOOGLE_APPLICATION_CREDENTIALS, or in the application default credentials of gcloud
+func gcsTokenFromCredentialsFile() (string, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = gcloudApplicationDefaultCredentialsPath()
+	}
+	if path == "" {
+		return "", nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	var credentials struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		PrivateKeyID string `json:"private_key_id"`
+		TokenURI     string `json:"token_uri"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(contents, &credentials); err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: credentialsTimeout})
+	var tokenSource oauth2.TokenSource
+	switch credentials.Type {
+	case "service_account":
+		tokenURL := credentials.TokenURI
+		if tokenURL == "" {
+			tokenURL = googleTokenURL
+		}
+		jwtConfig := &jwt.Config{
+			Email:        credentials.ClientEmail,
+			PrivateKey:   []byte(credentials.PrivateKey),
+			PrivateKeyID: credentials.PrivateKeyID,
+			Scopes:       []string{gcsScope},
+			TokenURL:     tokenURL,
+		}
+		tokenSource = jwtConfig.TokenSource(ctx)
+	case "authorized_user":
+		oauthConfig := &oauth2.Config{
+			ClientID:     credentials.ClientID,
+			ClientSecret: credentials.ClientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: googleTokenURL},
+			Scopes:       []string{gcsScope},
+		}
+		tokenSource = oauthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: credentials.RefreshToken})
+	default:
+		return "", errors.WithStackTrace(types.UnsupportedObjectStorageCredentialsErr{Path: path, Type: credentials.Type})
+	}
+
+	token, err := tokenSource.Token()
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return token.AccessToken, nil
+}
+
+// gcloudApplicationDefaultCredentialsPath returns where gcloud auth application-default login saves its credentials
+func gcloudApplicationDefaultCredentialsPath() string {
+	if configDir := os.Getenv("CLOUDSDK_CONFIG"); configDir != "" {
+		return filepath.Join(configDir, "application_default_credentials.json")
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// gcsTokenFromMetadata requests an access token of the service account of the GCE instance, or GKE pod, from the
+// metadata server. Outside of Google Cloud, there is none
+func gcsTokenFromMetadata() (string, error) {
+	metadataURL := gceMetadataURL
+	if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+		metadataURL = "http://" + host
+	}
+
+	req, err := http.NewRequest(http.MethodGet, metadataURL+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	body, err := requestCredentials(req, metadataTimeout)
+	if err != nil {
+		return "", nil
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return response.AccessToken, nil
+}
+
+// requestCredentials sends the supplied request for credentials, and returns the body of the response, or a
+// types.ObjectStorageCredentialsRequestFailedErr if it doesn't succeed
+func requestCredentials(req *http.Request, timeout time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, errors.WithStackTrace(types.ObjectStorageCredentialsRequestFailedErr{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(body)),
+		})
+	}
+	return body, nil
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setObjectStorageEnv sets the supplied environment variables, and unsets every other one credentials are looked up in,
+// until the test is done
+func setObjectStorageEnv(t *testing.T, env map[string]string) {
+	names := []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_SHARED_CREDENTIALS_FILE", "AWS_PROFILE",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+		"AWS_EC2_METADATA_DISABLED", "CLOUDSDK_AUTH_ACCESS_TOKEN", "GOOGLE_APPLICATION_CREDENTIALS", "CLOUDSDK_CONFIG",
+		"GCE_METADATA_HOST",
+	}
+	for _, name := range names {
+		previous, wasSet := os.LookupEnv(name)
+		os.Unsetenv(name)
+		if value, ok := env[name]; ok {
+			os.Setenv(name, value)
+		}
+		t.Cleanup(func() {
+			if wasSet {
+				os.Setenv(name, previous)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+func TestResolveAWSCredentialsFromSharedFile(t *testing.T) {
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = default-key
+aws_secret_access_key = default-secret
+
+# The profile of the reports bucket
+[reports]
+aws_access_key_id = reports-key
+aws_secret_access_key = reports-secret
+aws_session_token = reports-token
+`), 0600))
+	setObjectStorageEnv(t, map[string]string{"AWS_SHARED_CREDENTIALS_FILE": credentialsFile, "AWS_PROFILE": "reports"})
+
+	credentials, err := resolveAWSCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, awsCredentials{accessKeyID: "reports-key", secretAccessKey: "reports-secret", sessionToken: "reports-token"}, *credentials)
+}
+
+func TestResolveAWSCredentialsFromContainer(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"AccessKeyId":"task-key","SecretAccessKey":"task-secret","Token":"task-token"}`))
+	}))
+	defer server.Close()
+	setObjectStorageEnv(t, map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE":        filepath.Join(t.TempDir(), "missing"),
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL + "/credentials",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":  "pod-token",
+	})
+
+	credentials, err := resolveAWSCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, awsCredentials{accessKeyID: "task-key", secretAccessKey: "task-secret", sessionToken: "task-token"}, *credentials)
+	assert.Equal(t, "pod-token", authorization)
+}
+
+func TestResolveAWSCredentialsFromWebIdentity(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>role-key</AccessKeyId>
+      <SecretAccessKey>role-secret</SecretAccessKey>
+      <SessionToken>role-token</SessionToken>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer server.Close()
+	previousSTSURL := awsSTSURL
+	awsSTSURL = server.URL
+	defer func() { awsSTSURL = previousSTSURL }()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("oidc-token\n"), 0600))
+	setObjectStorageEnv(t, map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "missing"),
+		"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/reports",
+	})
+
+	credentials, err := resolveAWSCredentials()
+	require.NoError(t, err)
+	assert.Equal(t, awsCredentials{accessKeyID: "role-key", secretAccessKey: "role-secret", sessionToken: "role-token"}, *credentials)
+	assert.Equal(t, []string{"oidc-token"}, form["WebIdentityToken"])
+	assert.Equal(t, []string{"arn:aws:iam::123456789012:role/reports"}, form["RoleArn"])
+}
+
+func TestResolveAWSCredentialsReportsWhereItLooked(t *testing.T) {
+	setObjectStorageEnv(t, map[string]string{
+		"AWS_SHARED_CREDENTIALS_FILE": filepath.Join(t.TempDir(), "missing"),
+		"AWS_EC2_METADATA_DISABLED":   "true",
+	})
+
+	_, err := resolveAWSCredentials()
+	require.Error(t, err)
+	missingErr, ok := errors.Unwrap(err).(types.MissingObjectStorageCredentialsErr)
+	require.True(t, ok)
+	assert.Len(t, missingErr.Sources, len(awsCredentialSources))
+}
+
+func TestResolveGCSAccessTokenFromMetadata(t *testing.T) {
+	var flavor string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flavor = r.Header.Get("Metadata-Flavor")
+		w.Write([]byte(`{"access_token":"instance-token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+	setObjectStorageEnv(t, map[string]string{
+		"CLOUDSDK_CONFIG":   t.TempDir(),
+		"GCE_METADATA_HOST": strings.TrimPrefix(server.URL, "http://"),
+	})
+
+	token, err := resolveGCSAccessToken()
+	require.NoError(t, err)
+	assert.Equal(t, "instance-token", token)
+	assert.Equal(t, "Google", flavor)
+}
+
+func TestResolveGCSAccessTokenRejectsUnsupportedCredentials(t *testing.T) {
+	credentialsFile := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, ioutil.WriteFile(credentialsFile, []byte(`{"type":"external_account"}`), 0600))
+	setObjectStorageEnv(t, map[string]string{"GOOGLE_APPLICATION_CREDENTIALS": credentialsFile})
+
+	_, err := resolveGCSAccessToken()
+	require.Error(t, err)
+	_, ok := errors.Unwrap(err).(types.UnsupportedObjectStorageCredentialsErr)
+	assert.True(t, ok)
+}
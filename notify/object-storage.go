@@ -0,0 +1,241 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// objectUploadTimeout bounds how long uploading a report to object storage may take
+const objectUploadTimeout = 60 * time.Second
+
+// objectUploader uploads objects to a bucket in object storage
+type objectUploader interface {
+	upload(key string, contentType string, body []byte) error
+}
+
+// EnsureObjectStorageCredentials returns an error if there are no credentials to upload to the supplied location with,
+// so that a run whose report can't be uploaded fails before it starts, rather than once it's done
+func EnsureObjectStorageCredentials(location types.ObjectStorageLocation) error {
+	_, err := newObjectUploader(location)
+	return err
+}
+
+// UploadObject uploads the supplied body as the object with the supplied name under the supplied location, and returns
+// the URL of the object. Credentials are looked up in a subset of the places the AWS CLI, for S3, and gcloud, for
+// Google Cloud Storage, look them up in: see awsCredentialSources and gcsCredentialSources
+func UploadObject(location types.ObjectStorageLocation, name string, contentType string, body []byte) (string, error) {
+	uploader, err := newObjectUploader(location)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uploader.upload(location.Key(name), contentType, body); err != nil {
+		return "", err
+	}
+	return location.URL(name), nil
+}
+
+// newObjectUploader returns an objectUploader for the bucket of the supplied location, with the credentials found for
+// its store. They are looked up again for each upload, since the temporary ones expire
+func newObjectUploader(location types.ObjectStorageLocation) (objectUploader, error) {
+	switch location.Scheme {
+	case common.ObjectStorageS3:
+		return newS3UploaderFromEnv(location.Bucket)
+	case common.ObjectStorageGCS:
+		return newGCSUploaderFromEnv(location.Bucket)
+	default:
+		return nil, errors.WithStackTrace(types.InvalidReportUploadURLErr{URL: location.URL("")})
+	}
+}
+
+// s3Uploader uploads objects to an S3 bucket, or a bucket of an S3-compatible store, signing each request with AWS
+// Signature Version 4
+type s3Uploader struct {
+	// baseURL is the URL objects are uploaded under, which includes the bucket
+	baseURL         string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	now             func() time.Time
+}
+
+// newS3UploaderFromEnv returns an s3Uploader for the supplied bucket, with the credentials found by
+// resolveAWSCredentials and the region in the environment. AWS_ENDPOINT_URL points it at an S3-compatible store instead
+// of AWS, with path-style URLs
+func newS3UploaderFromEnv(bucket string) (*s3Uploader, error) {
+	credentials, err := resolveAWSCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	baseURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		baseURL = strings.TrimSuffix(endpoint, "/") + "/" + bucket
+	}
+
+	return &s3Uploader{
+		baseURL:         baseURL,
+		region:          region,
+		accessKeyID:     credentials.accessKeyID,
+		secretAccessKey: credentials.secretAccessKey,
+		sessionToken:    credentials.sessionToken,
+		now:             time.Now,
+	}, nil
+}
+
+func (uploader *s3Uploader) upload(key string, contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPut, uploader.baseURL+"/"+awsURIEncode(key), bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if uploader.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", uploader.sessionToken)
+	}
+	uploader.sign(req, body)
+	return sendUpload(req)
+}
+
+// sign adds the headers of AWS Signature Version 4 to the supplied request, signing every header it holds along with
+// its host, path, query and body
+func (uploader *s3Uploader) sign(req *http.Request, body []byte) {
+	now := uploader.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, uploader.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+uploader.secretAccessKey), date)
+	for _, part := range []string{uploader.region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", uploader.accessKeyID, scope, signedHeaders, signature))
+}
+
+// gcsUploader uploads objects to a Google Cloud Storage bucket, authenticating with an OAuth access token
+type gcsUploader struct {
+	// baseURL is the URL objects are uploaded under, which includes the bucket
+	baseURL     string
+	accessToken string
+}
+
+// newGCSUploaderFromEnv returns a gcsUploader for the supplied bucket, with the access token found by
+// resolveGCSAccessToken
+func newGCSUploaderFromEnv(bucket string) (*gcsUploader, error) {
+	accessToken, err := resolveGCSAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	return &gcsUploader{baseURL: "https://storage.googleapis.com/" + bucket, accessToken: accessToken}, nil
+}
+
+func (uploader *gcsUploader) upload(key string, contentType string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPut, uploader.baseURL+"/"+awsURIEncode(key), bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+uploader.accessToken)
+	return sendUpload(req)
+}
+
+// sendUpload sends the supplied upload request, and returns a types.ObjectUploadFailedErr with the error message of the
+// store if it doesn't succeed
+func sendUpload(req *http.Request) error {
+	client := &http.Client{Timeout: objectUploadTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.WithStackTrace(types.ObjectUploadFailedErr{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(respBody)),
+		})
+	}
+	return nil
+}
+
+// awsURIEncode percent-encodes the supplied object key as AWS Signature Version 4 expects: every byte but letters,
+// digits, / and -._~ is encoded
+func awsURIEncode(value string) string {
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.', c == '_', c == '~', c == '/':
+			builder.WriteByte(c)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", c)
+		}
+	}
+	return builder.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// PushMetrics pushes the supplied metrics, in the Prometheus text exposition format, to the Pushgateway at the supplied
+// URL under the supplied job. The metrics replace the ones previously pushed for the job, so that the Pushgateway
+// always holds those of the latest run
+func PushMetrics(gatewayURL string, job string, metrics []byte) error {
+	pushURL := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+
+	req, err := http.NewRequest(http.MethodPut, pushURL, bytes.NewReader(metrics))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.WithStackTrace(types.WebhookRequestFailedErr{URL: pushURL, StatusCode: resp.StatusCode})
+	}
+	return nil
+}
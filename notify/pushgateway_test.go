@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushMetrics(t *testing.T) {
+	t.Parallel()
+
+	var method, path string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		path = r.URL.Path
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	require.NoError(t, PushMetrics(server.URL+"/", "nightly-upgrade", []byte("git_xargs_github_api_calls 42\n")))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/nightly-upgrade", path)
+	assert.Equal(t, "git_xargs_github_api_calls 42\n", string(body))
+}
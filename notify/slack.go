@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// slackAPIURL is the base URL of the Slack Web API. It's a variable so that tests can point it at a fake server
+var slackAPIURL = "https://slack.com/api/"
+
+// slackResponse is the envelope the Slack Web API wraps around every response
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// EnsureSlackBotTokenSet is a sanity check that a value is exported for SLACK_BOT_TOKEN, which --slack-channel requires
+func EnsureSlackBotTokenSet() error {
+	if os.Getenv("SLACK_BOT_TOKEN") == "" {
+		return errors.WithStackTrace(types.NoSlackBotTokenProvidedErr{})
+	}
+	return nil
+}
+
+// PostSlackWebhook posts the supplied mrkdwn message to a Slack incoming webhook
+func PostSlackWebhook(url string, text string) error {
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return PostWebhook(url, payload)
+}
+
+// PostSlackMessage posts the supplied mrkdwn message to the supplied Slack channel as the bot whose token is exported
+// as SLACK_BOT_TOKEN
+func PostSlackMessage(channel string, text string) error {
+	if err := EnsureSlackBotTokenSet(); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"channel":      channel,
+		"text":         text,
+		"unfurl_links": false,
+	})
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, slackAPIURL+"chat.postMessage", bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("SLACK_BOT_TOKEN"))
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.WithStackTrace(types.WebhookRequestFailedErr{URL: slackAPIURL + "chat.postMessage", StatusCode: resp.StatusCode})
+	}
+
+	response := slackResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if !response.OK {
+		return errors.WithStackTrace(types.SlackAPIErr{Message: response.Error})
+	}
+	return nil
+}
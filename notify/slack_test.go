@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests are not run in parallel, because they point slackAPIURL at a fake server and set SLACK_BOT_TOKEN
+
+func TestPostSlackMessage(t *testing.T) {
+	var authorization string
+	body := map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/chat.postMessage", r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	defer setSlackAPIURL(server.URL + "/")()
+
+	os.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	defer os.Unsetenv("SLACK_BOT_TOKEN")
+
+	require.NoError(t, PostSlackMessage("#campaigns", "*git-xargs run finished*"))
+	assert.Equal(t, "Bearer xoxb-test", authorization)
+	assert.Equal(t, "#campaigns", body["channel"])
+	assert.Equal(t, "*git-xargs run finished*", body["text"])
+}
+
+func TestPostSlackMessageReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer server.Close()
+	defer setSlackAPIURL(server.URL + "/")()
+
+	os.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	defer os.Unsetenv("SLACK_BOT_TOKEN")
+
+	err := PostSlackMessage("#missing", "text")
+	require.Error(t, err)
+	assert.Equal(t, types.SlackAPIErr{Message: "channel_not_found"}, errors.Unwrap(err))
+}
+
+func TestPostSlackMessageRequiresToken(t *testing.T) {
+	os.Unsetenv("SLACK_BOT_TOKEN")
+
+	err := PostSlackMessage("#campaigns", "text")
+	require.Error(t, err)
+	assert.Equal(t, types.NoSlackBotTokenProvidedErr{}, errors.Unwrap(err))
+}
+
+// setSlackAPIURL points the Slack client at the supplied URL, and returns a function that restores the real one
+func setSlackAPIURL(url string) func() {
+	original := slackAPIURL
+	slackAPIURL = url
+	return func() { slackAPIURL = original }
+}
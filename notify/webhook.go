@@ -0,0 +1,40 @@
+// Package notify sends the outcome of a run to external systems once it finishes, so that dashboards and chat rooms
+// can follow a campaign without anybody watching the terminal.
+package notify
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// webhookTimeout bounds how long a webhook endpoint can hold up the end of a run
+const webhookTimeout = 30 * time.Second
+
+// PostWebhook POSTs the supplied JSON payload to the supplied URL, and returns an error if the endpoint doesn't respond
+// with a 2xx status code
+func PostWebhook(url string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "git-xargs")
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.WithStackTrace(types.WebhookRequestFailedErr{URL: url, StatusCode: resp.StatusCode})
+	}
+	return nil
+}
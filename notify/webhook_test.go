@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostWebhook(t *testing.T) {
+	t.Parallel()
+
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	require.NoError(t, PostWebhook(server.URL, []byte(`{"run_id":"run-1"}`)))
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, `{"run_id":"run-1"}`, string(body))
+}
+
+func TestPostWebhookReturnsErrorOnFailureStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := PostWebhook(server.URL, []byte(`{}`))
+	require.Error(t, err)
+	assert.Equal(t, types.WebhookRequestFailedErr{URL: server.URL, StatusCode: http.StatusInternalServerError}, errors.Unwrap(err))
+}
@@ -0,0 +1,268 @@
+// Package picker lets the user narrow down a list of repos in an interactive multi-select on the terminal, with a filter
+// to search them, before a run touches any of them.
+package picker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"golang.org/x/term"
+)
+
+const (
+	// The escape sequences that switch to the alternate screen of the terminal and hide the cursor, and back, so that the
+	// picker doesn't leave its output in the scrollback
+	enterPickerScreen = "\x1b[?1049h\x1b[?25l"
+	exitPickerScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen       = "\x1b[H\x1b[2J"
+	// headerLines and footerLines are the number of lines drawn above and below the list of repos
+	headerLines = 2
+	footerLines = 1
+	// The size the picker is drawn at if the size of the terminal can't be read
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// key is a key press the picker acts on
+type key int
+
+const (
+	keyUp key = iota
+	keyDown
+	keyToggle
+	keySelectAll
+	keyDeselectAll
+	keyBackspace
+	keyConfirm
+	keyCancel
+	keyRune
+)
+
+// keyPress is a key pressed by the user, along with the character typed, for keyRune
+type keyPress struct {
+	key  key
+	char rune
+}
+
+// outcome is what handling a key press did to the picker
+type outcome int
+
+const (
+	pickerContinue outcome = iota
+	pickerConfirmed
+	pickerCancelled
+)
+
+// Pick shows the supplied repos in an interactive multi-select on the terminal, all of them selected to begin with, and
+// returns the ones still selected once the user confirms. The picker reads from the terminal itself rather than stdin,
+// so that it works when the repos were piped in via stdin
+func Pick(repos []string) ([]string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.PickNeedsTerminalErr{})
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, errors.WithStackTrace(types.PickNeedsTerminalErr{})
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	defer term.Restore(fd, oldState)
+
+	fmt.Fprint(tty, enterPickerScreen)
+	defer fmt.Fprint(tty, exitPickerScreen)
+
+	p := newPicker(repos)
+	input := make([]byte, 64)
+	for {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = defaultWidth, defaultHeight
+		}
+		fmt.Fprint(tty, clearScreen+p.render(width, height))
+
+		n, err := tty.Read(input)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		for _, press := range parseKeys(input[:n]) {
+			switch p.handle(press) {
+			case pickerConfirmed:
+				return p.picked(), nil
+			case pickerCancelled:
+				return nil, errors.WithStackTrace(types.PickCancelledErr{})
+			}
+		}
+	}
+}
+
+// picker is the state of the multi-select: which repos are selected, the filter typed so far and where the cursor is
+type picker struct {
+	repos    []string
+	selected []bool
+	filter   string
+	// cursor is the position of the highlighted repo among the repos matching the filter, and offset the position of
+	// the first of them shown, once there are more than fit on the terminal
+	cursor int
+	offset int
+}
+
+func newPicker(repos []string) *picker {
+	selected := make([]bool, len(repos))
+	for i := range selected {
+		selected[i] = true
+	}
+	return &picker{repos: repos, selected: selected}
+}
+
+// visible returns the indexes of the repos that match the filter, ignoring case
+func (p *picker) visible() []int {
+	filter := strings.ToLower(p.filter)
+	indexes := []int{}
+	for i, repo := range p.repos {
+		if strings.Contains(strings.ToLower(repo), filter) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// picked returns the selected repos, in their original order
+func (p *picker) picked() []string {
+	picked := []string{}
+	for i, repo := range p.repos {
+		if p.selected[i] {
+			picked = append(picked, repo)
+		}
+	}
+	return picked
+}
+
+// handle updates the picker for the supplied key press
+func (p *picker) handle(press keyPress) outcome {
+	visible := p.visible()
+
+	switch press.key {
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < len(visible)-1 {
+			p.cursor++
+		}
+	case keyToggle:
+		if p.cursor < len(visible) {
+			p.selected[visible[p.cursor]] = !p.selected[visible[p.cursor]]
+		}
+	case keySelectAll, keyDeselectAll:
+		for _, index := range visible {
+			p.selected[index] = press.key == keySelectAll
+		}
+	case keyBackspace:
+		if p.filter != "" {
+			filter := []rune(p.filter)
+			p.filter = string(filter[:len(filter)-1])
+			p.cursor = 0
+		}
+	case keyRune:
+		p.filter += string(press.char)
+		p.cursor = 0
+	case keyConfirm:
+		return pickerConfirmed
+	case keyCancel:
+		return pickerCancelled
+	}
+	return pickerContinue
+}
+
+// render draws the picker to fit the supplied size of the terminal. Lines end in \r\n, since the terminal is in raw mode
+func (p *picker) render(width int, height int) string {
+	visible := p.visible()
+	selectedCount := len(p.picked())
+
+	rows := height - headerLines - footerLines
+	if rows < 1 {
+		rows = 1
+	}
+	// Scroll the list so that the cursor stays on screen
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+rows {
+		p.offset = p.cursor - rows + 1
+	}
+
+	lines := []string{
+		fmt.Sprintf("Pick the repos to run against: %d of %d selected", selectedCount, len(p.repos)),
+		fmt.Sprintf("Filter: %s", p.filter),
+	}
+	for row := p.offset; row < len(visible) && row < p.offset+rows; row++ {
+		pointer := " "
+		if row == p.cursor {
+			pointer = ">"
+		}
+		checkbox := "[ ]"
+		if p.selected[visible[row]] {
+			checkbox = "[x]"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s", pointer, checkbox, p.repos[visible[row]]))
+	}
+	if len(visible) == 0 {
+		lines = append(lines, "  No repos match the filter")
+	}
+	lines = append(lines, "Type to filter, Up/Down: move, Space: toggle, Ctrl-A/Ctrl-X: select/deselect all shown, Enter: confirm, Esc: cancel")
+
+	for i, line := range lines {
+		if len(line) > width {
+			lines[i] = line[:width]
+		}
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// parseKeys returns the key presses in the supplied input read from the terminal in raw mode
+func parseKeys(input []byte) []keyPress {
+	presses := []keyPress{}
+	for i := 0; i < len(input); i++ {
+		switch b := input[i]; {
+		case b == 0x1b && i+2 < len(input) && input[i+1] == '[':
+			// An escape sequence, such as an arrow key. Sequences other than the up and down arrows are ignored
+			switch input[i+2] {
+			case 'A':
+				presses = append(presses, keyPress{key: keyUp})
+			case 'B':
+				presses = append(presses, keyPress{key: keyDown})
+			}
+			i += 2
+		case b == 0x1b || b == 0x03:
+			// Esc on its own, or Ctrl-C
+			presses = append(presses, keyPress{key: keyCancel})
+		case b == '\r' || b == '\n':
+			presses = append(presses, keyPress{key: keyConfirm})
+		case b == ' ' || b == '\t':
+			presses = append(presses, keyPress{key: keyToggle})
+		case b == 0x01:
+			presses = append(presses, keyPress{key: keySelectAll})
+		case b == 0x18:
+			presses = append(presses, keyPress{key: keyDeselectAll})
+		case b == 0x10:
+			presses = append(presses, keyPress{key: keyUp})
+		case b == 0x0e:
+			presses = append(presses, keyPress{key: keyDown})
+		case b == 0x7f || b == 0x08:
+			presses = append(presses, keyPress{key: keyBackspace})
+		case unicode.IsPrint(rune(b)) && b < 0x80:
+			presses = append(presses, keyPress{key: keyRune, char: rune(b)})
+		}
+	}
+	return presses
+}
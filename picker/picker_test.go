@@ -0,0 +1,93 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	presses := parseKeys([]byte("ab\x1b[A\x1b[B\x1b[C \x01\x18\x7f\r\x1b"))
+	assert.Equal(t, []keyPress{
+		{key: keyRune, char: 'a'},
+		{key: keyRune, char: 'b'},
+		{key: keyUp},
+		{key: keyDown},
+		{key: keyToggle},
+		{key: keySelectAll},
+		{key: keyDeselectAll},
+		{key: keyBackspace},
+		{key: keyConfirm},
+		{key: keyCancel},
+	}, presses)
+}
+
+func TestPickerHandle(t *testing.T) {
+	t.Parallel()
+
+	p := newPicker([]string{"gruntwork-io/git-xargs", "gruntwork-io/cloud-nuke", "gruntwork-io/terratest", "gruntwork-io/terragrunt"})
+
+	// Every repo starts out selected
+	assert.Equal(t, p.repos, p.picked())
+
+	// Deselecting everything matching the filter leaves the others selected
+	for _, char := range "TERRA" {
+		p.handle(keyPress{key: keyRune, char: char})
+	}
+	assert.Equal(t, []int{2, 3}, p.visible())
+	p.handle(keyPress{key: keyDeselectAll})
+	assert.Equal(t, []string{"gruntwork-io/git-xargs", "gruntwork-io/cloud-nuke"}, p.picked())
+
+	// Toggling acts on the repo under the cursor, among those matching the filter
+	p.handle(keyPress{key: keyDown})
+	p.handle(keyPress{key: keyDown})
+	p.handle(keyPress{key: keyToggle})
+	assert.Equal(t, []string{"gruntwork-io/git-xargs", "gruntwork-io/cloud-nuke", "gruntwork-io/terragrunt"}, p.picked())
+
+	// Clearing the filter shows every repo again
+	for range "TERRA" {
+		p.handle(keyPress{key: keyBackspace})
+	}
+	assert.Len(t, p.visible(), 4)
+
+	assert.Equal(t, pickerContinue, p.handle(keyPress{key: keyUp}))
+	assert.Equal(t, pickerConfirmed, p.handle(keyPress{key: keyConfirm}))
+	assert.Equal(t, pickerCancelled, p.handle(keyPress{key: keyCancel}))
+}
+
+func TestPickerRender(t *testing.T) {
+	t.Parallel()
+
+	repos := []string{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		repos = append(repos, "gruntwork-io/"+name)
+	}
+	p := newPicker(repos)
+	p.selected[1] = false
+
+	// A terminal 6 lines high fits the header, the footer and 3 repos
+	lines := strings.Split(p.render(80, 6), "\r\n")
+	assert.Len(t, lines, 6)
+	assert.Equal(t, "Pick the repos to run against: 5 of 6 selected", lines[0])
+	assert.Equal(t, "> [x] gruntwork-io/a", lines[2])
+	assert.Equal(t, "  [ ] gruntwork-io/b", lines[3])
+
+	// The list scrolls to keep the cursor on screen
+	for i := 0; i < 4; i++ {
+		p.handle(keyPress{key: keyDown})
+	}
+	lines = strings.Split(p.render(80, 6), "\r\n")
+	assert.Equal(t, "  [x] gruntwork-io/c", lines[2])
+	assert.Equal(t, "> [x] gruntwork-io/e", lines[4])
+
+	// Lines are cut to the width of the terminal
+	for _, line := range strings.Split(p.render(20, 6), "\r\n") {
+		assert.True(t, len(line) <= 20, line)
+	}
+
+	p.filter = "nothing"
+	assert.Contains(t, p.render(80, 6), "No repos match the filter")
+}
@@ -0,0 +1,107 @@
+// Package plan implements the plan files written by git-xargs plan and executed by git-xargs apply. A plan records,
+// for every repo the command changed, the exact file contents to commit and the pull request to open, so that the
+// changes can be reviewed before anything is pushed, and what is applied is exactly what was reviewed.
+package plan
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// Version is the version of the plan file format written by this version of git-xargs
+const Version = 1
+
+// FileChange is a single file changed by the command in a repo
+type FileChange struct {
+	Path string `json:"path"`
+	// Deleted is true if the command deleted the file
+	Deleted bool `json:"deleted,omitempty"`
+	// Mode is the git file mode of the file after the change
+	Mode uint32 `json:"mode,omitempty"`
+	// Contents are the contents of the file after the change
+	Contents []byte `json:"contents,omitempty"`
+	// BaseBlob is the hash of the file the change was planned against, or empty if the command created the file
+	BaseBlob string `json:"base_blob,omitempty"`
+}
+
+// Repo is the planned change to a single repo
+type Repo struct {
+	Owner                  string       `json:"owner"`
+	Name                   string       `json:"name"`
+	BaseCommit             string       `json:"base_commit"`
+	PullRequestTitle       string       `json:"pull_request_title"`
+	PullRequestDescription string       `json:"pull_request_description"`
+	Diff                   string       `json:"diff"`
+	Files                  []FileChange `json:"files"`
+}
+
+// FullName returns the repo's name in the format <owner>/<name>
+func (repo Repo) FullName() string {
+	return repo.Owner + "/" + repo.Name
+}
+
+// Plan is the full set of changes planned by a single git-xargs plan invocation
+type Plan struct {
+	Version        int       `json:"version"`
+	RunID          string    `json:"run_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Command        []string  `json:"command"`
+	BranchName     string    `json:"branch_name"`
+	BaseBranchName string    `json:"base_branch_name,omitempty"`
+	CommitMessage  string    `json:"commit_message"`
+	Draft          bool      `json:"draft,omitempty"`
+	Repos          []*Repo   `json:"repos"`
+
+	mutex sync.Mutex
+}
+
+// New returns an empty plan
+func New() *Plan {
+	return &Plan{Version: Version, Repos: []*Repo{}}
+}
+
+// AddRepo adds the planned change to a repo. It is safe to call from the goroutines that process repos concurrently
+func (p *Plan) AddRepo(repo *Repo) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.Repos = append(p.Repos, repo)
+}
+
+// Write writes the plan to the file at the supplied path, with its repos sorted by full name
+func Write(p *Plan, path string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	sort.Slice(p.Repos, func(i, j int) bool {
+		return p.Repos[i].FullName() < p.Repos[j].FullName()
+	})
+
+	encoded, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return errors.WithStackTrace(ioutil.WriteFile(path, encoded, 0600))
+}
+
+// Read reads the plan in the file at the supplied path
+func Read(path string) (*Plan, error) {
+	encoded, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	p := &Plan{}
+	if err := json.Unmarshal(encoded, p); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPlanFileErr{Path: path, Err: err})
+	}
+	if p.Version != Version {
+		return nil, errors.WithStackTrace(types.UnsupportedPlanVersionErr{Path: path, Version: p.Version})
+	}
+	return p, nil
+}
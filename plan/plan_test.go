@@ -0,0 +1,48 @@
+package plan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a plan survives being written and read back, with its repos sorted by full name
+func TestWriteAndRead(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-plan-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plan.json")
+
+	p := New()
+	p.RunID = "run-1"
+	p.AddRepo(&Repo{Owner: "gruntwork-io", Name: "terragrunt", Files: []FileChange{{Path: "README.md", Contents: []byte("hello\n")}}})
+	p.AddRepo(&Repo{Owner: "gruntwork-io", Name: "fetch", Files: []FileChange{{Path: "old.txt", Deleted: true, BaseBlob: "abc"}}})
+	require.NoError(t, Write(p, path))
+
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", read.RunID)
+	require.Len(t, read.Repos, 2)
+	assert.Equal(t, "gruntwork-io/fetch", read.Repos[0].FullName())
+	assert.True(t, read.Repos[0].Files[0].Deleted)
+	assert.Equal(t, []byte("hello\n"), read.Repos[1].Files[0].Contents)
+}
+
+func TestReadRejectsUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-plan-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plan.json")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 99}`), 0600))
+	_, err = Read(path)
+	assert.Error(t, err)
+}
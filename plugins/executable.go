@@ -0,0 +1,162 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// describeHook is the argument git-xargs runs an executable plugin with when loading it, to find out which hooks it
+// implements
+const describeHook = "describe"
+
+// describeReply is what an executable plugin replies to describe with, e.g. {"hooks": ["select-repos", "transform"]}
+type describeReply struct {
+	Hooks []string `json:"hooks"`
+}
+
+// selectReposReply is what an executable plugin replies to select-repos with, e.g. {"repos": ["gruntwork-io/git-xargs"]}
+type selectReposReply struct {
+	Repos []string `json:"repos"`
+}
+
+// hookReply is what an executable plugin may reply to transform and post-process with, to track the repo under custom
+// events, e.g. {"events": [{"event": "needs-manual-review", "description": "Repos that need a manual review"}]}
+type hookReply struct {
+	Events []Event `json:"events"`
+}
+
+// executable is a plugin run as an executable. For each hook, git-xargs runs it with the name of the hook as its only
+// argument, writes the request to its stdin as JSON and reads the reply from its stdout as JSON. Exiting with a non-zero
+// code fails the hook, with what the executable wrote to stderr as the error
+type executable struct {
+	path string
+}
+
+// The hooks an executable plugin can implement, each wrapping the executable
+type executableRepoSelector struct{ executable }
+type executableTransform struct{ executable }
+type executablePostProcessHook struct{ executable }
+
+func (e executableRepoSelector) SelectRepos(ctx context.Context, request SelectReposRequest) ([]string, error) {
+	reply := selectReposReply{}
+	if err := e.run(ctx, HookSelectRepos, request, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Repos, nil
+}
+
+func (e executableTransform) Transform(ctx context.Context, request TransformRequest) error {
+	reply, err := e.runWithOptionalReply(ctx, HookTransform, request)
+	if err != nil {
+		return err
+	}
+	return trackEvents(request.TrackEvent, reply.Events)
+}
+
+func (e executablePostProcessHook) PostProcess(ctx context.Context, request PostProcessRequest) error {
+	reply, err := e.runWithOptionalReply(ctx, HookPostProcess, request)
+	if err != nil {
+		return err
+	}
+	return trackEvents(request.TrackEvent, reply.Events)
+}
+
+// trackEvents tracks the repo of a hook under each of the supplied events an executable plugin replied with, unless the
+// caller doesn't track events
+func trackEvents(trackEvent func(event Event) error, events []Event) error {
+	if trackEvent == nil {
+		return nil
+	}
+	for _, event := range events {
+		if err := trackEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadExecutable runs the executable plugin at the supplied path with describe, and returns a hook for each of the
+// hooks it replies that it implements
+func loadExecutable(path string) ([]interface{}, error) {
+	plugin := executable{path: path}
+	reply := describeReply{}
+	if err := plugin.run(context.Background(), describeHook, struct{}{}, &reply); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPluginErr{Plugin: path, Reason: err.Error()})
+	}
+
+	hooks := []interface{}{}
+	for _, hook := range reply.Hooks {
+		switch hook {
+		case HookSelectRepos:
+			hooks = append(hooks, executableRepoSelector{plugin})
+		case HookTransform:
+			hooks = append(hooks, executableTransform{plugin})
+		case HookPostProcess:
+			hooks = append(hooks, executablePostProcessHook{plugin})
+		default:
+			return nil, errors.WithStackTrace(types.InvalidPluginErr{Plugin: path, Reason: fmt.Sprintf("it replied to describe with the unknown hook %q", hook)})
+		}
+	}
+	return hooks, nil
+}
+
+// run runs the executable with the supplied hook, passing it the supplied request, and decodes its reply into the
+// supplied value, unless it is nil
+func (e executable) run(ctx context.Context, hook string, request interface{}, reply interface{}) error {
+	stdout, err := e.output(ctx, hook, request)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil || len(bytes.TrimSpace(stdout)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(stdout, reply); err != nil {
+		return errors.WithStackTrace(fmt.Errorf("invalid JSON reply to %s: %v", hook, err))
+	}
+	return nil
+}
+
+// runWithOptionalReply runs the executable with the supplied hook, like run, for the hooks that don't need a reply.
+// Their executables are often scripts that log to stdout, so anything other than a JSON reply is taken as no reply
+func (e executable) runWithOptionalReply(ctx context.Context, hook string, request interface{}) (hookReply, error) {
+	stdout, err := e.output(ctx, hook, request)
+	if err != nil {
+		return hookReply{}, err
+	}
+
+	reply := hookReply{}
+	if err := json.Unmarshal(stdout, &reply); err != nil {
+		return hookReply{}, nil
+	}
+	return reply, nil
+}
+
+// output runs the executable with the supplied hook, passing it the supplied request, and returns what it wrote to
+// stdout. Exiting with a non-zero code is an error, with what it wrote to stderr as the message
+func (e executable) output(ctx context.Context, hook string, request interface{}) ([]byte, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.path, hook)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, errors.WithStackTrace(fmt.Errorf("%v: %s", err, message))
+		}
+		return nil, errors.WithStackTrace(err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package plugins
+
+import (
+	"plugin"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// goPluginSymbol is the name of the variable a Go plugin exports. A pointer to it must implement one or more of
+// RepoSelector, Transform and PostProcessHook
+const goPluginSymbol = "Plugin"
+
+// loadGoPlugin opens the Go plugin at the supplied path and returns its Plugin variable. Go plugins only load on Linux
+// and macOS, and must be built with the same version of Go and of git-xargs as the binary loading them
+func loadGoPlugin(path string) ([]interface{}, error) {
+	goPlugin, err := plugin.Open(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPluginErr{Plugin: path, Reason: err.Error()})
+	}
+	symbol, err := goPlugin.Lookup(goPluginSymbol)
+	if err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPluginErr{Plugin: path, Reason: "it doesn't export a variable named " + goPluginSymbol})
+	}
+	return []interface{}{symbol}, nil
+}
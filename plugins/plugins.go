@@ -0,0 +1,208 @@
+// Package plugins extends how git-xargs selects repos and what it does to them, without forking it. A plugin is either a
+// Go plugin built with -buildmode=plugin, or an executable that speaks a JSON protocol over stdin and stdout, and
+// implements any of the RepoSelector, Transform and PostProcessHook hooks.
+package plugins
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+const (
+	// The names of the hooks, as listed by executables in their reply to describe, and as passed to them to run a hook
+	HookSelectRepos = "select-repos"
+	HookTransform   = "transform"
+	HookPostProcess = "post-process"
+)
+
+// SelectReposRequest is what a RepoSelector is told about the run
+type SelectReposRequest struct {
+	RunID     string `json:"run_id"`
+	GithubOrg string `json:"github_org"`
+	// Repos are the full names of the repos selected so far, via the repo selection flags and any earlier RepoSelector
+	Repos []string `json:"repos"`
+}
+
+// Event is a custom event category a Transform or PostProcessHook tracks its repo under, e.g. to flag the repos that
+// need a manual review. The run report lists the repos tracked under each custom event along with the built-in ones
+type Event struct {
+	Event       string `json:"event"`
+	Description string `json:"description"`
+}
+
+// TransformRequest is what a Transform is told about the repo to change
+type TransformRequest struct {
+	RunID      string `json:"run_id"`
+	Repo       string `json:"repo"`
+	BranchName string `json:"branch_name"`
+	// Dir is the directory the repo is cloned to, with the changes of the command already made
+	Dir string `json:"dir"`
+	// TrackEvent tracks the repo under a custom event. It is nil if the caller doesn't track events
+	TrackEvent func(event Event) error `json:"-"`
+}
+
+// PostProcessRequest is what a PostProcessHook is told about a repo that has been processed
+type PostProcessRequest struct {
+	RunID          string `json:"run_id"`
+	Repo           string `json:"repo"`
+	BranchName     string `json:"branch_name"`
+	PullRequestURL string `json:"pull_request_url"`
+	// Error is the error the repo failed with, or an empty string if it succeeded
+	Error string `json:"error"`
+	// TrackEvent tracks the repo under a custom event. It is nil if the caller doesn't track events
+	TrackEvent func(event Event) error `json:"-"`
+}
+
+// RepoSelector narrows down or adds to the repos selected for a run. It returns the full names of the repos to process,
+// e.g. gruntwork-io/git-xargs
+type RepoSelector interface {
+	SelectRepos(ctx context.Context, request SelectReposRequest) ([]string, error)
+}
+
+// Transform changes the clone of a repo after the command has run against it, before the changes are committed
+type Transform interface {
+	Transform(ctx context.Context, request TransformRequest) error
+}
+
+// PostProcessHook is called once a repo has been processed, whether it succeeded or failed, e.g. to label its pull
+// request or to record it in another system
+type PostProcessHook interface {
+	PostProcess(ctx context.Context, request PostProcessRequest) error
+}
+
+// The hooks of a plugin, along with the name of the plugin, for errors
+type namedRepoSelector struct {
+	plugin string
+	RepoSelector
+}
+
+type namedTransform struct {
+	plugin string
+	Transform
+}
+
+type namedPostProcessHook struct {
+	plugin string
+	PostProcessHook
+}
+
+// Set holds the hooks of the plugins of a run, in the order the plugins were passed. A nil Set has no hooks, so it can
+// be used whether or not any plugin was passed
+type Set struct {
+	selectors        []namedRepoSelector
+	transforms       []namedTransform
+	postProcessHooks []namedPostProcessHook
+}
+
+// Load loads the plugins at the supplied paths. Paths ending in .so are loaded as Go plugins, and anything else is run
+// as an executable. It returns nil if no paths were supplied
+func Load(paths []string) (*Set, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	set := &Set{}
+	for _, path := range paths {
+		var hooks []interface{}
+		var err error
+		if strings.HasSuffix(path, ".so") {
+			hooks, err = loadGoPlugin(path)
+		} else {
+			hooks, err = loadExecutable(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := set.Add(filepath.Base(path), hooks...); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// Add adds every hook the supplied values implement, under the supplied plugin name. Programs that embed git-xargs can
+// use it to add hooks implemented in their own code. It returns an error if the values implement no hook at all
+func (set *Set) Add(name string, hooks ...interface{}) error {
+	added := false
+	for _, hook := range hooks {
+		if set.add(name, hook) {
+			added = true
+		}
+	}
+	if !added {
+		return errors.WithStackTrace(types.InvalidPluginErr{Plugin: name, Reason: "it implements none of the select-repos, transform and post-process hooks"})
+	}
+	return nil
+}
+
+// add adds every hook the supplied value implements, and returns false if it implements none
+func (set *Set) add(name string, plugin interface{}) bool {
+	added := false
+	if selector, ok := plugin.(RepoSelector); ok {
+		set.selectors = append(set.selectors, namedRepoSelector{plugin: name, RepoSelector: selector})
+		added = true
+	}
+	if transform, ok := plugin.(Transform); ok {
+		set.transforms = append(set.transforms, namedTransform{plugin: name, Transform: transform})
+		added = true
+	}
+	if postProcessHook, ok := plugin.(PostProcessHook); ok {
+		set.postProcessHooks = append(set.postProcessHooks, namedPostProcessHook{plugin: name, PostProcessHook: postProcessHook})
+		added = true
+	}
+	return added
+}
+
+// HasRepoSelectors returns true if any plugin implements RepoSelector
+func (set *Set) HasRepoSelectors() bool {
+	return set != nil && len(set.selectors) > 0
+}
+
+// SelectRepos passes the supplied repos through every RepoSelector in turn, each receiving the repos the previous one
+// returned, and returns the repos the last one returned
+func (set *Set) SelectRepos(ctx context.Context, request SelectReposRequest) ([]string, error) {
+	if set == nil {
+		return request.Repos, nil
+	}
+	for _, selector := range set.selectors {
+		repos, err := selector.SelectRepos(ctx, request)
+		if err != nil {
+			return nil, errors.WithStackTrace(types.PluginErr{Plugin: selector.plugin, Hook: HookSelectRepos, Err: err})
+		}
+		request.Repos = repos
+	}
+	return request.Repos, nil
+}
+
+// Transform runs every Transform against the supplied repo in turn, stopping at the first that fails
+func (set *Set) Transform(ctx context.Context, request TransformRequest) error {
+	if set == nil {
+		return nil
+	}
+	for _, transform := range set.transforms {
+		if err := transform.Transform.Transform(ctx, request); err != nil {
+			return errors.WithStackTrace(types.PluginErr{Plugin: transform.plugin, Hook: HookTransform, Err: err})
+		}
+	}
+	return nil
+}
+
+// PostProcess calls every PostProcessHook with the supplied repo. Every hook is called even if an earlier one failed,
+// and the error of each that failed is returned
+func (set *Set) PostProcess(ctx context.Context, request PostProcessRequest) []error {
+	if set == nil {
+		return nil
+	}
+	var errs []error
+	for _, postProcessHook := range set.postProcessHooks {
+		if err := postProcessHook.PostProcess(ctx, request); err != nil {
+			errs = append(errs, errors.WithStackTrace(types.PluginErr{Plugin: postProcessHook.plugin, Hook: HookPostProcess, Err: err}))
+		}
+	}
+	return errs
+}
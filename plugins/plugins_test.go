@@ -0,0 +1,162 @@
+package plugins
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeExecutable writes an executable plugin with the supplied shell script as its body to a temporary directory
+func writeExecutable(t *testing.T, script string) string {
+	dir, err := ioutil.TempDir("", "git-xargs-plugin")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "plugin.sh")
+	require.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+func TestLoadExecutable(t *testing.T) {
+	t.Parallel()
+
+	path := writeExecutable(t, `
+case "$1" in
+  describe)
+    echo '{"hooks": ["select-repos", "post-process"]}' ;;
+  select-repos)
+    # Keep the repos of the request, and add one
+    repos=$(cat | sed 's/.*"repos":\[\(.*\)\].*/\1/')
+    echo "{\"repos\": [$repos, \"gruntwork-io/terratest\"]}" ;;
+  post-process)
+    echo "post-process failed" >&2
+    exit 1 ;;
+esac
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	set, err := Load([]string{path})
+	require.NoError(t, err)
+	assert.True(t, set.HasRepoSelectors())
+	assert.Empty(t, set.transforms)
+
+	repos, err := set.SelectRepos(context.Background(), SelectReposRequest{Repos: []string{"gruntwork-io/git-xargs"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gruntwork-io/git-xargs", "gruntwork-io/terratest"}, repos)
+
+	errs := set.PostProcess(context.Background(), PostProcessRequest{Repo: "gruntwork-io/git-xargs"})
+	require.Len(t, errs, 1)
+	pluginErr, ok := errors.Unwrap(errs[0]).(types.PluginErr)
+	require.True(t, ok)
+	assert.Equal(t, HookPostProcess, pluginErr.Hook)
+	assert.True(t, strings.Contains(pluginErr.Error(), "post-process failed"))
+}
+
+func TestLoadRejectsInvalidPlugins(t *testing.T) {
+	t.Parallel()
+
+	path := writeExecutable(t, `echo '{"hooks": ["rename-repos"]}'`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	_, err := Load([]string{path})
+	assert.IsType(t, types.InvalidPluginErr{}, errors.Unwrap(err))
+
+	_, err = Load([]string{"/does/not/exist"})
+	assert.IsType(t, types.InvalidPluginErr{}, errors.Unwrap(err))
+
+	set, err := Load(nil)
+	require.NoError(t, err)
+	assert.Nil(t, set)
+}
+
+type dropRepoSelector struct {
+	repo string
+}
+
+func (selector dropRepoSelector) SelectRepos(ctx context.Context, request SelectReposRequest) ([]string, error) {
+	repos := []string{}
+	for _, repo := range request.Repos {
+		if repo != selector.repo {
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+func TestSelectReposChainsSelectors(t *testing.T) {
+	t.Parallel()
+
+	set := &Set{}
+	require.NoError(t, set.Add("drop-one", dropRepoSelector{repo: "org/one"}))
+	require.NoError(t, set.Add("drop-two", dropRepoSelector{repo: "org/two"}))
+	assert.IsType(t, types.InvalidPluginErr{}, errors.Unwrap(set.Add("nothing", struct{}{})))
+
+	repos, err := set.SelectRepos(context.Background(), SelectReposRequest{Repos: []string{"org/one", "org/two", "org/three"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org/three"}, repos)
+
+	// A nil set keeps the repos as they are
+	var noPlugins *Set
+	repos, err = noPlugins.SelectRepos(context.Background(), SelectReposRequest{Repos: []string{"org/one"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"org/one"}, repos)
+}
+
+// Test that the events an executable replies to a hook with are tracked for the repo of the hook
+func TestExecutableTracksEvents(t *testing.T) {
+	t.Parallel()
+
+	path := writeExecutable(t, `
+case "$1" in
+  describe)
+    echo '{"hooks": ["transform", "post-process"]}' ;;
+  transform)
+    echo '{"events": [{"event": "needs-manual-review", "description": "Repos that need a manual review"}]}' ;;
+esac
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	set, err := Load([]string{path})
+	require.NoError(t, err)
+
+	tracked := []Event{}
+	trackEvent := func(event Event) error {
+		tracked = append(tracked, event)
+		return nil
+	}
+	require.NoError(t, set.Transform(context.Background(), TransformRequest{Repo: "gruntwork-io/git-xargs", TrackEvent: trackEvent}))
+	assert.Empty(t, set.PostProcess(context.Background(), PostProcessRequest{Repo: "gruntwork-io/git-xargs", TrackEvent: trackEvent}))
+	assert.Equal(t, []Event{{Event: "needs-manual-review", Description: "Repos that need a manual review"}}, tracked)
+
+	// Callers that don't track events can still run the hooks
+	require.NoError(t, set.Transform(context.Background(), TransformRequest{Repo: "gruntwork-io/git-xargs"}))
+}
+
+// Transform and post-process hooks don't need a reply, so output that isn't one, such as logs, is ignored
+func TestExecutableIgnoresOutputThatIsNotAReply(t *testing.T) {
+	t.Parallel()
+
+	path := writeExecutable(t, `
+case "$1" in
+  describe)
+    echo '{"hooks": ["transform", "post-process"]}' ;;
+  transform)
+    echo "Updating the CI config..." ;;
+  post-process)
+    echo "[1, 2, 3]" ;;
+esac
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	set, err := Load([]string{path})
+	require.NoError(t, err)
+
+	require.NoError(t, set.Transform(context.Background(), TransformRequest{Repo: "gruntwork-io/git-xargs"}))
+	assert.Empty(t, set.PostProcess(context.Background(), PostProcessRequest{Repo: "gruntwork-io/git-xargs"}))
+}
@@ -0,0 +1,58 @@
+package printer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// csvHeader is the header row of the CSV run report
+var csvHeader = []string{"Repo", "Repo URL", "Outcome", "Failure reason", "Error", "Skip reason", "Pull requests", "Draft pull requests", "Events", "Files changed", "Insertions", "Deletions"}
+
+// WriteCSVReport writes a row per repo with its outcome and pull requests, for tracking campaigns in a spreadsheet.
+// Repos with several pull requests list them separated by spaces
+func WriteCSVReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	for _, outcome := range repoOutcomes(allEvents, runReport) {
+		events := []string{}
+		for _, event := range outcome.Events {
+			events = append(events, string(event))
+		}
+
+		filesChanged, insertions, deletions := "", "", ""
+		if outcome.DiffStats != nil {
+			filesChanged = strconv.Itoa(outcome.DiffStats.FilesChanged)
+			insertions = strconv.Itoa(outcome.DiffStats.Insertions)
+			deletions = strconv.Itoa(outcome.DiffStats.Deletions)
+		}
+
+		row := []string{
+			outcome.Name,
+			outcome.URL,
+			outcome.Outcome,
+			string(outcome.FailureReason),
+			outcome.Error,
+			outcome.SkipReason,
+			strings.Join(outcome.PullRequestURLs, " "),
+			strings.Join(outcome.DraftPullRequestURLs, " "),
+			strings.Join(events, " "),
+			filesChanged,
+			insertions,
+			deletions,
+		}
+		if err := writer.Write(row); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+
+	writer.Flush()
+	return errors.WithStackTrace(writer.Error())
+}
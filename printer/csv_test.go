@@ -0,0 +1,28 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSVReport(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCSVReport(&buffer, allEvents, runReport))
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, []string{"gruntwork-io/fetch", "https://github.com/gruntwork-io/fetch", OutcomeFailed, "pull-request-invalid", "422 Validation Failed", "", "", "", "repo-successfully-cloned pull-request-open-error", "", "", ""}, rows[2])
+	assert.Equal(t, "https://github.com/gruntwork-io/terragrunt/pull/1 https://github.com/gruntwork-io/terragrunt/pull/2", rows[3][6])
+	assert.Equal(t, []string{"3", "12", "4"}, rows[3][9:])
+}
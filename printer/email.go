@@ -0,0 +1,53 @@
+package printer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// RenderEmail renders a summary of the run as the subject and plain text body of an email: how many repos were
+// processed, links to the pull requests opened, and the repos that failed along with their errors, grouped by the kind
+// of error they failed with
+func RenderEmail(allEvents []types.AnnotatedEvent, runReport *types.RunReport) (string, string) {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	run := "git-xargs run"
+	if runReport.RunID != "" {
+		run += " " + runReport.RunID
+	}
+	subject := fmt.Sprintf("%s: %d succeeded, %d failed, %d skipped", run, summary.Succeeded, summary.Failed, summary.Skipped)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s finished.\n\n", run)
+	if len(runReport.Command) > 0 {
+		fmt.Fprintf(&builder, "Command: %s\n\n", strings.Join(runReport.Command, " "))
+	}
+	fmt.Fprintf(&builder, "%d repos processed: %d succeeded, %d failed, %d skipped. %d pull requests and %d draft pull requests opened.\n", summary.Repos, summary.Succeeded, summary.Failed, summary.Skipped, summary.PullRequests, summary.DraftPullRequests)
+
+	pullRequests := []string{}
+	for _, outcome := range outcomes {
+		for _, url := range outcome.PullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("- %s #%s: %s", outcome.Name, path.Base(url), url))
+		}
+		for _, url := range outcome.DraftPullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("- %s #%s (draft): %s", outcome.Name, path.Base(url), url))
+		}
+	}
+	if len(pullRequests) > 0 {
+		fmt.Fprintf(&builder, "\nPull requests opened (%d):\n\n", len(pullRequests))
+		builder.WriteString(strings.Join(pullRequests, "\n") + "\n")
+	}
+
+	for _, group := range groupFailures(outcomes) {
+		fmt.Fprintf(&builder, "\nFailed: %s (%d):\n\n", group.Description, len(group.Repos))
+		for _, outcome := range group.Repos {
+			fmt.Fprintf(&builder, "- %s: %s\n", outcome.Name, strings.Join(strings.Fields(outcome.Error), " "))
+		}
+	}
+
+	return subject, builder.String()
+}
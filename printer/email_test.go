@@ -0,0 +1,25 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderEmail(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	subject, body := RenderEmail(allEvents, runReport)
+
+	assert.Equal(t, "git-xargs run run-1: 1 succeeded, 1 failed, 1 skipped", subject)
+	expected := "git-xargs run run-1 finished.\n\n" +
+		"Command: touch file\n\n" +
+		"3 repos processed: 1 succeeded, 1 failed, 1 skipped. 2 pull requests and 0 draft pull requests opened.\n" +
+		"\nPull requests opened (2):\n\n" +
+		"- gruntwork-io/terragrunt #1: https://github.com/gruntwork-io/terragrunt/pull/1\n" +
+		"- gruntwork-io/terragrunt #2: https://github.com/gruntwork-io/terragrunt/pull/2\n" +
+		"\nFailed: Pull request rejected with a 422 (1):\n\n" +
+		"- gruntwork-io/fetch: 422 Validation Failed\n"
+	assert.Equal(t, expected, body)
+}
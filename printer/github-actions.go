@@ -0,0 +1,53 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// WriteGithubActionsSummary writes the step summary of the run for GitHub Actions: the Markdown run report, without the
+// diffs of the changed repos
+func WriteGithubActionsSummary(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	return WriteMarkdownReport(w, allEvents, withoutDiffs(runReport))
+}
+
+// WriteGithubActionsOutputs writes the step outputs of the run in the format of the $GITHUB_OUTPUT file of GitHub
+// Actions. Each output is a JSON array, which later steps of the workflow can read with fromJSON:
+//
+//	pr_urls: the URLs of every pull request opened, including draft pull requests
+//	failed_repos: the full names of the repos that failed
+func WriteGithubActionsOutputs(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	pullRequestURLs := []string{}
+	failedRepos := []string{}
+	for _, outcome := range repoOutcomes(allEvents, runReport) {
+		pullRequestURLs = append(pullRequestURLs, outcome.PullRequestURLs...)
+		pullRequestURLs = append(pullRequestURLs, outcome.DraftPullRequestURLs...)
+		if outcome.Outcome == OutcomeFailed {
+			failedRepos = append(failedRepos, outcome.Name)
+		}
+	}
+	sort.Strings(pullRequestURLs)
+
+	outputs := []struct {
+		name   string
+		values []string
+	}{
+		{"pr_urls", pullRequestURLs},
+		{"failed_repos", failedRepos},
+	}
+	for _, output := range outputs {
+		value, err := json.Marshal(output.values)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", output.name, value); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGithubActionsOutputs(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteGithubActionsOutputs(&buffer, allEvents, runReport))
+
+	assert.Equal(t, `pr_urls=["https://github.com/gruntwork-io/terragrunt/pull/1","https://github.com/gruntwork-io/terragrunt/pull/2"]
+failed_repos=["gruntwork-io/fetch"]
+`, buffer.String())
+}
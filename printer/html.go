@@ -0,0 +1,138 @@
+package printer
+
+import (
+	"html/template"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// htmlReport holds the values rendered by htmlReportTemplate
+type htmlReport struct {
+	RunID          string
+	Command        string
+	SelectionMode  string
+	RuntimeSeconds int
+	Summary        reportSummary
+	Failures       []failureGroup
+	Repos          []types.RepoOutcome
+}
+
+// htmlReportTemplate renders the run report as a standalone page: the styles and the script that filters and sorts the
+// repos are inlined, so that the file can be shared as is
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pullRequestNumber": func(url string) string { return "#" + path.Base(url) },
+	"eventList": func(events []types.Event) string {
+		names := []string{}
+		for _, event := range events {
+			names = append(names, string(event))
+		}
+		return strings.Join(names, ", ")
+	},
+	"failureDescription": func(reason types.FailureReason) string {
+		for _, failureReason := range failureReasons {
+			if failureReason.reason == reason {
+				return failureReason.description
+			}
+		}
+		return string(reason)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>git-xargs run{{if .RunID}} {{.RunID}}{{end}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+.outcome-succeeded { color: #1a7f37; }
+.outcome-failed { color: #cf222e; }
+.outcome-skipped { color: #9a6700; }
+.filters button { margin-right: 4px; }
+.filters button.active { font-weight: bold; }
+pre { background: #f6f8fa; padding: 8px; overflow-x: auto; max-height: 40em; }
+.events { color: #57606a; font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>git-xargs run{{if .RunID}} <code>{{.RunID}}</code>{{end}}</h1>
+{{if .Command}}<p>Command: <code>{{.Command}}</code></p>{{end}}
+<p>{{.Summary.Repos}} repos{{if .SelectionMode}} selected via {{.SelectionMode}}{{end}}: <span class="outcome-succeeded">{{.Summary.Succeeded}} succeeded</span>, <span class="outcome-failed">{{.Summary.Failed}} failed</span>, <span class="outcome-skipped">{{.Summary.Skipped}} skipped</span>. {{.Summary.PullRequests}} pull requests and {{.Summary.DraftPullRequests}} draft pull requests opened in {{.RuntimeSeconds}} seconds.</p>
+{{if .Failures}}<p>Failures by reason:</p>
+<ul>
+{{range .Failures}}<li class="outcome-failed">{{.Description}}: {{len .Repos}}</li>
+{{end}}</ul>{{end}}
+<p class="filters">
+<button type="button" class="active" data-filter="all">All</button>
+<button type="button" data-filter="succeeded">Succeeded</button>
+<button type="button" data-filter="failed">Failed</button>
+<button type="button" data-filter="skipped">Skipped</button>
+</p>
+<table id="repos">
+<thead>
+<tr><th data-column="0">Repo</th><th data-column="1">Outcome</th><th data-column="2">Details</th><th data-column="3">Pull requests</th></tr>
+</thead>
+<tbody>
+{{range .Repos}}<tr data-outcome="{{.Outcome}}">
+<td>{{if .URL}}<a href="{{.URL}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td>
+<td class="outcome-{{.Outcome}}">{{.Outcome}}</td>
+<td>{{if .Error}}<strong>{{failureDescription .FailureReason}}</strong>: {{.Error}}{{else}}{{.SkipReason}}{{end}}
+<div class="events">{{eventList .Events}}</div>
+{{if .Diff}}<details><summary>Diff</summary><pre>{{.Diff}}</pre></details>{{end}}</td>
+<td>{{range .PullRequestURLs}}<a href="{{.}}">{{pullRequestNumber .}}</a> {{end}}{{range .DraftPullRequestURLs}}<a href="{{.}}">{{pullRequestNumber .}}</a> (draft) {{end}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<script>
+(function () {
+  var rows = Array.prototype.slice.call(document.querySelectorAll("#repos tbody tr"));
+  var buttons = document.querySelectorAll(".filters button");
+  Array.prototype.forEach.call(buttons, function (button) {
+    button.addEventListener("click", function () {
+      Array.prototype.forEach.call(buttons, function (b) { b.classList.remove("active"); });
+      button.classList.add("active");
+      var filter = button.getAttribute("data-filter");
+      rows.forEach(function (row) {
+        row.style.display = filter === "all" || row.getAttribute("data-outcome") === filter ? "" : "none";
+      });
+    });
+  });
+  var ascending = {};
+  Array.prototype.forEach.call(document.querySelectorAll("#repos th"), function (header) {
+    header.addEventListener("click", function () {
+      var column = header.getAttribute("data-column");
+      ascending[column] = !ascending[column];
+      rows.sort(function (a, b) {
+        var x = a.cells[column].textContent.trim(), y = b.cells[column].textContent.trim();
+        return ascending[column] ? x.localeCompare(y) : y.localeCompare(x);
+      });
+      var body = document.querySelector("#repos tbody");
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes the run report as a standalone HTML page, in which the repos can be filtered by outcome and
+// sorted, along with the diff of the changes made to each repo and links to its pull requests
+func WriteHTMLReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	outcomes := repoOutcomes(allEvents, runReport)
+	report := htmlReport{
+		RunID:          runReport.RunID,
+		Command:        strings.Join(runReport.Command, " "),
+		SelectionMode:  runReport.SelectionMode,
+		RuntimeSeconds: runReport.RuntimeSeconds,
+		Summary:        summarize(outcomes, runReport),
+		Failures:       groupFailures(outcomes),
+		Repos:          outcomes,
+	}
+	return errors.WithStackTrace(htmlReportTemplate.Execute(w, report))
+}
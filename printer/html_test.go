@@ -0,0 +1,29 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Errors["gruntwork-io/fetch"] = "<script>alert(1)</script>"
+	runReport.Diffs = map[string]string{"gruntwork-io/terragrunt": "diff --git a/file b/file\n+new line\n"}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteHTMLReport(&buffer, allEvents, runReport))
+	page := buffer.String()
+
+	assert.Contains(t, page, `<tr data-outcome="succeeded">`)
+	assert.Contains(t, page, `<tr data-outcome="failed">`)
+	assert.Contains(t, page, `<tr data-outcome="skipped">`)
+	assert.Contains(t, page, `<a href="https://github.com/gruntwork-io/terragrunt/pull/2">#2</a>`)
+	assert.Contains(t, page, "<pre>diff --git a/file b/file\n&#43;new line\n</pre>")
+	assert.Contains(t, page, "&lt;script&gt;alert(1)&lt;/script&gt;")
+	assert.NotContains(t, page, "<script>alert(1)</script>")
+}
@@ -0,0 +1,89 @@
+package printer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// jiraEscaper escapes the characters that Jira's wiki markup treats as markup, so that errors and commands are shown as
+// they are
+var jiraEscaper = strings.NewReplacer(
+	"\\", "\\\\", "[", "\\[", "]", "\\]", "{", "\\{", "}", "\\}", "|", "\\|", "*", "\\*", "_", "\\_", "^", "\\^",
+	"~", "\\~", "+", "\\+", "-", "\\-", "!", "\\!",
+)
+
+// RenderJiraComment renders the run report as Jira wiki markup, which is what comments posted via version 2 of the Jira
+// REST API are written in, with the repos grouped by outcome and links to their pull requests
+func RenderJiraComment(allEvents []types.AnnotatedEvent, runReport *types.RunReport) string {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	var builder strings.Builder
+	builder.WriteString("h2. git-xargs run")
+	if runReport.RunID != "" {
+		fmt.Fprintf(&builder, " {{%s}}", jiraEscaper.Replace(runReport.RunID))
+	}
+	builder.WriteString("\n\n")
+	if len(runReport.Command) > 0 {
+		fmt.Fprintf(&builder, "Command: {{%s}}\n\n", jiraEscaper.Replace(strings.Join(runReport.Command, " ")))
+	}
+	fmt.Fprintf(&builder, "%d repos: %d succeeded, %d failed, %d skipped. %d pull requests and %d draft pull requests opened.\n", summary.Repos, summary.Succeeded, summary.Failed, summary.Skipped, summary.PullRequests, summary.DraftPullRequests)
+
+	for _, section := range markdownSections {
+		lines := []string{}
+		for _, outcome := range outcomes {
+			if outcome.Outcome == section.outcome {
+				lines = append(lines, jiraRepoLine(outcome))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\nh3. %s (%d)\n", section.title, len(lines))
+
+		// Failed repos are grouped by the kind of error they failed with, like in the Markdown report
+		if section.outcome == OutcomeFailed {
+			for _, group := range groupFailures(outcomes) {
+				fmt.Fprintf(&builder, "\nh4. %s (%d)\n\n", jiraEscaper.Replace(group.Description), len(group.Repos))
+				for _, outcome := range group.Repos {
+					builder.WriteString(jiraRepoLine(outcome) + "\n")
+				}
+			}
+			continue
+		}
+
+		builder.WriteString("\n")
+		for _, line := range lines {
+			builder.WriteString(line + "\n")
+		}
+	}
+	return builder.String()
+}
+
+// jiraRepoLine renders a repo as a list item linking to the repo and its pull requests, followed by its error or the
+// reason it was skipped
+func jiraRepoLine(outcome types.RepoOutcome) string {
+	line := fmt.Sprintf("* [%s|%s]", jiraEscaper.Replace(outcome.Name), outcome.URL)
+
+	links := []string{}
+	for _, url := range outcome.PullRequestURLs {
+		links = append(links, fmt.Sprintf("[#%s|%s]", path.Base(url), url))
+	}
+	for _, url := range outcome.DraftPullRequestURLs {
+		links = append(links, fmt.Sprintf("[#%s|%s] (draft)", path.Base(url), url))
+	}
+	if len(links) > 0 {
+		line += ": " + strings.Join(links, ", ")
+	}
+
+	switch {
+	case outcome.Error != "":
+		line += ": " + jiraEscaper.Replace(strings.Join(strings.Fields(outcome.Error), " "))
+	case outcome.SkipReason != "":
+		line += ": " + jiraEscaper.Replace(outcome.SkipReason)
+	}
+	return line
+}
@@ -0,0 +1,21 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderJiraComment(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Errors["gruntwork-io/fetch"] = "branch [main] not found"
+
+	comment := RenderJiraComment(allEvents, runReport)
+	assert.Contains(t, comment, "h2. git-xargs run {{run\\-1}}\n")
+	assert.Contains(t, comment, "Command: {{touch file}}\n")
+	assert.Contains(t, comment, "\nh3. Succeeded (1)\n\n* [gruntwork\\-io/terragrunt|https://github.com/gruntwork-io/terragrunt]: [#1|https://github.com/gruntwork-io/terragrunt/pull/1], [#2|https://github.com/gruntwork-io/terragrunt/pull/2]\n")
+	assert.Contains(t, comment, "* [gruntwork\\-io/fetch|https://github.com/gruntwork-io/fetch]: branch \\[main\\] not found\n")
+	assert.NotContains(t, comment, "## ")
+}
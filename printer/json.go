@@ -0,0 +1,71 @@
+package printer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// jsonReport is the run report written by --output json
+type jsonReport struct {
+	RunID          string   `json:"run_id,omitempty"`
+	Command        []string `json:"command"`
+	SelectionMode  string   `json:"selection_mode"`
+	RuntimeSeconds int      `json:"runtime_seconds"`
+	APIRetries     uint64   `json:"api_retries"`
+	APICalls       uint64   `json:"api_calls"`
+	// APICallsByCategory and RateLimits are omitted when the run sent no requests to the GitHub API
+	APICallsByCategory map[string]uint64                `json:"api_calls_by_category,omitempty"`
+	RateLimits         map[string]types.RateLimitStatus `json:"rate_limits,omitempty"`
+	Summary            reportSummary                    `json:"summary"`
+	// FailuresByReason counts the repos that failed with each kind of error
+	FailuresByReason map[types.FailureReason]int `json:"failures_by_reason"`
+	Repos            []types.RepoOutcome         `json:"repos"`
+}
+
+// WriteJSONReport writes the run report as a JSON document with an outcome per repo, for CI pipelines and other tools
+// to parse
+func WriteJSONReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.WithStackTrace(encoder.Encode(newJSONReport(allEvents, runReport)))
+}
+
+// WriteWebhookPayload writes the run report POSTed to --webhook-url: the JSON report, without the diffs of the changed
+// repos, and without the events tracked for each repo unless includeEvents is set
+func WriteWebhookPayload(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport, includeEvents bool) error {
+	report := newJSONReport(allEvents, withoutDiffs(runReport))
+	if !includeEvents {
+		for i := range report.Repos {
+			report.Repos[i].Events = nil
+		}
+	}
+	return errors.WithStackTrace(json.NewEncoder(w).Encode(report))
+}
+
+// newJSONReport builds the JSON report of the supplied run
+func newJSONReport(allEvents []types.AnnotatedEvent, runReport *types.RunReport) jsonReport {
+	outcomes := repoOutcomes(allEvents, runReport)
+	report := jsonReport{
+		RunID:              runReport.RunID,
+		Command:            runReport.Command,
+		SelectionMode:      runReport.SelectionMode,
+		RuntimeSeconds:     runReport.RuntimeSeconds,
+		APIRetries:         runReport.APIRetries,
+		APICalls:           runReport.APICalls,
+		APICallsByCategory: runReport.APICallsByCategory,
+		RateLimits:         runReport.RateLimits,
+		Summary:            summarize(outcomes, runReport),
+		Repos:              outcomes,
+	}
+	if report.Command == nil {
+		report.Command = []string{}
+	}
+	report.FailuresByReason = map[types.FailureReason]int{}
+	for _, group := range groupFailures(outcomes) {
+		report.FailuresByReason[group.Reason] = len(group.Repos)
+	}
+	return report
+}
@@ -0,0 +1,78 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSONReport(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.APICalls = 7
+	runReport.APICallsByCategory = map[string]uint64{"pulls": 5, "repos": 2}
+	runReport.RateLimits = map[string]types.RateLimitStatus{"core": {Limit: 5000, Remaining: 4993}}
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteJSONReport(&buffer, allEvents, runReport))
+
+	report := jsonReport{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &report))
+
+	assert.Equal(t, "run-1", report.RunID)
+	assert.Equal(t, reportSummary{Repos: 3, Succeeded: 1, Failed: 1, Skipped: 1, PullRequests: 2}, report.Summary)
+	assert.Equal(t, uint64(7), report.APICalls)
+	assert.Equal(t, uint64(5), report.APICallsByCategory["pulls"])
+	assert.Equal(t, 4993, report.RateLimits["core"].Remaining)
+	require.Len(t, report.Repos, 3)
+
+	assert.Equal(t, "gruntwork-io/cloud-nuke", report.Repos[0].Name)
+	assert.Equal(t, OutcomeSkipped, report.Repos[0].Outcome)
+	assert.Equal(t, "Repos that were skipped because a pull request was already open", report.Repos[0].SkipReason)
+
+	assert.Equal(t, "gruntwork-io/fetch", report.Repos[1].Name)
+	assert.Equal(t, OutcomeFailed, report.Repos[1].Outcome)
+	assert.Equal(t, "422 Validation Failed", report.Repos[1].Error)
+	assert.Equal(t, types.FailurePullRequestInvalid, report.Repos[1].FailureReason)
+	assert.Equal(t, map[types.FailureReason]int{types.FailurePullRequestInvalid: 1}, report.FailuresByReason)
+
+	assert.Equal(t, "gruntwork-io/terragrunt", report.Repos[2].Name)
+	assert.Equal(t, OutcomeSucceeded, report.Repos[2].Outcome)
+	assert.Len(t, report.Repos[2].PullRequestURLs, 2)
+}
+
+func TestWriteWebhookPayload(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	var withoutEvents bytes.Buffer
+	require.NoError(t, WriteWebhookPayload(&withoutEvents, allEvents, runReport, false))
+	report := jsonReport{}
+	require.NoError(t, json.Unmarshal(withoutEvents.Bytes(), &report))
+	require.Len(t, report.Repos, 3)
+	assert.Equal(t, reportSummary{Repos: 3, Succeeded: 1, Failed: 1, Skipped: 1, PullRequests: 2}, report.Summary)
+	assert.Empty(t, report.Repos[1].Events)
+	assert.NotContains(t, withoutEvents.String(), `"events"`)
+
+	var withEvents bytes.Buffer
+	require.NoError(t, WriteWebhookPayload(&withEvents, allEvents, runReport, true))
+	report = jsonReport{}
+	require.NoError(t, json.Unmarshal(withEvents.Bytes(), &report))
+	assert.Len(t, report.Repos[1].Events, 2)
+}
+
+func TestPreviewDiff(t *testing.T) {
+	t.Parallel()
+
+	diff := "line 1\nline 2\nline 3\n"
+	assert.Equal(t, diff, previewDiff(diff, 0))
+	assert.Equal(t, diff, previewDiff(diff, 3))
+	assert.Equal(t, "line 1\n... 2 more lines\n", previewDiff(diff, 1))
+	assert.Equal(t, "line 1\nline 2\n... 1 more line\n", previewDiff(diff, 2))
+}
@@ -0,0 +1,99 @@
+package printer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// junitTestSuites is the root element of the JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of a run, one per repo
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     int             `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is the outcome of a single repo
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+// junitMessage is the failure or skip reason of a test case
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the run report as JUnit XML, with a test case per repo, so that CI systems can render the run
+// as a test report. Failed repos are reported as failures with their error, and skipped repos as skipped tests
+func WriteJUnitReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	suiteName := "git-xargs"
+	if runReport.RunID != "" {
+		suiteName = fmt.Sprintf("git-xargs run %s", runReport.RunID)
+	}
+	suite := junitTestSuite{
+		Name:     suiteName,
+		Tests:    summary.Repos,
+		Failures: summary.Failed,
+		Skipped:  summary.Skipped,
+		Time:     runReport.RuntimeSeconds,
+		Cases:    []junitTestCase{},
+	}
+
+	for _, outcome := range outcomes {
+		events := []string{}
+		for _, event := range outcome.Events {
+			events = append(events, string(event))
+		}
+		pullRequests := append(append([]string{}, outcome.PullRequestURLs...), outcome.DraftPullRequestURLs...)
+
+		systemOut := "Events: " + strings.Join(events, ", ")
+		if len(pullRequests) > 0 {
+			systemOut += "\nPull requests: " + strings.Join(pullRequests, ", ")
+		}
+
+		testCase := junitTestCase{
+			Name:      outcome.Name,
+			ClassName: "git-xargs",
+			SystemOut: systemOut,
+		}
+		switch outcome.Outcome {
+		case OutcomeFailed:
+			testCase.Failure = &junitMessage{Message: outcome.Error, Text: outcome.Error}
+		case OutcomeSkipped:
+			testCase.Skipped = &junitMessage{Message: outcome.SkipReason}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return errors.WithStackTrace(err)
+}
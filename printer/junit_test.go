@@ -0,0 +1,43 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteJUnitReport(&buffer, allEvents, runReport))
+
+	report := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buffer.Bytes(), &report))
+	require.Len(t, report.Suites, 1)
+
+	suite := report.Suites[0]
+	assert.Equal(t, "git-xargs run run-1", suite.Name)
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	require.Len(t, suite.Cases, 3)
+
+	assert.Equal(t, "gruntwork-io/cloud-nuke", suite.Cases[0].Name)
+	require.NotNil(t, suite.Cases[0].Skipped)
+	assert.Nil(t, suite.Cases[0].Failure)
+
+	assert.Equal(t, "gruntwork-io/fetch", suite.Cases[1].Name)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, "422 Validation Failed", suite.Cases[1].Failure.Message)
+
+	assert.Equal(t, "gruntwork-io/terragrunt", suite.Cases[2].Name)
+	assert.Nil(t, suite.Cases[2].Failure)
+	assert.Nil(t, suite.Cases[2].Skipped)
+	assert.Contains(t, suite.Cases[2].SystemOut, "https://github.com/gruntwork-io/terragrunt/pull/2")
+}
@@ -0,0 +1,41 @@
+package printer
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/version"
+)
+
+// NewRunManifest builds the --output-manifest of the supplied run, with the supplied snapshot of its config. Repos are
+// listed in the order of their names, like in the other structured reports
+func NewRunManifest(allEvents []types.AnnotatedEvent, runReport *types.RunReport, config manifest.Config) *manifest.Manifest {
+	runManifest := &manifest.Manifest{
+		FormatVersion:   manifest.FormatVersion,
+		RunID:           runReport.RunID,
+		GitXargsVersion: version.Summary(),
+		Command:         runReport.Command,
+		StartedAt:       runReport.StartedAt.UTC(),
+		FinishedAt:      time.Now().UTC(),
+		Config:          config,
+		Repos:           []manifest.Repo{},
+	}
+
+	for _, outcome := range repoOutcomes(allEvents, runReport) {
+		branches := runReport.Branches[outcome.Key]
+		if branches == nil {
+			branches = []types.PushedBranch{}
+		}
+		runManifest.Repos = append(runManifest.Repos, manifest.Repo{
+			Name:       outcome.Name,
+			URL:        outcome.URL,
+			Outcome:    outcome.Outcome,
+			Error:      outcome.Error,
+			SkipReason: outcome.SkipReason,
+			Events:     outcome.Events,
+			Branches:   branches,
+		})
+	}
+	return runManifest
+}
@@ -0,0 +1,32 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/manifest"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRunManifest(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Branches = map[string][]types.PushedBranch{
+		"gruntwork-io/terragrunt": {{Name: "update-ci", CommitSHA: "5555555555555555555555555555555555555555", PullRequestURL: "https://github.com/gruntwork-io/terragrunt/pull/1", PullRequestNumber: 1}},
+	}
+
+	runManifest := NewRunManifest(allEvents, runReport, manifest.Config{BranchName: "update-ci"})
+	assert.Equal(t, manifest.FormatVersion, runManifest.FormatVersion)
+	assert.Equal(t, "run-1", runManifest.RunID)
+	assert.Equal(t, "update-ci", runManifest.Config.BranchName)
+
+	require.Len(t, runManifest.Repos, 3)
+	assert.Equal(t, "gruntwork-io/cloud-nuke", runManifest.Repos[0].Name)
+	assert.Equal(t, OutcomeSkipped, runManifest.Repos[0].Outcome)
+	assert.Empty(t, runManifest.Repos[0].Branches)
+	assert.Equal(t, OutcomeFailed, runManifest.Repos[1].Outcome)
+	assert.Equal(t, "422 Validation Failed", runManifest.Repos[1].Error)
+	assert.Equal(t, runReport.Branches["gruntwork-io/terragrunt"], runManifest.Repos[2].Branches)
+}
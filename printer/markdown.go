@@ -0,0 +1,122 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// markdownSections are the outcomes the Markdown report groups repos by, in the order they are rendered
+var markdownSections = []struct {
+	outcome string
+	title   string
+}{
+	{OutcomeSucceeded, "Succeeded"},
+	{OutcomeFailed, "Failed"},
+	{OutcomeSkipped, "Skipped"},
+}
+
+// WriteMarkdownReport writes the run report as Markdown, with the repos grouped by outcome and links to their pull
+// requests, ready to be pasted into a GitHub issue or a chat message
+func WriteMarkdownReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) error {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	var builder strings.Builder
+	builder.WriteString("## git-xargs run")
+	if runReport.RunID != "" {
+		fmt.Fprintf(&builder, " `%s`", runReport.RunID)
+	}
+	builder.WriteString("\n\n")
+	if len(runReport.Command) > 0 {
+		fmt.Fprintf(&builder, "Command: `%s`\n\n", strings.Join(runReport.Command, " "))
+	}
+	fmt.Fprintf(&builder, "%d repos: %d succeeded, %d failed, %d skipped. %d pull requests and %d draft pull requests opened.\n", summary.Repos, summary.Succeeded, summary.Failed, summary.Skipped, summary.PullRequests, summary.DraftPullRequests)
+
+	repoLine := func(outcome types.RepoOutcome) string {
+		line := markdownRepoLine(outcome)
+		if runReport.DiffPreviewLines > 0 && outcome.Diff != "" {
+			line += "\n" + markdownDiffPreview(outcome.Diff)
+		}
+		return line
+	}
+
+	for _, section := range markdownSections {
+		lines := []string{}
+		for _, outcome := range outcomes {
+			if outcome.Outcome == section.outcome {
+				lines = append(lines, repoLine(outcome))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n### %s (%d)\n", section.title, len(lines))
+
+		// Failed repos are grouped by the kind of error they failed with, so that the most common reasons stand out
+		if section.outcome == OutcomeFailed {
+			for _, group := range groupFailures(outcomes) {
+				fmt.Fprintf(&builder, "\n#### %s (%d)\n\n", group.Description, len(group.Repos))
+				for _, outcome := range group.Repos {
+					builder.WriteString(repoLine(outcome) + "\n")
+				}
+			}
+			continue
+		}
+
+		builder.WriteString("\n")
+		for _, line := range lines {
+			builder.WriteString(line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return errors.WithStackTrace(err)
+}
+
+// markdownDiffPreview renders the supplied diff as a collapsed block nested in the list item of its repo. The diff is
+// fenced with more backticks than it contains in a row, so that backticks in the changed files can't end the block
+func markdownDiffPreview(diff string) string {
+	fence := "```"
+	for strings.Contains(diff, fence) {
+		fence += "`"
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\n  <details><summary>Diff</summary>\n\n")
+	builder.WriteString("  " + fence + "diff\n")
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		builder.WriteString("  " + line + "\n")
+	}
+	builder.WriteString("  " + fence + "\n\n  </details>\n")
+	return builder.String()
+}
+
+// markdownRepoLine renders a repo as a list item linking to the repo and its pull requests, followed by its error or
+// the reason it was skipped
+func markdownRepoLine(outcome types.RepoOutcome) string {
+	line := fmt.Sprintf("- [%s](%s)", outcome.Name, outcome.URL)
+
+	links := []string{}
+	for _, url := range outcome.PullRequestURLs {
+		links = append(links, fmt.Sprintf("[#%s](%s)", path.Base(url), url))
+	}
+	for _, url := range outcome.DraftPullRequestURLs {
+		links = append(links, fmt.Sprintf("[#%s](%s) (draft)", path.Base(url), url))
+	}
+	if len(links) > 0 {
+		line += ": " + strings.Join(links, ", ")
+	}
+
+	switch {
+	case outcome.Error != "":
+		line += ": " + strings.Join(strings.Fields(outcome.Error), " ")
+	case outcome.SkipReason != "":
+		line += ": " + outcome.SkipReason
+	}
+	return line
+}
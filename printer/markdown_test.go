@@ -0,0 +1,54 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdownReport(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteMarkdownReport(&buffer, allEvents, runReport))
+
+	expected := "## git-xargs run `run-1`\n\n" +
+		"Command: `touch file`\n\n" +
+		"3 repos: 1 succeeded, 1 failed, 1 skipped. 2 pull requests and 0 draft pull requests opened.\n" +
+		"\n### Succeeded (1)\n\n" +
+		"- [gruntwork-io/terragrunt](https://github.com/gruntwork-io/terragrunt): [#1](https://github.com/gruntwork-io/terragrunt/pull/1), [#2](https://github.com/gruntwork-io/terragrunt/pull/2)\n" +
+		"\n### Failed (1)\n\n" +
+		"#### Pull request rejected with a 422 (1)\n\n" +
+		"- [gruntwork-io/fetch](https://github.com/gruntwork-io/fetch): 422 Validation Failed\n" +
+		"\n### Skipped (1)\n\n" +
+		"- [gruntwork-io/cloud-nuke](https://github.com/gruntwork-io/cloud-nuke): Repos that were skipped because a pull request was already open\n"
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestWriteMarkdownReportWithDiffPreviews(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Diffs = map[string]string{"gruntwork-io/terragrunt": "diff --git a/README.md b/README.md\n+```\n-old\n+new\n"}
+	runReport.DiffPreviewLines = 3
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteMarkdownReport(&buffer, allEvents, runReport))
+
+	expected := "\n### Succeeded (1)\n\n" +
+		"- [gruntwork-io/terragrunt](https://github.com/gruntwork-io/terragrunt): [#1](https://github.com/gruntwork-io/terragrunt/pull/1), [#2](https://github.com/gruntwork-io/terragrunt/pull/2)\n" +
+		"\n  <details><summary>Diff</summary>\n\n" +
+		"  ````diff\n" +
+		"  diff --git a/README.md b/README.md\n" +
+		"  +```\n" +
+		"  -old\n" +
+		"  ... 1 more line\n" +
+		"  ````\n\n" +
+		"  </details>\n\n" +
+		"\n### Failed (1)\n"
+	assert.Contains(t, buffer.String(), expected)
+}
@@ -2,10 +2,13 @@ package printer
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/state"
 	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/kataras/tablewriter"
 	"github.com/landoop/tableprinter"
@@ -22,30 +25,43 @@ func configurePrinterStyling(printer *tableprinter.Printer) {
 	printer.HeaderFgColor = tablewriter.FgGreenColor
 }
 
-func PrintRepoReport(allEvents []types.AnnotatedEvent, runReport *types.RunReport) {
-	fmt.Print("\n\n")
-	fmt.Println("*****************************************************************")
-	fmt.Printf("  GIT-XARGS RUN SUMMARY @ %v\n", time.Now().UTC())
-	fmt.Printf("  Runtime in seconds: %v\n", runReport.RuntimeSeconds)
-	fmt.Println("*****************************************************************")
+func PrintRepoReport(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport) {
+	fmt.Fprint(w, "\n\n")
+	fmt.Fprintln(w, "*****************************************************************")
+	fmt.Fprintf(w, "  GIT-XARGS RUN SUMMARY @ %v\n", time.Now().UTC())
+	fmt.Fprintf(w, "  Runtime in seconds: %v\n", runReport.RuntimeSeconds)
+	if runReport.RunID != "" {
+		fmt.Fprintf(w, "  Run ID: %s\n", runReport.RunID)
+	}
+	if runReport.APIRetries > 0 {
+		fmt.Fprintf(w, "  GitHub API requests retried: %d\n", runReport.APIRetries)
+	}
+	if runReport.APICalls > 0 {
+		fmt.Fprintf(w, "  GitHub API calls: %d\n", runReport.APICalls)
+	}
+	for _, resource := range rateLimitResources(runReport.RateLimits) {
+		rateLimit := runReport.RateLimits[resource]
+		fmt.Fprintf(w, "  GitHub API rate limit left (%s): %d of %d, resets at %v\n", resource, rateLimit.Remaining, rateLimit.Limit, rateLimit.Reset.UTC())
+	}
+	fmt.Fprintln(w, "*****************************************************************")
 
 	// If there were any allowed repos provided via file, print out the list of them
-	fileProvidedReposPrinter := tableprinter.New(os.Stdout)
+	fileProvidedReposPrinter := tableprinter.New(w)
 	configurePrinterStyling(fileProvidedReposPrinter)
 
-	fmt.Print("\n\n")
+	fmt.Fprint(w, "\n\n")
 
-	fmt.Println("COMMAND SUPPLIED")
-	fmt.Println()
-	fmt.Println(runReport.Command)
-	fmt.Println()
-	fmt.Println("REPO SELECTION METHOD USED FOR THIS RUN - (see README.md for more information)")
-	fmt.Println()
-	fmt.Println(runReport.SelectionMode)
+	fmt.Fprintln(w, "COMMAND SUPPLIED")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, runReport.Command)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "REPO SELECTION METHOD USED FOR THIS RUN - (see README.md for more information)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, runReport.SelectionMode)
 
 	// If the user selected repos via a flat file, print a table showing which repos they were
 	if len(runReport.FileProvidedRepos) > 0 {
-		fmt.Println(" REPOS SUPPLIED VIA --repos FILE FLAG")
+		fmt.Fprintln(w, " REPOS SUPPLIED VIA --repos FILE FLAG")
 		fileProvidedReposPrinter.Print(runReport.FileProvidedRepos)
 	}
 	// For each event type, print a summary of the repos in that category
@@ -53,7 +69,7 @@ func PrintRepoReport(allEvents []types.AnnotatedEvent, runReport *types.RunRepor
 
 		var reducedRepos []types.ReducedRepo
 
-		printer := tableprinter.New(os.Stdout)
+		printer := tableprinter.New(w)
 		configurePrinterStyling(printer)
 
 		for _, repo := range runReport.Repos[ae.Event] {
@@ -65,13 +81,24 @@ func PrintRepoReport(allEvents []types.AnnotatedEvent, runReport *types.RunRepor
 		}
 
 		if len(reducedRepos) > 0 {
-			fmt.Println()
-			fmt.Printf(" %s\n", strings.ToUpper(ae.Description))
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, " %s\n", strings.ToUpper(ae.Description))
 			printer.Print(reducedRepos)
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 	}
 
+	if failures := failuresByReason(repoOutcomes(allEvents, runReport)); len(failures) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintln(w, "  FAILURES BY REASON")
+		fmt.Fprintln(w, "*****************************************************")
+		failuresPrinter := tableprinter.New(w)
+		configurePrinterStyling(failuresPrinter)
+		failuresPrinter.Print(failures)
+		fmt.Fprintln(w)
+	}
+
 	var pullRequests []types.PullRequest
 
 	for repoName, prURL := range runReport.PullRequests {
@@ -93,26 +120,321 @@ func PrintRepoReport(allEvents []types.AnnotatedEvent, runReport *types.RunRepor
 	}
 
 	if len(pullRequests) > 0 {
-		fmt.Println()
-		fmt.Println("*****************************************************")
-		fmt.Println("  PULL REQUESTS OPENED")
-		fmt.Println("*****************************************************")
-		pullRequestPrinter := tableprinter.New(os.Stdout)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintln(w, "  PULL REQUESTS OPENED")
+		fmt.Fprintln(w, "*****************************************************")
+		pullRequestPrinter := tableprinter.New(w)
 		configurePrinterStyling(pullRequestPrinter)
 		pullRequestPrinter.Print(pullRequests)
-		fmt.Println()
+		fmt.Fprintln(w)
 
 	}
 
 	if len(draftPullRequests) > 0 {
-		fmt.Println()
-		fmt.Println("*****************************************************")
-		fmt.Println("  DRAFT PULL REQUESTS OPENED")
-		fmt.Println("*****************************************************")
-		pullRequestPrinter := tableprinter.New(os.Stdout)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintln(w, "  DRAFT PULL REQUESTS OPENED")
+		fmt.Fprintln(w, "*****************************************************")
+		pullRequestPrinter := tableprinter.New(w)
 		configurePrinterStyling(pullRequestPrinter)
 		pullRequestPrinter.Print(draftPullRequests)
+		fmt.Fprintln(w)
+
+	}
+
+	if repoChanges := changesPerRepo(runReport.DiffStats); len(repoChanges) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintln(w, "  CHANGES PER REPO")
+		fmt.Fprintln(w, "*****************************************************")
+		repoChangesPrinter := tableprinter.New(w)
+		configurePrinterStyling(repoChangesPrinter)
+		repoChangesPrinter.Print(repoChanges)
+		fmt.Fprintln(w)
+	}
+
+	if slowRepos := slowestRepos(runReport.Durations, slowestReposCount); len(slowRepos) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintf(w, "  SLOWEST REPOS (TOP %d)\n", slowestReposCount)
+		fmt.Fprintln(w, "*****************************************************")
+		slowReposPrinter := tableprinter.New(w)
+		configurePrinterStyling(slowReposPrinter)
+		slowReposPrinter.Print(slowRepos)
+		fmt.Fprintln(w)
+	}
+
+	if categories := apiCallsByCategory(runReport.APICallsByCategory); len(categories) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "*****************************************************")
+		fmt.Fprintln(w, "  GITHUB API CALLS BY ENDPOINT CATEGORY")
+		fmt.Fprintln(w, "*****************************************************")
+		categoriesPrinter := tableprinter.New(w)
+		configurePrinterStyling(categoriesPrinter)
+		categoriesPrinter.Print(categories)
+		fmt.Fprintln(w)
+	}
+}
+
+// failuresByReason returns the number and names of the repos that failed with each kind of error, in the order of
+// failureReasons
+func failuresByReason(outcomes []types.RepoOutcome) []types.FailureReasonCount {
+	failures := []types.FailureReasonCount{}
+	for _, group := range groupFailures(outcomes) {
+		names := []string{}
+		for _, outcome := range group.Repos {
+			names = append(names, outcome.Name)
+		}
+		failures = append(failures, types.FailureReasonCount{
+			Reason: group.Description,
+			Count:  len(group.Repos),
+			Repos:  strings.Join(names, ", "),
+		})
+	}
+	return failures
+}
+
+// rateLimitResources returns the resources of the supplied rate limits, sorted, so that they are always printed in the
+// same order
+func rateLimitResources(rateLimits map[string]types.RateLimitStatus) []string {
+	resources := []string{}
+	for resource := range rateLimits {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// apiCallsByCategory returns the GitHub API calls made to each category of endpoints, with the categories that took the
+// most calls first, so that operators planning back-to-back runs can see where the quota went
+func apiCallsByCategory(byCategory map[string]uint64) []types.APICallCategory {
+	categories := []types.APICallCategory{}
+	for category, calls := range byCategory {
+		categories = append(categories, types.APICallCategory{Category: category, Calls: calls})
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Calls == categories[j].Calls {
+			return categories[i].Category < categories[j].Category
+		}
+		return categories[i].Calls > categories[j].Calls
+	})
+	return categories
+}
+
+// changesPerRepo returns the files and lines changed in each repo, with the repos with the most lines changed first, so
+// that repos where the command did more than expected stand out at the top of the table
+func changesPerRepo(diffStats map[string]types.DiffStats) []types.RepoChanges {
+	repoChanges := []types.RepoChanges{}
+	for name, stats := range diffStats {
+		repoChanges = append(repoChanges, types.RepoChanges{
+			Name:         name,
+			FilesChanged: stats.FilesChanged,
+			Insertions:   stats.Insertions,
+			Deletions:    stats.Deletions,
+		})
+	}
+	sort.Slice(repoChanges, func(i, j int) bool {
+		linesI := repoChanges[i].Insertions + repoChanges[i].Deletions
+		linesJ := repoChanges[j].Insertions + repoChanges[j].Deletions
+		if linesI == linesJ {
+			return repoChanges[i].Name < repoChanges[j].Name
+		}
+		return linesI > linesJ
+	})
+	return repoChanges
+}
+
+// slowestReposCount is the number of repos listed in the table of the slowest repos of the final run report
+const slowestReposCount = 10
+
+// slowestRepos returns, for the limit repos that took the longest to process, how long each phase of processing them
+// took
+func slowestRepos(durations map[string]map[types.Phase]time.Duration, limit int) []types.SlowRepo {
+	type repoTotal struct {
+		name  string
+		total time.Duration
+	}
+	totals := []repoTotal{}
+	for name, phases := range durations {
+		total := time.Duration(0)
+		for _, duration := range phases {
+			total += duration
+		}
+		totals = append(totals, repoTotal{name: name, total: total})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].total == totals[j].total {
+			return totals[i].name < totals[j].name
+		}
+		return totals[i].total > totals[j].total
+	})
+	if len(totals) > limit {
+		totals = totals[:limit]
+	}
+
+	format := func(duration time.Duration) string {
+		return duration.Round(100 * time.Millisecond).String()
+	}
+	slowRepos := []types.SlowRepo{}
+	for _, repoTotal := range totals {
+		phases := durations[repoTotal.name]
+		slowRepos = append(slowRepos, types.SlowRepo{
+			Name:        repoTotal.name,
+			Total:       format(repoTotal.total),
+			Clone:       format(phases[types.PhaseClone]),
+			Command:     format(phases[types.PhaseCommand]),
+			Push:        format(phases[types.PhasePush]),
+			PullRequest: format(phases[types.PhasePullRequest]),
+		})
+	}
+	return slowRepos
+}
+
+// PrintRunStatus prints the current state of every pull request opened by the supplied run to the supplied writer,
+// preceded by a count of the pull requests in each state
+func PrintRunStatus(w io.Writer, run *state.Run, statuses []types.PullRequestStatus) {
+	fmt.Fprint(w, "\n\n")
+	fmt.Fprintln(w, "*****************************************************************")
+	fmt.Fprintf(w, "  GIT-XARGS RUN STATUS @ %v\n", time.Now().UTC())
+	fmt.Fprintf(w, "  Run ID: %s\n", run.ID)
+	fmt.Fprintf(w, "  Started: %v\n", run.StartedAt.UTC())
+	fmt.Fprintf(w, "  Command: %s\n", strings.Join(run.Command, " "))
+	fmt.Fprintln(w, "*****************************************************************")
+	fmt.Fprintln(w)
+
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "This run did not open any pull requests")
+		fmt.Fprintln(w)
+		return
+	}
+
+	counts := map[string]int{}
+	states := []string{}
+	for _, status := range statuses {
+		if counts[status.State] == 0 {
+			states = append(states, status.State)
+		}
+		counts[status.State]++
+	}
+	sort.Strings(states)
+
+	fmt.Fprintf(w, "  %d pull requests:", len(statuses))
+	for _, state := range states {
+		fmt.Fprintf(w, " %d %s", counts[state], state)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	statusPrinter := tableprinter.New(w)
+	configurePrinterStyling(statusPrinter)
+	statusPrinter.Print(statuses)
+	fmt.Fprintln(w)
+}
+
+// PrintRunComparison prints how the outcome of each repo changed between the supplied runs, preceded by a count of the
+// repos with each kind of change. Only the repos whose outcome changed are listed
+func PrintRunComparison(w io.Writer, before *state.Run, after *state.Run, comparisons []state.RepoComparison) {
+	fmt.Fprint(w, "\n\n")
+	fmt.Fprintln(w, "*****************************************************************")
+	fmt.Fprintf(w, "  GIT-XARGS RUN COMPARISON @ %v\n", time.Now().UTC())
+	fmt.Fprintf(w, "  Before: %s (started %v)\n", before.ID, before.StartedAt.UTC())
+	fmt.Fprintf(w, "  After: %s (started %v)\n", after.ID, after.StartedAt.UTC())
+	fmt.Fprintln(w, "*****************************************************************")
+	fmt.Fprintln(w)
+
+	counts := map[string]int{}
+	changes := []string{}
+	for _, comparison := range comparisons {
+		if counts[comparison.Change] == 0 {
+			changes = append(changes, comparison.Change)
+		}
+		counts[comparison.Change]++
+	}
+
+	fmt.Fprintf(w, "  %d repos:", len(comparisons))
+	for i, change := range changes {
+		separator := ","
+		if i == 0 {
+			separator = ""
+		}
+		fmt.Fprintf(w, "%s %d %s", separator, counts[change], change)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+
+	rows := repoOutcomeChanges(comparisons)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "The outcome of every repo is the same in both runs")
+		fmt.Fprintln(w)
+		return
+	}
+
+	changesPrinter := tableprinter.New(w)
+	configurePrinterStyling(changesPrinter)
+	changesPrinter.Print(rows)
+	fmt.Fprintln(w)
+}
+
+// repoOutcomeChanges returns a row for each repo whose outcome changed between two runs. The error is the one the repo
+// failed with in the second run or, for fixed repos, in the first run
+func repoOutcomeChanges(comparisons []state.RepoComparison) []types.RepoOutcomeChange {
+	outcome := func(repo *state.Repo) string {
+		if repo == nil {
+			return "not selected"
+		}
+		return repo.Outcome
+	}
+
+	rows := []types.RepoOutcomeChange{}
+	for _, comparison := range comparisons {
+		if comparison.Change == state.ChangeNone {
+			continue
+		}
+		errorMessage := ""
+		switch {
+		case comparison.After != nil && comparison.After.Error != "":
+			errorMessage = comparison.After.Error
+		case comparison.Before != nil:
+			errorMessage = comparison.Before.Error
+		}
+		rows = append(rows, types.RepoOutcomeChange{
+			Repo:   comparison.Repo,
+			Change: comparison.Change,
+			Before: outcome(comparison.Before),
+			After:  outcome(comparison.After),
+			Error:  strings.Join(strings.Fields(errorMessage), " "),
+		})
+	}
+	return rows
+}
+
+// PrintPlan prints the diff planned for every repo, followed by a summary of the plan and how to apply it
+func PrintPlan(p *plan.Plan, path string) {
+	fmt.Print("\n\n")
+	fmt.Println("*****************************************************************")
+	fmt.Printf("  GIT-XARGS PLAN @ %v\n", p.CreatedAt.UTC())
+	fmt.Printf("  Run ID: %s\n", p.RunID)
+	fmt.Printf("  Command: %s\n", strings.Join(p.Command, " "))
+	fmt.Printf("  Branch: %s\n", p.BranchName)
+	fmt.Println("*****************************************************************")
+	fmt.Println()
+
+	for _, repo := range p.Repos {
+		fmt.Printf("# %s (%d files changed)\n", repo.FullName(), len(repo.Files))
+		fmt.Printf("# Pull request: %s\n", repo.PullRequestTitle)
 		fmt.Println()
+		fmt.Println(repo.Diff)
+	}
 
+	if len(p.Repos) == 0 {
+		fmt.Println("No changes. The command did not change any of the selected repos")
+		fmt.Println()
+		return
 	}
+
+	fmt.Printf("Plan: %d repos to change, saved to %s\n", len(p.Repos), path)
+	fmt.Println()
+	fmt.Printf("To open these pull requests, run: git-xargs apply %s\n", path)
+	fmt.Println()
 }
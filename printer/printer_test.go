@@ -0,0 +1,115 @@
+package printer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowestRepos(t *testing.T) {
+	t.Parallel()
+
+	durations := map[string]map[types.Phase]time.Duration{
+		"fetch": {
+			types.PhaseClone:   2 * time.Second,
+			types.PhaseCommand: 500 * time.Millisecond,
+		},
+		"terragrunt": {
+			types.PhaseClone:       10 * time.Second,
+			types.PhaseCommand:     90 * time.Second,
+			types.PhasePush:        3 * time.Second,
+			types.PhasePullRequest: 1234 * time.Millisecond,
+		},
+		"cloud-nuke": {
+			types.PhaseClone: time.Second,
+		},
+	}
+
+	slowRepos := slowestRepos(durations, 2)
+	assert.Equal(t, []types.SlowRepo{
+		{Name: "terragrunt", Total: "1m44.2s", Clone: "10s", Command: "1m30s", Push: "3s", PullRequest: "1.2s"},
+		{Name: "fetch", Total: "2.5s", Clone: "2s", Command: "500ms", Push: "0s", PullRequest: "0s"},
+	}, slowRepos)
+}
+
+func TestChangesPerRepo(t *testing.T) {
+	t.Parallel()
+
+	diffStats := map[string]types.DiffStats{
+		"fetch":      {FilesChanged: 1, Insertions: 2, Deletions: 1},
+		"terragrunt": {FilesChanged: 40, Insertions: 1200, Deletions: 800},
+		"cloud-nuke": {FilesChanged: 2, Insertions: 3},
+	}
+
+	assert.Equal(t, []types.RepoChanges{
+		{Name: "terragrunt", FilesChanged: 40, Insertions: 1200, Deletions: 800},
+		{Name: "cloud-nuke", FilesChanged: 2, Insertions: 3},
+		{Name: "fetch", FilesChanged: 1, Insertions: 2, Deletions: 1},
+	}, changesPerRepo(diffStats))
+}
+
+func TestAPICallsByCategory(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []types.APICallCategory{
+		{Category: "pulls", Calls: 40},
+		{Category: "issues", Calls: 12},
+		{Category: "repos", Calls: 12},
+		{Category: "search", Calls: 1},
+	}, apiCallsByCategory(map[string]uint64{"issues": 12, "search": 1, "pulls": 40, "repos": 12}))
+}
+
+func TestFailuresByReason(t *testing.T) {
+	t.Parallel()
+
+	outcomes := []types.RepoOutcome{
+		{Name: "gruntwork-io/cloud-nuke", Outcome: OutcomeFailed, FailureReason: types.FailureOther},
+		{Name: "gruntwork-io/fetch", Outcome: OutcomeFailed, FailureReason: types.FailurePushRejected},
+		{Name: "gruntwork-io/terragrunt", Outcome: OutcomeFailed, FailureReason: types.FailurePushRejected},
+		{Name: "gruntwork-io/terratest", Outcome: OutcomeSucceeded},
+	}
+
+	assert.Equal(t, []types.FailureReasonCount{
+		{Reason: "Push rejected", Count: 2, Repos: "gruntwork-io/fetch, gruntwork-io/terragrunt"},
+		{Reason: "Other errors", Count: 1, Repos: "gruntwork-io/cloud-nuke"},
+	}, failuresByReason(outcomes))
+}
+
+func TestRepoOutcomeChanges(t *testing.T) {
+	t.Parallel()
+
+	comparisons := []state.RepoComparison{
+		{
+			Repo:   "gruntwork-io/terragrunt",
+			Change: state.ChangeRegressed,
+			Before: &state.Repo{Outcome: state.OutcomeSucceeded},
+			After:  &state.Repo{Outcome: state.OutcomeFailed, Error: "push\nrejected"},
+		},
+		{
+			Repo:   "gruntwork-io/fetch",
+			Change: state.ChangeFixed,
+			Before: &state.Repo{Outcome: state.OutcomeFailed, Error: "exit status 1"},
+			After:  &state.Repo{Outcome: state.OutcomeSucceeded},
+		},
+		{
+			Repo:   "gruntwork-io/bash-commons",
+			Change: state.ChangeAdded,
+			After:  &state.Repo{Outcome: state.OutcomeSucceeded},
+		},
+		{
+			Repo:   "gruntwork-io/boilerplate",
+			Change: state.ChangeNone,
+			Before: &state.Repo{Outcome: state.OutcomeSucceeded},
+			After:  &state.Repo{Outcome: state.OutcomeSucceeded},
+		},
+	}
+
+	assert.Equal(t, []types.RepoOutcomeChange{
+		{Repo: "gruntwork-io/terragrunt", Change: state.ChangeRegressed, Before: "succeeded", After: "failed", Error: "push rejected"},
+		{Repo: "gruntwork-io/fetch", Change: state.ChangeFixed, Before: "failed", After: "succeeded", Error: "exit status 1"},
+		{Repo: "gruntwork-io/bash-commons", Change: state.ChangeAdded, Before: "not selected", After: "succeeded"},
+	}, repoOutcomeChanges(comparisons))
+}
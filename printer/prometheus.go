@@ -0,0 +1,139 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// prometheusMetric is a metric of the Prometheus text exposition format, along with its samples
+type prometheusMetric struct {
+	name    string
+	help    string
+	samples []prometheusSample
+}
+
+// prometheusSample is a single value of a metric, identified by its labels
+type prometheusSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// WritePrometheusMetrics writes the metrics of the run in the Prometheus text exposition format, as pushed to a
+// Pushgateway, so that recurring runs can be monitored and alerted on: the repos in each outcome, the repos tracked
+// under each event, the pull requests opened, the calls made to the GitHub API and how long the run took
+func WritePrometheusMetrics(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport, runtime time.Duration, apiCalls uint64) error {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	eventSamples := []prometheusSample{}
+	for _, annotatedEvent := range allEvents {
+		count := len(runReport.Repos[annotatedEvent.Event]) + len(runReport.SkippedRepos[annotatedEvent.Event])
+		if count == 0 {
+			continue
+		}
+		eventSamples = append(eventSamples, prometheusSample{labels: map[string]string{"event": string(annotatedEvent.Event)}, value: float64(count)})
+	}
+
+	phaseDurations := map[types.Phase]time.Duration{}
+	for _, durations := range runReport.Durations {
+		for phase, duration := range durations {
+			phaseDurations[phase] += duration
+		}
+	}
+	phaseSamples := []prometheusSample{}
+	for _, phase := range []types.Phase{types.PhaseClone, types.PhaseCommand, types.PhasePush, types.PhasePullRequest} {
+		phaseSamples = append(phaseSamples, prometheusSample{labels: map[string]string{"phase": string(phase)}, value: phaseDurations[phase].Seconds()})
+	}
+
+	metrics := []prometheusMetric{
+		{
+			name: "git_xargs_repos",
+			help: "Number of repos processed by the run, by outcome",
+			samples: []prometheusSample{
+				{labels: map[string]string{"outcome": OutcomeSucceeded}, value: float64(summary.Succeeded)},
+				{labels: map[string]string{"outcome": OutcomeFailed}, value: float64(summary.Failed)},
+				{labels: map[string]string{"outcome": OutcomeSkipped}, value: float64(summary.Skipped)},
+			},
+		},
+		{
+			name:    "git_xargs_repo_events",
+			help:    "Number of repos tracked under each event by the run, such as each category of failure",
+			samples: eventSamples,
+		},
+		{
+			name: "git_xargs_pull_requests_opened",
+			help: "Number of pull requests opened by the run",
+			samples: []prometheusSample{
+				{labels: map[string]string{"draft": "false"}, value: float64(summary.PullRequests)},
+				{labels: map[string]string{"draft": "true"}, value: float64(summary.DraftPullRequests)},
+			},
+		},
+		{
+			name:    "git_xargs_github_api_calls",
+			help:    "Number of requests the run sent to the GitHub API",
+			samples: []prometheusSample{{value: float64(apiCalls)}},
+		},
+		{
+			name:    "git_xargs_github_api_retries",
+			help:    "Number of requests to the GitHub API the run sent again, because they failed or were rate limited",
+			samples: []prometheusSample{{value: float64(runReport.APIRetries)}},
+		},
+		{
+			name:    "git_xargs_phase_duration_seconds",
+			help:    "Time spent in each phase of processing repos, summed across every repo of the run",
+			samples: phaseSamples,
+		},
+		{
+			name:    "git_xargs_run_duration_seconds",
+			help:    "How long the run took",
+			samples: []prometheusSample{{value: runtime.Seconds()}},
+		},
+		{
+			name:    "git_xargs_run_completion_timestamp_seconds",
+			help:    "When the run finished, as a Unix timestamp",
+			samples: []prometheusSample{{value: float64(time.Now().Unix())}},
+		},
+	}
+
+	var builder strings.Builder
+	for _, metric := range metrics {
+		if len(metric.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&builder, "# TYPE %s gauge\n", metric.name)
+		for _, sample := range metric.samples {
+			fmt.Fprintf(&builder, "%s%s %v\n", metric.name, formatPrometheusLabels(sample.labels), sample.value)
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return errors.WithStackTrace(err)
+}
+
+// prometheusLabelEscaper escapes the characters that aren't allowed as is in a label value
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatPrometheusLabels renders the supplied labels, sorted by name, e.g. {outcome="failed"}
+func formatPrometheusLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := []string{}
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []string{}
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, prometheusLabelEscaper.Replace(labels[name])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
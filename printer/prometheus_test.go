@@ -0,0 +1,45 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Durations = map[string]map[types.Phase]time.Duration{
+		"gruntwork-io/terragrunt": {types.PhaseClone: 2 * time.Second},
+		"gruntwork-io/fetch":      {types.PhaseClone: 3 * time.Second, types.PhaseCommand: time.Second},
+	}
+	runReport.APIRetries = 3
+
+	var buffer bytes.Buffer
+	require.NoError(t, WritePrometheusMetrics(&buffer, allEvents, runReport, 90*time.Second, 42))
+	metrics := buffer.String()
+
+	assert.Contains(t, metrics, "# TYPE git_xargs_repos gauge\n")
+	assert.Contains(t, metrics, "git_xargs_repos{outcome=\"succeeded\"} 1\n")
+	assert.Contains(t, metrics, "git_xargs_repos{outcome=\"failed\"} 1\n")
+	assert.Contains(t, metrics, "git_xargs_repo_events{event=\"repo-successfully-cloned\"} 2\n")
+	assert.Contains(t, metrics, "git_xargs_repo_events{event=\"pull-request-open-error\"} 1\n")
+	assert.Contains(t, metrics, "git_xargs_pull_requests_opened{draft=\"false\"} 2\n")
+	assert.Contains(t, metrics, "git_xargs_github_api_calls 42\n")
+	assert.Contains(t, metrics, "git_xargs_github_api_retries 3\n")
+	assert.Contains(t, metrics, "git_xargs_phase_duration_seconds{phase=\"clone\"} 5\n")
+	assert.Contains(t, metrics, "git_xargs_phase_duration_seconds{phase=\"push\"} 0\n")
+	assert.Contains(t, metrics, "git_xargs_run_duration_seconds 90\n")
+}
+
+func TestFormatPrometheusLabels(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", formatPrometheusLabels(nil))
+	assert.Equal(t, `{a="1",b="say \"hi\""}`, formatPrometheusLabels(map[string]string{"b": `say "hi"`, "a": "1"}))
+}
@@ -0,0 +1,201 @@
+package printer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+)
+
+const (
+	// OutcomeSucceeded denotes a repo that was processed without error
+	OutcomeSucceeded = "succeeded"
+	// OutcomeFailed denotes a repo whose processing returned an error
+	OutcomeFailed = "failed"
+	// OutcomeSkipped denotes a repo that was skipped rather than processed
+	OutcomeSkipped = "skipped"
+)
+
+// reportSummary counts the repos and pull requests in a run report
+type reportSummary struct {
+	Repos             int `json:"repos"`
+	Succeeded         int `json:"succeeded"`
+	Failed            int `json:"failed"`
+	Skipped           int `json:"skipped"`
+	PullRequests      int `json:"pull_requests"`
+	DraftPullRequests int `json:"draft_pull_requests"`
+}
+
+// previewDiff cuts the supplied diff off after the supplied number of lines, noting how many lines were left out. A
+// maxLines of 0 keeps the whole diff
+func previewDiff(diff string, maxLines int) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return diff
+	}
+	more := len(lines) - maxLines
+	unit := "lines"
+	if more == 1 {
+		unit = "line"
+	}
+	return fmt.Sprintf("%s\n... %d more %s\n", strings.Join(lines[:maxLines], "\n"), more, unit)
+}
+
+// withoutDiffs returns a copy of the supplied run report without the diffs of the changed repos, for the summaries of
+// the run posted elsewhere, such as tracking issues and webhooks. Only the run reports themselves preview the diffs
+func withoutDiffs(runReport *types.RunReport) *types.RunReport {
+	summaryReport := *runReport
+	summaryReport.Diffs = nil
+	summaryReport.DiffPreviewLines = 0
+	return &summaryReport
+}
+
+// repoOutcomes turns the events, errors and pull requests tracked during a run into one outcome per repo, sorted by
+// repo name. This is the per-repo view of the run shared by the structured report formats
+func repoOutcomes(allEvents []types.AnnotatedEvent, runReport *types.RunReport) []types.RepoOutcome {
+	outcomes := map[string]*types.RepoOutcome{}
+
+	track := func(annotatedEvent types.AnnotatedEvent, repo *github.Repository) {
+		key := util.RepoKey(repo)
+		outcome, ok := outcomes[key]
+		if !ok {
+			outcome = &types.RepoOutcome{
+				Key:    key,
+				Name:   repo.GetOwner().GetLogin() + "/" + repo.GetName(),
+				URL:    repo.GetHTMLURL(),
+				Events: []types.Event{},
+			}
+			outcomes[key] = outcome
+		}
+		outcome.Events = append(outcome.Events, annotatedEvent.Event)
+		if annotatedEvent.Skip && outcome.SkipReason == "" {
+			outcome.SkipReason = annotatedEvent.Description
+		}
+	}
+
+	for _, annotatedEvent := range allEvents {
+		for _, repo := range runReport.Repos[annotatedEvent.Event] {
+			track(annotatedEvent, repo)
+		}
+		for _, repo := range runReport.SkippedRepos[annotatedEvent.Event] {
+			track(annotatedEvent, repo)
+		}
+	}
+
+	summary := []types.RepoOutcome{}
+	for key, outcome := range outcomes {
+		outcome.Error = runReport.Errors[key]
+		outcome.Diff = previewDiff(runReport.Diffs[key], runReport.DiffPreviewLines)
+		if diffStats, ok := runReport.DiffStats[key]; ok {
+			outcome.DiffStats = &diffStats
+		}
+		if durations := runReport.Durations[key]; len(durations) > 0 {
+			outcome.DurationsSeconds = map[types.Phase]float64{}
+			for phase, duration := range durations {
+				outcome.DurationsSeconds[phase] = duration.Seconds()
+			}
+		}
+		outcome.PullRequestURLs = pullRequestURLsForRepo(runReport.PullRequests, key)
+		outcome.DraftPullRequestURLs = pullRequestURLsForRepo(runReport.DraftPullRequests, key)
+
+		switch {
+		case outcome.Error != "":
+			outcome.Outcome = OutcomeFailed
+			outcome.FailureReason = runReport.FailureReasons[key]
+			if outcome.FailureReason == "" {
+				outcome.FailureReason = types.FailureOther
+			}
+		case outcome.SkipReason != "":
+			outcome.Outcome = OutcomeSkipped
+		default:
+			outcome.Outcome = OutcomeSucceeded
+		}
+		summary = append(summary, *outcome)
+	}
+
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Name != summary[j].Name {
+			return summary[i].Name < summary[j].Name
+		}
+		return summary[i].Key < summary[j].Key
+	})
+	return summary
+}
+
+// failureReasons are the kinds of errors the reports group failed repos by, in the order they are rendered, with a
+// description of each
+var failureReasons = []struct {
+	reason      types.FailureReason
+	description string
+}{
+	{types.FailureCloneError, "Clone failed"},
+	{types.FailureCommandExit, "Command exited with a non-zero status"},
+	{types.FailurePushRejected, "Push rejected"},
+	{types.FailurePullRequestInvalid, "Pull request rejected with a 422"},
+	{types.FailurePullRequestError, "Pull request failed to open"},
+	{types.FailureRateLimited, "Rate limited by the GitHub API"},
+	{types.FailureTimedOut, "Timed out"},
+	{types.FailureOther, "Other errors"},
+}
+
+// failureGroup is the repos that failed with one kind of error
+type failureGroup struct {
+	Reason      types.FailureReason
+	Description string
+	Repos       []types.RepoOutcome
+}
+
+// groupFailures groups the failed repos among the supplied outcomes by the kind of error they failed with, so that the
+// reports can show how many repos failed for each reason rather than a flat list of errors. Reasons no repo failed with
+// are left out
+func groupFailures(outcomes []types.RepoOutcome) []failureGroup {
+	groups := []failureGroup{}
+	for _, failureReason := range failureReasons {
+		group := failureGroup{Reason: failureReason.reason, Description: failureReason.description}
+		for _, outcome := range outcomes {
+			if outcome.Outcome == OutcomeFailed && outcome.FailureReason == failureReason.reason {
+				group.Repos = append(group.Repos, outcome)
+			}
+		}
+		if len(group.Repos) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// pullRequestURLsForRepo returns the URLs of the pull requests opened for the repo with the supplied util.RepoKey,
+// including each part of changes that were split across several pull requests, and each directory of a monorepo
+func pullRequestURLsForRepo(pullRequests map[string]string, key string) []string {
+	urls := []string{}
+	for name, url := range pullRequests {
+		if name == key || strings.HasPrefix(name, key+" (") {
+			urls = append(urls, url)
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// summarize counts the repos in each outcome, and the pull requests opened, in the supplied run report
+func summarize(outcomes []types.RepoOutcome, runReport *types.RunReport) reportSummary {
+	summary := reportSummary{
+		Repos:             len(outcomes),
+		PullRequests:      len(runReport.PullRequests),
+		DraftPullRequests: len(runReport.DraftPullRequests),
+	}
+	for _, outcome := range outcomes {
+		switch outcome.Outcome {
+		case OutcomeSucceeded:
+			summary.Succeeded++
+		case OutcomeFailed:
+			summary.Failed++
+		case OutcomeSkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
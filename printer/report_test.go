@@ -0,0 +1,88 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepo(name string) *github.Repository {
+	return &github.Repository{
+		Owner:   &github.User{Login: github.String("gruntwork-io")},
+		Name:    github.String(name),
+		HTMLURL: github.String("https://github.com/gruntwork-io/" + name),
+	}
+}
+
+// newTestRunReport returns a run report in which terragrunt succeeded with a pull request split in two parts, fetch
+// failed and cloud-nuke was skipped
+func newTestRunReport() ([]types.AnnotatedEvent, *types.RunReport) {
+	terragrunt, fetch, cloudNuke := newTestRepo("terragrunt"), newTestRepo("fetch"), newTestRepo("cloud-nuke")
+
+	allEvents := []types.AnnotatedEvent{
+		{Event: "repo-successfully-cloned", Description: "Repos that were successfully cloned"},
+		{Event: "pull-request-open-error", Description: "Repos against which pull requests failed to be opened"},
+		{Event: "pull-request-already-open-skipped", Description: "Repos that were skipped because a pull request was already open", Skip: true},
+	}
+	runReport := &types.RunReport{
+		Repos: map[types.Event][]*github.Repository{
+			"repo-successfully-cloned":          {terragrunt, fetch},
+			"pull-request-open-error":           {fetch},
+			"pull-request-already-open-skipped": {cloudNuke},
+		},
+		Command: []string{"touch", "file"},
+		RunID:   "run-1",
+		PullRequests: map[string]string{
+			"gruntwork-io/terragrunt (part 1 of 2)": "https://github.com/gruntwork-io/terragrunt/pull/1",
+			"gruntwork-io/terragrunt (part 2 of 2)": "https://github.com/gruntwork-io/terragrunt/pull/2",
+		},
+		Errors:         map[string]string{"gruntwork-io/fetch": "422 Validation Failed"},
+		FailureReasons: map[string]types.FailureReason{"gruntwork-io/fetch": types.FailurePullRequestInvalid},
+		DiffStats: map[string]types.DiffStats{
+			"gruntwork-io/terragrunt": {FilesChanged: 3, Insertions: 12, Deletions: 4},
+		},
+	}
+	return allEvents, runReport
+}
+
+// Test that repos of the same name in different orgs, or on different hosts, get an outcome each
+func TestRepoOutcomesTellReposOfTheSameNameApart(t *testing.T) {
+	t.Parallel()
+
+	fork := &github.Repository{
+		Owner:   &github.User{Login: github.String("forks")},
+		Name:    github.String("terragrunt"),
+		HTMLURL: github.String("https://github.com/forks/terragrunt"),
+	}
+	enterprise := &github.Repository{
+		Owner:   &github.User{Login: github.String("gruntwork-io")},
+		Name:    github.String("terragrunt"),
+		HTMLURL: github.String("https://github.example.com/gruntwork-io/terragrunt"),
+	}
+	allEvents := []types.AnnotatedEvent{{Event: "repo-successfully-cloned", Description: "Repos that were successfully cloned"}}
+	runReport := &types.RunReport{
+		Repos: map[types.Event][]*github.Repository{
+			"repo-successfully-cloned": {newTestRepo("terragrunt"), fork, enterprise},
+		},
+		PullRequests: map[string]string{"gruntwork-io/terragrunt": "https://github.com/gruntwork-io/terragrunt/pull/1"},
+		Errors:       map[string]string{"github.example.com/gruntwork-io/terragrunt": "push rejected"},
+	}
+
+	outcomes := repoOutcomes(allEvents, runReport)
+	require.Len(t, outcomes, 3)
+
+	assert.Equal(t, "forks/terragrunt", outcomes[0].Name)
+	assert.Equal(t, OutcomeSucceeded, outcomes[0].Outcome)
+	assert.Empty(t, outcomes[0].PullRequestURLs)
+
+	assert.Equal(t, "github.example.com/gruntwork-io/terragrunt", outcomes[1].Key)
+	assert.Equal(t, OutcomeFailed, outcomes[1].Outcome)
+	assert.Empty(t, outcomes[1].PullRequestURLs)
+
+	assert.Equal(t, "gruntwork-io/terragrunt", outcomes[2].Key)
+	assert.Equal(t, OutcomeSucceeded, outcomes[2].Outcome)
+	assert.Equal(t, []string{"https://github.com/gruntwork-io/terragrunt/pull/1"}, outcomes[2].PullRequestURLs)
+}
@@ -0,0 +1,67 @@
+package printer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// slackListLimit caps the pull requests and failures listed in a Slack message, which Slack truncates past a few
+// thousand characters
+const slackListLimit = 20
+
+// slackEscaper escapes the characters that Slack's mrkdwn treats as control characters
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// RenderSlackMessage renders a summary of the run as a Slack mrkdwn message: how many repos were processed, links to
+// the pull requests opened and the repos that failed along with their errors
+func RenderSlackMessage(allEvents []types.AnnotatedEvent, runReport *types.RunReport) string {
+	outcomes := repoOutcomes(allEvents, runReport)
+	summary := summarize(outcomes, runReport)
+
+	var builder strings.Builder
+	builder.WriteString("*git-xargs run")
+	if runReport.RunID != "" {
+		fmt.Fprintf(&builder, " `%s`", runReport.RunID)
+	}
+	builder.WriteString(" finished*\n")
+	if len(runReport.Command) > 0 {
+		fmt.Fprintf(&builder, "Command: `%s`\n", slackEscaper.Replace(strings.Join(runReport.Command, " ")))
+	}
+	fmt.Fprintf(&builder, "%d repos processed: %d succeeded, %d failed, %d skipped\n", summary.Repos, summary.Succeeded, summary.Failed, summary.Skipped)
+
+	pullRequests := []string{}
+	failures := []string{}
+	for _, outcome := range outcomes {
+		for _, url := range outcome.PullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("• <%s|%s #%s>", url, outcome.Name, path.Base(url)))
+		}
+		for _, url := range outcome.DraftPullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("• <%s|%s #%s> (draft)", url, outcome.Name, path.Base(url)))
+		}
+		if outcome.Outcome == OutcomeFailed {
+			failures = append(failures, fmt.Sprintf("• <%s|%s>: %s", outcome.URL, outcome.Name, slackEscaper.Replace(strings.Join(strings.Fields(outcome.Error), " "))))
+		}
+	}
+
+	writeSlackList(&builder, "Pull requests opened", pullRequests)
+	writeSlackList(&builder, "Failures needing attention", failures)
+	return builder.String()
+}
+
+// writeSlackList writes the supplied lines under a bold title, listing at most slackListLimit of them
+func writeSlackList(builder *strings.Builder, title string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(builder, "\n*%s (%d):*\n", title, len(lines))
+	for i, line := range lines {
+		if i == slackListLimit {
+			fmt.Fprintf(builder, "…and %d more\n", len(lines)-slackListLimit)
+			break
+		}
+		builder.WriteString(line + "\n")
+	}
+}
@@ -0,0 +1,24 @@
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSlackMessage(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Errors["gruntwork-io/fetch"] = "branch <main> not found"
+
+	expected := "*git-xargs run `run-1` finished*\n" +
+		"Command: `touch file`\n" +
+		"3 repos processed: 1 succeeded, 1 failed, 1 skipped\n" +
+		"\n*Pull requests opened (2):*\n" +
+		"• <https://github.com/gruntwork-io/terragrunt/pull/1|gruntwork-io/terragrunt #1>\n" +
+		"• <https://github.com/gruntwork-io/terragrunt/pull/2|gruntwork-io/terragrunt #2>\n" +
+		"\n*Failures needing attention (1):*\n" +
+		"• <https://github.com/gruntwork-io/fetch|gruntwork-io/fetch>: branch &lt;main&gt; not found\n"
+	assert.Equal(t, expected, RenderSlackMessage(allEvents, runReport))
+}
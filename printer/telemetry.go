@@ -0,0 +1,80 @@
+package printer
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// TelemetryUsage describes how git-xargs was used for a run, for the --telemetry-endpoint payload
+type TelemetryUsage struct {
+	Version string
+	// Command is the subcommand that was run, or "run" for a regular run
+	Command string
+	// Flags are the names of the flags that were set, without their values
+	Flags    []string
+	APICalls uint64
+}
+
+// telemetryPayload is the JSON payload sent to --telemetry-endpoint. It is anonymized: it holds counts, durations and
+// the names of events and flags, but no repo, org, branch or user names, URLs, commands, flag values or errors
+type telemetryPayload struct {
+	GitXargsVersion      string                  `json:"git_xargs_version"`
+	OS                   string                  `json:"os"`
+	Arch                 string                  `json:"arch"`
+	Command              string                  `json:"command"`
+	Flags                []string                `json:"flags"`
+	Summary              reportSummary           `json:"summary"`
+	Events               map[types.Event]int     `json:"events"`
+	DurationSeconds      float64                 `json:"duration_seconds"`
+	PhaseDurationSeconds map[types.Phase]float64 `json:"phase_duration_seconds"`
+	APICalls             uint64                  `json:"github_api_calls"`
+	APIRetries           uint64                  `json:"github_api_retries"`
+}
+
+// WriteTelemetryPayload writes the anonymized metrics of the run sent to --telemetry-endpoint, so that platform teams
+// running git-xargs as a service can aggregate its usage: the repos in each outcome, the repos tracked under each event,
+// such as each category of failure, how long the run and each phase of processing repos took, and how it was used
+func WriteTelemetryPayload(w io.Writer, allEvents []types.AnnotatedEvent, runReport *types.RunReport, runtimeDuration time.Duration, usage TelemetryUsage) error {
+	outcomes := repoOutcomes(allEvents, runReport)
+
+	events := map[types.Event]int{}
+	for _, annotatedEvent := range allEvents {
+		count := len(runReport.Repos[annotatedEvent.Event]) + len(runReport.SkippedRepos[annotatedEvent.Event])
+		if count > 0 {
+			events[annotatedEvent.Event] = count
+		}
+	}
+
+	phaseDurations := map[types.Phase]float64{}
+	for _, durations := range runReport.Durations {
+		for phase, duration := range durations {
+			phaseDurations[phase] += duration.Seconds()
+		}
+	}
+
+	flags := usage.Flags
+	if flags == nil {
+		flags = []string{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return errors.WithStackTrace(encoder.Encode(telemetryPayload{
+		GitXargsVersion:      usage.Version,
+		OS:                   runtime.GOOS,
+		Arch:                 runtime.GOARCH,
+		Command:              usage.Command,
+		Flags:                flags,
+		Summary:              summarize(outcomes, runReport),
+		Events:               events,
+		DurationSeconds:      runtimeDuration.Seconds(),
+		PhaseDurationSeconds: phaseDurations,
+		APICalls:             usage.APICalls,
+		APIRetries:           runReport.APIRetries,
+	}))
+}
@@ -0,0 +1,42 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the telemetry payload holds the counts and durations of the run, and nothing that identifies its repos
+func TestWriteTelemetryPayload(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.Durations = map[string]map[types.Phase]time.Duration{
+		"gruntwork-io/terragrunt": {types.PhaseClone: 2 * time.Second},
+		"gruntwork-io/fetch":      {types.PhaseClone: 3 * time.Second},
+	}
+
+	var buffer bytes.Buffer
+	usage := TelemetryUsage{Version: "v0.1.5", Command: "run", Flags: []string{"branch-name", "github-org"}, APICalls: 42}
+	require.NoError(t, WriteTelemetryPayload(&buffer, allEvents, runReport, 90*time.Second, usage))
+
+	payload := telemetryPayload{}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &payload))
+	assert.Equal(t, "v0.1.5", payload.GitXargsVersion)
+	assert.Equal(t, "run", payload.Command)
+	assert.Equal(t, []string{"branch-name", "github-org"}, payload.Flags)
+	assert.Equal(t, reportSummary{Repos: 3, Succeeded: 1, Failed: 1, Skipped: 1, PullRequests: 2}, payload.Summary)
+	assert.Equal(t, 1, payload.Events["pull-request-open-error"])
+	assert.Equal(t, float64(90), payload.DurationSeconds)
+	assert.Equal(t, float64(5), payload.PhaseDurationSeconds[types.PhaseClone])
+	assert.Equal(t, uint64(42), payload.APICalls)
+
+	for _, identifying := range []string{"terragrunt", "gruntwork-io", "run-1", "touch", "422 Validation Failed"} {
+		assert.NotContains(t, buffer.String(), identifying)
+	}
+}
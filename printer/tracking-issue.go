@@ -0,0 +1,76 @@
+package printer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// trackingIssueMaxLength is the longest body GitHub accepts for an issue. It is counted in characters, so counting
+// bytes, as len does, errs on the safe side
+const trackingIssueMaxLength = 65536
+
+// RenderTrackingIssue returns the body of the tracking issue of a run: the Markdown run report, without the diffs of
+// the changed repos, followed by a checklist
+// of the pull requests opened, to tick off as they are merged, and a checklist of the repos that failed, to tick off
+// as they are fixed. GitHub renders the URL of each pull request in the checklist with its title and state. A body
+// longer than GitHub accepts is cut short, with a link to the full report at the supplied URL, if there is one
+func RenderTrackingIssue(allEvents []types.AnnotatedEvent, runReport *types.RunReport, fullReportURL string) (string, error) {
+	var builder strings.Builder
+	if err := WriteMarkdownReport(&builder, allEvents, withoutDiffs(runReport)); err != nil {
+		return "", err
+	}
+
+	pullRequests := []string{}
+	failedRepos := []string{}
+	for _, outcome := range repoOutcomes(allEvents, runReport) {
+		for _, url := range outcome.PullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("- [ ] %s", url))
+		}
+		for _, url := range outcome.DraftPullRequestURLs {
+			pullRequests = append(pullRequests, fmt.Sprintf("- [ ] %s (draft)", url))
+		}
+		if outcome.Outcome == OutcomeFailed {
+			failedRepos = append(failedRepos, fmt.Sprintf("- [ ] [%s](%s): %s", outcome.Name, outcome.URL, strings.Join(strings.Fields(outcome.Error), " ")))
+		}
+	}
+
+	checklists := []struct {
+		title string
+		items []string
+	}{
+		{"Pull requests to merge", pullRequests},
+		{"Failed repos to fix", failedRepos},
+	}
+	for _, checklist := range checklists {
+		if len(checklist.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n### %s (%d)\n\n", checklist.title, len(checklist.items))
+		builder.WriteString(strings.Join(checklist.items, "\n") + "\n")
+	}
+
+	return truncateTrackingIssue(builder.String(), fullReportURL), nil
+}
+
+// truncateTrackingIssue cuts the supplied body short, at the end of a line, if it is longer than GitHub accepts, and
+// ends it with a note linking to the full report at the supplied URL
+func truncateTrackingIssue(body string, fullReportURL string) string {
+	if len(body) <= trackingIssueMaxLength {
+		return body
+	}
+
+	note := "\n---\n\n**This report was too long for an issue, and was cut short.** "
+	if fullReportURL != "" {
+		note += fmt.Sprintf("See the [full report](%s).\n", fullReportURL)
+	} else {
+		note += "Pass `--report-gist` or `--report-upload` to link to the full report here.\n"
+	}
+
+	truncated := body[:trackingIssueMaxLength-len(note)]
+	if end := strings.LastIndex(truncated, "\n"); end >= 0 {
+		truncated = truncated[:end+1]
+	}
+	return truncated + note
+}
@@ -0,0 +1,61 @@
+package printer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTrackingIssue(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+
+	body, err := RenderTrackingIssue(allEvents, runReport, "")
+	require.NoError(t, err)
+
+	assert.Contains(t, body, "## git-xargs run")
+	assert.Contains(t, body, `### Pull requests to merge (2)
+
+- [ ] https://github.com/gruntwork-io/terragrunt/pull/1
+- [ ] https://github.com/gruntwork-io/terragrunt/pull/2
+`)
+	assert.Contains(t, body, `### Failed repos to fix (1)
+
+- [ ] [gruntwork-io/fetch](https://github.com/gruntwork-io/fetch): 422 Validation Failed
+`)
+}
+
+// Test that a body longer than GitHub accepts is cut short at the end of a line, with a link to the full report
+func TestTruncateTrackingIssue(t *testing.T) {
+	t.Parallel()
+
+	line := "- [ ] https://github.com/gruntwork-io/terragrunt/pull/1\n"
+	body := strings.Repeat(line, trackingIssueMaxLength/len(line)+10)
+
+	truncated := truncateTrackingIssue(body, "https://gist.github.com/abc")
+	assert.True(t, len(truncated) <= trackingIssueMaxLength)
+	assert.True(t, strings.HasPrefix(truncated, line))
+	assert.Contains(t, truncated, line+"\n---\n\n**This report was too long for an issue, and was cut short.** See the [full report](https://gist.github.com/abc).\n")
+
+	assert.Equal(t, line, truncateTrackingIssue(line, ""))
+}
+
+// Test that the diffs previewed in the run reports are left out of the tracking issue
+func TestRenderTrackingIssueWithoutDiffs(t *testing.T) {
+	t.Parallel()
+
+	allEvents, runReport := newTestRunReport()
+	runReport.DiffPreviewLines = 10
+	runReport.Diffs = map[string]string{"gruntwork-io/terragrunt": "+internal change\n"}
+
+	var report strings.Builder
+	require.NoError(t, WriteMarkdownReport(&report, allEvents, runReport))
+	assert.Contains(t, report.String(), "+internal change")
+
+	body, err := RenderTrackingIssue(allEvents, runReport, "")
+	require.NoError(t, err)
+	assert.NotContains(t, body, "internal change")
+}
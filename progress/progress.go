@@ -0,0 +1,237 @@
+// Package progress renders a live view of a run while it executes: how far along the run is, which phase each repo
+// being processed is in and the most recent errors.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+)
+
+const (
+	// interactiveRefreshInterval is how often the dashboard is redrawn on a terminal
+	interactiveRefreshInterval = 250 * time.Millisecond
+	// plainRefreshInterval is how often a progress line is printed when stderr isn't a terminal, e.g. in CI logs
+	plainRefreshInterval = 10 * time.Second
+	// barWidth is the number of characters of the progress bar
+	barWidth = 30
+	// maxInProgressShown caps the number of repos listed as in progress
+	maxInProgressShown = 10
+	// maxRecentErrors is the number of most recent errors listed
+	maxRecentErrors = 5
+	// maxLineLength truncates the lines of the dashboard, so that they don't wrap and throw off the redrawing
+	maxLineLength = 110
+)
+
+// phaseLabels are the descriptions of the phases a repo goes through, as shown next to the repos in progress
+var phaseLabels = map[types.Phase]string{
+	types.PhaseClone:       "cloning",
+	types.PhaseCommand:     "running command",
+	types.PhasePush:        "pushing",
+	types.PhasePullRequest: "opening PR",
+}
+
+// Tracker keeps track of the progress of a run and renders it to stderr. When --progress isn't passed the tracker is
+// nil, and its methods return straight away
+type Tracker struct {
+	out         io.Writer
+	interactive bool
+	runID       string
+
+	mutex        sync.Mutex
+	total        int
+	started      time.Time
+	phases       map[string]types.Phase
+	succeeded    int
+	failed       int
+	recentErrors []string
+	linesDrawn   int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a Tracker for the run with the supplied ID, which renders to stderr. The dashboard is redrawn in place if
+// stderr is a terminal, and printed as a line every few seconds otherwise
+func New(runID string) *Tracker {
+	return newTracker(os.Stderr, isTerminal(os.Stderr), runID)
+}
+
+func newTracker(out io.Writer, interactive bool, runID string) *Tracker {
+	return &Tracker{
+		out:         out,
+		interactive: interactive,
+		runID:       runID,
+		phases:      map[string]types.Phase{},
+	}
+}
+
+// isTerminal returns true if the supplied file is a terminal rather than a pipe or a regular file
+func isTerminal(file *os.File) bool {
+	stat, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// Start starts rendering the progress of a run over the supplied number of repos, until Stop is called
+func (t *Tracker) Start(total int) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	t.total = total
+	t.started = time.Now()
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	t.mutex.Unlock()
+
+	interval := plainRefreshInterval
+	if t.interactive {
+		interval = interactiveRefreshInterval
+	}
+
+	go func() {
+		defer close(t.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.draw()
+			case <-t.stop:
+				t.draw()
+				return
+			}
+		}
+	}()
+}
+
+// Stop renders the final state of the run and stops rendering
+func (t *Tracker) Stop() {
+	if t == nil || t.stop == nil {
+		return
+	}
+	close(t.stop)
+	<-t.done
+}
+
+// SetPhase records that the supplied repo entered the supplied phase of processing
+func (t *Tracker) SetPhase(repo *github.Repository, phase types.Phase) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.phases[util.RepoKey(repo)] = phase
+}
+
+// Finish records that the supplied repo is done being processed, and failed if the supplied error isn't nil
+func (t *Tracker) Finish(repo *github.Repository, err error) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.phases, util.RepoKey(repo))
+	if err == nil {
+		t.succeeded++
+		return
+	}
+
+	t.failed++
+	t.recentErrors = append(t.recentErrors, fmt.Sprintf("%s: %s", util.RepoKey(repo), strings.Join(strings.Fields(err.Error()), " ")))
+	if len(t.recentErrors) > maxRecentErrors {
+		t.recentErrors = t.recentErrors[len(t.recentErrors)-maxRecentErrors:]
+	}
+}
+
+// draw writes the current state of the run: in place of the previous dashboard on a terminal, or as a new line
+// otherwise
+func (t *Tracker) draw() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.interactive {
+		fmt.Fprintln(t.out, t.summaryLine())
+		return
+	}
+
+	lines := t.render()
+	var builder strings.Builder
+	if t.linesDrawn > 0 {
+		// Move the cursor back up to the start of the previous dashboard and clear everything below it
+		fmt.Fprintf(&builder, "\x1b[%dA\x1b[J", t.linesDrawn)
+	}
+	for _, line := range lines {
+		builder.WriteString(line + "\n")
+	}
+	io.WriteString(t.out, builder.String())
+	t.linesDrawn = len(lines)
+}
+
+// summaryLine returns a single line with the counts of repos in each state
+func (t *Tracker) summaryLine() string {
+	done := t.succeeded + t.failed
+	queued := t.total - done - len(t.phases)
+	if queued < 0 {
+		queued = 0
+	}
+	return fmt.Sprintf("%d/%d repos done (%d failed), %d in progress, %d queued", done, t.total, t.failed, len(t.phases), queued)
+}
+
+// render returns the lines of the dashboard
+func (t *Tracker) render() []string {
+	done := t.succeeded + t.failed
+	filled := 0
+	if t.total > 0 {
+		filled = done * barWidth / t.total
+	}
+
+	header := "git-xargs"
+	if t.runID != "" {
+		header += " run " + t.runID
+	}
+	lines := []string{
+		fmt.Sprintf("%s  [%s%s]  %s", header, strings.Repeat("#", filled), strings.Repeat("-", barWidth-filled), time.Since(t.started).Round(time.Second)),
+		t.summaryLine(),
+	}
+
+	repoNames := []string{}
+	for repoName := range t.phases {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+	for i, repoName := range repoNames {
+		if i == maxInProgressShown {
+			lines = append(lines, fmt.Sprintf("  …and %d more", len(repoNames)-maxInProgressShown))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-16s %s", phaseLabels[t.phases[repoName]], repoName))
+	}
+
+	if len(t.recentErrors) > 0 {
+		lines = append(lines, "Recent errors:")
+		for _, recentError := range t.recentErrors {
+			lines = append(lines, "  "+recentError)
+		}
+	}
+
+	for i, line := range lines {
+		if len([]rune(line)) > maxLineLength {
+			lines[i] = string([]rune(line)[:maxLineLength-1]) + "…"
+		}
+	}
+	return lines
+}
@@ -0,0 +1,79 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOwner owns the repos of the tests
+var testOwner = &github.User{Login: github.String("gruntwork-io")}
+
+func TestTrackerRender(t *testing.T) {
+	t.Parallel()
+
+	tracker := newTracker(&bytes.Buffer{}, true, "run-1")
+	tracker.total = 4
+
+	terragrunt := &github.Repository{Owner: testOwner, Name: github.String("terragrunt")}
+	fetch := &github.Repository{Owner: testOwner, Name: github.String("fetch")}
+	cloudNuke := &github.Repository{Owner: testOwner, Name: github.String("cloud-nuke")}
+
+	tracker.SetPhase(terragrunt, types.PhaseClone)
+	tracker.SetPhase(terragrunt, types.PhasePush)
+	tracker.SetPhase(fetch, types.PhaseCommand)
+	tracker.Finish(fetch, fmt.Errorf("exit status 1"))
+	tracker.SetPhase(cloudNuke, types.PhaseCommand)
+
+	lines := tracker.render()
+	require.Len(t, lines, 6)
+	assert.True(t, strings.HasPrefix(lines[0], "git-xargs run run-1  [#######-----------------------]"))
+	assert.Equal(t, "1/4 repos done (1 failed), 2 in progress, 1 queued", lines[1])
+	assert.Equal(t, "  running command  gruntwork-io/cloud-nuke", lines[2])
+	assert.Equal(t, "  pushing          gruntwork-io/terragrunt", lines[3])
+	assert.Equal(t, "Recent errors:", lines[4])
+	assert.Equal(t, "  gruntwork-io/fetch: exit status 1", lines[5])
+}
+
+func TestTrackerKeepsMostRecentErrors(t *testing.T) {
+	t.Parallel()
+
+	tracker := newTracker(&bytes.Buffer{}, true, "")
+	for i := 0; i < maxRecentErrors+2; i++ {
+		tracker.Finish(&github.Repository{Owner: testOwner, Name: github.String(fmt.Sprintf("repo-%d", i))}, fmt.Errorf("failed"))
+	}
+
+	require.Len(t, tracker.recentErrors, maxRecentErrors)
+	assert.Equal(t, "gruntwork-io/repo-2: failed", tracker.recentErrors[0])
+	assert.Equal(t, maxRecentErrors+2, tracker.failed)
+}
+
+func TestTrackerDrawsInPlace(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	tracker := newTracker(&out, true, "")
+	tracker.Start(1)
+	tracker.Finish(&github.Repository{Owner: testOwner, Name: github.String("fetch")}, nil)
+	tracker.draw()
+	tracker.Stop()
+
+	assert.Contains(t, out.String(), "\x1b[2A\x1b[J")
+	assert.Contains(t, out.String(), "1/1 repos done (0 failed), 0 in progress, 0 queued")
+}
+
+func TestNilTrackerIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	var tracker *Tracker
+	tracker.Start(1)
+	tracker.SetPhase(&github.Repository{}, types.PhaseClone)
+	tracker.Finish(&github.Repository{}, nil)
+	tracker.Stop()
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// runAborter aborts a run once more of its repos failed than --fail-fast, --max-failures or --max-failure-rate allow.
+// Aborting cancels the context of the run, so the repos being processed are stopped and no more are started, just as if
+// the run was interrupted
+type runAborter struct {
+	config     *config.GitXargsConfig
+	runContext context.Context
+	abort      context.CancelFunc
+	total      int
+	failed     int
+	mutex      sync.Mutex
+}
+
+// newRunAborter replaces the context of the run, which processes the supplied number of repos, with one that the
+// returned runAborter can cancel. Call stop once every repo has been processed to restore the original context
+func newRunAborter(config *config.GitXargsConfig, total int) *runAborter {
+	runContext := config.Context
+	abortContext, abort := context.WithCancel(runContext)
+	config.Context = abortContext
+
+	return &runAborter{
+		config:     config,
+		runContext: runContext,
+		abort:      abort,
+		total:      total,
+	}
+}
+
+// repoFailed records that the supplied repo failed, and aborts the run if that makes too many failed repos
+func (aborter *runAborter) repoFailed(repo *github.Repository, err error) {
+	aborter.mutex.Lock()
+	defer aborter.mutex.Unlock()
+
+	aborter.failed++
+	if aborter.aborted() || aborter.runContext.Err() != nil || !aborter.tooManyFailures() {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name":    repo.GetName(),
+		"Error":        err,
+		"Failed repos": aborter.failed,
+	}).Warn("Aborting the run, because too many repos failed")
+	aborter.abort()
+}
+
+// tooManyFailures returns true if more repos failed than --fail-fast, --max-failures or --max-failure-rate allow. The
+// rate is out of every repo of the run, so the run is only aborted once it can't finish within the rate anymore
+func (aborter *runAborter) tooManyFailures() bool {
+	config := aborter.config
+	switch {
+	case config.FailFast:
+		return true
+	case config.MaxFailures >= 0 && aborter.failed > config.MaxFailures:
+		return true
+	case config.MaxFailureRate >= 0 && aborter.total > 0 && float64(aborter.failed)/float64(aborter.total) > config.MaxFailureRate:
+		return true
+	default:
+		return false
+	}
+}
+
+// aborted returns true if the run was aborted, rather than cancelled or interrupted
+func (aborter *runAborter) aborted() bool {
+	return aborter.runContext.Err() == nil && aborter.config.Context.Err() != nil
+}
+
+// stop restores the original context of the run
+func (aborter *runAborter) stop() {
+	aborter.abort()
+	aborter.config.Context = aborter.runContext
+}
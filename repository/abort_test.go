@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAborter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		failFast       bool
+		maxFailures    int
+		maxFailureRate float64
+		failed         int
+		cancelRun      bool
+		expectAborted  bool
+	}{
+		{"no thresholds", false, -1, -1, 3, false, false},
+		{"fail fast", true, -1, -1, 1, false, true},
+		{"within max failures", false, 2, -1, 2, false, false},
+		{"over max failures", false, 2, -1, 3, false, true},
+		{"within max failure rate", false, -1, 0.5, 2, false, false},
+		{"over max failure rate", false, -1, 0.5, 3, false, true},
+		{"cancelled run", true, -1, -1, 1, true, false},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testConfig := config.NewGitXargsTestConfig()
+			testConfig.FailFast = testCase.failFast
+			testConfig.MaxFailures = testCase.maxFailures
+			testConfig.MaxFailureRate = testCase.maxFailureRate
+			runContext, cancel := context.WithCancel(testConfig.Context)
+			defer cancel()
+			testConfig.Context = runContext
+
+			aborter := newRunAborter(testConfig, 4)
+			if testCase.cancelRun {
+				cancel()
+			}
+			for i := 0; i < testCase.failed; i++ {
+				aborter.repoFailed(mocks.GetMockGithubRepo(), errors.New("command failed"))
+			}
+
+			assert.Equal(t, testCase.expectAborted, aborter.aborted())
+			assert.Equal(t, testCase.expectAborted || testCase.cancelRun, runCancelled(testConfig))
+
+			// Aborting the run doesn't cancel the context it was started with
+			aborter.stop()
+			assert.Equal(t, runContext, testConfig.Context)
+			assert.Equal(t, testCase.cancelRun, runCancelled(testConfig))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// skipChangesAlreadyOnBaseBranch returns true if every file the command changed in the supplied worktree is already
+// identical on the base branch of the supplied repo, in which case the repo is tracked as already applied, and nothing
+// is pushed for it. This happens when a campaign is run again, e.g. on a branch left over from an earlier run whose
+// changes have since landed on the base branch, and keeps it from opening empty or duplicate pull requests
+func skipChangesAlreadyOnBaseBranch(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, worktree *git.Worktree, status git.Status) (bool, error) {
+	baseBranch := config.BaseBranchName
+	if baseBranch == "" {
+		baseBranch = repo.GetDefaultBranch()
+	}
+
+	applied, err := changesOnBranch(localRepository, worktree, status, baseBranch)
+	if err != nil || !applied {
+		return false, err
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name":   repo.GetName(),
+		"Base branch": baseBranch,
+	}).Info("Skipping repo because its changes are already on the base branch")
+
+	config.Stats.TrackSingle(stats.ChangesAlreadyOnBaseBranch, repo)
+	return true, nil
+}
+
+// changesOnBranch returns true if each file changed in the supplied worktree, per the supplied status, has the same
+// contents and mode on the supplied branch of the origin remote, or is absent from it if it was deleted. A branch that
+// wasn't fetched with the clone can't be compared against, so its changes are assumed not to be on it
+func changesOnBranch(localRepository *git.Repository, worktree *git.Worktree, status git.Status, branch string) (bool, error) {
+	ref, err := localRepository.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return false, nil
+	}
+	commit, err := localRepository.CommitObject(ref.Hash())
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+
+	for path, fileStatus := range status {
+		deleted := fileStatus.Worktree == git.Deleted || (fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Deleted)
+
+		entry, err := tree.FindEntry(path)
+		if err == object.ErrEntryNotFound || err == object.ErrDirectoryNotFound {
+			if deleted {
+				continue
+			}
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.WithStackTrace(err)
+		}
+		if deleted {
+			return false, nil
+		}
+
+		same, err := sameFile(localRepository, worktree, path, entry)
+		if err != nil || !same {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// sameFile returns true if the file at the supplied path of the supplied worktree has the contents and mode of the
+// supplied tree entry
+func sameFile(localRepository *git.Repository, worktree *git.Worktree, path string, entry *object.TreeEntry) (bool, error) {
+	info, err := worktree.Filesystem.Lstat(path)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil || mode != entry.Mode {
+		return false, nil
+	}
+
+	var contents []byte
+	if mode == filemode.Symlink {
+		target, err := worktree.Filesystem.Readlink(path)
+		if err != nil {
+			return false, errors.WithStackTrace(err)
+		}
+		contents = []byte(target)
+	} else {
+		file, err := worktree.Filesystem.Open(path)
+		if err != nil {
+			return false, errors.WithStackTrace(err)
+		}
+		defer file.Close()
+		if contents, err = ioutil.ReadAll(file); err != nil {
+			return false, errors.WithStackTrace(err)
+		}
+	}
+
+	blob, err := localRepository.BlobObject(entry.Hash)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	defer reader.Close()
+	baseContents, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	return bytes.Equal(contents, baseContents), nil
+}
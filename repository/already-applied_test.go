@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that changes made on a leftover branch are recognized as already applied once the base branch has the same
+// contents for every changed file, and that any difference is still pushed
+func TestSkipChangesAlreadyOnBaseBranch(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-already-applied")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	writeFile := func(name string, contents string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, name), []byte(contents), 0644))
+	}
+	commit := func(message string) plumbing.Hash {
+		_, err := worktree.Add(".")
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &git.CommitOptions{All: true, Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+		require.NoError(t, err)
+		return hash
+	}
+
+	// The base branch already has the changes, while the leftover branch predates them
+	writeFile("README.md", "new")
+	writeFile("ci.yml", "added")
+	baseHash := commit("base")
+	require.NoError(t, localRepository.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "main"), baseHash)))
+
+	writeFile("README.md", "old")
+	writeFile("stale.txt", "stale")
+	require.NoError(t, os.Remove(filepath.Join(repositoryDir, "ci.yml")))
+	commit("leftover")
+
+	// The command makes the same changes again
+	writeFile("README.md", "new")
+	writeFile("ci.yml", "added")
+	require.NoError(t, os.Remove(filepath.Join(repositoryDir, "stale.txt")))
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.BaseBranchName = "main"
+	repo := mocks.GetMockGithubRepo()
+
+	status, err := worktree.Status()
+	require.NoError(t, err)
+	applied, err := skipChangesAlreadyOnBaseBranch(testConfig, repo, localRepository, worktree, status)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Len(t, testConfig.Stats.GetRepos()[stats.ChangesAlreadyOnBaseBranch], 1)
+
+	// A base branch that wasn't fetched can't be compared against
+	applied, err = changesOnBranch(localRepository, worktree, status, "release")
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	// Contents or modes that differ from the base branch are changes of their own
+	writeFile("ci.yml", "changed")
+	status, err = worktree.Status()
+	require.NoError(t, err)
+	applied, err = changesOnBranch(localRepository, worktree, status, "main")
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	writeFile("ci.yml", "added")
+	require.NoError(t, os.Chmod(filepath.Join(repositoryDir, "ci.yml"), 0755))
+	status, err = worktree.Status()
+	require.NoError(t, err)
+	applied, err = changesOnBranch(localRepository, worktree, status, "main")
+	require.NoError(t, err)
+	assert.False(t, applied)
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ApplyPlan executes the supplied plan written by git-xargs plan. Rather than running the command again, the planned
+// file contents are written to each repo, committed, pushed and opened as a pull request with the planned title and
+// description, so that exactly what was reviewed is applied. Repos in which a planned file has changed since the plan
+// was created are skipped as stale
+func ApplyPlan(config *config.GitXargsConfig, p *plan.Plan) error {
+	logger := logging.GetLogger("git-xargs")
+
+	runWorkerPool(len(p.Repos), config.MaxConcurrentRepos, func(index int) error {
+		repoPlan := p.Repos[index]
+
+		repo, applyErr := applyRepoPlan(config, repoPlan)
+		if applyErr != nil {
+			logger.WithFields(logrus.Fields{
+				"Repo name": repoPlan.FullName(), "Error": applyErr,
+			}).Debug("Error encountered while applying plan to repo")
+			config.Stats.TrackError(repo, applyErr)
+		}
+		logStateErr(config.State.RecordOutcome(config.RunID, repo, applyErr), repo)
+		logAuditErr(config.Audit.RepoFinished(repo, applyErr), repo)
+		return applyErr
+	})
+
+	return nil
+}
+
+// applyRepoPlan applies the planned changes to a single repo, returning the repo as looked up via the GitHub API
+func applyRepoPlan(config *config.GitXargsConfig, repoPlan *plan.Repo) (*github.Repository, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	repo, _, err := config.GithubClient.Repositories.Get(config.Context, repoPlan.Owner, repoPlan.Name)
+	if err != nil {
+		repo = &github.Repository{Owner: &github.User{Login: github.String(repoPlan.Owner)}, Name: github.String(repoPlan.Name)}
+		config.Stats.TrackSingle(stats.RepoNotExists, repo)
+		return repo, errors.WithStackTrace(err)
+	}
+
+	repositoryDir, localRepository, err := cloneLocalRepository(config, repo)
+	if err != nil {
+		return repo, err
+	}
+
+	ref, err := getLocalRepoHeadRef(config, localRepository, repo)
+	if err != nil {
+		return repo, err
+	}
+
+	worktree, err := getLocalWorkTree(repositoryDir, localRepository, repo)
+	if err != nil {
+		return repo, err
+	}
+
+	// The plan was created on the branch as checked out and pulled here, so the planned files are compared against it
+	branchName, err := checkoutLocalBranch(config, ref, worktree, repo, localRepository)
+	if err != nil {
+		return repo, err
+	}
+
+	branchRef, err := getLocalRepoHeadRef(config, localRepository, repo)
+	if err != nil {
+		return repo, err
+	}
+
+	stale, err := findStaleFiles(localRepository, branchRef.Hash(), repoPlan.Files)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, repo)
+		return repo, err
+	}
+	if len(stale) > 0 {
+		logger.WithFields(logrus.Fields{
+			"Repo":  repo.GetName(),
+			"Files": stale,
+		}).Info("Skipping repo because planned files have changed since the plan was created")
+
+		config.Stats.TrackSingle(stats.PlanStale, repo)
+		return repo, errors.WithStackTrace(types.StalePlanErr{Repo: repoPlan.FullName(), Files: stale})
+	}
+
+	if err := writeFileChanges(repositoryDir, repoPlan.Files); err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, repo)
+		return repo, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		config.Stats.TrackSingle(stats.WorktreeStatusCheckFailedCommand, repo)
+		return repo, errors.WithStackTrace(err)
+	}
+
+	// The branch already holds the planned changes, for example because the plan was applied before
+	if status.IsClean() {
+		config.Stats.TrackSingle(stats.WorktreeStatusClean, repo)
+		return repo, nil
+	}
+
+	commitHash, err := commitLocalChanges(status, config, repositoryDir, worktree, repo, localRepository)
+	if err != nil {
+		return repo, err
+	}
+
+	pushedHash, err := pushLocalBranch(config, repo, localRepository, branchName.String())
+	if err != nil {
+		return repo, err
+	}
+
+	setCommitStatus(config, repo, pushedHash)
+
+	err = openPullRequestWithContent(config, repo, localRepository, commitHash, branchName.String(), changePart{}, func() (string, string, error) {
+		return repoPlan.PullRequestTitle, repoPlan.PullRequestDescription, nil
+	})
+	if err != nil {
+		return repo, err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+	}).Info("Plan successfully applied to repository")
+
+	return repo, nil
+}
+
+// findStaleFiles returns the planned files whose contents at the supplied commit differ from the ones the plan was
+// created against. A file that already has its planned contents, e.g. because the plan was applied before, isn't stale
+func findStaleFiles(localRepository *git.Repository, headHash plumbing.Hash, files []plan.FileChange) ([]string, error) {
+	headCommit, err := localRepository.CommitObject(headHash)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	stale := []string{}
+	for _, file := range files {
+		current := blobHash(headTree, file.Path)
+		if current != file.BaseBlob && current != plannedBlobHash(file) {
+			stale = append(stale, file.Path)
+		}
+	}
+	return stale, nil
+}
+
+// plannedBlobHash returns the hash of the blob of the planned contents of the supplied file, or an empty string if the
+// plan deletes it, like blobHash does for a file missing from a tree
+func plannedBlobHash(file plan.FileChange) string {
+	if file.Deleted {
+		return ""
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, file.Contents).String()
+}
+
+// writeFileChanges writes the planned contents of every file to the worktree in the supplied directory, and removes
+// the planned deletions. Plans are plain JSON files that can be edited, so nothing is written unless every path is
+// inside the worktree and outside the .git directory
+func writeFileChanges(repositoryDir string, files []plan.FileChange) error {
+	for _, file := range files {
+		if !isRepoRelativePath(file.Path) || strings.SplitN(file.Path, "/", 2)[0] == ".git" {
+			return errors.WithStackTrace(types.InvalidPlanPathErr{Path: file.Path})
+		}
+	}
+
+	for _, file := range files {
+		fullPath := filepath.Join(repositoryDir, filepath.FromSlash(file.Path))
+
+		if file.Deleted {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return errors.WithStackTrace(err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return errors.WithStackTrace(err)
+		}
+
+		mode := filemode.FileMode(file.Mode)
+		if mode == filemode.Symlink {
+			os.Remove(fullPath)
+			if err := os.Symlink(string(file.Contents), fullPath); err != nil {
+				return errors.WithStackTrace(err)
+			}
+			continue
+		}
+
+		osMode, err := mode.ToOSFileMode()
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		if err := ioutil.WriteFile(fullPath, file.Contents, osMode.Perm()); err != nil {
+			return errors.WithStackTrace(err)
+		}
+		if err := os.Chmod(fullPath, osMode.Perm()); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	return nil
+}
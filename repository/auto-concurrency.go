@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// autoConcurrencyInterval is how often --auto-concurrency looks at how the run is going and adjusts the number of
+	// repos processed at once
+	autoConcurrencyInterval = 5 * time.Second
+	// defaultMaxAutoConcurrency is the most repos --auto-concurrency processes at once when --max-concurrent-repos
+	// isn't passed
+	defaultMaxAutoConcurrency = 64
+
+	// Below these, the number of repos processed at once is halved
+	minAPIHeadroom = 0.1
+	minDiskFree    = 0.05
+	// Above these, the number of repos processed at once is halved
+	maxFailureRate = 0.5
+	maxLoadPerCPU  = 1.5
+	// The number of repos processed at once is only raised while there is at least this much headroom left
+	growAPIHeadroom  = 0.25
+	growDiskFree     = 0.1
+	growFailureRate  = 0.2
+	growLoadPerCPU   = 1.0
+	minFinishedRepos = 3
+)
+
+// runPressure is what --auto-concurrency observed about the run and this machine over one interval
+type runPressure struct {
+	// apiHeadroom is the fraction of the GitHub API rate limit window that is left
+	apiHeadroom float64
+	// finished and failed are the number of repos that finished and failed during the interval
+	finished int
+	failed   int
+	// loadPerCPU is the load average of this machine divided by its number of CPUs, or 0 if it isn't known
+	loadPerCPU float64
+	// diskFree is the fraction of the disk the repos are cloned to that is free, or 1 if it isn't known
+	diskFree float64
+}
+
+// failureRate returns the fraction of the repos that finished during the interval that failed
+func (pressure runPressure) failureRate() float64 {
+	if pressure.finished == 0 {
+		return 0
+	}
+	return float64(pressure.failed) / float64(pressure.finished)
+}
+
+// nextConcurrency returns the number of repos to process at once from now on, given the current number, the most
+// allowed, what was observed over the last interval and whether the current number held back any repo, along with the
+// reason for changing it. Like TCP congestion control, it backs off quickly, by halving the number, as soon as the GitHub
+// API, the repos or this machine show signs of strain, and grows slowly while they all have headroom left and the
+// number is what holds the run back
+func nextConcurrency(current int, max int, pressure runPressure, heldBack bool) (int, string) {
+	var reason string
+	switch {
+	case pressure.apiHeadroom < minAPIHeadroom:
+		reason = "The GitHub API rate limit is running low"
+	case pressure.finished >= minFinishedRepos && pressure.failureRate() > maxFailureRate:
+		reason = "Most repos are failing"
+	case pressure.loadPerCPU > maxLoadPerCPU:
+		reason = "The CPUs of this machine are overloaded"
+	case pressure.diskFree < minDiskFree:
+		reason = "The disk repos are cloned to is almost full"
+	}
+	if reason != "" {
+		next := current / 2
+		if next < 1 {
+			next = 1
+		}
+		return next, reason
+	}
+
+	canGrow := heldBack &&
+		pressure.apiHeadroom >= growAPIHeadroom &&
+		pressure.failureRate() <= growFailureRate &&
+		pressure.loadPerCPU < growLoadPerCPU &&
+		pressure.diskFree >= growDiskFree
+	if !canGrow || current >= max {
+		return current, ""
+	}
+
+	next := current + current/4
+	if next == current {
+		next++
+	}
+	if next > max {
+		next = max
+	}
+	return next, "There is headroom left to process more repos at once"
+}
+
+// autoConcurrency adjusts the number of repos in flight as the run goes, for --auto-concurrency. A nil autoConcurrency
+// does nothing, so it can be used whether or not --auto-concurrency was passed
+type autoConcurrency struct {
+	config   *config.GitXargsConfig
+	limit    *concurrencyLimit
+	max      int
+	finished int
+	failed   int
+	mutex    sync.Mutex
+	done     chan struct{}
+	stopped  sync.WaitGroup
+}
+
+// startAutoConcurrency starts adjusting the number of repos in flight if --auto-concurrency was passed, starting from
+// the number of CPUs of this machine, and returns nil otherwise
+func startAutoConcurrency(config *config.GitXargsConfig) *autoConcurrency {
+	if !config.AutoConcurrency {
+		return nil
+	}
+
+	max := config.MaxConcurrentRepos
+	if max <= 0 {
+		max = defaultMaxAutoConcurrency
+	}
+	start := runtime.NumCPU()
+	if start > max {
+		start = max
+	}
+
+	tuner := &autoConcurrency{
+		config: config,
+		limit:  newConcurrencyLimit(start),
+		max:    max,
+		done:   make(chan struct{}),
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Concurrency":     start,
+		"Max concurrency": max,
+	}).Debug("Adjusting the number of repos processed at once as the run goes")
+
+	tuner.stopped.Add(1)
+	go tuner.run()
+	return tuner
+}
+
+// repoFinished records that a repo finished, and whether it failed
+func (tuner *autoConcurrency) repoFinished(err error) {
+	if tuner == nil {
+		return
+	}
+
+	tuner.mutex.Lock()
+	defer tuner.mutex.Unlock()
+	tuner.finished++
+	if err != nil {
+		tuner.failed++
+	}
+}
+
+// stop stops adjusting the number of repos in flight
+func (tuner *autoConcurrency) stop() {
+	if tuner == nil {
+		return
+	}
+	close(tuner.done)
+	tuner.stopped.Wait()
+}
+
+// run adjusts the number of repos in flight every autoConcurrencyInterval, until stop is called
+func (tuner *autoConcurrency) run() {
+	defer tuner.stopped.Done()
+
+	ticker := time.NewTicker(autoConcurrencyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tuner.done:
+			return
+		case <-ticker.C:
+			tuner.adjust(tuner.observe())
+		}
+	}
+}
+
+// observe returns what happened to the run and this machine since the last call
+func (tuner *autoConcurrency) observe() runPressure {
+	tuner.mutex.Lock()
+	finished, failed := tuner.finished, tuner.failed
+	tuner.finished, tuner.failed = 0, 0
+	tuner.mutex.Unlock()
+
+	return runPressure{
+		apiHeadroom: tuner.config.GithubClient.RateLimiter.Headroom(),
+		finished:    finished,
+		failed:      failed,
+		loadPerCPU:  loadAverage() / float64(runtime.NumCPU()),
+		diskFree:    diskFree(os.TempDir()),
+	}
+}
+
+// adjust changes the number of repos in flight based on the supplied observations
+func (tuner *autoConcurrency) adjust(pressure runPressure) {
+	current, heldBack := tuner.limit.current()
+	next, reason := nextConcurrency(current, tuner.max, pressure, heldBack)
+	if next == current {
+		return
+	}
+	tuner.limit.setLimit(next)
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Previous concurrency": current,
+		"Concurrency":          next,
+		"API headroom":         strconv.FormatFloat(pressure.apiHeadroom, 'f', 2, 64),
+		"Failed repos":         pressure.failed,
+		"Finished repos":       pressure.finished,
+		"Load per CPU":         strconv.FormatFloat(pressure.loadPerCPU, 'f', 2, 64),
+		"Disk free":            strconv.FormatFloat(pressure.diskFree, 'f', 2, 64),
+	}).Info(reason + ", adjusting the number of repos processed at once")
+}
+
+// diskFree returns the fraction of the disk the supplied directory is on that is free, or 1 if it isn't known
+func diskFree(dir string) float64 {
+	free, total, ok := util.DiskSpace(dir)
+	if !ok {
+		return 1
+	}
+	return float64(free) / float64(total)
+}
+
+// loadAverage returns the 1 minute load average of this machine, or 0 if it isn't known, such as on machines other than
+// Linux ones
+func loadAverage() float64 {
+	contents, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) == 0 {
+		return 0
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return load
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextConcurrency(t *testing.T) {
+	t.Parallel()
+
+	healthy := runPressure{apiHeadroom: 0.8, finished: 10, failed: 1, loadPerCPU: 0.5, diskFree: 0.5}
+
+	testCases := []struct {
+		name          string
+		current       int
+		pressure      runPressure
+		heldBack      bool
+		expectNext    int
+		expectChanged bool
+	}{
+		{"grows while held back", 8, healthy, true, 10, true},
+		{"grows by at least one", 2, healthy, true, 3, true},
+		{"grows up to the max", 15, healthy, true, 16, true},
+		{"stays at the max", 16, healthy, true, 16, false},
+		{"stays when not held back", 8, healthy, false, 8, false},
+		{"stays with little API headroom", 8, runPressure{apiHeadroom: 0.2, diskFree: 1}, true, 8, false},
+		{"halves when the rate limit runs low", 8, runPressure{apiHeadroom: 0.05, diskFree: 1}, true, 4, true},
+		{"halves when most repos fail", 8, runPressure{apiHeadroom: 1, finished: 4, failed: 3, diskFree: 1}, false, 4, true},
+		{"ignores failures of too few repos", 8, runPressure{apiHeadroom: 1, finished: 2, failed: 2, diskFree: 1}, false, 8, false},
+		{"halves when the CPUs are overloaded", 8, runPressure{apiHeadroom: 1, loadPerCPU: 2, diskFree: 1}, true, 4, true},
+		{"halves when the disk is almost full", 8, runPressure{apiHeadroom: 1, diskFree: 0.01}, true, 4, true},
+		{"never goes below one", 1, runPressure{apiHeadroom: 0, diskFree: 1}, true, 1, true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			next, reason := nextConcurrency(testCase.current, 16, testCase.pressure, testCase.heldBack)
+			assert.Equal(t, testCase.expectNext, next)
+			assert.Equal(t, testCase.expectChanged, reason != "")
+		})
+	}
+}
+
+func TestConcurrencyLimitCanBeRaisedWhileReposWait(t *testing.T) {
+	t.Parallel()
+
+	limit := newConcurrencyLimit(1)
+	limit.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		limit.acquire()
+		close(acquired)
+	}()
+
+	// Raising the limit lets the waiting repo in without any repo finishing
+	for {
+		if _, heldBack := limit.current(); heldBack {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	limit.setLimit(2)
+	<-acquired
+
+	limit.mutex.Lock()
+	defer limit.mutex.Unlock()
+	assert.Equal(t, 2, limit.limit)
+	assert.Equal(t, 2, limit.inUse)
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// branchProtection holds the protection rules of the base branch of a repo that decide whether the changes of a run can
+// land on it
+type branchProtection struct {
+	requiredChecks       []string
+	requiredApprovals    int
+	requireLinearHistory bool
+	requireSignedCommits bool
+}
+
+// checkBranchProtection looks up the protection rules of the base branch of the repo of the supplied job before any work
+// is done on it, if --check-branch-protection was passed, so that changes the rules would block are predicted rather
+// than discovered when merging. Where it can, the job adapts to the rules, e.g. by squashing pull requests into a base
+// branch that requires a linear history. Otherwise the blocked step is skipped and the repo is listed in the run report.
+// Pushing straight to a base branch that only takes pull requests fails the repo up front, and a base branch that
+// requires signed commits is left to skipRepoRequiringSignedCommits
+func checkBranchProtection(job *repoJob) error {
+	config, repo := job.config, job.repo
+	if !config.CheckBranchProtection {
+		return nil
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	baseBranch := config.BaseBranchName
+	if baseBranch == "" {
+		baseBranch = repo.GetDefaultBranch()
+	}
+
+	protection, err := lookupBranchProtection(config, repo, baseBranch)
+	if err != nil {
+		// Reading branch protection takes admin rights on the repo, which the token may lack, so a failed lookup is
+		// reported without failing the repo
+		logger.WithFields(logrus.Fields{
+			"Error":  err,
+			"Repo":   repo.GetName(),
+			"Branch": baseBranch,
+		}).Debug("Error looking up the protection of the base branch")
+
+		config.Stats.TrackSingle(stats.BranchProtectionLookupErr, repo)
+		return nil
+	}
+	if protection == nil {
+		return nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Repo":                   repo.GetName(),
+		"Branch":                 baseBranch,
+		"Required checks":        strings.Join(protection.requiredChecks, ", "),
+		"Required approvals":     protection.requiredApprovals,
+		"Require linear history": protection.requireLinearHistory,
+		"Require signed commits": protection.requireSignedCommits,
+	}).Debug("Base branch is protected")
+
+	if config.SkipPullRequests && (len(protection.requiredChecks) > 0 || protection.requiredApprovals > 0) {
+		config.Stats.TrackSingle(stats.BaseBranchRequiresPullRequest, repo)
+		return errors.WithStackTrace(types.BaseBranchRequiresPullRequestErr{Repo: repo.GetFullName(), Branch: baseBranch})
+	}
+
+	job.requireSignedCommits = protection.requireSignedCommits
+
+	repoConfig := *config
+	if repoConfig.ApproveAndMerge && protection.requireLinearHistory && repoConfig.MergeMethod == "merge" {
+		config.Stats.TrackSingle(stats.MergeMethodAdaptedToLinearHistory, repo)
+		repoConfig.MergeMethod = "squash"
+	}
+	if repoConfig.ApproveAndMerge && protection.requiredApprovals > 1 {
+		config.Stats.TrackSingle(stats.ApproveAndMergeNeedsApprovals, repo)
+		repoConfig.ApproveAndMerge = false
+	}
+	job.config = &repoConfig
+	return nil
+}
+
+// lookupBranchProtection returns the protection rules of the supplied branch of the supplied repo, or nil if the branch
+// isn't protected
+func lookupBranchProtection(config *config.GitXargsConfig, repo *github.Repository, branch string) (*branchProtection, error) {
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+	protection, resp, err := config.GithubClient.Repositories.GetBranchProtection(config.Context, owner, name, branch)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, errors.WithStackTrace(err)
+	}
+
+	result := &branchProtection{}
+	if checks := protection.GetRequiredStatusChecks(); checks != nil {
+		result.requiredChecks = checks.Contexts
+	}
+	if reviews := protection.GetRequiredPullRequestReviews(); reviews != nil {
+		result.requiredApprovals = reviews.RequiredApprovingReviewCount
+	}
+	if linearHistory := protection.GetRequireLinearHistory(); linearHistory != nil {
+		result.requireLinearHistory = linearHistory.Enabled
+	}
+
+	signatures, resp, err := config.GithubClient.Repositories.GetSignaturesProtectedBranch(config.Context, owner, name, branch)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return nil, errors.WithStackTrace(err)
+	}
+	result.requireSignedCommits = signatures.GetEnabled()
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that repos whose base branch isn't protected are left as they are
+func TestCheckBranchProtectionUnprotected(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.CheckBranchProtection = true
+	testConfig.ApproveAndMerge = true
+
+	job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+	require.NoError(t, checkBranchProtection(job))
+	assert.Same(t, testConfig, job.config)
+}
+
+// Test that --approve-and-merge squashes into a base branch that requires a linear history, and that a base branch that
+// requires signed commits is noted for skipRepoRequiringSignedCommits
+func TestCheckBranchProtectionAdaptsMerge(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithBranchProtection(&github.Protection{
+		RequireLinearHistory:       &github.RequireLinearHistory{Enabled: true},
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{RequiredApprovingReviewCount: 1},
+	}, false)
+	testConfig.CheckBranchProtection = true
+	testConfig.ApproveAndMerge = true
+	testConfig.MergeMethod = "merge"
+
+	job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+	require.NoError(t, checkBranchProtection(job))
+	assert.True(t, job.config.ApproveAndMerge)
+	assert.Equal(t, "squash", job.config.MergeMethod)
+	assert.Equal(t, "merge", testConfig.MergeMethod)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.MergeMethodAdaptedToLinearHistory), 1)
+
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithBranchProtection(&github.Protection{}, true)
+	job = &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+	require.NoError(t, checkBranchProtection(job))
+	assert.True(t, job.requireSignedCommits)
+	assert.True(t, skipRepoRequiringSignedCommits(job))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.BaseBranchRequiresSignedCommits), 1)
+}
+
+// Test that pushing straight to a base branch that requires status checks fails the repo before it is cloned
+func TestCheckBranchProtectionRejectsDirectPush(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithBranchProtection(&github.Protection{
+		RequiredStatusChecks: &github.RequiredStatusChecks{Contexts: []string{"build"}},
+	}, false)
+	testConfig.CheckBranchProtection = true
+	testConfig.SkipPullRequests = true
+
+	err := checkBranchProtection(&repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig})
+	assert.IsType(t, types.BaseBranchRequiresPullRequestErr{}, errors.Unwrap(err))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.BaseBranchRequiresPullRequest), 1)
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+const (
+	// ChecksNone denotes a commit with no commit statuses or check runs
+	ChecksNone = "none"
+	// ChecksPending denotes a commit with at least one commit status or check run that hasn't finished yet
+	ChecksPending = "pending"
+	// ChecksFailing denotes a commit with at least one failed commit status or check run
+	ChecksFailing = "failing"
+	// ChecksPassing denotes a commit whose commit statuses and check runs all passed
+	ChecksPassing = "passing"
+)
+
+// getChecksState combines the commit statuses and the check runs, such as GitHub Actions jobs, reported for the
+// supplied commit into a single state. Failures take precedence over pending checks, which take precedence over
+// passing ones
+func getChecksState(config *config.GitXargsConfig, repo *github.Repository, sha string) (string, error) {
+	owner := repo.GetOwner().GetLogin()
+
+	combined, _, err := config.GithubClient.Repositories.GetCombinedStatus(config.Context, owner, repo.GetName(), sha, nil)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	checkRuns, err := listCheckRuns(config, repo, sha)
+	if err != nil {
+		return "", err
+	}
+
+	seen, pending, failing := false, false, false
+
+	if combined.GetTotalCount() > 0 {
+		seen = true
+		switch combined.GetState() {
+		case "pending":
+			pending = true
+		case "failure", "error":
+			failing = true
+		}
+	}
+
+	for _, checkRun := range checkRuns {
+		seen = true
+		if checkRun.GetStatus() != "completed" {
+			pending = true
+			continue
+		}
+		switch checkRun.GetConclusion() {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			failing = true
+		}
+	}
+
+	switch {
+	case failing:
+		return ChecksFailing, nil
+	case pending:
+		return ChecksPending, nil
+	case seen:
+		return ChecksPassing, nil
+	}
+	return ChecksNone, nil
+}
+
+// getRequiredChecksState combines the results of the supplied required checks for the supplied commit into a single
+// state, matching them by the context of commit statuses and the name of check runs, like GitHub does. Other checks are
+// left out, so that a failing optional check doesn't hold up a merge. A required check that hasn't reported yet is
+// pending. Neutral and skipped check runs pass, as they do for branch protection
+func getRequiredChecksState(config *config.GitXargsConfig, repo *github.Repository, sha string, required []string) (string, error) {
+	owner := repo.GetOwner().GetLogin()
+
+	combined, _, err := config.GithubClient.Repositories.GetCombinedStatus(config.Context, owner, repo.GetName(), sha, nil)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	checkRuns, err := listCheckRuns(config, repo, sha)
+	if err != nil {
+		return "", err
+	}
+
+	results := map[string]string{}
+	for _, status := range combined.Statuses {
+		switch status.GetState() {
+		case "success":
+			results[status.GetContext()] = ChecksPassing
+		case "failure", "error":
+			results[status.GetContext()] = ChecksFailing
+		default:
+			results[status.GetContext()] = ChecksPending
+		}
+	}
+	for _, checkRun := range checkRuns {
+		switch {
+		case checkRun.GetStatus() != "completed":
+			results[checkRun.GetName()] = ChecksPending
+		case checkRun.GetConclusion() == "success" || checkRun.GetConclusion() == "neutral" || checkRun.GetConclusion() == "skipped":
+			results[checkRun.GetName()] = ChecksPassing
+		default:
+			results[checkRun.GetName()] = ChecksFailing
+		}
+	}
+
+	state := ChecksPassing
+	for _, check := range required {
+		switch results[check] {
+		case ChecksFailing:
+			return ChecksFailing, nil
+		case ChecksPassing:
+		default:
+			state = ChecksPending
+		}
+	}
+	return state, nil
+}
+
+// listCheckRuns returns every check run reported for the supplied commit, across all pages of results
+func listCheckRuns(config *config.GitXargsConfig, repo *github.Repository, sha string) ([]*github.CheckRun, error) {
+	checkRuns := []*github.CheckRun{}
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		results, resp, err := config.GithubClient.Checks.ListCheckRunsForRef(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), sha, opts)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		checkRuns = append(checkRuns, results.CheckRuns...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return checkRuns, nil
+}
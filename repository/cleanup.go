@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// CleanupStaleBranches deletes the git-xargs branches in the supplied repos whose pull requests have all been merged or
+// closed. A branch belongs to git-xargs if its name matches --branch-pattern or, when no pattern is passed, if its head
+// commit carries the run ID trailer, optionally restricted to the run passed via --run-id. Default and protected
+// branches, and branches that never had a pull request, are always kept. With --dry-run, the branches that would be
+// deleted are only reported
+func CleanupStaleBranches(config *config.GitXargsConfig, repos []*github.Repository) error {
+	logger := logging.GetLogger("git-xargs")
+
+	var branchPattern *regexp.Regexp
+	if config.BranchPattern != "" {
+		pattern, err := regexp.Compile(config.BranchPattern)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		branchPattern = pattern
+	}
+
+	for _, repo := range repos {
+		branches, err := listBranches(config, repo)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  repo.GetName(),
+			}).Debug("Error listing branches")
+
+			config.Stats.TrackSingle(stats.BranchListErr, repo)
+			continue
+		}
+
+		for _, branch := range branches {
+			if branch.GetName() == repo.GetDefaultBranch() || branch.GetProtected() {
+				continue
+			}
+
+			stale, err := isStaleGitXargsBranch(config, repo, branch, branchPattern)
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"Error":  err,
+					"Repo":   repo.GetName(),
+					"Branch": branch.GetName(),
+				}).Debug("Error checking whether branch is stale")
+
+				config.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+				continue
+			}
+			if !stale {
+				continue
+			}
+
+			if config.DryRun {
+				logger.WithFields(logrus.Fields{
+					"Repo":   repo.GetName(),
+					"Branch": branch.GetName(),
+				}).Info("Would delete stale branch, but --dry-run is set")
+
+				config.Stats.TrackSingle(stats.StaleBranchDeleteSkipped, repo)
+				continue
+			}
+
+			deleteRemoteBranch(config, repo, branch.GetName())
+		}
+	}
+
+	return nil
+}
+
+// listBranches returns every branch in the supplied repo, following pagination
+func listBranches(config *config.GitXargsConfig, repo *github.Repository) ([]*github.Branch, error) {
+	allBranches := []*github.Branch{}
+
+	opts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, resp, err := config.GithubClient.Repositories.ListBranches(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), opts)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		allBranches = append(allBranches, branches...)
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allBranches, nil
+}
+
+// isStaleGitXargsBranch returns true if the supplied branch belongs to git-xargs and all of its pull requests have been
+// merged or closed
+func isStaleGitXargsBranch(config *config.GitXargsConfig, repo *github.Repository, branch *github.Branch, branchPattern *regexp.Regexp) (bool, error) {
+	owner := repo.GetOwner().GetLogin()
+
+	if branchPattern != nil {
+		if !branchPattern.MatchString(branch.GetName()) {
+			return false, nil
+		}
+	} else {
+		commit, _, err := config.GithubClient.Repositories.GetCommit(config.Context, owner, repo.GetName(), branch.GetCommit().GetSHA())
+		if err != nil {
+			return false, errors.WithStackTrace(err)
+		}
+		if !hasRunIDTrailer(commit.GetCommit().GetMessage(), config.RunIDSupplied, config.RunID) {
+			return false, nil
+		}
+	}
+
+	opts := &github.PullRequestListOptions{
+		State: "all",
+		Head:  fmt.Sprintf("%s:%s", owner, branch.GetName()),
+	}
+	prs, _, err := config.GithubClient.PullRequests.List(config.Context, owner, repo.GetName(), opts)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+
+	return allPullRequestsFinished(prs), nil
+}
+
+// hasRunIDTrailer returns true if the supplied commit message carries the run ID trailer, for the supplied run ID if
+// onlyRunID is set, or for any run otherwise
+func hasRunIDTrailer(message string, onlyRunID bool, runID string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		value := strings.TrimPrefix(line, common.RunIDTrailerKey+": ")
+		if value == line {
+			continue
+		}
+		if !onlyRunID || strings.TrimSpace(value) == runID {
+			return true
+		}
+	}
+	return false
+}
+
+// allPullRequestsFinished returns true if there is at least one pull request in the supplied list, and none of them are
+// still open
+func allPullRequestsFinished(prs []*github.PullRequest) bool {
+	if len(prs) == 0 {
+		return false
+	}
+	for _, pr := range prs {
+		if pr.GetState() == "open" {
+			return false
+		}
+	}
+	return true
+}
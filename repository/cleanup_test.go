@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasRunIDTrailer(t *testing.T) {
+	t.Parallel()
+
+	message := "Update CI\n\nGit-Xargs-Run-Id: run-1"
+	assert.True(t, hasRunIDTrailer(message, false, ""))
+	assert.True(t, hasRunIDTrailer(message, true, "run-1"))
+	assert.False(t, hasRunIDTrailer(message, true, "run-2"))
+	assert.False(t, hasRunIDTrailer("Update CI", false, ""))
+}
+
+func TestAllPullRequestsFinished(t *testing.T) {
+	t.Parallel()
+
+	merged := &github.PullRequest{State: github.String("closed"), Merged: github.Bool(true)}
+	open := &github.PullRequest{State: github.String("open")}
+
+	assert.True(t, allPullRequestsFinished([]*github.PullRequest{merged}))
+	assert.False(t, allPullRequestsFinished([]*github.PullRequest{merged, open}))
+	assert.False(t, allPullRequestsFinished(nil))
+}
+
+// Test that git-xargs branches whose pull requests are still open are kept
+func TestCleanupStaleBranchesKeepsBranchesWithOpenPullRequests(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	require.NoError(t, CleanupStaleBranches(testConfig, []*github.Repository{mocks.GetMockGithubRepo()}))
+
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.BranchDeleted))
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.BranchListErr))
+}
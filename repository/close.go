@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/sirupsen/logrus"
+)
+
+// CloseRunPullRequests closes every pull request opened by the run passed via --run-id that is still open, after
+// adding the rendered --close-comment to it. If --delete-branch was passed, the branch of every closed pull request is
+// deleted afterwards
+func CloseRunPullRequests(config *config.GitXargsConfig) error {
+	logger := logging.GetLogger("git-xargs")
+
+	return forEachRunPullRequest(config, config.RunID, func(repo *github.Repository, recordedPR state.PullRequest) {
+		repoConfig, err := withRepoHost(config, repo)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up the GitHub host of pull request")
+
+			config.Stats.TrackError(repo, err)
+			return
+		}
+		pr, _, err := repoConfig.GithubClient.PullRequests.Get(repoConfig.Context, repo.GetOwner().GetLogin(), repo.GetName(), recordedPR.Number)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up pull request")
+
+			repoConfig.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+			return
+		}
+
+		if pr.GetState() != "open" {
+			logger.WithFields(logrus.Fields{
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Skipping pull request that is no longer open")
+			return
+		}
+
+		addCloseComment(repoConfig, repo, pr)
+
+		update := &github.PullRequest{State: github.String("closed")}
+		if _, _, err := repoConfig.GithubClient.PullRequests.Edit(repoConfig.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), update); err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Error closing pull request")
+
+			repoConfig.Stats.TrackSingle(stats.PullRequestCloseErr, repo)
+			return
+		}
+
+		repoConfig.Stats.TrackSingle(stats.PullRequestClosed, repo)
+
+		if repoConfig.DeleteBranch {
+			deleteRemoteBranch(repoConfig, repo, pr.GetHead().GetRef())
+		}
+	})
+}
+
+// addCloseComment adds the rendered --close-comment to the supplied pull request, unless it is empty. Failures are
+// tracked, but don't stop the pull request from being closed
+func addCloseComment(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	logger := logging.GetLogger("git-xargs")
+
+	body, err := util.RenderTemplate(config.CloseComment, newTemplateData(config, repo))
+	if err == nil && body == "" {
+		return
+	}
+	if err == nil {
+		comment := &github.IssueComment{Body: github.String(body)}
+		_, _, err = config.GithubClient.Issues.CreateComment(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), comment)
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error adding closing comment to pull request")
+
+		config.Stats.TrackSingle(stats.PullRequestCommentErr, repo)
+	}
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the open pull requests of an earlier run are closed, and their branches deleted
+func TestCloseRunPullRequests(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.RunID = "run-1"
+	testConfig.DeleteBranch = true
+
+	store, cleanup := newTestStateStore(t, testConfig.RunID)
+	defer cleanup()
+	testConfig.State = store
+
+	require.NoError(t, CloseRunPullRequests(testConfig))
+
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestClosed), 1)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.BranchDeleted), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestCommentErr))
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/events"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ParseFileChanges reads the files passed via --put-file, in the format of <repo-path>=<local-path>, and combines them
+// with the paths passed via --delete-file into the file changes to make via the GitHub Contents API
+func ParseFileChanges(putFiles []string, deleteFiles []string) ([]types.FileChange, error) {
+	var changes []types.FileChange
+
+	for _, putFile := range putFiles {
+		parts := strings.SplitN(putFile, "=", 2)
+		if len(parts) != 2 || strings.Trim(strings.TrimSpace(parts[0]), "/") == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.WithStackTrace(types.InvalidPutFileFlagErr{PutFile: putFile})
+		}
+		content, err := ioutil.ReadFile(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		changes = append(changes, types.FileChange{Path: strings.Trim(strings.TrimSpace(parts[0]), "/"), Content: content})
+	}
+
+	for _, deleteFile := range deleteFiles {
+		path := strings.Trim(strings.TrimSpace(deleteFile), "/")
+		if path == "" {
+			return nil, errors.WithStackTrace(types.InvalidDeleteFileFlagErr{DeleteFile: deleteFile})
+		}
+		changes = append(changes, types.FileChange{Path: path, Delete: true})
+	}
+
+	return changes, nil
+}
+
+// contentsStage makes the file changes passed via --put-file and --delete-file to the repo of the supplied job through
+// the GitHub Contents API, one commit per changed file, so that simple changes don't need the repo to be cloned at all.
+// It takes the place of the clone, command and push stages when there are file changes to make
+func contentsStage(job *repoJob) error {
+	config, repo := job.config, job.repo
+
+	// If --resume was passed, skip the repos the interrupted run already got through
+	if alreadyProcessedByResumedRun(config, repo) {
+		job.finished = true
+		return nil
+	}
+
+	// If --skip-repos-with-open-pull-requests was passed, skip the repos that already have an open pull request
+	if skip, err := skipRepoWithOpenPullRequest(config, repo); skip || err != nil {
+		job.finished = skip
+		return err
+	}
+
+	// If --check-branch-protection was passed, predict what the protection of the base branch would block
+	if err := checkBranchProtection(job); err != nil {
+		return err
+	}
+	config = job.config
+
+	logger := logging.GetLogger("git-xargs")
+
+	baseBranch := config.BaseBranchName
+	if baseBranch == "" {
+		baseBranch = repo.GetDefaultBranch()
+	}
+
+	// With --skip-pull-requests, the changes are committed directly to the base branch, like they would be pushed to it
+	branch := config.BranchName
+	if config.SkipPullRequests {
+		branch = baseBranch
+	}
+
+	changes, err := pendingFileChanges(config, repo, branch, baseBranch)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		logger.WithFields(logrus.Fields{
+			"Repo": repo.GetName(),
+		}).Debug("Skipping repo because none of its files would change")
+
+		config.Stats.TrackSingle(stats.WorktreeStatusClean, repo)
+		job.finished = true
+		return nil
+	}
+	config.Stats.TrackSingle(stats.WorktreeStatusDirty, repo)
+
+	if config.DryRun {
+		for _, change := range changes {
+			logger.WithFields(logrus.Fields{
+				"Repo":   repo.GetName(),
+				"File":   change.Path,
+				"Delete": change.Delete,
+			}).Info("Would change file via the GitHub Contents API, but --dry-run is set")
+		}
+
+		config.Stats.TrackSingle(stats.PushBranchSkipped, repo)
+		job.finished = true
+		return nil
+	}
+
+	branchCreated, err := ensureRemoteBranch(config, repo, branch, baseBranch)
+	if err != nil {
+		return err
+	}
+
+	endPhase := startPhase(config, repo, types.PhasePush)
+	commitSHA, err := commitFileChanges(config, repo, branch, changes)
+	endPhase()
+	if err != nil {
+		return err
+	}
+
+	// The branch was created for nothing if all of the files turned out to be unchanged on it after all
+	if commitSHA == "" {
+		if branchCreated {
+			if _, err := config.GithubClient.Git.DeleteRef(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), "heads/"+branch); err != nil {
+				logger.WithFields(logrus.Fields{
+					"Error":  err,
+					"Repo":   repo.GetName(),
+					"Branch": branch,
+				}).Debug("Error deleting branch that ended up without changes")
+			}
+		}
+		job.finished = true
+		return nil
+	}
+
+	commitHash := plumbing.NewHash(commitSHA)
+	recordCheckpoint(config, repo, state.CheckpointPushed)
+	config.Events.Repo(events.BranchPushed, repo)
+	recordPushedBranch(config, repo, types.PushedBranch{Name: plumbing.ReferenceName(branch).Short(), CommitSHA: commitSHA})
+	setCommitStatus(config, repo, commitHash)
+
+	if config.SkipPullRequests {
+		config.Stats.TrackSingle(stats.CommitsMadeDirectlyToBranch, repo)
+		config.Stats.TrackSingle(stats.DirectCommitsPushedToRemoteBranch, repo)
+	}
+
+	job.branchName = branch
+	if !config.SkipPullRequests {
+		job.pendingPullRequests = []pendingPullRequest{{commitHash: commitHash, branchName: branch}}
+	}
+	return nil
+}
+
+// pendingFileChanges returns the file changes that would actually change the supplied repo, leaving out the files that
+// already have the content they'd be given, and the files to delete that don't exist. The files are looked up on the
+// branch if it already exists, e.g. from a previous run, and on the base branch otherwise
+func pendingFileChanges(config *config.GitXargsConfig, repo *github.Repository, branch string, baseBranch string) ([]types.FileChange, error) {
+	var pending []types.FileChange
+
+	ref := branch
+	if _, exists, err := lookupRemoteBranch(config, repo, branch); err != nil {
+		return nil, err
+	} else if !exists {
+		ref = baseBranch
+	}
+
+	for _, change := range config.FileChanges {
+		existing, exists, err := getRemoteFile(config, repo, change.Path, ref)
+		if err != nil {
+			return nil, err
+		}
+		if change.Delete && !exists {
+			continue
+		}
+		if !change.Delete && exists && existing == string(change.Content) {
+			continue
+		}
+		pending = append(pending, change)
+	}
+
+	return pending, nil
+}
+
+// ensureRemoteBranch creates the supplied branch in the repo from the head of the base branch, if it doesn't exist
+// yet, and returns true if it was created
+func ensureRemoteBranch(config *config.GitXargsConfig, repo *github.Repository, branch string, baseBranch string) (bool, error) {
+	_, exists, err := lookupRemoteBranch(config, repo, branch)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		config.Stats.TrackSingle(stats.TargetBranchAlreadyExists, repo)
+		return false, nil
+	}
+	config.Stats.TrackSingle(stats.TargetBranchNotFound, repo)
+
+	baseSHA, baseExists, err := lookupRemoteBranch(config, repo, baseBranch)
+	if err != nil {
+		return false, err
+	}
+	if !baseExists {
+		config.Stats.TrackSingle(stats.BaseBranchTargetInvalidErr, repo)
+		return false, errors.WithStackTrace(types.BaseBranchNotFoundErr{Repo: repo.GetFullName(), Branch: baseBranch})
+	}
+
+	reference := &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.String(baseSHA)},
+	}
+	if _, _, err := config.GithubClient.Git.CreateRef(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), reference); err != nil {
+		config.Stats.TrackSingle(stats.PushBranchFailed, repo)
+		return false, errors.WithStackTrace(err)
+	}
+
+	config.Stats.TrackSingle(stats.TargetBranchSuccessfullyCreated, repo)
+	return true, nil
+}
+
+// lookupRemoteBranch returns the SHA of the head of the supplied branch of the repo, and whether the branch exists
+func lookupRemoteBranch(config *config.GitXargsConfig, repo *github.Repository, branch string) (string, bool, error) {
+	reference, resp, err := config.GithubClient.Git.GetRef(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), "heads/"+branch)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		config.Stats.TrackSingle(stats.TargetBranchLookupErr, repo)
+		return "", false, errors.WithStackTrace(err)
+	}
+	return reference.GetObject().GetSHA(), true, nil
+}
+
+// getRemoteFile returns the content of the file at the supplied path of the repo, as of the supplied ref, and whether
+// the file exists
+func getRemoteFile(config *config.GitXargsConfig, repo *github.Repository, path string, ref string) (string, bool, error) {
+	file, _, resp, err := config.GithubClient.Repositories.GetContents(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, errors.WithStackTrace(err)
+	}
+	// The path of a directory is returned as a listing of it rather than a file, and can't be changed like a file
+	if file == nil {
+		return "", false, errors.WithStackTrace(types.ContentsPathIsDirectoryErr{Repo: repo.GetFullName(), Path: path})
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return "", false, errors.WithStackTrace(err)
+	}
+	return content, true, nil
+}
+
+// commitFileChanges makes each of the supplied file changes on the branch of the repo through the GitHub Contents API,
+// which commits every file separately, and returns the SHA of the last commit made, or an empty string if no file
+// needed to change after all
+func commitFileChanges(config *config.GitXargsConfig, repo *github.Repository, branch string, changes []types.FileChange) (string, error) {
+	logger := logging.GetLogger("git-xargs")
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+	message := commitMessageWithMarkers(config)
+
+	var commitSHA string
+	for _, change := range changes {
+		existing, err := getRemoteFileSHA(config, repo, change.Path, branch)
+		if err != nil {
+			return "", err
+		}
+
+		opts := &github.RepositoryContentFileOptions{
+			Message: github.String(message),
+			Branch:  github.String(branch),
+		}
+
+		var result *github.RepositoryContentResponse
+		switch {
+		case change.Delete && existing == "":
+			continue
+		case change.Delete:
+			opts.SHA = github.String(existing)
+			result, _, err = config.GithubClient.Repositories.DeleteFile(config.Context, owner, name, change.Path, opts)
+		case existing == "":
+			opts.Content = change.Content
+			result, _, err = config.GithubClient.Repositories.CreateFile(config.Context, owner, name, change.Path, opts)
+		default:
+			opts.Content = change.Content
+			opts.SHA = github.String(existing)
+			result, _, err = config.GithubClient.Repositories.UpdateFile(config.Context, owner, name, change.Path, opts)
+		}
+
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  name,
+				"File":  change.Path,
+			}).Debug("Error changing file via the GitHub Contents API")
+
+			config.Stats.TrackSingle(stats.CommitChangesFailed, repo)
+			return "", errors.WithStackTrace(err)
+		}
+		commitSHA = result.Commit.GetSHA()
+
+		logger.WithFields(logrus.Fields{
+			"Repo":   name,
+			"File":   change.Path,
+			"Delete": change.Delete,
+			"Commit": commitSHA,
+		}).Debug("Changed file via the GitHub Contents API")
+	}
+
+	return commitSHA, nil
+}
+
+// getRemoteFileSHA returns the blob SHA of the file at the supplied path of the repo, as of the supplied branch, which
+// the Contents API requires to update or delete it, or an empty string if the file doesn't exist
+func getRemoteFileSHA(config *config.GitXargsConfig, repo *github.Repository, path string, branch string) (string, error) {
+	file, _, resp, err := config.GithubClient.Repositories.GetContents(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), path, &github.RepositoryContentGetOptions{Ref: branch})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", errors.WithStackTrace(err)
+	}
+	if file == nil {
+		return "", errors.WithStackTrace(types.ContentsPathIsDirectoryErr{Repo: repo.GetFullName(), Path: path})
+	}
+	return file.GetSHA(), nil
+}
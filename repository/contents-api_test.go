@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that --put-file values are read from their local paths, and that malformed values are rejected
+func TestParseFileChanges(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-put-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "CODEOWNERS")
+	require.NoError(t, ioutil.WriteFile(localPath, []byte("* @platform\n"), 0644))
+
+	changes, err := ParseFileChanges([]string{".github/CODEOWNERS=" + localPath}, []string{"/.travis.yml"})
+	require.NoError(t, err)
+	assert.Equal(t, []types.FileChange{
+		{Path: ".github/CODEOWNERS", Content: []byte("* @platform\n")},
+		{Path: ".travis.yml", Delete: true},
+	}, changes)
+
+	_, err = ParseFileChanges([]string{"CODEOWNERS"}, nil)
+	assert.Error(t, err)
+
+	_, err = ParseFileChanges(nil, []string{"/"})
+	assert.Error(t, err)
+}
+
+// Test that file changes are made via the Contents API on a newly created branch, leaving out the ones that wouldn't
+// change anything, and that a pull request is left to be opened
+func TestContentsStageChangesFiles(t *testing.T) {
+	t.Parallel()
+
+	contents := map[string]string{"README.md": "old", "LICENSE": "MIT", "unchanged.txt": "same"}
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithContents(contents)
+	testConfig.BaseBranchName = "master"
+	testConfig.FileChanges = []types.FileChange{
+		{Path: "README.md", Content: []byte("new")},
+		{Path: "CHANGELOG.md", Content: []byte("# Changelog")},
+		{Path: "unchanged.txt", Content: []byte("same")},
+		{Path: "LICENSE", Delete: true},
+		{Path: "missing.txt", Delete: true},
+	}
+
+	job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+	require.NoError(t, contentsStage(job))
+
+	assert.Equal(t, map[string]string{"README.md": "new", "CHANGELOG.md": "# Changelog", "unchanged.txt": "same"}, contents)
+	assert.False(t, job.finished)
+	assert.Len(t, job.pendingPullRequests, 1)
+	assert.Equal(t, testConfig.BranchName, job.branchName)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.TargetBranchSuccessfullyCreated), 1)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.WorktreeStatusDirty), 1)
+
+	branches := testConfig.Stats.GetBranches()["gruntwork-io/terragrunt"]
+	require.Len(t, branches, 1)
+	assert.Equal(t, testConfig.BranchName, branches[0].Name)
+	assert.NotEmpty(t, branches[0].CommitSHA)
+}
+
+// Test that a repo whose files already match the file changes is skipped without creating a branch
+func TestContentsStageSkipsUnchangedRepo(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithContents(map[string]string{"README.md": "same"})
+	testConfig.BaseBranchName = "master"
+	testConfig.FileChanges = []types.FileChange{{Path: "README.md", Content: []byte("same")}}
+
+	job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+	require.NoError(t, contentsStage(job))
+
+	assert.True(t, job.finished)
+	assert.Empty(t, job.pendingPullRequests)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.WorktreeStatusClean), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.TargetBranchSuccessfullyCreated))
+}
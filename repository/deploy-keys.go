@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// deployKeyPath returns the path of the SSH deploy key of the supplied repo in --deploy-keys-dir, which holds the key of
+// each repo as <org>/<repo>, or an empty string if the repo has none
+func deployKeyPath(config *config.GitXargsConfig, repo *github.Repository) string {
+	if config.DeployKeysDir == "" {
+		return ""
+	}
+
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+	for _, path := range []string{
+		filepath.Join(config.DeployKeysDir, owner, name),
+		filepath.Join(config.DeployKeysDir, strings.ToLower(owner), strings.ToLower(name)),
+	} {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// gitRemote returns the URL to clone the supplied repo from, and the credentials to clone, pull and push it with. Repos
+// with a deploy key in --deploy-keys-dir are reached over SSH with that key, so that repos the GitHub token can't write
+// to can still be pushed to. Other repos are reached over HTTPS with the GitHub token
+func gitRemote(config *config.GitXargsConfig, repo *github.Repository) (string, transport.AuthMethod, error) {
+	if keyPath := deployKeyPath(config, repo); keyPath != "" {
+		auth, err := gitssh.NewPublicKeysFromFile("git", keyPath, os.Getenv("GIT_XARGS_DEPLOY_KEY_PASSPHRASE"))
+		if err != nil {
+			return "", nil, errors.WithStackTrace(types.InvalidDeployKeyErr{Path: keyPath, Err: err})
+		}
+		return repo.GetSSHURL(), auth, nil
+	}
+
+	return repo.GetCloneURL(), &http.BasicAuth{
+		Username: repo.GetOwner().GetLogin(),
+		Password: config.GithubToken,
+	}, nil
+}
+
+// pushesViaAPI returns true if the changes to the supplied repo are pushed via the Git Data API, as --push-via-api asks
+// for. Repos with a deploy key are pushed through git with it instead, since the GitHub token can't write to them
+func pushesViaAPI(config *config.GitXargsConfig, repo *github.Repository) bool {
+	return config.PushViaAPI && deployKeyPath(config, repo) == ""
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that repos with a deploy key are cloned and pushed over SSH with it, and through git even with --push-via-api,
+// while other repos are reached over HTTPS with the GitHub token
+func TestGitRemoteUsesDeployKey(t *testing.T) {
+	t.Parallel()
+
+	keysDir, err := ioutil.TempDir("", "git-xargs-deploy-keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(keysDir)
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(keysDir, "gruntwork-io"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "gruntwork-io", "terragrunt"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(keysDir, "gruntwork-io", "broken"), []byte("not a key"), 0600))
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.DeployKeysDir = keysDir
+	testConfig.PushViaAPI = true
+
+	newRepo := func(name string) *github.Repository {
+		return &github.Repository{
+			Owner:    &github.User{Login: github.String("gruntwork-io")},
+			Name:     github.String(name),
+			CloneURL: github.String("https://github.com/gruntwork-io/" + name + ".git"),
+			SSHURL:   github.String("git@github.com:gruntwork-io/" + name + ".git"),
+		}
+	}
+
+	url, auth, err := gitRemote(testConfig, newRepo("terragrunt"))
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com:gruntwork-io/terragrunt.git", url)
+	assert.IsType(t, &gitssh.PublicKeys{}, auth)
+	assert.False(t, pushesViaAPI(testConfig, newRepo("terragrunt")))
+
+	url, auth, err = gitRemote(testConfig, newRepo("terratest"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/gruntwork-io/terratest.git", url)
+	assert.IsType(t, &http.BasicAuth{}, auth)
+	assert.True(t, pushesViaAPI(testConfig, newRepo("terratest")))
+
+	_, _, err = gitRemote(testConfig, newRepo("broken"))
+	assert.IsType(t, types.InvalidDeployKeyErr{}, errors.Unwrap(err))
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// markReadyForReviewMutation is the GraphQL mutation that flips a draft pull request to ready for review. The REST API
+// does not expose this operation
+const markReadyForReviewMutation = `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+    pullRequest {
+      isDraft
+    }
+  }
+}`
+
+// shouldOpenAsDraft determines whether the pull request for the given repo should be opened in draft mode. The --draft
+// flag always wins, otherwise each of the --draft-if-* rules is evaluated in turn and the first one that holds causes
+// the pull request to be opened as a draft:
+// 1. --draft-if-repo-matches: the <org>/<repo> name matches one of the supplied regular expressions
+// 2. --draft-if-diff-lines-over: the commit made by git-xargs adds or deletes more than the supplied number of lines
+// 3. --draft-if-checks-pending: a status or check run reported on the pushed branch hasn't completed yet
+func shouldOpenAsDraft(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, commitHash plumbing.Hash, branch string) (bool, string, error) {
+	if config.Draft {
+		return true, "", nil
+	}
+
+	fullName := fmt.Sprintf("%s/%s", repo.GetOwner().GetLogin(), repo.GetName())
+	for _, pattern := range config.DraftIfRepoMatches {
+		matched, err := regexp.MatchString(pattern, fullName)
+		if err != nil {
+			return false, "", errors.WithStackTrace(err)
+		}
+		if matched {
+			return true, fmt.Sprintf("repo matches pattern %s", pattern), nil
+		}
+	}
+
+	if config.DraftIfDiffLinesOver > 0 && localRepository != nil {
+		lineCount, err := getCommitDiffLineCount(localRepository, commitHash)
+		if err != nil {
+			return false, "", err
+		}
+		if lineCount > config.DraftIfDiffLinesOver {
+			return true, fmt.Sprintf("diff of %d lines exceeds %d", lineCount, config.DraftIfDiffLinesOver), nil
+		}
+	}
+
+	if config.DraftIfChecksPending {
+		pending, err := reportedChecksPending(config, repo, branch)
+		if err != nil {
+			return false, "", err
+		}
+		if pending {
+			return true, "status checks have not completed", nil
+		}
+	}
+
+	return false, "", nil
+}
+
+// reportedChecksPending returns true if a status or check run reported on the supplied branch of the supplied repo
+// hasn't completed yet. The combined status of a branch no status was reported on is pending too, so the statuses are
+// looked at one by one, so that a branch that was just pushed, and that no CI has picked up yet, doesn't count as pending
+func reportedChecksPending(config *config.GitXargsConfig, repo *github.Repository, branch string) (bool, error) {
+	status, _, err := config.GithubClient.Repositories.GetCombinedStatus(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), branch, nil)
+	if err != nil {
+		return false, errors.WithStackTrace(err)
+	}
+	for _, repoStatus := range status.Statuses {
+		if repoStatus.GetState() == "pending" {
+			return true, nil
+		}
+	}
+
+	checkRuns, err := listCheckRuns(config, repo, branch)
+	if err != nil {
+		return false, err
+	}
+	for _, checkRun := range checkRuns {
+		if checkRun.GetStatus() != "completed" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getCommitDiffLineCount returns the total number of lines added and deleted by the commit with the supplied hash
+func getCommitDiffLineCount(localRepository *git.Repository, commitHash plumbing.Hash) (int, error) {
+	commit, err := localRepository.CommitObject(commitHash)
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+
+	fileStats, err := commit.Stats()
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+
+	lineCount := 0
+	for _, fileStat := range fileStats {
+		lineCount += fileStat.Addition + fileStat.Deletion
+	}
+
+	return lineCount, nil
+}
+
+// MarkDraftPullRequestsReady looks up the open draft pull request from the configured branch in each of the supplied
+// repos and marks it as ready for review. Failures are tracked per repo so that one bad repo doesn't stop the others
+// from being processed
+func MarkDraftPullRequestsReady(config *config.GitXargsConfig, repos []*github.Repository) error {
+	logger := logging.GetLogger("git-xargs")
+
+	for _, repo := range repos {
+		opts := &github.PullRequestListOptions{
+			State: "open",
+			Head:  fmt.Sprintf("%s:%s", repo.GetOwner().GetLogin(), config.BranchName),
+		}
+
+		prs, _, err := config.GithubClient.PullRequests.List(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), opts)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  repo.GetName(),
+			}).Debug("Error listing pull requests")
+
+			config.Stats.TrackSingle(stats.PullRequestMarkReadyErr, repo)
+			continue
+		}
+
+		foundDraft := false
+		for _, pr := range prs {
+			if !pr.GetDraft() {
+				continue
+			}
+			foundDraft = true
+
+			variables := map[string]interface{}{"id": pr.GetNodeID()}
+			if err := config.GithubClient.GraphQL.Do(config.Context, markReadyForReviewMutation, variables, nil); err != nil {
+				logger.WithFields(logrus.Fields{
+					"Error":            err,
+					"Pull Request URL": pr.GetHTMLURL(),
+				}).Debug("Error marking pull request as ready for review")
+
+				config.Stats.TrackSingle(stats.PullRequestMarkReadyErr, repo)
+				continue
+			}
+
+			logger.WithFields(logrus.Fields{
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Successfully marked pull request as ready for review")
+
+			config.Stats.TrackSingle(stats.PullRequestMarkedReady, repo)
+			config.Stats.TrackPullRequest(util.RepoKey(repo), pr.GetHTMLURL())
+		}
+
+		if !foundDraft {
+			config.Stats.TrackSingle(stats.NoDraftPullRequestFound, repo)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShouldOpenAsDraft ensures the --draft and --draft-if-* rules are evaluated as expected
+func TestShouldOpenAsDraft(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		draft              bool
+		draftIfRepoMatches []string
+		expected           bool
+	}{
+		{"no rules", false, []string{}, false},
+		{"draft flag", true, []string{}, true},
+		{"matching repo pattern", false, []string{"^gruntwork-io/terra"}, true},
+		{"non-matching repo pattern", false, []string{"^gruntwork-io/cloud-nuke$"}, false},
+	}
+
+	for _, testCase := range testCases {
+		// The following is necessary to make sure testCase's values don't
+		// get updated due to concurrency within the scope of t.Run(..) below
+		testCase := testCase
+
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testConfig := config.NewGitXargsTestConfig()
+			testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+			testConfig.Draft = testCase.draft
+			testConfig.DraftIfRepoMatches = testCase.draftIfRepoMatches
+
+			draft, _, err := shouldOpenAsDraft(testConfig, mocks.GetMockGithubRepo(), nil, plumbing.ZeroHash, testConfig.BranchName)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, draft)
+		})
+	}
+}
+
+// TestShouldOpenAsDraftWhenChecksPending ensures a status or check run still pending on the pushed branch results in a
+// draft, while a branch no checks were reported on yet doesn't
+func TestShouldOpenAsDraftWhenChecksPending(t *testing.T) {
+	t.Parallel()
+
+	completedRun := &github.CheckRun{Name: github.String("build"), Status: github.String("completed"), Conclusion: github.String("success")}
+	queuedRun := &github.CheckRun{Name: github.String("test"), Status: github.String("queued")}
+	pendingStatus := &github.RepoStatus{Context: github.String("ci/jenkins"), State: github.String("pending")}
+
+	testCases := []struct {
+		name      string
+		status    *github.CombinedStatus
+		checkRuns []*github.CheckRun
+		expected  bool
+	}{
+		{"nothing reported yet", &github.CombinedStatus{State: github.String("pending")}, nil, false},
+		{"checks completed", &github.CombinedStatus{State: github.String("success")}, []*github.CheckRun{completedRun}, false},
+		{"status pending", &github.CombinedStatus{State: github.String("pending"), Statuses: []*github.RepoStatus{pendingStatus}}, []*github.CheckRun{completedRun}, true},
+		{"check run queued", &github.CombinedStatus{State: github.String("pending")}, []*github.CheckRun{completedRun, queuedRun}, true},
+	}
+
+	for _, testCase := range testCases {
+		testConfig := config.NewGitXargsTestConfig()
+		testConfig.GithubClient = mocks.ConfigureMockGithubClientWithChecks(testCase.status, testCase.checkRuns)
+		testConfig.DraftIfChecksPending = true
+
+		draft, _, err := shouldOpenAsDraft(testConfig, mocks.GetMockGithubRepo(), nil, plumbing.ZeroHash, testConfig.BranchName)
+		require.NoError(t, err, testCase.name)
+		assert.Equal(t, testCase.expected, draft, testCase.name)
+	}
+}
+
+// TestMarkDraftPullRequestsReadySkipsNonDrafts ensures repos without an open draft pull request are tracked as such
+func TestMarkDraftPullRequestsReadySkipsNonDrafts(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	err := MarkDraftPullRequestsReady(testConfig, mocks.MockGithubRepositories[:2])
+	require.NoError(t, err)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.NoDraftPullRequestFound), 2)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestMarkedReady))
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// listReposForDryRun logs the supplied repos, which a run with --dry-run-level list-only would process, and tracks them
+// as untouched because of the dry run
+func listReposForDryRun(config *config.GitXargsConfig, repos []*github.Repository) {
+	logger := logging.GetLogger("git-xargs")
+	for _, repo := range repos {
+		logger.WithFields(logrus.Fields{
+			"Repo name": repo.GetFullName(),
+		}).Info("Would process repo, but --dry-run-level is list-only")
+
+		config.Stats.TrackSingle(stats.DryRunSet, repo)
+	}
+}
+
+// showChangesForDryRun tracks the diff of the changes the command made to the supplied repo, for --dry-run-level
+// clone-and-run to print once the run is done. The diff is taken from a commit, which is then undone, leaving the
+// changes uncommitted in the clone
+func showChangesForDryRun(config *config.GitXargsConfig, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository, status git.Status) error {
+	head, err := localRepository.Head()
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	commitHash, err := commitLocalChanges(status, config, "", worktree, remoteRepository, localRepository)
+	if err != nil {
+		return err
+	}
+	// commitLocalChanges already tracks the diff when the run reports include it
+	if !reportsDiffs(config) {
+		patch, err := getCommitPatch(localRepository, commitHash)
+		if err != nil {
+			return errors.WithStackTrace(err)
+		}
+		config.Stats.TrackDiff(remoteRepository, patch.String())
+	}
+
+	if err := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.MixedReset}); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	config.Stats.TrackSingle(stats.DryRunSet, remoteRepository)
+	return nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that --dry-run-level list-only stops the run once the repos are selected, without cloning any of them
+func TestOperateOnReposListOnly(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubOrg = "gruntwork-io"
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.ApplyDryRunLevel(common.DryRunListOnly)
+
+	require.NoError(t, OperateOnRepos(testConfig))
+	assert.Len(t, testConfig.Stats.GetRepos()[stats.DryRunSet], 4)
+	assert.Empty(t, testConfig.Stats.GetRepos()[stats.RepoSuccessfullyCloned])
+}
+
+// Test that --dry-run-level clone-and-run keeps the diff of the changes, and leaves them uncommitted in the clone
+func TestShowChangesForDryRun(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-dry-run-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "README.md"), []byte("docs\n"), 0644))
+	_, err = worktree.Add("README.md")
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	initialCommit, err := worktree.Commit("initial commit", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "README.md"), []byte("better docs\n"), 0644))
+	status, err := worktree.Status()
+	require.NoError(t, err)
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.ApplyDryRunLevel(common.DryRunCloneAndRun)
+	repo := mocks.GetMockGithubRepo()
+
+	require.NoError(t, showChangesForDryRun(testConfig, worktree, repo, localRepository, status))
+	assert.Contains(t, testConfig.Stats.GetDiffs()[util.RepoKey(repo)], "+better docs")
+
+	head, err := localRepository.Head()
+	require.NoError(t, err)
+	assert.Equal(t, initialCommit, head.Hash())
+	status, err = worktree.Status()
+	require.NoError(t, err)
+	assert.False(t, status.IsClean())
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// envVarNamePattern matches the names of the variables an env file may set
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// repoEnvFileDeniedVars are the variables the .git-xargs.env file committed to a repo may not set, since they change
+// which programs the command runs, or what those programs load and run on start up, rather than just what they do
+var repoEnvFileDeniedVars = map[string]bool{
+	"PATH": true, "IFS": true, "ENV": true, "BASH_ENV": true, "SHELLOPTS": true, "BASHOPTS": true, "PS4": true,
+	"PROMPT_COMMAND": true, "HOME": true, "XDG_CONFIG_HOME": true, "PYTHONPATH": true, "PYTHONSTARTUP": true,
+	"PYTHONHOME": true, "NODE_OPTIONS": true, "NODE_PATH": true, "PERL5OPT": true, "PERL5LIB": true, "RUBYOPT": true,
+	"RUBYLIB": true, "JAVA_TOOL_OPTIONS": true, "_JAVA_OPTIONS": true, "GITHUB_OAUTH_TOKEN": true,
+}
+
+// repoEnvFileDeniedPrefixes are the prefixes of the names of other variables the .git-xargs.env file committed to a repo
+// may not set: those of the dynamic linker, of git, including git-xargs' own, and exported bash functions
+var repoEnvFileDeniedPrefixes = []string{"LD_", "DYLD_", "GIT_", "BASH_FUNC_"}
+
+// repoEnvVarDenied returns whether the .git-xargs.env file committed to a repo may not set the variable of the supplied
+// name
+func repoEnvVarDenied(name string) bool {
+	if repoEnvFileDeniedVars[strings.ToUpper(name)] {
+		return true
+	}
+	for _, prefix := range repoEnvFileDeniedPrefixes {
+		if strings.HasPrefix(strings.ToUpper(name), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// envFilePath returns the path of the env file of the supplied repo in --env-files-dir, which holds the env file of each
+// repo as <org>/<repo>.env, or an empty string if the repo has none
+func envFilePath(config *config.GitXargsConfig, repo *github.Repository) string {
+	if config.EnvFilesDir == "" {
+		return ""
+	}
+
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+	for _, path := range []string{
+		filepath.Join(config.EnvFilesDir, owner, name+".env"),
+		filepath.Join(config.EnvFilesDir, strings.ToLower(owner), strings.ToLower(name)+".env"),
+	} {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadCommandEnv loads the variables the command gets for the repo of the supplied job, on top of the environment of
+// git-xargs, into a copy of the config of the job. They come from the .git-xargs.env file committed to the repo, if
+// --repo-env-file was passed, and then from the env file of the repo in --env-files-dir, whose variables take precedence,
+// since they are the operator's. Neither is read unless it was asked for, since the variables can change what the command
+// does. The file committed to the repo may still not set the variables that change which programs run, e.g. PATH
+func loadCommandEnv(job *repoJob) error {
+	config, repo := job.config, job.repo
+
+	var paths []string
+	repoEnvFile := ""
+	if config.RepoEnvFile {
+		path := filepath.Join(job.repositoryDir, common.RepoEnvFileName)
+		if _, err := os.Stat(path); err == nil {
+			repoEnvFile = path
+			paths = append(paths, path)
+		}
+	}
+	if path := envFilePath(config, repo); path != "" {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	env := append([]string{}, config.CommandEnv...)
+	for _, path := range paths {
+		vars, err := readEnvFile(path, path == repoEnvFile)
+		if err != nil {
+			config.Stats.TrackSingle(stats.EnvFileInvalid, repo)
+			return err
+		}
+		env = append(env, vars...)
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Env files": strings.Join(paths, ", "),
+	}).Debug("Loaded env files for the command")
+
+	repoConfig := *config
+	repoConfig.CommandEnv = env
+	job.config = &repoConfig
+	return nil
+}
+
+// readEnvFile reads the variables of the env file at the supplied path, as NAME=value entries. Each line holds one
+// variable, optionally prefixed with export, and its value may be wrapped in single or double quotes. Within double
+// quotes, \n, \", and \\ are unescaped. Blank lines and lines starting with # are ignored. An env file committed to a repo
+// may not set the variables repoEnvVarDenied denies
+func readEnvFile(path string, committedToRepo bool) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var vars []string
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !envVarNamePattern.MatchString(name) {
+			return nil, errors.WithStackTrace(types.InvalidEnvFileErr{Path: path, Line: lineNumber})
+		}
+
+		if committedToRepo && repoEnvVarDenied(name) {
+			return nil, errors.WithStackTrace(types.DeniedRepoEnvVarErr{Path: path, Line: lineNumber, Name: name})
+		}
+
+		value, ok := unquoteEnvValue(strings.TrimSpace(parts[1]))
+		if !ok {
+			return nil, errors.WithStackTrace(types.InvalidEnvFileErr{Path: path, Line: lineNumber})
+		}
+		vars = append(vars, name+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return vars, nil
+}
+
+// unquoteEnvValue returns the supplied value of an env file without the quotes it may be wrapped in, and false if it
+// has a quote that isn't closed
+func unquoteEnvValue(value string) (string, bool) {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return value, true
+	}
+	quote := value[0]
+	if len(value) < 2 || value[len(value)-1] != quote {
+		return "", false
+	}
+	value = value[1 : len(value)-1]
+	if quote == '\'' {
+		return value, true
+	}
+	return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(value), true
+}
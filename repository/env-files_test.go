@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-env-file")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "terragrunt.env")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`# Parameters of the upgrade
+export TF_VERSION=1.5.7
+EMPTY=
+URL = https://example.com/?a=b
+SINGLE='keep \n as is'
+DOUBLE="line one\nline \"two\""
+`), 0644))
+
+	vars, err := readEnvFile(path, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"TF_VERSION=1.5.7",
+		"EMPTY=",
+		"URL=https://example.com/?a=b",
+		`SINGLE=keep \n as is`,
+		"DOUBLE=line one\nline \"two\"",
+	}, vars)
+
+	for _, invalid := range []string{"NO_VALUE", "1NAME=value", "NAME=\"unclosed", "BAD NAME=value"} {
+		require.NoError(t, ioutil.WriteFile(path, []byte("OK=1\n"+invalid+"\n"), 0644))
+		_, err := readEnvFile(path, false)
+		assert.Equal(t, types.InvalidEnvFileErr{Path: path, Line: 2}, errors.Unwrap(err), invalid)
+	}
+}
+
+// Test that the command gets the variables of the repo's env files, with the ones in --env-files-dir taking precedence
+// over the .git-xargs.env committed to the repo, which is only read with --repo-env-file
+func TestLoadCommandEnv(t *testing.T) {
+	t.Parallel()
+
+	envFilesDir, err := ioutil.TempDir("", "git-xargs-env-files")
+	require.NoError(t, err)
+	defer os.RemoveAll(envFilesDir)
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-env-repo")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(envFilesDir, "gruntwork-io"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(envFilesDir, "gruntwork-io", "terragrunt.env"), []byte("TOKEN=operator\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, common.RepoEnvFileName), []byte("TOKEN=repo\nMODULE=vpc\n"), 0644))
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.EnvFilesDir = envFilesDir
+	job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig, repositoryDir: repositoryDir}
+	require.NoError(t, loadCommandEnv(job))
+	assert.Equal(t, []string{"TOKEN=operator"}, job.config.CommandEnv)
+	assert.Empty(t, testConfig.CommandEnv)
+
+	testConfig.RepoEnvFile = true
+	job = &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig, repositoryDir: repositoryDir}
+	require.NoError(t, loadCommandEnv(job))
+	assert.Equal(t, []string{"TOKEN=repo", "MODULE=vpc", "TOKEN=operator"}, job.config.CommandEnv)
+
+	// The variables that come later win over the earlier ones and the environment of git-xargs
+	job.config.Args = []string{"sh", "-c", `echo "$TOKEN $MODULE"`}
+	var buffer bytes.Buffer
+	logger := &logrus.Logger{Out: &buffer, Level: logrus.TraceLevel, Formatter: new(logrus.TextFormatter)}
+	require.NoError(t, executeCommandWithLogger(job.config, repositoryDir, job.repo, logger))
+	assert.Contains(t, buffer.String(), "operator vpc")
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, common.RepoEnvFileName), []byte("not a variable\n"), 0644))
+	job = &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig, repositoryDir: repositoryDir}
+	assert.Error(t, loadCommandEnv(job))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.EnvFileInvalid), 1)
+
+	// The env file committed to the repo can't change which programs the command runs, but the operator's can
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, common.RepoEnvFileName), []byte("MODULE=vpc\nexport LD_PRELOAD=/tmp/evil.so\n"), 0644))
+	job = &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig, repositoryDir: repositoryDir}
+	err = loadCommandEnv(job)
+	require.Error(t, err)
+	assert.Equal(t, types.DeniedRepoEnvVarErr{Path: filepath.Join(repositoryDir, common.RepoEnvFileName), Line: 2, Name: "LD_PRELOAD"}, errors.Unwrap(err))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(envFilesDir, "gruntwork-io", "terragrunt.env"), []byte("PATH=/opt/tools/bin\n"), 0644))
+	vars, err := readEnvFile(filepath.Join(envFilesDir, "gruntwork-io", "terragrunt.env"), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PATH=/opt/tools/bin"}, vars)
+}
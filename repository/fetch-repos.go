@@ -11,12 +11,13 @@ import (
 	"github.com/gruntwork-io/go-commons/errors"
 
 	"github.com/google/go-github/v32/github"
-	"github.com/gruntwork-io/go-commons/logging"
+	"github.com/gruntwork-io/git-xargs/logging"
 	"github.com/sirupsen/logrus"
 )
 
-// getFileDefinedRepos converts user-supplied repositories to GitHub API response objects that can be further processed
-func getFileDefinedRepos(GithubClient auth.GithubClient, allowedRepos []*types.AllowedRepo, tracker *stats.RunStats) ([]*github.Repository, error) {
+// getFileDefinedRepos converts user-supplied repositories to GitHub API response objects that can be further processed,
+// looking each repo up via the GitHub API client of the host it is on
+func getFileDefinedRepos(ctx context.Context, GithubClient auth.GithubClient, hostClients map[string]auth.GithubClient, allowedRepos []*types.AllowedRepo, tracker *stats.RunStats) ([]*github.Repository, error) {
 	logger := logging.GetLogger("git-xargs")
 
 	var allRepos []*github.Repository
@@ -28,7 +29,12 @@ func getFileDefinedRepos(GithubClient auth.GithubClient, allowedRepos []*types.A
 			"Name":         allowedRepo.Name,
 		}).Debug("Looking up filename provided repo")
 
-		repo, resp, err := GithubClient.Repositories.Get(context.Background(), allowedRepo.Organization, allowedRepo.Name)
+		client, err := githubClientForHost(GithubClient, hostClients, allowedRepo.Host)
+		if err != nil {
+			return allRepos, err
+		}
+
+		repo, resp, err := client.Repositories.Get(ctx, allowedRepo.Organization, allowedRepo.Name)
 
 		if err != nil {
 			logger.WithFields(logrus.Fields{
@@ -65,6 +71,21 @@ func getFileDefinedRepos(GithubClient auth.GithubClient, allowedRepos []*types.A
 	return allRepos, nil
 }
 
+// ListOrgRepoNames returns the full names of the repos of the Github organization passed via --github-org, e.g. to
+// complete the values of --repo in the shell
+func ListOrgRepoNames(config *config.GitXargsConfig) ([]string, error) {
+	repos, err := getReposByOrg(config)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, repo := range repos {
+		names = append(names, repo.GetFullName())
+	}
+	return names, nil
+}
+
 // getReposByOrg takes the string name of a GitHub organization and pages through the API to fetch all of its repositories
 func getReposByOrg(config *config.GitXargsConfig) ([]*github.Repository, error) {
 
@@ -85,7 +106,7 @@ func getReposByOrg(config *config.GitXargsConfig) ([]*github.Repository, error)
 
 	for {
 		var reposToAdd []*github.Repository
-		repos, resp, err := config.GithubClient.Repositories.ListByOrg(context.Background(), config.GithubOrg, opt)
+		repos, resp, err := config.GithubClient.Repositories.ListByOrg(config.Context, config.GithubOrg, opt)
 		if err != nil {
 			return allRepos, errors.WithStackTrace(err)
 		}
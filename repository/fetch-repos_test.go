@@ -32,7 +32,7 @@ func TestGetFileDefinedRepos(t *testing.T) {
 		},
 	}
 
-	githubRepos, reposLookupErr := getFileDefinedRepos(config.GithubClient, allowedRepos, config.Stats)
+	githubRepos, reposLookupErr := getFileDefinedRepos(config.Context, config.GithubClient, nil, allowedRepos, config.Stats)
 
 	assert.Equal(t, len(githubRepos), len(allowedRepos))
 	assert.NoError(t, reposLookupErr)
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// CreateReportGist uploads the supplied files, keyed by file name, as a secret gist with the supplied description, so
+// that the run report can be shared once the machine that ran git-xargs is gone
+func CreateReportGist(config *config.GitXargsConfig, description string, files map[string]string) (*github.Gist, error) {
+	gistFiles := map[github.GistFilename]github.GistFile{}
+	for name, content := range files {
+		gistFiles[github.GistFilename(name)] = github.GistFile{Content: github.String(content)}
+	}
+
+	gist, _, err := config.GithubClient.Gists.Create(context.Background(), &github.Gist{
+		Description: github.String(description),
+		Public:      github.Bool(false),
+		Files:       gistFiles,
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return gist, nil
+}
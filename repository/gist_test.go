@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReportGist(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	gist, err := CreateReportGist(testConfig, "git-xargs run abc", map[string]string{
+		"git-xargs-abc.md":   "## git-xargs run",
+		"git-xargs-abc.json": "{}",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://gist.github.com/gruntwork-io/0123456789abcdef", gist.GetHTMLURL())
+	assert.False(t, gist.GetPublic())
+	jsonFile := gist.Files[github.GistFilename("git-xargs-abc.json")]
+	assert.Equal(t, "{}", jsonFile.GetContent())
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// pushBranchViaGitDataAPI recreates the commits of the supplied local branch that GitHub doesn't have yet via the Git
+// Data API, oldest first, uploading the blobs each one changed and building its tree on top of the tree of its parent,
+// then points the remote branch at the last one, so that nothing is pushed through git. This is used instead of a git
+// push when --push-via-api was passed. The commits keep their trees, parents, authors, committers and messages, so
+// GitHub normally gives them the same SHAs they have locally. The SHA GitHub gave the head of the branch is returned
+func pushBranchViaGitDataAPI(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, branchName string) (plumbing.Hash, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	head, commits, err := findUnpushedCommits(localRepository, branchName)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// The SHAs GitHub gave the commits recreated so far, so that the commits after them are made their children
+	remoteSHAs := map[plumbing.Hash]string{}
+	remoteSHA := func(hash plumbing.Hash) string {
+		if sha, ok := remoteSHAs[hash]; ok {
+			return sha
+		}
+		return hash.String()
+	}
+
+	owner, name := remoteRepository.GetOwner().GetLogin(), remoteRepository.GetName()
+	for _, commit := range commits {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return plumbing.ZeroHash, errors.WithStackTrace(err)
+		}
+
+		entries, err := createTreeEntriesForCommit(config, remoteRepository, localRepository, commit, parent)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		tree, _, err := config.GithubClient.Git.CreateTree(config.Context, owner, name, parent.TreeHash.String(), entries)
+		if err != nil {
+			return plumbing.ZeroHash, errors.WithStackTrace(err)
+		}
+
+		parents := []*github.Commit{}
+		for _, parentHash := range commit.ParentHashes {
+			parents = append(parents, &github.Commit{SHA: github.String(remoteSHA(parentHash))})
+		}
+
+		remoteCommit, _, err := config.GithubClient.Git.CreateCommit(config.Context, owner, name, &github.Commit{
+			Message:   github.String(commit.Message),
+			Tree:      &github.Tree{SHA: tree.SHA},
+			Parents:   parents,
+			Author:    commitAuthor(commit.Author),
+			Committer: commitAuthor(commit.Committer),
+		})
+		if err != nil {
+			return plumbing.ZeroHash, errors.WithStackTrace(err)
+		}
+
+		if remoteCommit.GetSHA() != commit.Hash.String() {
+			logger.WithFields(logrus.Fields{
+				"Repo":          name,
+				"Local commit":  commit.Hash.String(),
+				"Remote commit": remoteCommit.GetSHA(),
+			}).Debug("The commit created via the Git Data API differs from the local one")
+		}
+		remoteSHAs[commit.Hash] = remoteCommit.GetSHA()
+	}
+
+	if err := pointRemoteBranchAt(config, remoteRepository, branchName, remoteSHA(head)); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Like a git push, record where the remote branch now is, so that a later push of a branch stacked on top of this one
+	// only recreates its own commits. That's only possible if GitHub gave the head the same SHA it has locally
+	if remoteSHA(head) == head.String() {
+		trackingRef := plumbing.NewRemoteReferenceName("origin", plumbing.ReferenceName(branchName).Short())
+		if err := localRepository.Storer.SetReference(plumbing.NewHashReference(trackingRef, head)); err != nil {
+			return plumbing.ZeroHash, errors.WithStackTrace(err)
+		}
+	}
+
+	return plumbing.NewHash(remoteSHA(head)), nil
+}
+
+// findUnpushedCommits returns the hash of the head of the supplied local branch, and the commits leading up to it that
+// aren't on any branch of the origin remote, oldest first. The clones pushed via the Git Data API are shallow, so the
+// history is followed back until a commit at the tip of a remote branch, or at the boundary of the clone
+func findUnpushedCommits(localRepository *git.Repository, branchName string) (plumbing.Hash, []*object.Commit, error) {
+	ref, err := localRepository.Reference(plumbing.ReferenceName(branchName), true)
+	if err != nil {
+		return plumbing.ZeroHash, nil, errors.WithStackTrace(err)
+	}
+
+	remoteTips := map[plumbing.Hash]bool{}
+	refs, err := localRepository.References()
+	if err != nil {
+		return plumbing.ZeroHash, nil, errors.WithStackTrace(err)
+	}
+	err = refs.ForEach(func(remoteRef *plumbing.Reference) error {
+		if remoteRef.Name().IsRemote() && remoteRef.Type() == plumbing.HashReference {
+			remoteTips[remoteRef.Hash()] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return plumbing.ZeroHash, nil, errors.WithStackTrace(err)
+	}
+
+	commits := []*object.Commit{}
+	commit, err := localRepository.CommitObject(ref.Hash())
+	if err != nil {
+		return plumbing.ZeroHash, nil, errors.WithStackTrace(err)
+	}
+	for !remoteTips[commit.Hash] {
+		if commit.NumParents() == 0 {
+			return plumbing.ZeroHash, nil, errors.WithStackTrace(types.CommitWithoutParentErr{Commit: commit.Hash.String()})
+		}
+
+		parent, err := commit.Parent(0)
+		// The parents of the commits at the boundary of a shallow clone are missing, and those commits came from GitHub
+		if err == plumbing.ErrObjectNotFound {
+			break
+		}
+		if err != nil {
+			return plumbing.ZeroHash, nil, errors.WithStackTrace(err)
+		}
+
+		commits = append([]*object.Commit{commit}, commits...)
+		commit = parent
+	}
+
+	return ref.Hash(), commits, nil
+}
+
+// createTreeEntriesForCommit uploads the blobs the supplied commit changed relative to its parent, and returns the
+// tree entries to apply on top of the tree of the parent to get the tree of the commit. Deleted files get an entry
+// without a SHA, which removes them
+func createTreeEntriesForCommit(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, commit *object.Commit, parent *object.Commit) ([]*github.TreeEntry, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	var entries []*github.TreeEntry
+	for _, change := range changes {
+		// Deleted files only exist in the parent
+		if change.To.Name == "" {
+			entries = append(entries, &github.TreeEntry{
+				Path: github.String(change.From.Name),
+				Mode: github.String(treeEntryMode(change.From.TreeEntry.Mode)),
+				Type: github.String(treeEntryType(change.From.TreeEntry.Mode)),
+			})
+			continue
+		}
+
+		entry := &github.TreeEntry{
+			Path: github.String(change.To.Name),
+			Mode: github.String(treeEntryMode(change.To.TreeEntry.Mode)),
+			Type: github.String(treeEntryType(change.To.TreeEntry.Mode)),
+			SHA:  github.String(change.To.TreeEntry.Hash.String()),
+		}
+		// Submodules point at a commit of another repo, so there is no blob to upload for them
+		if change.To.TreeEntry.Mode != filemode.Submodule {
+			sha, err := uploadBlob(config, remoteRepository, localRepository, change.To.TreeEntry.Hash)
+			if err != nil {
+				return nil, err
+			}
+			entry.SHA = github.String(sha)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// uploadBlob creates the supplied local blob in the GitHub repo, and returns its SHA there
+func uploadBlob(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, hash plumbing.Hash) (string, error) {
+	blob, err := localRepository.BlobObject(hash)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+
+	// Blobs are uploaded base64-encoded, so that binary files survive the trip
+	created, _, err := config.GithubClient.Git.CreateBlob(config.Context, remoteRepository.GetOwner().GetLogin(), remoteRepository.GetName(), &github.Blob{
+		Content:  github.String(base64.StdEncoding.EncodeToString(content)),
+		Encoding: github.String("base64"),
+	})
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return created.GetSHA(), nil
+}
+
+// pointRemoteBranchAt moves the supplied branch of the GitHub repo to the supplied commit, creating the branch if it
+// doesn't exist yet. Like a git push, the branch is only moved if the commit is a descendant of its current head
+func pointRemoteBranchAt(config *config.GitXargsConfig, remoteRepository *github.Repository, branchName string, sha string) error {
+	owner, name := remoteRepository.GetOwner().GetLogin(), remoteRepository.GetName()
+	ref := "heads/" + plumbing.ReferenceName(branchName).Short()
+	reference := &github.Reference{
+		Ref:    github.String("refs/" + ref),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	}
+
+	_, resp, err := config.GithubClient.Git.GetRef(config.Context, owner, name, ref)
+	switch {
+	case err != nil && resp != nil && resp.StatusCode == http.StatusNotFound:
+		_, _, err = config.GithubClient.Git.CreateRef(config.Context, owner, name, reference)
+	case err != nil:
+		config.Stats.TrackSingle(stats.TargetBranchLookupErr, remoteRepository)
+	default:
+		_, _, err = config.GithubClient.Git.UpdateRef(config.Context, owner, name, reference, false)
+	}
+
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	return nil
+}
+
+// commitAuthor converts the supplied git signature into the author or committer of a commit created via the GitHub API
+func commitAuthor(signature object.Signature) *github.CommitAuthor {
+	when := signature.When
+	return &github.CommitAuthor{
+		Name:  github.String(signature.Name),
+		Email: github.String(signature.Email),
+		Date:  &when,
+	}
+}
+
+// treeEntryMode returns the supplied file mode in the six digit octal format of the GitHub API, e.g. 100644
+func treeEntryMode(mode filemode.FileMode) string {
+	return fmt.Sprintf("%06o", uint32(mode))
+}
+
+// treeEntryType returns the type of object a tree entry with the supplied file mode points at
+func treeEntryType(mode filemode.FileMode) string {
+	switch mode {
+	case filemode.Submodule:
+		return "commit"
+	case filemode.Dir:
+		return "tree"
+	default:
+		return "blob"
+	}
+}
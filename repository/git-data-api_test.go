@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the files changed by each unpushed commit of a branch become tree entries, with deleted files left without
+// a SHA, that every unpushed commit is recreated on top of the one before it, and that the remote branch is pointed at
+// the head created via the Git Data API
+func TestPushBranchViaGitDataAPI(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-git-data-api-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "main.tf"), []byte("one\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "old.txt"), []byte("old\n"), 0644))
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	initialHash, err := worktree.Commit("initial", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	// The initial commit is already on GitHub
+	require.NoError(t, localRepository.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "master"), initialHash)))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "main.tf"), []byte("two\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "run.sh"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.Remove(filepath.Join(repositoryDir, "old.txt")))
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	_, err = worktree.Commit("update", &git.CommitOptions{Author: signature, All: true})
+	require.NoError(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, "main.tf"), []byte("three\n"), 0644))
+	_, err = worktree.Commit("update again", &git.CommitOptions{Author: signature, All: true})
+	require.NoError(t, err)
+
+	testConfig := config.NewGitXargsTestConfig()
+	created := []*github.Commit{}
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithCommits(&created)
+	repo := mocks.GetMockGithubRepo()
+
+	_, commits, err := findUnpushedCommits(localRepository, "refs/heads/master")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "update", commits[0].Message)
+	parent, err := commits[0].Parent(0)
+	require.NoError(t, err)
+	entries, err := createTreeEntriesForCommit(testConfig, repo, localRepository, commits[0], parent)
+	require.NoError(t, err)
+
+	modes := map[string]string{}
+	deleted := []string{}
+	for _, entry := range entries {
+		modes[entry.GetPath()] = entry.GetMode()
+		if entry.SHA == nil {
+			deleted = append(deleted, entry.GetPath())
+		}
+	}
+	assert.Equal(t, map[string]string{"main.tf": "100644", "old.txt": "100644", "run.sh": "100755"}, modes)
+	assert.Equal(t, []string{"old.txt"}, deleted)
+
+	pushedHash, err := pushBranchViaGitDataAPI(testConfig, repo, localRepository, "refs/heads/master")
+	require.NoError(t, err)
+	assert.Equal(t, pushedHash.String(), remoteBranchSHA(t, testConfig, "master"))
+
+	// The mock gives the commits SHAs that differ from the local ones, so the second commit must be made a child of the
+	// SHA GitHub gave the first
+	require.Len(t, created, 2)
+	assert.Equal(t, initialHash.String(), created[0].Parents[0].GetSHA())
+	assert.Equal(t, created[0].GetSHA(), created[1].Parents[0].GetSHA())
+	assert.Equal(t, created[1].GetSHA(), pushedHash.String())
+}
+
+// Test that an existing remote branch is moved to the commit created via the Git Data API, and a missing one created
+func TestPointRemoteBranchAt(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	repo := mocks.GetMockGithubRepo()
+
+	require.NoError(t, pointRemoteBranchAt(testConfig, repo, "refs/heads/master", "5555555555555555555555555555555555555555"))
+	require.NoError(t, pointRemoteBranchAt(testConfig, repo, "refs/heads/update-ci", "5555555555555555555555555555555555555555"))
+
+	for _, branch := range []string{"master", "update-ci"} {
+		assert.Equal(t, "5555555555555555555555555555555555555555", remoteBranchSHA(t, testConfig, branch))
+	}
+}
+
+// remoteBranchSHA returns the SHA the supplied branch of the mock repo points at
+func remoteBranchSHA(t *testing.T, testConfig *config.GitXargsConfig, branch string) string {
+	reference, _, err := testConfig.GithubClient.Git.GetRef(testConfig.Context, "gruntwork-io", "terragrunt", "heads/"+branch)
+	require.NoError(t, err)
+	return reference.GetObject().GetSHA()
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/sirupsen/logrus"
+)
+
+// githubHostFeature is a feature of git-xargs that needs a minimum version of GitHub Enterprise Server, and is disabled
+// for the repos on older servers rather than failing them midway with an error from their API
+type githubHostFeature struct {
+	name       string
+	minVersion [2]int
+	// enabled returns true if the feature was passed for the run
+	enabled func(config *config.GitXargsConfig) bool
+	// disable turns the feature off in the supplied config of a repo
+	disable func(config *config.GitXargsConfig)
+}
+
+// githubHostFeatures are the features that GitHub Enterprise Server versions older than their minimum don't support
+var githubHostFeatures = []githubHostFeature{
+	{
+		name:       "draft pull requests",
+		minVersion: [2]int{2, 17},
+		enabled: func(config *config.GitXargsConfig) bool {
+			return config.Draft || config.DraftIfChecksPending || config.DraftIfDiffLinesOver > 0 || len(config.DraftIfRepoMatches) > 0
+		},
+		disable: func(config *config.GitXargsConfig) {
+			config.Draft = false
+			config.DraftIfChecksPending = false
+			config.DraftIfDiffLinesOver = 0
+			config.DraftIfRepoMatches = nil
+		},
+	},
+	{
+		name:       "Projects (v2)",
+		minVersion: [2]int{3, 7},
+		enabled: func(config *config.GitXargsConfig) bool {
+			return config.ResolvedProject != nil
+		},
+		disable: func(config *config.GitXargsConfig) {
+			config.ResolvedProject = nil
+		},
+	},
+}
+
+// DetectGithubHostVersions reads the version of each GitHub Enterprise Server passed via --github-host from its meta
+// endpoint, so that the features its version doesn't support can be disabled for its repos, and warns about each of
+// them up front. A server whose version can't be read is assumed to support every feature
+func DetectGithubHostVersions(config *config.GitXargsConfig) {
+	logger := logging.GetLogger("git-xargs")
+
+	config.GithubHostVersions = map[string]string{}
+	for host, client := range config.GithubHostClients {
+		if client.Meta == nil {
+			continue
+		}
+
+		version, _, err := client.Meta.InstalledVersion(config.Context)
+		if err != nil || version == "" {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Host":  host,
+			}).Warn("Could not detect the GitHub Enterprise Server version, assuming it supports every feature")
+			continue
+		}
+		config.GithubHostVersions[host] = version
+
+		logger.WithFields(logrus.Fields{
+			"Host":    host,
+			"Version": version,
+		}).Debug("Detected GitHub Enterprise Server version")
+
+		for _, feature := range unsupportedGithubHostFeatures(config, version) {
+			logger.WithFields(logrus.Fields{
+				"Host":    host,
+				"Version": version,
+				"Feature": feature.name,
+			}).Warn("The GitHub Enterprise Server version doesn't support this feature, so it is disabled for the repos on it")
+		}
+	}
+}
+
+// adaptToGithubHostVersion disables the features passed for the run that the version of the GitHub Enterprise Server
+// the supplied repo is on doesn't support, in the supplied config of the repo
+func adaptToGithubHostVersion(repoConfig *config.GitXargsConfig, repo *github.Repository, host string) {
+	version, ok := repoConfig.GithubHostVersions[host]
+	if !ok {
+		return
+	}
+
+	unsupported := unsupportedGithubHostFeatures(repoConfig, version)
+	for _, feature := range unsupported {
+		feature.disable(repoConfig)
+	}
+	if len(unsupported) > 0 {
+		repoConfig.Stats.TrackSingle(stats.FeatureUnsupportedByGithubHost, repo)
+	}
+}
+
+// unsupportedGithubHostFeatures returns the features passed for the run that the supplied GitHub Enterprise Server
+// version doesn't support. A version that can't be parsed is assumed to support every feature
+func unsupportedGithubHostFeatures(config *config.GitXargsConfig, version string) []githubHostFeature {
+	parsed, ok := parseGithubHostVersion(version)
+	if !ok {
+		return nil
+	}
+
+	var unsupported []githubHostFeature
+	for _, feature := range githubHostFeatures {
+		if feature.enabled(config) && versionBefore(parsed, feature.minVersion) {
+			unsupported = append(unsupported, feature)
+		}
+	}
+	return unsupported
+}
+
+// parseGithubHostVersion returns the major and minor numbers of the supplied GitHub Enterprise Server version, e.g.
+// 3.9.2, ignoring the patch number and any suffix
+func parseGithubHostVersion(version string) ([2]int, bool) {
+	var parts [2]int
+	fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".")
+	if len(fields) < len(parts) {
+		return parts, false
+	}
+	for i := range parts {
+		number, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = number
+	}
+	return parts, true
+}
+
+// versionBefore returns true if the supplied version is older than the supplied minimum version
+func versionBefore(version [2]int, minVersion [2]int) bool {
+	if version[0] != minVersion[0] {
+		return version[0] < minVersion[0]
+	}
+	return version[1] < minVersion[1]
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the versions of GitHub Enterprise Server are parsed and compared by their major and minor numbers
+func TestParseGithubHostVersion(t *testing.T) {
+	t.Parallel()
+
+	version, ok := parseGithubHostVersion("3.9.2")
+	assert.True(t, ok)
+	assert.Equal(t, [2]int{3, 9}, version)
+	assert.True(t, versionBefore(version, [2]int{3, 10}))
+	assert.False(t, versionBefore(version, [2]int{3, 7}))
+	assert.True(t, versionBefore([2]int{2, 22}, [2]int{3, 7}))
+
+	_, ok = parseGithubHostVersion("enterprise")
+	assert.False(t, ok)
+}
+
+// Test that the features a GitHub Enterprise Server is too old for are disabled for the repos on it, and only for them
+func TestWithRepoHostAdaptsToGithubHostVersion(t *testing.T) {
+	t.Parallel()
+
+	oldClient := mocks.ConfigureMockGithubClient()
+	oldClient.Meta = mocks.MockGithubMetaService{Version: "3.5.1"}
+	newClient := mocks.ConfigureMockGithubClient()
+	newClient.Meta = mocks.MockGithubMetaService{Version: "3.12.0"}
+	unknownClient := mocks.ConfigureMockGithubClient()
+	unknownClient.Meta = mocks.MockGithubMetaService{Err: errors.New("not found")}
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Draft = true
+	testConfig.ResolvedProject = &types.ProjectV2{ID: "PVT_1"}
+	testConfig.GithubHostClients = map[string]auth.GithubClient{
+		"github.old.com":     oldClient,
+		"github.new.com":     newClient,
+		"github.unknown.com": unknownClient,
+	}
+
+	DetectGithubHostVersions(testConfig)
+	assert.Equal(t, map[string]string{"github.old.com": "3.5.1", "github.new.com": "3.12.0"}, testConfig.GithubHostVersions)
+
+	oldRepo := &github.Repository{Name: github.String("api"), HTMLURL: github.String("https://github.old.com/platform/api")}
+	repoConfig, err := withRepoHost(testConfig, oldRepo)
+	require.NoError(t, err)
+	assert.True(t, repoConfig.Draft)
+	assert.Nil(t, repoConfig.ResolvedProject)
+	assert.NotNil(t, testConfig.ResolvedProject)
+	assert.Len(t, testConfig.Stats.GetRepos()[stats.FeatureUnsupportedByGithubHost], 1)
+
+	newRepo := &github.Repository{Name: github.String("web"), HTMLURL: github.String("https://github.new.com/platform/web")}
+	repoConfig, err = withRepoHost(testConfig, newRepo)
+	require.NoError(t, err)
+	assert.NotNil(t, repoConfig.ResolvedProject)
+
+	unknownRepo := &github.Repository{Name: github.String("db"), HTMLURL: github.String("https://github.unknown.com/platform/db")}
+	repoConfig, err = withRepoHost(testConfig, unknownRepo)
+	require.NoError(t, err)
+	assert.NotNil(t, repoConfig.ResolvedProject)
+	assert.Len(t, testConfig.Stats.GetRepos()[stats.FeatureUnsupportedByGithubHost], 1)
+
+	testConfig.GithubHostVersions["github.old.com"] = "2.16.0"
+	repoConfig, err = withRepoHost(testConfig, oldRepo)
+	require.NoError(t, err)
+	assert.False(t, repoConfig.Draft)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// githubClientForHost returns the GitHub API client for the supplied host, which is the default client for github.com,
+// denoted by an empty host, and the one configured via --github-host for a GitHub Enterprise Server
+func githubClientForHost(defaultClient auth.GithubClient, hostClients map[string]auth.GithubClient, host string) (auth.GithubClient, error) {
+	if host == "" {
+		return defaultClient, nil
+	}
+
+	client, ok := hostClients[host]
+	if !ok {
+		return auth.GithubClient{}, errors.WithStackTrace(types.UnknownGithubHostErr{Host: host})
+	}
+	return client, nil
+}
+
+// withRepoHost returns a copy of the supplied config that talks to the GitHub host the supplied repo is on, with the API
+// clients and token configured for it via --github-host, so that a single run can span github.com and one or more
+// GitHub Enterprise Servers. The features the version of the server doesn't support are disabled for the repo. Repos on
+// github.com keep the supplied config. Repos on a host no client was configured for get an UnknownGithubHostErr, rather
+// than being sent to the API of github.com. Every API call about a repo goes through the config this returns for it
+func withRepoHost(gitxargsConfig *config.GitXargsConfig, repo *github.Repository) (*config.GitXargsConfig, error) {
+	host := util.RepoHost(repo)
+	if host == "" {
+		return gitxargsConfig, nil
+	}
+	client, err := githubClientForHost(gitxargsConfig.GithubClient, gitxargsConfig.GithubHostClients, host)
+	if err != nil {
+		return nil, err
+	}
+
+	repoConfig := *gitxargsConfig
+	repoConfig.GithubClient = client
+	repoConfig.ApproverGithubClient = gitxargsConfig.GithubHostApproverClients[host]
+	repoConfig.GithubToken = gitxargsConfig.GithubHostTokens[host]
+	adaptToGithubHostVersion(&repoConfig, repo, host)
+	return &repoConfig, nil
+}
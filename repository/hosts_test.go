@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that repos passed with a leading host are attributed to it, and that github.com denotes the default host
+func TestConvertStringToAllowedRepoWithHost(t *testing.T) {
+	t.Parallel()
+
+	repo := util.ConvertStringToAllowedRepo("GitHub.Example.com/platform/api")
+	require.NotNil(t, repo)
+	assert.Equal(t, "github.example.com", repo.Host)
+	assert.Equal(t, "platform", repo.Organization)
+	assert.Equal(t, "api", repo.Name)
+
+	repo = util.ConvertStringToAllowedRepo("github.com/gruntwork-io/terragrunt")
+	require.NotNil(t, repo)
+	assert.Equal(t, "", repo.Host)
+	assert.Equal(t, "gruntwork-io", repo.Organization)
+}
+
+// Test that repos on a GitHub Enterprise Server get the client and token configured for it, while repos on github.com
+// keep the ones of the run
+func TestWithRepoHost(t *testing.T) {
+	t.Parallel()
+
+	enterpriseClient := auth.ConfigureGithubClientForHost("github.example.com", "enterprise-token")
+	assert.Equal(t, "https://github.example.com/api/v3/", enterpriseClient.BaseURL.String())
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.GithubHostClients = map[string]auth.GithubClient{"github.example.com": enterpriseClient}
+	testConfig.GithubHostApproverClients = map[string]auth.GithubClient{"github.example.com": enterpriseClient}
+	testConfig.GithubHostTokens = map[string]string{"github.example.com": "enterprise-token"}
+
+	repoConfig, err := withRepoHost(testConfig, mocks.GetMockGithubRepo())
+	require.NoError(t, err)
+	assert.Same(t, testConfig, repoConfig)
+
+	enterpriseRepo := &github.Repository{HTMLURL: github.String("https://github.example.com/platform/api")}
+	repoConfig, err = withRepoHost(testConfig, enterpriseRepo)
+	require.NoError(t, err)
+	assert.Equal(t, "enterprise-token", repoConfig.GithubToken)
+	assert.Equal(t, enterpriseClient.BaseURL, repoConfig.GithubClient.BaseURL)
+	assert.Equal(t, enterpriseClient.BaseURL, repoConfig.ApproverGithubClient.BaseURL)
+
+	_, err = githubClientForHost(testConfig.GithubClient, testConfig.GithubHostClients, "github.other.com")
+	assert.Error(t, err)
+
+	// Repos on a host no client was configured for fail, rather than falling back to the client of github.com
+	otherRepo := &github.Repository{HTMLURL: github.String("https://github.other.com/platform/api")}
+	_, err = withRepoHost(testConfig, otherRepo)
+	assert.Equal(t, types.UnknownGithubHostErr{Host: "github.other.com"}, errors.Unwrap(err))
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// BranchNameWithJiraIssue returns the branch name of the supplied config with the key of --jira-issue in front, e.g.
+// PLAT-123-upgrade-ci, so that Jira links the branch to the issue. Branch names that already mention the key are kept
+func BranchNameWithJiraIssue(config *config.GitXargsConfig) string {
+	if config.JiraIssue == "" || strings.Contains(config.BranchName, config.JiraIssue) {
+		return config.BranchName
+	}
+	return fmt.Sprintf("%s-%s", config.JiraIssue, config.BranchName)
+}
+
+// withJiraIssueKey puts the key of --jira-issue in front of the supplied commit message or pull request title, e.g.
+// "PLAT-123: Upgrade CI", so that Jira links the commit or pull request to the issue. Text that already mentions the
+// key is kept
+func withJiraIssueKey(config *config.GitXargsConfig, text string) string {
+	if config.JiraIssue == "" || strings.Contains(text, config.JiraIssue) {
+		return text
+	}
+	return fmt.Sprintf("%s: %s", config.JiraIssue, text)
+}
+
+// AllPullRequestsMerged returns true if the supplied statuses hold at least one pull request, and all of them are merged
+func AllPullRequestsMerged(statuses []types.PullRequestStatus) bool {
+	for _, status := range statuses {
+		if status.State != PullRequestStateMerged {
+			return false
+		}
+	}
+	return len(statuses) > 0
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the key of --jira-issue is put in front of the branch name, commit message and pull request title, unless
+// they already mention it
+func TestJiraIssueKeyIsInjected(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.JiraIssue = "PLAT-123"
+	testConfig.BranchName = "upgrade-ci"
+	testConfig.CommitMessage = "Upgrade CI"
+	testConfig.PullRequestTitle = "Upgrade CI for PLAT-123"
+	testConfig.SkipRunMarkers = true
+
+	assert.Equal(t, "PLAT-123-upgrade-ci", BranchNameWithJiraIssue(testConfig))
+	assert.Equal(t, "PLAT-123: Upgrade CI", commitMessageWithMarkers(testConfig))
+
+	title, _, err := renderPullRequest(testConfig, mocks.GetMockGithubRepo(), changePart{})
+	require.NoError(t, err)
+	assert.Equal(t, "Upgrade CI for PLAT-123", title)
+
+	testConfig.BranchName = "plat-123/PLAT-123-upgrade-ci"
+	assert.Equal(t, "plat-123/PLAT-123-upgrade-ci", BranchNameWithJiraIssue(testConfig))
+}
+
+func TestAllPullRequestsMerged(t *testing.T) {
+	t.Parallel()
+
+	merged := types.PullRequestStatus{State: PullRequestStateMerged}
+	assert.True(t, AllPullRequestsMerged([]types.PullRequestStatus{merged, merged}))
+	assert.False(t, AllPullRequestsMerged([]types.PullRequestStatus{merged, {State: PullRequestStateOpen}}))
+	assert.False(t, AllPullRequestsMerged(nil))
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/git-xargs/version"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// RunMarkerLabel returns the name of the label that is added to every pull request opened by the run with the supplied ID
+func RunMarkerLabel(runID string) string {
+	return common.RunIDMarkerLabelPrefix + runID
+}
+
+// RunMarkerComment returns the hidden HTML comment that is added to the body of every pull request opened by the run
+// with the supplied ID. It does not render on GitHub, but is returned by the API and matched by search
+func RunMarkerComment(runID string) string {
+	return fmt.Sprintf("<!-- git-xargs-run-id: %s -->", runID)
+}
+
+// commitMessageWithMarkers puts the key of --jira-issue in front of the configured commit message, if it was passed, and
+// appends the run ID as a git trailer to it, unless --skip-run-markers was passed, and adds the --skip-ci-trailer if
+// --skip-ci-in includes commits. A --skip-ci-trailer in the key: value form of a git trailer goes with the run ID
+// trailer, while a marker such as [skip ci] is appended to the subject line
+func commitMessageWithMarkers(config *config.GitXargsConfig) string {
+	message := withJiraIssueKey(config, config.CommitMessage)
+	var trailers []string
+
+	if config.SkipCITrailer != "" && config.SkipCIIn != common.SkipCIInTitle {
+		if util.IsGitTrailer(config.SkipCITrailer) {
+			trailers = append(trailers, config.SkipCITrailer)
+		} else {
+			lines := strings.SplitN(message, "\n", 2)
+			lines[0] = fmt.Sprintf("%s %s", lines[0], config.SkipCITrailer)
+			message = strings.Join(lines, "\n")
+		}
+	}
+	if !config.SkipRunMarkers {
+		trailers = append(trailers, fmt.Sprintf("%s: %s", common.RunIDTrailerKey, config.RunID))
+	}
+
+	if len(trailers) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s\n\n%s", message, strings.Join(trailers, "\n"))
+}
+
+// titleWithSkipCIMarker appends the --skip-ci-trailer to the supplied pull request title, if --skip-ci-in includes titles.
+// Trailers in the key: value form are rejected at startup for titles, and are never put in one
+func titleWithSkipCIMarker(config *config.GitXargsConfig, title string) string {
+	if config.SkipCITrailer == "" || util.IsGitTrailer(config.SkipCITrailer) || (config.SkipCIIn != common.SkipCIInTitle && config.SkipCIIn != common.SkipCIInBoth) {
+		return title
+	}
+	return fmt.Sprintf("%s %s", title, config.SkipCITrailer)
+}
+
+// descriptionWithRunMarker appends the run marker comment to the supplied pull request description, unless
+// --skip-run-markers was passed
+func descriptionWithRunMarker(config *config.GitXargsConfig, description string) string {
+	if config.SkipRunMarkers {
+		return description
+	}
+	return fmt.Sprintf("%s\n\n%s", description, RunMarkerComment(config.RunID))
+}
+
+// descriptionWithBuildInfo appends the version, commit and build date of git-xargs to the supplied pull request
+// description, so that a pull request can be traced back to the build that opened it, unless --skip-run-markers was
+// passed
+func descriptionWithBuildInfo(config *config.GitXargsConfig, description string) string {
+	if config.SkipRunMarkers {
+		return description
+	}
+	return fmt.Sprintf("%s\n\n<sub>Opened by %s</sub>", description, version.Summary())
+}
+
+// addRunMarkerLabel adds the run's marker label to the supplied pull request, unless --skip-run-markers was passed.
+// GitHub creates the label in the repo if it doesn't exist yet. Failures are tracked, but don't fail the repo, since
+// the pull request itself was opened successfully
+func addRunMarkerLabel(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	if config.SkipRunMarkers {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	labels := []string{RunMarkerLabel(config.RunID)}
+	if _, _, err := config.GithubClient.Issues.AddLabelsToIssue(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), labels); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error adding run marker label to pull request")
+
+		config.Stats.TrackSingle(stats.RunMarkerLabelErr, repo)
+	}
+}
+
+// setCommitStatus sets a successful commit status with the "git-xargs" context on the supplied commit, if
+// --commit-status was passed, so that downstream tooling and humans can trace the change back to this run. Commits that
+// were never pushed, because of --dry-run, are skipped. Failures are tracked, but don't fail the repo
+func setCommitStatus(config *config.GitXargsConfig, repo *github.Repository, commitHash plumbing.Hash) {
+	if !config.CommitStatus || config.DryRun {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	status := &github.RepoStatus{
+		State:       github.String("success"),
+		Context:     github.String(common.CommitStatusContext),
+		Description: github.String(fmt.Sprintf("Changed by git-xargs run %s", config.RunID)),
+	}
+
+	if config.CommitStatusURL != "" {
+		targetURL, err := util.RenderTemplate(config.CommitStatusURL, newTemplateData(config, repo))
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  repo.GetName(),
+			}).Debug("Error rendering commit status URL template")
+
+			config.Stats.TrackSingle(stats.CommitStatusErr, repo)
+			return
+		}
+		status.TargetURL = github.String(targetURL)
+	}
+
+	if _, _, err := config.GithubClient.Repositories.CreateStatus(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), commitHash.String(), status); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":  err,
+			"Repo":   repo.GetName(),
+			"Commit": commitHash.String(),
+		}).Debug("Error setting commit status")
+
+		config.Stats.TrackSingle(stats.CommitStatusErr, repo)
+	}
+}
+
+// openPullRequestExistsForRunMarker returns true if there is an open pull request in the given repo carrying the run's
+// marker label, regardless of which branch it was opened from
+func openPullRequestExistsForRunMarker(config *config.GitXargsConfig, repo *github.Repository) (bool, error) {
+	query := fmt.Sprintf("repo:%s/%s is:pr is:open label:\"%s\"", repo.GetOwner().GetLogin(), repo.GetName(), RunMarkerLabel(config.RunID))
+	result, _, err := config.GithubClient.Search.Issues(config.Context, query, nil)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+		return false, errors.WithStackTrace(err)
+	}
+	return result.GetTotal() > 0, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/version"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the run ID is added as a commit trailer and pull request body marker
+func TestRunMarkersAreAdded(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RunID = "20240102T150405-abcdef01"
+	testConfig.CommitMessage = "Update CI"
+
+	assert.Equal(t, "Update CI\n\nGit-Xargs-Run-Id: 20240102T150405-abcdef01", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Body\n\n<!-- git-xargs-run-id: 20240102T150405-abcdef01 -->", descriptionWithRunMarker(testConfig, "Body"))
+	assert.Equal(t, "git-xargs:20240102T150405-abcdef01", RunMarkerLabel(testConfig.RunID))
+	assert.Equal(t, "Body\n\n<sub>Opened by "+version.Summary()+"</sub>", descriptionWithBuildInfo(testConfig, "Body"))
+}
+
+// Test that no run markers are added when --skip-run-markers is passed
+func TestRunMarkersAreSkipped(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.SkipRunMarkers = true
+	testConfig.CommitMessage = "Update CI"
+
+	assert.Equal(t, "Update CI", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Body", descriptionWithRunMarker(testConfig, "Body"))
+	assert.Equal(t, "Body", descriptionWithBuildInfo(testConfig, "Body"))
+}
+
+// Test that the --skip-ci-trailer is added where --skip-ci-in says
+func TestSkipCIMarkers(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RunID = "20240102T150405-abcdef01"
+	testConfig.CommitMessage = "Update CI\n\nBumps the runner image"
+	testConfig.SkipCITrailer = "[skip ci]"
+
+	assert.Equal(t, "Update CI [skip ci]\n\nBumps the runner image\n\nGit-Xargs-Run-Id: 20240102T150405-abcdef01", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Update CI", titleWithSkipCIMarker(testConfig, "Update CI"))
+
+	testConfig.SkipCITrailer = "skip-checks: true"
+	assert.Equal(t, "Update CI\n\nBumps the runner image\n\nskip-checks: true\nGit-Xargs-Run-Id: 20240102T150405-abcdef01", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Update CI", titleWithSkipCIMarker(testConfig, "Update CI"))
+
+	testConfig.SkipCITrailer = "[skip ci]"
+	testConfig.SkipCIIn = common.SkipCIInBoth
+	assert.Equal(t, "Update CI [skip ci]\n\nBumps the runner image\n\nGit-Xargs-Run-Id: 20240102T150405-abcdef01", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Update CI [skip ci]", titleWithSkipCIMarker(testConfig, "Update CI"))
+
+	testConfig.SkipCITrailer = "[skip ci]"
+	testConfig.SkipCIIn = common.SkipCIInTitle
+	testConfig.SkipRunMarkers = true
+	assert.Equal(t, "Update CI\n\nBumps the runner image", commitMessageWithMarkers(testConfig))
+	assert.Equal(t, "Update CI [skip ci]", titleWithSkipCIMarker(testConfig, "Update CI"))
+}
+
+// Test that the commit status is only set on pushed commits when --commit-status is passed
+func TestSetCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.CommitStatus = true
+	testConfig.CommitStatusURL = "https://ci.example.com/git-xargs/{{.RunID}}"
+
+	setCommitStatus(testConfig, mocks.GetMockGithubRepo(), plumbing.ZeroHash)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.CommitStatusErr))
+
+	testConfig.CommitStatusURL = "https://ci.example.com/git-xargs/{{.Missing}}"
+	setCommitStatus(testConfig, mocks.GetMockGithubRepo(), plumbing.ZeroHash)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.CommitStatusErr), 1)
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/sirupsen/logrus"
+)
+
+// checksPollInterval is how often --approve-and-merge looks up the required checks of a pull request while waiting for
+// them to pass
+const checksPollInterval = 30 * time.Second
+
+// approveAndMergePullRequest approves the supplied pull request as the identity whose token was exported as
+// GITHUB_APPROVER_OAUTH_TOKEN, which satisfies branch protection rules that require an approval, and then merges it
+// as the identity that opened it, once the checks its base branch requires have passed. Failures are tracked, but don't
+// fail the repo, since the pull request itself was opened successfully and can still be merged by hand
+func approveAndMergePullRequest(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	logger := logging.GetLogger("git-xargs")
+
+	review := &github.PullRequestReviewRequest{
+		Event: github.String("APPROVE"),
+	}
+
+	if _, _, err := config.ApproverGithubClient.PullRequests.CreateReview(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), review); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error approving pull request")
+
+		config.Stats.TrackSingle(stats.PullRequestApproveErr, repo)
+		return
+	}
+
+	config.Stats.TrackSingle(stats.PullRequestApproved, repo)
+
+	// Merging before the checks the base branch requires have passed would be rejected, so wait for them first
+	if required := requiredChecks(config, repo, pr.GetBase().GetRef()); len(required) > 0 {
+		checks, err := waitForRequiredChecks(config, repo, pr.GetHead().GetSHA(), required)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Error waiting for the required checks of pull request")
+
+			config.Stats.TrackSingle(stats.PullRequestMergeErr, repo)
+			return
+		}
+		if checks == ChecksFailing {
+			config.Stats.TrackSingle(stats.PullRequestNotMergeable, repo)
+			return
+		}
+		if checks == ChecksPending {
+			config.Stats.TrackSingle(stats.ApproveAndMergeAwaitsChecks, repo)
+			return
+		}
+	}
+
+	mergePullRequest(config, repo, pr)
+}
+
+// requiredChecks returns the names of the status checks the supplied branch of the supplied repo requires before pull
+// requests can be merged into it. Branches that aren't protected, or whose protection can't be read because the token
+// lacks admin rights on the repo, are treated as requiring none
+func requiredChecks(config *config.GitXargsConfig, repo *github.Repository, branch string) []string {
+	protection, err := lookupBranchProtection(config, repo, branch)
+	if err != nil {
+		logging.GetLogger("git-xargs").WithFields(logrus.Fields{
+			"Error":  err,
+			"Repo":   repo.GetName(),
+			"Branch": branch,
+		}).Debug("Error looking up the required checks of the base branch")
+		return nil
+	}
+	if protection == nil {
+		return nil
+	}
+	return protection.requiredChecks
+}
+
+// waitForRequiredChecks polls the supplied required checks of the supplied commit until they have all passed, one of
+// them has failed or --merge-checks-timeout has passed, and returns the state they were last in
+func waitForRequiredChecks(config *config.GitXargsConfig, repo *github.Repository, sha string, required []string) (string, error) {
+	deadline := time.Now().Add(config.MergeChecksTimeout)
+	for {
+		checks, err := getRequiredChecksState(config, repo, sha, required)
+		if err != nil || checks != ChecksPending || !time.Now().Before(deadline) {
+			return checks, err
+		}
+
+		select {
+		case <-time.After(checksPollInterval):
+		case <-config.Context.Done():
+			return ChecksPending, nil
+		}
+	}
+}
+
+// mergePullRequest merges the supplied pull request using --merge-method, returning true if it was merged. GitHub
+// still enforces the base branch's protection rules, so failures are tracked rather than treated as fatal
+func mergePullRequest(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) bool {
+	logger := logging.GetLogger("git-xargs")
+
+	opts := &github.PullRequestOptions{
+		MergeMethod: config.MergeMethod,
+	}
+
+	result, _, err := config.GithubClient.PullRequests.Merge(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), "", opts)
+	if err != nil || !result.GetMerged() {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Message":          result.GetMessage(),
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error merging pull request")
+
+		config.Stats.TrackSingle(stats.PullRequestMergeErr, repo)
+		return false
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Pull Request URL": pr.GetHTMLURL(),
+		"SHA":              result.GetSHA(),
+	}).Debug("Successfully merged pull request")
+
+	config.Stats.TrackSingle(stats.PullRequestMerged, repo)
+	return true
+}
+
+// MergeRunPullRequests merges every pull request opened by the run passed via --run-id that is ready to merge: it is
+// open and not a draft, the checks its base branch requires have passed, or all its checks if it requires none, no
+// reviewer has requested changes and it doesn't conflict with its base branch. If --delete-branch was passed, the
+// branch of every merged pull request is deleted afterwards
+func MergeRunPullRequests(config *config.GitXargsConfig) error {
+	logger := logging.GetLogger("git-xargs")
+
+	return forEachRunPullRequest(config, config.RunID, func(repo *github.Repository, recordedPR state.PullRequest) {
+		repoConfig, err := withRepoHost(config, repo)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up the GitHub host of pull request")
+
+			config.Stats.TrackError(repo, err)
+			return
+		}
+		pr, _, err := repoConfig.GithubClient.PullRequests.Get(repoConfig.Context, repo.GetOwner().GetLogin(), repo.GetName(), recordedPR.Number)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up pull request")
+
+			repoConfig.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+			return
+		}
+
+		if getPullRequestState(pr) == PullRequestStateMerged || getPullRequestState(pr) == PullRequestStateClosed {
+			logger.WithFields(logrus.Fields{
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Skipping pull request that is no longer open")
+			return
+		}
+
+		reason, err := getNotMergeableReason(repoConfig, repo, pr)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Error checking whether pull request is ready to merge")
+
+			repoConfig.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+			return
+		}
+		if reason != "" {
+			logger.WithFields(logrus.Fields{
+				"Pull Request URL": pr.GetHTMLURL(),
+				"Reason":           reason,
+			}).Info("Skipping pull request that is not ready to merge")
+
+			repoConfig.Stats.TrackSingle(stats.PullRequestNotMergeable, repo)
+			return
+		}
+
+		if mergePullRequest(repoConfig, repo, pr) && repoConfig.DeleteBranch {
+			deleteRemoteBranch(repoConfig, repo, pr.GetHead().GetRef())
+		}
+	})
+}
+
+// getNotMergeableReason returns why the supplied open pull request is not ready to merge, or an empty string if it is
+func getNotMergeableReason(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) (string, error) {
+	if pr.GetDraft() {
+		return "pull request is a draft", nil
+	}
+
+	// GitHub computes mergeability in the background, so Mergeable is only set once it has done so
+	if pr.Mergeable != nil && !pr.GetMergeable() {
+		return "pull request conflicts with its base branch", nil
+	}
+
+	// Only the checks the base branch requires have to pass, if it requires any, so that optional checks that fail or
+	// never finish don't hold the pull request up
+	var checks string
+	var err error
+	if required := requiredChecks(config, repo, pr.GetBase().GetRef()); len(required) > 0 {
+		checks, err = getRequiredChecksState(config, repo, pr.GetHead().GetSHA(), required)
+	} else {
+		checks, err = getChecksState(config, repo, pr.GetHead().GetSHA())
+	}
+	if err != nil {
+		return "", err
+	}
+	switch checks {
+	case ChecksFailing:
+		return "checks are failing", nil
+	case ChecksPending:
+		return "checks are still running", nil
+	}
+
+	review, err := getReviewState(config, repo, pr.GetNumber())
+	if err != nil {
+		return "", err
+	}
+	if review == ReviewChangesRequested {
+		return "a reviewer requested changes", nil
+	}
+
+	return "", nil
+}
+
+// deleteRemoteBranch deletes the supplied branch from the supplied repo on GitHub
+func deleteRemoteBranch(config *config.GitXargsConfig, repo *github.Repository, branch string) {
+	logger := logging.GetLogger("git-xargs")
+
+	ref := "heads/" + strings.TrimPrefix(branch, "refs/heads/")
+	if _, err := config.GithubClient.Git.DeleteRef(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), ref); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":  err,
+			"Repo":   repo.GetName(),
+			"Branch": branch,
+		}).Debug("Error deleting branch")
+
+		config.Stats.TrackSingle(stats.BranchDeleteErr, repo)
+		return
+	}
+
+	config.Stats.TrackSingle(stats.BranchDeleted, repo)
+}
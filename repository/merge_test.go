@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that a pull request is approved by the second identity and then merged
+func TestApproveAndMergePullRequest(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.ApproverGithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.ApproveAndMerge = true
+
+	repo := mocks.GetMockGithubRepo()
+	approveAndMergePullRequest(testConfig, repo, mocks.GetMockPullRequest())
+
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestApproved), 1)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestMerged), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestMergeErr))
+}
+
+// Test that the pull requests of an earlier run are merged, and their branches deleted, when they are ready to merge
+func TestMergeRunPullRequests(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.RunID = "run-1"
+	testConfig.DeleteBranch = true
+
+	store, cleanup := newTestStateStore(t, testConfig.RunID)
+	defer cleanup()
+	testConfig.State = store
+
+	require.NoError(t, MergeRunPullRequests(testConfig))
+
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestMerged), 1)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.BranchDeleted), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestNotMergeable))
+}
+
+// Test that --approve-and-merge merges once the checks the base branch requires have passed, and leaves the pull request
+// open if a required check doesn't report within --merge-checks-timeout, regardless of other checks
+func TestApproveAndMergeWaitsForRequiredChecks(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		required []string
+		merged   bool
+	}{
+		{[]string{"build"}, true},
+		{[]string{"build", "deploy"}, false},
+	} {
+		testConfig := config.NewGitXargsTestConfig()
+		testConfig.GithubClient = mocks.ConfigureMockGithubClientWithBranchProtection(&github.Protection{
+			RequiredStatusChecks: &github.RequiredStatusChecks{Contexts: testCase.required},
+		}, false)
+		testConfig.ApproverGithubClient = mocks.ConfigureMockGithubClient()
+		testConfig.ApproveAndMerge = true
+		testConfig.MergeChecksTimeout = 0
+
+		approveAndMergePullRequest(testConfig, mocks.GetMockGithubRepo(), mocks.GetMockPullRequest())
+
+		if testCase.merged {
+			assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestMerged), 1, testCase.required)
+		} else {
+			assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestMerged), testCase.required)
+			assert.Len(t, testConfig.Stats.GetMultiple(stats.ApproveAndMergeAwaitsChecks), 1, testCase.required)
+		}
+	}
+}
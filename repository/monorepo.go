@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// monorepoManifest is the format of the file passed via --monorepo-manifest, which lists the directories of one or more
+// monorepos to run the command in, each as if it was a repo of its own
+type monorepoManifest struct {
+	Monorepos []monorepoManifestEntry `yaml:"monorepos"`
+}
+
+type monorepoManifestEntry struct {
+	Repo        string   `yaml:"repo"`
+	Directories []string `yaml:"directories"`
+}
+
+// monorepoCommit is the commit made for the changes the command made in one directory of a monorepo
+type monorepoCommit struct {
+	directory  string
+	branchName string
+	commitHash plumbing.Hash
+}
+
+// LoadMonorepoManifest reads the manifest passed via --monorepo-manifest, and returns the directories to run the
+// command in, keyed by the <org>/<repo> name of the monorepo they belong to, in the order they are listed
+func LoadMonorepoManifest(manifestPath string) (map[string][]string, error) {
+	contents, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	manifest := &monorepoManifest{}
+	if err := yaml.UnmarshalStrict(contents, manifest); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidMonorepoManifestErr{File: manifestPath, Err: err})
+	}
+	if len(manifest.Monorepos) == 0 {
+		return nil, errors.WithStackTrace(types.InvalidMonorepoManifestErr{File: manifestPath, Err: fmt.Errorf("it lists no monorepos")})
+	}
+
+	targets := map[string][]string{}
+	for _, entry := range manifest.Monorepos {
+		parts := strings.Split(entry.Repo, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.WithStackTrace(types.InvalidMonorepoManifestErr{File: manifestPath, Err: fmt.Errorf("%q is not in the format of <org>/<repo>", entry.Repo)})
+		}
+		if len(entry.Directories) == 0 {
+			return nil, errors.WithStackTrace(types.InvalidMonorepoManifestErr{File: manifestPath, Err: fmt.Errorf("%s lists no directories", entry.Repo)})
+		}
+
+		key := strings.ToLower(entry.Repo)
+		for _, directory := range entry.Directories {
+			cleaned := path.Clean(strings.TrimSpace(directory))
+			if cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+				return nil, errors.WithStackTrace(types.InvalidMonorepoManifestErr{File: manifestPath, Err: fmt.Errorf("%q of %s is not a subdirectory of the repo", directory, entry.Repo)})
+			}
+			targets[key] = append(targets[key], cleaned)
+		}
+	}
+
+	return targets, nil
+}
+
+// MonorepoNames returns the <org>/<repo> names of the monorepos of the supplied manifest targets, sorted by name
+func MonorepoNames(targets map[string][]string) []string {
+	names := []string{}
+	for name := range targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// monorepoDirectories returns the directories of the supplied repo to run the command in, if --monorepo-manifest
+// lists it, or nil if the command is to be run in the whole repo
+func monorepoDirectories(config *config.GitXargsConfig, repo *github.Repository) []string {
+	return config.MonorepoTargets[strings.ToLower(repo.GetOwner().GetLogin()+"/"+repo.GetName())]
+}
+
+// runCommandInDirectories runs the command in each of the supplied directories of the monorepo of the supplied job,
+// committing the changes made in each directory separately. Unless pull requests are opened per directory, the commits
+// all go on the branch of the job. Otherwise each directory gets a branch of its own, stacked on the one before, so
+// that each pull request only shows the changes to its own directory
+func runCommandInDirectories(job *repoJob, directories []string) error {
+	config, repo := job.config, job.repo
+	logger := logging.GetLogger("git-xargs")
+	perDirectory := config.MonorepoPullRequests == common.MonorepoPerDirectory
+
+	head, err := job.localRepository.Head()
+	if err != nil {
+		config.Stats.TrackSingle(stats.GetHeadRefFailed, repo)
+		return errors.WithStackTrace(err)
+	}
+	base := head.Hash()
+
+	for i, directory := range directories {
+		directoryPath := filepath.Join(job.repositoryDir, filepath.FromSlash(directory))
+		if info, err := os.Stat(directoryPath); err != nil || !info.IsDir() {
+			logger.WithFields(logrus.Fields{
+				"Repo":      repo.GetName(),
+				"Directory": directory,
+			}).Warn("Skipping monorepo directory because it doesn't exist")
+
+			config.Stats.TrackSingle(stats.MonorepoDirectoryNotFound, repo)
+			continue
+		}
+
+		branchName := job.branchName
+		if perDirectory {
+			part := changePart{Index: i + 1, Total: len(directories), Directory: directory}
+			branchName = plumbing.NewBranchReferenceName(part.branchName(config)).String()
+
+			// Each directory's branch starts from the commit of the directory before it
+			co := &git.CheckoutOptions{Hash: base, Branch: plumbing.ReferenceName(branchName), Create: true, Force: true}
+			if err := job.worktree.Checkout(co); err != nil {
+				config.Stats.TrackSingle(stats.BranchCheckoutFailed, repo)
+				return errors.WithStackTrace(err)
+			}
+		}
+
+		if err := runCommandAndTransforms(job, directoryPath); err != nil {
+			return err
+		}
+
+		status, err := job.worktree.Status()
+		if err != nil {
+			config.Stats.TrackSingle(stats.WorktreeStatusCheckFailedCommand, repo)
+			return errors.WithStackTrace(err)
+		}
+		if status.IsClean() {
+			logger.WithFields(logrus.Fields{
+				"Repo":      repo.GetName(),
+				"Directory": directory,
+			}).Debug("Command made no changes in monorepo directory")
+			continue
+		}
+
+		// Each commit names the directory it changes, so that the history of a combined pull request reads well
+		directoryConfig := *config
+		directoryConfig.CommitMessage = commitMessageForDirectory(config.CommitMessage, directory)
+		commitHash, err := commitLocalChanges(status, &directoryConfig, job.repositoryDir, job.worktree, repo, job.localRepository)
+		if err != nil {
+			return err
+		}
+		base = commitHash
+
+		job.monorepoCommits = append(job.monorepoCommits, monorepoCommit{directory: directory, branchName: branchName, commitHash: commitHash})
+	}
+
+	if len(job.monorepoCommits) == 0 {
+		config.Stats.TrackSingle(stats.WorktreeStatusClean, repo)
+		job.finished = true
+	}
+	return nil
+}
+
+// commitMessageForDirectory appends the supplied monorepo directory to the subject line of the supplied commit message,
+// leaving its body alone
+func commitMessageForDirectory(commitMessage string, directory string) string {
+	lines := strings.SplitN(commitMessage, "\n", 2)
+	lines[0] = fmt.Sprintf("%s (%s)", lines[0], directory)
+	return strings.Join(lines, "\n")
+}
+
+// pushMonorepoChanges pushes the commits made by runCommandInDirectories, leaving their pull requests for the pull
+// request stage to open: a single combined one, or one per directory, stacked on the branch of the directory before it
+func pushMonorepoChanges(job *repoJob) error {
+	config, repo := job.config, job.repo
+
+	if config.MonorepoPullRequests != common.MonorepoPerDirectory {
+		pushedHash, err := pushLocalBranch(config, repo, job.localRepository, job.branchName)
+		if err != nil {
+			return err
+		}
+		last := job.monorepoCommits[len(job.monorepoCommits)-1]
+		setCommitStatus(config, repo, pushedHash)
+
+		directories := []string{}
+		for _, commit := range job.monorepoCommits {
+			directories = append(directories, commit.directory)
+		}
+		job.pendingPullRequests = []pendingPullRequest{{
+			commitHash: last.commitHash,
+			branchName: job.branchName,
+			part:       changePart{Directories: directories},
+		}}
+		return nil
+	}
+
+	baseBranch := ""
+	for i, commit := range job.monorepoCommits {
+		pushedHash, err := pushLocalBranch(config, repo, job.localRepository, commit.branchName)
+		if err != nil {
+			return err
+		}
+		setCommitStatus(config, repo, pushedHash)
+
+		job.pendingPullRequests = append(job.pendingPullRequests, pendingPullRequest{
+			commitHash: commit.commitHash,
+			branchName: commit.branchName,
+			part:       changePart{Index: i + 1, Total: len(job.monorepoCommits), Directory: commit.directory, BaseBranch: baseBranch},
+		})
+		baseBranch = plumbing.ReferenceName(commit.branchName).Short()
+	}
+	return nil
+}
+
+// directorySlug turns the supplied monorepo directory into something that can be used in a branch name
+func directorySlug(directory string) string {
+	return strings.Replace(strings.Trim(directory, "/"), "/", "-", -1)
+}
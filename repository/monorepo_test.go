@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the monorepo manifest is read into the directories of each monorepo, and that invalid manifests are rejected
+func TestLoadMonorepoManifest(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-monorepo-manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeManifest := func(contents string) string {
+		manifestPath := filepath.Join(dir, "manifest.yml")
+		require.NoError(t, ioutil.WriteFile(manifestPath, []byte(contents), 0644))
+		return manifestPath
+	}
+
+	targets, err := LoadMonorepoManifest(writeManifest(`
+monorepos:
+  - repo: gruntwork-io/Platform
+    directories:
+      - services/api/
+      - services/web
+  - repo: gruntwork-io/infra
+    directories:
+      - modules/vpc
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"gruntwork-io/platform": {"services/api", "services/web"},
+		"gruntwork-io/infra":    {"modules/vpc"},
+	}, targets)
+	assert.Equal(t, []string{"gruntwork-io/infra", "gruntwork-io/platform"}, MonorepoNames(targets))
+
+	for _, invalid := range []string{
+		"monorepos: []",
+		"monorepos:\n  - repo: platform\n    directories: [api]",
+		"monorepos:\n  - repo: gruntwork-io/platform",
+		"monorepos:\n  - repo: gruntwork-io/platform\n    directories: [../other]",
+		"monorepos:\n  - repo: gruntwork-io/platform\n    directory: [api]",
+	} {
+		_, err := LoadMonorepoManifest(writeManifest(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+// newMonorepoJob returns a job for a local monorepo holding the supplied directories, with a README.md in each
+func newMonorepoJob(t *testing.T, testConfig *config.GitXargsConfig, directories ...string) (*repoJob, func()) {
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-monorepo-test")
+	require.NoError(t, err)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	for _, directory := range directories {
+		require.NoError(t, os.MkdirAll(filepath.Join(repositoryDir, directory), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, directory, "README.md"), []byte("docs\n"), 0644))
+	}
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	_, err = worktree.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	require.NoError(t, err)
+
+	job := &repoJob{
+		repo:            mocks.GetMockGithubRepo(),
+		config:          testConfig,
+		repositoryDir:   repositoryDir,
+		localRepository: localRepository,
+		worktree:        worktree,
+		branchName:      "refs/heads/master",
+	}
+	return job, func() { os.RemoveAll(repositoryDir) }
+}
+
+// Test that the command is run in each listed directory of a monorepo, with a commit per directory that changed, and
+// that missing directories are skipped
+func TestRunCommandInDirectoriesCombined(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Args = []string{"touch", "CHANGELOG.md"}
+
+	job, cleanup := newMonorepoJob(t, testConfig, "services/api", "services/web")
+	defer cleanup()
+
+	require.NoError(t, runCommandInDirectories(job, []string{"services/api", "services/missing", "services/web"}))
+
+	require.Len(t, job.monorepoCommits, 2)
+	assert.Equal(t, "services/api", job.monorepoCommits[0].directory)
+	assert.Equal(t, "services/web", job.monorepoCommits[1].directory)
+	assert.Equal(t, "refs/heads/master", job.monorepoCommits[1].branchName)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.MonorepoDirectoryNotFound), 1)
+
+	commit, err := job.localRepository.CommitObject(job.monorepoCommits[1].commitHash)
+	require.NoError(t, err)
+	assert.Contains(t, commit.Message, "(services/web)")
+	assert.Equal(t, job.monorepoCommits[0].commitHash, commit.ParentHashes[0])
+}
+
+// Test that per-directory pull requests get stacked branches, each starting from the commit of the directory before
+func TestRunCommandInDirectoriesPerDirectory(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Args = []string{"touch", "CHANGELOG.md"}
+	testConfig.MonorepoPullRequests = common.MonorepoPerDirectory
+
+	job, cleanup := newMonorepoJob(t, testConfig, "services/api", "services/web")
+	defer cleanup()
+
+	require.NoError(t, runCommandInDirectories(job, []string{"services/api", "services/web"}))
+
+	require.Len(t, job.monorepoCommits, 2)
+	assert.Equal(t, plumbing.NewBranchReferenceName(testConfig.BranchName+"-services-api").String(), job.monorepoCommits[0].branchName)
+	assert.Equal(t, plumbing.NewBranchReferenceName(testConfig.BranchName+"-services-web").String(), job.monorepoCommits[1].branchName)
+
+	webBranch, err := job.localRepository.Reference(plumbing.ReferenceName(job.monorepoCommits[1].branchName), true)
+	require.NoError(t, err)
+	commit, err := job.localRepository.CommitObject(webBranch.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, job.monorepoCommits[0].commitHash, commit.ParentHashes[0])
+}
+
+func TestCommitMessageForDirectory(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Update CI (services/web)", commitMessageForDirectory("Update CI", "services/web"))
+	assert.Equal(t, "Update CI (services/web)\n\nBumps the runner image", commitMessageForDirectory("Update CI\n\nBumps the runner image", "services/web"))
+}
+
+// Test that stacked pull requests name their directory and are opened against the branch they build on
+func TestMonorepoChangePart(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	part := changePart{Index: 2, Total: 2, Directory: "services/web", BaseBranch: "git-xargs-services-api"}
+
+	title, description := part.decorate(testConfig, "Update CI", "Updates CI")
+	assert.Equal(t, "Update CI (services/web)", title)
+	assert.Contains(t, description, "2 of 2 stacked pull requests")
+	assert.Equal(t, "gruntwork-io/terragrunt (services/web)", part.reportName(mocks.GetMockGithubRepo()))
+
+	_, description = changePart{Directories: []string{"services/api", "services/web"}}.decorate(testConfig, "Update CI", "Updates CI")
+	assert.Contains(t, description, "- `services/api`\n- `services/web`")
+}
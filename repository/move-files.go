@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/gruntwork-io/git-xargs/events"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ParseFileMoves reads the moves passed via --move-file, in the format of <glob>=<destination>. Both are paths relative
+// to the root of the repo, which may not leave it
+func ParseFileMoves(moveFiles []string) ([]types.FileMove, error) {
+	var moves []types.FileMove
+
+	for _, moveFile := range moveFiles {
+		parts := strings.SplitN(moveFile, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.WithStackTrace(types.InvalidMoveFileFlagErr{MoveFile: moveFile})
+		}
+		pattern := strings.Trim(strings.TrimSpace(parts[0]), "/")
+		destination := strings.TrimLeft(strings.TrimSpace(parts[1]), "/")
+		if !isRepoRelativePath(pattern) || !isRepoRelativePath(strings.TrimSuffix(destination, "/")) {
+			return nil, errors.WithStackTrace(types.InvalidMoveFileFlagErr{MoveFile: moveFile})
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.WithStackTrace(types.InvalidMoveFileFlagErr{MoveFile: moveFile})
+		}
+		moves = append(moves, types.FileMove{Pattern: pattern, Destination: destination})
+	}
+
+	return moves, nil
+}
+
+// isRepoRelativePath returns true if the supplied path is a non-empty path inside the root of a repo
+func isRepoRelativePath(repoPath string) bool {
+	if repoPath == "" || path.IsAbs(repoPath) {
+		return false
+	}
+	for _, element := range strings.Split(repoPath, "/") {
+		if element == ".." || element == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// moveFiles makes the moves passed via --move-file in the supplied directory of the clone of the repo of the supplied
+// job. It takes the place of the command, and moves the files through the index of the clone as git mv would, so that
+// git sees the moved files as renamed and their history can be followed
+func moveFiles(job *repoJob, dir string) error {
+	config, repo := job.config, job.repo
+	logger := logging.GetLogger("git-xargs")
+	defer startPhase(config, repo, types.PhaseCommand)()
+
+	// The moves are relative to the directory they are made in, which is a directory of a monorepo or the root of the
+	// repo, while the index holds paths relative to the root of the repo
+	prefix, err := filepath.Rel(job.repositoryDir, dir)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	prefix = filepath.ToSlash(prefix)
+
+	for _, move := range config.FileMoves {
+		moved, err := moveMatchingFiles(job.localRepository, job.worktree, prefix, move)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  repo.GetName(),
+			}).Debug("Error moving files")
+			config.Stats.TrackSingle(stats.CommandErrorOccurredDuringExecution, repo)
+			config.Events.RepoError(events.CommandFailed, repo, err)
+			return err
+		}
+
+		logger.WithFields(logrus.Fields{
+			"Repo":        repo.GetName(),
+			"Pattern":     move.Pattern,
+			"Destination": move.Destination,
+			"Moved files": moved,
+		}).Debug("Moved files matching pattern")
+	}
+
+	config.Events.Repo(events.CommandSucceeded, repo)
+	return nil
+}
+
+// moveMatchingFiles moves the tracked files under the supplied prefix of the repo that match the pattern of the supplied
+// move, or that are in a directory matching it, and returns the number of files it moved. A repo without any matches is
+// left alone
+func moveMatchingFiles(localRepository *git.Repository, worktree *git.Worktree, prefix string, move types.FileMove) (int, error) {
+	index, err := localRepository.Storer.Index()
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+	trackedFiles := []string{}
+	for _, entry := range index.Entries {
+		trackedFiles = append(trackedFiles, entry.Name)
+	}
+
+	pattern := path.Join(prefix, move.Pattern)
+	destination := path.Join(prefix, move.Destination)
+	intoDirectory := strings.HasSuffix(move.Destination, "/")
+
+	matches := matchTrackedPaths(trackedFiles, pattern)
+	if len(matches) > 1 && !intoDirectory {
+		return 0, errors.WithStackTrace(types.AmbiguousFileMoveErr{Pattern: move.Pattern, Destination: move.Destination, Matches: matches})
+	}
+
+	moved := 0
+	for _, match := range matches {
+		target := destination
+		if intoDirectory {
+			target = path.Join(destination, path.Base(match))
+		}
+		for _, trackedFile := range trackedFiles {
+			if trackedFile != match && !strings.HasPrefix(trackedFile, match+"/") {
+				continue
+			}
+			if _, err := worktree.Move(trackedFile, target+strings.TrimPrefix(trackedFile, match)); err != nil {
+				return moved, errors.WithStackTrace(err)
+			}
+			moved++
+		}
+	}
+	return moved, nil
+}
+
+// matchTrackedPaths returns the sorted paths that match the supplied pattern among the supplied tracked files and the
+// directories they are in. A matching directory stands in for the files in it, so those aren't returned on their own
+func matchTrackedPaths(trackedFiles []string, pattern string) []string {
+	matched := map[string]bool{}
+	for _, trackedFile := range trackedFiles {
+		elements := strings.Split(trackedFile, "/")
+		for i := range elements {
+			candidate := strings.Join(elements[:i+1], "/")
+			if ok, _ := path.Match(pattern, candidate); ok {
+				matched[candidate] = true
+				break
+			}
+		}
+	}
+
+	matches := []string{}
+	for match := range matched {
+		matches = append(matches, match)
+	}
+	sort.Strings(matches)
+	return matches
+}
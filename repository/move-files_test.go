@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileMoves(t *testing.T) {
+	t.Parallel()
+
+	moves, err := ParseFileMoves([]string{".circleci/config.yml=.github/workflows/ci.yml", " /docs/*.md = documentation/ "})
+	require.NoError(t, err)
+	assert.Equal(t, []types.FileMove{
+		{Pattern: ".circleci/config.yml", Destination: ".github/workflows/ci.yml"},
+		{Pattern: "docs/*.md", Destination: "documentation/"},
+	}, moves)
+
+	for _, invalid := range []string{"README.md", "=docs/", "README.md=", "../secrets=stolen", "docs=../docs", "[=docs"} {
+		_, err := ParseFileMoves([]string{invalid})
+		assert.IsType(t, types.InvalidMoveFileFlagErr{}, errors.Unwrap(err), invalid)
+	}
+}
+
+// newMoveFilesJob returns a job for a repo whose only commit holds the supplied tracked files, along with a function
+// that removes it
+func newMoveFilesJob(t *testing.T, moves []types.FileMove, files ...string) (*repoJob, func()) {
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-move-files")
+	require.NoError(t, err)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	for _, file := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repositoryDir, file)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, file), []byte(file), 0644))
+	}
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	_, err = worktree.Commit("base", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+	require.NoError(t, err)
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.FileMoves = moves
+	job := &repoJob{
+		repo:            mocks.GetMockGithubRepo(),
+		config:          testConfig,
+		repositoryDir:   repositoryDir,
+		localRepository: localRepository,
+		worktree:        worktree,
+	}
+	return job, func() { os.RemoveAll(repositoryDir) }
+}
+
+// Test that matching files and directories are moved through the index, so that the changes are staged as renames
+func TestMoveFiles(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newMoveFilesJob(t, []types.FileMove{
+		{Pattern: ".circleci/config.yml", Destination: ".github/workflows/ci.yml"},
+		{Pattern: "docs/*.md", Destination: "documentation/"},
+		{Pattern: "scripts", Destination: "tools/scripts"},
+	}, ".circleci/config.yml", "docs/intro.md", "docs/usage.md", "docs/logo.png", "scripts/build.sh", "scripts/lib/common.sh", "README.md")
+	defer cleanup()
+
+	require.NoError(t, moveFiles(job, job.repositoryDir))
+
+	status, err := job.worktree.Status()
+	require.NoError(t, err)
+	for _, moved := range []string{".github/workflows/ci.yml", "documentation/intro.md", "documentation/usage.md", "tools/scripts/build.sh", "tools/scripts/lib/common.sh"} {
+		assert.Equal(t, git.Added, status.File(moved).Staging, moved)
+	}
+	for _, removed := range []string{".circleci/config.yml", "docs/intro.md", "docs/usage.md", "scripts/build.sh", "scripts/lib/common.sh"} {
+		assert.Equal(t, git.Deleted, status.File(removed).Staging, removed)
+	}
+	assert.Len(t, status, 10)
+
+	content, err := ioutil.ReadFile(filepath.Join(job.repositoryDir, "documentation", "intro.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "docs/intro.md", string(content))
+}
+
+// Test that a pattern matching several paths can only be moved into a directory, and that no matches leave the repo alone
+func TestMoveFilesAmbiguousAndMissing(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newMoveFilesJob(t, []types.FileMove{{Pattern: "*.md", Destination: "README.md"}}, "CHANGELOG.md", "README.md")
+	defer cleanup()
+	err := moveFiles(job, job.repositoryDir)
+	assert.IsType(t, types.AmbiguousFileMoveErr{}, errors.Unwrap(err))
+
+	job, cleanup = newMoveFilesJob(t, []types.FileMove{{Pattern: ".circleci/config.yml", Destination: ".github/workflows/ci.yml"}}, "README.md")
+	defer cleanup()
+	require.NoError(t, moveFiles(job, job.repositoryDir))
+	status, err := job.worktree.Status()
+	require.NoError(t, err)
+	assert.True(t, status.IsClean())
+}
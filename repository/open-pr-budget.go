@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// openPullRequestBudget limits the number of git-xargs pull requests that are open at once to --max-open-prs, counting
+// either the pull requests of the run or those of every run in the organization of each repo, per --max-open-prs-scope.
+// The pull requests that were already open are counted once per scope via the search API, and the ones the run opens
+// are counted as it goes. Each repo reserves a pull request before it is started, so that the repos in flight can't
+// overshoot the budget, and gives back what it didn't use once it is finished. It is safe to call from the concurrent
+// goroutines that process repos
+type openPullRequestBudget struct {
+	config *config.GitXargsConfig
+	open   map[string]int
+	mutex  *sync.Mutex
+}
+
+// newOpenPullRequestBudget returns the budget of --max-open-prs for the run, or nil if it wasn't passed. A nil budget
+// never holds back any repo
+func newOpenPullRequestBudget(config *config.GitXargsConfig) *openPullRequestBudget {
+	if config.MaxOpenPRs <= 0 {
+		return nil
+	}
+	return &openPullRequestBudget{
+		config: config,
+		open:   map[string]int{},
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// reserve reserves a pull request for the supplied repo, and returns false if the budget is used up, in which case the
+// repo should be queued. If the pull requests that were already open can't be counted, the repo is queued too, so that
+// the budget is never overshot
+func (budget *openPullRequestBudget) reserve(repo *github.Repository) bool {
+	if budget == nil {
+		return true
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	scope := budget.scope(repo)
+	open, counted := budget.open[scope]
+	if !counted {
+		var err error
+		open, err = budget.countOpenPullRequests(repo)
+		if err != nil {
+			logger := logging.GetLogger("git-xargs")
+			logger.WithFields(logrus.Fields{
+				"Error": err,
+				"Repo":  repo.GetName(),
+			}).Warn("Could not count the open git-xargs pull requests for --max-open-prs, queueing the repo")
+			return false
+		}
+		budget.open[scope] = open
+	}
+
+	if open >= budget.config.MaxOpenPRs {
+		return false
+	}
+	budget.open[scope] = open + 1
+	return true
+}
+
+// settle gives back the pull request the supplied repo reserved, and counts the ones it actually opened instead, which
+// may be none, e.g. because the command changed nothing, or more than one, if its changes were split up
+func (budget *openPullRequestBudget) settle(repo *github.Repository, opened int) {
+	if budget == nil {
+		return
+	}
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+	budget.open[budget.scope(repo)] += opened - 1
+}
+
+// scope returns the key under which the open pull requests that count towards the budget of the supplied repo are
+// counted: the organization of the repo with the org scope, or the run with the run scope
+func (budget *openPullRequestBudget) scope(repo *github.Repository) string {
+	if budget.config.MaxOpenPRsScope == common.MaxOpenPRsScopeOrg {
+		return repo.GetOwner().GetLogin()
+	}
+	return ""
+}
+
+// countOpenPullRequests counts the git-xargs pull requests that count towards the budget of the supplied repo and are
+// already open, by searching for the run marker in their descriptions. Pull requests opened with --skip-run-markers
+// can't be found, and aren't counted
+func (budget *openPullRequestBudget) countOpenPullRequests(repo *github.Repository) (int, error) {
+	query := fmt.Sprintf("is:pr is:open in:body %q", RunMarkerComment(budget.config.RunID))
+	if budget.config.MaxOpenPRsScope == common.MaxOpenPRsScopeOrg {
+		query = fmt.Sprintf("is:pr is:open in:body org:%s %q", repo.GetOwner().GetLogin(), "git-xargs-run-id")
+	}
+
+	result, _, err := budget.config.GithubClient.Search.Issues(budget.config.Context, query, nil)
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+	return result.GetTotal(), nil
+}
+
+// queueRepo records the supplied repo as queued for the continue subcommand, because the --max-open-prs budget is used up
+func queueRepo(config *config.GitXargsConfig, repo *github.Repository) {
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name":    repo.GetName(),
+		"Max open PRs": config.MaxOpenPRs,
+	}).Info("Queueing repo for git-xargs continue, because --max-open-prs pull requests are open")
+
+	config.Stats.TrackSingle(stats.RepoQueuedForOpenPRBudget, repo)
+	logStateErr(config.State.RecordQueued(config.RunID, repo), repo)
+}
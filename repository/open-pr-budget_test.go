@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that repos are only started while the run has room for their pull requests, and that repos that didn't open
+// one give their room back
+func TestOpenPullRequestBudget(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.MaxOpenPRs = 2
+	repo := mocks.GetMockGithubRepo()
+
+	budget := newOpenPullRequestBudget(testConfig)
+	assert.True(t, budget.reserve(repo))
+	assert.True(t, budget.reserve(repo))
+	assert.False(t, budget.reserve(repo))
+
+	// A repo that opened no pull request gives its room back, while one whose changes were split takes up more
+	budget.settle(repo, 0)
+	assert.True(t, budget.reserve(repo))
+	budget.settle(repo, 2)
+	assert.False(t, budget.reserve(repo))
+
+	// Without --max-open-prs, no repo is held back
+	testConfig.MaxOpenPRs = 0
+	assert.Nil(t, newOpenPullRequestBudget(testConfig))
+	assert.True(t, (*openPullRequestBudget)(nil).reserve(repo))
+}
+
+// Test that with the org scope, the pull requests already open in the organization of each repo count towards its budget
+func TestOpenPullRequestBudgetPerOrg(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.MaxOpenPRs = 3
+	testConfig.MaxOpenPRsScope = common.MaxOpenPRsScopeOrg
+
+	budget := newOpenPullRequestBudget(testConfig)
+	budget.open["busy-org"] = 3
+
+	busyRepo := &github.Repository{Owner: &github.User{Login: github.String("busy-org")}, Name: github.String("api")}
+	assert.False(t, budget.reserve(busyRepo))
+	assert.True(t, budget.reserve(mocks.GetMockGithubRepo()))
+
+	queueRepo(testConfig, busyRepo)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.RepoQueuedForOpenPRBudget), 1)
+}
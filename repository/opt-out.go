@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/sirupsen/logrus"
+)
+
+// dropOptedOutRepos returns the supplied repos, less those carrying the opt-out topic, which are tracked as skipped.
+// Checking the topic up front spares cloning repos that opted out of every run
+func dropOptedOutRepos(config *config.GitXargsConfig, repos []*github.Repository) []*github.Repository {
+	kept := []*github.Repository{}
+	for _, repo := range repos {
+		if hasOptOutTopic(repo) {
+			skipOptedOutRepo(config, repo, "topic "+common.OptOutTopic)
+			continue
+		}
+		kept = append(kept, repo)
+	}
+	return kept
+}
+
+// hasOptOutTopic returns true if the supplied repo carries the opt-out topic
+func hasOptOutTopic(repo *github.Repository) bool {
+	for _, topic := range repo.Topics {
+		if topic == common.OptOutTopic {
+			return true
+		}
+	}
+	return false
+}
+
+// optedOutViaMarkerFile returns true if the supplied clone of a repo has the opt-out marker file at its root, in which
+// case the repo is tracked as skipped
+func optedOutViaMarkerFile(config *config.GitXargsConfig, repo *github.Repository, repositoryDir string) bool {
+	if _, err := os.Stat(filepath.Join(repositoryDir, common.OptOutMarkerFileName)); err != nil {
+		return false
+	}
+	skipOptedOutRepo(config, repo, "marker file "+common.OptOutMarkerFileName)
+	return true
+}
+
+// skipOptedOutRepo logs and tracks the supplied repo as skipped, because it opted out via the supplied signal
+func skipOptedOutRepo(config *config.GitXargsConfig, repo *github.Repository, signal string) {
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Via":       signal,
+	}).Info("Skipping repo because it opted out of git-xargs")
+
+	config.Stats.TrackSingle(stats.RepoOptedOutSkipped, repo)
+}
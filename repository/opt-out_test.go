@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropOptedOutRepos(t *testing.T) {
+	t.Parallel()
+
+	repos := []*github.Repository{
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("git-xargs"), Topics: []string{"go", "cli"}},
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("cloud-nuke"), Topics: []string{common.OptOutTopic}},
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("terratest")},
+	}
+
+	testConfig := config.NewGitXargsTestConfig()
+	kept := dropOptedOutRepos(testConfig, repos)
+
+	assert.Equal(t, []*github.Repository{repos[0], repos[2]}, kept)
+	assert.Equal(t, []*github.Repository{repos[1]}, testConfig.Stats.GetMultiple(stats.RepoOptedOutSkipped))
+}
+
+func TestOptedOutViaMarkerFile(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-opt-out")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	repo := &github.Repository{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("git-xargs")}
+	testConfig := config.NewGitXargsTestConfig()
+
+	assert.False(t, optedOutViaMarkerFile(testConfig, repo, repositoryDir))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, common.OptOutMarkerFileName), nil, 0644))
+	assert.True(t, optedOutViaMarkerFile(testConfig, repo, repositoryDir))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.RepoOptedOutSkipped), 1)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadPathLabels reads the rules passed via --path-labels, which map each label to the globs of the paths that get a
+// pull request the label, like the labeler GitHub Action:
+//
+//	documentation:
+//	  - docs/**
+//	  - "**/*.md"
+//
+// The rules are returned keyed by label
+func LoadPathLabels(rulesPath string) (map[string][]string, error) {
+	contents, err := ioutil.ReadFile(rulesPath)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	rules := map[string][]string{}
+	if err := yaml.UnmarshalStrict(contents, &rules); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidPathLabelsErr{File: rulesPath, Err: err})
+	}
+	if len(rules) == 0 {
+		return nil, errors.WithStackTrace(types.InvalidPathLabelsErr{File: rulesPath, Err: fmt.Errorf("it lists no labels")})
+	}
+	for label, globs := range rules {
+		if strings.TrimSpace(label) == "" || len(globs) == 0 {
+			return nil, errors.WithStackTrace(types.InvalidPathLabelsErr{File: rulesPath, Err: fmt.Errorf("label %q lists no paths", label)})
+		}
+	}
+	return rules, nil
+}
+
+// matchPathLabels returns the labels of the supplied rules that at least one of the supplied paths matches, sorted
+func matchPathLabels(rules map[string][]string, paths []string) []string {
+	labels := []string{}
+	for label, globs := range rules {
+		if anyPathMatches(globs, paths) {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// anyPathMatches returns true if any of the supplied paths matches any of the supplied globs
+func anyPathMatches(globs []string, paths []string) bool {
+	for _, glob := range globs {
+		pattern := globToRegexp(glob)
+		for _, path := range paths {
+			if pattern.MatchString(path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// globToRegexp converts the supplied glob into a regular expression that matches the whole of a path. As in the globs
+// of the labeler GitHub Action, * and ? match within a directory, while ** matches across directories
+func globToRegexp(glob string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			pattern.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			pattern.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			pattern.WriteString("[^/]*")
+		case glob[i] == '?':
+			pattern.WriteString("[^/]")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(glob[i])))
+		}
+	}
+	pattern.WriteString("$")
+	return regexp.MustCompile(pattern.String())
+}
+
+// addPathLabels adds the labels of the --path-labels rules that the files changed by the supplied pull request match
+// to it. The files are those changed by the commit at the head of the supplied local branch, which is what the pull
+// request was opened from, so that they don't need to be listed via the API. GitHub creates the labels in the repo if
+// they don't exist yet. Failures are tracked, but don't fail the repo, since the pull request itself was opened
+// successfully
+func addPathLabels(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest, localRepository *git.Repository, branch string) {
+	if len(config.PathLabels) == 0 || localRepository == nil {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	owner, name := repo.GetOwner().GetLogin(), repo.GetName()
+
+	paths, err := branchChangedPaths(localRepository, branch)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error computing the files changed by pull request")
+
+		config.Stats.TrackSingle(stats.PathLabelsErr, repo)
+		return
+	}
+
+	labels := matchPathLabels(config.PathLabels, paths)
+	if len(labels) == 0 {
+		return
+	}
+	if _, _, err := config.GithubClient.Issues.AddLabelsToIssue(config.Context, owner, name, pr.GetNumber(), labels); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+			"Labels":           labels,
+		}).Debug("Error adding path labels to pull request")
+
+		config.Stats.TrackSingle(stats.PathLabelsErr, repo)
+	}
+}
+
+// branchChangedPaths returns the paths of the files changed by the commit at the head of the supplied local branch. A
+// renamed file counts under both its old and its new path
+func branchChangedPaths(localRepository *git.Repository, branch string) ([]string, error) {
+	head, err := branchHeadHash(localRepository, branch)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := getCommitPatch(localRepository, head)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	paths := []string{}
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		if from != nil {
+			paths = append(paths, from.Path())
+		}
+		if to != nil && (from == nil || to.Path() != from.Path()) {
+			paths = append(paths, to.Path())
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the --path-labels rules are read keyed by label, and that invalid rules are rejected
+func TestLoadPathLabels(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-path-labels")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeRules := func(contents string) string {
+		rulesPath := filepath.Join(dir, "labels.yml")
+		require.NoError(t, ioutil.WriteFile(rulesPath, []byte(contents), 0644))
+		return rulesPath
+	}
+
+	rules, err := LoadPathLabels(writeRules(`
+documentation:
+  - docs/**
+  - "**/*.md"
+terraform:
+  - "*.tf"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"documentation": {"docs/**", "**/*.md"},
+		"terraform":     {"*.tf"},
+	}, rules)
+
+	for _, invalid := range []string{
+		"",
+		"documentation: []",
+		"documentation: docs/**",
+	} {
+		_, err := LoadPathLabels(writeRules(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+// Test that a label is matched by any of its globs, with * staying within a directory and ** crossing directories
+func TestMatchPathLabels(t *testing.T) {
+	t.Parallel()
+
+	rules := map[string][]string{
+		"documentation": {"docs/**", "**/*.md"},
+		"terraform":     {"*.tf"},
+		"ci":            {".github/workflows/*.yml"},
+	}
+
+	assert.Equal(t, []string{"documentation", "terraform"}, matchPathLabels(rules, []string{"main.tf", "modules/vpc/README.md"}))
+	assert.Equal(t, []string{"documentation"}, matchPathLabels(rules, []string{"docs/guides/setup.txt", "modules/vpc/main.tf"}))
+	assert.Equal(t, []string{"ci"}, matchPathLabels(rules, []string{".github/workflows/ci.yml"}))
+	assert.Empty(t, matchPathLabels(rules, []string{".github/workflows/nested/ci.yml", "mainXtf"}))
+}
+
+// Test that the pull request files are listed and labeled without tracking a failure
+func TestAddPathLabels(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-path-labels-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			path := filepath.Join(repositoryDir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		}
+		_, err := worktree.Add(".")
+		require.NoError(t, err)
+		_, err = worktree.Commit("commit", &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+	}
+
+	// Only the files of the commit at the head of the branch count, not the ones committed before it
+	commit(map[string]string{"main.tf": "resource\n", "go.mod": "module example\n"})
+	commit(map[string]string{"main.tf": "resource {}\n", "docs/README.md": "docs\n"})
+
+	var labels []string
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithLabels(&labels)
+	testConfig.PathLabels = map[string][]string{"terraform": {"*.tf"}, "docs": {"docs/**"}, "go": {"go.mod"}}
+	repo := mocks.GetMockGithubRepo()
+	pr := &github.PullRequest{Number: github.Int(1)}
+
+	addPathLabels(testConfig, repo, pr, localRepository, "refs/heads/master")
+	assert.Equal(t, []string{"docs", "terraform"}, labels)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PathLabelsErr))
+
+	// A branch that doesn't exist locally is tracked as a failure to label the pull request
+	addPathLabels(testConfig, repo, pr, localRepository, "refs/heads/missing")
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PathLabelsErr), 1)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// startPhase records that the supplied phase of processing the supplied repo has started, for the --progress
+// dashboard and the --otlp-endpoint traces. It returns a func, meant to be deferred, that records how long the phase
+// took in the run's stats and ends its span
+func startPhase(config *config.GitXargsConfig, repo *github.Repository, phase types.Phase) func() {
+	start := time.Now()
+	config.Progress.SetPhase(repo, phase)
+	span := config.Tracer.StartPhase(repo, phase)
+
+	return func() {
+		config.Stats.TrackDuration(repo, phase, start)
+		span.End(nil)
+	}
+}
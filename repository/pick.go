@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/picker"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// pickRepos shows the selected repos in the --pick repo picker, and returns the ones the user kept selected
+func pickRepos(config *config.GitXargsConfig, repos []*github.Repository) ([]*github.Repository, error) {
+	names := []string{}
+	for _, repo := range repos {
+		names = append(names, repo.GetOwner().GetLogin()+"/"+repo.GetName())
+	}
+
+	pickedNames, err := picker.Pick(names)
+	if err != nil {
+		return nil, err
+	}
+	return keepPickedRepos(config, repos, pickedNames)
+}
+
+// keepPickedRepos returns the supplied repos that were picked, and tracks the others as skipped in the run report
+func keepPickedRepos(config *config.GitXargsConfig, repos []*github.Repository, pickedNames []string) ([]*github.Repository, error) {
+	picked := map[string]bool{}
+	for _, name := range pickedNames {
+		picked[name] = true
+	}
+
+	pickedRepos := []*github.Repository{}
+	for _, repo := range repos {
+		if picked[repo.GetOwner().GetLogin()+"/"+repo.GetName()] {
+			pickedRepos = append(pickedRepos, repo)
+		} else {
+			config.Stats.TrackSingle(stats.RepoNotPickedSkipped, repo)
+		}
+	}
+
+	if len(pickedRepos) == 0 {
+		return nil, errors.WithStackTrace(types.NoReposPickedErr{})
+	}
+	return pickedRepos, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepPickedRepos(t *testing.T) {
+	t.Parallel()
+
+	selected := []*github.Repository{
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("git-xargs")},
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("cloud-nuke")},
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("terratest")},
+	}
+
+	testConfig := config.NewGitXargsTestConfig()
+	repos, err := keepPickedRepos(testConfig, selected, []string{"gruntwork-io/terratest", "gruntwork-io/git-xargs"})
+	require.NoError(t, err)
+
+	// The picked repos keep the order they were selected in, and the others are skipped
+	assert.Equal(t, []*github.Repository{selected[0], selected[2]}, repos)
+	assert.Equal(t, []*github.Repository{selected[1]}, testConfig.Stats.GetMultiple(stats.RepoNotPickedSkipped))
+
+	_, err = keepPickedRepos(config.NewGitXargsTestConfig(), selected, []string{})
+	assert.IsType(t, types.NoReposPickedErr{}, errors.Unwrap(err))
+}
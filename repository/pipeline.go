@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/tracing"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// repoJob is a repo moving through the stages of the pipeline, along with what the stages it went through so far found
+// out about it
+type repoJob struct {
+	index           int
+	repo            *github.Repository
+	config          *config.GitXargsConfig
+	cancelTimeout   context.CancelFunc
+	span            *tracing.Span
+	repositoryDir   string
+	localRepository *git.Repository
+	worktree        *git.Worktree
+	branchName      string
+	monorepoCommits []monorepoCommit
+	// pendingPullRequests are the pushed branches of the repo whose pull requests the pull request stage has to open
+	pendingPullRequests []pendingPullRequest
+	// heldForSchedule is set once the pull requests of the repo were held until the --pr-schedule window opened
+	heldForSchedule bool
+	// requireSignedCommits is set by checkBranchProtection if the base branch of the repo requires signed commits
+	requireSignedCommits bool
+	// startErr, if set, fails the repo before it reaches the first stage
+	startErr error
+	// finished is set by a stage once no later stage has anything left to do for the repo, e.g. because it was skipped
+	finished bool
+}
+
+// pendingPullRequest is a pushed branch whose pull request is left to open, along with the head commit of the branch
+// and the part of the changes of the repo it holds
+type pendingPullRequest struct {
+	commitHash plumbing.Hash
+	branchName string
+	part       changePart
+}
+
+// pipelineStage is one stage of processing repos, such as cloning them or running the command against them. Each stage
+// processes up to concurrency repos at once, or every repo handed to it if concurrency is 0
+type pipelineStage struct {
+	name        string
+	concurrency int
+	process     func(job *repoJob) error
+	// hold, if set, is called before a repo is processed, and returns when to try again if the repo has to wait, or the
+	// zero time to process it right away. Waiting repos don't take up any of the concurrency of the stage
+	hold func(job *repoJob) time.Time
+}
+
+// concurrencyLimit limits the number of repos in flight at once. Unlike a buffered channel, its limit can be changed
+// while repos are in flight, which --auto-concurrency relies on. A nil concurrencyLimit doesn't limit anything
+type concurrencyLimit struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	limit   int
+	inUse   int
+	// waited is set once acquire had to wait for a repo to finish, i.e. the limit held the run back
+	waited bool
+}
+
+// newConcurrencyLimit returns a concurrencyLimit that lets the supplied number of repos in flight at once, or nil to let
+// any number of them in flight if the number is 0
+func newConcurrencyLimit(limit int) *concurrencyLimit {
+	if limit <= 0 {
+		return nil
+	}
+	concurrency := &concurrencyLimit{limit: limit}
+	concurrency.changed = sync.NewCond(&concurrency.mutex)
+	return concurrency
+}
+
+// acquire waits until another repo may be in flight, and counts it as in flight
+func (concurrency *concurrencyLimit) acquire() {
+	if concurrency == nil {
+		return
+	}
+
+	concurrency.mutex.Lock()
+	defer concurrency.mutex.Unlock()
+	for concurrency.inUse >= concurrency.limit {
+		concurrency.waited = true
+		concurrency.changed.Wait()
+	}
+	concurrency.inUse++
+}
+
+// release counts a repo as no longer in flight
+func (concurrency *concurrencyLimit) release() {
+	if concurrency == nil {
+		return
+	}
+
+	concurrency.mutex.Lock()
+	defer concurrency.mutex.Unlock()
+	concurrency.inUse--
+	concurrency.changed.Broadcast()
+}
+
+// setLimit changes the number of repos that may be in flight at once. Lowering it doesn't stop the repos already in
+// flight, it only holds back new ones until enough of them finished
+func (concurrency *concurrencyLimit) setLimit(limit int) {
+	concurrency.mutex.Lock()
+	defer concurrency.mutex.Unlock()
+	concurrency.limit = limit
+	concurrency.changed.Broadcast()
+}
+
+// current returns the number of repos that may be in flight at once, and whether the limit held back any repo since the
+// last call
+func (concurrency *concurrencyLimit) current() (int, bool) {
+	concurrency.mutex.Lock()
+	defer concurrency.mutex.Unlock()
+	waited := concurrency.waited
+	concurrency.waited = false
+	return concurrency.limit, waited
+}
+
+// runPipeline runs count repos through the supplied stages, which are connected by bounded queues, so that the
+// network-heavy stages, such as cloning and pushing, overlap with the CPU-heavy ones, such as running the command. start
+// is called with the index of each repo to build its job, and may return nil to leave the repo out. finish is called
+// with each job once it failed, was finished by a stage or went through every stage. The number of repos between start
+// and finish at once is limited by inFlight. runPipeline returns once every repo is finished
+func runPipeline(count int, inFlight *concurrencyLimit, stages []pipelineStage, start func(index int) *repoJob, finish func(job *repoJob, err error)) {
+	if count == 0 || len(stages) == 0 {
+		return
+	}
+
+	complete := func(job *repoJob, err error) {
+		finish(job, err)
+		inFlight.release()
+	}
+
+	queues := make([]chan *repoJob, len(stages))
+	for i, stage := range stages {
+		size := stage.concurrency
+		if size <= 0 {
+			size = 1
+		}
+		queues[i] = make(chan *repoJob, size)
+	}
+
+	var stagesDone sync.WaitGroup
+	for i, stage := range stages {
+		var out chan *repoJob
+		if i+1 < len(stages) {
+			out = queues[i+1]
+		}
+		stagesDone.Add(1)
+		go func(stage pipelineStage, in chan *repoJob, out chan *repoJob) {
+			defer stagesDone.Done()
+			runPipelineStage(stage, in, out, complete)
+		}(stage, queues[i], out)
+	}
+
+	for index := 0; index < count; index++ {
+		inFlight.acquire()
+		job := start(index)
+		if job == nil {
+			inFlight.release()
+			continue
+		}
+		queues[0] <- job
+	}
+	close(queues[0])
+
+	stagesDone.Wait()
+}
+
+// runPipelineStage processes the jobs handed to the supplied stage, and hands the ones it didn't finish to the next
+// stage, if any. It closes the queue of the next stage once the queue of this stage is closed and drained
+func runPipelineStage(stage pipelineStage, in <-chan *repoJob, out chan<- *repoJob, complete func(job *repoJob, err error)) {
+	var slots chan struct{}
+	if stage.concurrency > 0 {
+		slots = make(chan struct{}, stage.concurrency)
+	}
+
+	var workers sync.WaitGroup
+	var handle func(job *repoJob)
+	// process handles the supplied job once the stage has a free slot for it
+	process := func(job *repoJob) {
+		if slots != nil {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+		}
+		handle(job)
+	}
+
+	handle = func(job *repoJob) {
+		if job.startErr != nil {
+			complete(job, job.startErr)
+			return
+		}
+
+		// Repos that were cancelled or timed out while they were queued don't start another stage
+		if err := job.config.Context.Err(); err != nil {
+			logger := logging.GetLogger("git-xargs")
+			logger.WithFields(logrus.Fields{
+				"Repo name": job.repo.GetName(),
+				"Stage":     stage.name,
+			}).Debug("Repo was cancelled before it reached the stage")
+			complete(job, errors.WithStackTrace(err))
+			return
+		}
+
+		// Repos that have to wait are set aside until it's time to try again, so that they don't hold up the others
+		if stage.hold != nil {
+			if until := stage.hold(job); !until.IsZero() {
+				workers.Add(1)
+				go func() {
+					defer workers.Done()
+					select {
+					case <-time.After(time.Until(until)):
+					case <-job.config.Context.Done():
+					}
+					process(job)
+				}()
+				return
+			}
+		}
+
+		err := stage.process(job)
+		if err != nil || job.finished || out == nil {
+			complete(job, err)
+			return
+		}
+		out <- job
+	}
+
+	if stage.concurrency > 0 {
+		for worker := 0; worker < stage.concurrency; worker++ {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for job := range in {
+					process(job)
+				}
+			}()
+		}
+	} else {
+		for job := range in {
+			workers.Add(1)
+			go func(job *repoJob) {
+				defer workers.Done()
+				process(job)
+			}(job)
+		}
+	}
+	workers.Wait()
+
+	if out != nil {
+		close(out)
+	}
+}
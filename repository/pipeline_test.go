@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrencyTracker records the most calls that were running at once
+type concurrencyTracker struct {
+	running, maxRunning int32
+}
+
+func (tracker *concurrencyTracker) enter() {
+	current := atomic.AddInt32(&tracker.running, 1)
+	for {
+		max := atomic.LoadInt32(&tracker.maxRunning)
+		if current <= max || atomic.CompareAndSwapInt32(&tracker.maxRunning, max, current) {
+			break
+		}
+	}
+}
+
+func (tracker *concurrencyTracker) exit() {
+	atomic.AddInt32(&tracker.running, -1)
+}
+
+func (tracker *concurrencyTracker) track(process func()) {
+	tracker.enter()
+	defer tracker.exit()
+	process()
+}
+
+// Test that each stage never processes more repos at once than its concurrency, that no more repos are in flight than
+// allowed, and that repos that fail or are finished by a stage skip the later stages
+func TestRunPipeline(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	var inFlight, cloning, running concurrencyTracker
+	var pushed int32
+
+	stages := []pipelineStage{
+		{name: "clone", concurrency: 2, process: func(job *repoJob) error {
+			cloning.track(func() { time.Sleep(time.Millisecond) })
+			// Every third repo is skipped
+			job.finished = job.index%3 == 0
+			return nil
+		}},
+		{name: "command", concurrency: 1, process: func(job *repoJob) error {
+			running.track(func() { time.Sleep(time.Millisecond) })
+			if job.index%3 == 1 {
+				return fmt.Errorf("command failed for repo %d", job.index)
+			}
+			return nil
+		}},
+		{name: "push", process: func(job *repoJob) error {
+			atomic.AddInt32(&pushed, 1)
+			return nil
+		}},
+	}
+
+	var mutex sync.Mutex
+	finished := map[int]error{}
+	runPipeline(12, newConcurrencyLimit(4), stages, func(index int) *repoJob {
+		// Repos left out by start never enter the pipeline
+		if index == 11 {
+			return nil
+		}
+		inFlight.enter()
+		return &repoJob{index: index, repo: &github.Repository{Name: github.String(fmt.Sprintf("repo-%d", index))}, config: testConfig}
+	}, func(job *repoJob, err error) {
+		inFlight.exit()
+		mutex.Lock()
+		defer mutex.Unlock()
+		finished[job.index] = err
+	})
+
+	assert.LessOrEqual(t, cloning.maxRunning, int32(2))
+	assert.Equal(t, int32(1), running.maxRunning)
+	assert.LessOrEqual(t, inFlight.maxRunning, int32(4))
+	assert.Len(t, finished, 11)
+	for index, err := range finished {
+		if index%3 == 1 {
+			assert.EqualError(t, err, fmt.Sprintf("command failed for repo %d", index))
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	// Repos 2, 5 and 8 made it through every stage
+	assert.Equal(t, int32(3), pushed)
+}
+
+// Test that repos a stage holds are set aside without taking up its concurrency, and are processed once it's time
+func TestRunPipelineHoldsRepos(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	held := map[int]bool{}
+	var mutex sync.Mutex
+	order := []int{}
+
+	stages := []pipelineStage{
+		{name: "pull request", concurrency: 1, process: func(job *repoJob) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			order = append(order, job.index)
+			return nil
+		}, hold: func(job *repoJob) time.Time {
+			// The first repo has to wait once, which mustn't hold up the second
+			mutex.Lock()
+			defer mutex.Unlock()
+			if job.index == 0 && !held[job.index] {
+				held[job.index] = true
+				return time.Now().Add(50 * time.Millisecond)
+			}
+			return time.Time{}
+		}},
+	}
+
+	runPipeline(2, nil, stages, func(index int) *repoJob {
+		return &repoJob{index: index, repo: &github.Repository{Name: github.String(fmt.Sprintf("repo-%d", index))}, config: testConfig}
+	}, func(job *repoJob, err error) {
+		assert.NoError(t, err)
+	})
+
+	assert.Equal(t, []int{1, 0}, order)
+}
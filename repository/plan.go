@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// addToPlan commits the changes the command made to the supplied repo locally, so that they can be diffed against the
+// commit they were made on, then records the diff, the changed files and the rendered pull request in the plan
+func addToPlan(config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository, status git.Status) error {
+	logger := logging.GetLogger("git-xargs")
+
+	commitHash, err := commitLocalChanges(status, config, repositoryDir, worktree, remoteRepository, localRepository)
+	if err != nil {
+		return err
+	}
+
+	commit, err := localRepository.CommitObject(commitHash)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, remoteRepository)
+		return errors.WithStackTrace(err)
+	}
+	baseCommit, err := commit.Parent(0)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, remoteRepository)
+		return errors.WithStackTrace(err)
+	}
+
+	patch, err := baseCommit.Patch(commit)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, remoteRepository)
+		return errors.WithStackTrace(err)
+	}
+
+	files, err := collectFileChanges(baseCommit, commit)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PlanRepoErr, remoteRepository)
+		return err
+	}
+
+	title, description, err := renderPullRequest(config, remoteRepository, changePart{})
+	if err != nil {
+		return err
+	}
+
+	config.Plan.AddRepo(&plan.Repo{
+		Owner:                  remoteRepository.GetOwner().GetLogin(),
+		Name:                   remoteRepository.GetName(),
+		BaseCommit:             baseCommit.Hash.String(),
+		PullRequestTitle:       title,
+		PullRequestDescription: description,
+		Diff:                   patch.String(),
+		Files:                  files,
+	})
+
+	logger.WithFields(logrus.Fields{
+		"Repo":  remoteRepository.GetName(),
+		"Files": len(files),
+	}).Debug("Added changes to the plan")
+
+	config.Stats.TrackSingle(stats.RepoAddedToPlan, remoteRepository)
+	return nil
+}
+
+// collectFileChanges returns every file changed between the supplied commits, with its contents after the change and
+// the hash of the file it replaced
+func collectFileChanges(baseCommit *object.Commit, commit *object.Commit) ([]plan.FileChange, error) {
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	changes, err := object.DiffTree(baseTree, tree)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	files := []plan.FileChange{}
+	seen := map[string]bool{}
+	for _, change := range changes {
+		for _, path := range []string{change.From.Name, change.To.Name} {
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			fileChange := plan.FileChange{Path: path, BaseBlob: blobHash(baseTree, path)}
+
+			file, err := tree.File(path)
+			if err == object.ErrFileNotFound {
+				fileChange.Deleted = true
+				files = append(files, fileChange)
+				continue
+			}
+			if err != nil {
+				return nil, errors.WithStackTrace(err)
+			}
+
+			contents, err := file.Contents()
+			if err != nil {
+				return nil, errors.WithStackTrace(err)
+			}
+			fileChange.Mode = uint32(file.Mode)
+			fileChange.Contents = []byte(contents)
+			files = append(files, fileChange)
+		}
+	}
+	return files, nil
+}
+
+// blobHash returns the hash of the file at the supplied path in the supplied tree, or an empty string if there is no
+// such file
+func blobHash(tree *object.Tree, path string) string {
+	file, err := tree.File(path)
+	if err != nil {
+		return ""
+	}
+	return file.Hash.String()
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/plan"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the changes recorded by addToPlan reproduce them exactly when written back to the commit they were planned
+// against, and that the plan goes stale once a planned file changes
+func TestPlanAndApplyFileChanges(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-plan-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	writeFile := func(name string, content string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, name), []byte(content), 0644))
+	}
+	readFile := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(repositoryDir, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+
+	writeFile("modified.txt", "original")
+	writeFile("deleted.txt", "original")
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	baseHash, err := worktree.Commit("base", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	// Simulate the changes made by the command
+	writeFile("modified.txt", "changed")
+	writeFile("added.txt", "new")
+	require.NoError(t, os.Remove(filepath.Join(repositoryDir, "deleted.txt")))
+	status, err := worktree.Status()
+	require.NoError(t, err)
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Plan = plan.New()
+	repo := mocks.GetMockGithubRepo()
+
+	require.NoError(t, addToPlan(testConfig, repositoryDir, worktree, repo, localRepository, status))
+	require.Len(t, testConfig.Plan.Repos, 1)
+	plannedRef, err := localRepository.Head()
+	require.NoError(t, err)
+
+	repoPlan := testConfig.Plan.Repos[0]
+	assert.Equal(t, baseHash.String(), repoPlan.BaseCommit)
+	assert.Equal(t, "gruntwork-io/terragrunt", repoPlan.FullName())
+	assert.Contains(t, repoPlan.Diff, "+changed")
+	assert.Len(t, repoPlan.Files, 3)
+
+	stale, err := findStaleFiles(localRepository, baseHash, repoPlan.Files)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+
+	// Writing the planned changes back to the base commit reproduces them
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Hash: baseHash, Force: true}))
+	require.NoError(t, writeFileChanges(repositoryDir, repoPlan.Files))
+	assert.Equal(t, "changed", readFile("modified.txt"))
+	assert.Equal(t, "new", readFile("added.txt"))
+	_, err = os.Stat(filepath.Join(repositoryDir, "deleted.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Once a planned file changes upstream, the plan is stale
+	require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Hash: baseHash, Force: true}))
+	writeFile("modified.txt", "someone else's change")
+	_, err = worktree.Add("modified.txt")
+	require.NoError(t, err)
+	upstreamHash, err := worktree.Commit("upstream", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	stale, err = findStaleFiles(localRepository, upstreamHash, repoPlan.Files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"modified.txt"}, stale)
+
+	// A branch that already holds the planned changes isn't stale
+	stale, err = findStaleFiles(localRepository, plannedRef.Hash(), repoPlan.Files)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
+
+func TestWriteFileChangesRejectsPathsOutsideTheRepo(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-plan-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	for _, path := range []string{"../escaped.txt", "dir/../../escaped.txt", "/etc/escaped.txt", ".git/hooks/pre-commit"} {
+		err := writeFileChanges(repositoryDir, []plan.FileChange{
+			{Path: "inside.txt", Contents: []byte("inside")},
+			{Path: path, Contents: []byte("escaped")},
+		})
+		assert.Equal(t, types.InvalidPlanPathErr{Path: path}, errors.Unwrap(err), path)
+	}
+
+	// Nothing is written if any path is rejected
+	_, err = os.Stat(filepath.Join(repositoryDir, "inside.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
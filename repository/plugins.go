@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plugins"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// selectReposViaPlugins passes the full names of the selected repos through the repo selectors of the --plugin plugins,
+// if any, and returns the repos they selected. Repos the plugins added are looked up via the GitHub API, like the repos
+// passed via --repo
+func selectReposViaPlugins(config *config.GitXargsConfig, repos []*github.Repository) ([]*github.Repository, error) {
+	if !config.Plugins.HasRepoSelectors() {
+		return repos, nil
+	}
+
+	reposByName := map[string]*github.Repository{}
+	names := []string{}
+	for _, repo := range repos {
+		name := repo.GetOwner().GetLogin() + "/" + repo.GetName()
+		reposByName[name] = repo
+		names = append(names, name)
+	}
+
+	selectedNames, err := config.Plugins.SelectRepos(config.Context, plugins.SelectReposRequest{
+		RunID:     config.RunID,
+		GithubOrg: config.GithubOrg,
+		Repos:     names,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	selectedRepos := []*github.Repository{}
+	addedRepos := []*types.AllowedRepo{}
+	malformedRepos := []string{}
+	seen := map[string]bool{}
+	for _, name := range selectedNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if repo, ok := reposByName[name]; ok {
+			selectedRepos = append(selectedRepos, repo)
+		} else if allowedRepo := util.ConvertStringToAllowedRepo(name); allowedRepo != nil {
+			addedRepos = append(addedRepos, allowedRepo)
+		} else {
+			malformedRepos = append(malformedRepos, name)
+		}
+	}
+	trackMalformedUserSuppliedRepoNames(config, malformedRepos)
+
+	if len(addedRepos) > 0 {
+		githubRepos, err := getFileDefinedRepos(config.Context, config.GithubClient, config.GithubHostClients, addedRepos, config.Stats)
+		if err != nil {
+			return nil, err
+		}
+		selectedRepos = append(selectedRepos, githubRepos...)
+	}
+
+	if len(selectedRepos) == 0 {
+		return nil, errors.WithStackTrace(types.NoValidReposFoundAfterFilteringErr{})
+	}
+	return selectedRepos, nil
+}
+
+// pluginEventTracker returns the function the hooks of the --plugin plugins use to track the supplied repo under a
+// custom event, registering the event with the run's stats the first time it is used
+func pluginEventTracker(config *config.GitXargsConfig, repo *github.Repository) func(event plugins.Event) error {
+	return func(event plugins.Event) error {
+		annotatedEvent := types.AnnotatedEvent{Event: types.Event(event.Event), Description: event.Description}
+		if err := config.Stats.RegisterEvent(annotatedEvent); err != nil {
+			return err
+		}
+		config.Stats.TrackSingle(annotatedEvent.Event, repo)
+		return nil
+	}
+}
+
+// postProcessRepo calls the post-process hooks of the --plugin plugins, if any, once the supplied repo has been
+// processed. Like the webhook, hooks that fail are logged rather than failing the repo, whose changes are already made
+func postProcessRepo(config *config.GitXargsConfig, repo *github.Repository, branchName string, processErr error) {
+	errorMessage := ""
+	if processErr != nil {
+		errorMessage = processErr.Error()
+	}
+
+	errs := config.Plugins.PostProcess(config.Context, plugins.PostProcessRequest{
+		RunID:          config.RunID,
+		Repo:           repo.GetOwner().GetLogin() + "/" + repo.GetName(),
+		BranchName:     branchName,
+		PullRequestURL: config.Stats.GetPullRequestURL(repo),
+		Error:          errorMessage,
+		TrackEvent:     pluginEventTracker(config, repo),
+	})
+
+	logger := logging.GetLogger("git-xargs")
+	for _, err := range errs {
+		logger.WithFields(logrus.Fields{
+			"Repo name": repo.GetName(),
+			"Error":     err,
+		}).Warn("Error running the post-process hook of a plugin")
+	}
+}
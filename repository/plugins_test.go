@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/plugins"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replaceReposSelector is a RepoSelector that replaces the selected repos with its own
+type replaceReposSelector struct {
+	repos []string
+}
+
+func (selector replaceReposSelector) SelectRepos(ctx context.Context, request plugins.SelectReposRequest) ([]string, error) {
+	return selector.repos, nil
+}
+
+func TestSelectReposViaPlugins(t *testing.T) {
+	t.Parallel()
+
+	selected := []*github.Repository{
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("git-xargs")},
+		{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("cloud-nuke")},
+	}
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.Plugins = &plugins.Set{}
+	require.NoError(t, testConfig.Plugins.Add("catalog", replaceReposSelector{repos: []string{"gruntwork-io/git-xargs", "gruntwork-io/terratest", "gruntwork-io/git-xargs"}}))
+
+	repos, err := selectReposViaPlugins(testConfig, selected)
+	require.NoError(t, err)
+
+	// cloud-nuke was dropped, git-xargs kept once, and terratest looked up via the GitHub API
+	require.Len(t, repos, 2)
+	assert.Equal(t, selected[0], repos[0])
+	assert.Equal(t, mocks.MockGithubRepositories[0].GetName(), repos[1].GetName())
+}
+
+// Test that plugins can track repos under custom events, which the run report then lists, but can't redefine the
+// built-in events or reuse a custom event with another description
+func TestPluginEventTracker(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	repo := mocks.GetMockGithubRepo()
+	trackEvent := pluginEventTracker(testConfig, repo)
+
+	needsReview := plugins.Event{Event: "needs-manual-review", Description: "Repos that need a manual review"}
+	require.NoError(t, trackEvent(needsReview))
+	require.NoError(t, trackEvent(needsReview))
+	assert.Len(t, testConfig.Stats.GetMultiple("needs-manual-review"), 1)
+
+	events := testConfig.Stats.Events()
+	assert.Equal(t, types.AnnotatedEvent{Event: "needs-manual-review", Description: "Repos that need a manual review"}, events[len(events)-1])
+
+	var report strings.Builder
+	require.NoError(t, testConfig.Stats.WriteReport(common.OutputFormatTable, &report))
+	assert.Contains(t, report.String(), "REPOS THAT NEED A MANUAL REVIEW")
+
+	assert.Error(t, trackEvent(plugins.Event{Event: "needs-manual-review", Description: "Something else"}))
+	assert.Error(t, trackEvent(plugins.Event{Event: string(stats.PullRequestOpenErr), Description: "Mine now"}))
+	assert.Error(t, trackEvent(plugins.Event{Event: "no-description"}))
+}
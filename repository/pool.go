@@ -0,0 +1,52 @@
+package repository
+
+import "sync"
+
+// poolResult is the error returned by processing the job at index in the worker pool
+type poolResult struct {
+	index int
+	err   error
+}
+
+// runWorkerPool calls process with the index of each of count jobs, on a pool of at most concurrency workers, or of one
+// worker per job if concurrency is 0. Jobs are handed to the workers through a bounded queue, and their errors are
+// collected through a result channel by the calling goroutine alone, so that no state is shared between workers. The
+// errors are returned in the order of the jobs, whatever order they finish in
+func runWorkerPool(count int, concurrency int, process func(index int) error) []error {
+	workers := concurrency
+	if workers <= 0 || workers > count {
+		workers = count
+	}
+
+	jobs := make(chan int, workers)
+	results := make(chan poolResult, workers)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results <- poolResult{index: index, err: process(index)}
+			}
+		}()
+	}
+
+	go func() {
+		for index := 0; index < count; index++ {
+			jobs <- index
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	errs := make([]error, count)
+	for result := range results {
+		errs[result.index] = result.err
+	}
+	return errs
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the pool never runs more jobs at once than its concurrency, and returns their errors in the order of the
+// jobs rather than the order they finish in
+func TestRunWorkerPool(t *testing.T) {
+	t.Parallel()
+
+	var running, maxRunning int32
+	errs := runWorkerPool(10, 3, func(index int) error {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		// Make the earlier jobs finish last
+		time.Sleep(time.Duration(10-index) * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		if index%2 == 0 {
+			return fmt.Errorf("job %d failed", index)
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Len(t, errs, 10)
+	for index, err := range errs {
+		if index%2 == 0 {
+			assert.EqualError(t, err, fmt.Sprintf("job %d failed", index))
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestRunWorkerPoolWithoutLimit(t *testing.T) {
+	t.Parallel()
+
+	var processed int32
+	errs := runWorkerPool(5, 0, func(index int) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	assert.Equal(t, int32(5), processed)
+	assert.Len(t, errs, 5)
+
+	assert.Empty(t, runWorkerPool(0, 0, func(index int) error { return nil }))
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/sirupsen/logrus"
+)
+
+// holdForPullRequestSchedule returns when the pull requests of the repo of the supplied job, whose branches have already
+// been pushed, should try the --pr-schedule window again, or the zero time if the window is open and has room for them.
+// The job is set aside until then, rather than holding up a worker of the pull request stage. If the run is
+// interrupted, --resume opens the pull requests of the branches that were held
+func holdForPullRequestSchedule(job *repoJob) time.Time {
+	config, repo := job.config, job.repo
+	if len(job.pendingPullRequests) == 0 {
+		return time.Time{}
+	}
+
+	next := config.PullRequestGate.Reserve(time.Now(), len(job.pendingPullRequests))
+	if next.IsZero() {
+		return next
+	}
+
+	if !job.heldForSchedule {
+		job.heldForSchedule = true
+		config.Stats.TrackSingle(stats.PullRequestHeldForSchedule, repo)
+	}
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo":     repo.GetName(),
+		"Branch":   job.branchName,
+		"Schedule": config.PullRequestSchedule,
+		"Opens at": next.Format(time.RFC1123),
+	}).Info("Holding pull requests until the --pr-schedule window opens")
+
+	return next
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/schedule"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that pull requests go straight through while the window is open, and are held while it's closed until it opens
+func TestHoldForPullRequestSchedule(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	job := &repoJob{
+		repo:                mocks.GetMockGithubRepo(),
+		config:              testConfig,
+		branchName:          "update-ci",
+		pendingPullRequests: []pendingPullRequest{{commitHash: plumbing.ZeroHash, branchName: "update-ci"}},
+	}
+	assert.True(t, holdForPullRequestSchedule(job).IsZero())
+
+	open, err := schedule.ParseWindow("daily 00:00-24:00", time.UTC)
+	require.NoError(t, err)
+	testConfig.PullRequestGate = schedule.NewGate(open, 0)
+	assert.True(t, holdForPullRequestSchedule(job).IsZero())
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.PullRequestHeldForSchedule))
+
+	// A window that only opens the day after tomorrow is closed for the whole test
+	closedDay := (time.Now().UTC().Weekday() + 2) % 7
+	closed, err := schedule.ParseWindow(closedDay.String()[:3]+" 09:00-11:00", time.UTC)
+	require.NoError(t, err)
+	testConfig.PullRequestGate = schedule.NewGate(closed, 0)
+	assert.True(t, holdForPullRequestSchedule(job).After(time.Now()))
+	assert.True(t, holdForPullRequestSchedule(job).After(time.Now()))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestHeldForSchedule), 1)
+
+	// Repos without pull requests to open aren't held
+	job.pendingPullRequests = nil
+	assert.True(t, holdForPullRequestSchedule(job).IsZero())
+}
@@ -1,42 +1,136 @@
 package repository
 
 import (
+	"os"
+
 	"github.com/google/go-github/v32/github"
 	"github.com/gruntwork-io/git-xargs/config"
-	"github.com/gruntwork-io/go-commons/logging"
-	"github.com/remeh/sizedwaitgroup"
+	"github.com/gruntwork-io/git-xargs/events"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/plugins"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/sirupsen/logrus"
 )
 
-// ProcessRepos loops through every repo we've selected and use a WaitGroup so that the processing can happen in parallel
+// ProcessRepos processes every repo we've selected on a pool of workers, so that the processing can happen in parallel.
+// If processing any of the repos failed, it returns a types.ProcessReposErr holding the error of each failed repo, once
+// every repo has been processed
 func ProcessRepos(gitxargsConfig *config.GitXargsConfig, repos []*github.Repository) error {
 	logger := logging.GetLogger("git-xargs")
 
-	// Limit the number of concurrent goroutines using the MaxConcurrentRepos config value
+	// If --progress was passed, show the live progress of the run until every repo has been processed
+	gitxargsConfig.Progress.Start(len(repos))
+	defer gitxargsConfig.Progress.Stop()
+
+	// If --events-file was passed, stream each lifecycle transition of the run as it happens
+	gitxargsConfig.Events.RunStarted(len(repos))
+	defer gitxargsConfig.Events.RunFinished()
+
+	// If --otlp-endpoint was passed, trace the run, each repo and each phase of processing it
+	runSpan := gitxargsConfig.Tracer.StartRun("ProcessRepos", map[string]string{"run.id": gitxargsConfig.RunID})
+	defer runSpan.End(nil)
+
+	// If --fail-fast, --max-failures or --max-failure-rate was passed, abort the rest of the run once too many repos failed
+	aborter := newRunAborter(gitxargsConfig, len(repos))
+	defer aborter.stop()
+
+	// Repos are cloned, have the command run against them, are pushed and have their pull requests opened in stages,
+	// so that the stages overlap. Limit the number of repos in flight using the MaxConcurrentRepos config value
 	// MaxConcurrentRepos == 0 will fall back to unlimited (previous default behavior)
-	wg := sizedwaitgroup.New(gitxargsConfig.MaxConcurrentRepos)
-
-	for _, repo := range repos {
-		wg.Add()
-		go func(gitxargsConfig *config.GitXargsConfig, repo *github.Repository) error {
-			defer wg.Done()
-			// For each repo, run the supplied command against it and, if it succeeds without error,
-			// commit the changes, push the local branch to remote and use the GitHub API to open a pr
-			processErr := processRepo(gitxargsConfig, repo)
-			if processErr != nil {
-				logger.WithFields(logrus.Fields{
-					"Repo name": repo.GetName(), "Error": processErr,
-				}).Debug("Error encountered while processing repo")
-			}
-			return processErr
+	inFlight := newConcurrencyLimit(gitxargsConfig.MaxConcurrentRepos)
+
+	// If --max-open-prs was passed, only start repos while the number of open pull requests leaves room for theirs
+	budget := newOpenPullRequestBudget(gitxargsConfig)
 
-		}(gitxargsConfig, repo)
+	// If --auto-concurrency was passed, adjust the limit as the run goes instead, based on how much load the GitHub API
+	// and this machine can take
+	tuner := startAutoConcurrency(gitxargsConfig)
+	defer tuner.stop()
+	if tuner != nil {
+		inFlight = tuner.limit
 	}
-	wg.Wait()
 
+	processErrs := make([]error, len(repos))
+	runPipeline(len(repos), inFlight, repoStages(gitxargsConfig), func(index int) *repoJob {
+		repo := repos[index]
+
+		// Once the run is cancelled or aborted, the repos already being processed are stopped, and no more are started
+		if runCancelled(gitxargsConfig) {
+			if aborter.aborted() {
+				gitxargsConfig.Stats.TrackSingle(stats.RunAbortedSkipped, repo)
+			} else {
+				gitxargsConfig.Stats.TrackSingle(stats.RunCancelledSkipped, repo)
+			}
+			return nil
+		}
+
+		// Once --max-open-prs pull requests are open, the rest of the repos are queued for git-xargs continue
+		if !budget.reserve(repo) {
+			queueRepo(gitxargsConfig, repo)
+			return nil
+		}
+
+		gitxargsConfig.Events.Repo(events.RepoStarted, repo)
+
+		// Repos on a GitHub host no client was configured for fail before they reach the first stage
+		hostConfig, hostErr := withRepoHost(gitxargsConfig, repo)
+		if hostErr != nil {
+			hostConfig = gitxargsConfig
+		}
+
+		// If --repo-timeout was passed, cancel the repo once it has taken that long
+		repoConfig, cancelRepoTimeout := withRepoTimeout(withRepoBaseBranch(hostConfig, repo))
+		return &repoJob{
+			index:         index,
+			repo:          repo,
+			config:        repoConfig,
+			cancelTimeout: cancelRepoTimeout,
+			span:          gitxargsConfig.Tracer.StartRepo(repo),
+			startErr:      hostErr,
+		}
+	}, func(job *repoJob, processErr error) {
+		repo := job.repo
+		removeCancelledClone(job.config, job.repositoryDir, repo)
+		processErr = checkRepoTimeout(gitxargsConfig, job.config, repo, processErr)
+		job.cancelTimeout()
+		budget.settle(repo, gitxargsConfig.Stats.CountPullRequests(repo))
+
+		if processErr != nil {
+			logger.WithFields(logrus.Fields{
+				"Repo name": repo.GetName(), "Error": processErr,
+			}).Debug("Error encountered while processing repo")
+			gitxargsConfig.Stats.TrackError(repo, processErr)
+			gitxargsConfig.Events.RepoError(events.RepoFailed, repo, processErr)
+			aborter.repoFailed(repo, processErr)
+		} else {
+			gitxargsConfig.Events.Repo(events.RepoSucceeded, repo)
+		}
+		job.span.End(processErr)
+		gitxargsConfig.Progress.Finish(repo, processErr)
+		logStateErr(gitxargsConfig.State.RecordOutcome(gitxargsConfig.RunID, repo, processErr), repo)
+		logAuditErr(gitxargsConfig.Audit.RepoFinished(repo, processErr), repo)
+		tuner.repoFinished(processErr)
+		postProcessRepo(gitxargsConfig, repo, job.branchName, processErr)
+		processErrs[job.index] = processErr
+	})
+
+	// Collect the error of each failed repo, keyed by its full name
+	repoErrors := map[string]error{}
+	for index, processErr := range processErrs {
+		if processErr != nil {
+			repoErrors[repos[index].GetOwner().GetLogin()+"/"+repos[index].GetName()] = processErr
+		}
+	}
+	if len(repoErrors) > 0 {
+		return errors.WithStackTrace(types.ProcessReposErr{RepoErrors: repoErrors, Total: len(repos)})
+	}
 	return nil
 }
 
+// processRepo runs the supplied repo through every stage of processing, one after the other:
 // 1. Attempt to clone it to the local filesystem. To avoid conflicts, this generates a new directory for each repo FOR EACH run, so heavy use of this tool may inflate your /tmp/ directory size
 // 2. Look up the HEAD ref of the repo, and create a new branch from that ref, specific to this tool so that we can safely make our changes in the branch
 // 3. Execute the supplied command against the locally cloned repo
@@ -47,15 +141,113 @@ func ProcessRepos(gitxargsConfig *config.GitXargsConfig, repos []*github.Reposit
 // 8. Track all successfully opened pull requests via the stats tracker so that we can print them out as part of our final
 // run report that is displayed in table format to the operator following each run
 func processRepo(config *config.GitXargsConfig, repo *github.Repository) error {
-	logger := logging.GetLogger("git-xargs")
+	config, err := withRepoHost(config, repo)
+	if err != nil {
+		return err
+	}
+	config = withRepoBaseBranch(config, repo)
+	job := &repoJob{repo: repo, config: config}
+	defer func() {
+		removeCancelledClone(config, job.repositoryDir, repo)
+	}()
+
+	for _, stage := range repoStages(config) {
+		if err := stage.process(job); err != nil || job.finished {
+			return err
+		}
+	}
+	return nil
+}
+
+// repoStages returns the stages of processing a repo, each limited to the concurrency passed via its flag
+func repoStages(config *config.GitXargsConfig) []pipelineStage {
+	// Files passed via --put-file and --delete-file are changed via the GitHub Contents API, without cloning the repo
+	if len(config.FileChanges) > 0 {
+		return []pipelineStage{
+			{name: "contents", concurrency: config.PushConcurrency, process: contentsStage},
+			{name: "pull request", concurrency: config.PullRequestConcurrency, process: pullRequestStage, hold: holdForPullRequestSchedule},
+		}
+	}
+	return []pipelineStage{
+		{name: "clone", concurrency: config.CloneConcurrency, process: cloneStage},
+		{name: "command", concurrency: config.CommandConcurrency, process: commandStage},
+		{name: "push", concurrency: config.PushConcurrency, process: pushStage},
+		{name: "pull request", concurrency: config.PullRequestConcurrency, process: pullRequestStage, hold: holdForPullRequestSchedule},
+	}
+}
+
+// cloneStage clones the repo of the supplied job and checks out the branch to make the changes on, unless the repo is
+// skipped because it was already handled
+func cloneStage(job *repoJob) error {
+	config, repo := job.config, job.repo
+
+	// If --resume was passed, skip the repos the interrupted run already got through
+	if alreadyProcessedByResumedRun(config, repo) {
+		job.finished = true
+		return nil
+	}
+
+	// If --skip-repos-with-open-pull-requests was passed, check for an open pull request from our branch, or carrying the
+	// marker label of the run passed via --run-id, before doing any work, so that re-running a campaign doesn't touch
+	// repos that were already handled
+	if skip, err := skipRepoWithOpenPullRequest(config, repo); skip || err != nil {
+		job.finished = skip
+		return err
+	}
+
+	// If --check-branch-protection was passed, predict what the protection of the base branch would block
+	if err := checkBranchProtection(job); err != nil {
+		return err
+	}
+	config = job.config
+
+	// Skip the repo if its base branch requires signed commits, since its pull request could never be merged
+	if skipRepoRequiringSignedCommits(job) {
+		job.finished = true
+		return nil
+	}
 
 	// Create a new temporary directory in the default temp directory of the system, but append
 	// git-xargs-<repo-name> to it so that it's easier to find when you're looking for it
 	repositoryDir, localRepository, cloneErr := cloneLocalRepository(config, repo)
+	job.repositoryDir = repositoryDir
 
 	if cloneErr != nil {
 		return cloneErr
 	}
+	job.localRepository = localRepository
+	recordCheckpoint(config, repo, state.CheckpointCloned)
+
+	// Skip the repo if its owners opted it out of git-xargs with a marker file, which can't be seen before cloning it
+	if optedOutViaMarkerFile(config, repo, repositoryDir) {
+		job.finished = true
+		return nil
+	}
+
+	// Apply the overrides of the .git-xargs.yml committed to the repo, if it has one, unless it opted out of the run
+	optedOut, repoConfigErr := applyRepoConfig(job)
+	if repoConfigErr != nil {
+		return repoConfigErr
+	}
+	if optedOut {
+		job.finished = true
+		return nil
+	}
+	// The repo may have its pull requests opened from a branch of its own, which the check above didn't look for
+	branchOverridden := job.config.BranchName != config.BranchName
+	config = job.config
+	if branchOverridden {
+		if skip, err := skipRepoWithOpenPullRequest(config, repo); skip || err != nil {
+			job.finished = skip
+			return err
+		}
+	}
+
+	// If --env-files-dir or --repo-env-file was passed, load the variables the command gets for the repo
+	if err := loadCommandEnv(job); err != nil {
+		return err
+	}
+	config = job.config
 
 	// Get HEAD ref from the repo
 	ref, headRefErr := getLocalRepoHeadRef(config, localRepository, repo)
@@ -69,6 +261,7 @@ func processRepo(config *config.GitXargsConfig, repo *github.Repository) error {
 	if worktreeErr != nil {
 		return worktreeErr
 	}
+	job.worktree = worktree
 
 	// Create a branch in the locally cloned copy of the repo to hold all the changes that may result from script execution
 	// Also, attempt to pull the latest from the remote branch if it exists
@@ -76,21 +269,153 @@ func processRepo(config *config.GitXargsConfig, repo *github.Repository) error {
 	if branchErr != nil {
 		return branchErr
 	}
+	job.branchName = branchName.String()
 
-	//Run the specified command
-	commandErr := executeCommand(config, repositoryDir, repo)
+	return nil
+}
+
+// skipRepoWithOpenPullRequest returns true if --skip-repos-with-open-pull-requests was passed and the supplied repo
+// already has an open pull request from our branch, or carrying the marker label of the run passed via --run-id, in
+// which case the repo is tracked as skipped
+func skipRepoWithOpenPullRequest(config *config.GitXargsConfig, repo *github.Repository) (bool, error) {
+	if !config.SkipReposWithOpenPRs {
+		return false, nil
+	}
+
+	alreadyOpen, err := openPullRequestExistsForBranch(config, repo)
+	if err != nil {
+		return false, err
+	}
+	if !alreadyOpen && config.RunIDSupplied && !config.SkipRunMarkers {
+		alreadyOpen, err = openPullRequestExistsForRunMarker(config, repo)
+		if err != nil {
+			return false, err
+		}
+	}
+	if !alreadyOpen {
+		return false, nil
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Branch":    config.BranchName,
+	}).Info("Skipping repo because a pull request is already open for this branch")
+
+	config.Stats.TrackSingle(stats.PullRequestAlreadyOpenSkipped, repo)
+	return true, nil
+}
+
+// commandStage runs the command against the clone of the repo of the supplied job
+func commandStage(job *repoJob) error {
+	// If --monorepo-manifest lists the repo, the command runs in each of its listed directories instead
+	if directories := monorepoDirectories(job.config, job.repo); len(directories) > 0 {
+		if err := runCommandInDirectories(job, directories); err != nil {
+			return err
+		}
+	} else if err := runCommandAndTransforms(job, job.repositoryDir); err != nil {
+		return err
+	}
+	recordCheckpoint(job.config, job.repo, state.CheckpointCommandRun)
+	return nil
+}
+
+// runCommandAndTransforms runs the command in the supplied directory of the clone of the repo of the supplied job, and
+// then the transforms of any plugins
+func runCommandAndTransforms(job *repoJob, dir string) error {
+	var commandErr error
+	// Files passed via --move-file are moved in place of the command
+	if len(job.config.FileMoves) > 0 {
+		commandErr = moveFiles(job, dir)
+	} else {
+		commandErr = executeCommand(job.config, dir, job.repo)
+	}
 	if commandErr != nil {
 		return commandErr
 	}
 
-	// Commit and push the changes to Git and open a PR
-	if err := updateRepo(config, repositoryDir, worktree, repo, localRepository, branchName.String()); err != nil {
+	// If --plugin loaded any transforms, let them change the repo further before the changes are committed
+	return job.config.Plugins.Transform(job.config.Context, plugins.TransformRequest{
+		RunID:      job.config.RunID,
+		Repo:       job.repo.GetOwner().GetLogin() + "/" + job.repo.GetName(),
+		BranchName: job.branchName,
+		Dir:        dir,
+		TrackEvent: pluginEventTracker(job.config, job.repo),
+	})
+}
+
+// pushStage commits the changes the command made to the repo of the supplied job, and pushes them to its branch
+func pushStage(job *repoJob) error {
+	if len(job.monorepoCommits) > 0 {
+		return pushMonorepoChanges(job)
+	}
+
+	pendingPullRequests, err := pushRepoChanges(job.config, job.repositoryDir, job.worktree, job.repo, job.localRepository, job.branchName)
+	if err != nil {
+		return err
+	}
+	job.pendingPullRequests = pendingPullRequests
+	return nil
+}
+
+// pullRequestStage opens the pull requests for the branches pushed for the repo of the supplied job, if any are left to
+// open
+func pullRequestStage(job *repoJob) error {
+	if err := openPendingPullRequests(job.config, job.repo, job.localRepository, job.pendingPullRequests); err != nil {
 		return err
 	}
 
+	logger := logging.GetLogger("git-xargs")
 	logger.WithFields(logrus.Fields{
-		"Repo name": repo.GetName(),
+		"Repo name": job.repo.GetName(),
 	}).Info("Repository successfully processed")
 
 	return nil
 }
+
+// runCancelled returns true if the context of the run was cancelled, either because git-xargs was interrupted or because
+// a run started by git-xargs serve was cancelled via the API
+func runCancelled(config *config.GitXargsConfig) bool {
+	return config.Context.Err() != nil
+}
+
+// removeCancelledClone removes the local clone of a repo once the run is cancelled, since its changes will never be
+// pushed. Clones of runs that weren't cancelled are left in place, so that they can be inspected while debugging
+func removeCancelledClone(config *config.GitXargsConfig, repositoryDir string, repo *github.Repository) {
+	if repositoryDir == "" || !runCancelled(config) {
+		return
+	}
+
+	if err := os.RemoveAll(repositoryDir); err != nil {
+		logger := logging.GetLogger("git-xargs")
+		logger.WithFields(logrus.Fields{
+			"Repo name": repo.GetName(),
+			"Directory": repositoryDir,
+			"Error":     err,
+		}).Debug("Error removing the clone of a cancelled repo")
+	}
+}
+
+// logStateErr logs errors recording the outcome of a repo in the state store. They don't fail the repo, since the
+// changes themselves were made successfully
+func logStateErr(err error, repo *github.Repository) {
+	if err == nil {
+		return
+	}
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(), "Error": err,
+	}).Warn("Error recording repo in the state store")
+}
+
+// logAuditErr logs an error recording a repo in the --audit-log-dir audit log. Like the state store, the audit log
+// doesn't fail the repo, but since auditors rely on it, its errors are logged as errors and returned once the run ends
+func logAuditErr(err error, repo *github.Repository) {
+	if err == nil {
+		return
+	}
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(), "Error": err,
+	}).Error("Error recording repo in the audit log")
+}
@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
 	"os/exec"
 	"testing"
 
+	"github.com/google/go-github/v32/github"
 	"github.com/gruntwork-io/git-xargs/config"
 	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/gruntwork-io/git-xargs/util"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestProcessRepo smoke tests the processRepo function with a basic test config - however, the MockGitProvider implemented
@@ -52,3 +57,60 @@ func cleanupLocalTestRepoChanges(t *testing.T, config *config.GitXargsConfig) {
 		t.Log("cleanupLocalTestRepoChanges successfully deleted branches in local test repo")
 	}
 }
+
+// TestProcessRepoSkipsReposWithOpenPullRequests ensures a repo with an open pull request from the branch is skipped
+// before it is cloned when --skip-repos-with-open-pull-requests is set
+func TestProcessRepoSkipsReposWithOpenPullRequests(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Args = []string{"touch", util.NewTestFileName()}
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.SkipReposWithOpenPRs = true
+
+	processErr := processRepo(testConfig, mocks.GetMockGithubRepo())
+	assert.NoError(t, processErr)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.PullRequestAlreadyOpenSkipped), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.RepoSuccessfullyCloned))
+}
+
+// Test that once the context of the run is cancelled, e.g. because git-xargs was interrupted, no more repos are started
+func TestProcessReposSkipsReposOnceCancelled(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Args = []string{"touch", util.NewTestFileName()}
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	ctx, cancel := context.WithCancel(testConfig.Context)
+	cancel()
+	testConfig.Context = ctx
+
+	processErr := ProcessRepos(testConfig, mocks.MockGithubRepositories)
+	assert.NoError(t, processErr)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.RunCancelledSkipped), len(mocks.MockGithubRepositories))
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.RepoSuccessfullyCloned))
+}
+
+// Test that repos on a GitHub host no client was configured for fail, rather than being processed with the client of
+// github.com
+func TestProcessReposFailsReposOnUnknownGithubHost(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.Args = []string{"touch", util.NewTestFileName()}
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	enterpriseRepo := &github.Repository{
+		Owner:   &github.User{Login: github.String("platform")},
+		Name:    github.String("api"),
+		HTMLURL: github.String("https://github.example.com/platform/api"),
+	}
+
+	processErr := ProcessRepos(testConfig, []*github.Repository{enterpriseRepo})
+	require.Error(t, processErr)
+	assert.Equal(t, map[string]string{
+		"github.example.com/platform/api": types.UnknownGithubHostErr{Host: "github.example.com"}.Error(),
+	}, testConfig.Stats.GetErrors())
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.RepoSuccessfullyCloned))
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const projectLookupQuery = `query($org: String!, $number: Int!) {
+  organization(login: $org) {
+    projectV2(number: $number) {
+      id
+      fields(first: 100) {
+        nodes {
+          ... on ProjectV2Field {
+            id
+            name
+            dataType
+          }
+          ... on ProjectV2SingleSelectField {
+            id
+            name
+            dataType
+            options {
+              id
+              name
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const addProjectItemMutation = `mutation($projectId: ID!, $contentId: ID!) {
+  addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
+    item {
+      id
+    }
+  }
+}`
+
+const updateProjectItemFieldMutation = `mutation($projectId: ID!, $itemId: ID!, $fieldId: ID!, $value: ProjectV2FieldValue!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $projectId, itemId: $itemId, fieldId: $fieldId, value: $value}) {
+    projectV2Item {
+      id
+    }
+  }
+}`
+
+type projectLookupResponse struct {
+	Organization struct {
+		ProjectV2 *struct {
+			ID     string `json:"id"`
+			Fields struct {
+				Nodes []struct {
+					ID       string `json:"id"`
+					Name     string `json:"name"`
+					DataType string `json:"dataType"`
+					Options  []struct {
+						ID   string `json:"id"`
+						Name string `json:"name"`
+					} `json:"options"`
+				} `json:"nodes"`
+			} `json:"fields"`
+		} `json:"projectV2"`
+	} `json:"organization"`
+}
+
+type addProjectItemResponse struct {
+	AddProjectV2ItemByID struct {
+		Item struct {
+			ID string `json:"id"`
+		} `json:"item"`
+	} `json:"addProjectV2ItemById"`
+}
+
+// ParseProjectFlag splits the value of the --project flag, in the format of <github-organization>/<project-number>,
+// into its organization and project number
+func ParseProjectFlag(project string) (string, int, error) {
+	parts := strings.Split(project, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, errors.WithStackTrace(types.InvalidProjectFlagErr{Project: project})
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil || number < 1 {
+		return "", 0, errors.WithStackTrace(types.InvalidProjectFlagErr{Project: project})
+	}
+	return parts[0], number, nil
+}
+
+// ParseProjectFieldFlag splits the value of a --project-field flag, in the format of <field-name>=<value>, into its
+// field name and value
+func ParseProjectFieldFlag(field string) (string, string, error) {
+	parts := strings.SplitN(field, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", errors.WithStackTrace(types.InvalidProjectFieldFlagErr{Field: field})
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+// ResolveProject looks up the Projects (v2) board supplied via --project once, before any repos are processed, and
+// converts each --project-field into the GraphQL value that will be set on every pull request added to the board. This
+// way a typo in a field name or option fails the run up front, instead of once per repo
+func ResolveProject(config *config.GitXargsConfig) error {
+	if config.Project == "" {
+		return nil
+	}
+
+	org, number, err := ParseProjectFlag(config.Project)
+	if err != nil {
+		return err
+	}
+
+	var resp projectLookupResponse
+	variables := map[string]interface{}{"org": org, "number": number}
+	if err := config.GithubClient.GraphQL.Do(config.Context, projectLookupQuery, variables, &resp); err != nil {
+		return err
+	}
+
+	project := resp.Organization.ProjectV2
+	if project == nil || project.ID == "" {
+		return errors.WithStackTrace(types.ProjectNotFoundErr{Project: config.Project})
+	}
+
+	resolved := &types.ProjectV2{ID: project.ID}
+
+	for _, fieldFlag := range config.ProjectFieldValues {
+		name, value, err := ParseProjectFieldFlag(fieldFlag)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, field := range project.Fields.Nodes {
+			if !strings.EqualFold(field.Name, name) {
+				continue
+			}
+			found = true
+
+			fieldValue := types.ProjectV2FieldValue{FieldID: field.ID}
+
+			switch field.DataType {
+			case "TEXT":
+				fieldValue.Value = map[string]interface{}{"text": value}
+			case "DATE":
+				fieldValue.Value = map[string]interface{}{"date": value}
+			case "NUMBER":
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return errors.WithStackTrace(types.InvalidProjectFieldValueErr{Field: name, Value: value})
+				}
+				fieldValue.Value = map[string]interface{}{"number": number}
+			case "SINGLE_SELECT":
+				for _, option := range field.Options {
+					if strings.EqualFold(option.Name, value) {
+						fieldValue.Value = map[string]interface{}{"singleSelectOptionId": option.ID}
+					}
+				}
+				if fieldValue.Value == nil {
+					return errors.WithStackTrace(types.InvalidProjectFieldValueErr{Field: name, Value: value})
+				}
+			default:
+				return errors.WithStackTrace(types.InvalidProjectFieldValueErr{Field: name, Value: value})
+			}
+
+			resolved.FieldValues = append(resolved.FieldValues, fieldValue)
+		}
+
+		if !found {
+			return errors.WithStackTrace(types.ProjectFieldNotFoundErr{Project: config.Project, Field: name})
+		}
+	}
+
+	config.ResolvedProject = resolved
+
+	return nil
+}
+
+// addPullRequestToProject adds the supplied pull request to the Projects (v2) board resolved from --project and sets
+// any --project-field values on the new item. Failures are tracked, but don't fail the repo, since the pull request
+// itself was opened successfully
+func addPullRequestToProject(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	if config.ResolvedProject == nil {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	var resp addProjectItemResponse
+	variables := map[string]interface{}{
+		"projectId": config.ResolvedProject.ID,
+		"contentId": pr.GetNodeID(),
+	}
+	if err := config.GithubClient.GraphQL.Do(config.Context, addProjectItemMutation, variables, &resp); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error adding pull request to project")
+
+		config.Stats.TrackSingle(stats.PullRequestAddToProjectErr, repo)
+		return
+	}
+
+	for _, fieldValue := range config.ResolvedProject.FieldValues {
+		variables := map[string]interface{}{
+			"projectId": config.ResolvedProject.ID,
+			"itemId":    resp.AddProjectV2ItemByID.Item.ID,
+			"fieldId":   fieldValue.FieldID,
+			"value":     fieldValue.Value,
+		}
+		if err := config.GithubClient.GraphQL.Do(config.Context, updateProjectItemFieldMutation, variables, nil); err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": pr.GetHTMLURL(),
+			}).Debug("Error setting project field value on pull request")
+
+			config.Stats.TrackSingle(stats.PullRequestAddToProjectErr, repo)
+			return
+		}
+	}
+
+	config.Stats.TrackSingle(stats.PullRequestAddedToProject, repo)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockProjectLookupResponse = `{
+  "organization": {
+    "projectV2": {
+      "id": "PVT_1",
+      "fields": {
+        "nodes": [
+          {"id": "F_TEXT", "name": "Campaign", "dataType": "TEXT"},
+          {"id": "F_STATUS", "name": "Status", "dataType": "SINGLE_SELECT", "options": [{"id": "OPT_1", "name": "In Progress"}]}
+        ]
+      }
+    }
+  }
+}`
+
+// TestParseProjectFlag ensures only <org>/<number> values are accepted for --project
+func TestParseProjectFlag(t *testing.T) {
+	t.Parallel()
+
+	org, number, err := ParseProjectFlag("gruntwork-io/12")
+	require.NoError(t, err)
+	assert.Equal(t, "gruntwork-io", org)
+	assert.Equal(t, 12, number)
+
+	for _, bad := range []string{"gruntwork-io", "gruntwork-io/abc", "/12", "gruntwork-io/0"} {
+		_, _, err := ParseProjectFlag(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+// TestResolveProject ensures --project-field values are converted to the GraphQL value for each field's data type
+func TestResolveProject(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.GithubClient.GraphQL = mocks.MockGithubGraphQLService{Response: mockProjectLookupResponse}
+	testConfig.Project = "gruntwork-io/12"
+	testConfig.ProjectFieldValues = []string{"Campaign=ci-upgrade", "status=in progress"}
+
+	require.NoError(t, ResolveProject(testConfig))
+	require.NotNil(t, testConfig.ResolvedProject)
+	assert.Equal(t, "PVT_1", testConfig.ResolvedProject.ID)
+	require.Len(t, testConfig.ResolvedProject.FieldValues, 2)
+	assert.Equal(t, "ci-upgrade", testConfig.ResolvedProject.FieldValues[0].Value["text"])
+	assert.Equal(t, "OPT_1", testConfig.ResolvedProject.FieldValues[1].Value["singleSelectOptionId"])
+
+	testConfig.ProjectFieldValues = []string{"Status=Done"}
+	assert.Error(t, ResolveProject(testConfig))
+
+	testConfig.ProjectFieldValues = []string{"Owner=me"}
+	assert.Error(t, ResolveProject(testConfig))
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// estimateAPICallsPerRepo returns roughly how many calls to the GitHub REST API processing a single repo takes with the
+// supplied config, and whether that is only a lower bound, since some calls depend on the changes the command makes.
+// Cloning and pushing go through git rather than the API, so they don't count
+func estimateAPICallsPerRepo(config *config.GitXargsConfig) (int, bool) {
+	calls := 0
+	lowerBound := false
+
+	// Looking up open pull requests from the branch before cloning
+	if config.SkipReposWithOpenPRs {
+		calls++
+	}
+	// Looking up the branch protection, required signatures and rulesets of the base branch
+	if config.CheckBranchProtection {
+		calls += 3
+	}
+	if config.CommitStatus && !config.DryRun {
+		calls++
+	}
+	if config.DryRun || config.SkipPullRequests || config.DryRunLevel == common.DryRunPushNoPR {
+		return calls, lowerBound
+	}
+
+	// Looking up an existing pull request for the branch, then opening one
+	calls += 2
+	if config.DraftIfChecksPending {
+		// The combined status and the check runs of the pushed branch
+		calls += 2
+	}
+	if !config.ReviewerPool.IsEmpty() || config.ReviewersFromBlame {
+		calls++
+	}
+	if config.ReviewersFromBlame {
+		// Looking up the author of each commit the changed lines are blamed on, which takes at least one call
+		calls++
+		lowerBound = true
+	}
+	if !config.AssigneePool.IsEmpty() {
+		calls++
+	}
+	if !config.SkipRunMarkers {
+		calls++
+	}
+	return calls, lowerBound
+}
+
+// checkRateLimitBudget estimates the GitHub API calls needed to process the supplied repos, logs it against what is left
+// of the core rate limit, and warns if the run doesn't fit in the current rate limit window. Such runs still complete,
+// since API calls are paused until the window resets, but they take longer. Failing to look up the rate limit is only
+// logged, since the estimate is advisory
+func checkRateLimitBudget(config *config.GitXargsConfig, repos []*github.Repository) {
+	logger := logging.GetLogger("git-xargs")
+
+	if config.GithubClient.RateLimits == nil {
+		return
+	}
+	limits, _, err := config.GithubClient.RateLimits.RateLimits(config.Context)
+	if err != nil || limits.GetCore() == nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+		}).Debug("Error looking up the GitHub API rate limit, so not estimating the API budget of the run")
+		return
+	}
+	core := limits.GetCore()
+
+	perRepo, lowerBound := estimateAPICallsPerRepo(config)
+	estimated := perRepo * len(repos)
+	fields := logrus.Fields{
+		"Repos":                  len(repos),
+		"Estimated API calls":    estimated,
+		"API calls made so far":  config.GithubClient.APICalls.Count(),
+		"Rate limit remaining":   core.Remaining,
+		"Rate limit":             core.Limit,
+		"Rate limit window ends": core.Reset.Time.Format(time.RFC3339),
+	}
+	if lowerBound {
+		fields["Estimate"] = "a lower bound, since --reviewers-from-blame looks up every commit the changes are blamed on"
+	}
+	logger.WithFields(fields).Info("GitHub API budget of the run")
+
+	if estimated <= core.Remaining {
+		return
+	}
+	fields = logrus.Fields{
+		"Estimated API calls":    estimated,
+		"Rate limit remaining":   core.Remaining,
+		"Rate limit window ends": core.Reset.Time.Format(time.RFC3339),
+	}
+	if windows, ok := rateLimitWindowsNeeded(estimated, core.Remaining, core.Limit); ok {
+		fields["Rate limit windows"] = windows
+	}
+	logger.WithFields(fields).Warn("The run needs more GitHub API calls than are left in the current rate limit window. API calls will pause until each window ends, so the run will take longer. Consider splitting the repos into batches")
+}
+
+// rateLimitWindowsNeeded returns how many rate limit windows, counting the current one, the supplied number of API
+// calls takes, given the calls remaining in the current window and the limit of each later one. Without a limit, calls
+// that don't fit in the current window never fit, so it returns false
+func rateLimitWindowsNeeded(calls, remaining, limit int) (int, bool) {
+	if calls <= remaining {
+		return 1, true
+	}
+	if limit <= 0 {
+		return 0, false
+	}
+	return 1 + (calls-remaining+limit-1)/limit, true
+}
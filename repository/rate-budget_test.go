@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateAPICallsPerRepo(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	assertEstimate(t, testConfig, 3, false)
+
+	testConfig.SkipReposWithOpenPRs = true
+	testConfig.ReviewerPool = reviewers.NewPool([]string{"alice", "bob"}, reviewers.StrategyRoundRobin, 1)
+	assertEstimate(t, testConfig, 5, false)
+
+	testConfig.CheckBranchProtection = true
+	assertEstimate(t, testConfig, 8, false)
+
+	// Every blamed commit is looked up, so the estimate is only a lower bound
+	testConfig.ReviewersFromBlame = true
+	assertEstimate(t, testConfig, 9, true)
+
+	testConfig.ApplyDryRunLevel(common.DryRunCommitNoPush)
+	assertEstimate(t, testConfig, 4, false)
+}
+
+func assertEstimate(t *testing.T, testConfig *config.GitXargsConfig, expectedCalls int, expectedLowerBound bool) {
+	calls, lowerBound := estimateAPICallsPerRepo(testConfig)
+	assert.Equal(t, expectedCalls, calls)
+	assert.Equal(t, expectedLowerBound, lowerBound)
+}
+
+func TestRateLimitWindowsNeeded(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		calls, remaining, limit int
+		expectedWindows         int
+		expectedOk              bool
+	}{
+		{100, 4000, 5000, 1, true},
+		{5000, 4000, 5000, 2, true},
+		{12000, 4000, 5000, 3, true},
+		{100, 100, 0, 1, true},
+		{100, 0, 0, 0, false},
+	}
+	for _, testCase := range testCases {
+		windows, ok := rateLimitWindowsNeeded(testCase.calls, testCase.remaining, testCase.limit)
+		assert.Equal(t, testCase.expectedWindows, windows)
+		assert.Equal(t, testCase.expectedOk, ok)
+	}
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+)
+
+// repoBaseBranchKey returns the key of the supplied repo in the per-repo base branches of the config, which is the
+// case-insensitive full name of the repo, prefixed with its host if it's on a GitHub Enterprise Server
+func repoBaseBranchKey(host string, owner string, name string) string {
+	key := owner + "/" + name
+	if host != "" {
+		key = host + "/" + key
+	}
+	return strings.ToLower(key)
+}
+
+// recordRepoBaseBranches records the branches supplied with an @ suffix, e.g. org/repo@release-1.x, on the supplied
+// repos in the config, so that each of those repos is cloned at, and has its pull request opened against, its own branch
+func recordRepoBaseBranches(config *config.GitXargsConfig, allowedRepos []*types.AllowedRepo) {
+	for _, allowedRepo := range allowedRepos {
+		if allowedRepo.Branch == "" {
+			continue
+		}
+		if config.RepoBaseBranches == nil {
+			config.RepoBaseBranches = map[string]string{}
+		}
+		config.RepoBaseBranches[repoBaseBranchKey(allowedRepo.Host, allowedRepo.Organization, allowedRepo.Name)] = allowedRepo.Branch
+	}
+}
+
+// repoBaseBranch returns the branch supplied for the supplied repo with an @ suffix, or an empty string if none was
+func repoBaseBranch(config *config.GitXargsConfig, repo *github.Repository) string {
+	return config.RepoBaseBranches[repoBaseBranchKey(util.RepoHost(repo), repo.GetOwner().GetLogin(), repo.GetName())]
+}
+
+// withRepoBaseBranch returns a copy of the supplied config with the branch supplied for the supplied repo with an @ suffix
+// as its base branch, which takes precedence over --base-branch-name. Repos without one keep the supplied config
+func withRepoBaseBranch(gitxargsConfig *config.GitXargsConfig, repo *github.Repository) *config.GitXargsConfig {
+	branch := repoBaseBranch(gitxargsConfig, repo)
+	if branch == "" {
+		return gitxargsConfig
+	}
+
+	repoConfig := *gitxargsConfig
+	repoConfig.BaseBranchName = branch
+	return &repoConfig
+}
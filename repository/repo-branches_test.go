@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that repos passed with an @ suffix carry the branch to target, which may itself contain slashes
+func TestConvertStringToAllowedRepoWithBranch(t *testing.T) {
+	t.Parallel()
+
+	repo := util.ConvertStringToAllowedRepo("gruntwork-io/terragrunt@release-1.x")
+	require.NotNil(t, repo)
+	assert.Equal(t, "gruntwork-io", repo.Organization)
+	assert.Equal(t, "terragrunt", repo.Name)
+	assert.Equal(t, "release-1.x", repo.Branch)
+
+	repo = util.ConvertStringToAllowedRepo("github.example.com/platform/api@release/2.3")
+	require.NotNil(t, repo)
+	assert.Equal(t, "github.example.com", repo.Host)
+	assert.Equal(t, "api", repo.Name)
+	assert.Equal(t, "release/2.3", repo.Branch)
+
+	repo = util.ConvertStringToAllowedRepo("gruntwork-io/fetch")
+	require.NotNil(t, repo)
+	assert.Equal(t, "", repo.Branch)
+
+	assert.Nil(t, util.ConvertStringToAllowedRepo("gruntwork-io/fetch@"))
+}
+
+// Test that repos supplied with a branch get it as their base branch, while other repos keep the one of the run
+func TestWithRepoBaseBranch(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.BaseBranchName = "main"
+	recordRepoBaseBranches(testConfig, []*types.AllowedRepo{
+		{Organization: "Gruntwork-IO", Name: "Terragrunt", Branch: "release-1.x"},
+		{Organization: "gruntwork-io", Name: "fetch"},
+	})
+
+	repoConfig := withRepoBaseBranch(testConfig, mocks.GetMockGithubRepo())
+	assert.Equal(t, "release-1.x", repoConfig.BaseBranchName)
+	assert.Equal(t, "main", testConfig.BaseBranchName)
+
+	otherRepo := &github.Repository{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("fetch")}
+	assert.Same(t, testConfig, withRepoBaseBranch(testConfig, otherRepo))
+}
+
+// Test that a branch supplied with an @ suffix takes precedence over the base branch of the repo's .git-xargs.yml
+func TestApplyRepoConfigKeepsRepoBaseBranch(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newRepoConfigJob(t, "base-branch-name: develop\n")
+	defer cleanup()
+	recordRepoBaseBranches(job.config, []*types.AllowedRepo{{Organization: "gruntwork-io", Name: "git-xargs", Branch: "release-1.x"}})
+	job.config = withRepoBaseBranch(job.config, job.repo)
+
+	_, err := applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.Equal(t, "release-1.x", job.config.BaseBranchName)
+}
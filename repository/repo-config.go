@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// repoConfigFile is the contents of the .git-xargs.yml file committed to a repo, with which its owners customize how
+// the changes of git-xargs land in it. Each key overrides the flag of the same name for that repo only
+type repoConfigFile struct {
+	BranchName     string   `yaml:"branch-name"`
+	BaseBranchName string   `yaml:"base-branch-name"`
+	Reviewers      []string `yaml:"reviewers"`
+	// OptOutTags are the --tag values of the campaigns the repo doesn't want changes from
+	OptOutTags []string `yaml:"opt-out-tags"`
+}
+
+// loadRepoConfig reads the .git-xargs.yml file in the supplied clone of a repo, returning nil if it has none. Keys that
+// aren't supported are an error, so that repo owners find out about their typos
+func loadRepoConfig(repositoryDir string) (*repoConfigFile, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(repositoryDir, common.RepoConfigFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	repoConfig := &repoConfigFile{}
+	if err := yaml.UnmarshalStrict(contents, repoConfig); err != nil {
+		return nil, errors.WithStackTrace(types.InvalidRepoConfigErr{Err: err})
+	}
+	return repoConfig, nil
+}
+
+// applyRepoConfig applies the .git-xargs.yml file in the clone of the repo of the supplied job, if it has one, to a copy
+// of the config of the job, so that its overrides only affect that repo, unless --ignore-repo-config was passed. It
+// returns true if the repo opted out of the run, via one of the --tag values of the run
+func applyRepoConfig(job *repoJob) (bool, error) {
+	config, repo := job.config, job.repo
+	if config.IgnoreRepoConfig {
+		return false, nil
+	}
+
+	repoConfig, err := loadRepoConfig(job.repositoryDir)
+	if err != nil {
+		config.Stats.TrackSingle(stats.RepoConfigInvalid, repo)
+		return false, err
+	}
+	if repoConfig == nil {
+		return false, nil
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	if tag := optedOutTag(config.Tags, repoConfig.OptOutTags); tag != "" {
+		logger.WithFields(logrus.Fields{
+			"Repo name": repo.GetName(),
+			"Tag":       tag,
+		}).Info("Skipping repo because it opted out of campaigns with this tag in its " + common.RepoConfigFileName)
+
+		config.Stats.TrackSingle(stats.RepoConfigOptedOutSkipped, repo)
+		return true, nil
+	}
+
+	repoJobConfig, err := overrideConfig(config, repo, repoConfig)
+	if err != nil {
+		config.Stats.TrackSingle(stats.RepoConfigInvalid, repo)
+		return false, err
+	}
+	job.config = repoJobConfig
+
+	logger.WithFields(logrus.Fields{
+		"Repo name":   repo.GetName(),
+		"Branch":      repoJobConfig.BranchName,
+		"Base branch": repoJobConfig.BaseBranchName,
+	}).Debug("Applied the overrides of the repo's " + common.RepoConfigFileName)
+	return false, nil
+}
+
+// overrideConfig returns a copy of the supplied config with the overrides of the supplied .git-xargs.yml applied. The
+// branch name is a template, like --branch-name
+func overrideConfig(gitxargsConfig *config.GitXargsConfig, repo *github.Repository, repoConfig *repoConfigFile) (*config.GitXargsConfig, error) {
+	repoJobConfig := *gitxargsConfig
+
+	if repoConfig.BranchName != "" {
+		branchName, err := util.RenderTemplate(repoConfig.BranchName, newTemplateData(gitxargsConfig, repo))
+		if err != nil {
+			return nil, errors.WithStackTrace(types.InvalidRepoConfigErr{Err: err})
+		}
+		repoJobConfig.BranchName = branchName
+	}
+	// A branch supplied with an @ suffix on the command line takes precedence, since the repo was cloned at it
+	if repoConfig.BaseBranchName != "" && repoBaseBranch(gitxargsConfig, repo) == "" {
+		repoJobConfig.BaseBranchName = repoConfig.BaseBranchName
+	}
+	if len(repoConfig.Reviewers) > 0 {
+		repoJobConfig.Reviewers = repoConfig.Reviewers
+		repoJobConfig.ReviewerPool = reviewers.NewPool(reviewers.Exclude(repoConfig.Reviewers, gitxargsConfig.ExcludeReviewers), gitxargsConfig.ReviewerStrategy, gitxargsConfig.ReviewersPerPR)
+	}
+	return &repoJobConfig, nil
+}
+
+// optedOutTag returns the first of the supplied tags of the run that the repo opted out of, or an empty string if it
+// opted out of none of them
+func optedOutTag(tags []string, optOutTags []string) string {
+	for _, tag := range tags {
+		for _, optOutTag := range optOutTags {
+			if tag == optOutTag {
+				return tag
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRepoConfigJob returns a job for a clone of a repo whose .git-xargs.yml holds the supplied contents, along with a
+// function that removes the clone
+func newRepoConfigJob(t *testing.T, contents string) (*repoJob, func()) {
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-repo-config")
+	require.NoError(t, err)
+	if contents != "" {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, common.RepoConfigFileName), []byte(contents), 0644))
+	}
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.BranchName = "upgrade-ci"
+	testConfig.RunID = "run-1"
+	testConfig.Tags = []string{"ci", "security"}
+
+	job := &repoJob{
+		repo:          &github.Repository{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("git-xargs")},
+		config:        testConfig,
+		repositoryDir: repositoryDir,
+	}
+	return job, func() { os.RemoveAll(repositoryDir) }
+}
+
+func TestApplyRepoConfigOverridesConfigForRepo(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newRepoConfigJob(t, "branch-name: fleet/{{.RunID}}\nbase-branch-name: develop\nreviewers: [gruntwork-io/platform]\n")
+	defer cleanup()
+	campaignConfig := job.config
+
+	optedOut, err := applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.False(t, optedOut)
+
+	assert.Equal(t, "fleet/run-1", job.config.BranchName)
+	assert.Equal(t, "develop", job.config.BaseBranchName)
+	assert.Equal(t, []string{"gruntwork-io/platform"}, job.config.Reviewers)
+	assert.False(t, job.config.ReviewerPool.IsEmpty())
+
+	// The overrides only apply to this repo
+	assert.Equal(t, "upgrade-ci", campaignConfig.BranchName)
+	assert.Equal(t, "", campaignConfig.BaseBranchName)
+}
+
+func TestApplyRepoConfigOptsOutOfTaggedRuns(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newRepoConfigJob(t, "opt-out-tags: [security]\n")
+	defer cleanup()
+
+	optedOut, err := applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.True(t, optedOut)
+	assert.Len(t, job.config.Stats.GetMultiple(stats.RepoConfigOptedOutSkipped), 1)
+
+	// Runs without the tag aren't opted out of
+	job.config.Tags = []string{"ci"}
+	optedOut, err = applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.False(t, optedOut)
+}
+
+func TestApplyRepoConfigRejectsUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newRepoConfigJob(t, "branch_name: typo\n")
+	defer cleanup()
+
+	_, err := applyRepoConfig(job)
+	assert.IsType(t, types.InvalidRepoConfigErr{}, errors.Unwrap(err))
+	assert.Len(t, job.config.Stats.GetMultiple(stats.RepoConfigInvalid), 1)
+}
+
+func TestApplyRepoConfigIgnoresMissingOrIgnoredFile(t *testing.T) {
+	t.Parallel()
+
+	job, cleanup := newRepoConfigJob(t, "")
+	defer cleanup()
+	campaignConfig := job.config
+
+	optedOut, err := applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.False(t, optedOut)
+	assert.Equal(t, campaignConfig, job.config)
+
+	job, cleanup = newRepoConfigJob(t, "opt-out-tags: [ci]\n")
+	defer cleanup()
+	job.config.IgnoreRepoConfig = true
+
+	optedOut, err = applyRepoConfig(job)
+	require.NoError(t, err)
+	assert.False(t, optedOut)
+}
@@ -2,26 +2,30 @@ package repository
 
 import (
 	"bytes"
-	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/sirupsen/logrus"
 
 	"github.com/google/go-github/v32/github"
 
 	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/events"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
 	"github.com/gruntwork-io/git-xargs/stats"
 	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
 	"github.com/gruntwork-io/go-commons/errors"
-	"github.com/gruntwork-io/go-commons/logging"
 )
 
 // cloneLocalRepository clones a remote GitHub repo via SSH to a local temporary directory so that the supplied command
@@ -29,10 +33,11 @@ import (
 // git-xargs-<repo-name> appended to it to make it easier to find when you are looking for it while debugging
 func cloneLocalRepository(config *config.GitXargsConfig, repo *github.Repository) (string, *git.Repository, error) {
 	logger := logging.GetLogger("git-xargs")
+	defer startPhase(config, repo, types.PhaseClone)()
 
 	logger.WithFields(logrus.Fields{
 		"Repo": repo.GetName(),
-	}).Debug("Attempting to clone repository using GITHUB_OAUTH_TOKEN")
+	}).Debug("Attempting to clone repository using the GitHub token")
 
 	repositoryDir, tmpDirErr := ioutil.TempDir("", fmt.Sprintf("git-xargs-%s", repo.GetName()))
 	if tmpDirErr != nil {
@@ -43,15 +48,28 @@ func cloneLocalRepository(config *config.GitXargsConfig, repo *github.Repository
 		return repositoryDir, nil, errors.WithStackTrace(tmpDirErr)
 	}
 
+	cloneURL, auth, err := gitRemote(config, repo)
+	if err != nil {
+		config.Stats.TrackSingle(stats.RepoFailedToClone, repo)
+		return repositoryDir, nil, err
+	}
+
 	gitProgressBuffer := bytes.NewBuffer(nil)
-	localRepository, err := config.GitClient.PlainClone(repositoryDir, false, &git.CloneOptions{
-		URL:      repo.GetCloneURL(),
+	cloneOptions := &git.CloneOptions{
+		URL:      cloneURL,
 		Progress: gitProgressBuffer,
-		Auth: &http.BasicAuth{
-			Username: repo.GetOwner().GetLogin(),
-			Password: os.Getenv("GITHUB_OAUTH_TOKEN"),
-		},
-	})
+		Auth:     auth,
+	}
+	// With --push-via-api nothing is pushed through git, so the history of the repo isn't needed, which makes the clones
+	// of large repos much smaller
+	if pushesViaAPI(config, repo) {
+		cloneOptions.Depth = 1
+	}
+	// A repo supplied as org/repo@branch is cloned at that branch, so that its work branch starts from it
+	if branch := repoBaseBranch(config, repo); branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	localRepository, err := config.GitClient.PlainClone(config.Context, repositoryDir, false, cloneOptions)
 
 	logger.WithFields(logrus.Fields{
 		"Repo": repo.GetName(),
@@ -70,6 +88,7 @@ func cloneLocalRepository(config *config.GitXargsConfig, repo *github.Repository
 	}
 
 	config.Stats.TrackSingle(stats.RepoSuccessfullyCloned, repo)
+	config.Events.Repo(events.RepoCloned, repo)
 
 	return repositoryDir, localRepository, nil
 }
@@ -104,11 +123,16 @@ func executeCommandWithLogger(config *config.GitXargsConfig, repositoryDir strin
 	if len(config.Args) < 1 {
 		return errors.WithStackTrace(types.NoCommandSuppliedErr{})
 	}
+	defer startPhase(config, repo, types.PhaseCommand)()
 
 	cmdArgs := config.Args
 
-	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+	cmd := exec.CommandContext(config.Context, cmdArgs[0], cmdArgs[1:]...)
 	cmd.Dir = repositoryDir
+	// The variables of the env files of the repo, if any, are added to the environment of git-xargs, and win over it
+	if len(config.CommandEnv) > 0 {
+		cmd.Env = append(os.Environ(), config.CommandEnv...)
+	}
 
 	logger.WithFields(logrus.Fields{
 		"Repo":      repo.GetName(),
@@ -126,9 +150,11 @@ func executeCommandWithLogger(config *config.GitXargsConfig, repositoryDir strin
 		}).Debug("Error getting output of command execution")
 		// Track the command error against the repo
 		config.Stats.TrackSingle(stats.CommandErrorOccurredDuringExecution, repo)
+		config.Events.RepoError(events.CommandFailed, repo, err)
 		return errors.WithStackTrace(err)
 	}
 
+	config.Events.Repo(events.CommandSucceeded, repo)
 	return nil
 }
 
@@ -185,15 +211,20 @@ func checkoutLocalBranch(config *config.GitXargsConfig, ref *plumbing.Reference,
 	}
 
 	// Pull latest code from remote branch if it exists to avoid fast-forwarding errors
+	_, auth, err := gitRemote(config, remoteRepository)
+	if err != nil {
+		return branchName, err
+	}
+
 	gitProgressBuffer := bytes.NewBuffer(nil)
 	po := &git.PullOptions{
 		RemoteName:    "origin",
 		ReferenceName: branchName,
-		Auth: &http.BasicAuth{
-			Username: remoteRepository.GetOwner().GetLogin(),
-			Password: os.Getenv("GITHUB_OAUTH_TOKEN"),
-		},
-		Progress: gitProgressBuffer,
+		Auth:          auth,
+		Progress:      gitProgressBuffer,
+	}
+	if pushesViaAPI(config, remoteRepository) {
+		po.Depth = 1
 	}
 
 	logger.WithFields(logrus.Fields{
@@ -224,6 +255,30 @@ func checkoutLocalBranch(config *config.GitXargsConfig, ref *plumbing.Reference,
 // add any untracked, deleted or modified files, create a commit using the supplied or default commit message,
 // push the code to the remote repo, and open a pull request.
 func updateRepo(config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository, branchName string) error {
+	pendingPullRequests, err := pushRepoChanges(config, repositoryDir, worktree, remoteRepository, localRepository, branchName)
+	if err != nil {
+		return err
+	}
+
+	// Open a pull request on GitHub, of the recently pushed branch against the repository default branch
+	return openPendingPullRequests(config, remoteRepository, localRepository, pendingPullRequests)
+}
+
+// openPendingPullRequests opens the pull requests of the supplied pushed branches of the supplied repo, in order
+func openPendingPullRequests(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, pendingPullRequests []pendingPullRequest) error {
+	for _, pending := range pendingPullRequests {
+		if err := openPullRequest(config, remoteRepository, localRepository, pending.commitHash, pending.branchName, pending.part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushRepoChanges commits the changes the command made to the supplied repo and pushes them to the supplied branch. It
+// returns the pushed branches whose pull requests are left to open: the supplied branch, or one branch per part if the
+// changes were split across several pull requests. No pull request is left to open if there were no changes, or if
+// they were recorded in the plan
+func pushRepoChanges(config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository, branchName string) ([]pendingPullRequest, error) {
 	logger := logging.GetLogger("git-xargs")
 
 	status, statusErr := worktree.Status()
@@ -237,7 +292,7 @@ func updateRepo(config *config.GitXargsConfig, repositoryDir string, worktree *g
 
 		// Track the status check failure
 		config.Stats.TrackSingle(stats.WorktreeStatusCheckFailedCommand, remoteRepository)
-		return errors.WithStackTrace(statusErr)
+		return nil, errors.WithStackTrace(statusErr)
 	}
 
 	// If there are no changes, we log it, track it, and return
@@ -246,36 +301,61 @@ func updateRepo(config *config.GitXargsConfig, repositoryDir string, worktree *g
 			"Repo": remoteRepository.GetName(),
 		}).Debug("Local repository status is clean - nothing to stage or commit")
 
+		// If --resume was passed and the interrupted run already pushed this branch, its pull request is all that's left
+		if resumed, err := pendingPullRequestForResumedBranch(config, remoteRepository, localRepository, branchName); resumed != nil || err != nil {
+			return resumed, err
+		}
+
 		// Track the fact that repo had no file changes post command execution
 		config.Stats.TrackSingle(stats.WorktreeStatusClean, remoteRepository)
 
-		return nil
+		return nil, nil
+	}
+
+	// If the changes are already on the base branch, e.g. because a leftover branch is run against again, there is nothing
+	// left to push or open a pull request for
+	if applied, err := skipChangesAlreadyOnBaseBranch(config, remoteRepository, localRepository, worktree, status); applied || err != nil {
+		return nil, err
+	}
+
+	// With --dry-run-level clone-and-run, show the changes instead of committing them
+	if config.DryRunLevel == common.DryRunCloneAndRun {
+		return nil, showChangesForDryRun(config, worktree, remoteRepository, localRepository, status)
+	}
+
+	// When running git-xargs plan, record the changes in the plan instead of pushing them
+	if config.Plan != nil {
+		return nil, addToPlan(config, repositoryDir, worktree, remoteRepository, localRepository, status)
+	}
+
+	// If --max-files-per-pull-request was passed and the changes touch more files than that, split them across
+	// several branches and pull requests instead
+	if shouldSplitChanges(config, status) {
+		return updateRepoInParts(config, repositoryDir, worktree, remoteRepository, localRepository, status)
 	}
 
 	// Commit any untracked files, modified or deleted files that resulted from script execution
-	commitErr := commitLocalChanges(status, config, repositoryDir, worktree, remoteRepository, localRepository)
+	commitHash, commitErr := commitLocalChanges(status, config, repositoryDir, worktree, remoteRepository, localRepository)
 	if commitErr != nil {
-		return commitErr
+		return nil, commitErr
 	}
 
 	// Push the local branch containing all of our changes from executing the supplied command
-	pushBranchErr := pushLocalBranch(config, remoteRepository, localRepository)
+	pushedHash, pushBranchErr := pushLocalBranch(config, remoteRepository, localRepository, branchName)
 	if pushBranchErr != nil {
-		return pushBranchErr
+		return nil, pushBranchErr
 	}
 
-	// Open a pull request on GitHub, of the recently pushed branch against the repository default branch
-	openPullRequestErr := openPullRequest(config, remoteRepository, branchName)
-	if openPullRequestErr != nil {
-		return openPullRequestErr
-	}
+	// If --commit-status was passed, mark the head of the pushed branch with the git-xargs commit status
+	setCommitStatus(config, remoteRepository, pushedHash)
 
-	return nil
+	return []pendingPullRequest{{commitHash: commitHash, branchName: branchName}}, nil
 }
 
 // commitLocalChanges will check for any changes in worktree as a result of script execution, and if any are present,
 // add any untracked, deleted or modified files and create a commit using the supplied or default commit message.
-func commitLocalChanges(status git.Status, config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository) error {
+// It returns the hash of the newly created commit.
+func commitLocalChanges(status git.Status, config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository) (plumbing.Hash, error) {
 	logger := logging.GetLogger("git-xargs")
 
 	// If there are changes, we need to stage, add and commit them
@@ -300,7 +380,7 @@ func commitLocalChanges(status git.Status, config *config.GitXargsConfig, reposi
 				}).Debug("Error adding file to git stage")
 				// Track the file staging failure
 				config.Stats.TrackSingle(stats.WorktreeAddFileFailed, remoteRepository)
-				return errors.WithStackTrace(addErr)
+				return plumbing.ZeroHash, errors.WithStackTrace(addErr)
 			}
 		}
 	}
@@ -309,10 +389,11 @@ func commitLocalChanges(status git.Status, config *config.GitXargsConfig, reposi
 	// option when configuring our commit option so that all modified and deleted files
 	// will have their changes committed
 	commitOps := &git.CommitOptions{
-		All: true,
+		All:     true,
+		SignKey: config.SigningKey,
 	}
 
-	_, commitErr := worktree.Commit(config.CommitMessage, commitOps)
+	commitHash, commitErr := worktree.Commit(commitMessageWithMarkers(config), commitOps)
 
 	if commitErr != nil {
 		logger.WithFields(logrus.Fields{
@@ -323,20 +404,25 @@ func commitLocalChanges(status git.Status, config *config.GitXargsConfig, reposi
 		// If we reach this point, we were unable to commit our changes, so we'll
 		// continue rather than attempt to push an empty branch and open an empty PR
 		config.Stats.TrackSingle(stats.CommitChangesFailed, remoteRepository)
-		return errors.WithStackTrace(commitErr)
+		return plumbing.ZeroHash, errors.WithStackTrace(commitErr)
 	}
 
+	// Count the files and lines changed for the final report and, if --report-html was passed, keep the diff of the
+	// changes to embed it in the report
+	trackCommitChanges(config, remoteRepository, localRepository, commitHash)
+
 	// If --skip-pull-requests was passed, track the repos whose changes were committed directly to the main branch
 	if config.SkipPullRequests {
 		config.Stats.TrackSingle(stats.CommitsMadeDirectlyToBranch, remoteRepository)
 	}
 
-	return nil
+	return commitHash, nil
 }
 
 // pushLocalBranch pushes the branch in the local clone of the /tmp/ directory repository to the GitHub remote origin
-// so that a pull request can be opened against it via the GitHub API
-func pushLocalBranch(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository) error {
+// so that a pull request can be opened against it via the GitHub API. It returns the hash of the head of the branch on
+// GitHub, which commits recreated via the Git Data API may not share with the local one
+func pushLocalBranch(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, branchName string) (plumbing.Hash, error) {
 	logger := logging.GetLogger("git-xargs")
 
 	if config.DryRun {
@@ -345,17 +431,34 @@ func pushLocalBranch(config *config.GitXargsConfig, remoteRepository *github.Rep
 		}).Debug("Skipping branch push to remote origin because --dry-run flag is set")
 
 		config.Stats.TrackSingle(stats.PushBranchSkipped, remoteRepository)
-		return nil
+		return plumbing.ZeroHash, nil
 	}
-	// Push the changes to the remote repo
-	po := &git.PushOptions{
-		RemoteName: "origin",
-		Auth: &http.BasicAuth{
-			Username: remoteRepository.GetOwner().GetLogin(),
-			Password: os.Getenv("GITHUB_OAUTH_TOKEN"),
-		},
+	defer startPhase(config, remoteRepository, types.PhasePush)()
+
+	var pushedHash plumbing.Hash
+	var pushErr error
+	if pushesViaAPI(config, remoteRepository) {
+		// With --push-via-api, the commits are recreated on GitHub via the Git Data API instead of being pushed through git
+		pushedHash, pushErr = pushBranchViaGitDataAPI(config, remoteRepository, localRepository, branchName)
+	} else {
+		// Push the changes to the remote repo. Only the supplied branch is pushed, so that the other local branches, such
+		// as the ones created when changes are split across several pull requests, are left alone
+		var auth transport.AuthMethod
+		_, auth, pushErr = gitRemote(config, remoteRepository)
+		if pushErr == nil {
+			po := &git.PushOptions{
+				RemoteName: "origin",
+				RefSpecs: []gitconfig.RefSpec{
+					gitconfig.RefSpec(fmt.Sprintf("%s:%s", branchName, branchName)),
+				},
+				Auth: auth,
+			}
+			pushErr = localRepository.PushContext(config.Context, po)
+		}
+		if pushErr == nil {
+			pushedHash, pushErr = branchHeadHash(localRepository, branchName)
+		}
 	}
-	pushErr := localRepository.Push(po)
 
 	if pushErr != nil {
 		logger.WithFields(logrus.Fields{
@@ -365,24 +468,65 @@ func pushLocalBranch(config *config.GitXargsConfig, remoteRepository *github.Rep
 
 		// Track the push failure
 		config.Stats.TrackSingle(stats.PushBranchFailed, remoteRepository)
-		return errors.WithStackTrace(pushErr)
+		return plumbing.ZeroHash, errors.WithStackTrace(pushErr)
 	}
 
 	logger.WithFields(logrus.Fields{
 		"Repo": remoteRepository.GetName(),
 	}).Debug("Successfully pushed local branch to remote origin")
+	recordCheckpoint(config, remoteRepository, state.CheckpointPushed)
+	config.Events.Repo(events.BranchPushed, remoteRepository)
+	trackPushedBranch(config, remoteRepository, branchName, pushedHash)
 
 	// If --skip-pull-requests was passed, track the fact that these changes were pushed directly to the main branch
 	if config.SkipPullRequests {
 		config.Stats.TrackSingle(stats.DirectCommitsPushedToRemoteBranch, remoteRepository)
 	}
 
-	return nil
+	return pushedHash, nil
+}
+
+// branchHeadHash returns the hash of the commit at the head of the supplied local branch
+func branchHeadHash(localRepository *git.Repository, branchName string) (plumbing.Hash, error) {
+	ref, err := localRepository.Reference(plumbing.ReferenceName(branchName), true)
+	if err != nil {
+		return plumbing.ZeroHash, errors.WithStackTrace(err)
+	}
+	return ref.Hash(), nil
+}
+
+// trackPushedBranch records the supplied branch, and the commit it was pushed at, for the run manifest and audit log
+func trackPushedBranch(config *config.GitXargsConfig, remoteRepository *github.Repository, branchName string, pushedHash plumbing.Hash) {
+	branch := types.PushedBranch{Name: plumbing.ReferenceName(branchName).Short(), CommitSHA: pushedHash.String()}
+	recordPushedBranch(config, remoteRepository, branch)
+}
+
+// recordPushedBranch tracks the supplied branch of the supplied repo, and the pull request opened for it if any, for
+// the run manifest, and records it in the audit log
+func recordPushedBranch(config *config.GitXargsConfig, repo *github.Repository, branch types.PushedBranch) {
+	config.Stats.TrackBranch(repo, branch)
+	logAuditErr(config.Audit.Branch(repo, branch), repo)
+}
+
+// commitSHA returns the supplied commit hash as a SHA, or an empty string if it is the zero hash
+func commitSHA(hash plumbing.Hash) string {
+	if hash.IsZero() {
+		return ""
+	}
+	return hash.String()
 }
 
 // Attempt to open a pull request via the GitHub API, of the supplied branch specific to this tool, against the main
 // branch for the remote origin
-func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, branch string) error {
+func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, commitHash plumbing.Hash, branch string, part changePart) error {
+	return openPullRequestWithContent(config, repo, localRepository, commitHash, branch, part, func() (string, string, error) {
+		return renderPullRequest(config, repo, part)
+	})
+}
+
+// openPullRequestWithContent opens a pull request like openPullRequest, but with the title and description returned by
+// the supplied function, which is only called once it is clear that a pull request will be opened
+func openPullRequestWithContent(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, commitHash plumbing.Hash, branch string, part changePart, content func() (string, string, error)) error {
 	logger := logging.GetLogger("git-xargs")
 
 	if config.DryRun || config.SkipPullRequests {
@@ -391,10 +535,23 @@ func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, bra
 		}).Debug("--dry-run and / or --skip-pull-requests is set to true, so skipping opening a pull request!")
 		return nil
 	}
+	if config.DryRunLevel == common.DryRunPushNoPR {
+		logger.WithFields(logrus.Fields{
+			"Repo":   repo.GetName(),
+			"Branch": branch,
+		}).Info("Pushed branch, but skipping opening a pull request because --dry-run-level is push-no-pr")
+		return nil
+	}
+	defer startPhase(config, repo, types.PhasePullRequest)()
+
 	repoDefaultBranch := config.BaseBranchName
 	if repoDefaultBranch == "" {
 		repoDefaultBranch = repo.GetDefaultBranch()
 	}
+	// Stacked pull requests are opened against the branch of the pull request they build on
+	if part.BaseBranch != "" {
+		repoDefaultBranch = part.BaseBranch
+	}
 
 	pullRequestAlreadyExists, err := pullRequestAlreadyExistsForBranch(config, repo, branch, repoDefaultBranch)
 
@@ -422,21 +579,21 @@ func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, bra
 		return nil
 	}
 
-	// If the user only supplies a commit message, use that for both the pull request title and descriptions,
-	// unless they are provided separately
-	titleToUse := config.PullRequestTitle
-	descriptionToUse := config.PullRequestDescription
-
-	commitMessage := config.CommitMessage
+	titleToUse, descriptionToUse, err := content()
+	if err != nil {
+		return err
+	}
 
-	if commitMessage != common.DefaultCommitMessage {
-		if titleToUse == common.DefaultPullRequestTitle {
-			titleToUse = commitMessage
-		}
+	// Evaluate the --draft and --draft-if-* rules to determine whether this pull request should be opened as a draft
+	draft, draftReason, err := shouldOpenAsDraft(config, repo, localRepository, commitHash, branch)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+			"Repo":  repo.GetName(),
+		}).Debug("Error evaluating draft pull request rules")
 
-		if descriptionToUse == common.DefaultPullRequestDescription {
-			descriptionToUse = commitMessage
-		}
+		config.Stats.TrackSingle(stats.PullRequestOpenErr, repo)
+		return err
 	}
 
 	// Configure pull request options that the GitHub client accepts when making calls to open new pull requests
@@ -446,11 +603,11 @@ func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, bra
 		Base:                github.String(repoDefaultBranch),
 		Body:                github.String(descriptionToUse),
 		MaintainerCanModify: github.Bool(true),
-		Draft:               github.Bool(config.Draft),
+		Draft:               github.Bool(draft),
 	}
 
 	// Make a pull request via the Github API
-	pr, resp, err := config.GithubClient.PullRequests.Create(context.Background(), *repo.GetOwner().Login, repo.GetName(), newPR)
+	pr, resp, err := config.GithubClient.PullRequests.Create(config.Context, *repo.GetOwner().Login, repo.GetName(), newPR)
 
 	prErrorMessage := "Error opening pull request"
 
@@ -492,15 +649,146 @@ func openPullRequest(config *config.GitXargsConfig, repo *github.Repository, bra
 		"Pull Request URL": pr.GetHTMLURL(),
 	}).Debug("Successfully opened pull request")
 
-	if config.Draft {
-		config.Stats.TrackDraftPullRequest(repo.GetName(), pr.GetHTMLURL())
+	if draftReason != "" {
+		logger.WithFields(logrus.Fields{
+			"Repo":   repo.GetName(),
+			"Reason": draftReason,
+		}).Debug("Opened pull request in draft mode because a --draft-if-* rule matched")
+
+		config.Stats.TrackSingle(stats.PullRequestOpenedAsConditionalDraft, repo)
+	}
+
+	if draft {
+		config.Stats.TrackDraftPullRequest(part.reportName(repo), pr.GetHTMLURL())
 	} else {
 		// Track successful opening of the pull request, extracting the HTML url to the PR itself for easier review
-		config.Stats.TrackPullRequest(repo.GetName(), pr.GetHTMLURL())
+		config.Stats.TrackPullRequest(part.reportName(repo), pr.GetHTMLURL())
+	}
+	config.Events.PullRequestOpened(repo, pr.GetHTMLURL(), draft)
+	recordPushedBranch(config, repo, types.PushedBranch{
+		Name:              plumbing.ReferenceName(branch).Short(),
+		CommitSHA:         commitSHA(commitHash),
+		PullRequestURL:    pr.GetHTMLURL(),
+		PullRequestNumber: pr.GetNumber(),
+		Draft:             draft,
+	})
+
+	// Record the pull request in the state store, so later subcommands can find it by run ID
+	logStateErr(config.State.RecordPullRequest(config.RunID, repo, state.PullRequest{
+		Number:   pr.GetNumber(),
+		URL:      pr.GetHTMLURL(),
+		Branch:   branch,
+		Draft:    draft,
+		OpenedAt: time.Now(),
+	}), repo)
+	recordCheckpoint(config, repo, state.CheckpointPullRequestOpened)
+
+	// If --reviewers was supplied, request reviews from the next reviewers in the pool
+	requestReviewers(config, repo, pr, localRepository, commitHash)
+
+	// If --exclude-reviewers was supplied, withdraw any review requests GitHub made of them, e.g. via CODEOWNERS
+	removeExcludedReviewers(config, repo, pr)
+
+	// If --assignees was supplied, assign the pull request to the next assignees in the pool
+	addAssignees(config, repo, pr)
+
+	// Label the pull request with the run's marker label, so that every pull request from this run can be found later
+	addRunMarkerLabel(config, repo, pr)
+
+	// If --path-labels was supplied, label the pull request after the paths it changes
+	addPathLabels(config, repo, pr, localRepository, branch)
+
+	// If --project was supplied, add the pull request to the Projects (v2) board
+	addPullRequestToProject(config, repo, pr)
+
+	// If --approve-and-merge was supplied, approve the pull request as the second identity and merge it. Pull requests
+	// that were opened as drafts by a --draft-if-* rule are left for humans to review
+	if config.ApproveAndMerge && !draft {
+		approveAndMergePullRequest(config, repo, pr)
 	}
+
 	return nil
 }
 
+// renderPullRequest returns the title and description of the pull request for the supplied repo and part of the changes
+func renderPullRequest(config *config.GitXargsConfig, repo *github.Repository, part changePart) (string, string, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	// If the user only supplies a commit message, use that for both the pull request title and descriptions,
+	// unless they are provided separately
+	titleToUse := config.PullRequestTitle
+	descriptionToUse := config.PullRequestDescription
+	titleIsTemplate := true
+
+	commitMessage := config.CommitMessage
+
+	if commitMessage != common.DefaultCommitMessage {
+		if titleToUse == common.DefaultPullRequestTitle {
+			titleToUse = commitMessage
+			titleIsTemplate = false
+		}
+
+		if descriptionToUse == common.DefaultPullRequestDescription {
+			descriptionToUse = commitMessage
+		}
+	}
+
+	// Fill in any placeholders, such as {{.FullName}}, in the --pull-request-title and footer for this repo, then add the
+	// footer and the run marker to the description so that the pull request can be traced back to this run. The
+	// description and commit message are used as is, since they often quote code with braces of its own, such as
+	// ${{ github.sha }} in a GitHub Actions workflow
+	templateData := newTemplateData(config, repo)
+	var err error
+	if titleIsTemplate {
+		titleToUse, err = util.RenderTemplate(titleToUse, templateData)
+	}
+	footer := ""
+	if err == nil {
+		footer, err = util.RenderTemplate(config.PullRequestFooter, templateData)
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+			"Repo":  repo.GetName(),
+		}).Debug("Error rendering pull request title or footer template")
+
+		config.Stats.TrackSingle(stats.PullRequestOpenErr, repo)
+		return "", "", errors.WithStackTrace(err)
+	}
+	titleToUse, descriptionToUse = part.decorate(config, titleToUse, descriptionToUse)
+	titleToUse = withJiraIssueKey(config, titleToUse)
+	titleToUse = titleWithSkipCIMarker(config, titleToUse)
+	descriptionToUse = descriptionWithFooter(descriptionToUse, footer)
+	descriptionToUse = descriptionWithBuildInfo(config, descriptionToUse)
+	descriptionToUse = descriptionWithRunMarker(config, descriptionToUse)
+
+	return titleToUse, descriptionToUse, nil
+}
+
+// openPullRequestExistsForBranch returns true if there is an open pull request in the given repo from the configured
+// branch, regardless of which base branch it targets
+func openPullRequestExistsForBranch(config *config.GitXargsConfig, repo *github.Repository) (bool, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	opts := &github.PullRequestListOptions{
+		State: "open",
+		Head:  fmt.Sprintf("%s:%s", repo.GetOwner().GetLogin(), config.BranchName),
+	}
+
+	prs, _, err := config.GithubClient.PullRequests.List(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), opts)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+			"Repo":  repo.GetName(),
+		}).Debug("Error listing open pull requests")
+
+		config.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+		return false, errors.WithStackTrace(err)
+	}
+
+	return len(prs) > 0, nil
+}
+
 // Returns true if a pull request already exists in the given repo for the given branch
 func pullRequestAlreadyExistsForBranch(config *config.GitXargsConfig, repo *github.Repository, branch string, repoDefaultBranch string) (bool, error) {
 	opts := &github.PullRequestListOptions{
@@ -510,7 +798,7 @@ func pullRequestAlreadyExistsForBranch(config *config.GitXargsConfig, repo *gith
 		Base: repoDefaultBranch,
 	}
 
-	prs, _, err := config.GithubClient.PullRequests.List(context.Background(), *repo.GetOwner().Login, repo.GetName(), opts)
+	prs, _, err := config.GithubClient.PullRequests.List(config.Context, *repo.GetOwner().Login, repo.GetName(), opts)
 	if err != nil {
 		return false, errors.WithStackTrace(err)
 	}
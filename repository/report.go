@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/sirupsen/logrus"
+)
+
+// trackCommitChanges records the number of files and lines changed by the commit with the supplied hash and, if
+// --report-html or --report-diff-lines was passed, its diff against its parent in the run stats. Changes that can't be
+// computed are only logged, since they are not worth failing the repo over
+func trackCommitChanges(config *config.GitXargsConfig, remoteRepository *github.Repository, localRepository *git.Repository, commitHash plumbing.Hash) {
+	logger := logging.GetLogger("git-xargs")
+
+	patch, err := getCommitPatch(localRepository, commitHash)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+			"Repo":  remoteRepository.GetName(),
+		}).Debug("Error computing the diff of the commit for the run report")
+		return
+	}
+
+	config.Stats.TrackDiffStats(remoteRepository, getPatchDiffStats(patch))
+	if reportsDiffs(config) {
+		config.Stats.TrackDiff(remoteRepository, patch.String())
+	}
+}
+
+// reportsDiffs returns true if the run reports include the diff of each changed repo
+func reportsDiffs(config *config.GitXargsConfig) bool {
+	return config.ReportHTML != "" || config.ReportDiffLines > 0
+}
+
+// getCommitPatch returns the patch of the commit with the supplied hash against its parent
+func getCommitPatch(localRepository *git.Repository, commitHash plumbing.Hash) (*object.Patch, error) {
+	commit, err := localRepository.CommitObject(commitHash)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, err
+	}
+	return parent.Patch(commit)
+}
+
+// getPatchDiffStats counts the files, and the lines inserted and deleted, in the supplied patch
+func getPatchDiffStats(patch *object.Patch) types.DiffStats {
+	diffStats := types.DiffStats{}
+	for _, fileStat := range patch.Stats() {
+		diffStats.FilesChanged++
+		diffStats.Insertions += fileStat.Addition
+		diffStats.Deletions += fileStat.Deletion
+	}
+	return diffStats
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that the files and lines changed by each commit are counted, and summed across the commits made to a repo
+func TestTrackCommitChanges(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-report-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, name), []byte(content), 0644))
+		}
+		_, err := worktree.Add(".")
+		require.NoError(t, err)
+		_, err = worktree.Commit("commit", &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+	}
+
+	testConfig := config.NewGitXargsTestConfig()
+	repo := mocks.GetMockGithubRepo()
+
+	commit(map[string]string{"main.tf": "one\ntwo\nthree\n", "README.md": "docs\n"})
+
+	commit(map[string]string{"main.tf": "one\n2\nthree\nfour\n", "new.txt": "a\nb\n"})
+	head, err := localRepository.Head()
+	require.NoError(t, err)
+	trackCommitChanges(testConfig, repo, localRepository, head.Hash())
+	assert.Equal(t, types.DiffStats{FilesChanged: 2, Insertions: 4, Deletions: 1}, testConfig.Stats.GetDiffStats()[util.RepoKey(repo)])
+	assert.Empty(t, testConfig.Stats.GetDiffs())
+
+	testConfig.ReportHTML = "report.html"
+	commit(map[string]string{"README.md": ""})
+	head, err = localRepository.Head()
+	require.NoError(t, err)
+	trackCommitChanges(testConfig, repo, localRepository, head.Hash())
+	assert.Equal(t, types.DiffStats{FilesChanged: 3, Insertions: 4, Deletions: 2}, testConfig.Stats.GetDiffStats()[util.RepoKey(repo)])
+	assert.Contains(t, testConfig.Stats.GetDiffs()[util.RepoKey(repo)], "-docs")
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"github.com/go-git/go-git/v5"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// recordCheckpoint records how far processing the supplied repo got, so that --resume knows where to pick it up if the
+// run is interrupted
+func recordCheckpoint(config *config.GitXargsConfig, repo *github.Repository, checkpoint string) {
+	logStateErr(config.State.RecordCheckpoint(config.RunID, repo, checkpoint), repo)
+}
+
+// getResumedRepo returns the record the interrupted run left for the supplied repo when --resume was passed, or nil if
+// the run isn't being resumed or never got to the repo
+func getResumedRepo(config *config.GitXargsConfig, repo *github.Repository) *state.Repo {
+	if !config.Resume {
+		return nil
+	}
+	record, err := config.State.GetRepo(config.RunID, repo)
+	logStateErr(err, repo)
+	return record
+}
+
+// alreadyProcessedByResumedRun returns true if the interrupted run being resumed already finished the supplied repo, or
+// got as far as opening its pull request, in which case processing it again would only duplicate that work
+func alreadyProcessedByResumedRun(config *config.GitXargsConfig, repo *github.Repository) bool {
+	record := getResumedRepo(config, repo)
+	if record == nil {
+		return false
+	}
+	if record.Outcome != state.OutcomeSucceeded && !record.ReachedCheckpoint(state.CheckpointPullRequestOpened) {
+		return false
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Outcome":   record.Outcome,
+	}).Info("Skipping repo because the resumed run already processed it")
+
+	config.Stats.TrackSingle(stats.RepoAlreadyProcessedSkipped, repo)
+	return true
+}
+
+// pendingPullRequestForResumedBranch returns the pull request left to open for a branch the interrupted run pushed
+// before it was stopped. When such a repo is processed again, the pushed branch is pulled before the command runs, so
+// the command usually leaves nothing to commit and the pull request would otherwise never be opened. Returns nil if the
+// repo's branch wasn't pushed by the interrupted run
+func pendingPullRequestForResumedBranch(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, branchName string) ([]pendingPullRequest, error) {
+	record := getResumedRepo(config, repo)
+	if record == nil || !record.ReachedCheckpoint(state.CheckpointPushed) {
+		return nil, nil
+	}
+
+	head, err := localRepository.Head()
+	if err != nil {
+		config.Stats.TrackSingle(stats.GetHeadRefFailed, repo)
+		return nil, errors.WithStackTrace(err)
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Branch":    branchName,
+	}).Debug("Opening pull request for branch pushed by the resumed run")
+
+	config.Stats.TrackSingle(stats.ResumedFromPushedBranch, repo)
+	return []pendingPullRequest{{commitHash: head.Hash(), branchName: branchName}}, nil
+}
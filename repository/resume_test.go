@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that --resume skips the repos the interrupted run already got through, and only those
+func TestAlreadyProcessedByResumedRun(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RunID = "run-1"
+
+	store, cleanup := newTestStateStore(t, testConfig.RunID)
+	defer cleanup()
+	testConfig.State = store
+
+	repo := mocks.GetMockGithubRepo()
+	require.NoError(t, store.RecordCheckpoint(testConfig.RunID, repo, state.CheckpointPushed))
+
+	// Without --resume, nothing is skipped
+	assert.False(t, alreadyProcessedByResumedRun(testConfig, repo))
+
+	testConfig.Resume = true
+	assert.False(t, alreadyProcessedByResumedRun(testConfig, repo))
+
+	require.NoError(t, store.RecordCheckpoint(testConfig.RunID, repo, state.CheckpointPullRequestOpened))
+	assert.True(t, alreadyProcessedByResumedRun(testConfig, repo))
+	assert.Contains(t, testConfig.Stats.GetRepos()[stats.RepoAlreadyProcessedSkipped], repo)
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SetRevertDefaults defaults --branch-name to git-xargs-revert-<run-id>, and the commit message, which in turn is used
+// as the pull request title and description, to one naming the reverted run
+func SetRevertDefaults(config *config.GitXargsConfig, revertedRunID string) {
+	if config.BranchName == "" {
+		config.BranchName = fmt.Sprintf("git-xargs-revert-%s", revertedRunID)
+	}
+	if config.CommitMessage == common.DefaultCommitMessage {
+		config.CommitMessage = fmt.Sprintf("Revert git-xargs run %s", revertedRunID)
+	}
+}
+
+// RevertRun opens a pull request reverting the changes of every merged pull request opened by the run with the
+// supplied ID. Each revert is committed to --branch-name on top of the repo's default branch, and pushed and opened the
+// same way as the changes of a regular run. Repos whose reverted files have changed since the pull request was merged
+// are skipped, rather than risk undoing later work
+func RevertRun(config *config.GitXargsConfig, revertedRunID string) error {
+	logger := logging.GetLogger("git-xargs")
+
+	return forEachRunPullRequest(config, revertedRunID, func(repo *github.Repository, recordedPR state.PullRequest) {
+		repoConfig, err := withRepoHost(config, repo)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up the GitHub host of pull request")
+
+			config.Stats.TrackError(repo, err)
+			logStateErr(config.State.RecordOutcome(config.RunID, repo, err), repo)
+			return
+		}
+		err = revertPullRequest(repoConfig, repo, recordedPR)
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error reverting pull request")
+			repoConfig.Stats.TrackError(repo, err)
+		}
+		logStateErr(repoConfig.State.RecordOutcome(repoConfig.RunID, repo, err), repo)
+	})
+}
+
+// revertPullRequest clones the repo the supplied pull request was opened against, reverts its merge on the revert
+// branch, then commits, pushes and opens a pull request for the revert
+func revertPullRequest(config *config.GitXargsConfig, repo *github.Repository, recordedPR state.PullRequest) error {
+	logger := logging.GetLogger("git-xargs")
+
+	pr, _, err := config.GithubClient.PullRequests.Get(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), recordedPR.Number)
+	if err != nil {
+		config.Stats.TrackSingle(stats.PullRequestLookupErr, repo)
+		return errors.WithStackTrace(err)
+	}
+	if getPullRequestState(pr) != PullRequestStateMerged {
+		logger.WithFields(logrus.Fields{
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Skipping pull request that was not merged")
+		return nil
+	}
+
+	// The state store only records the repo's name, so look up the rest, such as its clone URL, before cloning
+	fullRepo, _, err := config.GithubClient.Repositories.Get(config.Context, repo.GetOwner().GetLogin(), repo.GetName())
+	if err != nil {
+		config.Stats.TrackSingle(stats.RepoNotExists, repo)
+		return errors.WithStackTrace(err)
+	}
+
+	repositoryDir, localRepository, err := cloneLocalRepository(config, fullRepo)
+	if err != nil {
+		return err
+	}
+
+	ref, err := getLocalRepoHeadRef(config, localRepository, fullRepo)
+	if err != nil {
+		return err
+	}
+
+	worktree, err := getLocalWorkTree(repositoryDir, localRepository, fullRepo)
+	if err != nil {
+		return err
+	}
+
+	branchName, err := checkoutLocalBranch(config, ref, worktree, fullRepo, localRepository)
+	if err != nil {
+		return err
+	}
+
+	head, err := localRepository.Head()
+	if err != nil {
+		config.Stats.TrackSingle(stats.GetHeadRefFailed, fullRepo)
+		return errors.WithStackTrace(err)
+	}
+
+	mergedCommits, err := mergedCommitCount(config, fullRepo, pr, localRepository)
+	if err != nil {
+		config.Stats.TrackSingle(stats.RevertFailed, fullRepo)
+		return err
+	}
+
+	conflicts, err := applyRevert(localRepository, repositoryDir, plumbing.NewHash(pr.GetMergeCommitSHA()), head.Hash(), mergedCommits)
+	if err == errRevertUnsupported {
+		logger.WithFields(logrus.Fields{
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Info("Skipping pull request whose merge cannot be reverted automatically")
+
+		config.Stats.TrackSingle(stats.RevertUnsupported, fullRepo)
+		return nil
+	}
+	if err != nil {
+		config.Stats.TrackSingle(stats.RevertFailed, fullRepo)
+		return err
+	}
+	if len(conflicts) > 0 {
+		logger.WithFields(logrus.Fields{
+			"Pull Request URL": pr.GetHTMLURL(),
+			"Files":            conflicts,
+		}).Info("Skipping revert because files changed by the pull request have changed since it was merged")
+
+		config.Stats.TrackSingle(stats.RevertConflict, fullRepo)
+		return nil
+	}
+
+	return updateRepo(config, repositoryDir, worktree, fullRepo, localRepository, branchName.String())
+}
+
+// errRevertUnsupported is returned by applyRevert for merges it cannot find the pre-merge state of
+var errRevertUnsupported = fmt.Errorf("cannot determine the state of the repo before this pull request was merged")
+
+// mergedCommitCount returns how many commits merging the supplied pull request put on its base branch, ending at its
+// merge commit: one for a merge commit or a squash, and one per commit of the pull request for a rebase. A rebase keeps
+// the message of the head commit of the pull request on the last commit it puts on the base branch, while a squash
+// writes one of its own, so only pull requests of several commits, merged without a merge commit, need the head commit
+// to be looked up to tell them apart
+func mergedCommitCount(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest, localRepository *git.Repository) (int, error) {
+	if pr.GetCommits() <= 1 {
+		return 1, nil
+	}
+	mergeCommit, err := localRepository.CommitObject(plumbing.NewHash(pr.GetMergeCommitSHA()))
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+	if mergeCommit.NumParents() != 1 {
+		return 1, nil
+	}
+
+	headCommit, _, err := config.GithubClient.Repositories.GetCommit(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetHead().GetSHA())
+	if err != nil {
+		return 0, errors.WithStackTrace(err)
+	}
+	if strings.TrimSpace(headCommit.GetCommit().GetMessage()) != strings.TrimSpace(mergeCommit.Message) {
+		return 1, nil
+	}
+	return pr.GetCommits(), nil
+}
+
+// applyRevert writes the state of every file changed by the supplied number of commits, ending at the supplied merge
+// commit, as it was before them, into the worktree. Merge commits are reverted against their first parent, and squashed
+// merges against their only parent, while rebased merges of several commits are reverted against the parent of the
+// first of them. If any of those files have changed between the merge commit and the supplied head commit, nothing is
+// written and the changed files are returned instead
+func applyRevert(localRepository *git.Repository, repositoryDir string, mergeHash plumbing.Hash, headHash plumbing.Hash, mergedCommits int) ([]string, error) {
+	mergeCommit, err := localRepository.CommitObject(mergeHash)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	firstCommit := mergeCommit
+	for i := 1; i < mergedCommits; i++ {
+		if firstCommit.NumParents() != 1 {
+			return nil, errRevertUnsupported
+		}
+		if firstCommit, err = firstCommit.Parent(0); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+	}
+	if firstCommit.NumParents() == 0 {
+		return nil, errRevertUnsupported
+	}
+
+	parentCommit, err := firstCommit.Parent(0)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	headCommit, err := localRepository.CommitObject(headHash)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	parentTree, err := parentCommit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	mergeTree, err := mergeCommit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	changes, err := object.DiffTree(parentTree, mergeTree)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	paths := []string{}
+	seen := map[string]bool{}
+	conflicts := []string{}
+	for _, change := range changes {
+		for _, path := range []string{change.From.Name, change.To.Name} {
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			paths = append(paths, path)
+
+			if !sameFileInTrees(mergeTree, headTree, path) {
+				conflicts = append(conflicts, path)
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	for _, path := range paths {
+		fullPath := filepath.Join(repositoryDir, filepath.FromSlash(path))
+
+		file, err := parentTree.File(path)
+		if err == object.ErrFileNotFound {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return nil, errors.WithStackTrace(err)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		mode, err := file.Mode.ToOSFileMode()
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if err := ioutil.WriteFile(fullPath, []byte(contents), mode.Perm()); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		if err := os.Chmod(fullPath, mode.Perm()); err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+	}
+
+	return nil, nil
+}
+
+// sameFileInTrees returns true if the file at the supplied path has the same contents in both trees, or is missing
+// from both
+func sameFileInTrees(a *object.Tree, b *object.Tree, path string) bool {
+	fileA, errA := a.File(path)
+	fileB, errB := b.File(path)
+	if errA != nil || errB != nil {
+		return errA == object.ErrFileNotFound && errB == object.ErrFileNotFound
+	}
+	return fileA.Hash == fileB.Hash
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRevertTestRepo creates a repo on disk whose history holds a base commit, a "merged" commit that modifies, adds and
+// deletes files, and a later commit that changes the supplied file
+func newRevertTestRepo(t *testing.T, laterChange string) (string, *git.Repository, plumbing.Hash, plumbing.Hash) {
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-revert-test")
+	require.NoError(t, err)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(message string, files map[string]string, removed ...string) plumbing.Hash {
+		for name, content := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, name), []byte(content), 0644))
+		}
+		for _, name := range removed {
+			require.NoError(t, os.Remove(filepath.Join(repositoryDir, name)))
+		}
+		_, err := worktree.Add(".")
+		require.NoError(t, err)
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, All: true})
+		require.NoError(t, err)
+		return hash
+	}
+
+	commit("base", map[string]string{"modified.txt": "original", "deleted.txt": "original", "unrelated.txt": "original"})
+	mergeHash := commit("merged", map[string]string{"modified.txt": "changed", "added.txt": "new"}, "deleted.txt")
+	headHash := commit("later", map[string]string{laterChange: "later"})
+
+	return repositoryDir, localRepository, mergeHash, headHash
+}
+
+func TestApplyRevert(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, localRepository, mergeHash, headHash := newRevertTestRepo(t, "unrelated.txt")
+	defer os.RemoveAll(repositoryDir)
+
+	conflicts, err := applyRevert(localRepository, repositoryDir, mergeHash, headHash, 1)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	readFile := func(name string) string {
+		content, err := ioutil.ReadFile(filepath.Join(repositoryDir, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+	assert.Equal(t, "original", readFile("modified.txt"))
+	assert.Equal(t, "original", readFile("deleted.txt"))
+	assert.Equal(t, "later", readFile("unrelated.txt"))
+	_, err = os.Stat(filepath.Join(repositoryDir, "added.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// Test that nothing is reverted if a file changed by the merge has changed since
+func TestApplyRevertReportsConflicts(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, localRepository, mergeHash, headHash := newRevertTestRepo(t, "modified.txt")
+	defer os.RemoveAll(repositoryDir)
+
+	conflicts, err := applyRevert(localRepository, repositoryDir, mergeHash, headHash, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"modified.txt"}, conflicts)
+
+	_, err = os.Stat(filepath.Join(repositoryDir, "added.txt"))
+	assert.NoError(t, err)
+}
+
+// Test that a pull request of several commits that was rebased is reverted as a whole, and one that was squashed is
+// reverted against the only parent of its squashed commit. The head commit of the mocked pull request has the message
+// "Update CI\n\nGit-Xargs-Run-Id: run-1"
+func TestRevertRebasedAndSquashedPullRequests(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		name           string
+		commitMessages []string
+		expectedCount  int
+	}{
+		{"rebased", []string{"Add first file", "Update CI\n\nGit-Xargs-Run-Id: run-1"}, 2},
+		{"squashed", []string{"Update CI (#1)\n\n* Add first file\n* Update CI"}, 1},
+	} {
+		repositoryDir, err := ioutil.TempDir("", "git-xargs-revert-test")
+		require.NoError(t, err)
+		defer os.RemoveAll(repositoryDir)
+
+		localRepository, err := git.PlainInit(repositoryDir, false)
+		require.NoError(t, err)
+		worktree, err := localRepository.Worktree()
+		require.NoError(t, err)
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+		commit := func(message string, name string) plumbing.Hash {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(repositoryDir, name), []byte(message), 0644))
+			_, err := worktree.Add(name)
+			require.NoError(t, err)
+			hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature})
+			require.NoError(t, err)
+			return hash
+		}
+
+		commit("base", "base.txt")
+		var mergeHash plumbing.Hash
+		for i, message := range testCase.commitMessages {
+			mergeHash = commit(message, fmt.Sprintf("merged-%d.txt", i))
+		}
+		headHash := commit("later", "later.txt")
+
+		testConfig := config.NewGitXargsTestConfig()
+		testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+		pr := &github.PullRequest{
+			Commits:        github.Int(2),
+			MergeCommitSHA: github.String(mergeHash.String()),
+			Head:           &github.PullRequestBranch{SHA: github.String("2222222222222222222222222222222222222222")},
+		}
+		mergedCommits, err := mergedCommitCount(testConfig, mocks.GetMockGithubRepo(), pr, localRepository)
+		require.NoError(t, err, testCase.name)
+		assert.Equal(t, testCase.expectedCount, mergedCommits, testCase.name)
+
+		conflicts, err := applyRevert(localRepository, repositoryDir, mergeHash, headHash, mergedCommits)
+		require.NoError(t, err, testCase.name)
+		assert.Empty(t, conflicts, testCase.name)
+		for i := range testCase.commitMessages {
+			_, err = os.Stat(filepath.Join(repositoryDir, fmt.Sprintf("merged-%d.txt", i)))
+			assert.True(t, os.IsNotExist(err), testCase.name)
+		}
+		for _, name := range []string{"base.txt", "later.txt"} {
+			_, err = os.Stat(filepath.Join(repositoryDir, name))
+			assert.NoError(t, err, testCase.name)
+		}
+	}
+}
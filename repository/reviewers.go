@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// requestReviewers requests reviews on the supplied pull request from the next reviewers in the --reviewers pool and,
+// if --reviewers-from-blame is set, from the most recent committers of the changed files. Failures are tracked, but
+// don't fail the repo, since the pull request itself was opened successfully
+func requestReviewers(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest, localRepository *git.Repository, commitHash plumbing.Hash) {
+	if config.ReviewerPool.IsEmpty() && !config.ReviewersFromBlame {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	selected, err := config.ReviewerPool.Next(openReviewRequestCounter(config))
+	if err == nil && config.ReviewersFromBlame && localRepository != nil {
+		var blameReviewers []string
+		blameReviewers, err = selectBlameReviewers(config, repo, localRepository, commitHash)
+		selected = append(selected, blameReviewers...)
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error": err,
+			"Repo":  repo.GetName(),
+		}).Debug("Error selecting reviewers")
+
+		config.Stats.TrackSingle(stats.ReviewersRequestErr, repo)
+		return
+	}
+
+	// GitHub rejects review requests for the author of the pull request, and duplicates are pointless. Reviewers passed
+	// via --exclude-reviewers are left out of the pool, but the committers picked via git blame may still be among them
+	selected = reviewers.Exclude(dedupeReviewers(selected, pr.GetUser().GetLogin()), config.ExcludeReviewers)
+	if len(selected) == 0 {
+		return
+	}
+
+	users, teams := reviewers.SplitUsersAndTeams(selected)
+	reviewersRequest := github.ReviewersRequest{
+		Reviewers:     users,
+		TeamReviewers: teams,
+	}
+
+	if _, _, err := config.GithubClient.PullRequests.RequestReviewers(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), reviewersRequest); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+			"Reviewers":        selected,
+		}).Debug("Error requesting reviewers on pull request")
+
+		config.Stats.TrackSingle(stats.ReviewersRequestErr, repo)
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Pull Request URL": pr.GetHTMLURL(),
+		"Reviewers":        selected,
+	}).Debug("Successfully requested reviewers on pull request")
+
+	config.Stats.TrackSingle(stats.ReviewersRequested, repo)
+}
+
+// removeExcludedReviewers withdraws the review requests on the supplied pull request of the users and teams passed via
+// --exclude-reviewers, which GitHub may have requested on its own, e.g. because they are code owners of the changed
+// files. Failures are tracked, but don't fail the repo, since the pull request itself was opened successfully
+func removeExcludedReviewers(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	if len(config.ExcludeReviewers) == 0 {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+	owner := repo.GetOwner().GetLogin()
+
+	requested, _, err := config.GithubClient.PullRequests.ListReviewers(config.Context, owner, repo.GetName(), pr.GetNumber(), nil)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error listing the requested reviewers of pull request")
+
+		config.Stats.TrackSingle(stats.ReviewersRequestErr, repo)
+		return
+	}
+
+	reviewersRequest := excludedReviewRequests(requested, owner, config.ExcludeReviewers)
+	if len(reviewersRequest.Reviewers) == 0 && len(reviewersRequest.TeamReviewers) == 0 {
+		return
+	}
+
+	if _, err := config.GithubClient.PullRequests.RemoveReviewers(config.Context, owner, repo.GetName(), pr.GetNumber(), reviewersRequest); err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error removing excluded reviewers from pull request")
+
+		config.Stats.TrackSingle(stats.ReviewersRequestErr, repo)
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Pull Request URL": pr.GetHTMLURL(),
+		"Users":            reviewersRequest.Reviewers,
+		"Teams":            reviewersRequest.TeamReviewers,
+	}).Debug("Removed excluded reviewers from pull request")
+}
+
+// excludedReviewRequests returns the users and teams among the supplied requested reviewers of a pull request in the
+// repo of the supplied owner that are excluded. Teams are excluded in the format of <github-organization>/<team-slug>
+func excludedReviewRequests(requested *github.Reviewers, owner string, excluded []string) github.ReviewersRequest {
+	reviewersRequest := github.ReviewersRequest{Reviewers: []string{}, TeamReviewers: []string{}}
+	for _, user := range requested.Users {
+		if reviewers.IsExcluded(user.GetLogin(), excluded) {
+			reviewersRequest.Reviewers = append(reviewersRequest.Reviewers, user.GetLogin())
+		}
+	}
+	for _, team := range requested.Teams {
+		if reviewers.IsExcluded(owner+"/"+team.GetSlug(), excluded) {
+			reviewersRequest.TeamReviewers = append(reviewersRequest.TeamReviewers, team.GetSlug())
+		}
+	}
+	return reviewersRequest
+}
+
+// openReviewRequestCounter returns a reviewers.LoadFunc that uses the GitHub search API to count the open pull requests
+// each reviewer has been asked to review. Teams are not counted, since their load is spread across their members
+func openReviewRequestCounter(config *config.GitXargsConfig) reviewers.LoadFunc {
+	return func(member string) (int, error) {
+		if users, _ := reviewers.SplitUsersAndTeams([]string{member}); len(users) == 0 {
+			return 0, nil
+		}
+
+		query := fmt.Sprintf("is:open is:pr review-requested:%s", member)
+		result, _, err := config.GithubClient.Search.Issues(config.Context, query, nil)
+		if err != nil {
+			return 0, errors.WithStackTrace(err)
+		}
+
+		return result.GetTotal(), nil
+	}
+}
+
+// ResolveAssignees expands any teams passed via --assignees, in the format of <github-organization>/<team-slug>, into
+// the logins of their members, since only users can be assigned to a pull request, and builds the assignee pool from
+// the result. Users that appear more than once, e.g. as a member of two teams, are only added to the pool once
+func ResolveAssignees(config *config.GitXargsConfig) error {
+	if len(config.Assignees) == 0 {
+		return nil
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	seen := make(map[string]bool)
+	members := []string{}
+
+	addMember := func(login string) {
+		if !seen[login] {
+			seen[login] = true
+			members = append(members, login)
+		}
+	}
+
+	for _, assignee := range config.Assignees {
+		parts := strings.SplitN(assignee, "/", 2)
+		if len(parts) != 2 {
+			addMember(assignee)
+			continue
+		}
+
+		opts := &github.TeamListTeamMembersOptions{
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			users, resp, err := config.GithubClient.Teams.ListTeamMembersBySlug(config.Context, parts[0], parts[1], opts)
+			if err != nil {
+				return errors.WithStackTrace(err)
+			}
+			for _, user := range users {
+				addMember(user.GetLogin())
+			}
+			if resp == nil || resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+
+		logger.WithFields(logrus.Fields{
+			"Team": assignee,
+		}).Debug("Expanded assignee team into its members")
+	}
+
+	config.AssigneePool = reviewers.NewPool(members, config.AssigneeStrategy, config.AssigneesPerPR)
+
+	return nil
+}
+
+// addAssignees assigns the supplied pull request to the next members of the --assignees pool. Failures are tracked,
+// but don't fail the repo, since the pull request itself was opened successfully
+func addAssignees(config *config.GitXargsConfig, repo *github.Repository, pr *github.PullRequest) {
+	if config.AssigneePool.IsEmpty() {
+		return
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	selected, err := config.AssigneePool.Next(openAssignmentCounter(config))
+	if err == nil {
+		_, _, err = config.GithubClient.Issues.AddAssignees(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), pr.GetNumber(), selected)
+	}
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"Error":            err,
+			"Pull Request URL": pr.GetHTMLURL(),
+		}).Debug("Error assigning pull request")
+
+		config.Stats.TrackSingle(stats.AssigneesAddErr, repo)
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Pull Request URL": pr.GetHTMLURL(),
+		"Assignees":        selected,
+	}).Debug("Successfully assigned pull request")
+
+	config.Stats.TrackSingle(stats.AssigneesAdded, repo)
+}
+
+// openAssignmentCounter returns a reviewers.LoadFunc that uses the GitHub search API to count the open pull requests
+// each user is currently assigned to
+func openAssignmentCounter(config *config.GitXargsConfig) reviewers.LoadFunc {
+	return func(member string) (int, error) {
+		query := fmt.Sprintf("is:open is:pr assignee:%s", member)
+		result, _, err := config.GithubClient.Search.Issues(config.Context, query, nil)
+		if err != nil {
+			return 0, errors.WithStackTrace(err)
+		}
+
+		return result.GetTotal(), nil
+	}
+}
+
+// dedupeReviewers removes duplicate reviewers, as well as the supplied pull request author, preserving order
+func dedupeReviewers(selected []string, author string) []string {
+	seen := make(map[string]bool)
+	deduped := []string{}
+	for _, reviewer := range selected {
+		if seen[strings.ToLower(reviewer)] || strings.EqualFold(reviewer, author) {
+			continue
+		}
+		seen[strings.ToLower(reviewer)] = true
+		deduped = append(deduped, reviewer)
+	}
+	return deduped
+}
+
+// blameCandidate is the most recent commit in which a given author touched one of the lines git-xargs changed
+type blameCandidate struct {
+	author string
+	hash   plumbing.Hash
+	date   time.Time
+}
+
+// selectBlameReviewers runs git blame, as of the commit before git-xargs made its changes, over every file that
+// git-xargs modified or deleted, and returns the GitHub logins of the most recent human committers of the lines it
+// changed. Commit author emails are resolved to GitHub logins via the GitHub API, and bot accounts, as well as commits
+// that can't be looked up, are skipped
+func selectBlameReviewers(config *config.GitXargsConfig, repo *github.Repository, localRepository *git.Repository, commitHash plumbing.Hash) ([]string, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	commit, err := localRepository.CommitObject(commitHash)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	// A commit without a parent means the repo was empty before git-xargs ran, so there is nobody to blame
+	if commit.NumParents() == 0 {
+		return []string{}, nil
+	}
+
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	patch, err := parent.Patch(commit)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	latestByAuthor := make(map[string]blameCandidate)
+	for _, filePatch := range patch.FilePatches() {
+		from, _ := filePatch.Files()
+		// Files that git-xargs created did not exist in the parent commit, so they have no history to blame
+		if from == nil || filePatch.IsBinary() {
+			continue
+		}
+
+		blame, err := git.Blame(parent, from.Path())
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":    err,
+				"Repo":     repo.GetName(),
+				"Filepath": from.Path(),
+			}).Debug("Skipping git blame for file that can't be blamed")
+			continue
+		}
+
+		for _, lineNumber := range changedLines(filePatch) {
+			if lineNumber >= len(blame.Lines) {
+				continue
+			}
+			line := blame.Lines[lineNumber]
+			if existing, ok := latestByAuthor[line.Author]; !ok || line.Date.After(existing.date) {
+				latestByAuthor[line.Author] = blameCandidate{author: line.Author, hash: line.Hash, date: line.Date}
+			}
+		}
+	}
+
+	candidates := []blameCandidate{}
+	for _, candidate := range latestByAuthor {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].date.After(candidates[j].date)
+	})
+
+	selected := []string{}
+	for _, candidate := range candidates {
+		if len(selected) >= config.BlameReviewersCount {
+			break
+		}
+
+		remoteCommit, _, err := config.GithubClient.Repositories.GetCommit(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), candidate.hash.String())
+		if err != nil {
+			// The commit may not be on GitHub, e.g. if it was pushed to a fork, so the next committer is tried instead
+			logger.WithFields(logrus.Fields{
+				"Error":  err,
+				"Repo":   repo.GetName(),
+				"Commit": candidate.hash.String(),
+			}).Debug("Skipping blamed commit that can't be looked up on GitHub")
+			continue
+		}
+
+		login := remoteCommit.GetAuthor().GetLogin()
+		if login == "" || strings.HasSuffix(login, "[bot]") {
+			continue
+		}
+
+		selected = append(selected, login)
+		selected = dedupeReviewers(selected, "")
+	}
+
+	return selected, nil
+}
+
+// changedLines returns the lines, counting from 0, of the file before the supplied patch that the patch deletes or
+// replaces. Lines the patch only inserts have no history of their own, so the lines around them are returned instead
+func changedLines(filePatch diff.FilePatch) []int {
+	lines := []int{}
+	fromLine := 0
+	var previousType diff.Operation = diff.Equal
+	for _, chunk := range filePatch.Chunks() {
+		count := countLines(chunk.Content())
+		switch chunk.Type() {
+		case diff.Equal:
+			fromLine += count
+		case diff.Delete:
+			for i := 0; i < count; i++ {
+				lines = append(lines, fromLine+i)
+			}
+			fromLine += count
+		case diff.Add:
+			if previousType != diff.Delete {
+				if fromLine > 0 {
+					lines = append(lines, fromLine-1)
+				}
+				lines = append(lines, fromLine)
+			}
+		}
+		previousType = chunk.Type()
+	}
+	return lines
+}
+
+// countLines returns the number of lines in the supplied content of a chunk, whose last line may not end in a newline
+func countLines(content string) int {
+	count := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		count++
+	}
+	return count
+}
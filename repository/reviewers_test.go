@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/go-github/v32/github"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequestReviewersFromPool ensures reviewers are requested on pull requests when a --reviewers pool is configured
+func TestRequestReviewersFromPool(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.ReviewerPool = reviewers.NewPool([]string{"alice", "bob"}, reviewers.StrategyLeastLoaded, 1)
+
+	repo := mocks.GetMockGithubRepo()
+	requestReviewers(testConfig, repo, mocks.GetMockPullRequest(), nil, plumbing.ZeroHash)
+
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.ReviewersRequested), 1)
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.ReviewersRequestErr))
+}
+
+// TestResolveAssigneesExpandsTeams ensures teams passed via --assignees are expanded into their members, without
+// duplicating users that were also passed individually
+func TestResolveAssigneesExpandsTeams(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.Assignees = []string{"bob", "gruntwork-io/platform"}
+	testConfig.AssigneeStrategy = reviewers.StrategyAll
+
+	require.NoError(t, ResolveAssignees(testConfig))
+
+	selected, err := testConfig.AssigneePool.Next(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "alice"}, selected)
+
+	addAssignees(testConfig, mocks.GetMockGithubRepo(), mocks.GetMockPullRequest())
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.AssigneesAdded), 1)
+}
+
+// TestSelectBlameReviewers ensures the committers of the files changed by git-xargs are resolved to GitHub logins
+func TestSelectBlameReviewers(t *testing.T) {
+	t.Parallel()
+
+	localRepository, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	writeAndCommit := func(content string, author string) plumbing.Hash {
+		file, err := worktree.Filesystem.Create("README.md")
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		_, err = worktree.Add("README.md")
+		require.NoError(t, err)
+		hash, err := worktree.Commit("update README.md", &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: author + "@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return hash
+	}
+
+	writeAndCommit("hello\n", "carol")
+	commitHash := writeAndCommit("hello, world\n", "git-xargs")
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+
+	selected, err := selectBlameReviewers(testConfig, mocks.GetMockGithubRepo(), localRepository, commitHash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, selected)
+}
+
+// TestSelectBlameReviewersOfChangedLines ensures only the committers of the lines git-xargs changed are picked, and
+// that a blamed commit GitHub doesn't know of is skipped rather than failing the lookup
+func TestSelectBlameReviewersOfChangedLines(t *testing.T) {
+	t.Parallel()
+
+	localRepository, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	when := time.Now().Add(-time.Hour)
+	writeAndCommit := func(content string, author string) plumbing.Hash {
+		file, err := worktree.Filesystem.Create("main.tf")
+		require.NoError(t, err)
+		_, err = file.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		_, err = worktree.Add("main.tf")
+		require.NoError(t, err)
+		when = when.Add(time.Minute)
+		hash, err := worktree.Commit("update main.tf", &git.CommitOptions{
+			Author: &object.Signature{Name: author, Email: author + "@example.com", When: when},
+		})
+		require.NoError(t, err)
+		return hash
+	}
+
+	// carol wrote the file, dave changed its second line, erin its last line, and frank its fourth line most recently
+	carol := writeAndCommit("one\ntwo\nthree\nfour\nfive\n", "carol")
+	dave := writeAndCommit("one\nTWO\nthree\nfour\nfive\n", "dave")
+	writeAndCommit("one\nTWO\nthree\nfour\nFIVE\n", "erin")
+	writeAndCommit("one\nTWO\nthree\nFOUR\nFIVE\n", "frank")
+	// git-xargs changes the second and last line
+	commitHash := writeAndCommit("one\n2\nthree\nFOUR\n5\n", "git-xargs")
+
+	repositoryCommit := func(login string) *github.RepositoryCommit {
+		return &github.RepositoryCommit{Author: &github.User{Login: github.String(login)}}
+	}
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.BlameReviewersCount = 3
+	// erin's commit isn't on GitHub
+	testConfig.GithubClient = mocks.ConfigureMockGithubClientWithRepositoryCommits(map[string]*github.RepositoryCommit{
+		carol.String(): repositoryCommit("carol"),
+		dave.String():  repositoryCommit("dave"),
+	})
+
+	selected, err := selectBlameReviewers(testConfig, mocks.GetMockGithubRepo(), localRepository, commitHash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave"}, selected)
+}
+
+// TestExcludedReviewRequests ensures only the excluded users and teams among the requested reviewers of a pull request,
+// e.g. its code owners, are withdrawn
+func TestExcludedReviewRequests(t *testing.T) {
+	t.Parallel()
+
+	requested := &github.Reviewers{
+		Users: []*github.User{{Login: github.String("alice")}, {Login: github.String("Bob")}},
+		Teams: []*github.Team{{Slug: github.String("platform")}, {Slug: github.String("security")}},
+	}
+	reviewersRequest := excludedReviewRequests(requested, "gruntwork-io", []string{"bob", "gruntwork-io/security", "other-org/platform"})
+	assert.Equal(t, []string{"Bob"}, reviewersRequest.Reviewers)
+	assert.Equal(t, []string{"security"}, reviewersRequest.TeamReviewers)
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.ExcludeReviewers = []string{"bob"}
+	removeExcludedReviewers(testConfig, mocks.GetMockGithubRepo(), mocks.GetMockPullRequest())
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.ReviewersRequestErr))
+}
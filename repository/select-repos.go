@@ -1,15 +1,17 @@
 package repository
 
 import (
+	"context"
 	"github.com/google/go-github/v32/github"
 	"github.com/gruntwork-io/git-xargs/auth"
+	"github.com/gruntwork-io/git-xargs/common"
 	"github.com/gruntwork-io/git-xargs/config"
 	"github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/logging"
 	"github.com/gruntwork-io/git-xargs/stats"
 	"github.com/gruntwork-io/git-xargs/types"
 	"github.com/gruntwork-io/git-xargs/util"
 	"github.com/gruntwork-io/go-commons/errors"
-	"github.com/gruntwork-io/go-commons/logging"
 
 	"github.com/sirupsen/logrus"
 )
@@ -167,9 +169,9 @@ func selectReposViaRepoFlag(inputRepos []string) ([]*types.AllowedRepo, []string
 }
 
 // fetchUserProvidedReposViaGithub converts repos provided as strings, already validated as being well-formed, into GitHub API repo objects that can be further processed
-func fetchUserProvidedReposViaGithubAPI(githubClient auth.GithubClient, rs RepoSelection, stats *stats.RunStats) ([]*github.Repository, error) {
+func fetchUserProvidedReposViaGithubAPI(ctx context.Context, githubClient auth.GithubClient, hostClients map[string]auth.GithubClient, rs RepoSelection, stats *stats.RunStats) ([]*github.Repository, error) {
 	ar := rs.GetAllowedRepos()
-	return getFileDefinedRepos(githubClient, ar, stats)
+	return getFileDefinedRepos(ctx, githubClient, hostClients, ar, stats)
 
 }
 
@@ -185,7 +187,35 @@ func fetchUserProvidedReposViaGithubAPI(githubClient auth.GithubClient, rs RepoS
 // library. Therefore, this function serves the purpose of creating that uniform interface, by looking up flat file-provided
 // repos via go-github, so that we're only ever dealing with pointers to github.Repositories going forward.
 func OperateOnRepos(config *config.GitXargsConfig) error {
+	reposToIterate, err := SelectRepos(config)
+	if err != nil {
+		return err
+	}
+
+	// With --dry-run-level list-only, listing the selected repos is all there is to do
+	if config.DryRunLevel == common.DryRunListOnly {
+		listReposForDryRun(config, reposToIterate)
+		return nil
+	}
+
+	// Warn upfront if processing the selected repos is going to exhaust the GitHub API rate limit
+	checkRateLimitBudget(config, reposToIterate)
+
+	// Record the selected repos in the state store before touching any of them
+	if err := config.State.RecordSelectedRepos(config.RunID, reposToIterate); err != nil {
+		return err
+	}
+
+	// Now that we've gathered the repos we're going to operate on, do the actual processing by running the
+	// user-defined scripts against each repo and handling the resulting git operations that follow. If any of them
+	// failed, this returns a types.ProcessReposErr with the error of each
+	return ProcessRepos(config, reposToIterate)
+}
 
+// SelectRepos resolves the user-supplied repo selection flags into the GitHub API repo objects that should be operated
+// on, tracking the selected repos in the run stats. It is shared by the main processing routine and by subcommands
+// such as ready that act on repos without running a command against them.
+func SelectRepos(config *config.GitXargsConfig) ([]*github.Repository, error) {
 	logger := logging.GetLogger("git-xargs")
 
 	// The set of GitHub repositories the tool will actually process
@@ -195,7 +225,7 @@ func OperateOnRepos(config *config.GitXargsConfig) error {
 	repoSelection, err := selectReposViaInput(config)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	switch repoSelection.GetCriteria() {
@@ -209,7 +239,7 @@ func OperateOnRepos(config *config.GitXargsConfig) error {
 				"Error":        err,
 				"Organization": config.GithubOrg,
 			}).Debug("Failure looking up repos for organization")
-			return err
+			return nil, err
 		}
 		// We gather all the repos by fetching them from the GitHub API, paging through the results of the supplied organization
 		reposToIterate = reposFetchedFromGithubAPI
@@ -217,28 +247,47 @@ func OperateOnRepos(config *config.GitXargsConfig) error {
 		logger.Debugf("Using Github org: %s as source of repositories. Paging through Github API for repos.", config.GithubOrg)
 
 	case ReposFilePath:
-		githubRepos, err := fetchUserProvidedReposViaGithubAPI(config.GithubClient, *repoSelection, config.Stats)
+		githubRepos, err := fetchUserProvidedReposViaGithubAPI(config.Context, config.GithubClient, config.GithubHostClients, *repoSelection, config.Stats)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		reposToIterate = githubRepos
 
 		// Update count of number of repos the tool read in from the provided file
 		config.Stats.SetFileProvidedRepos(repoSelection.GetAllowedRepos())
+		recordRepoBaseBranches(config, repoSelection.GetAllowedRepos())
 
 	case ExplicitReposOnCommandLine, ReposViaStdIn:
-		githubRepos, err := fetchUserProvidedReposViaGithubAPI(config.GithubClient, *repoSelection, config.Stats)
+		githubRepos, err := fetchUserProvidedReposViaGithubAPI(config.Context, config.GithubClient, config.GithubHostClients, *repoSelection, config.Stats)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		reposToIterate = githubRepos // Update the count of number of repos the tool read in from explicit --repo flags
 		config.Stats.SetRepoFlagProvidedRepos(repoSelection.GetAllowedRepos())
+		recordRepoBaseBranches(config, repoSelection.GetAllowedRepos())
 
 	default:
 		// We've got no repos to iterate on, so return an error
-		return errors.WithStackTrace(types.NoValidReposFoundAfterFilteringErr{})
+		return nil, errors.WithStackTrace(types.NoValidReposFoundAfterFilteringErr{})
+	}
+
+	// If --plugin loaded any repo selectors, let them narrow down or add to the selected repos
+	reposToIterate, err = selectReposViaPlugins(config, reposToIterate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Leave out the repos whose owners opted them out of git-xargs, including any a repo selector added
+	reposToIterate = dropOptedOutRepos(config, reposToIterate)
+
+	// If --pick was passed, let the user narrow down the selected repos on the terminal before any of them is touched
+	if config.Pick {
+		reposToIterate, err = pickRepos(config, reposToIterate)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Track the repos selected for processing
@@ -250,11 +299,6 @@ func OperateOnRepos(config *config.GitXargsConfig) error {
 			"Repository": repo.GetName(),
 		}).Debug("Repo will have all targeted scripts run against it")
 	}
-	// Now that we've gathered the repos we're going to operate on, do the actual processing by running the
-	// user-defined scripts against each repo and handling the resulting git operations that follow
-	if err := ProcessRepos(config, reposToIterate); err != nil {
-		return err
-	}
 
-	return nil
+	return reposToIterate, nil
 }
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gruntwork-io/git-xargs/config"
 	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
 	"github.com/stretchr/testify/require"
 
 	"github.com/stretchr/testify/assert"
@@ -51,8 +53,13 @@ func TestOperateOnRepos(t *testing.T) {
 	testConfig.GithubOrg = "gruntwork-io"
 	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
 
+	// The mocked repos can't be cloned, so each of them is returned as failed rather than the failures being dropped
 	err := OperateOnRepos(testConfig)
-	assert.NoError(t, err)
+	processReposErr, isProcessReposErr := errors.Unwrap(err).(types.ProcessReposErr)
+	require.True(t, isProcessReposErr)
+	assert.Equal(t, 4, processReposErr.Total)
+	assert.Len(t, processReposErr.RepoErrors, 4)
+	assert.Contains(t, processReposErr.RepoErrors, "gruntwork-io/fetch")
 
 	configReposOnCommandLine := config.NewGitXargsTestConfig()
 	configReposOnCommandLine.GithubClient = mocks.ConfigureMockGithubClient()
@@ -60,7 +67,9 @@ func TestOperateOnRepos(t *testing.T) {
 	configReposOnCommandLine.RepoSlice = []string{"gruntwork-io/fetch", "gruntwork-io/cloud-nuke"}
 
 	cmdLineErr := OperateOnRepos(configReposOnCommandLine)
-	assert.NoError(t, cmdLineErr)
+	processReposErr, isProcessReposErr = errors.Unwrap(cmdLineErr).(types.ProcessReposErr)
+	require.True(t, isProcessReposErr)
+	assert.Equal(t, 2, processReposErr.Total)
 }
 
 // TestGetPreferredOrderOfRepoSelections ensures the getPreferredOrderOfRepoSelections returns the expected method
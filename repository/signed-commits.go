@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"net/http"
+
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/sirupsen/logrus"
+)
+
+// requiredSignaturesRule is the type of the ruleset rule that requires the commits pushed to a branch to be signed
+const requiredSignaturesRule = "required_signatures"
+
+// skipRepoRequiringSignedCommits returns true if --check-branch-protection was passed and the base branch of the repo of
+// the supplied job requires signed commits, either via its branch protection or a ruleset, in which case the repo is
+// tracked as skipped before any work is done on it. Without --signing-key, the commits git-xargs pushes through git
+// aren't signed, so their pull requests could never be merged, and pushing them straight to the base branch would be
+// rejected. Repos changed via the Contents API aren't checked, since GitHub signs the commits it makes
+func skipRepoRequiringSignedCommits(job *repoJob) bool {
+	config, repo := job.config, job.repo
+	if !config.CheckBranchProtection || config.SigningKey != nil {
+		return false
+	}
+
+	logger := logging.GetLogger("git-xargs")
+
+	baseBranch := config.BaseBranchName
+	if baseBranch == "" {
+		baseBranch = repo.GetDefaultBranch()
+	}
+
+	required := job.requireSignedCommits
+	if !required && config.GithubClient.Rules != nil {
+		rules, resp, err := config.GithubClient.Rules.GetRulesForBranch(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), baseBranch)
+		// Servers that predate rulesets don't have the endpoint. Any other error is logged without failing the repo,
+		// which GitHub still enforces the rules on
+		if err != nil && (resp == nil || resp.Response == nil || resp.StatusCode != http.StatusNotFound) {
+			logger.WithFields(logrus.Fields{
+				"Error":  err,
+				"Repo":   repo.GetName(),
+				"Branch": baseBranch,
+			}).Debug("Error looking up the rulesets of the base branch")
+		}
+		for _, rule := range rules {
+			if rule.Type == requiredSignaturesRule {
+				required = true
+			}
+		}
+	}
+	if !required {
+		return false
+	}
+
+	logger.WithFields(logrus.Fields{
+		"Repo name": repo.GetName(),
+		"Branch":    baseBranch,
+	}).Warn("Skipping repo because its base branch requires signed commits. Pass --signing-key to sign the commits")
+
+	config.Stats.TrackSingle(stats.BaseBranchRequiresSignedCommits, repo)
+	return true
+}
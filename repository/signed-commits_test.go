@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+)
+
+// Test that repos whose base branch requires signed commits via a ruleset are skipped with --check-branch-protection,
+// while repos whose rulesets don't, or can't be looked up, are processed, as are all repos if the commits are signed
+func TestSkipRepoRequiringSignedCommits(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		rules  mocks.MockGithubRulesService
+		expect bool
+	}{
+		{"no rulesets", mocks.MockGithubRulesService{}, false},
+		{"other rules", mocks.MockGithubRulesService{Rules: []*types.BranchRule{{Type: "pull_request"}, {Type: "non_fast_forward"}}}, false},
+		{"required signatures", mocks.MockGithubRulesService{Rules: []*types.BranchRule{{Type: "pull_request"}, {Type: "required_signatures"}}}, true},
+		{"lookup error", mocks.MockGithubRulesService{Err: errors.New("boom")}, false},
+	}
+
+	requiredSignatures := mocks.MockGithubRulesService{Rules: []*types.BranchRule{{Type: "required_signatures"}}}
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.GithubClient.Rules = requiredSignatures
+	assert.False(t, skipRepoRequiringSignedCommits(&repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}))
+
+	signingKey, err := openpgp.NewEntity("git-xargs", "", "git-xargs@example.com", nil)
+	require.NoError(t, err)
+	testConfig.CheckBranchProtection = true
+	testConfig.SigningKey = signingKey
+	assert.False(t, skipRepoRequiringSignedCommits(&repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}))
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			testConfig := config.NewGitXargsTestConfig()
+			testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+			testConfig.GithubClient.Rules = testCase.rules
+			testConfig.CheckBranchProtection = true
+
+			job := &repoJob{repo: mocks.GetMockGithubRepo(), config: testConfig}
+			assert.Equal(t, testCase.expect, skipRepoRequiringSignedCommits(job))
+
+			expectedSkips := 0
+			if testCase.expect {
+				expectedSkips = 1
+			}
+			assert.Len(t, testConfig.Stats.GetMultiple(stats.BaseBranchRequiresSignedCommits), expectedSkips)
+		})
+	}
+}
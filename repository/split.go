@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// changePart identifies one of the pull requests that a repo's changes were split across, either because they touched
+// too many files or because they were made to several directories of a monorepo. The zero value denotes changes that
+// were not split
+type changePart struct {
+	Index int
+	Total int
+	// Directory is the monorepo directory whose changes the part holds, when pull requests are opened per directory
+	Directory string
+	// Directories are the monorepo directories changed by a combined pull request, with one commit each
+	Directories []string
+	// BaseBranch is the branch to open the part's pull request against, rather than the base branch of the run
+	BaseBranch string
+}
+
+// isSplit returns true if this part is one of several
+func (part changePart) isSplit() bool {
+	return part.Total > 1
+}
+
+// branchName returns the name of the branch that holds this part's changes
+func (part changePart) branchName(config *config.GitXargsConfig) string {
+	if part.Directory != "" {
+		return fmt.Sprintf("%s-%s", config.BranchName, directorySlug(part.Directory))
+	}
+	if !part.isSplit() {
+		return config.BranchName
+	}
+	return fmt.Sprintf("%s-part-%d", config.BranchName, part.Index)
+}
+
+// decorate adds the part number to the supplied pull request title and description, so reviewers know they are
+// looking at one of several related pull requests
+func (part changePart) decorate(config *config.GitXargsConfig, title string, description string) (string, string) {
+	if part.Directory != "" {
+		title = fmt.Sprintf("%s (%s)", title, part.Directory)
+		description = fmt.Sprintf("%s\n\nThis pull request changes `%s`. It is %d of %d stacked pull requests, one per directory of the monorepo, which should be merged in order.", description, part.Directory, part.Index, part.Total)
+		return title, description
+	}
+	if len(part.Directories) > 0 {
+		description = fmt.Sprintf("%s\n\nThis pull request changes these directories of the monorepo, with one commit each:\n", description)
+		for _, directory := range part.Directories {
+			description = fmt.Sprintf("%s\n- `%s`", description, directory)
+		}
+		return title, description
+	}
+	if !part.isSplit() {
+		return title, description
+	}
+	title = fmt.Sprintf("%s (part %d of %d)", title, part.Index, part.Total)
+	description = fmt.Sprintf("%s\n\nThis is part %d of %d of this change, which was split across several pull requests because it touched more than %d files.", description, part.Index, part.Total, config.MaxFilesPerPR)
+	return title, description
+}
+
+// reportName returns the name this part's pull request is listed under in the run report
+func (part changePart) reportName(repo *github.Repository) string {
+	key := util.RepoKey(repo)
+	if part.Directory != "" {
+		return fmt.Sprintf("%s (%s)", key, part.Directory)
+	}
+	if !part.isSplit() {
+		return key
+	}
+	return fmt.Sprintf("%s (part %d of %d)", key, part.Index, part.Total)
+}
+
+// fileSnapshot holds the contents of a file changed by the command, so that it can be written back to the worktree
+// when the part containing it is committed
+type fileSnapshot struct {
+	Deleted bool
+	Mode    os.FileMode
+	Content []byte
+}
+
+// shouldSplitChanges returns true if --max-files-per-pull-request was passed and the changes in the supplied worktree
+// status touch more files than that. Changes are never split when they are committed directly to the branch
+func shouldSplitChanges(config *config.GitXargsConfig, status git.Status) bool {
+	if config.MaxFilesPerPR <= 0 || config.SkipPullRequests {
+		return false
+	}
+	return len(status) > config.MaxFilesPerPR
+}
+
+// splitChangedFiles groups the supplied file paths into parts of at most maxFiles files each. When splitting by
+// directory, files in the same directory are kept together wherever the directory fits within a single part. When
+// splitting by file, or when a directory holds more than maxFiles files, files are split in path order
+func splitChangedFiles(paths []string, maxFiles int, splitBy string) [][]string {
+	sorted := append([]string{}, paths...)
+	sort.Strings(sorted)
+
+	if splitBy == common.SplitByFile {
+		return chunkFiles(sorted, maxFiles)
+	}
+
+	// Group the files by directory, keeping the directories in path order
+	directories := []string{}
+	filesByDirectory := map[string][]string{}
+	for _, file := range sorted {
+		directory := path.Dir(file)
+		if _, ok := filesByDirectory[directory]; !ok {
+			directories = append(directories, directory)
+		}
+		filesByDirectory[directory] = append(filesByDirectory[directory], file)
+	}
+	sort.Strings(directories)
+
+	parts := [][]string{}
+	current := []string{}
+	for _, directory := range directories {
+		files := filesByDirectory[directory]
+
+		if len(current)+len(files) > maxFiles && len(current) > 0 {
+			parts = append(parts, current)
+			current = []string{}
+		}
+
+		if len(files) > maxFiles {
+			parts = append(parts, chunkFiles(files, maxFiles)...)
+			continue
+		}
+
+		current = append(current, files...)
+	}
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+
+	return parts
+}
+
+// chunkFiles splits the supplied file paths into consecutive chunks of at most maxFiles files each
+func chunkFiles(files []string, maxFiles int) [][]string {
+	chunks := [][]string{}
+	for start := 0; start < len(files); start += maxFiles {
+		end := start + maxFiles
+		if end > len(files) {
+			end = len(files)
+		}
+		chunks = append(chunks, files[start:end])
+	}
+	return chunks
+}
+
+// snapshotChanges reads every file in the supplied worktree status into memory, recording deleted files as such
+func snapshotChanges(repositoryDir string, status git.Status) (map[string]fileSnapshot, error) {
+	snapshot := map[string]fileSnapshot{}
+	for file := range status {
+		fullPath := filepath.Join(repositoryDir, filepath.FromSlash(file))
+
+		info, err := os.Lstat(fullPath)
+		if os.IsNotExist(err) {
+			snapshot[file] = fileSnapshot{Deleted: true}
+			continue
+		}
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+
+		content, err := ioutil.ReadFile(fullPath)
+		if err != nil {
+			return nil, errors.WithStackTrace(err)
+		}
+		snapshot[file] = fileSnapshot{Mode: info.Mode().Perm(), Content: content}
+	}
+	return snapshot, nil
+}
+
+// restoreChanges writes the snapshotted changes to the supplied files back into the worktree
+func restoreChanges(repositoryDir string, snapshot map[string]fileSnapshot, files []string) error {
+	for _, file := range files {
+		fullPath := filepath.Join(repositoryDir, filepath.FromSlash(file))
+		change := snapshot[file]
+
+		if change.Deleted {
+			if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+				return errors.WithStackTrace(err)
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return errors.WithStackTrace(err)
+		}
+		if err := ioutil.WriteFile(fullPath, change.Content, change.Mode); err != nil {
+			return errors.WithStackTrace(err)
+		}
+		// WriteFile only applies the mode to new files, so make sure modified files keep theirs too
+		if err := os.Chmod(fullPath, change.Mode); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	return nil
+}
+
+// updateRepoInParts splits the changes in the supplied worktree status into parts of at most
+// --max-files-per-pull-request files, and for each part creates a branch from the current HEAD, commits only that
+// part's files and pushes the branch. It returns the pushed branches, whose pull requests are left to open
+func updateRepoInParts(config *config.GitXargsConfig, repositoryDir string, worktree *git.Worktree, remoteRepository *github.Repository, localRepository *git.Repository, status git.Status) ([]pendingPullRequest, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	head, err := localRepository.Head()
+	if err != nil {
+		config.Stats.TrackSingle(stats.GetHeadRefFailed, remoteRepository)
+		return nil, errors.WithStackTrace(err)
+	}
+
+	snapshot, err := snapshotChanges(repositoryDir, status)
+	if err != nil {
+		config.Stats.TrackSingle(stats.WorktreeStatusCheckFailedCommand, remoteRepository)
+		return nil, err
+	}
+
+	// Untracked files survive the resets below, so remove them up front. Each is written back with its part
+	files := []string{}
+	for file := range status {
+		files = append(files, file)
+		if status.IsUntracked(file) {
+			if err := os.Remove(filepath.Join(repositoryDir, filepath.FromSlash(file))); err != nil {
+				config.Stats.TrackSingle(stats.WorktreeAddFileFailed, remoteRepository)
+				return nil, errors.WithStackTrace(err)
+			}
+		}
+	}
+
+	parts := splitChangedFiles(files, config.MaxFilesPerPR, config.SplitBy)
+	pendingPullRequests := []pendingPullRequest{}
+
+	logger.WithFields(logrus.Fields{
+		"Repo":  remoteRepository.GetName(),
+		"Files": len(files),
+		"Parts": len(parts),
+	}).Debug("Splitting changes across several pull requests")
+
+	config.Stats.TrackSingle(stats.ChangesSplitAcrossPullRequests, remoteRepository)
+
+	for i, partFiles := range parts {
+		part := changePart{Index: i + 1, Total: len(parts)}
+		branchName := plumbing.NewBranchReferenceName(part.branchName(config))
+
+		// Start each part from a clean copy of the original HEAD, on a branch of its own
+		co := &git.CheckoutOptions{
+			Hash:   head.Hash(),
+			Branch: branchName,
+			Create: true,
+			Force:  true,
+		}
+		if err := worktree.Checkout(co); err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":       err,
+				"Branch Name": branchName,
+				"Repo":        remoteRepository.GetName(),
+			}).Debug("Error creating branch for part of the changes")
+
+			config.Stats.TrackSingle(stats.BranchCheckoutFailed, remoteRepository)
+			return nil, errors.WithStackTrace(err)
+		}
+
+		if err := restoreChanges(repositoryDir, snapshot, partFiles); err != nil {
+			config.Stats.TrackSingle(stats.WorktreeAddFileFailed, remoteRepository)
+			return nil, err
+		}
+
+		partStatus, err := worktree.Status()
+		if err != nil {
+			config.Stats.TrackSingle(stats.WorktreeStatusCheckFailedCommand, remoteRepository)
+			return nil, errors.WithStackTrace(err)
+		}
+
+		commitHash, err := commitLocalChanges(partStatus, config, repositoryDir, worktree, remoteRepository, localRepository)
+		if err != nil {
+			return nil, err
+		}
+
+		pushedHash, err := pushLocalBranch(config, remoteRepository, localRepository, branchName.String())
+		if err != nil {
+			return nil, err
+		}
+
+		setCommitStatus(config, remoteRepository, pushedHash)
+
+		pendingPullRequests = append(pendingPullRequests, pendingPullRequest{commitHash: commitHash, branchName: branchName.String(), part: part})
+	}
+
+	return pendingPullRequests, nil
+}
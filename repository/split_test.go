@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	files := []string{"b/2.txt", "a/1.txt", "a/2.txt", "c/1.txt", "c/2.txt", "c/3.txt", "root.txt"}
+
+	byDirectory := splitChangedFiles(files, 2, common.SplitByDirectory)
+	assert.Equal(t, [][]string{
+		{"root.txt"},
+		{"a/1.txt", "a/2.txt"},
+		{"b/2.txt"},
+		{"c/1.txt", "c/2.txt"},
+		{"c/3.txt"},
+	}, byDirectory)
+
+	byFile := splitChangedFiles(files, 3, common.SplitByFile)
+	assert.Equal(t, [][]string{
+		{"a/1.txt", "a/2.txt", "b/2.txt"},
+		{"c/1.txt", "c/2.txt", "c/3.txt"},
+		{"root.txt"},
+	}, byFile)
+}
+
+// Test that changes touching more than --max-files-per-pull-request files are committed to one branch per part, each
+// created from the original HEAD and holding only that part's files
+func TestUpdateRepoInParts(t *testing.T) {
+	t.Parallel()
+
+	repositoryDir, err := ioutil.TempDir("", "git-xargs-split-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(repositoryDir)
+
+	localRepository, err := git.PlainInit(repositoryDir, false)
+	require.NoError(t, err)
+	worktree, err := localRepository.Worktree()
+	require.NoError(t, err)
+
+	writeFile := func(name string, content string) {
+		fullPath := filepath.Join(repositoryDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, ioutil.WriteFile(fullPath, []byte(content), 0644))
+	}
+
+	writeFile("a/existing.txt", "original")
+	writeFile("b/removed.txt", "original")
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	baseHash, err := worktree.Commit("initial", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	// Simulate a command that modifies, deletes and adds files
+	writeFile("a/existing.txt", "modified")
+	writeFile("a/new.txt", "new")
+	require.NoError(t, os.Remove(filepath.Join(repositoryDir, "b/removed.txt")))
+	writeFile("c/new.txt", "new")
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.DryRun = true
+	testConfig.MaxFilesPerPR = 2
+
+	status, err := worktree.Status()
+	require.NoError(t, err)
+	require.True(t, shouldSplitChanges(testConfig, status))
+
+	repo := mocks.GetMockGithubRepo()
+	pendingPullRequests, err := updateRepoInParts(testConfig, repositoryDir, worktree, repo, localRepository, status)
+	require.NoError(t, err)
+	require.Len(t, pendingPullRequests, 2)
+	assert.Equal(t, changePart{Index: 2, Total: 2}, pendingPullRequests[1].part)
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.ChangesSplitAcrossPullRequests), 1)
+
+	expectedParts := map[int][]string{
+		1: {"a/existing.txt", "a/new.txt", "b/removed.txt"},
+		2: {"a/existing.txt", "c/new.txt"},
+	}
+	expectedContents := map[int]map[string]string{
+		1: {"a/existing.txt": "modified", "a/new.txt": "new", "b/removed.txt": "original"},
+		2: {"a/existing.txt": "original", "c/new.txt": "new"},
+	}
+
+	for index, expectedFiles := range expectedParts {
+		part := changePart{Index: index, Total: 2}
+		ref, err := localRepository.Reference(plumbing.NewBranchReferenceName(part.branchName(testConfig)), true)
+		require.NoError(t, err)
+
+		commit, err := localRepository.CommitObject(ref.Hash())
+		require.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{baseHash}, commit.ParentHashes)
+
+		tree, err := commit.Tree()
+		require.NoError(t, err)
+
+		files := []string{}
+		require.NoError(t, tree.Files().ForEach(func(file *object.File) error {
+			files = append(files, file.Name)
+			content, err := file.Contents()
+			require.NoError(t, err)
+			assert.Equal(t, expectedContents[index][file.Name], content, file.Name)
+			return nil
+		}))
+		assert.ElementsMatch(t, expectedFiles, files)
+	}
+}
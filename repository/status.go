@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PullRequestStateOpen denotes an open pull request that is ready for review
+	PullRequestStateOpen = "open"
+	// PullRequestStateDraft denotes an open draft pull request
+	PullRequestStateDraft = "draft"
+	// PullRequestStateMerged denotes a merged pull request
+	PullRequestStateMerged = "merged"
+	// PullRequestStateClosed denotes a pull request that was closed without being merged
+	PullRequestStateClosed = "closed"
+
+	// ReviewApproved denotes a pull request approved by at least one reviewer, with no outstanding change requests
+	ReviewApproved = "approved"
+	// ReviewChangesRequested denotes a pull request on which at least one reviewer requested changes
+	ReviewChangesRequested = "changes requested"
+	// ReviewPending denotes a pull request that has not been approved or had changes requested yet
+	ReviewPending = "pending"
+)
+
+// GetRunStatus looks up every pull request the run passed via --run-id opened in the state store, and queries GitHub
+// for its current state, checks and review state
+func GetRunStatus(config *config.GitXargsConfig) ([]types.PullRequestStatus, error) {
+	logger := logging.GetLogger("git-xargs")
+
+	statuses := []types.PullRequestStatus{}
+	err := forEachRunPullRequest(config, config.RunID, func(repo *github.Repository, recordedPR state.PullRequest) {
+		var status types.PullRequestStatus
+		repoConfig, err := withRepoHost(config, repo)
+		if err == nil {
+			status, err = getPullRequestStatus(repoConfig, repo, recordedPR.Number)
+		}
+		if err != nil {
+			logger.WithFields(logrus.Fields{
+				"Error":            err,
+				"Pull Request URL": recordedPR.URL,
+			}).Debug("Error looking up pull request status")
+
+			status = types.PullRequestStatus{Repo: repo.GetOwner().GetLogin() + "/" + repo.GetName(), Number: recordedPR.Number, URL: recordedPR.URL, State: "unknown"}
+		}
+		statuses = append(statuses, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Repo < statuses[j].Repo
+	})
+
+	return statuses, nil
+}
+
+// forEachRunPullRequest calls the supplied function with every pull request recorded in the state store for the run
+// with the supplied ID, along with the repo it was opened against. API calls about the repo go through the config
+// withRepoHost returns for it, which talks to the GitHub host it is on
+func forEachRunPullRequest(config *config.GitXargsConfig, runID string, fn func(repo *github.Repository, recordedPR state.PullRequest)) error {
+	repos, err := config.State.ListRepos(runID)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range repos {
+		for _, recordedPR := range record.PullRequests {
+			// State files written before the URL of each repo was recorded only have the URLs of its pull requests, which
+			// are on the same host as the repo
+			repoURL := record.URL
+			if repoURL == "" {
+				repoURL = strings.TrimSuffix(recordedPR.URL, fmt.Sprintf("/pull/%d", recordedPR.Number))
+			}
+
+			repo := &github.Repository{
+				Owner:   &github.User{Login: github.String(record.Owner)},
+				Name:    github.String(record.Name),
+				HTMLURL: github.String(repoURL),
+			}
+			fn(repo, recordedPR)
+		}
+	}
+
+	return nil
+}
+
+// getPullRequestStatus queries GitHub for the current state, checks and review state of the supplied pull request
+func getPullRequestStatus(config *config.GitXargsConfig, repo *github.Repository, number int) (types.PullRequestStatus, error) {
+	owner := repo.GetOwner().GetLogin()
+
+	pr, _, err := config.GithubClient.PullRequests.Get(config.Context, owner, repo.GetName(), number)
+	if err != nil {
+		return types.PullRequestStatus{}, errors.WithStackTrace(err)
+	}
+
+	checks, err := getChecksState(config, repo, pr.GetHead().GetSHA())
+	if err != nil {
+		return types.PullRequestStatus{}, err
+	}
+
+	review, err := getReviewState(config, repo, number)
+	if err != nil {
+		return types.PullRequestStatus{}, err
+	}
+
+	return types.PullRequestStatus{
+		Repo:   owner + "/" + repo.GetName(),
+		Number: number,
+		State:  getPullRequestState(pr),
+		Checks: checks,
+		Review: review,
+		URL:    pr.GetHTMLURL(),
+	}, nil
+}
+
+// getPullRequestState returns whether the supplied pull request is open, a draft, merged or closed
+func getPullRequestState(pr *github.PullRequest) string {
+	switch {
+	case pr.GetMerged() || pr.MergedAt != nil:
+		return PullRequestStateMerged
+	case pr.GetState() == "closed":
+		return PullRequestStateClosed
+	case pr.GetDraft():
+		return PullRequestStateDraft
+	}
+	return PullRequestStateOpen
+}
+
+// getReviewState combines the latest review of every reviewer of the supplied pull request into a single state.
+// Comments don't change a reviewer's verdict, and a single outstanding change request outweighs any approvals
+func getReviewState(config *config.GitXargsConfig, repo *github.Repository, number int) (string, error) {
+	latestByReviewer := map[string]string{}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := config.GithubClient.PullRequests.ListReviews(config.Context, repo.GetOwner().GetLogin(), repo.GetName(), number, opts)
+		if err != nil {
+			return "", errors.WithStackTrace(err)
+		}
+
+		// Reviews are returned oldest first, so later reviews overwrite earlier ones
+		for _, review := range reviews {
+			switch review.GetState() {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				latestByReviewer[review.GetUser().GetLogin()] = review.GetState()
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	approved := false
+	for _, state := range latestByReviewer {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return ReviewChangesRequested, nil
+		case "APPROVED":
+			approved = true
+		}
+	}
+
+	if approved {
+		return ReviewApproved, nil
+	}
+	return ReviewPending, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStateStore returns a state store in a temporary directory holding a run with the supplied ID, which opened a
+// single pull request against the mock repo
+func newTestStateStore(t *testing.T, runID string) (*state.Store, func()) {
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+
+	store, err := state.Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+
+	repo := mocks.GetMockGithubRepo()
+	require.NoError(t, store.StartRun(state.Run{ID: runID}))
+	require.NoError(t, store.RecordSelectedRepos(runID, []*github.Repository{repo}))
+	require.NoError(t, store.RecordPullRequest(runID, repo, state.PullRequest{Number: 1, URL: mocks.GetMockPullRequest().GetHTMLURL()}))
+
+	return store, func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGetRunStatus(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.RunID = "run-1"
+
+	store, cleanup := newTestStateStore(t, testConfig.RunID)
+	defer cleanup()
+	testConfig.State = store
+
+	statuses, err := GetRunStatus(testConfig)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+
+	assert.Equal(t, "gruntwork-io/terragrunt", statuses[0].Repo)
+	assert.Equal(t, PullRequestStateOpen, statuses[0].State)
+	assert.Equal(t, ChecksPassing, statuses[0].Checks)
+	assert.Equal(t, ReviewApproved, statuses[0].Review)
+}
+
+// Test that a pull request recorded on a GitHub Enterprise Server is looked up on that server
+func TestForEachRunPullRequestKeepsTheHostOfThePullRequest(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := state.Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	repo := &github.Repository{
+		Owner:   &github.User{Login: github.String("platform")},
+		Name:    github.String("api"),
+		HTMLURL: github.String("https://github.example.com/platform/api"),
+	}
+	require.NoError(t, store.StartRun(state.Run{ID: "run-1"}))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{repo}))
+	require.NoError(t, store.RecordPullRequest("run-1", repo, state.PullRequest{Number: 7, URL: "https://github.example.com/platform/api/pull/7"}))
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.State = store
+
+	hosts := []string{}
+	require.NoError(t, forEachRunPullRequest(testConfig, "run-1", func(repo *github.Repository, recordedPR state.PullRequest) {
+		hosts = append(hosts, util.RepoHost(repo))
+	}))
+	assert.Equal(t, []string{"github.example.com"}, hosts)
+}
+
+func TestGetPullRequestState(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, PullRequestStateMerged, getPullRequestState(&github.PullRequest{State: github.String("closed"), Merged: github.Bool(true)}))
+	assert.Equal(t, PullRequestStateClosed, getPullRequestState(&github.PullRequest{State: github.String("closed")}))
+	assert.Equal(t, PullRequestStateDraft, getPullRequestState(&github.PullRequest{State: github.String("open"), Draft: github.Bool(true)}))
+	assert.Equal(t, PullRequestStateOpen, getPullRequestState(&github.PullRequest{State: github.String("open")}))
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/types"
+)
+
+// descriptionWithFooter appends the rendered --pull-request-footer to the supplied pull request description, separated
+// by a horizontal rule so it stays visually distinct from the description itself
+func descriptionWithFooter(description string, footer string) string {
+	if strings.TrimSpace(footer) == "" {
+		return description
+	}
+	return fmt.Sprintf("%s\n\n---\n%s", description, footer)
+}
+
+// newTemplateData collects the values available to templated flags for the supplied repo
+func newTemplateData(config *config.GitXargsConfig, repo *github.Repository) types.TemplateData {
+	return types.TemplateData{
+		RepoOwner: repo.GetOwner().GetLogin(),
+		RepoName:  repo.GetName(),
+		FullName:  fmt.Sprintf("%s/%s", repo.GetOwner().GetLogin(), repo.GetName()),
+		Date:      config.StartTime.UTC().Format("2006-01-02"),
+		RunID:     config.RunID,
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPullRequestTitleTemplate ensures the placeholders available to --pull-request-title are filled in per repo
+func TestPullRequestTitleTemplate(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	templateData := newTemplateData(testConfig, mocks.GetMockGithubRepo())
+
+	title, err := util.RenderTemplate("Update CI config ({{.FullName}}) [{{.RunID}}]", templateData)
+	require.NoError(t, err)
+	assert.Equal(t, "Update CI config (gruntwork-io/terragrunt) ["+testConfig.RunID+"]", title)
+
+	plain, err := util.RenderTemplate("git-xargs programmatic pull request", templateData)
+	require.NoError(t, err)
+	assert.Equal(t, "git-xargs programmatic pull request", plain)
+}
+
+// TestPullRequestDescriptionIsNotTemplated ensures only the --pull-request-title is rendered as a template, and the
+// description, or a commit message standing in for the title, is used as is
+func TestPullRequestDescriptionIsNotTemplated(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.SkipRunMarkers = true
+	testConfig.PullRequestTitle = "Pin actions ({{.FullName}})"
+	testConfig.PullRequestDescription = "Uses `${{ github.sha }}` instead of {{.RunID}}"
+
+	title, description, err := renderPullRequest(testConfig, mocks.GetMockGithubRepo(), changePart{})
+	require.NoError(t, err)
+	assert.Equal(t, "Pin actions (gruntwork-io/terragrunt)", title)
+	assert.Equal(t, "Uses `${{ github.sha }}` instead of {{.RunID}}", description)
+
+	testConfig.PullRequestTitle = common.DefaultPullRequestTitle
+	testConfig.CommitMessage = "Replace {{ .Values.image }}"
+	title, _, err = renderPullRequest(testConfig, mocks.GetMockGithubRepo(), changePart{})
+	require.NoError(t, err)
+	assert.Equal(t, "Replace {{ .Values.image }}", title)
+}
+
+// TestPullRequestFooter ensures the --pull-request-footer is appended below the description, and omitted when empty
+func TestPullRequestFooter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Description\n\n---\nOpened by git-xargs run 1", descriptionWithFooter("Description", "Opened by git-xargs run 1"))
+	assert.Equal(t, "Description", descriptionWithFooter("Description", ""))
+}
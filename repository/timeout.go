@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// withRepoTimeout returns a copy of the supplied config whose context is cancelled once --repo-timeout has passed, so
+// that a repo that hangs while it is cloned, its command runs, its branch is pushed or its pull request is opened doesn't
+// hold a worker forever. The returned function releases the timeout. Without --repo-timeout, the config itself is
+// returned
+func withRepoTimeout(gitxargsConfig *config.GitXargsConfig) (*config.GitXargsConfig, context.CancelFunc) {
+	if gitxargsConfig.RepoTimeout <= 0 {
+		return gitxargsConfig, func() {}
+	}
+
+	repoConfig := *gitxargsConfig
+	ctx, cancel := context.WithTimeout(gitxargsConfig.Context, gitxargsConfig.RepoTimeout)
+	repoConfig.Context = ctx
+	return &repoConfig, cancel
+}
+
+// checkRepoTimeout replaces the error of a repo that failed because it took longer than --repo-timeout with a
+// types.RepoTimedOutErr, and tracks the repo as timed out for the run report. Repos that failed because the whole run
+// was cancelled are left alone
+func checkRepoTimeout(gitxargsConfig *config.GitXargsConfig, repoConfig *config.GitXargsConfig, repo *github.Repository, processErr error) error {
+	if processErr == nil || repoConfig.Context.Err() != context.DeadlineExceeded || gitxargsConfig.Context.Err() != nil {
+		return processErr
+	}
+
+	gitxargsConfig.Stats.TrackSingle(stats.RepoTimedOut, repo)
+	return errors.WithStackTrace(types.RepoTimedOutErr{Timeout: gitxargsConfig.RepoTimeout})
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/gruntwork-io/git-xargs/stats"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRepoTimeout(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	repoConfig, cancel := withRepoTimeout(testConfig)
+	cancel()
+	assert.Equal(t, testConfig, repoConfig)
+
+	testConfig.RepoTimeout = time.Millisecond
+	repoConfig, cancel = withRepoTimeout(testConfig)
+	defer cancel()
+	<-repoConfig.Context.Done()
+
+	// Only the repo's copy of the config times out
+	assert.NoError(t, testConfig.Context.Err())
+
+	repo := mocks.GetMockGithubRepo()
+	assert.NoError(t, checkRepoTimeout(testConfig, repoConfig, repo, nil))
+
+	err := checkRepoTimeout(testConfig, repoConfig, repo, fmt.Errorf("signal: killed"))
+	require.Error(t, err)
+	assert.Equal(t, types.RepoTimedOutErr{Timeout: time.Millisecond}, errors.Unwrap(err))
+	assert.Len(t, testConfig.Stats.GetMultiple(stats.RepoTimedOut), 1)
+}
+
+func TestCheckRepoTimeoutIgnoresCancelledRuns(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.RepoTimeout = time.Millisecond
+	runContext, cancelRun := context.WithCancel(testConfig.Context)
+	testConfig.Context = runContext
+
+	repoConfig, cancel := withRepoTimeout(testConfig)
+	defer cancel()
+	<-repoConfig.Context.Done()
+	cancelRun()
+
+	processErr := fmt.Errorf("context canceled")
+	assert.Equal(t, processErr, checkRepoTimeout(testConfig, repoConfig, mocks.GetMockGithubRepo(), processErr))
+	assert.Empty(t, testConfig.Stats.GetMultiple(stats.RepoTimedOut))
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// CreateTrackingIssue opens an issue with the supplied title and body in the --create-tracking-issue repo, to give the
+// run a canonical place to follow up on its pull requests. The issue is opened on the GitHub host the repo is on
+func CreateTrackingIssue(config *config.GitXargsConfig, title string, body string) (*github.Issue, error) {
+	repo := util.ConvertStringToAllowedRepo(config.TrackingIssueRepo)
+
+	client, err := githubClientForHost(config.GithubClient, config.GithubHostClients, repo.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	issue, _, err := client.Issues.Create(context.Background(), repo.Organization, repo.Name, &github.IssueRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return issue, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTrackingIssue(t *testing.T) {
+	t.Parallel()
+
+	testConfig := config.NewGitXargsTestConfig()
+	testConfig.GithubClient = mocks.ConfigureMockGithubClient()
+	testConfig.TrackingIssueRepo = "gruntwork-io/campaigns"
+
+	issue, err := CreateTrackingIssue(testConfig, "git-xargs run abc: upgrade-ci", "body")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/gruntwork-io/campaigns/issues/1", issue.GetHTMLURL())
+	assert.Equal(t, "git-xargs run abc: upgrade-ci", issue.GetTitle())
+}
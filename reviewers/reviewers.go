@@ -0,0 +1,161 @@
+package reviewers
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// StrategyAll requests every member of the pool on every pull request
+	StrategyAll = "all"
+	// StrategyRoundRobin hands out members of the pool in turn, so pull requests are spread evenly across them
+	StrategyRoundRobin = "round-robin"
+	// StrategyLeastLoaded hands out the members of the pool with the fewest open review requests, counting both the
+	// requests they had before the run started and the ones assigned to them during the run
+	StrategyLeastLoaded = "least-loaded"
+)
+
+// LoadFunc returns the number of open review requests currently assigned to the supplied pool member
+type LoadFunc func(member string) (int, error)
+
+// Pool distributes pull requests across a fixed set of users or teams according to a strategy. It is safe to call from
+// the concurrent goroutines that process repos
+type Pool struct {
+	members  []string
+	strategy string
+	perPR    int
+	next     int
+	load     map[string]int
+	loaded   bool
+	mutex    *sync.Mutex
+}
+
+// NewPool returns a Pool of the supplied members that will hand out perPR members per pull request using the supplied
+// strategy. perPR is ignored by StrategyAll and falls back to 1 if it is not positive
+func NewPool(members []string, strategy string, perPR int) *Pool {
+	if strategy == "" {
+		strategy = StrategyAll
+	}
+	if perPR < 1 {
+		perPR = 1
+	}
+	if perPR > len(members) {
+		perPR = len(members)
+	}
+	return &Pool{
+		members:  members,
+		strategy: strategy,
+		perPR:    perPR,
+		load:     make(map[string]int),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// IsValidStrategy returns true if the supplied strategy is one that a Pool understands
+func IsValidStrategy(strategy string) bool {
+	switch strategy {
+	case StrategyAll, StrategyRoundRobin, StrategyLeastLoaded:
+		return true
+	}
+	return false
+}
+
+// IsEmpty returns true if the pool has no members to hand out
+func (p *Pool) IsEmpty() bool {
+	return p == nil || len(p.members) == 0
+}
+
+// Next returns the members that should be assigned to the next pull request. loadFunc is only consulted by
+// StrategyLeastLoaded, once per member, the first time Next is called
+func (p *Pool) Next(loadFunc LoadFunc) ([]string, error) {
+	if p.IsEmpty() {
+		return []string{}, nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	switch p.strategy {
+	case StrategyRoundRobin:
+		selected := []string{}
+		for i := 0; i < p.perPR; i++ {
+			selected = append(selected, p.members[p.next])
+			p.next = (p.next + 1) % len(p.members)
+		}
+		return selected, nil
+
+	case StrategyLeastLoaded:
+		if !p.loaded {
+			for _, member := range p.members {
+				if loadFunc == nil {
+					continue
+				}
+				count, err := loadFunc(member)
+				if err != nil {
+					return nil, err
+				}
+				p.load[member] = count
+			}
+			p.loaded = true
+		}
+
+		// Sort a copy of the members by their current load, keeping the pool order for members with equal load so that
+		// the selection is deterministic
+		candidates := make([]string, len(p.members))
+		copy(candidates, p.members)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return p.load[candidates[i]] < p.load[candidates[j]]
+		})
+
+		selected := candidates[:p.perPR]
+		for _, member := range selected {
+			p.load[member]++
+		}
+		return append([]string{}, selected...), nil
+
+	default:
+		return append([]string{}, p.members...), nil
+	}
+}
+
+// Exclude returns the supplied members, leaving out the excluded ones. Members are compared case-insensitively, since
+// GitHub logins and team slugs are
+func Exclude(members []string, excluded []string) []string {
+	if len(excluded) == 0 {
+		return members
+	}
+	remaining := []string{}
+	for _, member := range members {
+		if !IsExcluded(member, excluded) {
+			remaining = append(remaining, member)
+		}
+	}
+	return remaining
+}
+
+// IsExcluded returns true if the supplied user login, or team in the format of <github-organization>/<team-slug>, is
+// one of the excluded ones
+func IsExcluded(member string, excluded []string) bool {
+	for _, excludedMember := range excluded {
+		if strings.EqualFold(member, excludedMember) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitUsersAndTeams separates pool members into individual user logins and team slugs. Teams are supplied in the
+// format of <github-organization>/<team-slug>, and GitHub only needs the slug when requesting a team review
+func SplitUsersAndTeams(members []string) ([]string, []string) {
+	users := []string{}
+	teams := []string{}
+	for _, member := range members {
+		if parts := strings.SplitN(member, "/", 2); len(parts) == 2 {
+			teams = append(teams, parts[1])
+		} else {
+			users = append(users, member)
+		}
+	}
+	return users, teams
+}
@@ -0,0 +1,77 @@
+package reviewers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolAllStrategyReturnsEveryMember(t *testing.T) {
+	t.Parallel()
+
+	pool := NewPool([]string{"alice", "bob", "carol"}, StrategyAll, 1)
+
+	selected, err := pool.Next(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, selected)
+}
+
+func TestPoolRoundRobinStrategyRotatesMembers(t *testing.T) {
+	t.Parallel()
+
+	pool := NewPool([]string{"alice", "bob", "carol"}, StrategyRoundRobin, 2)
+
+	first, err := pool.Next(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, first)
+
+	second, err := pool.Next(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "alice"}, second)
+}
+
+func TestPoolLeastLoadedStrategyPrefersIdleMembers(t *testing.T) {
+	t.Parallel()
+
+	existingLoad := map[string]int{"alice": 5, "bob": 0, "carol": 1}
+	loadFunc := func(member string) (int, error) {
+		return existingLoad[member], nil
+	}
+
+	pool := NewPool([]string{"alice", "bob", "carol"}, StrategyLeastLoaded, 1)
+
+	var selections []string
+	for i := 0; i < 4; i++ {
+		selected, err := pool.Next(loadFunc)
+		require.NoError(t, err)
+		selections = append(selections, selected...)
+	}
+
+	// bob starts with no reviews, then alternates with carol once he catches up to her load
+	assert.Equal(t, []string{"bob", "bob", "carol", "bob"}, selections)
+}
+
+func TestSplitUsersAndTeams(t *testing.T) {
+	t.Parallel()
+
+	users, teams := SplitUsersAndTeams([]string{"alice", "gruntwork-io/platform", "bob"})
+	assert.Equal(t, []string{"alice", "bob"}, users)
+	assert.Equal(t, []string{"platform"}, teams)
+}
+
+func TestExclude(t *testing.T) {
+	t.Parallel()
+
+	members := []string{"alice", "gruntwork-io/platform", "bob", "git-xargs-bot"}
+	assert.Equal(t, []string{"alice", "bob"}, Exclude(members, []string{"Git-Xargs-Bot", "gruntwork-io/Platform"}))
+	assert.Equal(t, members, Exclude(members, nil))
+
+	// Round-robin never hands out an excluded member
+	pool := NewPool(Exclude(members, []string{"bob"}), StrategyRoundRobin, 1)
+	for i := 0; i < len(members); i++ {
+		selected, err := pool.Next(nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, []string{"bob"}, selected)
+	}
+}
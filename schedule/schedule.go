@@ -0,0 +1,191 @@
+// Package schedule holds back pull requests until a time window opens, such as weekdays from 9 to 11am in the
+// timezone of the reviewers, so that a campaign's pull requests land while the reviewers are around to look at them.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// weekdays maps the abbreviated names of the days of the week that a window can list to their time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a time window that recurs on some days of the week, from a start to an end time of day in a timezone
+type Window struct {
+	days     [7]bool
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+}
+
+// ParseWindow parses a window in the format "<days> <start>-<end>", such as "Mon-Fri 09:00-11:00". The days are either
+// a range of days, a comma separated list of days and ranges, such as "Mon,Wed-Thu", or "daily". The start and end are
+// times of day in the supplied location, and the end must come after the start, so windows can't span midnight
+func ParseWindow(spec string, location *time.Location) (*Window, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("expected the format \"<days> <start>-<end>\", such as \"Mon-Fri 09:00-11:00\"")
+	}
+
+	window := &Window{location: location}
+	if err := window.parseDays(fields[0]); err != nil {
+		return nil, err
+	}
+
+	times := strings.Split(fields[1], "-")
+	if len(times) != 2 {
+		return nil, fmt.Errorf("%q is not a time range in the format <start>-<end>, such as 09:00-11:00", fields[1])
+	}
+	var err error
+	if window.start, err = parseTimeOfDay(times[0]); err != nil {
+		return nil, err
+	}
+	if window.end, err = parseTimeOfDay(times[1]); err != nil {
+		return nil, err
+	}
+	if window.end <= window.start {
+		return nil, fmt.Errorf("the window ends at %s, which is not after it starts at %s", times[1], times[0])
+	}
+	return window, nil
+}
+
+// parseDays marks the days of the week listed by the supplied days field of a window
+func (window *Window) parseDays(days string) error {
+	if strings.EqualFold(days, "daily") {
+		for i := range window.days {
+			window.days[i] = true
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(days, ",") {
+		bounds := strings.Split(part, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("%q is not a day or a range of days", part)
+		}
+		first, ok := weekdays[strings.ToLower(bounds[0])]
+		if !ok {
+			return fmt.Errorf("%q is not a day of the week, such as Mon", bounds[0])
+		}
+		last, ok := weekdays[strings.ToLower(bounds[len(bounds)-1])]
+		if !ok {
+			return fmt.Errorf("%q is not a day of the week, such as Mon", bounds[len(bounds)-1])
+		}
+
+		// Ranges may wrap around the end of the week, as in Sat-Sun
+		for day := first; ; day = (day + 1) % 7 {
+			window.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses a time of day in the format HH:MM, returning how long after midnight it is
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("%q is not a time of day in the format HH:MM", value)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("%q is not a time of day in the format HH:MM", value)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes != 0) {
+		return 0, fmt.Errorf("%q is not a time of day in the format HH:MM", value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// Opening returns when the window that the supplied time falls in opened, or if it falls outside the window, when the
+// window next opens. The second return value is true if the supplied time falls in the window
+func (window *Window) Opening(now time.Time) (time.Time, bool) {
+	now = now.In(window.location)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, window.location)
+
+	// Look a week ahead, plus today, since a window that only opens on one day of the week may have just closed
+	for day := 0; day <= 7; day++ {
+		date := midnight.AddDate(0, 0, day)
+		if !window.days[date.Weekday()] {
+			continue
+		}
+		opens := window.at(date, window.start)
+		closes := window.at(date, window.end)
+		if now.Before(opens) {
+			return opens, false
+		}
+		if now.Before(closes) {
+			return opens, true
+		}
+	}
+	// Unreachable, since every window lists at least one day
+	return now, true
+}
+
+// at returns the supplied time of day on the supplied date, in the timezone of the window. Building it from the wall
+// clock, rather than adding the time of day to midnight, keeps the window at the same time of day on the days daylight
+// saving time starts or ends, which are shorter or longer than 24 hours
+func (window *Window) at(date time.Time, timeOfDay time.Duration) time.Time {
+	hours, minutes := int(timeOfDay/time.Hour), int(timeOfDay%time.Hour/time.Minute)
+	return time.Date(date.Year(), date.Month(), date.Day(), hours, minutes, 0, 0, window.location)
+}
+
+// Gate lets pull requests through while its window is open, up to a maximum number of pull requests per opening of the
+// window, so that a campaign is spread over as many days as it takes. A nil *Gate lets every pull request through
+type Gate struct {
+	window       *Window
+	maxPerWindow int
+
+	mutex   sync.Mutex
+	opening time.Time
+	count   int
+}
+
+// NewGate returns a Gate for the supplied window. A maxPerWindow of zero lets any number of pull requests through
+// while the window is open
+func NewGate(window *Window, maxPerWindow int) *Gate {
+	return &Gate{window: window, maxPerWindow: maxPerWindow}
+}
+
+// Reserve lets the supplied number of pull requests through at the supplied time, if the window is open and still has
+// room for them, in which case it returns the zero time. Pull requests are let through together, so that a repo's pull
+// requests are opened in the same opening of the window, and an opening with no pull requests let through yet takes
+// any number of them. Otherwise it returns when the window next opens, at which point the pull requests should try again
+func (gate *Gate) Reserve(now time.Time, count int) time.Time {
+	if gate == nil {
+		return time.Time{}
+	}
+
+	gate.mutex.Lock()
+	defer gate.mutex.Unlock()
+
+	opening, open := gate.window.Opening(now)
+	if !open {
+		return opening
+	}
+	if !opening.Equal(gate.opening) {
+		gate.opening = opening
+		gate.count = 0
+	}
+	if gate.maxPerWindow > 0 && gate.count > 0 && gate.count+count > gate.maxPerWindow {
+		// This opening of the window is full, so wait for the one after it, which opens once this one closes
+		next, _ := gate.window.Opening(gate.window.at(opening, gate.window.end))
+		return next
+	}
+	gate.count += count
+	return time.Time{}
+}
@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test that windows are parsed into their days and times, and that malformed windows are rejected
+func TestParseWindow(t *testing.T) {
+	t.Parallel()
+
+	window, err := ParseWindow("Mon-Fri 09:00-11:00", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{false, true, true, true, true, true, false}, window.days)
+	assert.Equal(t, 9*time.Hour, window.start)
+	assert.Equal(t, 11*time.Hour, window.end)
+
+	window, err = ParseWindow("mon,Sat-Sun 22:30-24:00", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, true, false, false, false, false, true}, window.days)
+
+	window, err = ParseWindow("daily 00:00-01:00", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, [7]bool{true, true, true, true, true, true, true}, window.days)
+
+	for _, invalid := range []string{
+		"",
+		"Mon-Fri",
+		"Weekdays 09:00-11:00",
+		"Mon-Tue-Wed 09:00-11:00",
+		"Mon-Fri 9-11",
+		"Mon-Fri 09:00-25:00",
+		"Mon-Fri 11:00-09:00",
+		"Mon-Fri 09:00",
+	} {
+		_, err := ParseWindow(invalid, time.UTC)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// Test that a time inside the window reports when the window opened, and a time outside it when the window next opens,
+// in the timezone of the window
+func TestWindowOpening(t *testing.T) {
+	t.Parallel()
+
+	newYork := time.FixedZone("EST", -5*60*60)
+	window, err := ParseWindow("Mon-Fri 09:00-11:00", newYork)
+	require.NoError(t, err)
+
+	// Wednesday 2021-06-02 at 10:00 in New York is inside the window
+	opening, open := window.Opening(time.Date(2021, 6, 2, 15, 0, 0, 0, time.UTC))
+	assert.True(t, open)
+	assert.Equal(t, time.Date(2021, 6, 2, 9, 0, 0, 0, newYork).Unix(), opening.Unix())
+
+	// Wednesday at 11:00 in New York has just closed, so the window opens again on Thursday
+	opening, open = window.Opening(time.Date(2021, 6, 2, 11, 0, 0, 0, newYork))
+	assert.False(t, open)
+	assert.Equal(t, time.Date(2021, 6, 3, 9, 0, 0, 0, newYork).Unix(), opening.Unix())
+
+	// Friday afternoon waits for Monday
+	opening, open = window.Opening(time.Date(2021, 6, 4, 15, 0, 0, 0, newYork))
+	assert.False(t, open)
+	assert.Equal(t, time.Date(2021, 6, 7, 9, 0, 0, 0, newYork).Unix(), opening.Unix())
+}
+
+// Test that the window opens at the same time of day on the days daylight saving time starts and ends
+func TestWindowOpeningAcrossDaylightSavingTime(t *testing.T) {
+	t.Parallel()
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("The timezone database isn't available")
+	}
+	window, err := ParseWindow("daily 09:00-11:00", newYork)
+	require.NoError(t, err)
+
+	// Daylight saving time started on Sunday 2021-03-14 and ended on Sunday 2021-11-07 in New York
+	for _, midnight := range []time.Time{time.Date(2021, 3, 14, 0, 0, 0, 0, newYork), time.Date(2021, 11, 7, 0, 0, 0, 0, newYork)} {
+		opening, open := window.Opening(midnight)
+		assert.False(t, open)
+		assert.Equal(t, time.Date(midnight.Year(), midnight.Month(), midnight.Day(), 9, 0, 0, 0, newYork), opening)
+	}
+}
+
+// Test that a gate lets pull requests through up to its maximum per opening of the window, and then holds them until
+// the window opens again
+func TestGateReserve(t *testing.T) {
+	t.Parallel()
+
+	window, err := ParseWindow("Mon-Fri 09:00-11:00", time.UTC)
+	require.NoError(t, err)
+	gate := NewGate(window, 2)
+
+	wednesday := time.Date(2021, 6, 2, 9, 30, 0, 0, time.UTC)
+	thursday := time.Date(2021, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, thursday, gate.Reserve(time.Date(2021, 6, 2, 12, 0, 0, 0, time.UTC), 1))
+	assert.True(t, gate.Reserve(wednesday, 1).IsZero())
+	assert.True(t, gate.Reserve(wednesday, 1).IsZero())
+	assert.Equal(t, thursday, gate.Reserve(wednesday, 1))
+	assert.True(t, gate.Reserve(thursday, 1).IsZero())
+
+	// The pull requests of a repo are let through together, even if they don't all fit, as long as none went through yet
+	gate = NewGate(window, 2)
+	assert.True(t, gate.Reserve(wednesday, 1).IsZero())
+	assert.Equal(t, thursday, gate.Reserve(wednesday, 2))
+	assert.True(t, gate.Reserve(thursday, 3).IsZero())
+	assert.Equal(t, time.Date(2021, 6, 4, 9, 0, 0, 0, time.UTC), gate.Reserve(thursday, 1))
+
+	var unscheduled *Gate
+	assert.True(t, unscheduled.Reserve(wednesday, 1).IsZero())
+}
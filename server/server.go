@@ -0,0 +1,358 @@
+// Package server implements the REST API exposed by git-xargs serve, which lets other systems submit runs, query their
+// status and cancel them over HTTP instead of shelling out to the CLI.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/config"
+	"github.com/gruntwork-io/git-xargs/logging"
+	"github.com/gruntwork-io/git-xargs/reviewers"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RunStateRunning denotes a run that is still processing repos
+	RunStateRunning = "running"
+	// RunStateCancelling denotes a run that was cancelled, but is still stopping the repos it already started on
+	RunStateCancelling = "cancelling"
+	// RunStateCancelled denotes a run that was cancelled before it processed every repo
+	RunStateCancelled = "cancelled"
+	// RunStateSucceeded denotes a run that finished without error
+	RunStateSucceeded = "succeeded"
+	// RunStateFailed denotes a run that returned an error
+	RunStateFailed = "failed"
+	// RunStateFinished denotes a finished run that was not started by this server, and is only known from the state store
+	RunStateFinished = "finished"
+	// RunStateUnknown denotes an unfinished run that was not started by this server
+	RunStateUnknown = "unknown"
+
+	// finishedRunRetention is how long a finished run is kept in memory, after which it is only looked up in the state
+	// store, so that a long-lived server doesn't hold on to every run it ever started
+	finishedRunRetention = time.Hour
+)
+
+// RunFunc runs git-xargs with the supplied config, as a regular run would, without printing the run report
+type RunFunc func(config *config.GitXargsConfig) error
+
+// ValidateFunc checks the config of a submitted run before it starts, as the CLI checks the config of a regular run
+type ValidateFunc func(config *config.GitXargsConfig) error
+
+// RunRequest is the body of a request to submit a run. Its fields mirror the flags of a regular run
+type RunRequest struct {
+	Command                []string `json:"command"`
+	GithubOrg              string   `json:"github_org,omitempty"`
+	Repos                  []string `json:"repos,omitempty"`
+	SkipArchivedRepos      bool     `json:"skip_archived_repos,omitempty"`
+	SkipReposWithOpenPRs   bool     `json:"skip_repos_with_open_pull_requests,omitempty"`
+	BranchName             string   `json:"branch_name"`
+	BaseBranchName         string   `json:"base_branch_name,omitempty"`
+	CommitMessage          string   `json:"commit_message,omitempty"`
+	PullRequestTitle       string   `json:"pull_request_title,omitempty"`
+	PullRequestDescription string   `json:"pull_request_description,omitempty"`
+	Draft                  bool     `json:"draft,omitempty"`
+	DryRun                 bool     `json:"dry_run,omitempty"`
+	DryRunLevel            string   `json:"dry_run_level,omitempty"`
+	SkipPullRequests       bool     `json:"skip_pull_requests,omitempty"`
+	Reviewers              []string `json:"reviewers,omitempty"`
+	Assignees              []string `json:"assignees,omitempty"`
+	MaxConcurrentRepos     int      `json:"max_concurrent_repos,omitempty"`
+}
+
+// RunStatus is the state of a run, as returned by the API
+type RunStatus struct {
+	RunID      string        `json:"run_id"`
+	State      string        `json:"state"`
+	Error      string        `json:"error,omitempty"`
+	Command    []string      `json:"command"`
+	BranchName string        `json:"branch_name"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Repos      []*state.Repo `json:"repos,omitempty"`
+}
+
+// run is a run started by this server
+type run struct {
+	status RunStatus
+	cancel context.CancelFunc
+}
+
+// Server is the http.Handler serving the git-xargs REST API
+type Server struct {
+	token     string
+	store     *state.Store
+	newConfig func() *config.GitXargsConfig
+	validate  ValidateFunc
+	runFunc   RunFunc
+	retention time.Duration
+
+	mutex sync.Mutex
+	runs  map[string]*run
+}
+
+// New returns a Server that only accepts requests bearing the supplied token, records runs in the supplied state store,
+// and starts each run with a config returned by newConfig, filled in from the request and checked by validate
+func New(token string, store *state.Store, newConfig func() *config.GitXargsConfig, validate ValidateFunc, runFunc RunFunc) *Server {
+	return &Server{
+		token:     token,
+		store:     store,
+		newConfig: newConfig,
+		validate:  validate,
+		runFunc:   runFunc,
+		retention: finishedRunRetention,
+		runs:      map[string]*run{},
+	}
+}
+
+// ServeHTTP routes requests to the API's endpoints:
+//
+//	GET  /healthz              returns 200 once the server is up, without authentication
+//	POST /runs                 submits a run, described by a RunRequest
+//	GET  /runs                 lists the runs started by this server
+//	GET  /runs/<id>            returns the status of a run, including its repos and pull requests
+//	POST /runs/<id>/cancel     cancels a run
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	switch {
+	case path == "runs" && r.Method == http.MethodPost:
+		s.submitRun(w, r)
+	case path == "runs" && r.Method == http.MethodGet:
+		s.listRuns(w)
+	case len(parts) == 2 && parts[0] == "runs" && r.Method == http.MethodGet:
+		s.getRun(w, parts[1])
+	case len(parts) == 3 && parts[0] == "runs" && parts[2] == "cancel" && r.Method == http.MethodPost:
+		s.cancelRun(w, parts[1])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// authorized returns true if the request carries the server's token as a bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) submitRun(w http.ResponseWriter, r *http.Request) {
+	request := RunRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	config, err := s.configFromRequest(request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, s.startRun(config))
+}
+
+// configFromRequest builds and validates the config of the run described by the supplied request
+func (s *Server) configFromRequest(request RunRequest) (*config.GitXargsConfig, error) {
+	config := s.newConfig()
+	config.Args = request.Command
+	config.GithubOrg = request.GithubOrg
+	config.RepoSlice = request.Repos
+	config.SkipArchivedRepos = request.SkipArchivedRepos
+	config.SkipReposWithOpenPRs = request.SkipReposWithOpenPRs
+	config.BranchName = request.BranchName
+	config.BaseBranchName = request.BaseBranchName
+	config.Draft = request.Draft
+	if request.DryRun {
+		config.ApplyDryRunLevel(common.DryRunCommitNoPush)
+	}
+	config.ApplyDryRunLevel(request.DryRunLevel)
+	config.SkipPullRequests = request.SkipPullRequests
+	config.MaxConcurrentRepos = request.MaxConcurrentRepos
+	if request.CommitMessage != "" {
+		config.CommitMessage = request.CommitMessage
+	}
+	if request.PullRequestTitle != "" {
+		config.PullRequestTitle = request.PullRequestTitle
+	}
+	if request.PullRequestDescription != "" {
+		config.PullRequestDescription = request.PullRequestDescription
+	}
+	if len(request.Reviewers) > 0 {
+		config.Reviewers = request.Reviewers
+		config.ReviewerPool = reviewers.NewPool(reviewers.Exclude(config.Reviewers, config.ExcludeReviewers), config.ReviewerStrategy, config.ReviewersPerPR)
+	}
+	if len(request.Assignees) > 0 {
+		config.Assignees = request.Assignees
+	}
+	config.State = s.store
+
+	if err := s.validate(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// evictFinishedRuns drops the runs that finished more than the retention of the server ago. The caller must hold the
+// mutex of the server
+func (s *Server) evictFinishedRuns() {
+	for runID, existing := range s.runs {
+		if existing.status.FinishedAt != nil && time.Since(*existing.status.FinishedAt) > s.retention {
+			delete(s.runs, runID)
+		}
+	}
+}
+
+// startRun runs git-xargs with the supplied config in the background, and returns the initial status of the run
+func (s *Server) startRun(config *config.GitXargsConfig) RunStatus {
+	logger := logging.GetLogger("git-xargs")
+
+	newRun := &run{
+		status: RunStatus{
+			RunID:      config.RunID,
+			State:      RunStateRunning,
+			Command:    config.Args,
+			BranchName: config.BranchName,
+			StartedAt:  config.StartTime,
+		},
+	}
+	config.Context, newRun.cancel = context.WithCancel(config.Context)
+
+	s.mutex.Lock()
+	s.evictFinishedRuns()
+	s.runs[config.RunID] = newRun
+	status := newRun.status
+	s.mutex.Unlock()
+
+	logger.WithFields(logrus.Fields{
+		"Run ID":  config.RunID,
+		"Command": config.Args,
+	}).Info("Starting run submitted via the API")
+
+	go func() {
+		err := s.runFunc(config)
+		newRun.cancel()
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		finishedAt := time.Now()
+		newRun.status.FinishedAt = &finishedAt
+		switch {
+		case err != nil:
+			newRun.status.State = RunStateFailed
+			newRun.status.Error = err.Error()
+		case newRun.status.State == RunStateCancelling:
+			newRun.status.State = RunStateCancelled
+		default:
+			newRun.status.State = RunStateSucceeded
+		}
+
+		logger.WithFields(logrus.Fields{
+			"Run ID": config.RunID,
+			"State":  newRun.status.State,
+		}).Info("Run submitted via the API finished")
+	}()
+
+	return status
+}
+
+func (s *Server) listRuns(w http.ResponseWriter) {
+	s.mutex.Lock()
+	s.evictFinishedRuns()
+	statuses := []RunStatus{}
+	for _, run := range s.runs {
+		statuses = append(statuses, run.status)
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].StartedAt.Before(statuses[j].StartedAt)
+	})
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) getRun(w http.ResponseWriter, runID string) {
+	s.mutex.Lock()
+	s.evictFinishedRuns()
+	existing, ok := s.runs[runID]
+	var status RunStatus
+	if ok {
+		status = existing.status
+	}
+	s.mutex.Unlock()
+
+	// Runs that weren't started by this server, such as those of the CLI, are looked up in the state store
+	if !ok {
+		recordedRun, err := s.store.GetRun(runID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, types.RunNotFoundErr{RunID: runID}.Error())
+			return
+		}
+		status = RunStatus{
+			RunID:      recordedRun.ID,
+			State:      RunStateUnknown,
+			Command:    recordedRun.Command,
+			BranchName: recordedRun.BranchName,
+			StartedAt:  recordedRun.StartedAt,
+		}
+		if !recordedRun.FinishedAt.IsZero() {
+			status.State = RunStateFinished
+			status.FinishedAt = &recordedRun.FinishedAt
+		}
+	}
+
+	repos, err := s.store.ListRepos(runID)
+	if err == nil {
+		status.Repos = repos
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) cancelRun(w http.ResponseWriter, runID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.evictFinishedRuns()
+	existing, ok := s.runs[runID]
+	if !ok {
+		writeError(w, http.StatusNotFound, types.RunNotFoundErr{RunID: runID}.Error())
+		return
+	}
+	if existing.status.State != RunStateRunning {
+		writeError(w, http.StatusConflict, "run "+runID+" is "+existing.status.State)
+		return
+	}
+
+	existing.cancel()
+	existing.status.State = RunStateCancelling
+	writeJSON(w, http.StatusAccepted, existing.status)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{"error": message})
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gruntwork-io/git-xargs/config"
+	gitxargs_io "github.com/gruntwork-io/git-xargs/io"
+	"github.com/gruntwork-io/git-xargs/state"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "test-token"
+
+// newTestServer returns a Server backed by a temporary state store, whose runs block until they are cancelled
+func newTestServer(t *testing.T) (*Server, func()) {
+	dir, err := ioutil.TempDir("", "git-xargs-server-test")
+	require.NoError(t, err)
+
+	store, err := state.Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+
+	runFunc := func(config *config.GitXargsConfig) error {
+		<-config.Context.Done()
+		return nil
+	}
+
+	// The CLI also checks for the tokens the run needs, which the tests don't have
+	validate := func(config *config.GitXargsConfig) error {
+		if len(config.Args) < 1 {
+			return types.NoArgumentsPassedErr{}
+		}
+		return gitxargs_io.EnsureValidOptionsPassed(config)
+	}
+
+	return New(testToken, store, config.NewGitXargsTestConfig, validate, runFunc), func() {
+		store.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func doRequest(t *testing.T, handler http.Handler, method string, path string, body interface{}, token string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	encoded, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	decoded := map[string]interface{}{}
+	json.Unmarshal(recorder.Body.Bytes(), &decoded)
+	return recorder, decoded
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	recorder, _ := doRequest(t, srv, http.MethodGet, "/healthz", nil, "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder, _ = doRequest(t, srv, http.MethodGet, "/runs", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder, _ = doRequest(t, srv, http.MethodGet, "/runs", nil, "wrong-token")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestServerRejectsInvalidRun(t *testing.T) {
+	t.Parallel()
+
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	// No repo selection
+	recorder, body := doRequest(t, srv, http.MethodPost, "/runs", RunRequest{Command: []string{"touch", "file"}, BranchName: "update"}, testToken)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.NotEmpty(t, body["error"])
+
+	// No command
+	recorder, _ = doRequest(t, srv, http.MethodPost, "/runs", RunRequest{Repos: []string{"gruntwork-io/fetch"}, BranchName: "update"}, testToken)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+// Test that a run can be submitted, queried and cancelled
+func TestServerSubmitsAndCancelsRuns(t *testing.T) {
+	t.Parallel()
+
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	recorder, body := doRequest(t, srv, http.MethodPost, "/runs", RunRequest{
+		Command:    []string{"touch", "file"},
+		Repos:      []string{"gruntwork-io/fetch"},
+		BranchName: "update",
+	}, testToken)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, RunStateRunning, body["state"])
+	runID := body["run_id"].(string)
+
+	recorder, body = doRequest(t, srv, http.MethodGet, "/runs/"+runID, nil, testToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, RunStateRunning, body["state"])
+
+	recorder, body = doRequest(t, srv, http.MethodPost, "/runs/"+runID+"/cancel", nil, testToken)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, RunStateCancelling, body["state"])
+
+	assert.Eventually(t, func() bool {
+		_, body := doRequest(t, srv, http.MethodGet, "/runs/"+runID, nil, testToken)
+		return body["state"] == RunStateCancelled
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A finished run can't be cancelled again
+	recorder, _ = doRequest(t, srv, http.MethodPost, "/runs/"+runID+"/cancel", nil, testToken)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder, _ = doRequest(t, srv, http.MethodGet, "/runs/no-such-run", nil, testToken)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+// Test that finished runs are dropped from memory once the retention of the server has passed, and are then looked up
+// in the state store like runs of the CLI
+func TestServerEvictsFinishedRuns(t *testing.T) {
+	t.Parallel()
+
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	srv.retention = 0
+
+	recorder, body := doRequest(t, srv, http.MethodPost, "/runs", RunRequest{
+		Command:    []string{"touch", "file"},
+		Repos:      []string{"gruntwork-io/fetch"},
+		BranchName: "update",
+	}, testToken)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	runID := body["run_id"].(string)
+	require.NoError(t, srv.store.StartRun(state.Run{ID: runID, StartedAt: time.Now()}))
+
+	recorder, _ = doRequest(t, srv, http.MethodPost, "/runs/"+runID+"/cancel", nil, testToken)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	assert.Eventually(t, func() bool {
+		srv.mutex.Lock()
+		defer srv.mutex.Unlock()
+		srv.evictFinishedRuns()
+		return len(srv.runs) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	recorder, body = doRequest(t, srv, http.MethodGet, "/runs/"+runID, nil, testToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, RunStateUnknown, body["state"])
+}
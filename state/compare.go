@@ -0,0 +1,89 @@
+package state
+
+import "sort"
+
+const (
+	// ChangeFixed denotes a repo that failed in the first run and succeeded in the second
+	ChangeFixed = "fixed"
+	// ChangeRegressed denotes a repo that succeeded in the first run and failed in the second
+	ChangeRegressed = "regressed"
+	// ChangeErrorChanged denotes a repo that failed in both runs, with a different error
+	ChangeErrorChanged = "error changed"
+	// ChangeOutcomeChanged denotes a repo whose outcome changed in any other way, e.g. one that never finished
+	// processing in the first run
+	ChangeOutcomeChanged = "outcome changed"
+	// ChangeAdded denotes a repo that was only selected by the second run
+	ChangeAdded = "added"
+	// ChangeRemoved denotes a repo that was only selected by the first run
+	ChangeRemoved = "removed"
+	// ChangeNone denotes a repo with the same outcome in both runs
+	ChangeNone = "unchanged"
+)
+
+// changeOrder is the order changes are listed in, with the ones most likely to need attention first
+var changeOrder = map[string]int{
+	ChangeRegressed:      0,
+	ChangeErrorChanged:   1,
+	ChangeOutcomeChanged: 2,
+	ChangeFixed:          3,
+	ChangeAdded:          4,
+	ChangeRemoved:        5,
+	ChangeNone:           6,
+}
+
+// RepoComparison is how the outcome of a repo changed between two runs. Before or After is nil if the repo wasn't
+// selected by that run
+type RepoComparison struct {
+	Repo   string
+	Change string
+	Before *Repo
+	After  *Repo
+}
+
+// CompareRepos compares the records of the repos of two runs, and returns how the outcome of each repo selected by
+// either run changed, with regressions first
+func CompareRepos(before []*Repo, after []*Repo) []RepoComparison {
+	comparisons := map[string]*RepoComparison{}
+	for _, repo := range before {
+		comparisons[repo.Key()] = &RepoComparison{Repo: repo.Key(), Before: repo}
+	}
+	for _, repo := range after {
+		comparison, ok := comparisons[repo.Key()]
+		if !ok {
+			comparison = &RepoComparison{Repo: repo.Key()}
+			comparisons[repo.Key()] = comparison
+		}
+		comparison.After = repo
+	}
+
+	result := []RepoComparison{}
+	for _, comparison := range comparisons {
+		comparison.Change = compareOutcomes(comparison.Before, comparison.After)
+		result = append(result, *comparison)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Change != result[j].Change {
+			return changeOrder[result[i].Change] < changeOrder[result[j].Change]
+		}
+		return result[i].Repo < result[j].Repo
+	})
+	return result
+}
+
+func compareOutcomes(before *Repo, after *Repo) string {
+	switch {
+	case before == nil:
+		return ChangeAdded
+	case after == nil:
+		return ChangeRemoved
+	case before.Outcome == OutcomeFailed && after.Outcome == OutcomeSucceeded:
+		return ChangeFixed
+	case before.Outcome == OutcomeSucceeded && after.Outcome == OutcomeFailed:
+		return ChangeRegressed
+	case before.Outcome != after.Outcome:
+		return ChangeOutcomeChanged
+	case before.Outcome == OutcomeFailed && before.Error != after.Error:
+		return ChangeErrorChanged
+	}
+	return ChangeNone
+}
@@ -0,0 +1,51 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareRepos(t *testing.T) {
+	t.Parallel()
+
+	record := func(name string, outcome string, err string) *Repo {
+		return &Repo{Owner: "gruntwork-io", Name: name, Outcome: outcome, Error: err}
+	}
+
+	before := []*Repo{
+		record("fetch", OutcomeFailed, "exit status 1"),
+		record("terragrunt", OutcomeSucceeded, ""),
+		record("cloud-nuke", OutcomeFailed, "exit status 1"),
+		record("terratest", OutcomeSelected, ""),
+		record("boilerplate", OutcomeSucceeded, ""),
+		record("kubergrunt", OutcomeSucceeded, ""),
+	}
+	after := []*Repo{
+		record("fetch", OutcomeSucceeded, ""),
+		record("terragrunt", OutcomeFailed, "push rejected"),
+		record("cloud-nuke", OutcomeFailed, "exit status 2"),
+		record("terratest", OutcomeSucceeded, ""),
+		record("boilerplate", OutcomeSucceeded, ""),
+		record("bash-commons", OutcomeSucceeded, ""),
+	}
+
+	changes := map[string]string{}
+	order := []string{}
+	for _, comparison := range CompareRepos(before, after) {
+		changes[comparison.Repo] = comparison.Change
+		order = append(order, comparison.Repo)
+	}
+
+	assert.Equal(t, map[string]string{
+		"gruntwork-io/fetch":        ChangeFixed,
+		"gruntwork-io/terragrunt":   ChangeRegressed,
+		"gruntwork-io/cloud-nuke":   ChangeErrorChanged,
+		"gruntwork-io/terratest":    ChangeOutcomeChanged,
+		"gruntwork-io/boilerplate":  ChangeNone,
+		"gruntwork-io/bash-commons": ChangeAdded,
+		"gruntwork-io/kubergrunt":   ChangeRemoved,
+	}, changes)
+	assert.Equal(t, "gruntwork-io/terragrunt", order[0])
+	assert.Equal(t, "gruntwork-io/boilerplate", order[len(order)-1])
+}
@@ -0,0 +1,469 @@
+// Package state implements the local store that records every git-xargs run: the repos it selected, what happened to
+// each of them, and the branches and pull requests it created. Subcommands that act on the artifacts of an earlier run,
+// such as status, merge and close, look them up here by run ID.
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// OutcomeSelected denotes a repo that was selected by the run, but has not finished processing yet
+	OutcomeSelected = "selected"
+	// OutcomeSucceeded denotes a repo that was processed without error
+	OutcomeSucceeded = "succeeded"
+	// OutcomeFailed denotes a repo whose processing returned an error
+	OutcomeFailed = "failed"
+	// OutcomeQueued denotes a repo that was left for the continue subcommand, because --max-open-prs pull requests were
+	// open when the run got to it
+	OutcomeQueued = "queued"
+)
+
+const (
+	// CheckpointCloned denotes a repo that was cloned
+	CheckpointCloned = "cloned"
+	// CheckpointCommandRun denotes a repo that the supplied command was run against
+	CheckpointCommandRun = "command-run"
+	// CheckpointPushed denotes a repo whose branch was pushed
+	CheckpointPushed = "pushed"
+	// CheckpointPullRequestOpened denotes a repo whose pull request was opened
+	CheckpointPullRequestOpened = "pull-request-opened"
+)
+
+// checkpoints lists the checkpoints in the order they are reached while processing a repo
+var checkpoints = []string{CheckpointCloned, CheckpointCommandRun, CheckpointPushed, CheckpointPullRequestOpened}
+
+var (
+	runsBucket  = []byte("runs")
+	reposBucket = []byte("repos")
+	runKey      = []byte("run")
+)
+
+// lockTimeout is how long Open waits for another git-xargs process to release the store
+var lockTimeout = 5 * time.Second
+
+// Run is the record of a single git-xargs invocation
+type Run struct {
+	ID                     string    `json:"id"`
+	Command                []string  `json:"command"`
+	BranchName             string    `json:"branch_name"`
+	BaseBranchName         string    `json:"base_branch_name,omitempty"`
+	CommitMessage          string    `json:"commit_message,omitempty"`
+	PullRequestTitle       string    `json:"pull_request_title,omitempty"`
+	PullRequestDescription string    `json:"pull_request_description,omitempty"`
+	StartedAt              time.Time `json:"started_at"`
+	FinishedAt             time.Time `json:"finished_at,omitempty"`
+}
+
+// PullRequest is the record of a pull request opened by a run
+type PullRequest struct {
+	Number   int       `json:"number"`
+	URL      string    `json:"url"`
+	Branch   string    `json:"branch"`
+	Draft    bool      `json:"draft"`
+	OpenedAt time.Time `json:"opened_at"`
+}
+
+// Repo is the record of what a run did to a single repo
+type Repo struct {
+	Host         string        `json:"host,omitempty"`
+	Owner        string        `json:"owner"`
+	Name         string        `json:"name"`
+	URL          string        `json:"url,omitempty"`
+	Outcome      string        `json:"outcome"`
+	Checkpoint   string        `json:"checkpoint,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	Events       []string      `json:"events,omitempty"`
+	PullRequests []PullRequest `json:"pull_requests,omitempty"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// FullName returns the repo's name in the format <owner>/<name>
+func (repo Repo) FullName() string {
+	return repo.Owner + "/" + repo.Name
+}
+
+// Key returns the key the repo's record is stored under: its full name, prefixed with the host of its GitHub Enterprise
+// Server if it's on one, so that repos of the same name on different hosts get records of their own
+func (repo Repo) Key() string {
+	if repo.Host != "" {
+		return repo.Host + "/" + repo.FullName()
+	}
+	return repo.FullName()
+}
+
+// ReachedCheckpoint returns true if processing the repo got at least as far as the supplied checkpoint
+func (repo Repo) ReachedCheckpoint(checkpoint string) bool {
+	return checkpointIndex(repo.Checkpoint) >= checkpointIndex(checkpoint)
+}
+
+// Store is the BoltDB backed run state store. A nil *Store is valid and records nothing, which is what is used when
+// --skip-state is passed and in tests
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns the path of the state store used when --state-file is not passed: ~/.git-xargs/state.db
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.WithStackTrace(err)
+	}
+	return filepath.Join(home, ".git-xargs", "state.db"), nil
+}
+
+// Open opens the state store at the supplied path, creating it and its parent directory if they don't exist yet. Only
+// one git-xargs process can hold the store open at a time, so Open gives up after a short wait if another one does
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if err == bolt.ErrTimeout {
+		return nil, errors.WithStackTrace(types.StateStoreLockedErr{Path: path})
+	}
+	if err != nil {
+		return nil, errors.WithStackTrace(types.StateStoreOpenErr{Path: path, Err: err})
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(runsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.WithStackTrace(err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the state store
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return errors.WithStackTrace(s.db.Close())
+}
+
+// StartRun records the start of the supplied run
+func (s *Store) StartRun(run Run) error {
+	if s == nil {
+		return nil
+	}
+	return errors.WithStackTrace(s.db.Update(func(tx *bolt.Tx) error {
+		runBucket, err := tx.Bucket(runsBucket).CreateBucketIfNotExists([]byte(run.ID))
+		if err != nil {
+			return err
+		}
+		if _, err := runBucket.CreateBucketIfNotExists(reposBucket); err != nil {
+			return err
+		}
+		return putJSON(runBucket, runKey, run)
+	}))
+}
+
+// FinishRun records the time at which the run with the supplied ID finished
+func (s *Store) FinishRun(runID string, finishedAt time.Time) error {
+	if s == nil {
+		return nil
+	}
+	return errors.WithStackTrace(s.db.Update(func(tx *bolt.Tx) error {
+		runBucket, err := getRunBucket(tx, runID)
+		if err != nil {
+			return err
+		}
+		run := Run{}
+		if err := getJSON(runBucket, runKey, &run); err != nil {
+			return err
+		}
+		run.FinishedAt = finishedAt
+		return putJSON(runBucket, runKey, run)
+	}))
+}
+
+// RecordSelectedRepos records the repos selected by the run with the supplied ID. Repos that already have an outcome,
+// because the run is being resumed, keep it
+func (s *Store) RecordSelectedRepos(runID string, repos []*github.Repository) error {
+	for _, repo := range repos {
+		err := s.updateRepo(runID, repo, func(record *Repo) {
+			if record.Outcome == "" {
+				record.Outcome = OutcomeSelected
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordOutcome records whether the supplied repo was processed successfully by the run with the supplied ID
+func (s *Store) RecordOutcome(runID string, repo *github.Repository, processErr error) error {
+	return s.updateRepo(runID, repo, func(record *Repo) {
+		record.Outcome = OutcomeSucceeded
+		record.Error = ""
+		if processErr != nil {
+			record.Outcome = OutcomeFailed
+			record.Error = processErr.Error()
+		}
+	})
+}
+
+// RecordQueued records that the supplied repo was queued by the run with the supplied ID, to be processed by a later
+// continue of the run
+func (s *Store) RecordQueued(runID string, repo *github.Repository) error {
+	return s.updateRepo(runID, repo, func(record *Repo) {
+		record.Outcome = OutcomeQueued
+		record.Error = ""
+	})
+}
+
+// ListQueuedRepos returns the keys of the repos the run with the supplied ID queued, in the format of <owner>/<name>,
+// prefixed with <host>/ for repos on a GitHub Enterprise Server
+func (s *Store) ListQueuedRepos(runID string) ([]string, error) {
+	repos, err := s.ListRepos(runID)
+	if err != nil {
+		return nil, err
+	}
+	queued := []string{}
+	for _, repo := range repos {
+		if repo.Outcome == OutcomeQueued {
+			queued = append(queued, repo.Key())
+		}
+	}
+	return queued, nil
+}
+
+// RecordCheckpoint records that processing the supplied repo reached the supplied checkpoint in the run with the
+// supplied ID. Checkpoints only move forward, so that a resumed run that starts a repo over doesn't forget how far the
+// interrupted run got
+func (s *Store) RecordCheckpoint(runID string, repo *github.Repository, checkpoint string) error {
+	return s.updateRepo(runID, repo, func(record *Repo) {
+		if !record.ReachedCheckpoint(checkpoint) {
+			record.Checkpoint = checkpoint
+		}
+	})
+}
+
+// RecordPullRequest records a pull request opened for the supplied repo by the run with the supplied ID
+func (s *Store) RecordPullRequest(runID string, repo *github.Repository, pr PullRequest) error {
+	return s.updateRepo(runID, repo, func(record *Repo) {
+		record.PullRequests = append(record.PullRequests, pr)
+	})
+}
+
+// RecordEvents records the stats events tracked against each repo by the run with the supplied ID
+func (s *Store) RecordEvents(runID string, events map[types.Event][]*github.Repository) error {
+	if s == nil {
+		return nil
+	}
+
+	eventsByRepo := map[string][]string{}
+	reposByKey := map[string]*github.Repository{}
+	for event, repos := range events {
+		for _, repo := range repos {
+			key := util.RepoKey(repo)
+			eventsByRepo[key] = append(eventsByRepo[key], string(event))
+			reposByKey[key] = repo
+		}
+	}
+
+	for key, repoEvents := range eventsByRepo {
+		sort.Strings(repoEvents)
+		err := s.updateRepo(runID, reposByKey[key], func(record *Repo) {
+			record.Events = repoEvents
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRun returns the run with the supplied ID, or a RunNotFoundErr if there is none
+func (s *Store) GetRun(runID string) (*Run, error) {
+	if s == nil {
+		return nil, errors.WithStackTrace(types.RunNotFoundErr{RunID: runID})
+	}
+
+	run := &Run{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runBucket, err := getRunBucket(tx, runID)
+		if err != nil {
+			return err
+		}
+		return getJSON(runBucket, runKey, run)
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return run, nil
+}
+
+// ListRuns returns every recorded run, oldest first
+func (s *Store) ListRuns() ([]*Run, error) {
+	runs := []*Run{}
+	if s == nil {
+		return runs, nil
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).ForEach(func(runID []byte, _ []byte) error {
+			run := &Run{}
+			if err := getJSON(tx.Bucket(runsBucket).Bucket(runID), runKey, run); err != nil {
+				return err
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// GetRepo returns the record of the supplied repo in the run with the supplied ID, or nil if the run never selected it
+func (s *Store) GetRepo(runID string, repo *github.Repository) (*Repo, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	var record *Repo
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runBucket, err := getRunBucket(tx, runID)
+		if err != nil {
+			return err
+		}
+		existing := runBucket.Bucket(reposBucket).Get([]byte(util.RepoKey(repo)))
+		if existing == nil {
+			return nil
+		}
+		record = &Repo{}
+		return json.Unmarshal(existing, record)
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return record, nil
+}
+
+// ListRepos returns the records of every repo selected by the run with the supplied ID, sorted by full name and then by
+// host
+func (s *Store) ListRepos(runID string) ([]*Repo, error) {
+	if s == nil {
+		return nil, errors.WithStackTrace(types.RunNotFoundErr{RunID: runID})
+	}
+
+	repos := []*Repo{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		runBucket, err := getRunBucket(tx, runID)
+		if err != nil {
+			return err
+		}
+		return runBucket.Bucket(reposBucket).ForEach(func(key []byte, value []byte) error {
+			repo := &Repo{}
+			if err := json.Unmarshal(value, repo); err != nil {
+				return err
+			}
+			repos = append(repos, repo)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].FullName() != repos[j].FullName() {
+			return repos[i].FullName() < repos[j].FullName()
+		}
+		return repos[i].Host < repos[j].Host
+	})
+	return repos, nil
+}
+
+// updateRepo applies the supplied update to the record of the supplied repo in the run with the supplied ID, creating
+// the record if it doesn't exist yet. The URL of the repo is recorded along with it, so that subcommands acting on the
+// pull requests of a run later can tell which host the repo is on. Each update runs in its own transaction, so it is
+// safe to call from the goroutines that process repos concurrently
+func (s *Store) updateRepo(runID string, repo *github.Repository, update func(record *Repo)) error {
+	if s == nil {
+		return nil
+	}
+	return errors.WithStackTrace(s.db.Update(func(tx *bolt.Tx) error {
+		runBucket, err := getRunBucket(tx, runID)
+		if err != nil {
+			return err
+		}
+		repos := runBucket.Bucket(reposBucket)
+
+		record := Repo{Host: util.RepoHost(repo), Owner: repo.GetOwner().GetLogin(), Name: repo.GetName()}
+		key := []byte(record.Key())
+		if existing := repos.Get(key); existing != nil {
+			if err := json.Unmarshal(existing, &record); err != nil {
+				return err
+			}
+		}
+
+		if url := repo.GetHTMLURL(); url != "" {
+			record.URL = url
+		}
+		update(&record)
+		record.UpdatedAt = time.Now()
+
+		return putJSON(repos, key, record)
+	}))
+}
+
+// getRunBucket returns the bucket holding the run with the supplied ID, or a RunNotFoundErr if there is none
+func getRunBucket(tx *bolt.Tx, runID string) (*bolt.Bucket, error) {
+	runBucket := tx.Bucket(runsBucket).Bucket([]byte(runID))
+	if runBucket == nil {
+		return nil, types.RunNotFoundErr{RunID: runID}
+	}
+	return runBucket, nil
+}
+
+// checkpointIndex returns the position of the supplied checkpoint in the order checkpoints are reached, or -1 if the
+// repo hasn't reached any yet
+func checkpointIndex(checkpoint string) int {
+	for i, candidate := range checkpoints {
+		if candidate == checkpoint {
+			return i
+		}
+	}
+	return -1
+}
+
+func putJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, encoded)
+}
+
+func getJSON(bucket *bolt.Bucket, key []byte, value interface{}) error {
+	encoded := bucket.Get(key)
+	if encoded == nil {
+		return nil
+	}
+	return json.Unmarshal(encoded, value)
+}
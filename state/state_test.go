@@ -0,0 +1,237 @@
+package state
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	goerrors "github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepo(owner string, name string) *github.Repository {
+	return &github.Repository{
+		Owner: &github.User{Login: github.String(owner)},
+		Name:  github.String(name),
+	}
+}
+
+// Test that a run, its repos, their outcomes and pull requests survive reopening the store
+func TestStoreRecordsRuns(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nested", "state.db")
+
+	store, err := Open(path)
+	require.NoError(t, err)
+
+	terragrunt := newTestRepo("gruntwork-io", "terragrunt")
+	terragrunt.HTMLURL = github.String("https://github.example.com/gruntwork-io/terragrunt")
+	fetch := newTestRepo("gruntwork-io", "fetch")
+	startedAt := time.Now()
+
+	require.NoError(t, store.StartRun(Run{ID: "run-1", Command: []string{"touch", "file"}, BranchName: "update", StartedAt: startedAt}))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{terragrunt, fetch}))
+	require.NoError(t, store.RecordPullRequest("run-1", terragrunt, PullRequest{Number: 7, URL: "https://github.com/gruntwork-io/terragrunt/pull/7", Branch: "update"}))
+	require.NoError(t, store.RecordOutcome("run-1", terragrunt, nil))
+	require.NoError(t, store.RecordOutcome("run-1", fetch, errors.New("clone failed")))
+	require.NoError(t, store.RecordEvents("run-1", map[types.Event][]*github.Repository{"pull-request-open-error": {fetch}}))
+	require.NoError(t, store.FinishRun("run-1", startedAt.Add(time.Minute)))
+	require.NoError(t, store.Close())
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	run, err := store.GetRun("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"touch", "file"}, run.Command)
+	assert.Equal(t, time.Minute, run.FinishedAt.Sub(run.StartedAt))
+
+	repos, err := store.ListRepos("run-1")
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+
+	assert.Equal(t, "gruntwork-io/fetch", repos[0].FullName())
+	assert.Equal(t, OutcomeFailed, repos[0].Outcome)
+	assert.Equal(t, "clone failed", repos[0].Error)
+	assert.Equal(t, []string{"pull-request-open-error"}, repos[0].Events)
+
+	assert.Equal(t, "gruntwork-io/terragrunt", repos[1].FullName())
+	assert.Equal(t, "https://github.example.com/gruntwork-io/terragrunt", repos[1].URL)
+	assert.Equal(t, OutcomeSucceeded, repos[1].Outcome)
+	require.Len(t, repos[1].PullRequests, 1)
+	assert.Equal(t, 7, repos[1].PullRequests[0].Number)
+
+	runs, err := store.ListRuns()
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	_, err = store.GetRun("run-2")
+	assert.Error(t, err)
+}
+
+// Test that a nil store, used when --skip-state is passed, records nothing without failing
+// Not parallel, since it shortens the wait for the lock of every store opened meanwhile
+func TestOpenReturnsLockedErrWhileAnotherStoreHoldsIt(t *testing.T) {
+	defer func(timeout time.Duration) { lockTimeout = timeout }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.db")
+
+	store, err := Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, err = Open(path)
+	assert.Equal(t, types.StateStoreLockedErr{Path: path}, goerrors.Unwrap(err))
+}
+
+func TestNilStoreRecordsNothing(t *testing.T) {
+	t.Parallel()
+
+	var store *Store
+	assert.NoError(t, store.StartRun(Run{ID: "run-1"}))
+	assert.NoError(t, store.RecordOutcome("run-1", newTestRepo("gruntwork-io", "fetch"), nil))
+	assert.NoError(t, store.Close())
+}
+
+// Test that checkpoints only move forward, and that re-selecting the repos of a resumed run keeps their outcomes
+func TestStoreRecordsCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	terragrunt := newTestRepo("gruntwork-io", "terragrunt")
+	fetch := newTestRepo("gruntwork-io", "fetch")
+
+	require.NoError(t, store.StartRun(Run{ID: "run-1"}))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{terragrunt}))
+	require.NoError(t, store.RecordCheckpoint("run-1", terragrunt, CheckpointPushed))
+	require.NoError(t, store.RecordCheckpoint("run-1", terragrunt, CheckpointCloned))
+	require.NoError(t, store.RecordOutcome("run-1", terragrunt, nil))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{terragrunt, fetch}))
+
+	record, err := store.GetRepo("run-1", terragrunt)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, CheckpointPushed, record.Checkpoint)
+	assert.Equal(t, OutcomeSucceeded, record.Outcome)
+	assert.True(t, record.ReachedCheckpoint(CheckpointCommandRun))
+	assert.False(t, record.ReachedCheckpoint(CheckpointPullRequestOpened))
+
+	record, err = store.GetRepo("run-1", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeSelected, record.Outcome)
+	assert.False(t, record.ReachedCheckpoint(CheckpointCloned))
+
+	record, err = store.GetRepo("run-1", newTestRepo("gruntwork-io", "cloud-nuke"))
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+// Test that queued repos are listed for continue until they get an outcome of their own
+func TestStoreListsQueuedRepos(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	terragrunt := newTestRepo("gruntwork-io", "terragrunt")
+	fetch := newTestRepo("gruntwork-io", "fetch")
+	cloudNuke := newTestRepo("gruntwork-io", "cloud-nuke")
+
+	require.NoError(t, store.StartRun(Run{ID: "run-1", BranchName: "update", StartedAt: time.Now()}))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{terragrunt, fetch, cloudNuke}))
+	require.NoError(t, store.RecordOutcome("run-1", terragrunt, nil))
+	require.NoError(t, store.RecordQueued("run-1", fetch))
+	require.NoError(t, store.RecordQueued("run-1", cloudNuke))
+
+	queued, err := store.ListQueuedRepos("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gruntwork-io/cloud-nuke", "gruntwork-io/fetch"}, queued)
+
+	// A continue that gets to a queued repo records its outcome, and it's no longer queued
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{fetch, cloudNuke}))
+	require.NoError(t, store.RecordOutcome("run-1", fetch, nil))
+	queued, err = store.ListQueuedRepos("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gruntwork-io/cloud-nuke"}, queued)
+}
+
+// Test that repos of the same name on github.com and on a GitHub Enterprise Server get records of their own
+func TestStoreKeysReposByHost(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-state-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	public := newTestRepo("gruntwork-io", "fetch")
+	public.HTMLURL = github.String("https://github.com/gruntwork-io/fetch")
+	enterprise := newTestRepo("gruntwork-io", "fetch")
+	enterprise.HTMLURL = github.String("https://github.example.com/gruntwork-io/fetch")
+
+	require.NoError(t, store.StartRun(Run{ID: "run-1", BranchName: "update", StartedAt: time.Now()}))
+	require.NoError(t, store.RecordSelectedRepos("run-1", []*github.Repository{public, enterprise}))
+	require.NoError(t, store.RecordCheckpoint("run-1", public, CheckpointPushed))
+	require.NoError(t, store.RecordPullRequest("run-1", public, PullRequest{Number: 7, URL: "https://github.com/gruntwork-io/fetch/pull/7", Branch: "update"}))
+	require.NoError(t, store.RecordOutcome("run-1", public, nil))
+	require.NoError(t, store.RecordQueued("run-1", enterprise))
+	require.NoError(t, store.RecordEvents("run-1", map[types.Event][]*github.Repository{"pull-request-open-error": {enterprise}}))
+
+	record, err := store.GetRepo("run-1", public)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "", record.Host)
+	assert.Equal(t, OutcomeSucceeded, record.Outcome)
+	assert.Equal(t, CheckpointPushed, record.Checkpoint)
+	assert.Len(t, record.PullRequests, 1)
+	assert.Empty(t, record.Events)
+
+	record, err = store.GetRepo("run-1", enterprise)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "github.example.com", record.Host)
+	assert.Equal(t, OutcomeQueued, record.Outcome)
+	assert.Equal(t, "", record.Checkpoint)
+	assert.Empty(t, record.PullRequests)
+	assert.Equal(t, []string{"pull-request-open-error"}, record.Events)
+
+	repos, err := store.ListRepos("run-1")
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+	assert.Equal(t, "gruntwork-io/fetch", repos[0].Key())
+	assert.Equal(t, "github.example.com/gruntwork-io/fetch", repos[1].Key())
+
+	queued, err := store.ListQueuedRepos("run-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"github.example.com/gruntwork-io/fetch"}, queued)
+}
@@ -0,0 +1,57 @@
+package stats
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+// classifyFailure returns the kind of error the supplied error, returned by processing a repo that was tracked under
+// the supplied events, is. Rate limits and timeouts are recognized from the error itself, whichever step they happened
+// in. Otherwise, the events tell which step of processing the repo failed
+func classifyFailure(err error, events map[types.Event]bool) types.FailureReason {
+	cause := errors.Unwrap(err)
+	message := strings.ToLower(err.Error())
+
+	switch cause.(type) {
+	case *github.RateLimitError, *github.AbuseRateLimitError:
+		return types.FailureRateLimited
+	case types.RepoTimedOutErr:
+		return types.FailureTimedOut
+	}
+	if strings.Contains(message, "rate limit") {
+		return types.FailureRateLimited
+	}
+	if cause == context.DeadlineExceeded || events[RepoTimedOut] || strings.Contains(message, "deadline exceeded") || strings.Contains(message, "timeout") {
+		return types.FailureTimedOut
+	}
+
+	switch {
+	case events[RepoFailedToClone]:
+		return types.FailureCloneError
+	case events[CommandErrorOccurredDuringExecution]:
+		return types.FailureCommandExit
+	case events[PushBranchFailed]:
+		return types.FailurePushRejected
+	case events[PullRequestOpenErr]:
+		if isValidationFailure(cause, message) {
+			return types.FailurePullRequestInvalid
+		}
+		return types.FailurePullRequestError
+	default:
+		return types.FailureOther
+	}
+}
+
+// isValidationFailure returns whether the supplied error is a 422 response of the GitHub API, e.g. because a pull
+// request already exists for the branch or the branch has no commits the base branch doesn't have
+func isValidationFailure(cause error, message string) bool {
+	if errorResponse, ok := cause.(*github.ErrorResponse); ok && errorResponse.Response != nil {
+		return errorResponse.Response.StatusCode == http.StatusUnprocessableEntity
+	}
+	return strings.Contains(message, "422")
+}
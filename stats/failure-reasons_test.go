@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	t.Parallel()
+
+	request, _ := http.NewRequest(http.MethodPost, "https://api.github.com/repos/gruntwork-io/terragrunt/pulls", nil)
+	validationErr := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusUnprocessableEntity, Request: request}, Message: "Validation Failed"}
+	serverErr := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadGateway, Request: request}, Message: "Bad Gateway"}
+
+	testCases := []struct {
+		name     string
+		err      error
+		events   []types.Event
+		expected types.FailureReason
+	}{
+		{"clone", fmt.Errorf("authentication required"), []types.Event{RepoFailedToClone}, types.FailureCloneError},
+		{"command", fmt.Errorf("exit status 1"), []types.Event{RepoSuccessfullyCloned, CommandErrorOccurredDuringExecution}, types.FailureCommandExit},
+		{"push", fmt.Errorf("non-fast-forward update"), []types.Event{RepoSuccessfullyCloned, PushBranchFailed}, types.FailurePushRejected},
+		{"pull request 422", errors.WithStackTrace(validationErr), []types.Event{PullRequestOpenErr}, types.FailurePullRequestInvalid},
+		{"pull request other", errors.WithStackTrace(serverErr), []types.Event{PullRequestOpenErr}, types.FailurePullRequestError},
+		{"rate limited", errors.WithStackTrace(&github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden, Request: request}, Message: "API rate limit exceeded"}), []types.Event{PullRequestOpenErr}, types.FailureRateLimited},
+		{"secondary rate limit", fmt.Errorf("You have exceeded a secondary rate limit"), []types.Event{PushBranchFailed}, types.FailureRateLimited},
+		{"timed out", errors.WithStackTrace(types.RepoTimedOutErr{Timeout: time.Minute}), []types.Event{RepoTimedOut}, types.FailureTimedOut},
+		{"other", fmt.Errorf("something else"), []types.Event{RepoSuccessfullyCloned}, types.FailureOther},
+	}
+
+	for _, testCase := range testCases {
+		events := map[types.Event]bool{}
+		for _, event := range testCase.events {
+			events[event] = true
+		}
+		assert.Equal(t, testCase.expected, classifyFailure(testCase.err, events), testCase.name)
+	}
+}
+
+// Test that the reason of a failure is tracked along with its error, from the events the repo was tracked under
+func TestTrackErrorClassifiesFailure(t *testing.T) {
+	t.Parallel()
+
+	repo := &github.Repository{Owner: &github.User{Login: github.String("gruntwork-io")}, Name: github.String("terragrunt")}
+	tracker := NewStatsTracker()
+	tracker.TrackSingle(PushBranchFailed, repo)
+	tracker.TrackError(repo, fmt.Errorf("rejected"))
+
+	report := tracker.GenerateRunReport()
+	assert.Equal(t, "rejected", report.Errors["gruntwork-io/terragrunt"])
+	assert.Equal(t, types.FailurePushRejected, report.FailureReasons["gruntwork-io/terragrunt"])
+}
@@ -1,12 +1,18 @@
 package stats
 
 import (
+	"io"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/common"
+	"github.com/gruntwork-io/git-xargs/manifest"
 	"github.com/gruntwork-io/git-xargs/printer"
 	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
 )
 
 const (
@@ -44,6 +50,8 @@ const (
 	WorktreeStatusDirty types.Event = "worktree-status-dirty"
 	// WorktreeStatusClean denotes a repo that did not have any local file changes following command execution
 	WorktreeStatusClean types.Event = "worktree-status-clean"
+	// ChangesAlreadyOnBaseBranch denotes a repo whose changes were skipped because they are already on its base branch
+	ChangesAlreadyOnBaseBranch types.Event = "changes-already-on-base-branch"
 	// WorktreeAddFileFailed denotes a failure to add at least one file to the git stage following command execution
 	WorktreeAddFileFailed types.Event = "worktree-add-file-failed"
 	// CommitChangesFailed denotes an error git committing our file changes to the local repo
@@ -72,13 +80,132 @@ const (
 	RepoDoesntSupportDraftPullRequestsErr types.Event = "repo-not-compatible-with-pull-config"
 	// BaseBranchTargetInvalidErr denotes a repo that does not have the base branch specified by the user
 	BaseBranchTargetInvalidErr types.Event = "base-branch-target-invalid"
+	// PullRequestOpenedAsConditionalDraft denotes a repo whose pull request was opened in draft mode because one of the --draft-if-* rules matched
+	PullRequestOpenedAsConditionalDraft types.Event = "pull-request-opened-as-conditional-draft"
+	// PullRequestMarkedReady denotes a repo whose draft pull request was marked as ready for review by the ready subcommand
+	PullRequestMarkedReady types.Event = "pull-request-marked-ready"
+	// PullRequestMarkReadyErr denotes a repo whose draft pull request could not be marked as ready for review
+	PullRequestMarkReadyErr types.Event = "pull-request-mark-ready-error"
+	// NoDraftPullRequestFound denotes a repo that had no open draft pull request for the specified branch
+	NoDraftPullRequestFound types.Event = "no-draft-pull-request-found"
+	// PullRequestAddedToProject denotes a repo whose pull request was added to the Projects (v2) board supplied via --project
+	PullRequestAddedToProject types.Event = "pull-request-added-to-project"
+	// PullRequestAddToProjectErr denotes a repo whose pull request could not be added to the Projects (v2) board supplied via --project
+	PullRequestAddToProjectErr types.Event = "pull-request-add-to-project-error"
+	// PullRequestAlreadyOpenSkipped denotes a repo that was skipped entirely because a pull request from the specified branch was already open
+	PullRequestAlreadyOpenSkipped types.Event = "pull-request-already-open-skipped"
+	// PullRequestLookupErr denotes a repo whose open pull requests could not be looked up via the GitHub API
+	PullRequestLookupErr types.Event = "pull-request-lookup-error"
+	// ReviewersRequested denotes a repo whose pull request had reviewers from the --reviewers pool requested
+	ReviewersRequested types.Event = "reviewers-requested"
+	// ReviewersRequestErr denotes a repo whose pull request could not have reviewers requested
+	ReviewersRequestErr types.Event = "reviewers-request-error"
+	// AssigneesAdded denotes a repo whose pull request was assigned to members of the --assignees pool
+	AssigneesAdded types.Event = "assignees-added"
+	// AssigneesAddErr denotes a repo whose pull request could not be assigned
+	AssigneesAddErr types.Event = "assignees-add-error"
+	// CommitStatusErr denotes a repo whose pushed branch could not have the git-xargs commit status set on it
+	CommitStatusErr types.Event = "commit-status-error"
+	// ChangesSplitAcrossPullRequests denotes a repo whose changes were split across several pull requests because they
+	// touched more files than --max-files-per-pull-request
+	ChangesSplitAcrossPullRequests types.Event = "changes-split-across-pull-requests"
+	// PullRequestApproved denotes a repo whose pull request was approved by the --approve-and-merge identity
+	PullRequestApproved types.Event = "pull-request-approved"
+	// PullRequestApproveErr denotes a repo whose pull request could not be approved by the --approve-and-merge identity
+	PullRequestApproveErr types.Event = "pull-request-approve-error"
+	// PullRequestMerged denotes a repo whose pull request was merged via --approve-and-merge
+	PullRequestMerged types.Event = "pull-request-merged"
+	// PullRequestMergeErr denotes a repo whose pull request could not be merged via --approve-and-merge
+	PullRequestMergeErr types.Event = "pull-request-merge-error"
+	// PullRequestNotMergeable denotes a repo whose pull request was not merged because it wasn't ready to merge
+	PullRequestNotMergeable types.Event = "pull-request-not-mergeable"
+	// PullRequestClosed denotes a repo whose pull request was closed by the close subcommand
+	PullRequestClosed types.Event = "pull-request-closed"
+	// PullRequestCloseErr denotes a repo whose pull request could not be closed by the close subcommand
+	PullRequestCloseErr types.Event = "pull-request-close-error"
+	// PullRequestCommentErr denotes a repo whose pull request could not have the --close-comment added to it
+	PullRequestCommentErr types.Event = "pull-request-comment-error"
+	// BranchDeleted denotes a repo whose git-xargs branch was deleted after its pull request was merged or closed
+	BranchDeleted types.Event = "branch-deleted"
+	// BranchDeleteErr denotes a repo whose git-xargs branch could not be deleted
+	BranchDeleteErr types.Event = "branch-delete-error"
+	// BranchListErr denotes a repo whose branches could not be listed
+	BranchListErr types.Event = "branch-list-error"
+	// RepoAlreadyProcessedSkipped denotes a repo that was skipped because the run being resumed with --resume already processed it
+	RepoAlreadyProcessedSkipped types.Event = "repo-already-processed-skipped"
+	// ResumedFromPushedBranch denotes a repo whose pull request was opened for the branch pushed by the run being resumed
+	ResumedFromPushedBranch types.Event = "resumed-from-pushed-branch"
+	// RepoAddedToPlan denotes a repo whose changes were recorded in the plan written by git-xargs plan
+	RepoAddedToPlan types.Event = "repo-added-to-plan"
+	// PlanRepoErr denotes a repo whose changes could not be recorded in, or applied from, a plan
+	PlanRepoErr types.Event = "plan-repo-error"
+	// PlanStale denotes a repo that git-xargs apply skipped because planned files changed since the plan was created
+	PlanStale types.Event = "plan-stale"
+	// RunCancelledSkipped denotes a repo that was not processed because the run was cancelled
+	RunCancelledSkipped types.Event = "run-cancelled-skipped"
+	// RepoTimedOut denotes a repo that was cancelled because processing it took longer than --repo-timeout
+	RepoTimedOut types.Event = "repo-timed-out"
+	// RunAbortedSkipped denotes a repo that was not processed because the run was aborted after too many repos failed
+	RunAbortedSkipped types.Event = "run-aborted-skipped"
+	// StaleBranchDeleteSkipped denotes a repo with stale git-xargs branches that were not deleted because of --dry-run
+	StaleBranchDeleteSkipped types.Event = "stale-branch-delete-skipped"
+	// RevertConflict denotes a repo whose merged pull request was not reverted because the files it changed have
+	// changed since
+	RevertConflict types.Event = "revert-conflict"
+	// RevertUnsupported denotes a repo whose merged pull request was not reverted because the state of the repo before it
+	// was merged could not be found
+	RevertUnsupported types.Event = "revert-unsupported"
+	// RevertFailed denotes a repo whose merged pull request could not be reverted
+	RevertFailed types.Event = "revert-failed"
+	// RunMarkerLabelErr denotes a repo whose pull request could not have the run's marker label added to it
+	RunMarkerLabelErr types.Event = "run-marker-label-error"
+	// PathLabelsErr denotes a repo whose pull request could not have the labels of the --path-labels rules added to it
+	PathLabelsErr types.Event = "path-labels-error"
+	// PullRequestHeldForSchedule denotes a repo whose pull request was held until the --pr-schedule window opened
+	PullRequestHeldForSchedule types.Event = "pull-request-held-for-schedule"
+	// RepoQueuedForOpenPRBudget denotes a repo that was queued for the continue subcommand because --max-open-prs pull
+	// requests were already open
+	RepoQueuedForOpenPRBudget types.Event = "repo-queued-for-open-pr-budget"
+	// RepoNotPickedSkipped denotes a repo that was not processed because it was deselected in the --pick repo picker
+	RepoNotPickedSkipped types.Event = "repo-not-picked-skipped"
+	// RepoOptedOutSkipped denotes a repo that was skipped because it carries the gitxargs-ignore topic or has a
+	// .git-xargs-ignore file
+	RepoOptedOutSkipped types.Event = "repo-opted-out-skipped"
+	// RepoConfigOptedOutSkipped denotes a repo that was skipped because its .git-xargs.yml opted out of a --tag of the run
+	RepoConfigOptedOutSkipped types.Event = "repo-config-opted-out-skipped"
+	// RepoConfigInvalid denotes a repo whose .git-xargs.yml could not be read or applied
+	RepoConfigInvalid types.Event = "repo-config-invalid"
+	// EnvFileInvalid denotes a repo whose env file could not be read, via --env-files-dir or --repo-env-file
+	EnvFileInvalid types.Event = "env-file-invalid"
+	// MonorepoDirectoryNotFound denotes a monorepo in which a directory listed by --monorepo-manifest doesn't exist
+	MonorepoDirectoryNotFound types.Event = "monorepo-directory-not-found"
+	// BranchProtectionLookupErr denotes a repo whose base branch protection could not be looked up
+	BranchProtectionLookupErr types.Event = "branch-protection-lookup-error"
+	// BaseBranchRequiresPullRequest denotes a repo whose base branch only takes changes via pull requests, so that the
+	// changes of a run with --skip-pull-requests can't be pushed to it
+	BaseBranchRequiresPullRequest types.Event = "base-branch-requires-pull-request"
+	// BaseBranchRequiresSignedCommits denotes a repo that was skipped because its base branch requires signed commits,
+	// and --signing-key wasn't passed to sign them
+	BaseBranchRequiresSignedCommits types.Event = "base-branch-requires-signed-commits"
+	// MergeMethodAdaptedToLinearHistory denotes a repo whose pull request is squashed rather than merged, because its base
+	// branch requires a linear history
+	MergeMethodAdaptedToLinearHistory types.Event = "merge-method-adapted-to-linear-history"
+	// ApproveAndMergeAwaitsChecks denotes a repo whose pull request is not merged by --approve-and-merge, because the
+	// status checks its base branch requires didn't pass within --merge-checks-timeout
+	ApproveAndMergeAwaitsChecks types.Event = "approve-and-merge-awaits-checks"
+	// ApproveAndMergeNeedsApprovals denotes a repo whose pull request is not merged by --approve-and-merge, because its
+	// base branch requires more approvals than the single one it gives
+	ApproveAndMergeNeedsApprovals types.Event = "approve-and-merge-needs-approvals"
+	// FeatureUnsupportedByGithubHost denotes a repo on a GitHub Enterprise Server whose version doesn't support one of
+	// the features passed, which was disabled for the repo
+	FeatureUnsupportedByGithubHost types.Event = "feature-unsupported-by-github-host"
 )
 
 var allEvents = []types.AnnotatedEvent{
 	{Event: FetchedViaGithubAPI, Description: "Repos successfully fetched via Github API"},
 	{Event: DryRunSet, Description: "Repos that were not modified in any way because this was a dry-run"},
 	{Event: ReposSelected, Description: "All repos that were targeted for processing AFTER filtering missing / malformed repos"},
-	{Event: ReposArchivedSkipped, Description: "All repos that were filtered out with the --skip-archived-repos flag"},
+	{Event: ReposArchivedSkipped, Description: "All repos that were filtered out with the --skip-archived-repos flag", Skip: true},
 	{Event: TargetBranchNotFound, Description: "Repos whose target branch was not found"},
 	{Event: TargetBranchAlreadyExists, Description: "Repos whose target branch already existed"},
 	{Event: TargetBranchLookupErr, Description: "Repos whose target branches could not be looked up due to an API error"},
@@ -90,6 +217,7 @@ var allEvents = []types.AnnotatedEvent{
 	{Event: WorktreeStatusCheckFailed, Description: "Repos for which the git status command failed following command execution"},
 	{Event: WorktreeStatusDirty, Description: "Repos that showed file changes to their working directory following command execution"},
 	{Event: WorktreeStatusClean, Description: "Repos that showed NO file changes to their working directory following command execution"},
+	{Event: ChangesAlreadyOnBaseBranch, Description: "Repos that were skipped because their changes were already applied on their base branch", Skip: true},
 	{Event: CommitChangesFailed, Description: "Repos whose file changes failed to be committed for some reason"},
 	{Event: PushBranchFailed, Description: "Repos whose tool-specific branch containing changes failed to push to remote origin"},
 	{Event: PushBranchSkipped, Description: "Repos whose local branch was not pushed because the --dry-run flag was set"},
@@ -103,6 +231,60 @@ var allEvents = []types.AnnotatedEvent{
 	{Event: RepoFlagSuppliedRepoMalformed, Description: "Repos passed via the --repo flag that were malformed (missing their Github org prefix?) and therefore unprocessable"},
 	{Event: RepoDoesntSupportDraftPullRequestsErr, Description: "Repos that do not support Draft PRs (--draft flag was passed)"},
 	{Event: BaseBranchTargetInvalidErr, Description: "Repos that did not have the branch specified by --base-branch-name"},
+	{Event: PullRequestOpenedAsConditionalDraft, Description: "Repos whose pull requests were opened in draft mode because one of the --draft-if-* rules matched"},
+	{Event: PullRequestMarkedReady, Description: "Repos whose draft pull requests were marked as ready for review"},
+	{Event: PullRequestMarkReadyErr, Description: "Repos whose draft pull requests could not be marked as ready for review"},
+	{Event: NoDraftPullRequestFound, Description: "Repos that had no open draft pull request for the specified branch"},
+	{Event: PullRequestAddedToProject, Description: "Repos whose pull requests were added to the project supplied via --project"},
+	{Event: PullRequestAddToProjectErr, Description: "Repos whose pull requests could not be added to the project supplied via --project"},
+	{Event: PullRequestAlreadyOpenSkipped, Description: "Repos that were skipped because a pull request from the specified branch was already open", Skip: true},
+	{Event: PullRequestLookupErr, Description: "Repos whose open pull requests could not be looked up via the Github API"},
+	{Event: ReviewersRequested, Description: "Repos whose pull requests had reviewers requested from the --reviewers pool"},
+	{Event: ReviewersRequestErr, Description: "Repos whose pull requests could not have reviewers requested"},
+	{Event: AssigneesAdded, Description: "Repos whose pull requests were assigned to members of the --assignees pool"},
+	{Event: AssigneesAddErr, Description: "Repos whose pull requests could not be assigned"},
+	{Event: CommitStatusErr, Description: "Repos whose pushed branches could not have the git-xargs commit status set"},
+	{Event: ChangesSplitAcrossPullRequests, Description: "Repos whose changes were split across several pull requests"},
+	{Event: PullRequestApproved, Description: "Repos whose pull requests were approved by the approving identity"},
+	{Event: PullRequestApproveErr, Description: "Repos whose pull requests could not be approved by the approving identity"},
+	{Event: PullRequestMerged, Description: "Repos whose pull requests were merged"},
+	{Event: PullRequestMergeErr, Description: "Repos whose pull requests could not be merged"},
+	{Event: PullRequestNotMergeable, Description: "Repos whose pull requests were not merged because they are drafts, have failing or pending checks, have changes requested or conflict with their base branch"},
+	{Event: PullRequestClosed, Description: "Repos whose pull requests were closed"},
+	{Event: PullRequestCloseErr, Description: "Repos whose pull requests could not be closed"},
+	{Event: PullRequestCommentErr, Description: "Repos whose pull requests could not have the closing comment added"},
+	{Event: BranchDeleted, Description: "Repos whose git-xargs branches were deleted"},
+	{Event: BranchDeleteErr, Description: "Repos whose git-xargs branches could not be deleted"},
+	{Event: BranchListErr, Description: "Repos whose branches could not be listed"},
+	{Event: RepoAlreadyProcessedSkipped, Description: "Repos that were skipped because the run being resumed with --resume already processed them", Skip: true},
+	{Event: ResumedFromPushedBranch, Description: "Repos whose pull request was opened for the branch the run being resumed had already pushed"},
+	{Event: RepoAddedToPlan, Description: "Repos whose changes were recorded in the plan"},
+	{Event: PlanRepoErr, Description: "Repos whose changes could not be recorded in, or applied from, the plan"},
+	{Event: PlanStale, Description: "Repos that were skipped because planned files have changed since the plan was created"},
+	{Event: RunCancelledSkipped, Description: "Repos that were not processed because the run was cancelled", Skip: true},
+	{Event: RepoTimedOut, Description: "Repos that were cancelled because processing them took longer than --repo-timeout"},
+	{Event: RunAbortedSkipped, Description: "Repos that were not processed because the run was aborted after too many repos failed", Skip: true},
+	{Event: StaleBranchDeleteSkipped, Description: "Repos with stale git-xargs branches that were not deleted because --dry-run was passed"},
+	{Event: RevertConflict, Description: "Repos whose changes were not reverted because the changed files have changed since they were merged"},
+	{Event: RevertUnsupported, Description: "Repos whose changes were not reverted because the state of the repo before their pull requests were merged could not be found"},
+	{Event: RevertFailed, Description: "Repos whose changes could not be reverted"},
+	{Event: RunMarkerLabelErr, Description: "Repos whose pull requests could not have the run's marker label added"},
+	{Event: PathLabelsErr, Description: "Repos whose pull requests could not have the labels of the --path-labels rules added"},
+	{Event: PullRequestHeldForSchedule, Description: "Repos whose pull requests were held until the --pr-schedule window opened"},
+	{Event: RepoQueuedForOpenPRBudget, Description: "Repos that were queued for git-xargs continue because --max-open-prs pull requests were already open", Skip: true},
+	{Event: RepoOptedOutSkipped, Description: "Repos that were not processed because they opted out of git-xargs via the gitxargs-ignore topic or a .git-xargs-ignore file", Skip: true},
+	{Event: RepoConfigOptedOutSkipped, Description: "Repos that were not processed because their .git-xargs.yml opted out of a --tag of the run", Skip: true},
+	{Event: RepoConfigInvalid, Description: "Repos whose .git-xargs.yml could not be read or applied"},
+	{Event: EnvFileInvalid, Description: "Repos whose env file could not be read"},
+	{Event: RepoNotPickedSkipped, Description: "Repos that were not processed because they were deselected in the --pick repo picker", Skip: true},
+	{Event: MonorepoDirectoryNotFound, Description: "Monorepos in which a directory listed by --monorepo-manifest does not exist"},
+	{Event: BranchProtectionLookupErr, Description: "Repos whose base branch protection could not be looked up"},
+	{Event: BaseBranchRequiresPullRequest, Description: "Repos that were not processed because their base branch only takes changes via pull requests"},
+	{Event: BaseBranchRequiresSignedCommits, Description: "Repos that were skipped because their base branch requires signed commits, and --signing-key wasn't passed to sign them", Skip: true},
+	{Event: MergeMethodAdaptedToLinearHistory, Description: "Repos whose pull requests are squashed rather than merged, because their base branch requires a linear history"},
+	{Event: ApproveAndMergeAwaitsChecks, Description: "Repos whose pull requests were not merged, because the status checks their base branch requires didn't pass within --merge-checks-timeout. Merge them with the merge subcommand once the checks pass"},
+	{Event: ApproveAndMergeNeedsApprovals, Description: "Repos whose pull requests were not merged, because their base branch requires more than one approval"},
+	{Event: FeatureUnsupportedByGithubHost, Description: "Repos on a GitHub Enterprise Server too old for some of the features passed, which were disabled for them"},
 }
 
 // RunStats will be a stats-tracker class that keeps score of which repos were touched, which were considered for update, which had branches made, PRs made, which were missing workflows or contexts, or had out of date workflows syntax values, etc
@@ -112,11 +294,24 @@ type RunStats struct {
 	skippedArchivedRepos  map[types.Event][]*github.Repository
 	pulls                 map[string]string
 	draftpulls            map[string]string
+	errors                map[string]string
+	failureReasons        map[string]types.FailureReason
+	diffs                 map[string]string
+	diffStats             map[string]types.DiffStats
+	branches              map[string][]types.PushedBranch
+	durations             map[string]map[types.Phase]time.Duration
 	command               []string
+	runID                 string
 	fileProvidedRepos     []*types.AllowedRepo
 	repoFlagProvidedRepos []*types.AllowedRepo
 	startTime             time.Time
 	skipPullRequests      bool
+	apiRetries            uint64
+	apiCalls              uint64
+	apiCallsByCategory    map[string]uint64
+	rateLimits            map[string]types.RateLimitStatus
+	diffPreviewLines      int
+	customEvents          []types.AnnotatedEvent
 	mutex                 *sync.Mutex
 }
 
@@ -130,6 +325,12 @@ func NewStatsTracker() *RunStats {
 		skippedArchivedRepos:  make(map[types.Event][]*github.Repository),
 		pulls:                 make(map[string]string),
 		draftpulls:            make(map[string]string),
+		errors:                make(map[string]string),
+		failureReasons:        make(map[string]types.FailureReason),
+		diffs:                 make(map[string]string),
+		diffStats:             make(map[string]types.DiffStats),
+		branches:              make(map[string][]types.PushedBranch),
+		durations:             make(map[string]map[types.Phase]time.Duration),
 		command:               []string{},
 		fileProvidedRepos:     fileProvidedRepos,
 		repoFlagProvidedRepos: repoFlagProvidedRepos,
@@ -150,6 +351,25 @@ func (r *RunStats) GetSelectionMode() string {
 	return r.selectionMode
 }
 
+// SetAPIRetries records the number of GitHub API requests that were sent again during the run, because they failed or
+// were rate limited
+func (r *RunStats) SetAPIRetries(retries uint64) {
+	r.apiRetries = retries
+}
+
+// GetAPIRetries returns the number of GitHub API requests that were sent again during the run
+func (r *RunStats) GetAPIRetries() uint64 {
+	return r.apiRetries
+}
+
+// SetAPIUsage records the number of GitHub API requests sent during the run, in total and by endpoint category, and
+// what the GitHub API last reported about each of its rate limits, for the run report
+func (r *RunStats) SetAPIUsage(calls uint64, byCategory map[string]uint64, rateLimits map[string]types.RateLimitStatus) {
+	r.apiCalls = calls
+	r.apiCallsByCategory = byCategory
+	r.rateLimits = rateLimits
+}
+
 // GetTotalRunSeconds returns the total time it took, in seconds, to run all the selected commands against all the targeted repos
 func (r *RunStats) GetTotalRunSeconds() int {
 	s := time.Since(r.startTime).Seconds()
@@ -171,6 +391,18 @@ func (r *RunStats) GetPullRequests() map[string]string {
 	return r.pulls
 }
 
+// GetPullRequestURL returns the URL of the pull request, draft or not, opened for the supplied repo, or an empty string
+// if none was. This function is safe to call from concurrent goroutines
+func (r *RunStats) GetPullRequestURL(repo *github.Repository) string {
+	key := util.RepoKey(repo)
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	if prURL, ok := r.pulls[key]; ok {
+		return prURL
+	}
+	return r.draftpulls[key]
+}
+
 // GetDraftPullRequests returns the inner representation of the draft pull requests that were opened during the lifecycle of a given run
 func (r *RunStats) GetDraftPullRequests() map[string]string {
 	return r.draftpulls
@@ -200,11 +432,66 @@ func (r *RunStats) SetSkipPullRequests(skipPullRequests bool) {
 	r.skipPullRequests = skipPullRequests
 }
 
+// SetDiffPreviewLines sets the number of lines of the diff of each changed repo to preview in the run reports, or 0 to
+// leave the previews out
+func (r *RunStats) SetDiffPreviewLines(lines int) {
+	r.diffPreviewLines = lines
+}
+
 // SetCommand sets the user-supplied command to be run against the targeted repos
 func (r *RunStats) SetCommand(c []string) {
 	r.command = c
 }
 
+// SetRunID sets the ID of the current run, so that it can be printed in the final report
+func (r *RunStats) SetRunID(runID string) {
+	r.runID = runID
+}
+
+// GetRunID returns the ID of the current run
+func (r *RunStats) GetRunID() string {
+	return r.runID
+}
+
+// RegisterEvent adds a custom event category to the run, e.g. for a plugin to track repos that need a manual review,
+// so that the run report lists the repos tracked under it along with those of the built-in events. Repos are tracked
+// under it via TrackSingle. Registering the same event with the same description again does nothing, so that hooks
+// called for every repo can register the events they track. This function is safe to call from concurrent goroutines
+func (r *RunStats) RegisterEvent(annotatedEvent types.AnnotatedEvent) error {
+	if annotatedEvent.Event == "" || annotatedEvent.Description == "" {
+		return errors.WithStackTrace(types.InvalidStatsEventErr{Event: annotatedEvent.Event, Reason: "it needs both a name and a description"})
+	}
+	for _, builtIn := range allEvents {
+		if builtIn.Event == annotatedEvent.Event {
+			return errors.WithStackTrace(types.InvalidStatsEventErr{Event: annotatedEvent.Event, Reason: "it is a built-in event"})
+		}
+	}
+
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	for _, registered := range r.customEvents {
+		if registered.Event != annotatedEvent.Event {
+			continue
+		}
+		if registered != annotatedEvent {
+			return errors.WithStackTrace(types.InvalidStatsEventErr{Event: annotatedEvent.Event, Reason: "it is already registered with a different description"})
+		}
+		return nil
+	}
+	r.customEvents = append(r.customEvents, annotatedEvent)
+	return nil
+}
+
+// Events returns the built-in events, followed by the custom events registered via RegisterEvent, in the order they
+// were registered. The run reports list the repos tracked under each of them, in this order
+func (r *RunStats) Events() []types.AnnotatedEvent {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	events := make([]types.AnnotatedEvent, 0, len(allEvents)+len(r.customEvents))
+	events = append(events, allEvents...)
+	return append(events, r.customEvents...)
+}
+
 // GetMultiple returns the slice of pointers to GitHub repositories filed under the provided event's key
 func (r *RunStats) GetMultiple(event types.Event) []*github.Repository {
 	return r.repos[event]
@@ -221,8 +508,9 @@ func (r *RunStats) TrackSingle(event types.Event, repo *github.Repository) {
 // TrackEventIfMissing prevents the addition of duplicates to the tracking slices. Repos may end up with file changes
 // for example, from multiple command runs, so we don't need the same repo repeated multiple times in the final report
 func TrackEventIfMissing(slice []*github.Repository, repo *github.Repository) []*github.Repository {
+	key := util.RepoKey(repo)
 	for _, existingRepo := range slice {
-		if existingRepo.GetName() == repo.GetName() {
+		if util.RepoKey(existingRepo) == key {
 			// We've already tracked this repo under this event, return the existing slice to avoid adding
 			// it a second time
 			return slice
@@ -231,20 +519,173 @@ func TrackEventIfMissing(slice []*github.Repository, repo *github.Repository) []
 	return append(slice, repo)
 }
 
-// TrackPullRequest stores the successful PR opening for the supplied Repo, at the supplied PR URL
+// TrackPullRequest stores the successful PR opening at the supplied PR URL, under the supplied report name: the
+// util.RepoKey of the repo, followed by the part for changes split across several pull requests
 // This function is safe to call from concurrent goroutines
-func (r *RunStats) TrackPullRequest(repoName, prURL string) {
+func (r *RunStats) TrackPullRequest(reportName, prURL string) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	r.pulls[reportName] = prURL
+}
+
+// TrackDraftPullRequest stores the successful Draft PR opening at the supplied PR URL, under the supplied report name,
+// like TrackPullRequest. This function is safe to call from concurrent goroutines
+func (r *RunStats) TrackDraftPullRequest(reportName, prURL string) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	r.draftpulls[reportName] = prURL
+}
+
+// TrackError stores the error that processing the supplied repo returned, along with the kind of error it is, so that
+// it can be included in the structured run reports. This function is safe to call from concurrent goroutines
+func (r *RunStats) TrackError(repo *github.Repository, err error) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	key := util.RepoKey(repo)
+	r.errors[key] = err.Error()
+	r.failureReasons[key] = classifyFailure(err, r.eventsOf(repo))
+}
+
+// GetErrors returns the errors returned by processing each repo, keyed by util.RepoKey
+func (r *RunStats) GetErrors() map[string]string {
+	return r.errors
+}
+
+// GetFailureReasons returns the kind of error processing each repo that failed returned, keyed by util.RepoKey
+func (r *RunStats) GetFailureReasons() map[string]types.FailureReason {
+	return r.failureReasons
+}
+
+// eventsOf returns the events the supplied repo has been tracked under so far. The caller must hold the mutex
+func (r *RunStats) eventsOf(repo *github.Repository) map[types.Event]bool {
+	events := map[types.Event]bool{}
+	key := util.RepoKey(repo)
+	for event, repos := range r.repos {
+		for _, trackedRepo := range repos {
+			if util.RepoKey(trackedRepo) == key {
+				events[event] = true
+				break
+			}
+		}
+	}
+	return events
+}
+
+// TrackDiff stores the diff of changes committed to the supplied repo, so that it can be embedded in the HTML run
+// report. Diffs of changes split across several commits are appended to each other. This function is safe to call
+// from concurrent goroutines
+func (r *RunStats) TrackDiff(repo *github.Repository, diff string) {
 	defer r.mutex.Unlock()
 	r.mutex.Lock()
-	r.pulls[repoName] = prURL
+	r.diffs[util.RepoKey(repo)] += diff
 }
 
-// TrackDraftPullRequest stores the successful Draft PR opening for the supplied Repo, at the supplied PR URL
+// GetDiffs returns the diffs of the changes committed to each repo, keyed by util.RepoKey
+func (r *RunStats) GetDiffs() map[string]string {
+	return r.diffs
+}
+
+// TrackDiffStats adds the supplied counts of files and lines changed to those of the supplied repo. Changes split across
+// several commits are summed. This function is safe to call from concurrent goroutines
+func (r *RunStats) TrackDiffStats(repo *github.Repository, diffStats types.DiffStats) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	key := util.RepoKey(repo)
+	existing := r.diffStats[key]
+	r.diffStats[key] = types.DiffStats{
+		FilesChanged: existing.FilesChanged + diffStats.FilesChanged,
+		Insertions:   existing.Insertions + diffStats.Insertions,
+		Deletions:    existing.Deletions + diffStats.Deletions,
+	}
+}
+
+// TrackBranch records the supplied branch as pushed to the supplied repo. Tracking a branch again, e.g. once its pull
+// request is opened, fills in the fields it didn't have yet. This function is safe to call from concurrent goroutines
+func (r *RunStats) TrackBranch(repo *github.Repository, branch types.PushedBranch) {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	key := util.RepoKey(repo)
+	branches := r.branches[key]
+	for i, existing := range branches {
+		if existing.Name != branch.Name {
+			continue
+		}
+		if branch.CommitSHA != "" {
+			existing.CommitSHA = branch.CommitSHA
+		}
+		if branch.PullRequestURL != "" {
+			existing.PullRequestURL = branch.PullRequestURL
+			existing.PullRequestNumber = branch.PullRequestNumber
+			existing.Draft = branch.Draft
+		}
+		branches[i] = existing
+		return
+	}
+	r.branches[key] = append(branches, branch)
+}
+
+// CountPullRequests returns the number of pull requests opened for the supplied repo so far. This function is safe to
+// call from concurrent goroutines
+func (r *RunStats) CountPullRequests(repo *github.Repository) int {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	count := 0
+	for _, branch := range r.branches[util.RepoKey(repo)] {
+		if branch.PullRequestURL != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// GetBranches returns the branches pushed to each repo, keyed by util.RepoKey
+func (r *RunStats) GetBranches() map[string][]types.PushedBranch {
+	return r.branches
+}
+
+// GetDiffStats returns the counts of files and lines changed in each repo, keyed by util.RepoKey
+func (r *RunStats) GetDiffStats() map[string]types.DiffStats {
+	return r.diffStats
+}
+
+// TrackDuration adds the time elapsed since the supplied start to the time spent in the supplied phase of processing
+// the supplied repo. It is meant to be deferred at the start of the phase:
+//
+//	defer config.Stats.TrackDuration(repo, types.PhaseClone, time.Now())
+//
 // This function is safe to call from concurrent goroutines
-func (r *RunStats) TrackDraftPullRequest(repoName, prURL string) {
+func (r *RunStats) TrackDuration(repo *github.Repository, phase types.Phase, start time.Time) {
+	elapsed := time.Since(start)
 	defer r.mutex.Unlock()
 	r.mutex.Lock()
-	r.draftpulls[repoName] = prURL
+	key := util.RepoKey(repo)
+	if r.durations[key] == nil {
+		r.durations[key] = map[types.Phase]time.Duration{}
+	}
+	r.durations[key][phase] += elapsed
+}
+
+// GetDurations returns the time spent in each phase of processing each repo, keyed by util.RepoKey
+func (r *RunStats) GetDurations() map[string]map[types.Phase]time.Duration {
+	return r.durations
+}
+
+// CountRepos returns the number of distinct repos that were tracked under any event during this run
+func (r *RunStats) CountRepos() int {
+	defer r.mutex.Unlock()
+	r.mutex.Lock()
+	repoNames := map[string]bool{}
+	for _, repos := range r.repos {
+		for _, repo := range repos {
+			repoNames[util.RepoKey(repo)] = true
+		}
+	}
+	for _, repos := range r.skippedArchivedRepos {
+		for _, repo := range repos {
+			repoNames[util.RepoKey(repo)] = true
+		}
+	}
+	return len(repoNames)
 }
 
 // TrackMultiple accepts a types.Event and a slice of pointers to GitHub repos that will all be associated with that event
@@ -260,14 +701,119 @@ func (r *RunStats) GenerateRunReport() *types.RunReport {
 		Repos:          r.GetRepos(),
 		SkippedRepos:   r.GetSkippedArchivedRepos(),
 		Command:        r.command,
+		RunID:          r.runID,
 		SelectionMode:  r.selectionMode,
 		RuntimeSeconds: r.GetTotalRunSeconds(), FileProvidedRepos: r.GetFileProvidedRepos(),
-		PullRequests:      r.GetPullRequests(),
-		DraftPullRequests: r.GetDraftPullRequests(),
+		PullRequests:       r.GetPullRequests(),
+		DraftPullRequests:  r.GetDraftPullRequests(),
+		Errors:             r.GetErrors(),
+		FailureReasons:     r.GetFailureReasons(),
+		Diffs:              r.GetDiffs(),
+		DiffStats:          r.GetDiffStats(),
+		Durations:          r.GetDurations(),
+		APIRetries:         r.GetAPIRetries(),
+		APICalls:           r.apiCalls,
+		APICallsByCategory: r.apiCallsByCategory,
+		RateLimits:         r.rateLimits,
+		DiffPreviewLines:   r.diffPreviewLines,
+		Branches:           r.GetBranches(),
+		StartedAt:          r.startTime,
 	}
 }
 
 // PrintReport renders to STDOUT a summary of each repo that was considered by this tool and what happened to it during processing
 func (r *RunStats) PrintReport() {
-	printer.PrintRepoReport(allEvents, r.GenerateRunReport())
+	printer.PrintRepoReport(os.Stdout, r.Events(), r.GenerateRunReport())
+}
+
+// WriteCSVReport writes the outcome of each repo that was considered by this tool to the supplied writer as CSV
+func (r *RunStats) WriteCSVReport(w io.Writer) error {
+	return printer.WriteCSVReport(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteMarkdownReport writes the summary of what was done to the supplied writer as Markdown
+func (r *RunStats) WriteMarkdownReport(w io.Writer) error {
+	return printer.WriteMarkdownReport(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteGithubActionsSummary writes the summary of what was done to the supplied writer as Markdown, for the summary of a
+// GitHub Actions step
+func (r *RunStats) WriteGithubActionsSummary(w io.Writer) error {
+	return printer.WriteGithubActionsSummary(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteGithubActionsOutputs writes the URLs of the pull requests opened and the repos that failed to the supplied
+// writer as GitHub Actions step outputs
+func (r *RunStats) WriteGithubActionsOutputs(w io.Writer) error {
+	return printer.WriteGithubActionsOutputs(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteJUnitReport writes the outcome of each repo that was considered by this tool to the supplied writer as JUnit XML
+func (r *RunStats) WriteJUnitReport(w io.Writer) error {
+	return printer.WriteJUnitReport(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteHTMLReport writes the summary of what was done to the supplied writer as a standalone HTML page
+func (r *RunStats) WriteHTMLReport(w io.Writer) error {
+	return printer.WriteHTMLReport(w, r.Events(), r.GenerateRunReport())
+}
+
+// WriteWebhookPayload writes the summary of what was done to the supplied writer as the JSON payload of --webhook-url,
+// including the events tracked for each repo if includeEvents is set
+func (r *RunStats) WriteWebhookPayload(w io.Writer, includeEvents bool) error {
+	return printer.WriteWebhookPayload(w, r.Events(), r.GenerateRunReport(), includeEvents)
+}
+
+// RenderTrackingIssue returns the summary of what was done as the body of a GitHub issue, with checklists of the pull
+// requests opened and the repos that failed. A summary too long for an issue links to the full report at the supplied
+// URL instead
+func (r *RunStats) RenderTrackingIssue(fullReportURL string) (string, error) {
+	return printer.RenderTrackingIssue(r.Events(), r.GenerateRunReport(), fullReportURL)
+}
+
+// RenderSlackMessage returns the summary of what was done as a Slack message
+func (r *RunStats) RenderSlackMessage() string {
+	return printer.RenderSlackMessage(r.Events(), r.GenerateRunReport())
+}
+
+// RenderJiraComment returns the summary of what was done as a Jira comment, in Jira's wiki markup
+func (r *RunStats) RenderJiraComment() string {
+	return printer.RenderJiraComment(r.Events(), r.GenerateRunReport())
+}
+
+// RenderEmail returns the subject and plain text body of an email summarizing what was done
+func (r *RunStats) RenderEmail() (string, string) {
+	return printer.RenderEmail(r.Events(), r.GenerateRunReport())
+}
+
+// WritePrometheusMetrics writes the metrics of this run to the supplied writer in the Prometheus text exposition
+// format, along with the supplied number of calls made to the GitHub API
+func (r *RunStats) WritePrometheusMetrics(w io.Writer, apiCalls uint64) error {
+	return printer.WritePrometheusMetrics(w, r.Events(), r.GenerateRunReport(), time.Since(r.startTime), apiCalls)
+}
+
+// WriteTelemetryPayload writes the anonymized metrics of this run sent to --telemetry-endpoint to the supplied writer,
+// with the supplied description of how git-xargs was used
+func (r *RunStats) WriteTelemetryPayload(w io.Writer, usage printer.TelemetryUsage) error {
+	return printer.WriteTelemetryPayload(w, r.Events(), r.GenerateRunReport(), time.Since(r.startTime), usage)
+}
+
+// WriteRunManifest writes the --output-manifest of this run to the supplied writer, with the supplied snapshot of the
+// config of the run
+func (r *RunStats) WriteRunManifest(w io.Writer, config manifest.Config) error {
+	return manifest.Write(w, printer.NewRunManifest(r.Events(), r.GenerateRunReport(), config))
+}
+
+// WriteReport writes the summary of each repo that was considered by this tool and what happened to it during
+// processing to the supplied writer, in the supplied --output format
+func (r *RunStats) WriteReport(format string, w io.Writer) error {
+	switch format {
+	case "", common.OutputFormatTable:
+		printer.PrintRepoReport(w, r.Events(), r.GenerateRunReport())
+		return nil
+	case common.OutputFormatJSON:
+		return printer.WriteJSONReport(w, r.Events(), r.GenerateRunReport())
+	default:
+		return errors.WithStackTrace(types.InvalidOutputFormatErr{Format: format})
+	}
 }
@@ -0,0 +1,320 @@
+// Package tracing records the spans of a run, one per repo and per phase of processing each repo, and exports them to
+// an OpenTelemetry collector over OTLP/HTTP, so that slow runs can be analyzed in a tracing backend. The spans are
+// encoded as OTLP/JSON here, rather than via the OpenTelemetry SDK, which git-xargs doesn't depend on: a run only ever
+// exports a single batch of spans, once it has finished.
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/git-xargs/util"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+const (
+	// exportTimeout bounds how long the collector can hold up the end of a run
+	exportTimeout = 30 * time.Second
+
+	// The OTLP span status codes
+	statusCodeUnset = 0
+	statusCodeError = 2
+
+	// spanKindInternal is the OTLP kind of every span git-xargs records
+	spanKindInternal = 1
+)
+
+// Tracer records the spans of a run and exports them once it's done. Without --otlp-endpoint the tracer is nil, and its
+// methods do nothing
+type Tracer struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	traceID     string
+
+	mutex     sync.Mutex
+	root      *Span
+	repoSpans map[string]*Span
+	ended     []*Span
+}
+
+// Span is a timed operation of a run. A nil *Span is valid and records nothing
+type Span struct {
+	tracer       *Tracer
+	spanID       string
+	parentSpanID string
+	name         string
+	start        time.Time
+	end          time.Time
+	attributes   map[string]string
+	errorMessage string
+}
+
+// New returns a Tracer that exports its spans to the OTLP/HTTP collector at the supplied base URL, e.g.
+// http://localhost:4318, sending the supplied headers along with the spans
+func New(endpoint string, headers map[string]string, serviceName string) *Tracer {
+	return &Tracer{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		headers:     headers,
+		serviceName: serviceName,
+		traceID:     randomID(16),
+		repoSpans:   map[string]*Span{},
+	}
+}
+
+// ParseHeaders parses headers in the format of OTEL_EXPORTER_OTLP_HEADERS, i.e. comma-separated key=value pairs whose
+// keys and values are percent-encoded, e.g. Authorization=Bearer%20token. A pair that isn't validly encoded is skipped
+func ParseHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		key, keyErr := url.PathUnescape(strings.TrimSpace(parts[0]))
+		headerValue, valueErr := url.PathUnescape(strings.TrimSpace(parts[1]))
+		if keyErr != nil || valueErr != nil {
+			continue
+		}
+		headers[key] = headerValue
+	}
+	return headers
+}
+
+// StartRun starts the root span of the run, which the spans of every repo are children of
+func (t *Tracer) StartRun(name string, attributes map[string]string) *Span {
+	if t == nil {
+		return nil
+	}
+	span := t.newSpan(name, "", attributes)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.root = span
+	return span
+}
+
+// StartRepo starts the span of processing the supplied repo, as a child of the run's span
+func (t *Tracer) StartRepo(repo *github.Repository) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	parentSpanID := ""
+	if t.root != nil {
+		parentSpanID = t.root.spanID
+	}
+	t.mutex.Unlock()
+
+	span := t.newSpan("processRepo", parentSpanID, map[string]string{
+		"repo.owner": repo.GetOwner().GetLogin(),
+		"repo.name":  repo.GetName(),
+	})
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.repoSpans[util.RepoKey(repo)] = span
+	return span
+}
+
+// StartPhase starts the span of the supplied phase of processing the supplied repo, as a child of the repo's span
+func (t *Tracer) StartPhase(repo *github.Repository, phase types.Phase) *Span {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	parentSpanID := ""
+	if repoSpan, ok := t.repoSpans[util.RepoKey(repo)]; ok {
+		parentSpanID = repoSpan.spanID
+	} else if t.root != nil {
+		parentSpanID = t.root.spanID
+	}
+	t.mutex.Unlock()
+
+	return t.newSpan(string(phase), parentSpanID, map[string]string{
+		"repo.name": repo.GetName(),
+	})
+}
+
+func (t *Tracer) newSpan(name string, parentSpanID string, attributes map[string]string) *Span {
+	return &Span{
+		tracer:       t,
+		spanID:       randomID(8),
+		parentSpanID: parentSpanID,
+		name:         name,
+		start:        time.Now(),
+		attributes:   attributes,
+	}
+}
+
+// End ends the span, marking it as failed if the supplied error isn't nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if err != nil {
+		s.errorMessage = err.Error()
+	}
+
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.tracer.ended = append(s.tracer.ended, s)
+}
+
+// Export sends every span that has ended to the collector in a single request
+func (t *Tracer) Export() error {
+	if t == nil {
+		return nil
+	}
+
+	t.mutex.Lock()
+	spans := t.ended
+	t.ended = nil
+	t.mutex.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(t.otlpRequest(spans))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: exportTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+	ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.WithStackTrace(types.TraceExportFailedErr{URL: t.endpoint, StatusCode: resp.StatusCode})
+	}
+	return nil
+}
+
+// The types below are the subset of the OTLP/HTTP JSON encoding of ExportTraceServiceRequest that git-xargs sends
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// otlpRequest converts the supplied spans into an OTLP export request
+func (t *Tracer) otlpRequest(spans []*Span) otlpRequest {
+	otlpSpans := []otlpSpan{}
+	for _, span := range spans {
+		status := otlpStatus{Code: statusCodeUnset}
+		if span.errorMessage != "" {
+			status = otlpStatus{Code: statusCodeError, Message: span.errorMessage}
+		}
+		otlpSpans = append(otlpSpans, otlpSpan{
+			TraceID:           t.traceID,
+			SpanID:            span.spanID,
+			ParentSpanID:      span.parentSpanID,
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.attributes),
+			Status:            status,
+		})
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: otlpAttributes(map[string]string{"service.name": t.serviceName})},
+			ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "git-xargs"}, Spans: otlpSpans}},
+		}},
+	}
+}
+
+// otlpAttributes converts the supplied attributes into OTLP attributes, sorted by key
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := []string{}
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	otlpAttributes := []otlpAttribute{}
+	for _, key := range keys {
+		otlpAttributes = append(otlpAttributes, otlpAttribute{Key: key, Value: otlpAnyValue{StringValue: attributes[key]}})
+	}
+	return otlpAttributes
+}
+
+// randomID returns a random ID of the supplied number of bytes, hex encoded, as OTLP/JSON expects trace and span IDs
+func randomID(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracerExportsSpans(t *testing.T) {
+	t.Parallel()
+
+	var request otlpRequest
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	tracer := New(server.URL+"/", map[string]string{"Authorization": "Bearer token"}, "git-xargs")
+	repo := &github.Repository{Name: github.String("fetch"), Owner: &github.User{Login: github.String("gruntwork-io")}}
+
+	runSpan := tracer.StartRun("ProcessRepos", map[string]string{"run.id": "run-1"})
+	repoSpan := tracer.StartRepo(repo)
+	tracer.StartPhase(repo, types.PhaseClone).End(nil)
+	repoSpan.End(fmt.Errorf("exit status 1"))
+	runSpan.End(nil)
+	require.NoError(t, tracer.Export())
+
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "Bearer token", authorization)
+	require.Len(t, request.ResourceSpans, 1)
+	assert.Equal(t, "service.name", request.ResourceSpans[0].Resource.Attributes[0].Key)
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 3)
+	clone, processRepo, run := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "clone", clone.Name)
+	assert.Equal(t, processRepo.SpanID, clone.ParentSpanID)
+	assert.Equal(t, run.SpanID, processRepo.ParentSpanID)
+	assert.Empty(t, run.ParentSpanID)
+	assert.Len(t, run.TraceID, 32)
+	assert.Len(t, run.SpanID, 16)
+	assert.Equal(t, run.TraceID, clone.TraceID)
+
+	assert.Equal(t, statusCodeError, processRepo.Status.Code)
+	assert.Equal(t, "exit status 1", processRepo.Status.Message)
+	assert.Equal(t, statusCodeUnset, run.Status.Code)
+}
+
+func TestParseHeaders(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string]string{"api-key": "secret", "x-team": "platform"}, ParseHeaders("api-key=secret, x-team=platform,invalid"))
+	assert.Equal(t, map[string]string{"Authorization": "Bearer abc=", "x-tag": "a,b"}, ParseHeaders("Authorization=Bearer%20abc%3D,x-tag=a%2Cb,bad=%zz"))
+	assert.Equal(t, map[string]string{}, ParseHeaders(""))
+}
+
+func TestNilTracerIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	var tracer *Tracer
+	repo := &github.Repository{}
+	tracer.StartRun("ProcessRepos", nil).End(nil)
+	tracer.StartRepo(repo).End(nil)
+	tracer.StartPhase(repo, types.PhaseClone).End(nil)
+	assert.NoError(t, tracer.Export())
+}
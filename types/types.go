@@ -2,6 +2,9 @@ package types
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v32/github"
 )
@@ -9,6 +12,42 @@ import (
 // Event is a generic tracking occurrence that RunStats manages
 type Event string
 
+// Phase is a step of processing a repo whose duration RunStats keeps track of
+type Phase string
+
+const (
+	// PhaseClone is the cloning of a repo to the local filesystem
+	PhaseClone Phase = "clone"
+	// PhaseCommand is the execution of the supplied command against a repo
+	PhaseCommand Phase = "command"
+	// PhasePush is the pushing of a repo's branch to GitHub
+	PhasePush Phase = "push"
+	// PhasePullRequest is the opening of a repo's pull request, including the reviewers, labels and projects added to it
+	PhasePullRequest Phase = "pull-request"
+)
+
+// FailureReason is the kind of error a repo failed with, by which the run report groups the repos that failed
+type FailureReason string
+
+const (
+	// FailureRateLimited is a repo that failed because a GitHub API rate limit was hit
+	FailureRateLimited FailureReason = "rate-limited"
+	// FailureTimedOut is a repo that failed because processing it, or a request made for it, took too long
+	FailureTimedOut FailureReason = "timed-out"
+	// FailureCloneError is a repo that couldn't be cloned
+	FailureCloneError FailureReason = "clone-error"
+	// FailureCommandExit is a repo against which the supplied command exited with a non-zero status
+	FailureCommandExit FailureReason = "command-exit"
+	// FailurePushRejected is a repo that the branch with the changes couldn't be pushed to
+	FailurePushRejected FailureReason = "push-rejected"
+	// FailurePullRequestInvalid is a repo whose pull request GitHub rejected as invalid, with a 422 response
+	FailurePullRequestInvalid FailureReason = "pull-request-invalid"
+	// FailurePullRequestError is a repo whose pull request couldn't be opened for any other reason
+	FailurePullRequestError FailureReason = "pull-request-error"
+	// FailureOther is a repo that failed with an error that fits none of the other reasons
+	FailureOther FailureReason = "other"
+)
+
 // ReducedRepo is a simplified form of the github.Repository struct
 type ReducedRepo struct {
 	Name string `header:"Repo name"`
@@ -19,23 +58,163 @@ type RunReport struct {
 	Repos             map[Event][]*github.Repository
 	SkippedRepos      map[Event][]*github.Repository
 	Command           []string
+	RunID             string
 	SelectionMode     string
 	RuntimeSeconds    int
 	FileProvidedRepos []*AllowedRepo
 	PullRequests      map[string]string
 	DraftPullRequests map[string]string
+	Errors            map[string]string
+	// FailureReasons is the kind of error each repo in Errors failed with, keyed by repo name
+	FailureReasons map[string]FailureReason
+	Diffs          map[string]string
+	DiffStats      map[string]DiffStats
+	Durations      map[string]map[Phase]time.Duration
+	APIRetries     uint64
+	// DiffPreviewLines is the number of lines of each diff to preview in the reports, or 0 to leave the previews out
+	DiffPreviewLines int
+	// Branches are the branches pushed to each repo, keyed by repo name
+	Branches  map[string][]PushedBranch
+	StartedAt time.Time
+	// APICalls is the number of requests sent to the GitHub API, in total and by endpoint category
+	APICalls           uint64
+	APICallsByCategory map[string]uint64
+	// RateLimits is what the GitHub API last reported about each of its rate limits, keyed by resource, e.g. core
+	RateLimits map[string]RateLimitStatus
+}
+
+// RateLimitStatus is what the GitHub API last reported about one of its rate limits
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// FailureReasonCount is the repos that failed with one kind of error, for the table of the run report
+type FailureReasonCount struct {
+	Reason string `header:"Failure reason"`
+	Count  int    `header:"Repos"`
+	Repos  string `header:"Repo names"`
+}
+
+// ObjectStorageLocation is a bucket in object storage, and the prefix within it, that run reports are uploaded under
+type ObjectStorageLocation struct {
+	// Scheme is s3 for Amazon S3 or gs for Google Cloud Storage
+	Scheme string
+	Bucket string
+	Prefix string
+}
+
+// Key returns the key of the object with the supplied name under the location
+func (location ObjectStorageLocation) Key(name string) string {
+	if location.Prefix == "" {
+		return name
+	}
+	return location.Prefix + "/" + name
+}
+
+// URL returns the URL of the object with the supplied name under the location, e.g. s3://bucket/prefix/name
+func (location ObjectStorageLocation) URL(name string) string {
+	return fmt.Sprintf("%s://%s/%s", location.Scheme, location.Bucket, location.Key(name))
+}
+
+// APICallCategory is the number of requests sent to one category of GitHub API endpoints, for the table of the run
+// report
+type APICallCategory struct {
+	Category string `header:"Endpoint category"`
+	Calls    uint64 `header:"API calls"`
+}
+
+// PushedBranch is a branch pushed to a repo during a run, along with the commit it was pushed at and the pull request
+// opened from it, if any
+type PushedBranch struct {
+	Name              string `json:"name"`
+	CommitSHA         string `json:"commit_sha,omitempty"`
+	PullRequestURL    string `json:"pull_request_url,omitempty"`
+	PullRequestNumber int    `json:"pull_request_number,omitempty"`
+	Draft             bool   `json:"draft,omitempty"`
+}
+
+// DiffStats counts the files and lines changed by the commits made to a repo
+type DiffStats struct {
+	FilesChanged int `json:"files_changed"`
+	Insertions   int `json:"insertions"`
+	Deletions    int `json:"deletions"`
+}
+
+// TemplateData holds the values available to the placeholders in templated flags such as --pull-request-title, e.g.
+// "Update CI config ({{.FullName}})"
+type TemplateData struct {
+	RepoOwner string
+	RepoName  string
+	FullName  string
+	Date      string
+	RunID     string
 }
 
 // AnnotatedEvent is used in printing the final report. It contains the info to print a section's table - both its Event for looking up the tagged repos, and the human-legible description for printing above the table
 type AnnotatedEvent struct {
 	Event       Event
 	Description string
+	// Skip is true for events that mean the repo was skipped, rather than processed
+	Skip bool
+}
+
+// RepoOutcome is what happened to a single repo during a run, as listed in the structured run reports
+type RepoOutcome struct {
+	// Key is what the stats of the run track the repo under, as returned by util.RepoKey
+	Key     string `json:"-"`
+	Name    string `json:"name"`
+	URL     string `json:"url,omitempty"`
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+	// FailureReason is the kind of error the repo failed with, if it failed
+	FailureReason        FailureReason `json:"failure_reason,omitempty"`
+	SkipReason           string        `json:"skip_reason,omitempty"`
+	Events               []Event       `json:"events,omitempty"`
+	PullRequestURLs      []string      `json:"pull_request_urls,omitempty"`
+	DraftPullRequestURLs []string      `json:"draft_pull_request_urls,omitempty"`
+	Diff                 string        `json:"diff,omitempty"`
+	// DiffStats counts the files and lines changed in the repo, if any changes were committed to it
+	DiffStats *DiffStats `json:"diff_stats,omitempty"`
+	// DurationsSeconds is how long each phase of processing the repo took, in seconds
+	DurationsSeconds map[Phase]float64 `json:"durations_seconds,omitempty"`
 }
 
 // AllowedRepo represents a single repository under a GitHub organization that this tool may operate on
 type AllowedRepo struct {
 	Organization string `header:"Organization name"`
 	Name         string `header:"URL"`
+	// Host is the GitHub Enterprise Server the repo is on, or empty for github.com
+	Host string
+	// Branch is the branch supplied with an @ suffix, e.g. org/repo@release-1.x, which the repo is cloned at and its pull
+	// request opened against, or empty to use the base branch of the run
+	Branch string
+}
+
+// PullRequestStatus is the current state of a pull request opened by an earlier run, as shown by the status subcommand
+type PullRequestStatus struct {
+	Repo   string `header:"Repo name"`
+	Number int    `header:"PR"`
+	State  string `header:"State"`
+	Checks string `header:"Checks"`
+	Review string `header:"Review"`
+	URL    string `header:"PR URL"`
+}
+
+// FileChange is a change to a single file of a repo, made via the GitHub Contents API without cloning the repo. The file
+// at Path is given Content, or deleted if Delete is set
+type FileChange struct {
+	Path    string
+	Content []byte
+	Delete  bool
+}
+
+// FileMove moves or renames the tracked files of a repo that match Pattern, or that are in a directory matching it, to
+// Destination. A Destination ending in / is a directory the matches are moved into under their own names
+type FileMove struct {
+	Pattern     string
+	Destination string
 }
 
 // PullRequest is a simple two column representation of the repo name and its PR url
@@ -44,6 +223,33 @@ type PullRequest struct {
 	URL  string `header:"PR URL"`
 }
 
+// SlowRepo is a row of the table of the slowest repos in the final run report
+type SlowRepo struct {
+	Name        string `header:"Repo name"`
+	Total       string `header:"Total"`
+	Clone       string `header:"Clone"`
+	Command     string `header:"Command"`
+	Push        string `header:"Push"`
+	PullRequest string `header:"Pull request"`
+}
+
+// RepoChanges is a row of the table of the changes made to each repo in the final run report
+type RepoChanges struct {
+	Name         string `header:"Repo name"`
+	FilesChanged int    `header:"Files changed"`
+	Insertions   int    `header:"Insertions"`
+	Deletions    int    `header:"Deletions"`
+}
+
+// RepoOutcomeChange is a row of the table of the repos whose outcome changed between two runs, as printed by report diff
+type RepoOutcomeChange struct {
+	Repo   string `header:"Repo name"`
+	Change string `header:"Change"`
+	Before string `header:"Before"`
+	After  string `header:"After"`
+	Error  string `header:"Error"`
+}
+
 type NoArgumentsPassedErr struct{}
 
 func (NoArgumentsPassedErr) Error() string {
@@ -94,8 +300,1011 @@ func (NoCommandSuppliedErr) Error() string {
 	return fmt.Sprintf("You must supply a valid command or script to execute")
 }
 
+type StateStoreOpenErr struct {
+	Path string
+	Err  error
+}
+
+func (err StateStoreOpenErr) Error() string {
+	return fmt.Sprintf("Could not open the git-xargs state store at %s. Pass --state-file to use a different one, or --skip-state to not record this run: %s", err.Path, err.Err)
+}
+
+type StateStoreLockedErr struct {
+	Path string
+}
+
+func (err StateStoreLockedErr) Error() string {
+	return fmt.Sprintf("The git-xargs state store at %s is in use by another git-xargs process. Wait for it to finish, or pass --state-file to use a different one", err.Path)
+}
+
+type InvalidBranchPatternErr struct {
+	Pattern string
+}
+
+func (err InvalidBranchPatternErr) Error() string {
+	return fmt.Sprintf("Invalid --branch-pattern %q. It must be a valid regular expression", err.Pattern)
+}
+
+type NoRunIDProvidedErr struct {
+	Command string
+}
+
+func (err NoRunIDProvidedErr) Error() string {
+	return fmt.Sprintf("You must pass the ID of the run to act on via --run-id to the %s subcommand", err.Command)
+}
+
+type RunNotFoundErr struct {
+	RunID string
+}
+
+func (err RunNotFoundErr) Error() string {
+	return fmt.Sprintf("No run with the ID %s was found in the git-xargs state store", err.RunID)
+}
+
+type NoGithubApproverOauthTokenProvidedErr struct{}
+
+func (NoGithubApproverOauthTokenProvidedErr) Error() string {
+	return "You must export a valid Github personal access token for the approving identity as GITHUB_APPROVER_OAUTH_TOKEN when passing --approve-and-merge"
+}
+
+type SameGithubApproverOauthTokenErr struct{}
+
+func (SameGithubApproverOauthTokenErr) Error() string {
+	return "GITHUB_APPROVER_OAUTH_TOKEN must belong to a different identity than GITHUB_OAUTH_TOKEN, because GitHub does not let the author of a pull request approve it"
+}
+
+type InvalidMergeMethodErr struct {
+	MergeMethod string
+}
+
+func (err InvalidMergeMethodErr) Error() string {
+	return fmt.Sprintf("Invalid --merge-method %q. Valid methods are merge, squash and rebase", err.MergeMethod)
+}
+
+type InvalidSplitByErr struct {
+	SplitBy string
+}
+
+func (err InvalidSplitByErr) Error() string {
+	return fmt.Sprintf("Invalid --split-by %q. Valid values are directory and file", err.SplitBy)
+}
+
+type NoPlanFileProvidedErr struct{}
+
+func (NoPlanFileProvidedErr) Error() string {
+	return "You must pass the path of the plan file to apply, for example: git-xargs apply git-xargs-plan.json"
+}
+
+type InvalidPlanFileErr struct {
+	Path string
+	Err  error
+}
+
+func (err InvalidPlanFileErr) Error() string {
+	return fmt.Sprintf("The plan file %s is not valid: %s", err.Path, err.Err)
+}
+
+type UnsupportedPlanVersionErr struct {
+	Path    string
+	Version int
+}
+
+func (err UnsupportedPlanVersionErr) Error() string {
+	return fmt.Sprintf("The plan file %s has version %d, which this version of git-xargs cannot apply. Create a new plan with git-xargs plan", err.Path, err.Version)
+}
+
+type InvalidPlanPathErr struct {
+	Path string
+}
+
+func (err InvalidPlanPathErr) Error() string {
+	return fmt.Sprintf("The plan changes %s, which is not a path inside the repo. Create a new plan with git-xargs plan", err.Path)
+}
+
+type StalePlanErr struct {
+	Repo  string
+	Files []string
+}
+
+func (err StalePlanErr) Error() string {
+	return fmt.Sprintf("The plan for %s is stale, because these files have changed since it was created: %s", err.Repo, strings.Join(err.Files, ", "))
+}
+
+type NoScheduleProvidedErr struct{}
+
+func (NoScheduleProvidedErr) Error() string {
+	return "You must pass the cron schedule to run on via --schedule to the watch subcommand"
+}
+
+type InvalidScheduleErr struct {
+	Schedule string
+	Err      error
+}
+
+func (err InvalidScheduleErr) Error() string {
+	return fmt.Sprintf("The schedule %q is not a valid cron schedule: %s", err.Schedule, err.Err)
+}
+
+type InvalidMaxOpenPRsErr struct {
+	MaxOpenPRs int
+}
+
+func (err InvalidMaxOpenPRsErr) Error() string {
+	return fmt.Sprintf("Invalid --max-open-prs %d. Pass a positive number of pull requests, or 0 for no limit", err.MaxOpenPRs)
+}
+
+type InvalidMaxOpenPRsScopeErr struct {
+	Scope string
+}
+
+func (err InvalidMaxOpenPRsScopeErr) Error() string {
+	return fmt.Sprintf("Invalid --max-open-prs-scope %q. Valid values are run and org", err.Scope)
+}
+
+type WatchWithRunIDErr struct{}
+
+func (WatchWithRunIDErr) Error() string {
+	return "You cannot pass --run-id or --resume to the watch subcommand, since every scheduled run gets a run ID of its own"
+}
+
+type NoServeTokenProvidedErr struct{}
+
+func (NoServeTokenProvidedErr) Error() string {
+	return "You must export a GIT_XARGS_SERVE_TOKEN, which clients of the API must send as a bearer token"
+}
+
+type InvalidOutputFormatErr struct {
+	Format string
+}
+
+func (err InvalidOutputFormatErr) Error() string {
+	return fmt.Sprintf("The output format %s is not supported. It must be one of: table, json", err.Format)
+}
+
+type ResumeWithoutRunIDErr struct{}
+
+func (ResumeWithoutRunIDErr) Error() string {
+	return "You must pass the ID of the interrupted run via --run-id together with --resume"
+}
+
+type ResumeWithSkipStateErr struct{}
+
+func (ResumeWithSkipStateErr) Error() string {
+	return "You cannot pass --resume together with --skip-state, since runs are resumed from the state store"
+}
+
+type ApproveAndMergeWithDraftErr struct{}
+
+func (ApproveAndMergeWithDraftErr) Error() string {
+	return "You cannot pass --approve-and-merge together with --draft, since draft pull requests cannot be merged"
+}
+
 type NoGithubOauthTokenProvidedErr struct{}
 
 func (NoGithubOauthTokenProvidedErr) Error() string {
 	return fmt.Sprintf("You must export a valid Github personal access token as GITHUB_OAUTH_TOKEN")
 }
+
+type GithubTokenEnvNotSetErr struct {
+	EnvVar string
+}
+
+func (err GithubTokenEnvNotSetErr) Error() string {
+	return fmt.Sprintf("--github-token-env says to read the Github token from %s, but no token is exported as %s", err.EnvVar, err.EnvVar)
+}
+
+// ProjectV2 is a Projects (v2) board resolved from the --project flag, along with the --project-field values that
+// should be set on every pull request added to it
+type ProjectV2 struct {
+	ID          string
+	FieldValues []ProjectV2FieldValue
+}
+
+// ProjectV2FieldValue is a single field value to set on a Projects (v2) item, already converted to the shape the
+// GraphQL API expects for the field's data type
+type ProjectV2FieldValue struct {
+	FieldID string
+	Value   map[string]interface{}
+}
+
+type GraphQLRequestFailedErr struct {
+	StatusCode int
+}
+
+func (err GraphQLRequestFailedErr) Error() string {
+	return fmt.Sprintf("Github GraphQL API request failed with HTTP status code %d", err.StatusCode)
+}
+
+type GraphQLResponseErr struct {
+	Messages []string
+}
+
+func (err GraphQLResponseErr) Error() string {
+	return fmt.Sprintf("Github GraphQL API returned errors: %s", strings.Join(err.Messages, "; "))
+}
+
+type InvalidDraftRepoPatternErr struct {
+	Pattern string
+}
+
+func (err InvalidDraftRepoPatternErr) Error() string {
+	return fmt.Sprintf("The pattern supplied via --draft-if-repo-matches is not a valid regular expression: %s", err.Pattern)
+}
+
+type InvalidProjectFlagErr struct {
+	Project string
+}
+
+func (err InvalidProjectFlagErr) Error() string {
+	return fmt.Sprintf("The --project flag must be in the format of <github-organization>/<project-number>, e.g. gruntwork-io/12, but got: %s", err.Project)
+}
+
+type InvalidProjectFieldFlagErr struct {
+	Field string
+}
+
+func (err InvalidProjectFieldFlagErr) Error() string {
+	return fmt.Sprintf("The --project-field flag must be in the format of <field-name>=<value>, but got: %s", err.Field)
+}
+
+type ProjectNotFoundErr struct {
+	Project string
+}
+
+func (err ProjectNotFoundErr) Error() string {
+	return fmt.Sprintf("Could not find the project supplied via --project: %s", err.Project)
+}
+
+type ProjectFieldNotFoundErr struct {
+	Project string
+	Field   string
+}
+
+func (err ProjectFieldNotFoundErr) Error() string {
+	return fmt.Sprintf("Could not find a field named %s on the project %s", err.Field, err.Project)
+}
+
+type InvalidProjectFieldValueErr struct {
+	Field string
+	Value string
+}
+
+func (err InvalidProjectFieldValueErr) Error() string {
+	return fmt.Sprintf("The value %s is not valid for the project field %s", err.Value, err.Field)
+}
+
+type ProjectFieldWithoutProjectErr struct{}
+
+func (ProjectFieldWithoutProjectErr) Error() string {
+	return "The --project-field flag can only be used in conjunction with the --project flag"
+}
+
+type InvalidTemplateErr struct {
+	Flag string
+	Err  error
+}
+
+func (err InvalidTemplateErr) Error() string {
+	return fmt.Sprintf("The value supplied via --%s is not a valid template: %s", err.Flag, err.Err)
+}
+
+type InvalidReviewerStrategyErr struct {
+	Strategy string
+}
+
+func (err InvalidReviewerStrategyErr) Error() string {
+	return fmt.Sprintf("The --reviewer-strategy flag must be one of all, round-robin or least-loaded, but got: %s", err.Strategy)
+}
+
+type InvalidAssigneeStrategyErr struct {
+	Strategy string
+}
+
+func (err InvalidAssigneeStrategyErr) Error() string {
+	return fmt.Sprintf("The --assignee-strategy flag must be one of all, round-robin or least-loaded, but got: %s", err.Strategy)
+}
+
+type WebhookRequestFailedErr struct {
+	URL        string
+	StatusCode int
+}
+
+func (err WebhookRequestFailedErr) Error() string {
+	return fmt.Sprintf("The webhook at %s responded with HTTP status code %d", err.URL, err.StatusCode)
+}
+
+type NoSlackBotTokenProvidedErr struct{}
+
+func (NoSlackBotTokenProvidedErr) Error() string {
+	return "You must export a valid Slack bot token as SLACK_BOT_TOKEN to post to --slack-channel"
+}
+
+type SlackAPIErr struct {
+	Message string
+}
+
+func (err SlackAPIErr) Error() string {
+	return fmt.Sprintf("The Slack API returned an error: %s", err.Message)
+}
+
+type TooManyFailedReposErr struct {
+	Failed int
+	Total  int
+}
+
+func (err TooManyFailedReposErr) Error() string {
+	return fmt.Sprintf("%d of %d repos failed, which is more than --allowed-failures, --allowed-failure-rate and --min-success-rate allow", err.Failed, err.Total)
+}
+
+type InvalidAllowedFailureRateErr struct {
+	Rate float64
+}
+
+func (err InvalidAllowedFailureRateErr) Error() string {
+	return fmt.Sprintf("The --allowed-failure-rate flag must be a fraction between 0 and 1, but got: %v", err.Rate)
+}
+
+type InvalidMinSuccessRateErr struct {
+	Rate string
+}
+
+func (err InvalidMinSuccessRateErr) Error() string {
+	return fmt.Sprintf("The --min-success-rate flag must be a percentage between 0%% and 100%%, e.g. 95%%, or a fraction between 0 and 1, but got: %s", err.Rate)
+}
+
+type InvalidMaxFailureRateErr struct {
+	Rate float64
+}
+
+func (err InvalidMaxFailureRateErr) Error() string {
+	return fmt.Sprintf("The --max-failure-rate flag must be a fraction between 0 and 1, but got: %v", err.Rate)
+}
+
+type RepoTimedOutErr struct {
+	Timeout time.Duration
+}
+
+func (err RepoTimedOutErr) Error() string {
+	return fmt.Sprintf("Processing the repo took longer than the --repo-timeout of %s, so it was cancelled", err.Timeout)
+}
+
+type TraceExportFailedErr struct {
+	URL        string
+	StatusCode int
+}
+
+func (err TraceExportFailedErr) Error() string {
+	return fmt.Sprintf("The OTLP collector at %s responded with HTTP status code %d", err.URL, err.StatusCode)
+}
+
+type InvalidTrackingIssueRepoErr struct {
+	Repo string
+}
+
+func (err InvalidTrackingIssueRepoErr) Error() string {
+	return fmt.Sprintf("--create-tracking-issue must be a repo in the format of <github-organization>/<repo-name>, got %s", err.Repo)
+}
+
+type InvalidReportDiffArgsErr struct{}
+
+func (InvalidReportDiffArgsErr) Error() string {
+	return "You must pass the IDs of the two runs to compare to report diff, e.g. git-xargs report diff <run-a> <run-b>"
+}
+
+// ProcessReposErr is returned when processing some of the repos of a run failed. It holds the error each failed repo
+// returned, keyed by the repo's full name, so that callers can tell a partial failure from a success
+type ProcessReposErr struct {
+	RepoErrors map[string]error
+	Total      int
+}
+
+func (err ProcessReposErr) Error() string {
+	repoNames := []string{}
+	for repoName := range err.RepoErrors {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	repoErrors := []string{}
+	for _, repoName := range repoNames {
+		repoErrors = append(repoErrors, fmt.Sprintf("%s: %s", repoName, err.RepoErrors[repoName]))
+	}
+	return fmt.Sprintf("%d of %d repos failed: %s", len(err.RepoErrors), err.Total, strings.Join(repoErrors, "; "))
+}
+
+type RunInterruptedErr struct{}
+
+func (RunInterruptedErr) Error() string {
+	return "The run was interrupted before every repo was processed"
+}
+
+type InvalidConfigFileErr struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (err InvalidConfigFileErr) Error() string {
+	if err.Line == 0 {
+		return fmt.Sprintf("The config file %s is invalid: %v", err.File, err.Err)
+	}
+	return fmt.Sprintf("The config file %s is invalid on line %d: %v", err.File, err.Line, err.Err)
+}
+
+type UnknownConfigFileKeyErr struct {
+	File       string
+	Line       int
+	Key        string
+	Suggestion string
+}
+
+func (err UnknownConfigFileKeyErr) Error() string {
+	message := fmt.Sprintf("The config file %s sets %s on line %d, which is not a git-xargs flag", err.File, err.Key, err.Line)
+	if err.Suggestion != "" {
+		message += fmt.Sprintf(". Did you mean %s?", err.Suggestion)
+	}
+	return message
+}
+
+type InvalidConfigFileValueErr struct {
+	File   string
+	Line   int
+	Key    string
+	Reason string
+}
+
+func (err InvalidConfigFileValueErr) Error() string {
+	return fmt.Sprintf("The config file %s sets %s on line %d to an invalid value: %s %s", err.File, err.Key, err.Line, err.Key, err.Reason)
+}
+
+type DiscoveredConfigFileCommandErr struct {
+	File string
+	Line int
+}
+
+func (err DiscoveredConfigFileCommandErr) Error() string {
+	return fmt.Sprintf("The config file %s was picked up from the working directory, and sets command on line %d. Only a config file passed via --config can set the command to run. Pass --config %s, or pass the command on the command line and remove it from the file", err.File, err.Line, err.File)
+}
+
+type UnknownProfileErr struct {
+	File    string
+	Profile string
+}
+
+func (err UnknownProfileErr) Error() string {
+	if err.File == "" {
+		return fmt.Sprintf("--profile %s was passed, but there is no config file to read the profile from. Pass one via --config", err.Profile)
+	}
+	return fmt.Sprintf("The config file %s has no profile named %s", err.File, err.Profile)
+}
+
+type UnsupportedShellErr struct {
+	Shell string
+}
+
+func (err UnsupportedShellErr) Error() string {
+	return fmt.Sprintf("Can't print a completion script for the shell %q. Pass one of bash, zsh or fish, e.g. git-xargs completion bash", err.Shell)
+}
+
+type DoctorFoundProblemsErr struct {
+	Problems int
+}
+
+func (err DoctorFoundProblemsErr) Error() string {
+	return fmt.Sprintf("git-xargs doctor found %d problems. Fix them before running git-xargs", err.Problems)
+}
+
+type InvalidPluginErr struct {
+	Plugin string
+	Reason string
+}
+
+func (err InvalidPluginErr) Error() string {
+	return fmt.Sprintf("Can't load the plugin %s: %s", err.Plugin, err.Reason)
+}
+
+type PluginErr struct {
+	Plugin string
+	Hook   string
+	Err    error
+}
+
+func (err PluginErr) Error() string {
+	return fmt.Sprintf("The %s hook of the plugin %s failed: %v", err.Hook, err.Plugin, err.Err)
+}
+
+type PickNeedsTerminalErr struct{}
+
+func (PickNeedsTerminalErr) Error() string {
+	return "--pick shows the repos in an interactive picker, which needs a terminal. Drop --pick to run without one, e.g. in CI"
+}
+
+type PickCancelledErr struct{}
+
+func (PickCancelledErr) Error() string {
+	return "The repo picker was cancelled, so no repos were processed"
+}
+
+type NoReposPickedErr struct{}
+
+func (NoReposPickedErr) Error() string {
+	return "No repos were selected in the repo picker, so no repos were processed"
+}
+
+type PickWithScheduleErr struct{}
+
+func (PickWithScheduleErr) Error() string {
+	return "--pick needs someone at the terminal to pick the repos, so it can't be used with the watch subcommand"
+}
+
+type NoReleaseAssetErr struct {
+	Release string
+	Asset   string
+}
+
+func (err NoReleaseAssetErr) Error() string {
+	return fmt.Sprintf("The git-xargs release %s has no %s asset, so git-xargs can't update itself on this machine. Download a binary from the releases page instead", err.Release, err.Asset)
+}
+
+type ReleaseAssetDownloadErr struct {
+	Asset      string
+	StatusCode int
+}
+
+func (err ReleaseAssetDownloadErr) Error() string {
+	return fmt.Sprintf("Downloading the release asset %s failed with status code %d", err.Asset, err.StatusCode)
+}
+
+type NoChecksumErr struct {
+	Asset string
+}
+
+func (err NoChecksumErr) Error() string {
+	return fmt.Sprintf("The SHA256SUMS file of the release lists no checksum for %s, so it can't be verified and was not installed", err.Asset)
+}
+
+type ChecksumMismatchErr struct {
+	Asset    string
+	Expected string
+	Actual   string
+}
+
+func (err ChecksumMismatchErr) Error() string {
+	return fmt.Sprintf("The checksum of the downloaded %s is %s, but the release lists %s, so it was not installed. The download was likely corrupted, so try again", err.Asset, err.Actual, err.Expected)
+}
+
+type HomebrewInstallErr struct {
+	Path string
+}
+
+func (err HomebrewInstallErr) Error() string {
+	return fmt.Sprintf("git-xargs at %s was installed by Homebrew, which would not know about the update. Run brew upgrade git-xargs instead", err.Path)
+}
+
+type QuietWithLogLevelErr struct{}
+
+func (QuietWithLogLevelErr) Error() string {
+	return "--quiet sets the log level to error, so it can't be combined with --log-level. Pass one or the other"
+}
+
+type InvalidLogLevelErr struct {
+	Level string
+}
+
+func (err InvalidLogLevelErr) Error() string {
+	return fmt.Sprintf("%s is not a valid --log-level. Valid levels are trace, debug, info, warning, error, fatal and panic", err.Level)
+}
+
+type InvalidRepoConfigErr struct {
+	Err error
+}
+
+func (err InvalidRepoConfigErr) Error() string {
+	return fmt.Sprintf("The .git-xargs.yml file of the repo is invalid: %v. Supported keys are branch-name, base-branch-name, reviewers and opt-out-tags", err.Err)
+}
+
+type InvalidDryRunLevelErr struct {
+	Level string
+}
+
+func (err InvalidDryRunLevelErr) Error() string {
+	return fmt.Sprintf("%s is not a valid --dry-run-level. Valid levels are list-only, clone-and-run, commit-no-push and push-no-pr", err.Level)
+}
+
+type InvalidSkipCIInErr struct {
+	SkipCIIn string
+}
+
+func (err InvalidSkipCIInErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --skip-ci-in. Valid values are commit, title and both", err.SkipCIIn)
+}
+
+type SkipCITrailerInTitleErr struct {
+	Trailer  string
+	SkipCIIn string
+}
+
+func (err SkipCITrailerInTitleErr) Error() string {
+	return fmt.Sprintf("--skip-ci-trailer %q is a git trailer, which only means something at the end of a commit message, but --skip-ci-in %s adds it to pull request titles. Pass --skip-ci-in commit, or a marker such as \"[skip ci]\"", err.Trailer, err.SkipCIIn)
+}
+
+type MutuallyExclusiveFlagsErr struct {
+	First  string
+	Second string
+	Reason string
+}
+
+func (err MutuallyExclusiveFlagsErr) Error() string {
+	return fmt.Sprintf("You cannot pass --%s together with --%s, since %s. They may be set in a config file, a profile or an environment variable", err.First, err.Second, err.Reason)
+}
+
+type InvalidRecordingErr struct {
+	File string
+	Err  error
+}
+
+func (err InvalidRecordingErr) Error() string {
+	return fmt.Sprintf("The recording %s passed via --replay is invalid: %v", err.File, err.Err)
+}
+
+type NoRecordedResponseErr struct {
+	Method string
+	URL    string
+}
+
+func (err NoRecordedResponseErr) Error() string {
+	return fmt.Sprintf("The recording passed via --replay has no response to %s %s. Record the run again with --record", err.Method, err.URL)
+}
+
+type InvalidPutFileFlagErr struct {
+	PutFile string
+}
+
+func (err InvalidPutFileFlagErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --put-file. Pass it in the format of <path-in-repo>=<local-path>", err.PutFile)
+}
+
+type InvalidDeleteFileFlagErr struct {
+	DeleteFile string
+}
+
+func (err InvalidDeleteFileFlagErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --delete-file. Pass the path of a file in the repo", err.DeleteFile)
+}
+
+type FileChangesWithCommandErr struct{}
+
+func (FileChangesWithCommandErr) Error() string {
+	return "You cannot pass a command together with --put-file or --delete-file, since the files are changed via the GitHub Contents API without cloning the repos the command would run in"
+}
+
+type InvalidMoveFileFlagErr struct {
+	MoveFile string
+}
+
+func (err InvalidMoveFileFlagErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --move-file. Pass it in the format of <glob>=<destination>, with paths relative to the root of the repo", err.MoveFile)
+}
+
+type MoveFilesWithCommandErr struct{}
+
+func (MoveFilesWithCommandErr) Error() string {
+	return "You cannot pass a command, --put-file or --delete-file together with --move-file, which takes the place of the command"
+}
+
+type AmbiguousFileMoveErr struct {
+	Pattern     string
+	Destination string
+	Matches     []string
+}
+
+func (err AmbiguousFileMoveErr) Error() string {
+	return fmt.Sprintf("--move-file %s=%s matches %d paths (%s), which can't all be moved to the same path. End the destination with / to move them into a directory", err.Pattern, err.Destination, len(err.Matches), strings.Join(err.Matches, ", "))
+}
+
+type BaseBranchNotFoundErr struct {
+	Repo   string
+	Branch string
+}
+
+func (err BaseBranchNotFoundErr) Error() string {
+	return fmt.Sprintf("The base branch %s of %s does not exist", err.Branch, err.Repo)
+}
+
+type ContentsPathIsDirectoryErr struct {
+	Repo string
+	Path string
+}
+
+func (err ContentsPathIsDirectoryErr) Error() string {
+	return fmt.Sprintf("%s in %s is a directory, but --put-file and --delete-file only change files", err.Path, err.Repo)
+}
+
+type CommitWithoutParentErr struct {
+	Commit string
+}
+
+func (err CommitWithoutParentErr) Error() string {
+	return fmt.Sprintf("Commit %s has no parent, so it can't be created via the Git Data API on top of the existing branch", err.Commit)
+}
+
+type InvalidMonorepoManifestErr struct {
+	File string
+	Err  error
+}
+
+func (err InvalidMonorepoManifestErr) Error() string {
+	return fmt.Sprintf("The monorepo manifest %s passed via --monorepo-manifest is invalid: %v", err.File, err.Err)
+}
+
+type InvalidMonorepoPullRequestsErr struct {
+	MonorepoPullRequests string
+}
+
+func (err InvalidMonorepoPullRequestsErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --monorepo-pull-requests. Valid values are combined and per-directory", err.MonorepoPullRequests)
+}
+
+type InvalidGithubHostFlagErr struct {
+	Value string
+}
+
+func (err InvalidGithubHostFlagErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --github-host. It must be in the format of <host>=<token-env-var>, e.g. github.example.com=GHE_TOKEN, or <host>=<token-env-var>:<approver-token-env-var>", err.Value)
+}
+
+type GithubHostTokenNotSetErr struct {
+	Host   string
+	EnvVar string
+}
+
+func (err GithubHostTokenNotSetErr) Error() string {
+	return fmt.Sprintf("--github-host says to read the token for %s from %s, but no token is exported as %s", err.Host, err.EnvVar, err.EnvVar)
+}
+
+type GithubHostApproverNotSetErr struct {
+	Host string
+}
+
+func (err GithubHostApproverNotSetErr) Error() string {
+	return fmt.Sprintf("--approve-and-merge approves pull requests with a second identity, but --github-host doesn't name its token for %s. Pass it as --github-host %s=<token-env-var>:<approver-token-env-var>", err.Host, err.Host)
+}
+
+type UnknownGithubHostErr struct {
+	Host string
+}
+
+func (err UnknownGithubHostErr) Error() string {
+	return fmt.Sprintf("Repos on %s were passed, but no token was configured for it. Pass --github-host %s=<token-env-var>", err.Host, err.Host)
+}
+
+type NoJiraTokenProvidedErr struct{}
+
+func (NoJiraTokenProvidedErr) Error() string {
+	return "You must export a valid Jira API token as JIRA_API_TOKEN, along with the email address of its owner as JIRA_USER_EMAIL for Jira Cloud, to use --jira-issue"
+}
+
+type JiraIssueWithoutURLErr struct{}
+
+func (JiraIssueWithoutURLErr) Error() string {
+	return "--jira-issue requires --jira-url, the URL of the Jira instance the issue is in"
+}
+
+type InvalidJiraIssueKeyErr struct {
+	Key string
+}
+
+func (err InvalidJiraIssueKeyErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --jira-issue. It must be a Jira issue key, such as PLAT-123", err.Key)
+}
+
+type JiraTransitionNotFoundErr struct {
+	Issue      string
+	Transition string
+	Available  []string
+}
+
+func (err JiraTransitionNotFoundErr) Error() string {
+	return fmt.Sprintf("Jira issue %s has no transition named %q, or leading to a status named %q. The transitions available from its current status are: %s", err.Issue, err.Transition, err.Transition, strings.Join(err.Available, ", "))
+}
+
+type BaseBranchRequiresPullRequestErr struct {
+	Repo   string
+	Branch string
+}
+
+func (err BaseBranchRequiresPullRequestErr) Error() string {
+	return fmt.Sprintf("The %s branch of %s requires status checks or approvals, so --skip-pull-requests can't push to it", err.Branch, err.Repo)
+}
+
+type InvalidDeployKeyErr struct {
+	Path string
+	Err  error
+}
+
+func (err InvalidDeployKeyErr) Error() string {
+	return fmt.Sprintf("The deploy key %s can't be used: %v", err.Path, err.Err)
+}
+
+type DeployKeysDirNotFoundErr struct {
+	Dir string
+}
+
+func (err DeployKeysDirNotFoundErr) Error() string {
+	return fmt.Sprintf("The directory %s passed via --deploy-keys-dir doesn't exist", err.Dir)
+}
+
+type EnvFilesDirNotFoundErr struct {
+	Dir string
+}
+
+func (err EnvFilesDirNotFoundErr) Error() string {
+	return fmt.Sprintf("The directory %s passed via --env-files-dir doesn't exist", err.Dir)
+}
+
+type InvalidEnvFileErr struct {
+	Path string
+	Line int
+}
+
+func (err InvalidEnvFileErr) Error() string {
+	return fmt.Sprintf("Line %d of the env file %s is invalid. Each line must set a variable as NAME=value", err.Line, err.Path)
+}
+
+type DeniedRepoEnvVarErr struct {
+	Path string
+	Line int
+	Name string
+}
+
+func (err DeniedRepoEnvVarErr) Error() string {
+	return fmt.Sprintf("Line %d of the env file %s sets %s, which an env file committed to a repo may not set, since it changes which programs the command runs. Set it in --env-files-dir instead", err.Line, err.Path, err.Name)
+}
+
+type InvalidPathLabelsErr struct {
+	File string
+	Err  error
+}
+
+func (err InvalidPathLabelsErr) Error() string {
+	return fmt.Sprintf("The label rules %s passed via --path-labels are invalid: %v", err.File, err.Err)
+}
+
+type InvalidPullRequestScheduleErr struct {
+	Schedule string
+	Err      error
+}
+
+func (err InvalidPullRequestScheduleErr) Error() string {
+	return fmt.Sprintf("The --pr-schedule %q is invalid: %v", err.Schedule, err.Err)
+}
+
+type InvalidStatsEventErr struct {
+	Event  Event
+	Reason string
+}
+
+func (err InvalidStatsEventErr) Error() string {
+	return fmt.Sprintf("The event %q can't be registered, because %s", err.Event, err.Reason)
+}
+
+type InvalidManifestErr struct {
+	File string
+	Err  error
+}
+
+func (err InvalidManifestErr) Error() string {
+	return fmt.Sprintf("The run manifest %s is invalid: %v", err.File, err.Err)
+}
+
+type InvalidAuditLogErr struct {
+	File string
+	Err  error
+}
+
+func (err InvalidAuditLogErr) Error() string {
+	return fmt.Sprintf("The audit log %s can't be carried on, because it is invalid: %v", err.File, err.Err)
+}
+
+type AuditLogTamperedErr struct {
+	Entry  int
+	Reason string
+}
+
+func (err AuditLogTamperedErr) Error() string {
+	return fmt.Sprintf("Entry %d of the audit log doesn't check out, because %s", err.Entry, err.Reason)
+}
+
+type NoAuditLogProvidedErr struct{}
+
+func (NoAuditLogProvidedErr) Error() string {
+	return "You must pass the path of the audit log to verify"
+}
+
+type InvalidReportUploadURLErr struct {
+	URL string
+}
+
+func (err InvalidReportUploadURLErr) Error() string {
+	return fmt.Sprintf("The --report-upload flag must be in the format of s3://<bucket>/<prefix> or gs://<bucket>/<prefix>, but got: %s", err.URL)
+}
+
+type MissingObjectStorageCredentialsErr struct {
+	Scheme  string
+	Sources []string
+}
+
+func (err MissingObjectStorageCredentialsErr) Error() string {
+	return fmt.Sprintf("Found no credentials to upload to %s:// with. Looked in: %s", err.Scheme, strings.Join(err.Sources, "; "))
+}
+
+type UnsupportedObjectStorageCredentialsErr struct {
+	Path string
+	Type string
+}
+
+func (err UnsupportedObjectStorageCredentialsErr) Error() string {
+	return fmt.Sprintf("The credentials in %s are of type %q, which git-xargs can't upload with. Set CLOUDSDK_AUTH_ACCESS_TOKEN to an access token instead", err.Path, err.Type)
+}
+
+type ObjectStorageCredentialsRequestFailedErr struct {
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+func (err ObjectStorageCredentialsRequestFailedErr) Error() string {
+	return fmt.Sprintf("Requesting credentials from %s failed with status %d: %s", err.URL, err.StatusCode, err.Message)
+}
+
+type ObjectUploadFailedErr struct {
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+func (err ObjectUploadFailedErr) Error() string {
+	return fmt.Sprintf("Uploading %s failed with HTTP status code %d: %s", err.URL, err.StatusCode, err.Message)
+}
+
+type EmailToWithoutFromErr struct{}
+
+func (EmailToWithoutFromErr) Error() string {
+	return "--email-to requires --email-from, the address to send the run summary from"
+}
+
+type EmailToWithoutSMTPServerErr struct{}
+
+func (EmailToWithoutSMTPServerErr) Error() string {
+	return "--email-to requires --smtp-server, the <host>:<port> of the SMTP server to send the run summary through"
+}
+
+type InvalidEmailAddressErr struct {
+	Address string
+}
+
+func (err InvalidEmailAddressErr) Error() string {
+	return fmt.Sprintf("%s is not a valid email address", err.Address)
+}
+
+// BranchRule is a rule that a ruleset applies to a branch, e.g. required_signatures
+type BranchRule struct {
+	Type string `json:"type"`
+}
+
+type InvalidSigningKeyErr struct {
+	Path string
+	Err  error
+}
+
+func (err InvalidSigningKeyErr) Error() string {
+	return fmt.Sprintf("Could not load the --signing-key %s: %v", err.Path, err.Err)
+}
+
+type InvalidAPIHeaderErr struct {
+	Header string
+}
+
+func (err InvalidAPIHeaderErr) Error() string {
+	return fmt.Sprintf("%q is not a valid --api-header. It must be in the format of <name>: <value>, e.g. X-Forwarded-Auth: xxx", err.Header)
+}
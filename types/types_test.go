@@ -1,6 +1,7 @@
 package types
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,4 +32,13 @@ func TestCustomErrorStatements(t *testing.T) {
 	errNoGithubOauthTokenProvided := NoGithubOauthTokenProvidedErr{}
 	assert.Equal(t, "You must export a valid Github personal access token as GITHUB_OAUTH_TOKEN", errNoGithubOauthTokenProvided.Error())
 
+	errProcessRepos := ProcessReposErr{
+		RepoErrors: map[string]error{
+			"gruntwork-io/terragrunt": fmt.Errorf("exit status 1"),
+			"gruntwork-io/fetch":      fmt.Errorf("push rejected"),
+		},
+		Total: 5,
+	}
+	assert.Equal(t, "2 of 5 repos failed: gruntwork-io/fetch: push rejected; gruntwork-io/terragrunt: exit status 1", errProcessRepos.Error())
+
 }
@@ -0,0 +1,218 @@
+// Package update replaces the running git-xargs binary with the one published in the latest GitHub release, after
+// checking it against the SHA256SUMS file published alongside it.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+)
+
+const (
+	// The GitHub repo git-xargs is released from
+	ReleaseOwner = "gruntwork-io"
+	ReleaseRepo  = "git-xargs"
+	// checksumsAssetName is the name of the release asset listing the SHA256 checksum of every binary
+	checksumsAssetName = "SHA256SUMS"
+	// downloadTimeout is the longest downloading a single release asset may take
+	downloadTimeout = 5 * time.Minute
+)
+
+// ReleaseService is the part of the go-github Repositories service used to look up releases
+type ReleaseService interface {
+	GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error)
+}
+
+// LatestRelease returns the latest release of git-xargs
+func LatestRelease(ctx context.Context, service ReleaseService) (*github.RepositoryRelease, error) {
+	release, _, err := service.GetLatestRelease(ctx, ReleaseOwner, ReleaseRepo)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	return release, nil
+}
+
+// AssetName returns the name of the release asset holding the binary for the supplied OS and architecture, e.g.
+// git-xargs_linux_amd64
+func AssetName(goos string, goarch string) string {
+	name := fmt.Sprintf("%s_%s_%s", ReleaseRepo, goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// IsNewer returns true if the version latest is newer than the version current. Versions are compared as vX.Y.Z tags.
+// A current version that isn't one, such as that of a binary built from source, is considered older than any release
+func IsNewer(latest string, current string) bool {
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range latestParts {
+		if latestParts[i] != currentParts[i] {
+			return latestParts[i] > currentParts[i]
+		}
+	}
+	return false
+}
+
+// parseVersion returns the major, minor and patch numbers of the supplied vX.Y.Z version, ignoring any pre-release or
+// build suffix
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) != len(parts) {
+		return parts, false
+	}
+	for i, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = number
+	}
+	return parts, true
+}
+
+// Download downloads the binary for this machine from the supplied release and checks it against the SHA256SUMS file of
+// the release, returning an error rather than the binary if it doesn't match
+func Download(ctx context.Context, client *http.Client, release *github.RepositoryRelease) ([]byte, error) {
+	assetName := AssetName(runtime.GOOS, runtime.GOARCH)
+
+	binaryAsset := findAsset(release, assetName)
+	if binaryAsset == nil {
+		return nil, errors.WithStackTrace(types.NoReleaseAssetErr{Release: release.GetTagName(), Asset: assetName})
+	}
+	checksumsAsset := findAsset(release, checksumsAssetName)
+	if checksumsAsset == nil {
+		return nil, errors.WithStackTrace(types.NoReleaseAssetErr{Release: release.GetTagName(), Asset: checksumsAssetName})
+	}
+
+	checksums, err := downloadAsset(ctx, client, checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	binary, err := downloadAsset(ctx, client, binaryAsset)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyChecksum(binary, checksums, assetName); err != nil {
+		return nil, err
+	}
+	return binary, nil
+}
+
+// findAsset returns the asset of the supplied release with the supplied name, or nil if it has none
+func findAsset(release *github.RepositoryRelease, name string) *github.ReleaseAsset {
+	for _, asset := range release.Assets {
+		if asset.GetName() == name {
+			return asset
+		}
+	}
+	return nil
+}
+
+// downloadAsset downloads the contents of the supplied release asset
+func downloadAsset(ctx context.Context, client *http.Client, asset *github.ReleaseAsset) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, asset.GetBrowserDownloadURL(), nil)
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.WithStackTrace(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.WithStackTrace(types.ReleaseAssetDownloadErr{Asset: asset.GetName(), StatusCode: resp.StatusCode})
+	}
+	contents, err := ioutil.ReadAll(resp.Body)
+	return contents, errors.WithStackTrace(err)
+}
+
+// VerifyChecksum checks the supplied binary against the checksum listed for the supplied asset in the supplied
+// sha256sum-formatted checksums file. The checksums come from the same release as the binary, so this catches corrupted
+// downloads, not a tampered release
+func VerifyChecksum(binary []byte, checksums []byte, assetName string) error {
+	sum := sha256.Sum256(binary)
+	actual := hex.EncodeToString(sum[:])
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum marks files it read in binary mode with a leading *
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != assetName {
+			continue
+		}
+		if !strings.EqualFold(fields[0], actual) {
+			return errors.WithStackTrace(types.ChecksumMismatchErr{Asset: assetName, Expected: fields[0], Actual: actual})
+		}
+		return nil
+	}
+	return errors.WithStackTrace(types.NoChecksumErr{Asset: assetName})
+}
+
+// ReplaceExecutable replaces the executable at the supplied path with the supplied binary. The binary is written next to
+// the executable first and then renamed over it, so that the executable is never left half written
+func ReplaceExecutable(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".new-")
+	if err != nil {
+		return errors.WithStackTrace(err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(binary); err != nil {
+		tmpFile.Close()
+		return errors.WithStackTrace(err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.WithStackTrace(err)
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()); err != nil {
+		return errors.WithStackTrace(err)
+	}
+
+	// Windows doesn't let a running executable be replaced, but it does let it be renamed, so move it out of the way first
+	if runtime.GOOS == "windows" {
+		oldPath := path + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(path, oldPath); err != nil {
+			return errors.WithStackTrace(err)
+		}
+	}
+	return errors.WithStackTrace(os.Rename(tmpPath, path))
+}
@@ -0,0 +1,134 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/types"
+	"github.com/gruntwork-io/go-commons/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNewer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		latest   string
+		current  string
+		expected bool
+	}{
+		{"v0.1.6", "v0.1.5", true},
+		{"v0.2.0", "v0.1.10", true},
+		{"v1.0.0", "v0.99.99", true},
+		{"v0.1.5", "v0.1.5", false},
+		{"v0.1.4", "v0.1.5", false},
+		{"v0.1.5", "v0.1.5-alpha.1", false},
+		{"v0.1.5", "", true},
+		{"v0.1.5", "dev", true},
+		{"nightly", "v0.1.5", false},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, IsNewer(testCase.latest, testCase.current), "%s over %s", testCase.latest, testCase.current)
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "git-xargs_linux_amd64", AssetName("linux", "amd64"))
+	assert.Equal(t, "git-xargs_darwin_arm64", AssetName("darwin", "arm64"))
+	assert.Equal(t, "git-xargs_windows_386.exe", AssetName("windows", "386"))
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("binary")
+	sum := sha256.Sum256(binary)
+	checksum := hex.EncodeToString(sum[:])
+
+	checksums := []byte(fmt.Sprintf("%s  git-xargs_darwin_amd64\n%s *git-xargs_linux_amd64\n", checksum, checksum))
+	assert.NoError(t, VerifyChecksum(binary, checksums, "git-xargs_linux_amd64"))
+
+	err := VerifyChecksum([]byte("tampered"), checksums, "git-xargs_linux_amd64")
+	assert.IsType(t, types.ChecksumMismatchErr{}, errors.Unwrap(err))
+
+	err = VerifyChecksum(binary, checksums, "git-xargs_windows_amd64.exe")
+	assert.IsType(t, types.NoChecksumErr{}, errors.Unwrap(err))
+}
+
+func TestDownload(t *testing.T) {
+	t.Parallel()
+
+	assetName := AssetName(runtime.GOOS, runtime.GOARCH)
+	binary := []byte("the new git-xargs")
+	sum := sha256.Sum256(binary)
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + assetName:
+			w.Write(binary)
+		case "/SHA256SUMS":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	release := &github.RepositoryRelease{
+		TagName: github.String("v0.1.6"),
+		Assets: []*github.ReleaseAsset{
+			{Name: github.String(assetName), BrowserDownloadURL: github.String(server.URL + "/" + assetName)},
+			{Name: github.String("SHA256SUMS"), BrowserDownloadURL: github.String(server.URL + "/SHA256SUMS")},
+		},
+	}
+
+	downloaded, err := Download(context.Background(), server.Client(), release)
+	require.NoError(t, err)
+	assert.Equal(t, binary, downloaded)
+
+	// A release without a binary for this machine can't be installed
+	release.Assets = release.Assets[1:]
+	_, err = Download(context.Background(), server.Client(), release)
+	assert.IsType(t, types.NoReleaseAssetErr{}, errors.Unwrap(err))
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "git-xargs-update")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "git-xargs")
+	require.NoError(t, ioutil.WriteFile(path, []byte("the old git-xargs"), 0755))
+
+	require.NoError(t, ReplaceExecutable(path, []byte("the new git-xargs")))
+
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "the new git-xargs", string(contents))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// The binary was renamed into place, leaving nothing else behind
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package util
+
+// DiskSpace returns the free and total bytes of the disk the supplied directory is on, which aren't known on this
+// platform, so it always returns false
+func DiskSpace(dir string) (uint64, uint64, bool) {
+	return 0, 0, false
+}
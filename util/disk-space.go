@@ -0,0 +1,15 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package util
+
+import "syscall"
+
+// DiskSpace returns the free and total bytes of the disk the supplied directory is on, and false if they aren't known
+func DiskSpace(dir string) (uint64, uint64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil || stat.Blocks == 0 {
+		return 0, 0, false
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), true
+}
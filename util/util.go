@@ -1,19 +1,28 @@
 package util
 
 import (
+	"bytes"
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"regexp"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/google/go-github/v32/github"
+	"github.com/gruntwork-io/git-xargs/logging"
 	"github.com/gruntwork-io/git-xargs/types"
-	"github.com/gruntwork-io/go-commons/logging"
 	"github.com/sirupsen/logrus"
 )
 
 const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
 
-// ConvertStringToAllowedRepo accepts a user-supplied repo in the format of <github-organization>/<repo-name>.
+// ConvertStringToAllowedRepo accepts a user-supplied repo in the format of <github-organization>/<repo-name>, optionally
+// prefixed with the host of the GitHub Enterprise Server it is on, e.g. github.example.com/<github-organization>/<repo-name>,
+// and optionally suffixed with the branch to target in that repo, e.g. <github-organization>/<repo-name>@release-1.x.
 // It trims out stray characters that we might expect in a repos file that was copy-pasted from json or an array,
 // and it only returns an AllowedRepo if the user-supplied input looks valid. Note this does not actually look
 // up the repo via the GitHub API because that's slow, and we do it later when converting repo names to GitHub response structs.
@@ -26,7 +35,26 @@ func ConvertStringToAllowedRepo(repoInput string) *types.AllowedRepo {
 
 	trimmedLine := strings.TrimSpace(repoInput)
 	cleanedLine := charRegex.ReplaceAllString(trimmedLine, "")
+	// A trailing @branch, which may itself contain slashes, denotes the branch to target in the repo
+	branch := ""
+	if at := strings.Index(cleanedLine, "@"); at != -1 {
+		branch = cleanedLine[at+1:]
+		cleanedLine = cleanedLine[:at]
+		if branch == "" {
+			logger.WithFields(logrus.Fields{
+				"Repo input": repoInput,
+			}).Debug("Empty branch after @ in repo input - skipping")
+
+			return nil
+		}
+	}
 	orgAndRepoSlice := strings.Split(cleanedLine, "/")
+	// A leading host, which contains a dot unlike an organization name, denotes the GitHub host the repo is on
+	host := ""
+	if len(orgAndRepoSlice) == 3 && strings.Contains(orgAndRepoSlice[0], ".") {
+		host = NormalizeGithubHost(orgAndRepoSlice[0])
+		orgAndRepoSlice = orgAndRepoSlice[1:]
+	}
 	// Guard against stray lines, extra dangling single quotes, etc
 	if len(orgAndRepoSlice) < 2 {
 
@@ -46,6 +74,8 @@ func ConvertStringToAllowedRepo(repoInput string) *types.AllowedRepo {
 		repo := &types.AllowedRepo{
 			Organization: parsedOrg,
 			Name:         parsedName,
+			Host:         host,
+			Branch:       branch,
 		}
 		return repo
 	}
@@ -65,6 +95,69 @@ func RandStringBytes(n int) string {
 	return string(b)
 }
 
+// NewRunID generates a unique identifier for a single invocation of git-xargs. It is prefixed with the UTC start time
+// of the run so that IDs sort chronologically, and suffixed with random hex to avoid collisions between concurrent runs
+func NewRunID(startTime time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := cryptorand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%s", startTime.UTC().Format("20060102T150405"), strings.ToLower(RandStringBytes(8)))
+	}
+	return fmt.Sprintf("%s-%s", startTime.UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+}
+
+// RenderTemplate renders the supplied Go text/template string using the supplied data. Strings without any template
+// actions are returned unchanged
+func RenderTemplate(text string, data interface{}) (string, error) {
+	tmpl, err := template.New("git-xargs").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
 func NewTestFileName() string {
 	return fmt.Sprintf("test-file-%s", RandStringBytes(9))
 }
+
+// NormalizeGithubHost lowercases the supplied GitHub host, and turns github.com, the default host, into an empty string
+func NormalizeGithubHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "github.com" || host == "www.github.com" {
+		return ""
+	}
+	return host
+}
+
+// RepoKey returns the key the run report tracks the supplied repo under: its owner and name, prefixed with the host of
+// its GitHub Enterprise Server if it's on one, so that repos of the same name in different orgs, or on different hosts,
+// are told apart
+func RepoKey(repo *github.Repository) string {
+	key := repo.GetOwner().GetLogin() + "/" + repo.GetName()
+	if host := RepoHost(repo); host != "" {
+		return host + "/" + key
+	}
+	return key
+}
+
+// RepoHost returns the host of the GitHub Enterprise Server the supplied repo is on, going by its URL, or an empty
+// string if it's on github.com
+func RepoHost(repo *github.Repository) string {
+	parsed, err := url.Parse(repo.GetHTMLURL())
+	if err != nil {
+		return ""
+	}
+	return NormalizeGithubHost(parsed.Hostname())
+}
+
+// IsGitTrailer returns true if the supplied --skip-ci-trailer is a git trailer in the key: value form, such as
+// "skip-checks: true", rather than a marker such as "[skip ci]". Trailers only mean something at the end of a commit
+// message
+func IsGitTrailer(marker string) bool {
+	return strings.Contains(marker, ": ")
+}
@@ -0,0 +1,68 @@
+// Package version holds the build metadata of the git-xargs binary, which is injected at build time via -ldflags. For
+// example:
+//
+// go build -ldflags "-X github.com/gruntwork-io/git-xargs/version.Version=v0.1.5 -X github.com/gruntwork-io/git-xargs/version.Commit=$(git rev-parse HEAD) -X github.com/gruntwork-io/git-xargs/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Binaries built without them, such as via go get, have none of it, except for the version of Go.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+var (
+	// Version is the semver tag the binary was released as, e.g. v0.1.5
+	Version string
+	// Commit is the SHA of the commit the binary was built from
+	Commit string
+	// BuildDate is when the binary was built, in RFC 3339 format
+	BuildDate string
+)
+
+// shortCommitLength is the number of characters of the commit SHA shown in the one line summary
+const shortCommitLength = 7
+
+// Details returns the build metadata as printed by --version, one field per line
+func Details() string {
+	lines := []string{
+		fmt.Sprintf("git-xargs version %s", orUnknown(Version)),
+		fmt.Sprintf("commit: %s", orUnknown(Commit)),
+		fmt.Sprintf("built: %s", orUnknown(BuildDate)),
+		fmt.Sprintf("go: %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Summary returns the build metadata on one line, leaving out the fields that weren't injected, e.g. git-xargs v0.1.5
+// (commit 1a2b3c4, built 2021-06-29T16:11:31Z, go1.14.15)
+func Summary() string {
+	name := "git-xargs"
+	if Version != "" {
+		name += " " + Version
+	} else {
+		name += " built from source"
+	}
+
+	details := []string{}
+	if Commit != "" {
+		commit := Commit
+		if len(commit) > shortCommitLength {
+			commit = commit[:shortCommitLength]
+		}
+		details = append(details, "commit "+commit)
+	}
+	if BuildDate != "" {
+		details = append(details, "built "+BuildDate)
+	}
+	details = append(details, runtime.Version())
+	return fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
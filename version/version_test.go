@@ -0,0 +1,23 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Not parallel, since the tests set the build metadata, which is global
+func TestSummary(t *testing.T) {
+	defer func(version, commit, buildDate string) {
+		Version, Commit, BuildDate = version, commit, buildDate
+	}(Version, Commit, BuildDate)
+
+	Version, Commit, BuildDate = "v0.1.5", "1a2b3c4d5e6f", "2021-06-29T16:11:31Z"
+	assert.Equal(t, "git-xargs v0.1.5 (commit 1a2b3c4, built 2021-06-29T16:11:31Z, "+runtime.Version()+")", Summary())
+	assert.Contains(t, Details(), "git-xargs version v0.1.5\ncommit: 1a2b3c4d5e6f\nbuilt: 2021-06-29T16:11:31Z\ngo: "+runtime.Version())
+
+	Version, Commit, BuildDate = "", "", ""
+	assert.Equal(t, "git-xargs built from source ("+runtime.Version()+")", Summary())
+	assert.Contains(t, Details(), "git-xargs version unknown\ncommit: unknown")
+}